@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/lin-snow/ech0/internal/cli"
+	"github.com/spf13/cobra"
+)
+
+// configCmd 是查看和校验配置的命令
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "查看和校验配置",
+	// 配置命令自行读取配置，配置不合法时也能输出结果，其余前置逻辑仍由根命令执行
+	Annotations: map[string]string{selfManagedConfigAnnotation: "true"},
+}
+
+// configPrintCmd 是输出生效配置的命令
+var configPrintCmd = &cobra.Command{
+	Use:   "print",
+	Short: "输出合并后的生效配置（隐藏敏感信息）",
+	Run: func(cmd *cobra.Command, args []string) {
+		cli.DoConfigPrint(configFile)
+	},
+}
+
+// configValidateCmd 是校验配置的命令
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "校验配置是否合法",
+	Run: func(cmd *cobra.Command, args []string) {
+		if !cli.DoConfigValidate(configFile) {
+			os.Exit(1)
+		}
+	},
+}
+
+// init 函数用于初始化根命令和子命令
+func init() {
+	configCmd.AddCommand(configPrintCmd)
+	configCmd.AddCommand(configValidateCmd)
+	rootCmd.AddCommand(configCmd)
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"github.com/lin-snow/ech0/internal/cli"
+	"github.com/spf13/cobra"
+)
+
+var (
+	deadLetterStatus string // 按状态过滤死信任务
+	deadLetterLimit  int    // 列出的死信任务数量
+)
+
+// deadLetterCmd 是管理死信队列的命令
+var deadLetterCmd = &cobra.Command{
+	Use:   "deadletter",
+	Short: "管理死信队列",
+}
+
+// deadLetterListCmd 是列出死信任务的命令
+var deadLetterListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "列出死信任务",
+	Run: func(cmd *cobra.Command, args []string) {
+		cli.DoDeadLetterList(deadLetterStatus, deadLetterLimit)
+	},
+}
+
+// deadLetterShowCmd 是查看死信任务详情的命令
+var deadLetterShowCmd = &cobra.Command{
+	Use:   "show <id>",
+	Short: "查看死信任务详情",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 1 {
+			_ = cmd.Help()
+			return
+		}
+
+		cli.DoDeadLetterShow(args[0])
+	},
+}
+
+// deadLetterRetryCmd 是重试死信任务的命令
+var deadLetterRetryCmd = &cobra.Command{
+	Use:   "retry <id>",
+	Short: "重置并重试死信任务",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 1 {
+			_ = cmd.Help()
+			return
+		}
+
+		cli.DoDeadLetterRetry(args[0])
+	},
+}
+
+// deadLetterDiscardCmd 是丢弃死信任务的命令
+var deadLetterDiscardCmd = &cobra.Command{
+	Use:   "discard <id>",
+	Short: "丢弃死信任务",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 1 {
+			_ = cmd.Help()
+			return
+		}
+
+		cli.DoDeadLetterDiscard(args[0])
+	},
+}
+
+// deadLetterPurgeCmd 是清理死信任务的命令
+var deadLetterPurgeCmd = &cobra.Command{
+	Use:   "purge",
+	Short: "清理已完成和已丢弃的死信任务",
+	Run: func(cmd *cobra.Command, args []string) {
+		cli.DoDeadLetterPurge()
+	},
+}
+
+// init 函数用于初始化根命令和子命令
+func init() {
+	deadLetterListCmd.Flags().
+		StringVarP(&deadLetterStatus, "status", "s", "", "按状态过滤 (pending/processing/failed/completed/discarded)")
+	deadLetterListCmd.Flags().IntVarP(&deadLetterLimit, "limit", "n", 20, "最多列出的数量")
+
+	deadLetterCmd.AddCommand(deadLetterListCmd)
+	deadLetterCmd.AddCommand(deadLetterShowCmd)
+	deadLetterCmd.AddCommand(deadLetterRetryCmd)
+	deadLetterCmd.AddCommand(deadLetterDiscardCmd)
+	deadLetterCmd.AddCommand(deadLetterPurgeCmd)
+	rootCmd.AddCommand(deadLetterCmd)
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"github.com/lin-snow/ech0/internal/cli"
+	model "github.com/lin-snow/ech0/internal/model/exporter"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportFormat string // 导出格式
+	exportOutput string // 导出文件路径
+)
+
+// exportCmd 是导出全部 Echo 的命令
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "导出全部 Echo 为 Markdown、JSONL 或静态 HTML",
+	Run: func(cmd *cobra.Command, args []string) {
+		cli.DoExport(exportFormat, exportOutput)
+	},
+}
+
+func init() {
+	exportCmd.Flags().StringVar(&exportFormat, "format", model.ExportFormatMarkdown, "导出格式 (md/jsonl/html)")
+	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "导出文件路径，默认为当前目录下按格式与时间命名的文件")
+	rootCmd.AddCommand(exportCmd)
+}
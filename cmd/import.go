@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"github.com/lin-snow/ech0/internal/cli"
+	model "github.com/lin-snow/ech0/internal/model/importer"
+	"github.com/spf13/cobra"
+)
+
+var importOptions model.ImportOptions // 导入选项
+
+// importCmd 是从其他平台导入内容的命令
+var importCmd = &cobra.Command{
+	Use:   "import <memos|mastodon|twitter> <path>",
+	Short: "从 Memos、Mastodon 或 Twitter/X 的导出数据导入 Echo",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 2 {
+			_ = cmd.Help()
+			return
+		}
+
+		options := importOptions
+		options.Source = args[0]
+		cli.DoImport(args[1], options)
+	},
+}
+
+func init() {
+	importCmd.Flags().BoolVar(&importOptions.DryRun, "dry-run", false, "只解析并统计，不写入数据")
+	importCmd.Flags().StringVar(&importOptions.Storage, "storage", "local", "媒体文件的存储方式 (local/s3)")
+	importCmd.Flags().StringVar(&importOptions.Visibility, "visibility", "", "覆盖所有导入内容的可见性")
+	importCmd.Flags().StringVar(&importOptions.BaseURL, "base-url", "", "来源站点地址，用于下载 Memos API 导出数据中的媒体文件")
+	rootCmd.AddCommand(importCmd)
+}
@@ -0,0 +1,19 @@
+package cmd
+
+import (
+	"github.com/lin-snow/ech0/internal/cli"
+	"github.com/spf13/cobra"
+)
+
+// reindexCmd 是重建全文索引的命令
+var reindexCmd = &cobra.Command{
+	Use:   "reindex",
+	Short: "重建 Echo 全文索引",
+	Run: func(cmd *cobra.Command, args []string) {
+		cli.DoReindex()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(reindexCmd)
+}
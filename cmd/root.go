@@ -4,9 +4,16 @@ import (
 	"os"
 
 	"github.com/lin-snow/ech0/internal/cli"
+	"github.com/lin-snow/ech0/internal/config"
 	"github.com/spf13/cobra"
 )
 
+// configFile 是通过 --config 指定的外部配置文件路径
+var configFile string
+
+// selfManagedConfigAnnotation 标记自行读取配置的命令，根命令的前置钩子不为其及子命令加载配置
+const selfManagedConfigAnnotation = "ech0/self-managed-config"
+
 // rootCmd 是 Ech0 的根命令
 // 默认启动CLI With TUI
 var rootCmd = &cobra.Command{
@@ -14,12 +21,30 @@ var rootCmd = &cobra.Command{
 	Short: "面向个人的新一代开源、自托管、专注思想流动的轻量级联邦发布平台",
 	Long:  `面向个人的新一代开源、自托管、专注思想流动的轻量级联邦发布平台`,
 
+	// 所有子命令执行前加载配置
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		if selfManagedConfig(cmd) {
+			return
+		}
+		config.LoadAppConfig(configFile)
+	},
+
 	// 这个 Run 会在没有子命令时执行
 	Run: func(cmd *cobra.Command, args []string) {
 		cli.DoTui()
 	},
 }
 
+// selfManagedConfig 判断命令或其上级命令是否自行读取配置
+func selfManagedConfig(cmd *cobra.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		if _, ok := c.Annotations[selfManagedConfigAnnotation]; ok {
+			return true
+		}
+	}
+	return false
+}
+
 // tuiCmd 是启动 Ech0 TUI 的命令
 var tuiCmd = &cobra.Command{
 	Use:   "tui",
@@ -60,6 +85,8 @@ var helloCmd = &cobra.Command{
 func init() {
 	// 解决Windows下使用 Cobra 触发 mousetrap 提示
 	cobra.MousetrapHelpText = ""
+	rootCmd.PersistentFlags().
+		StringVarP(&configFile, "config", "c", "", "外部配置文件路径，默认查找 data/config.yaml 等位置")
 	rootCmd.AddCommand(tuiCmd)
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(infoCmd)
@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"github.com/lin-snow/ech0/internal/cli"
+	"github.com/spf13/cobra"
+)
+
+// userCmd 是管理用户的命令
+var userCmd = &cobra.Command{
+	Use:   "user",
+	Short: "管理用户",
+}
+
+// userDisableTwoFactorCmd 是关闭用户两步验证的命令
+var userDisableTwoFactorCmd = &cobra.Command{
+	Use:   "disable-2fa <username>",
+	Short: "关闭用户的两步验证，用于丢失认证器时找回账号",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 1 {
+			_ = cmd.Help()
+			return
+		}
+
+		cli.DoUserDisableTwoFactor(args[0])
+	},
+}
+
+// init 函数用于初始化根命令和子命令
+func init() {
+	userCmd.AddCommand(userDisableTwoFactorCmd)
+	rootCmd.AddCommand(userCmd)
+}
@@ -0,0 +1,34 @@
+// Package authz 基于角色权限表的统一鉴权，供路由中间件与各 service 共用
+package authz
+
+import (
+	"errors"
+
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
+)
+
+// Authorize 检查用户是否拥有指定权限，不具备时返回无权限错误
+func Authorize(user userModel.User, permission userModel.Permission) error {
+	if user.ID == userModel.USER_NOT_EXISTS_ID || !user.Can(permission) {
+		return errors.New(commonModel.NO_PERMISSION_DENIED)
+	}
+	return nil
+}
+
+// AuthorizeOwner 检查用户能否操作属于 ownerID 的内容，
+// 拥有 manage 权限可以操作所有人的内容，否则只能以 own 权限操作自己的内容
+func AuthorizeOwner(
+	user userModel.User,
+	ownerID uint,
+	manage userModel.Permission,
+	own userModel.Permission,
+) error {
+	if user.Can(manage) {
+		return nil
+	}
+	if user.ID != userModel.USER_NOT_EXISTS_ID && user.ID == ownerID && user.Can(own) {
+		return nil
+	}
+	return errors.New(commonModel.NO_PERMISSION_DENIED)
+}
@@ -0,0 +1,104 @@
+package authz
+
+import (
+	"slices"
+	"testing"
+
+	userModel "github.com/lin-snow/ech0/internal/model/user"
+)
+
+func newUser(id uint, role userModel.Role) userModel.User {
+	user := userModel.User{ID: id, Username: string(role)}
+	user.SetRole(role)
+	return user
+}
+
+func TestAuthorizeRoleTable(t *testing.T) {
+	// 每个权限允许的角色，未列出的角色必须被拒绝
+	allowed := map[userModel.Permission][]userModel.Role{
+		userModel.PermissionEchoWrite:      {userModel.RoleOwner, userModel.RoleAdmin, userModel.RoleEditor},
+		userModel.PermissionMediaUpload:    {userModel.RoleOwner, userModel.RoleAdmin, userModel.RoleEditor},
+		userModel.PermissionTodoManage:     {userModel.RoleOwner, userModel.RoleAdmin, userModel.RoleEditor},
+		userModel.PermissionAccessToken:    {userModel.RoleOwner, userModel.RoleAdmin, userModel.RoleEditor},
+		userModel.PermissionOAuthBind:      {userModel.RoleOwner, userModel.RoleAdmin, userModel.RoleEditor},
+		userModel.PermissionEchoManage:     {userModel.RoleOwner, userModel.RoleAdmin},
+		userModel.PermissionInboxManage:    {userModel.RoleOwner, userModel.RoleAdmin},
+		userModel.PermissionQueueManage:    {userModel.RoleOwner, userModel.RoleAdmin},
+		userModel.PermissionTrashManage:    {userModel.RoleOwner, userModel.RoleAdmin},
+		userModel.PermissionConnectManage:  {userModel.RoleOwner, userModel.RoleAdmin},
+		userModel.PermissionContentImport:  {userModel.RoleOwner, userModel.RoleAdmin},
+		userModel.PermissionContentExport:  {userModel.RoleOwner, userModel.RoleAdmin},
+		userModel.PermissionUserManage:     {userModel.RoleOwner, userModel.RoleAdmin},
+		userModel.PermissionSettingsManage: {userModel.RoleOwner, userModel.RoleAdmin},
+		userModel.PermissionBackupCreate:   {userModel.RoleOwner, userModel.RoleAdmin},
+		userModel.PermissionSystemSetting:  {userModel.RoleOwner},
+		userModel.PermissionBackupRestore:  {userModel.RoleOwner},
+	}
+
+	for permission, roles := range allowed {
+		for _, role := range userModel.Roles {
+			want := slices.Contains(roles, role)
+			err := Authorize(newUser(1, role), permission)
+			if (err == nil) != want {
+				t.Errorf("%s %s: err = %v, want allowed = %v", role, permission, err, want)
+			}
+		}
+	}
+
+	// 权限表中的每个权限都要在上面列出
+	for _, permission := range userModel.RoleOwner.Permissions() {
+		if _, ok := allowed[permission]; !ok {
+			t.Errorf("permission %s is not covered", permission)
+		}
+	}
+}
+
+func TestAuthorizeRejectsAnonymousAndUnknownRoles(t *testing.T) {
+	// 未登录用户即使带有角色也没有任何权限
+	if err := Authorize(newUser(userModel.USER_NOT_EXISTS_ID, userModel.RoleOwner), userModel.PermissionEchoWrite); err == nil {
+		t.Error("expected anonymous user to be denied")
+	}
+	if err := Authorize(userModel.User{ID: 1, Role: "root"}, userModel.PermissionEchoWrite); err == nil {
+		t.Error("expected unknown role to be denied")
+	}
+}
+
+func TestSetRoleSyncsAdminFlag(t *testing.T) {
+	for role, want := range map[userModel.Role]bool{
+		userModel.RoleOwner:  true,
+		userModel.RoleAdmin:  true,
+		userModel.RoleEditor: false,
+		userModel.RoleViewer: false,
+	} {
+		if got := newUser(1, role).IsAdmin; got != want {
+			t.Errorf("%s: IsAdmin = %v, want %v", role, got, want)
+		}
+	}
+}
+
+func TestAuthorizeOwner(t *testing.T) {
+	const ownerID = 2
+
+	tests := map[string]struct {
+		user userModel.User
+		want bool
+	}{
+		"admin manages others": {newUser(1, userModel.RoleAdmin), true},
+		"editor edits own":     {newUser(ownerID, userModel.RoleEditor), true},
+		"editor edits others":  {newUser(3, userModel.RoleEditor), false},
+		"viewer edits own":     {newUser(ownerID, userModel.RoleViewer), false},
+	}
+
+	for name, tt := range tests {
+		err := AuthorizeOwner(tt.user, ownerID, userModel.PermissionEchoManage, userModel.PermissionEchoWrite)
+		if (err == nil) != tt.want {
+			t.Errorf("%s: err = %v, want allowed = %v", name, err, tt.want)
+		}
+	}
+
+	// 资源属于匿名用户时，匿名请求也不能以 own 权限操作
+	anonymous := newUser(userModel.USER_NOT_EXISTS_ID, userModel.RoleEditor)
+	if err := AuthorizeOwner(anonymous, userModel.USER_NOT_EXISTS_ID, userModel.PermissionEchoManage, userModel.PermissionEchoWrite); err == nil {
+		t.Error("expected anonymous owner match to be denied")
+	}
+}
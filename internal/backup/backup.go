@@ -3,9 +3,11 @@ package backup
 import (
 	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
 	"time"
 
+	"github.com/lin-snow/ech0/internal/config"
 	"github.com/lin-snow/ech0/internal/database"
 	fileUtil "github.com/lin-snow/ech0/internal/util/file"
 	logUtil "github.com/lin-snow/ech0/internal/util/log"
@@ -25,6 +27,16 @@ func ExecuteBackup() (string, string, error) {
 	backupFileName := fmt.Sprintf("%s_%s.zip", backupFileName, backupTime) // 暂时不开启多备份，每次只保留最新的一份备份
 	backupPath := fmt.Sprintf("%s/%s", backupDir, backupFileName)
 
+	// 非 SQLite 数据库无法直接打包数据库文件，先导出逻辑备份随数据目录一同打包
+	if !database.IsSQLite() {
+		ensureDatabase()
+		dumpPath := filepath.Join(dataDir, database.DumpFileName)
+		if err := database.ExportDumpToFile(dumpPath); err != nil {
+			return "", "", err
+		}
+		defer os.Remove(dumpPath)
+	}
+
 	return backupPath, backupFileName, fileUtil.ZipDirectoryWithOptions(
 		dataDir,
 		backupPath,
@@ -55,7 +67,13 @@ func ExecuteRestore(backupFilePath string) error {
 		return err
 	}
 
-	return nil
+	// SQLite 备份已随解压覆盖数据库文件，其余情况需要逻辑恢复
+	if database.IsSQLite() && !fileUtil.FileExists(filepath.Join(dataDir, database.DumpFileName)) {
+		return nil
+	}
+
+	ensureDatabase()
+	return restoreLogical(dataDir)
 }
 
 // ExcuteRestoreOnline 在线恢复备份
@@ -82,7 +100,16 @@ func ExcuteRestoreOnline(filePath string, timeStamp int64) error {
 		return err
 	}
 
-	tempDbPath := filepath.Join(extractPath, "ech0.db")
+	dbPath := config.Config.Database.Path
+	tempDbPath := filepath.Join(extractPath, filepath.Base(dbPath))
+
+	// 非 SQLite 数据库或备份中没有数据库文件时，复制数据目录后从逻辑备份恢复
+	if !database.IsSQLite() || !fileUtil.FileExists(tempDbPath) {
+		if err := fileUtil.CopyDirectory(extractPath, dataDir); err != nil {
+			return err
+		}
+		return restoreLogical(extractPath)
+	}
 
 	// 热切换到临时数据库
 	if err := database.HotChangeDatabase(tempDbPath); err != nil {
@@ -90,15 +117,37 @@ func ExcuteRestoreOnline(filePath string, timeStamp int64) error {
 	}
 
 	// 复制备份覆盖到正式数据目录
-	dataPath := "data"
-	if err := fileUtil.CopyDirectory(extractPath, dataPath); err != nil {
+	if err := fileUtil.CopyDirectory(extractPath, dataDir); err != nil {
 		return err
 	}
 
 	// 热切换回正式数据库
-	if err := database.HotChangeDatabase("data/ech0.db"); err != nil {
+	if err := database.HotChangeDatabase(dbPath); err != nil {
 		return err
 	}
 
 	return nil
 }
+
+// restoreLogical 从目录中的逻辑备份恢复数据库，没有逻辑备份时尝试导入其中的 SQLite 数据库文件
+func restoreLogical(dir string) error {
+	dumpPath := filepath.Join(dir, database.DumpFileName)
+	if fileUtil.FileExists(dumpPath) {
+		defer os.Remove(filepath.Join(dataDir, database.DumpFileName))
+		return database.ImportDumpFromFile(dumpPath)
+	}
+
+	sqlitePath := filepath.Join(dir, filepath.Base(config.Config.Database.Path))
+	if fileUtil.FileExists(sqlitePath) {
+		return database.ImportSQLiteFile(sqlitePath)
+	}
+
+	return errors.New("备份中没有可恢复的数据库")
+}
+
+// ensureDatabase 确保数据库已连接，命令行中执行备份恢复时数据库尚未初始化
+func ensureDatabase() {
+	if !database.IsInitialized() {
+		database.InitDatabase()
+	}
+}
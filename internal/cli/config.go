@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lin-snow/ech0/internal/config"
+	"github.com/lin-snow/ech0/internal/tui"
+)
+
+// DoConfigPrint 输出合并后的生效配置，敏感信息会被隐藏
+func DoConfigPrint(configFile string) {
+	cfg, path, err := config.ReadAppConfig(configFile)
+	if err != nil {
+		tui.PrintCLIInfo("😭 读取配置失败", err.Error())
+		return
+	}
+
+	out, err := cfg.RedactedYAML()
+	if err != nil {
+		tui.PrintCLIInfo("😭 输出配置失败", err.Error())
+		return
+	}
+
+	tui.PrintCLIInfo("📄 配置来源", configSource(path))
+	fmt.Print(string(out))
+
+	if err := cfg.Validate(); err != nil {
+		tui.PrintCLIInfo("⚠️ 配置不合法", err.Error())
+	}
+}
+
+// DoConfigValidate 校验配置，返回配置是否合法
+func DoConfigValidate(configFile string) bool {
+	cfg, path, err := config.ReadAppConfig(configFile)
+	if err != nil {
+		tui.PrintCLIInfo("😭 读取配置失败", err.Error())
+		return false
+	}
+
+	if err := cfg.Validate(); err != nil {
+		tui.PrintCLIWithBox(
+			tui.CLIInfoItem{Title: "📄 配置来源", Msg: configSource(path)},
+			tui.CLIInfoItem{Title: "😭 配置不合法", Msg: err.Error()},
+		)
+		return false
+	}
+
+	tui.PrintCLIWithBox(
+		tui.CLIInfoItem{Title: "📄 配置来源", Msg: configSource(path)},
+		tui.CLIInfoItem{Title: "🎉 校验通过", Msg: "配置合法"},
+	)
+	return true
+}
+
+// configSource 描述配置的来源
+func configSource(path string) string {
+	sources := []string{"内置默认配置"}
+	if path != "" {
+		sources = append(sources, path)
+	}
+	sources = append(sources, config.EnvPrefix+"_* 环境变量")
+	return strings.Join(sources, " -> ")
+}
@@ -0,0 +1,178 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/lin-snow/ech0/internal/database"
+	queueModel "github.com/lin-snow/ech0/internal/model/queue"
+	queueRepository "github.com/lin-snow/ech0/internal/repository/queue"
+	"github.com/lin-snow/ech0/internal/tui"
+)
+
+const deadLetterTimeLayout = "2006-01-02 15:04:05"
+
+// DoDeadLetterList 列出死信任务
+func DoDeadLetterList(status string, limit int) {
+	if status != "" && !queueModel.IsValidDeadLetterStatus(status) {
+		tui.PrintCLIInfo("😭 执行结果", "无效的死信任务状态: "+status)
+		return
+	}
+
+	repo := newQueueRepository()
+	deadLetters, total, err := repo.ListDeadLettersByPage(status, 0, limit)
+	if err != nil {
+		tui.PrintCLIInfo("😭 执行结果", "获取死信任务失败: "+err.Error())
+		return
+	}
+	if len(deadLetters) == 0 {
+		tui.PrintCLIInfo("📭 死信队列", "没有死信任务")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "ID\tTYPE\tSTATUS\tRETRY\tNEXT RETRY\tERROR")
+	for _, dl := range deadLetters {
+		_, _ = fmt.Fprintf(
+			w,
+			"%d\t%s\t%s\t%d\t%s\t%s\n",
+			dl.ID,
+			dl.Type,
+			dl.Status,
+			dl.RetryCount,
+			dl.NextRetry.Format(deadLetterTimeLayout),
+			truncate(dl.ErrorMsg, 60),
+		)
+	}
+	_ = w.Flush()
+
+	tui.PrintCLIInfo("📬 死信队列", fmt.Sprintf("共 %d 条，显示 %d 条", total, len(deadLetters)))
+}
+
+// DoDeadLetterShow 查看死信任务详情
+func DoDeadLetterShow(rawID string) {
+	repo := newQueueRepository()
+	deadLetter, ok := loadDeadLetter(repo, rawID)
+	if !ok {
+		return
+	}
+
+	// 隐藏 webhook 密钥、用户密码等敏感字段
+	redacted := deadLetter.Redacted()
+	payload := string(redacted.Payload)
+	var pretty map[string]any
+	if err := json.Unmarshal(redacted.Payload, &pretty); err == nil {
+		if indented, err := json.MarshalIndent(pretty, "", "  "); err == nil {
+			payload = string(indented)
+		}
+	}
+
+	tui.PrintCLIWithBox(
+		tui.CLIInfoItem{Title: "ID", Msg: strconv.FormatInt(deadLetter.ID, 10)},
+		tui.CLIInfoItem{Title: "类型", Msg: deadLetter.Type},
+		tui.CLIInfoItem{Title: "状态", Msg: deadLetter.Status},
+		tui.CLIInfoItem{Title: "重试次数", Msg: strconv.Itoa(deadLetter.RetryCount)},
+		tui.CLIInfoItem{Title: "下次重试", Msg: deadLetter.NextRetry.Format(deadLetterTimeLayout)},
+		tui.CLIInfoItem{Title: "创建时间", Msg: deadLetter.CreatedAt.Format(deadLetterTimeLayout)},
+		tui.CLIInfoItem{Title: "失败原因", Msg: deadLetter.ErrorMsg},
+	)
+	fmt.Println(payload)
+}
+
+// DoDeadLetterRetry 重置死信任务，使其在下一次消费时立即重试
+func DoDeadLetterRetry(rawID string) {
+	repo := newQueueRepository()
+	deadLetter, ok := loadDeadLetter(repo, rawID)
+	if !ok {
+		return
+	}
+	if deadLetter.Status == queueModel.DeadLetterStatusCompleted {
+		tui.PrintCLIInfo("⚠️ 执行结果", "死信任务已完成，无需重试")
+		return
+	}
+
+	deadLetter.ResetForRetry(time.Now())
+	if err := repo.UpdateDeadLetter(context.Background(), deadLetter); err != nil {
+		tui.PrintCLIInfo("😭 执行结果", "重置死信任务失败: "+err.Error())
+		return
+	}
+
+	tui.PrintCLIInfo("🎉 已重新提交", "服务运行时将在下一次消费死信队列时重试该任务")
+}
+
+// DoDeadLetterDiscard 丢弃死信任务
+func DoDeadLetterDiscard(rawID string) {
+	repo := newQueueRepository()
+	deadLetter, ok := loadDeadLetter(repo, rawID)
+	if !ok {
+		return
+	}
+
+	deadLetter.Discard(time.Now())
+	if err := repo.UpdateDeadLetter(context.Background(), deadLetter); err != nil {
+		tui.PrintCLIInfo("😭 执行结果", "丢弃死信任务失败: "+err.Error())
+		return
+	}
+
+	tui.PrintCLIInfo("🎉 丢弃成功", fmt.Sprintf("死信任务 #%d 已丢弃", deadLetter.ID))
+}
+
+// DoDeadLetterPurge 清理已完成和已丢弃的死信任务
+func DoDeadLetterPurge() {
+	repo := newQueueRepository()
+	purged, err := repo.PurgeDeadLetters(
+		context.Background(),
+		queueModel.DeadLetterStatusCompleted,
+		queueModel.DeadLetterStatusDiscarded,
+	)
+	if err != nil {
+		tui.PrintCLIInfo("😭 执行结果", "清理死信任务失败: "+err.Error())
+		return
+	}
+
+	tui.PrintCLIInfo("🎉 清理成功", fmt.Sprintf("已删除 %d 条死信任务", purged))
+}
+
+// newQueueRepository 初始化数据库并创建死信队列仓储
+func newQueueRepository() queueRepository.QueueRepositoryInterface {
+	database.InitDatabase()
+	return queueRepository.NewQueueRepository(database.GetDB)
+}
+
+// loadDeadLetter 解析 ID 并加载死信任务，失败时打印原因
+func loadDeadLetter(
+	repo queueRepository.QueueRepositoryInterface,
+	rawID string,
+) (*queueModel.DeadLetter, bool) {
+	id, err := strconv.ParseInt(rawID, 10, 64)
+	if err != nil {
+		tui.PrintCLIInfo("😭 执行结果", "无效的死信任务 ID: "+rawID)
+		return nil, false
+	}
+
+	deadLetter, err := repo.GetDeadLetterByID(id)
+	if err != nil {
+		tui.PrintCLIInfo("😭 执行结果", "获取死信任务失败: "+err.Error())
+		return nil, false
+	}
+	if deadLetter == nil {
+		tui.PrintCLIInfo("😭 执行结果", fmt.Sprintf("死信任务 #%d 不存在", id))
+		return nil, false
+	}
+
+	return deadLetter, true
+}
+
+// truncate 截断过长的字符串
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max]) + "..."
+}
@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/lin-snow/ech0/internal/cache"
+	"github.com/lin-snow/ech0/internal/database"
+	"github.com/lin-snow/ech0/internal/di"
+	"github.com/lin-snow/ech0/internal/event"
+	model "github.com/lin-snow/ech0/internal/model/exporter"
+	"github.com/lin-snow/ech0/internal/transaction"
+	"github.com/lin-snow/ech0/internal/tui"
+)
+
+// DoExport 导出全部 Echo 到文件
+func DoExport(format, output string) {
+	if !model.IsValidExportFormat(format) {
+		tui.PrintCLIInfo("😭 执行结果", "不支持的导出格式: "+format)
+		return
+	}
+	if output == "" {
+		output = model.NewExportFile(format, time.Now()).Filename
+	}
+
+	database.InitDatabase()
+	event.SetEventBus(event.NewEventBus())
+
+	exportService, err := di.BuildExporter(
+		database.GetDB,
+		cache.NewCacheFactory(),
+		transaction.NewTransactionManagerFactory(database.GetDB),
+		event.GetEventBus,
+	)
+	if err != nil {
+		tui.PrintCLIInfo("😭 执行结果", "初始化导出服务失败: "+err.Error())
+		return
+	}
+
+	file, err := os.Create(output)
+	if err != nil {
+		tui.PrintCLIInfo("😭 执行结果", "创建导出文件失败: "+err.Error())
+		return
+	}
+
+	start := time.Now()
+	err = exportService.Export(context.Background(), format, file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(output)
+		tui.PrintCLIInfo("😭 执行结果", "导出失败: "+err.Error())
+		return
+	}
+
+	tui.PrintCLIInfo(
+		"🎉 导出成功",
+		fmt.Sprintf("已导出到 %s，耗时 %s", output, time.Since(start).Round(time.Millisecond)),
+	)
+}
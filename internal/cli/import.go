@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lin-snow/ech0/internal/cache"
+	"github.com/lin-snow/ech0/internal/database"
+	"github.com/lin-snow/ech0/internal/di"
+	"github.com/lin-snow/ech0/internal/event"
+	model "github.com/lin-snow/ech0/internal/model/importer"
+	userRepository "github.com/lin-snow/ech0/internal/repository/user"
+	"github.com/lin-snow/ech0/internal/transaction"
+	"github.com/lin-snow/ech0/internal/tui"
+)
+
+// DoImport 从其他平台的导出数据导入 Echo，导入的内容归属于系统管理员
+func DoImport(path string, options model.ImportOptions) {
+	database.InitDatabase()
+
+	// 导入不会推送到联邦网络，使用内存事件总线即可
+	event.SetEventBus(event.NewEventBus())
+
+	cacheFactory := cache.NewCacheFactory()
+	admin, err := userRepository.NewUserRepository(database.GetDB, cacheFactory.Cache()).GetSysAdmin()
+	if err != nil {
+		tui.PrintCLIInfo("😭 执行结果", "获取系统管理员失败: "+err.Error())
+		return
+	}
+
+	importService, err := di.BuildImporter(
+		database.GetDB,
+		cacheFactory,
+		transaction.NewTransactionManagerFactory(database.GetDB),
+		event.GetEventBus,
+	)
+	if err != nil {
+		tui.PrintCLIInfo("😭 执行结果", "初始化导入服务失败: "+err.Error())
+		return
+	}
+
+	progress, err := importService.Import(admin.ID, path, options, func(p model.ImportProgress) {
+		if p.Total > 0 && p.Status == model.ImportStatusRunning {
+			fmt.Printf("\r导入进度: %d/%d", p.Processed, p.Total)
+		}
+	})
+	if progress.Total > 0 {
+		fmt.Println()
+	}
+	if err != nil {
+		tui.PrintCLIInfo("😭 执行结果", "导入失败: "+err.Error())
+		return
+	}
+
+	title := "🎉 导入完成"
+	if progress.DryRun {
+		title = "🔍 试运行完成"
+	}
+	summary := fmt.Sprintf(
+		"共 %d 条，导入 %d 条，跳过 %d 条，失败 %d 条；媒体导入 %d 个，失败 %d 个",
+		progress.Total,
+		progress.Imported,
+		progress.Skipped,
+		progress.Failed,
+		progress.MediaImported,
+		progress.MediaFailed,
+	)
+	if len(progress.Errors) > 0 {
+		summary += "\n\n" + strings.Join(progress.Errors, "\n")
+	}
+	tui.PrintCLIInfo(title, summary)
+}
@@ -0,0 +1,33 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/lin-snow/ech0/internal/database"
+	"github.com/lin-snow/ech0/internal/tui"
+)
+
+// DoReindex 重建 Echo 全文索引
+func DoReindex() {
+	database.InitDatabase()
+	if !database.SearchIndexEnabled() {
+		tui.PrintCLIInfo(
+			"😭 执行结果",
+			"当前数据库不支持全文索引（仅支持启用 FTS5 的 SQLite），搜索将使用模糊匹配",
+		)
+		return
+	}
+
+	start := time.Now()
+	count, err := database.RebuildSearchIndex(database.GetDB())
+	if err != nil {
+		tui.PrintCLIInfo("😭 执行结果", "重建全文索引失败: "+err.Error())
+		return
+	}
+
+	tui.PrintCLIInfo(
+		"🎉 重建成功",
+		fmt.Sprintf("已索引 %d 条 Echo，耗时 %s", count, time.Since(start).Round(time.Millisecond)),
+	)
+}
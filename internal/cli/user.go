@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lin-snow/ech0/internal/cache"
+	"github.com/lin-snow/ech0/internal/database"
+	userRepository "github.com/lin-snow/ech0/internal/repository/user"
+	"github.com/lin-snow/ech0/internal/transaction"
+	"github.com/lin-snow/ech0/internal/tui"
+)
+
+// DoUserDisableTwoFactor 关闭指定用户的两步验证，用于丢失认证器与恢复码时找回账号
+// 被管理员要求使用两步验证的用户下次使用密码登录时需要重新绑定认证器
+func DoUserDisableTwoFactor(username string) {
+	database.InitDatabase()
+	repo := userRepository.NewUserRepository(database.GetDB, cache.NewCacheFactory().Cache())
+
+	user, err := repo.GetUserByUsername(username)
+	if err != nil {
+		tui.PrintCLIInfo("😭 执行结果", "用户不存在: "+username)
+		return
+	}
+
+	twoFactor, err := repo.GetTwoFactorByUserID(user.ID)
+	if err != nil {
+		tui.PrintCLIInfo("😭 执行结果", "获取两步验证配置失败: "+err.Error())
+		return
+	}
+	if twoFactor == nil {
+		tui.PrintCLIInfo("⚠️ 执行结果", fmt.Sprintf("用户 %s 未启用两步验证", user.Username))
+		return
+	}
+
+	if err := transaction.NewTransactionManager(database.GetDB).Run(func(ctx context.Context) error {
+		return repo.DeleteTwoFactor(ctx, user.ID)
+	}); err != nil {
+		tui.PrintCLIInfo("😭 执行结果", "关闭两步验证失败: "+err.Error())
+		return
+	}
+
+	msg := fmt.Sprintf("已关闭用户 %s 的两步验证，并作废全部恢复码", user.Username)
+	if user.TwoFactorRequired {
+		msg += "，该用户下次登录时需要重新绑定认证器"
+	}
+	tui.PrintCLIInfo("🎉 关闭成功", msg)
+}
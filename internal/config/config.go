@@ -8,8 +8,10 @@ import (
 	_ "embed"
 	"encoding/hex"
 	"encoding/pem"
+	"fmt"
 	"log"
 	"os"
+	"strings"
 
 	model "github.com/lin-snow/ech0/internal/model/common"
 	"github.com/spf13/viper"
@@ -39,18 +41,28 @@ type AppConfig struct {
 		Port string `yaml:"port"` // 服务器端口
 		Host string `yaml:"host"` // 服务器主机地址
 		Mode string `yaml:"mode"` // 运行模式，可能的值为 "debug" 或 "release"
+		// 可信反向代理的 IP 或 CIDR，仅来自这些地址的请求才会采用 X-Forwarded-For 中的客户端 IP
+		TrustedProxies []string `yaml:"trustedproxies"`
 	} `yaml:"server"`
 	Database struct {
-		Type    string `yaml:"type"`    // 数据库类型
-		Path    string `yaml:"path"`    // 数据库文件路径
+		Type    string `yaml:"type"`    // 数据库类型，可能的值为 "sqlite"、"postgres" 或 "mysql"
+		Path    string `yaml:"path"`    // SQLite 数据库文件路径
+		DSN     string `yaml:"dsn"`     // PostgreSQL / MySQL 连接串
 		LogMode string `yaml:"logmode"` // 数据库日志模式
 	} `yaml:"database"`
 	Auth struct {
 		Jwt struct {
-			Expires  int    `yaml:"expires"`  // JWT的过期时间，单位为秒
-			Issuer   string `yaml:"issuer"`   // JWT的发行者
-			Audience string `yaml:"audience"` // JWT的受众
+			Expires       int    `yaml:"expires"`       // 登录会话（刷新令牌）的有效期，单位为秒
+			AccessExpires int    `yaml:"accessexpires"` // 登录访问令牌（JWT）的有效期，单位为秒
+			Issuer        string `yaml:"issuer"`        // JWT的发行者
+			Audience      string `yaml:"audience"`      // JWT的受众
 		} `yaml:"jwt"`
+		MaxUsers int `yaml:"maxusers"` // 最大用户数量，0 表示不限制
+		Lockout  struct {
+			Threshold int `yaml:"threshold"` // 同一用户名连续登录失败多少次后开始锁定，0 表示不锁定
+			BaseDelay int `yaml:"basedelay"` // 首次锁定时长，之后每次失败翻倍，单位为秒
+			MaxDelay  int `yaml:"maxdelay"`  // 最长锁定时长，单位为秒
+		} `yaml:"lockout"`
 	} `yaml:"auth"`
 	Upload struct {
 		ImageMaxSize int      `yaml:"imagemaxsize"` // 图片文件的最大上传大小，单位为字节
@@ -82,27 +94,73 @@ type AppConfig struct {
 		Host string `yaml:"host"` // SSH 主机地址
 		Key  string `yaml:"key"`  // SSH 私钥路径
 	} `yaml:"ssh"`
+	DeadLetter struct {
+		MaxRetries     int     `yaml:"maxretries"`     // 最大重试次数，超过后标记为失败
+		InitialBackoff int     `yaml:"initialbackoff"` // 首次重试间隔，单位为秒
+		MaxBackoff     int     `yaml:"maxbackoff"`     // 最大重试间隔，单位为秒
+		Multiplier     float64 `yaml:"multiplier"`     // 每次重试间隔的增长倍数
+	} `yaml:"deadletter"`
+	EventBus struct {
+		Type           string `yaml:"type"`           // 事件总线类型，可能的值为 "memory" 或 "persistent"
+		PollInterval   int    `yaml:"pollinterval"`   // 持久化事件总线的轮询间隔，单位为秒
+		BatchSize      int    `yaml:"batchsize"`      // 每次读取的事件数量
+		MaxRetries     int    `yaml:"maxretries"`     // 单个事件处理失败后的最大重试次数，超过后写入死信队列
+		InitialBackoff int    `yaml:"initialbackoff"` // 首次重试间隔，单位为秒
+		MaxBackoff     int    `yaml:"maxbackoff"`     // 最大重试间隔，单位为秒
+		Retention      int    `yaml:"retention"`      // 已处理事件的保留时长，单位为秒
+		GapGrace       int    `yaml:"gapgrace"`       // 事件序号出现空缺时等待未提交事务的时长，单位为秒
+	} `yaml:"eventbus"`
+	Trash struct {
+		RetentionDays int `yaml:"retentiondays"` // 回收站中的内容保留天数，超过后彻底删除，0 表示不自动清理
+	} `yaml:"trash"`
+	RateLimit struct {
+		Enable      bool          `yaml:"enable"`      // 是否启用请求限流
+		Global      RateLimitRule `yaml:"global"`      // 所有 API 请求，按客户端 IP 限流
+		User        RateLimitRule `yaml:"user"`        // 需要登录的 API 请求，按用户限流
+		Auth        RateLimitRule `yaml:"auth"`        // 登录、注册、Passkey 与两步验证等认证接口，按客户端 IP 限流
+		Refresh     RateLimitRule `yaml:"refresh"`     // 刷新访问令牌接口，按客户端 IP 限流
+		Interaction RateLimitRule `yaml:"interaction"` // 点赞等匿名互动接口，按客户端 IP 限流
+	} `yaml:"ratelimit"`
+}
+
+// RateLimitRule 令牌桶限流规则，Rate 或 Burst 为 0 表示不限流
+type RateLimitRule struct {
+	Rate  float64 `yaml:"rate"`  // 每秒补充的请求数
+	Burst int     `yaml:"burst"` // 允许的最大突发请求数
 }
 
 //go:embed config.yaml
 var configData []byte
 
-// LoadAppConfig 加载应用程序配置
-func LoadAppConfig() {
-	// viper.SetConfigFile("config/config.yaml")
-	viper.SetConfigType("yaml")
-	// 使用嵌入的配置数据而不是从文件系统读取
-	err := viper.ReadConfig(bytes.NewReader(configData))
+const (
+	EnvPrefix     = "ECH0"        // 环境变量前缀，如 ECH0_SERVER_PORT 覆盖 server.port
+	ConfigFileEnv = "ECH0_CONFIG" // 指定外部配置文件路径的环境变量
+)
+
+// DefaultConfigPaths 未指定配置文件时依次查找的外部配置文件路径
+var DefaultConfigPaths = []string{
+	"data/config.yaml",
+	"config/config.yaml",
+	"/etc/ech0/config.yaml",
+}
+
+// ConfigFileUsed 实际加载的外部配置文件路径，为空表示仅使用内置配置
+var ConfigFileUsed string
+
+// LoadAppConfig 加载应用程序配置，configFile 为空时使用 ECH0_CONFIG 或默认查找路径
+func LoadAppConfig(configFile string) {
+	cfg, path, err := ReadAppConfig(configFile)
 	if err != nil {
-		panic(model.READ_CONFIG_PANIC + ":" + err.Error())
+		log.Fatalf("%s: %v", model.READ_CONFIG_PANIC, err)
 	}
 
-	// 将配置文件内容反序列化到结构体 Config 中
-	err = viper.Unmarshal(&Config)
-	if err != nil {
-		panic(model.READ_CONFIG_PANIC + ":" + err.Error())
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("%s:\n%v", model.INVALID_CONFIG_PANIC, err)
 	}
 
+	Config = cfg
+	ConfigFileUsed = path
+
 	// 初始化 JWT_SECRET
 	JWT_SECRET = GetJWTSecret()
 
@@ -110,6 +168,70 @@ func LoadAppConfig() {
 	GenSecretKey()
 }
 
+// ReadAppConfig 读取并合并配置，不做校验
+//
+// 合并顺序（后者覆盖前者）: 内置 config.yaml -> 外部配置文件 -> ECH0_ 前缀的环境变量。
+// 返回合并后的配置以及实际加载的外部配置文件路径。
+func ReadAppConfig(configFile string) (AppConfig, string, error) {
+	v := viper.New()
+	v.SetConfigType("yaml")
+
+	// 使用嵌入的配置数据作为默认值
+	if err := v.ReadConfig(bytes.NewReader(configData)); err != nil {
+		return AppConfig{}, "", err
+	}
+
+	path, err := resolveConfigFile(configFile)
+	if err != nil {
+		return AppConfig{}, "", err
+	}
+	if path != "" {
+		v.SetConfigFile(path)
+		if err := v.MergeInConfig(); err != nil {
+			return AppConfig{}, "", fmt.Errorf("解析配置文件 %s 失败: %w", path, err)
+		}
+	}
+
+	// 环境变量覆盖，键名中的 "." 替换为 "_"
+	v.SetEnvPrefix(EnvPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+
+	// 将配置文件内容反序列化到结构体中
+	var cfg AppConfig
+	if err := v.Unmarshal(&cfg); err != nil {
+		return AppConfig{}, "", err
+	}
+
+	return cfg, path, nil
+}
+
+// resolveConfigFile 确定要加载的外部配置文件，显式指定的文件不存在时返回错误
+func resolveConfigFile(configFile string) (string, error) {
+	if configFile == "" {
+		configFile = os.Getenv(ConfigFileEnv)
+	}
+
+	if configFile != "" {
+		st, err := os.Stat(configFile)
+		if err != nil {
+			return "", fmt.Errorf("配置文件 %s 不存在: %w", configFile, err)
+		}
+		if st.IsDir() {
+			return "", fmt.Errorf("配置文件 %s 是一个目录", configFile)
+		}
+		return configFile, nil
+	}
+
+	for _, path := range DefaultConfigPaths {
+		if st, err := os.Stat(path); err == nil && !st.IsDir() {
+			return path, nil
+		}
+	}
+
+	return "", nil
+}
+
 // GetJWTSecret 加载JWT密钥
 func GetJWTSecret() []byte {
 	// 从环境变量中获取JWT密钥
@@ -0,0 +1,195 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeConfig 在临时目录中写入外部配置文件
+func writeConfig(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	return path
+}
+
+// isolate 切换到空的临时目录并清除配置相关的环境变量，避免读取到开发环境中的配置
+func isolate(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Chdir(dir)
+	t.Setenv(ConfigFileEnv, "")
+	for _, env := range os.Environ() {
+		if key, _, _ := strings.Cut(env, "="); strings.HasPrefix(key, EnvPrefix+"_") {
+			t.Setenv(key, "")
+			os.Unsetenv(key)
+		}
+	}
+	return dir
+}
+
+func TestReadAppConfigMergeOrder(t *testing.T) {
+	dir := isolate(t)
+
+	// 只有内置配置
+	cfg, path, err := ReadAppConfig("")
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if path != "" || cfg.Server.Port != "6277" || cfg.Database.Type != "sqlite" {
+		t.Fatalf("defaults: path %q port %q db %q", path, cfg.Server.Port, cfg.Database.Type)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("built-in config is invalid: %v", err)
+	}
+
+	// 默认路径下的外部配置文件覆盖内置配置，未出现的配置项保留默认值
+	writeConfig(t, dir, "data/config.yaml", "server:\n  port: 7000\ndatabase:\n  path: other.db\n")
+	cfg, path, err = ReadAppConfig("")
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if path != "data/config.yaml" || cfg.Server.Port != "7000" || cfg.Database.Path != "other.db" {
+		t.Errorf("file: path %q port %q db path %q", path, cfg.Server.Port, cfg.Database.Path)
+	}
+	if cfg.Server.Mode != "release" || cfg.Auth.Jwt.Issuer != "ech0" {
+		t.Errorf("defaults lost: mode %q issuer %q", cfg.Server.Mode, cfg.Auth.Jwt.Issuer)
+	}
+
+	// 环境变量覆盖外部配置文件
+	t.Setenv("ECH0_SERVER_PORT", "8000")
+	t.Setenv("ECH0_AUTH_JWT_ISSUER", "env")
+	cfg, _, err = ReadAppConfig("")
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if cfg.Server.Port != "8000" || cfg.Auth.Jwt.Issuer != "env" || cfg.Database.Path != "other.db" {
+		t.Errorf("env: port %q issuer %q db path %q", cfg.Server.Port, cfg.Auth.Jwt.Issuer, cfg.Database.Path)
+	}
+}
+
+func TestResolveConfigFile(t *testing.T) {
+	dir := isolate(t)
+	explicit := writeConfig(t, dir, "custom.yaml", "server:\n  port: 7100\n")
+	fromEnv := writeConfig(t, dir, "env.yaml", "server:\n  port: 7200\n")
+	writeConfig(t, dir, "config/config.yaml", "server:\n  port: 7300\n")
+
+	tests := map[string]struct {
+		flag    string
+		env     string
+		want    string
+		wantErr bool
+	}{
+		"默认路径":         {want: "config/config.yaml"},
+		"环境变量指定":       {env: fromEnv, want: fromEnv},
+		"参数优先于环境变量":    {flag: explicit, env: fromEnv, want: explicit},
+		"指定的文件不存在":     {flag: filepath.Join(dir, "missing.yaml"), wantErr: true},
+		"环境变量指定的文件不存在": {env: filepath.Join(dir, "missing.yaml"), wantErr: true},
+		"指定的是目录":       {flag: dir, wantErr: true},
+	}
+
+	for name, tt := range tests {
+		t.Setenv(ConfigFileEnv, tt.env)
+		got, err := resolveConfigFile(tt.flag)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v, wantErr %v", name, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: path = %q, want %q", name, got, tt.want)
+		}
+	}
+}
+
+func TestReadAppConfigRejectsMalformedFile(t *testing.T) {
+	dir := isolate(t)
+	path := writeConfig(t, dir, "broken.yaml", "server: [\n")
+	if _, _, err := ReadAppConfig(path); err == nil {
+		t.Error("expected malformed config file to be rejected")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	isolate(t)
+	base, _, err := ReadAppConfig("")
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+
+	tests := map[string]struct {
+		mutate func(c *AppConfig)
+		want   string // 错误信息中应包含的配置项，为空表示合法
+	}{
+		"内置配置":           {mutate: func(c *AppConfig) {}},
+		"端口不是数字":         {mutate: func(c *AppConfig) { c.Server.Port = "http" }, want: "server.port"},
+		"端口超出范围":         {mutate: func(c *AppConfig) { c.Server.Port = "70000" }, want: "server.port"},
+		"运行模式":           {mutate: func(c *AppConfig) { c.Server.Mode = "prod" }, want: "server.mode"},
+		"代理地址":           {mutate: func(c *AppConfig) { c.Server.TrustedProxies = []string{"proxy"} }, want: "server.trustedproxies"},
+		"代理网段":           {mutate: func(c *AppConfig) { c.Server.TrustedProxies = []string{"10.0.0.0/8", "::1"} }},
+		"数据库类型":          {mutate: func(c *AppConfig) { c.Database.Type = "oracle" }, want: "database.type"},
+		"sqlite 缺少路径":    {mutate: func(c *AppConfig) { c.Database.Path = " " }, want: "database.path"},
+		"postgres 缺少连接串": {mutate: func(c *AppConfig) { c.Database.Type = "postgres" }, want: "database.dsn"},
+		"mysql 配置连接串": {mutate: func(c *AppConfig) {
+			c.Database.Type = "mysql"
+			c.Database.DSN = "ech0:ech0@tcp(localhost:3306)/ech0"
+		}},
+		"令牌有效期":     {mutate: func(c *AppConfig) { c.Auth.Jwt.AccessExpires = 0 }, want: "auth.jwt.accessexpires"},
+		"死信重试倍数":    {mutate: func(c *AppConfig) { c.DeadLetter.Multiplier = 0.5 }, want: "deadletter.multiplier"},
+		"事件总线类型":    {mutate: func(c *AppConfig) { c.EventBus.Type = "kafka" }, want: "eventbus.type"},
+		"回收站保留天数":   {mutate: func(c *AppConfig) { c.Trash.RetentionDays = -1 }, want: "trash.retentiondays"},
+		"限流速率":      {mutate: func(c *AppConfig) { c.RateLimit.Auth.Rate = -1 }, want: "ratelimit.auth.rate"},
+		"允许的上传类型为空": {mutate: func(c *AppConfig) { c.Upload.AllowedTypes = nil }, want: "upload.allowedtypes"},
+	}
+
+	for name, tt := range tests {
+		cfg := base
+		cfg.Server.TrustedProxies = append([]string(nil), base.Server.TrustedProxies...)
+		tt.mutate(&cfg)
+		err := cfg.Validate()
+		if tt.want == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error %v", name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: err = %v, want mention of %s", name, err, tt.want)
+		}
+	}
+
+	// 一次返回所有不合法的配置项
+	cfg := base
+	cfg.Server.Port = ""
+	cfg.SSH.Port = ""
+	err = cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "server.port") || !strings.Contains(err.Error(), "ssh.port") {
+		t.Errorf("err = %v, want both ports reported", err)
+	}
+}
+
+func TestRedactedYAML(t *testing.T) {
+	var cfg AppConfig
+	cfg.Server.Port = "6277"
+	cfg.Database.DSN = "host=db password=hunter2"
+	cfg.SSH.Key = "data/ssh_key"
+
+	out, err := cfg.RedactedYAML()
+	if err != nil {
+		t.Fatalf("redact: %v", err)
+	}
+	text := string(out)
+	if strings.Contains(text, "hunter2") || !strings.Contains(text, "dsn: '******'") {
+		t.Errorf("dsn not redacted:\n%s", text)
+	}
+	// 未配置的敏感项保持为空，普通配置项原样输出
+	if !strings.Contains(text, `port: "6277"`) || !strings.Contains(text, "key: data/ssh_key") {
+		t.Errorf("unexpected output:\n%s", text)
+	}
+}
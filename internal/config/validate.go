@@ -0,0 +1,172 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SupportedDatabaseTypes 支持的数据库类型
+var SupportedDatabaseTypes = []string{"sqlite", "postgres", "mysql"}
+
+// redactedKeywords 键名包含这些关键字的配置项在输出时会被隐藏
+var redactedKeywords = []string{"secret", "password", "passwd", "token", "dsn"}
+
+const redactedValue = "******"
+
+// Validate 校验配置的合法性，返回所有不合法的配置项
+func (c *AppConfig) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(isValidPort(c.Server.Port), "server.port 必须是 1-65535 之间的端口号，当前为 %q", c.Server.Port)
+	check(
+		c.Server.Mode == "debug" || c.Server.Mode == "release",
+		"server.mode 只能是 debug 或 release，当前为 %q", c.Server.Mode,
+	)
+	for _, proxy := range c.Server.TrustedProxies {
+		check(isValidProxy(proxy), "server.trustedproxies 中的 %q 不是合法的 IP 或 CIDR", proxy)
+	}
+
+	check(
+		slices.Contains(SupportedDatabaseTypes, c.Database.Type),
+		"database.type 只能是 %s，当前为 %q", strings.Join(SupportedDatabaseTypes, "/"), c.Database.Type,
+	)
+	if c.Database.Type == "sqlite" {
+		check(strings.TrimSpace(c.Database.Path) != "", "database.path 不能为空")
+	} else {
+		check(
+			strings.TrimSpace(c.Database.DSN) != "",
+			"database.type 为 %s 时 database.dsn 不能为空", c.Database.Type,
+		)
+	}
+	check(
+		c.Database.LogMode == "debug" || c.Database.LogMode == "release",
+		"database.logmode 只能是 debug 或 release，当前为 %q", c.Database.LogMode,
+	)
+
+	check(c.Auth.Jwt.Expires > 0, "auth.jwt.expires 必须大于 0，当前为 %d", c.Auth.Jwt.Expires)
+	check(
+		c.Auth.Jwt.AccessExpires > 0,
+		"auth.jwt.accessexpires 必须大于 0，当前为 %d", c.Auth.Jwt.AccessExpires,
+	)
+	check(c.Auth.Jwt.Issuer != "", "auth.jwt.issuer 不能为空")
+	check(c.Auth.Jwt.Audience != "", "auth.jwt.audience 不能为空")
+	check(c.Auth.MaxUsers >= 0, "auth.maxusers 不能小于 0，当前为 %d", c.Auth.MaxUsers)
+	check(c.Auth.Lockout.Threshold >= 0, "auth.lockout.threshold 不能为负数")
+	check(c.Auth.Lockout.BaseDelay >= 0, "auth.lockout.basedelay 不能为负数")
+	check(c.Auth.Lockout.MaxDelay >= 0, "auth.lockout.maxdelay 不能为负数")
+
+	check(c.Upload.ImageMaxSize > 0, "upload.imagemaxsize 必须大于 0，当前为 %d", c.Upload.ImageMaxSize)
+	check(c.Upload.AudioMaxSize > 0, "upload.audiomaxsize 必须大于 0，当前为 %d", c.Upload.AudioMaxSize)
+	check(c.Upload.ModelMaxSize > 0, "upload.modelmaxsize 必须大于 0，当前为 %d", c.Upload.ModelMaxSize)
+	check(c.Upload.ImagePath != "", "upload.imagepath 不能为空")
+	check(c.Upload.AudioPath != "", "upload.audiopath 不能为空")
+	check(c.Upload.ModelPath != "", "upload.modelpath 不能为空")
+	check(len(c.Upload.AllowedTypes) > 0, "upload.allowedtypes 不能为空")
+
+	check(isValidPort(c.SSH.Port), "ssh.port 必须是 1-65535 之间的端口号，当前为 %q", c.SSH.Port)
+	check(c.SSH.Key != "", "ssh.key 不能为空")
+
+	check(c.DeadLetter.MaxRetries >= 0, "deadletter.maxretries 不能为负数")
+	check(c.DeadLetter.InitialBackoff >= 0, "deadletter.initialbackoff 不能为负数")
+	check(c.DeadLetter.MaxBackoff >= 0, "deadletter.maxbackoff 不能为负数")
+	check(
+		c.DeadLetter.Multiplier == 0 || c.DeadLetter.Multiplier >= 1,
+		"deadletter.multiplier 必须不小于 1，当前为 %v", c.DeadLetter.Multiplier,
+	)
+
+	check(
+		c.EventBus.Type == "" || c.EventBus.Type == "memory" || c.EventBus.Type == "persistent",
+		"eventbus.type 只能是 memory 或 persistent，当前为 %q", c.EventBus.Type,
+	)
+	check(c.EventBus.PollInterval >= 0, "eventbus.pollinterval 不能为负数")
+	check(c.EventBus.BatchSize >= 0, "eventbus.batchsize 不能为负数")
+	check(c.EventBus.MaxRetries >= 0, "eventbus.maxretries 不能为负数")
+	check(c.EventBus.InitialBackoff >= 0, "eventbus.initialbackoff 不能为负数")
+	check(c.EventBus.MaxBackoff >= 0, "eventbus.maxbackoff 不能为负数")
+	check(c.EventBus.Retention >= 0, "eventbus.retention 不能为负数")
+	check(c.EventBus.GapGrace >= 0, "eventbus.gapgrace 不能为负数")
+
+	check(c.Trash.RetentionDays >= 0, "trash.retentiondays 不能为负数")
+
+	rules := []struct {
+		name string
+		rule RateLimitRule
+	}{
+		{"global", c.RateLimit.Global},
+		{"user", c.RateLimit.User},
+		{"auth", c.RateLimit.Auth},
+		{"refresh", c.RateLimit.Refresh},
+		{"interaction", c.RateLimit.Interaction},
+	}
+	for _, r := range rules {
+		check(r.rule.Rate >= 0, "ratelimit.%s.rate 不能为负数", r.name)
+		check(r.rule.Burst >= 0, "ratelimit.%s.burst 不能为负数", r.name)
+	}
+
+	return errors.Join(errs...)
+}
+
+// RedactedYAML 以 YAML 输出配置，敏感配置项会被隐藏
+func (c *AppConfig) RedactedYAML() ([]byte, error) {
+	raw, err := yaml.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+
+	var tree map[string]any
+	if err := yaml.Unmarshal(raw, &tree); err != nil {
+		return nil, err
+	}
+	redact(tree)
+
+	return yaml.Marshal(tree)
+}
+
+// redact 递归隐藏敏感配置项
+func redact(tree map[string]any) {
+	for key, value := range tree {
+		if sub, ok := value.(map[string]any); ok {
+			redact(sub)
+			continue
+		}
+		if isSensitiveKey(key) && value != nil && value != "" {
+			tree[key] = redactedValue
+		}
+	}
+}
+
+// isSensitiveKey 判断配置项是否为敏感信息
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, keyword := range redactedKeywords {
+		if strings.Contains(key, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// isValidProxy 判断是否为合法的 IP 或 CIDR
+func isValidProxy(proxy string) bool {
+	if _, _, err := net.ParseCIDR(proxy); err == nil {
+		return true
+	}
+	return net.ParseIP(proxy) != nil
+}
+
+// isValidPort 判断是否为合法端口号
+func isValidPort(port string) bool {
+	n, err := strconv.Atoi(port)
+	return err == nil && n > 0 && n <= 65535
+}
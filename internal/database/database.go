@@ -2,11 +2,14 @@ package database
 
 import (
 	"errors"
+	"fmt"
 	"os"
+	"path/filepath"
 	"runtime"
 	"sync/atomic"
 	"time"
 
+	mysqlDriver "github.com/go-sql-driver/mysql"
 	"github.com/lin-snow/ech0/internal/config"
 	authModel "github.com/lin-snow/ech0/internal/model/auth"
 	commonModel "github.com/lin-snow/ech0/internal/model/common"
@@ -20,6 +23,8 @@ import (
 	userModel "github.com/lin-snow/ech0/internal/model/user"
 	webhookModel "github.com/lin-snow/ech0/internal/model/webhook"
 	util "github.com/lin-snow/ech0/internal/util/err"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -33,10 +38,28 @@ var db atomic.Value // 用于存储 *gorm.DB
 
 var writeLocked atomic.Bool
 
+// 支持的数据库类型
+const (
+	DatabaseTypeSQLite   = "sqlite"
+	DatabaseTypePostgres = "postgres"
+	DatabaseTypeMySQL    = "mysql"
+)
+
 func GetDB() *gorm.DB {
 	return db.Load().(*gorm.DB)
 }
 
+// IsInitialized 判断数据库连接是否已初始化
+func IsInitialized() bool {
+	d, ok := db.Load().(*gorm.DB)
+	return ok && d != nil
+}
+
+// IsSQLite 判断当前配置的数据库是否为 SQLite
+func IsSQLite() bool {
+	return config.Config.Database.Type == DatabaseTypeSQLite
+}
+
 func SetDB(newDB *gorm.DB) {
 	db.Store(newDB)
 }
@@ -67,36 +90,30 @@ func IsWriteLocked() bool {
 
 // InitDatabase 初始化数据库连接
 func InitDatabase() {
-	// 读取数据库类型和保存路径
+	// 读取数据库类型和连接信息，SQLite 使用文件路径，其他数据库使用 DSN
 	dbType := config.Config.Database.Type
-	dbPath := config.Config.Database.Path
-
-	dir := dbPath[:len(dbPath)-len("/ech0.db")] // 提取目录部分
-	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
-		util.HandlePanicError(&commonModel.ServerError{
-			Msg: commonModel.CREATE_DB_PATH_PANIC,
-			Err: err,
-		})
-	}
+	dsn := config.Config.Database.DSN
 
-	if dbType == "sqlite" {
-		var err error
-		ll := logger.LogLevel(logger.Error)
-		if config.Config.Database.LogMode == "release" {
-			ll = logger.LogLevel(logger.Silent)
-		}
-		SQLiteDB, err := gorm.Open(sqlite.Open(dbPath), &gorm.Config{
-			Logger: logger.Default.LogMode(ll),
-		})
-		if err != nil {
+	if dbType == DatabaseTypeSQLite {
+		dsn = config.Config.Database.Path
+		dir := filepath.Dir(dsn) // 提取目录部分
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
 			util.HandlePanicError(&commonModel.ServerError{
-				Msg: commonModel.INIT_DATABASE_PANIC,
+				Msg: commonModel.CREATE_DB_PATH_PANIC,
 				Err: err,
 			})
 		}
-		SetDB(SQLiteDB)
 	}
 
+	newDB, err := openDatabase(dbType, dsn)
+	if err != nil {
+		util.HandlePanicError(&commonModel.ServerError{
+			Msg: commonModel.INIT_DATABASE_PANIC,
+			Err: err,
+		})
+	}
+	SetDB(newDB)
+
 	// 自动建表
 	if err := MigrateDB(); err != nil {
 		util.HandlePanicError(&commonModel.ServerError{
@@ -114,9 +131,39 @@ func InitDatabase() {
 	}
 }
 
-// MigrateDB 执行数据库迁移
-func MigrateDB() error {
-	models := []interface{}{
+// openDatabase 按数据库类型打开数据库连接
+func openDatabase(dbType, dsn string) (*gorm.DB, error) {
+	var dialector gorm.Dialector
+	switch dbType {
+	case DatabaseTypeSQLite:
+		dialector = sqlite.Open(dsn)
+	case DatabaseTypePostgres:
+		dialector = postgres.Open(dsn)
+	case DatabaseTypeMySQL:
+		// 时间字段需要 parseTime 才能正确扫描为 time.Time
+		mysqlConfig, err := mysqlDriver.ParseDSN(dsn)
+		if err != nil {
+			return nil, err
+		}
+		mysqlConfig.ParseTime = true
+		dialector = mysql.Open(mysqlConfig.FormatDSN())
+	default:
+		return nil, fmt.Errorf("unsupported database type: %s", dbType)
+	}
+
+	ll := logger.LogLevel(logger.Error)
+	if config.Config.Database.LogMode == "release" {
+		ll = logger.LogLevel(logger.Silent)
+	}
+
+	return gorm.Open(dialector, &gorm.Config{
+		Logger: logger.Default.LogMode(ll),
+	})
+}
+
+// Models 返回所有需要迁移和备份的数据模型
+func Models() []any {
+	return []any{
 		&userModel.User{},
 		&echoModel.Echo{},
 		&echoModel.Image{},
@@ -127,25 +174,47 @@ func MigrateDB() error {
 		&userModel.OAuthBinding{},
 		&echoModel.Tag{},
 		&echoModel.EchoTag{},
+		&echoModel.RemoteReply{},
+		&echoModel.EchoRevision{},
 		&webhookModel.Webhook{},
+		&webhookModel.WebhookDelivery{},
 		&queueModel.DeadLetter{},
+		&queueModel.OutboxEvent{},
+		&queueModel.EventCursor{},
 		&settingModel.AccessTokenSetting{},
 		&inboxModel.Inbox{},
 		&authModel.Passkey{},
+		&authModel.Session{},
+		&authModel.TwoFactor{},
+		&authModel.RecoveryCode{},
 
 		// Fediverse 相关
 		&fediverseModel.Follow{},
 		&fediverseModel.Follower{},
 		&fediverseModel.InboxStatus{},
+		&fediverseModel.Interaction{},
+		&fediverseModel.Tombstone{},
+	}
+}
+
+// MigrateDB 执行数据库迁移
+func MigrateDB() error {
+	if err := GetDB().AutoMigrate(
+		Models()...,
+	); err != nil {
+		return err
 	}
 
-	return GetDB().AutoMigrate(
-		models...,
-	)
+	// 创建全文索引（仅 SQLite）
+	return InitSearchIndex(GetDB())
 }
 
-// HotChangeDatabase 热切换数据库连接
+// HotChangeDatabase 热切换数据库连接，仅支持 SQLite 数据库文件
 func HotChangeDatabase(newDBPath string) error {
+	if !IsSQLite() {
+		return errors.New(commonModel.HOT_CHANGE_DATABASE_UNSUPPORTED)
+	}
+
 	// 获取当前数据库连接
 	oldDB := GetDB()
 
@@ -157,20 +226,18 @@ func HotChangeDatabase(newDBPath string) error {
 	}
 
 	// 打开新连接
-	ll := logger.LogLevel(logger.Error)
-	if config.Config.Database.LogMode == "release" {
-		ll = logger.LogLevel(logger.Silent)
-	}
-
-	newDB, err := gorm.Open(sqlite.Open(newDBPath), &gorm.Config{
-		Logger: logger.Default.LogMode(ll),
-	})
+	newDB, err := openDatabase(DatabaseTypeSQLite, newDBPath)
 	if err != nil {
 		return err
 	}
 
 	SetDB(newDB)
-	return nil
+
+	// 备份可能来自旧版本，需要补齐表结构、全文索引并执行数据修复任务
+	if err := MigrateDB(); err != nil {
+		return err
+	}
+	return UpdateMigration()
 }
 
 // CloseDatabaseFully 彻底关闭数据库连接，释放资源
@@ -0,0 +1,506 @@
+package database
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"time"
+
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	echoModel "github.com/lin-snow/ech0/internal/model/echo"
+	settingModel "github.com/lin-snow/ech0/internal/model/setting"
+	cryptoUtil "github.com/lin-snow/ech0/internal/util/crypto"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+const (
+	DumpFileName  = "ech0_dump.json" // 逻辑备份文件名，非 SQLite 数据库的备份中包含该文件
+	DumpVersion   = 2                // 逻辑备份格式版本
+	dumpBatchSize = 100              // 导入时每批写入的行数
+
+	// dumpVersionUnordered 表按名称排序的旧版本格式，导入时需要读入整个 tables 后再按模型顺序写入
+	dumpVersionUnordered = 1
+)
+
+// Dump 数据库逻辑备份，按表名保存每一行的列值，与具体数据库方言无关
+//
+// 从版本 2 开始，tables 中的表按 Models() 的顺序写出，导入时可以逐行读取并写入，无需将整个备份读入内存。
+type Dump struct {
+	Version   int                                     `json:"version"`    // 格式版本
+	Dialect   string                                  `json:"dialect"`    // 导出时的数据库类型
+	CreatedAt time.Time                               `json:"created_at"` // 导出时间
+	Tables    map[string][]map[string]json.RawMessage `json:"tables"`     // 表名 -> 行（列名 -> 值）
+}
+
+// ExportDump 将数据库中所有模型对应的表按 Models() 的顺序导出为 JSON，逐行读取与写出
+func ExportDump(db *gorm.DB, w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	dialect, _ := json.Marshal(db.Dialector.Name())
+	createdAt, _ := json.Marshal(time.Now().UTC())
+	if _, err := fmt.Fprintf(
+		bw,
+		`{"version":%d,"dialect":%s,"created_at":%s,"tables":{`,
+		DumpVersion, dialect, createdAt,
+	); err != nil {
+		return err
+	}
+
+	for i, model := range Models() {
+		if i > 0 {
+			if err := bw.WriteByte(','); err != nil {
+				return err
+			}
+		}
+		if err := exportDumpTable(db, model, bw); err != nil {
+			return err
+		}
+	}
+
+	if _, err := bw.WriteString("}}\n"); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// exportDumpTable 导出单个模型对应的表，写出 "表名":[行,...]
+func exportDumpTable(db *gorm.DB, model any, w *bufio.Writer) error {
+	s, err := parseSchema(db, model)
+	if err != nil {
+		return err
+	}
+
+	name, _ := json.Marshal(s.Table)
+	if _, err := fmt.Fprintf(w, "%s:[", name); err != nil {
+		return err
+	}
+
+	// 按模型类型逐行读取所有行（包括回收站中的数据），保证字段类型在导入时可以还原
+	rows, err := db.Unscoped().Model(model).Rows()
+	if err != nil {
+		return fmt.Errorf("export table %s: %w", s.Table, err)
+	}
+	defer rows.Close()
+
+	ctx := context.Background()
+	for first := true; rows.Next(); first = false {
+		row := reflect.New(s.ModelType)
+		if err := db.ScanRows(rows, row.Interface()); err != nil {
+			return fmt.Errorf("export table %s: %w", s.Table, err)
+		}
+
+		record := make(map[string]json.RawMessage, len(s.Fields))
+		for _, field := range s.Fields {
+			// 跳过关联字段以及 gorm:"-" 等不对应数据库列的字段
+			if field.DBName == "" || !field.Readable {
+				continue
+			}
+			raw, err := json.Marshal(field.ReflectValueOf(ctx, row.Elem()).Interface())
+			if err != nil {
+				return fmt.Errorf("export %s.%s: %w", s.Table, field.DBName, err)
+			}
+			record[field.DBName] = raw
+		}
+
+		data, err := json.Marshal(record)
+		if err != nil {
+			return fmt.Errorf("export table %s: %w", s.Table, err)
+		}
+		if !first {
+			if err := w.WriteByte(','); err != nil {
+				return err
+			}
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("export table %s: %w", s.Table, err)
+	}
+
+	return w.WriteByte(']')
+}
+
+// ImportDump 从 JSON 逻辑备份恢复数据，会先清空所有模型对应的表，整个过程在一个事务中完成
+//
+// 备份按流式方式读取，每读满一批行即写入数据库；旧版本（按表名排序）的备份需要读入全部表后按模型顺序写入。
+func ImportDump(db *gorm.DB, r io.Reader) error {
+	dec := json.NewDecoder(r)
+	if err := expectDumpDelim(dec, '{'); err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		version := 0
+		imported := false
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return err
+			}
+
+			switch key {
+			case "version":
+				if err := dec.Decode(&version); err != nil {
+					return err
+				}
+				if version != DumpVersion && version != dumpVersionUnordered {
+					return errors.New(commonModel.DATABASE_DUMP_VERSION_INVALID)
+				}
+			case "tables":
+				// 版本号位于 tables 之前，未读到版本号说明不是本程序导出的备份
+				if version == 0 {
+					return errors.New(commonModel.DATABASE_DUMP_VERSION_INVALID)
+				}
+				if err := truncateDumpTables(tx); err != nil {
+					return err
+				}
+				if version == dumpVersionUnordered {
+					err = importUnorderedDumpTables(tx, dec)
+				} else {
+					err = importDumpTables(tx, dec)
+				}
+				if err != nil {
+					return err
+				}
+				imported = true
+			default:
+				var skip json.RawMessage
+				if err := dec.Decode(&skip); err != nil {
+					return err
+				}
+			}
+		}
+		if !imported {
+			return errors.New(commonModel.DATABASE_DUMP_VERSION_INVALID)
+		}
+
+		// 逻辑备份中不包含全文索引，导入后重建
+		_, err := RebuildSearchIndex(tx)
+		return err
+	})
+}
+
+// truncateDumpTables 逆序清空所有模型对应的表，避免外键约束
+func truncateDumpTables(tx *gorm.DB) error {
+	models := Models()
+	for i := len(models) - 1; i >= 0; i-- {
+		if err := tx.Session(&gorm.Session{AllowGlobalUpdate: true}).
+			Unscoped().
+			Delete(models[i]).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// importDumpTables 按备份中的顺序逐表逐行导入，备份中未知的表会被跳过
+func importDumpTables(tx *gorm.DB, dec *json.Decoder) error {
+	schemas, err := dumpSchemas(tx)
+	if err != nil {
+		return err
+	}
+
+	if err := expectDumpDelim(dec, '{'); err != nil {
+		return err
+	}
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		table, _ := token.(string)
+
+		s, ok := schemas[table]
+		if !ok {
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := expectDumpDelim(dec, '['); err != nil {
+			return err
+		}
+		writer := &dumpTableWriter{tx: tx, schema: s}
+		for dec.More() {
+			var record map[string]json.RawMessage
+			if err := dec.Decode(&record); err != nil {
+				return fmt.Errorf("import table %s: %w", table, err)
+			}
+			if err := writer.add(record); err != nil {
+				return err
+			}
+		}
+		if err := expectDumpDelim(dec, ']'); err != nil {
+			return err
+		}
+		if err := writer.close(); err != nil {
+			return err
+		}
+	}
+	return expectDumpDelim(dec, '}')
+}
+
+// importUnorderedDumpTables 导入旧版本的备份，表按名称排序，需要读入全部表后按模型顺序写入以满足外键约束
+func importUnorderedDumpTables(tx *gorm.DB, dec *json.Decoder) error {
+	var tables map[string][]map[string]json.RawMessage
+	if err := dec.Decode(&tables); err != nil {
+		return err
+	}
+
+	for _, model := range Models() {
+		s, err := parseSchema(tx, model)
+		if err != nil {
+			return err
+		}
+
+		writer := &dumpTableWriter{tx: tx, schema: s}
+		for _, record := range tables[s.Table] {
+			if err := writer.add(record); err != nil {
+				return err
+			}
+		}
+		if err := writer.close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dumpSchemas 解析所有模型的表结构，按表名索引
+func dumpSchemas(tx *gorm.DB) (map[string]*schema.Schema, error) {
+	schemas := make(map[string]*schema.Schema)
+	for _, model := range Models() {
+		s, err := parseSchema(tx, model)
+		if err != nil {
+			return nil, err
+		}
+		schemas[s.Table] = s
+	}
+	return schemas, nil
+}
+
+// expectDumpDelim 读取下一个 JSON 分隔符并校验
+func expectDumpDelim(dec *json.Decoder, delim json.Delim) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if d, ok := token.(json.Delim); !ok || d != delim {
+		return fmt.Errorf("invalid dump: expected %q, got %v", delim, token)
+	}
+	return nil
+}
+
+// dumpTableWriter 将单个表的行按批写入数据库
+type dumpTableWriter struct {
+	tx      *gorm.DB
+	schema  *schema.Schema
+	rows    []map[string]any
+	written int
+}
+
+// add 还原一行数据，攒满一批后写入
+func (w *dumpTableWriter) add(record map[string]json.RawMessage) error {
+	upgradeDumpRecord(w.schema.Table, record)
+	row, err := decodeDumpRecord(w.schema, record)
+	if err != nil {
+		return err
+	}
+	w.rows = append(w.rows, row)
+	if len(w.rows) >= dumpBatchSize {
+		return w.flush()
+	}
+	return nil
+}
+
+// flush 写入已攒下的行，使用 map 写入，避免带默认值的零值字段被忽略
+func (w *dumpTableWriter) flush() error {
+	if len(w.rows) == 0 {
+		return nil
+	}
+	if err := w.tx.Table(w.schema.Table).Create(w.rows).Error; err != nil {
+		return fmt.Errorf("import table %s: %w", w.schema.Table, err)
+	}
+	w.written += len(w.rows)
+	w.rows = w.rows[:0]
+	return nil
+}
+
+// close 写入剩余的行，并同步自增序列
+func (w *dumpTableWriter) close() error {
+	if err := w.flush(); err != nil {
+		return err
+	}
+	if w.written == 0 {
+		return nil
+	}
+	return resetSequence(w.tx, w.schema)
+}
+
+// ExportDumpToFile 将当前数据库导出到文件
+func ExportDumpToFile(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return ExportDump(GetDB(), file)
+}
+
+// ImportDumpFromFile 从文件恢复当前数据库
+func ImportDumpFromFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return ImportDump(GetDB(), file)
+}
+
+// ImportSQLiteFile 将 SQLite 数据库文件中的数据导入当前数据库，用于从 SQLite 备份迁移到其他数据库
+func ImportSQLiteFile(path string) error {
+	src, err := openDatabase(DatabaseTypeSQLite, path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if sqlDB, err := src.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	}()
+
+	// 旧版本的数据库文件可能缺少新字段，先迁移到当前结构
+	if err := src.AutoMigrate(Models()...); err != nil {
+		return err
+	}
+	if err := migrateEchoVisibility(src); err != nil {
+		return err
+	}
+
+	reader, writer := io.Pipe()
+	go func() {
+		writer.CloseWithError(ExportDump(src, writer))
+	}()
+
+	return ImportDump(GetDB(), reader)
+}
+
+// parseSchema 解析模型对应的表结构
+func parseSchema(db *gorm.DB, model any) (*schema.Schema, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return nil, err
+	}
+	return stmt.Schema, nil
+}
+
+// decodeDumpRecord 按字段类型还原一行数据
+func decodeDumpRecord(
+	s *schema.Schema,
+	record map[string]json.RawMessage,
+) (map[string]any, error) {
+	row := make(map[string]any, len(record))
+	for _, field := range s.Fields {
+		raw, ok := record[field.DBName]
+		if field.DBName == "" || !ok {
+			continue
+		}
+
+		value := reflect.New(field.FieldType)
+		if err := json.Unmarshal(raw, value.Interface()); err != nil {
+			return nil, fmt.Errorf("import %s.%s: %w", s.Table, field.DBName, err)
+		}
+
+		// 使用序列化器的字段按 JSON 文本存储
+		if field.Serializer != nil {
+			row[field.DBName] = string(raw)
+			continue
+		}
+		row[field.DBName] = value.Elem().Interface()
+	}
+	return row, nil
+}
+
+// upgradeDumpRecord 兼容旧版本逻辑备份中的字段，private 标记转换为 visibility，
+// 明文访问令牌转换为摘要
+func upgradeDumpRecord(table string, record map[string]json.RawMessage) {
+	if table == "access_token_settings" {
+		upgradeDumpAccessToken(record)
+		return
+	}
+	if table != "echos" && table != "echo_revisions" {
+		return
+	}
+	raw, ok := record["private"]
+	if !ok {
+		return
+	}
+	if _, exists := record["visibility"]; exists {
+		return
+	}
+
+	visibility := echoModel.VisibilityPublic
+	var private bool
+	if json.Unmarshal(raw, &private) == nil && private {
+		visibility = echoModel.VisibilityPrivate
+	}
+	record["visibility"], _ = json.Marshal(visibility)
+}
+
+// upgradeDumpAccessToken 将旧版本备份中的明文访问令牌转换为摘要，并保留全部权限范围
+func upgradeDumpAccessToken(record map[string]json.RawMessage) {
+	raw, ok := record["token"]
+	if !ok {
+		return
+	}
+	if _, exists := record["token_hash"]; exists {
+		return
+	}
+
+	var token string
+	if json.Unmarshal(raw, &token) != nil || token == "" {
+		return
+	}
+	record["token_hash"], _ = json.Marshal(cryptoUtil.SHA256Hex(token))
+	record["prefix"], _ = json.Marshal(settingModel.AccessTokenDisplayPrefix(token))
+	record["scopes"], _ = json.Marshal([]string{"*"})
+}
+
+// resetSequence 显式写入自增主键后，PostgreSQL 需要同步序列的当前值
+func resetSequence(tx *gorm.DB, s *schema.Schema) error {
+	if tx.Dialector.Name() != DatabaseTypePostgres {
+		return nil
+	}
+
+	field := s.PrioritizedPrimaryField
+	if field == nil || !field.AutoIncrement {
+		return nil
+	}
+
+	// pg_get_serial_sequence 的表名参数按 SQL 标识符解析，需要带引号以保留大小写；列名参数按原样匹配
+	return tx.Exec(
+		"SELECT setval(pg_get_serial_sequence(?, ?), COALESCE((SELECT MAX(?) FROM ?), 1))",
+		quote(tx, s.Table),
+		field.DBName,
+		clause.Column{Name: field.DBName},
+		clause.Table{Name: s.Table},
+	).Error
+}
+
+// quote 按当前数据库方言引用标识符
+func quote(tx *gorm.DB, name string) string {
+	return tx.Statement.Quote(name)
+}
@@ -0,0 +1,231 @@
+package database
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	echoModel "github.com/lin-snow/ech0/internal/model/echo"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
+)
+
+// newTestDB 基于临时 SQLite 数据库并迁移所有模型
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "ech0.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.AutoMigrate(Models()...); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db
+}
+
+// newDryRunPostgres 不连接数据库的 PostgreSQL 会话，记录执行的原始 SQL
+func newDryRunPostgres(t *testing.T) (*gorm.DB, *[]string) {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=127.0.0.1 dbname=ech0"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+		Logger:               logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open postgres: %v", err)
+	}
+
+	var statements []string
+	if err := db.Callback().Raw().After("gorm:raw").Register("test:capture", func(tx *gorm.DB) {
+		statements = append(statements, tx.Dialector.Explain(tx.Statement.SQL.String(), tx.Statement.Vars...))
+	}); err != nil {
+		t.Fatalf("register callback: %v", err)
+	}
+	return db, &statements
+}
+
+// mixedCaseRecord 表名带大写字母的自增主键模型
+type mixedCaseRecord struct {
+	ID uint `gorm:"primaryKey"`
+}
+
+func (mixedCaseRecord) TableName() string { return "UserEchos" }
+
+// naturalKeyRecord 主键不是自增列的模型
+type naturalKeyRecord struct {
+	Key string `gorm:"primaryKey"`
+}
+
+func TestResetSequence(t *testing.T) {
+	pg, statements := newDryRunPostgres(t)
+
+	tests := map[string]struct {
+		db    *gorm.DB
+		model any
+		want  string // 期望执行的语句，为空表示不执行
+	}{
+		"表名保留大小写": {
+			db:    pg,
+			model: &mixedCaseRecord{},
+			want:  `SELECT setval(pg_get_serial_sequence('"UserEchos"', 'id'), COALESCE((SELECT MAX("id") FROM "UserEchos"), 1))`,
+		},
+		"普通表": {
+			db:    pg,
+			model: &echoModel.Echo{},
+			want:  `SELECT setval(pg_get_serial_sequence('"echos"', 'id'), COALESCE((SELECT MAX("id") FROM "echos"), 1))`,
+		},
+		"非自增主键":       {db: pg, model: &naturalKeyRecord{}},
+		"SQLite 无需同步": {db: newTestDB(t), model: &echoModel.Echo{}},
+	}
+
+	for name, tt := range tests {
+		*statements = nil
+		s, err := parseSchema(tt.db, tt.model)
+		if err != nil {
+			t.Fatalf("%s: parse schema: %v", name, err)
+		}
+		if err := resetSequence(tt.db, s); err != nil {
+			t.Errorf("%s: reset: %v", name, err)
+			continue
+		}
+
+		switch {
+		case tt.want == "" && len(*statements) != 0:
+			t.Errorf("%s: executed %v, want nothing", name, *statements)
+		case tt.want != "" && (len(*statements) != 1 || (*statements)[0] != tt.want):
+			t.Errorf("%s: executed %v, want %s", name, *statements, tt.want)
+		}
+	}
+}
+
+func TestDumpRoundTrip(t *testing.T) {
+	src := newTestDB(t)
+	user := userModel.User{Username: "alice", Password: "hash"}
+	if err := src.Create(&user).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	publishAt := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	echos := []echoModel.Echo{
+		{Content: "hello", UserID: user.ID, Username: user.Username, Tags: []echoModel.Tag{{Name: "go"}}},
+		{Content: "later", UserID: user.ID, Status: echoModel.EchoStatusScheduled, PublishAt: &publishAt},
+		{Content: "trashed", UserID: user.ID, Visibility: echoModel.VisibilityPrivate},
+	}
+	if err := src.Create(&echos).Error; err != nil {
+		t.Fatalf("create echos: %v", err)
+	}
+	// 回收站中的内容同样需要备份
+	if err := src.Delete(&echos[2]).Error; err != nil {
+		t.Fatalf("trash echo: %v", err)
+	}
+
+	var dump bytes.Buffer
+	if err := ExportDump(src, &dump); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+
+	// 导入时先清空目标数据库中原有的数据
+	dst := newTestDB(t)
+	if err := dst.Create(&echoModel.Echo{Content: "stale", UserID: 99}).Error; err != nil {
+		t.Fatalf("create stale echo: %v", err)
+	}
+	if err := ImportDump(dst, bytes.NewReader(dump.Bytes())); err != nil {
+		t.Fatalf("import: %v", err)
+	}
+
+	var restored []echoModel.Echo
+	if err := dst.Unscoped().Preload("Tags").Order("id").Find(&restored).Error; err != nil {
+		t.Fatalf("load echos: %v", err)
+	}
+	if len(restored) != len(echos) {
+		t.Fatalf("restored %d echos, want %d", len(restored), len(echos))
+	}
+	for i, echo := range restored {
+		if echo.ID != echos[i].ID || echo.Content != echos[i].Content {
+			t.Errorf("echo %d = %d %q", i, echo.ID, echo.Content)
+		}
+	}
+	if len(restored[0].Tags) != 1 || restored[0].Tags[0].Name != "go" {
+		t.Errorf("tags = %+v", restored[0].Tags)
+	}
+	if restored[1].PublishAt == nil || !restored[1].PublishAt.Equal(publishAt) || restored[1].Status != echoModel.EchoStatusScheduled {
+		t.Errorf("scheduled echo = %v %s", restored[1].PublishAt, restored[1].Status)
+	}
+	if !restored[2].DeletedAt.Valid || restored[2].Visibility != echoModel.VisibilityPrivate {
+		t.Errorf("trashed echo = %+v", restored[2])
+	}
+
+	// 导入后新写入的行继续使用后续的主键
+	next := echoModel.Echo{Content: "next", UserID: user.ID}
+	if err := dst.Create(&next).Error; err != nil {
+		t.Fatalf("create after import: %v", err)
+	}
+	if next.ID <= echos[2].ID {
+		t.Errorf("next id = %d, want greater than %d", next.ID, echos[2].ID)
+	}
+}
+
+func TestImportDumpVersions(t *testing.T) {
+	tests := map[string]struct {
+		dump    string
+		wantErr bool
+	}{
+		"旧版本按表名排序": {
+			dump: `{"version":1,"tables":{"echos":[{"id":5,"content":"legacy","user_id":1,"private":true}],"users":[{"id":1,"username":"alice","password":"x"}]}}`,
+		},
+		"缺少版本号":     {dump: `{"tables":{}}`, wantErr: true},
+		"不支持的版本":    {dump: `{"version":99,"tables":{}}`, wantErr: true},
+		"没有表":       {dump: `{"version":2}`, wantErr: true},
+		"格式错误":      {dump: `[]`, wantErr: true},
+		"跳过未知的表与字段": {dump: `{"version":2,"extra":{"a":1},"tables":{"unknown":[{"x":1}],"users":[{"id":3,"username":"bob","password":"x"}]}}`},
+	}
+
+	for name, tt := range tests {
+		db := newTestDB(t)
+		err := ImportDump(db, strings.NewReader(tt.dump))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v, wantErr %v", name, err, tt.wantErr)
+		}
+	}
+
+	// 旧版本备份中的 private 字段转换为可见性
+	db := newTestDB(t)
+	if err := ImportDump(db, strings.NewReader(tests["旧版本按表名排序"].dump)); err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	var echo echoModel.Echo
+	if err := db.First(&echo, 5).Error; err != nil {
+		t.Fatalf("load echo: %v", err)
+	}
+	if echo.Visibility != echoModel.VisibilityPrivate {
+		t.Errorf("visibility = %q, want private", echo.Visibility)
+	}
+}
+
+func TestExportDumpIsVersioned(t *testing.T) {
+	db := newTestDB(t)
+	var buf bytes.Buffer
+	if err := ExportDump(db, &buf); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+
+	var dump Dump
+	if err := json.Unmarshal(buf.Bytes(), &dump); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if dump.Version != DumpVersion || dump.Dialect != DatabaseTypeSQLite {
+		t.Errorf("version %d dialect %q", dump.Version, dump.Dialect)
+	}
+	// 每个模型对应的表都会写出，空表为空数组
+	if len(dump.Tables) != len(Models()) {
+		t.Errorf("tables = %d, want %d", len(dump.Tables), len(Models()))
+	}
+}
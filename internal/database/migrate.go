@@ -5,6 +5,10 @@ import (
 
 	commonModel "github.com/lin-snow/ech0/internal/model/common"
 	echoModel "github.com/lin-snow/ech0/internal/model/echo"
+	settingModel "github.com/lin-snow/ech0/internal/model/setting"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
+	cryptoUtil "github.com/lin-snow/ech0/internal/util/crypto"
+	"gorm.io/gorm"
 )
 
 // fixOldEchoLayoutData 为旧数据补充默认的布局值（layout 为 NULL 或空字符串时设为 'waterfall'）
@@ -24,7 +28,129 @@ func fixOldEchoLayoutData() error {
 	return nil
 }
 
+// fixOldEchoStatusData 为旧数据补充发布状态（status 为 NULL 或空字符串时设为 'published'）
+func fixOldEchoStatusData() error {
+	db := GetDB()
+	if db == nil {
+		return errors.New(commonModel.DATABASE_NOT_INITED)
+	}
+
+	return db.Model(&echoModel.Echo{}).
+		Where("status IS NULL OR status = ''").
+		Update("status", echoModel.EchoStatusPublished).Error
+}
+
+// migrateEchoVisibility 将旧版本的 private 字段迁移为 visibility，迁移完成后删除 private 列
+func migrateEchoVisibility(db *gorm.DB) error {
+	for _, model := range []any{&echoModel.Echo{}, &echoModel.EchoRevision{}} {
+		if db.Migrator().HasColumn(model, "private") {
+			if err := db.Model(model).
+				Where("private = ?", true).
+				Update("visibility", echoModel.VisibilityPrivate).Error; err != nil {
+				return err
+			}
+			if err := db.Migrator().DropColumn(model, "private"); err != nil {
+				return err
+			}
+		}
+
+		if err := db.Model(model).
+			Where("visibility IS NULL OR visibility = ''").
+			Update("visibility", echoModel.VisibilityPublic).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateLegacyAccessTokens 将旧版本明文保存的 JWT 访问令牌转换为摘要存储，
+// 旧令牌保留全部权限范围以保证兼容，迁移完成后删除 token 列
+func migrateLegacyAccessTokens(db *gorm.DB) error {
+	model := &settingModel.AccessTokenSetting{}
+	if !db.Migrator().HasColumn(model, "token") {
+		return nil
+	}
+
+	var legacyTokens []struct {
+		ID    int
+		Token string
+	}
+	if err := db.Model(model).
+		Select("id", "token").
+		Where("token_hash IS NULL OR token_hash = ''").
+		Find(&legacyTokens).Error; err != nil {
+		return err
+	}
+
+	for _, legacy := range legacyTokens {
+		if err := db.Model(model).
+			Where("id = ?", legacy.ID).
+			Updates(&settingModel.AccessTokenSetting{
+				TokenHash: cryptoUtil.SHA256Hex(legacy.Token),
+				Prefix:    settingModel.AccessTokenDisplayPrefix(legacy.Token),
+				Scopes:    []string{"*"},
+			}).Error; err != nil {
+			return err
+		}
+	}
+
+	if err := db.Migrator().DropColumn(model, "token"); err != nil {
+		return err
+	}
+
+	// SQLite 删除列时会重建表，需要补回摘要的唯一索引
+	if !db.Migrator().HasIndex(model, "TokenHash") {
+		return db.Migrator().CreateIndex(model, "TokenHash")
+	}
+	return nil
+}
+
+// migrateUserRoles 为旧版本的用户补充角色：管理员迁移为 admin，
+// 其中 ID 最小的管理员即首个注册的用户，迁移为站长。已存在站长时跳过
+func migrateUserRoles(db *gorm.DB) error {
+	model := &userModel.User{}
+
+	var ownerCount int64
+	if err := db.Model(model).
+		Where("role = ?", userModel.RoleOwner).
+		Count(&ownerCount).Error; err != nil {
+		return err
+	}
+	if ownerCount > 0 {
+		return nil
+	}
+
+	var owner userModel.User
+	if err := db.Where("is_admin = ?", true).Order("id ASC").First(&owner).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	if err := db.Model(model).
+		Where("is_admin = ?", true).
+		Update("role", userModel.RoleAdmin).Error; err != nil {
+		return err
+	}
+	return db.Model(model).
+		Where("id = ?", owner.ID).
+		Update("role", userModel.RoleOwner).Error
+}
+
 // UpdateMigration 执行旧数据库迁移和数据修复任务
 func UpdateMigration() error {
-	return fixOldEchoLayoutData()
+	if err := fixOldEchoLayoutData(); err != nil {
+		return err
+	}
+	if err := fixOldEchoStatusData(); err != nil {
+		return err
+	}
+	if err := migrateEchoVisibility(GetDB()); err != nil {
+		return err
+	}
+	if err := migrateLegacyAccessTokens(GetDB()); err != nil {
+		return err
+	}
+	return migrateUserRoles(GetDB())
 }
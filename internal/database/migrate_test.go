@@ -0,0 +1,148 @@
+package database
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	echoModel "github.com/lin-snow/ech0/internal/model/echo"
+	settingModel "github.com/lin-snow/ech0/internal/model/setting"
+	cryptoUtil "github.com/lin-snow/ech0/internal/util/crypto"
+)
+
+func TestMigrateEchoVisibility(t *testing.T) {
+	db := newTestDB(t)
+	// 与旧版本由 GORM 创建的列定义一致
+	for _, table := range []string{"echos", "echo_revisions"} {
+		if err := db.Exec("ALTER TABLE " + table + " ADD COLUMN `private` numeric DEFAULT false").Error; err != nil {
+			t.Fatalf("add private column: %v", err)
+		}
+	}
+
+	// 旧版本只有 private 字段，visibility 为空
+	tests := map[string]struct {
+		private    bool
+		visibility string
+		want       string
+	}{
+		"旧版私密":      {private: true, want: echoModel.VisibilityPrivate},
+		"旧版公开":      {private: false, want: echoModel.VisibilityPublic},
+		"已设置的可见性保留": {private: false, visibility: echoModel.VisibilityMembers, want: echoModel.VisibilityMembers},
+	}
+	ids := make(map[string]uint, len(tests))
+	for name, tt := range tests {
+		echo := echoModel.Echo{Content: name, Visibility: tt.visibility}
+		if err := db.Create(&echo).Error; err != nil {
+			t.Fatalf("create echo: %v", err)
+		}
+		// Create 会补全默认值，这里还原为旧版本的数据
+		if err := db.Exec("UPDATE echos SET private = ?, visibility = ? WHERE id = ?", tt.private, tt.visibility, echo.ID).Error; err != nil {
+			t.Fatalf("set legacy columns: %v", err)
+		}
+		revision := echoModel.EchoRevision{EchoID: echo.ID, Revision: 1, Content: name}
+		if err := db.Create(&revision).Error; err != nil {
+			t.Fatalf("create revision: %v", err)
+		}
+		if err := db.Exec("UPDATE echo_revisions SET private = ?, visibility = ? WHERE id = ?", tt.private, tt.visibility, revision.ID).Error; err != nil {
+			t.Fatalf("set legacy columns: %v", err)
+		}
+		ids[name] = echo.ID
+	}
+
+	// 重复执行结果不变
+	for range 2 {
+		if err := migrateEchoVisibility(db); err != nil {
+			t.Fatalf("migrate: %v", err)
+		}
+	}
+
+	for name, tt := range tests {
+		var echo echoModel.Echo
+		if err := db.First(&echo, ids[name]).Error; err != nil {
+			t.Fatalf("%s: load echo: %v", name, err)
+		}
+		var revision echoModel.EchoRevision
+		if err := db.Where("echo_id = ?", ids[name]).First(&revision).Error; err != nil {
+			t.Fatalf("%s: load revision: %v", name, err)
+		}
+		if echo.Visibility != tt.want || revision.Visibility != tt.want {
+			t.Errorf("%s: echo %q revision %q, want %q", name, echo.Visibility, revision.Visibility, tt.want)
+		}
+	}
+	for _, model := range []any{&echoModel.Echo{}, &echoModel.EchoRevision{}} {
+		if db.Migrator().HasColumn(model, "private") {
+			t.Errorf("%T still has the private column", model)
+		}
+	}
+}
+
+func TestMigrateLegacyAccessTokens(t *testing.T) {
+	db := newTestDB(t)
+	if err := migrateLegacyAccessTokens(db); err != nil {
+		t.Fatalf("migrate without legacy column: %v", err)
+	}
+	if err := db.Exec("ALTER TABLE access_token_settings ADD COLUMN `token` text").Error; err != nil {
+		t.Fatalf("add token column: %v", err)
+	}
+
+	// 旧版本只保存明文 JWT，摘要为空；新版本创建的令牌已有摘要，迁移时保持不变
+	legacy := []string{"eyJhbGciOiJIUzI1NiJ9.legacy-1", "eyJhbGciOiJIUzI1NiJ9.legacy-2"}
+	for i, token := range legacy {
+		if err := db.Exec(
+			"INSERT INTO access_token_settings (user_id, token, name, created_at) VALUES (?, ?, ?, ?)",
+			1, token, fmt.Sprintf("legacy-%d", i), time.Now(),
+		).Error; err != nil {
+			t.Fatalf("insert legacy token: %v", err)
+		}
+	}
+	current := settingModel.AccessTokenSetting{
+		UserID:    1,
+		TokenHash: cryptoUtil.SHA256Hex("ech0_current"),
+		Prefix:    "ech0_current",
+		Name:      "current",
+		Scopes:    []string{settingModel.ScopeEchoRead},
+		CreatedAt: time.Now(),
+	}
+	if err := db.Create(&current).Error; err != nil {
+		t.Fatalf("create token: %v", err)
+	}
+
+	// 重复执行结果不变
+	for range 2 {
+		if err := migrateLegacyAccessTokens(db); err != nil {
+			t.Fatalf("migrate: %v", err)
+		}
+	}
+
+	for _, token := range legacy {
+		var migrated settingModel.AccessTokenSetting
+		if err := db.Where("token_hash = ?", cryptoUtil.SHA256Hex(token)).First(&migrated).Error; err != nil {
+			t.Fatalf("load %s: %v", token, err)
+		}
+		if migrated.Prefix != token[:settingModel.AccessTokenDisplayLength] ||
+			!slices.Equal(migrated.Scopes, []string{"*"}) || !migrated.HasScope(settingModel.ScopeSettingsAdmin) {
+			t.Errorf("%s: migrated = %+v", token, migrated)
+		}
+	}
+	var kept settingModel.AccessTokenSetting
+	if err := db.First(&kept, current.ID).Error; err != nil {
+		t.Fatalf("load token: %v", err)
+	}
+	if kept.TokenHash != current.TokenHash || !slices.Equal(kept.Scopes, current.Scopes) {
+		t.Errorf("current token changed: %+v", kept)
+	}
+
+	model := &settingModel.AccessTokenSetting{}
+	if db.Migrator().HasColumn(model, "token") {
+		t.Error("token column still exists")
+	}
+	if !db.Migrator().HasIndex(model, "TokenHash") {
+		t.Error("token hash index missing")
+	}
+	duplicate := current
+	duplicate.ID = 0
+	if err := db.Create(&duplicate).Error; err == nil {
+		t.Error("duplicate token hash accepted")
+	}
+}
@@ -0,0 +1,128 @@
+package database
+
+import (
+	"strings"
+	"sync/atomic"
+
+	echoModel "github.com/lin-snow/ech0/internal/model/echo"
+	logUtil "github.com/lin-snow/ech0/internal/util/log"
+	searchUtil "github.com/lin-snow/ech0/internal/util/search"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	SearchIndexTable     = "echo_fts" // Echo 全文索引表（FTS5 虚拟表），rowid 与 Echo ID 一致
+	searchIndexBatchSize = 200        // 重建索引时每批读取的 Echo 数量
+)
+
+// searchIndexEnabled 当前数据库是否可以使用全文索引
+var searchIndexEnabled atomic.Bool
+
+// SearchIndexEnabled 判断全文索引是否可用，不可用时搜索回退为 LIKE 模糊匹配
+func SearchIndexEnabled() bool {
+	return searchIndexEnabled.Load()
+}
+
+// InitSearchIndex 创建 Echo 全文索引
+//
+// 仅 SQLite 支持，需要使用 sqlite_fts5 构建标签编译以启用 FTS5 模块。索引表首次创建时会根据现有 Echo 重建索引
+func InitSearchIndex(db *gorm.DB) error {
+	searchIndexEnabled.Store(false)
+	if db.Dialector.Name() != DatabaseTypeSQLite {
+		return nil
+	}
+
+	exists := db.Migrator().HasTable(SearchIndexTable)
+	err := db.Exec(
+		"CREATE VIRTUAL TABLE IF NOT EXISTS " + SearchIndexTable +
+			" USING fts5(content, tags, tokenize = 'unicode61 remove_diacritics 2')",
+	).Error
+	if err == nil {
+		// 索引表已存在时不会检查模块，需要实际查询一次确认 FTS5 可用
+		err = db.Exec("SELECT rowid FROM " + SearchIndexTable + " LIMIT 0").Error
+	}
+	if err != nil {
+		if strings.Contains(err.Error(), "no such module") {
+			logUtil.GetLogger().Warn("SQLite FTS5 is not available, fallback to LIKE search")
+			return nil
+		}
+		return err
+	}
+	searchIndexEnabled.Store(true)
+
+	if !exists {
+		count, err := RebuildSearchIndex(db)
+		if err != nil {
+			return err
+		}
+		logUtil.GetLogger().Info("Search index created", zap.Int("echos", count))
+	}
+
+	return nil
+}
+
+// RebuildSearchIndex 清空并根据所有 Echo 重建全文索引，返回写入索引的 Echo 数量
+func RebuildSearchIndex(db *gorm.DB) (int, error) {
+	if !SearchIndexEnabled() {
+		return 0, nil
+	}
+
+	count := 0
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM " + SearchIndexTable).Error; err != nil {
+			return err
+		}
+
+		var echos []echoModel.Echo
+		return tx.Preload("Tags").
+			Order("id").
+			FindInBatches(&echos, searchIndexBatchSize, func(batch *gorm.DB, _ int) error {
+				for i := range echos {
+					if err := insertSearchIndex(batch, &echos[i]); err != nil {
+						return err
+					}
+				}
+				count += len(echos)
+				return nil
+			}).Error
+	})
+
+	return count, err
+}
+
+// IndexEcho 写入或更新单个 Echo 的全文索引，echo 需预加载 Tags
+func IndexEcho(db *gorm.DB, echo *echoModel.Echo) error {
+	if !SearchIndexEnabled() {
+		return nil
+	}
+
+	if err := RemoveEchoIndex(db, echo.ID); err != nil {
+		return err
+	}
+	return insertSearchIndex(db, echo)
+}
+
+// RemoveEchoIndex 删除单个 Echo 的全文索引
+func RemoveEchoIndex(db *gorm.DB, id uint) error {
+	if !SearchIndexEnabled() {
+		return nil
+	}
+
+	return db.Exec("DELETE FROM "+SearchIndexTable+" WHERE rowid = ?", id).Error
+}
+
+// insertSearchIndex 将 Echo 内容与标签分词后写入索引
+func insertSearchIndex(db *gorm.DB, echo *echoModel.Echo) error {
+	tags := make([]string, 0, len(echo.Tags))
+	for _, tag := range echo.Tags {
+		tags = append(tags, tag.Name)
+	}
+
+	return db.Exec(
+		"INSERT INTO "+SearchIndexTable+" (rowid, content, tags) VALUES (?, ?, ?)",
+		echo.ID,
+		searchUtil.Tokenize(echo.Content),
+		searchUtil.Tokenize(strings.Join(tags, " ")),
+	).Error
+}
@@ -0,0 +1,129 @@
+package database
+
+import (
+	"slices"
+	"testing"
+
+	"gorm.io/gorm"
+
+	echoModel "github.com/lin-snow/ech0/internal/model/echo"
+	logUtil "github.com/lin-snow/ech0/internal/util/log"
+	searchUtil "github.com/lin-snow/ech0/internal/util/search"
+	"go.uber.org/zap"
+)
+
+// newSearchDB 创建带全文索引的测试数据库，FTS5 不可用时跳过（需使用 -tags sqlite_fts5 运行）
+func newSearchDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	logUtil.Logger = zap.NewNop()
+	db := newTestDB(t)
+	t.Cleanup(func() { searchIndexEnabled.Store(false) })
+	if err := InitSearchIndex(db); err != nil {
+		t.Fatalf("init search index: %v", err)
+	}
+	if !SearchIndexEnabled() {
+		t.Skip("SQLite FTS5 is not available, run with -tags sqlite_fts5")
+	}
+	return db
+}
+
+// match 返回匹配搜索词的 Echo ID
+func match(t *testing.T, db *gorm.DB, terms ...string) []uint {
+	t.Helper()
+	var ids []uint
+	if err := db.Raw(
+		"SELECT rowid FROM "+SearchIndexTable+" WHERE "+SearchIndexTable+" MATCH ? ORDER BY rowid",
+		searchUtil.BuildMatchQuery(terms),
+	).Scan(&ids).Error; err != nil {
+		t.Fatalf("match %q: %v", terms, err)
+	}
+	return ids
+}
+
+func TestSearchIndexMatch(t *testing.T) {
+	db := newSearchDB(t)
+
+	echos := []echoModel.Echo{
+		{Content: "今天在楼下喝咖啡，很开心", UserID: 1},
+		{Content: "Coffee beans from Yunnan", UserID: 1, Tags: []echoModel.Tag{{Name: "咖啡豆"}}},
+		{Content: "東京のカフェで休憩", UserID: 1},
+		{Content: `say "hi" NEAR(a`, UserID: 1},
+	}
+	if err := db.Create(&echos).Error; err != nil {
+		t.Fatalf("create echos: %v", err)
+	}
+	for i := range echos {
+		if err := IndexEcho(db, &echos[i]); err != nil {
+			t.Fatalf("index: %v", err)
+		}
+	}
+	id := func(i int) uint { return echos[i].ID }
+
+	tests := map[string]struct {
+		terms []string
+		want  []uint
+	}{
+		"中文词":        {terms: []string{"咖啡"}, want: []uint{id(0), id(1)}},
+		"任意中文子串":     {terms: []string{"啡，很"}, want: []uint{id(0)}},
+		"单字":         {terms: []string{"楼"}, want: []uint{id(0)}},
+		"不连续的字不匹配":   {terms: []string{"喝开心"}},
+		"英文前缀不区分大小写": {terms: []string{"coff"}, want: []uint{id(1)}},
+		"标签":         {terms: []string{"咖啡豆"}, want: []uint{id(1)}},
+		"日文":         {terms: []string{"カフェ"}, want: []uint{id(2)}},
+		"多个词为 AND":   {terms: []string{"咖啡", "yunnan"}, want: []uint{id(1)}},
+		"双引号与运算符":    {terms: []string{`"hi"`, "NEAR(a"}, want: []uint{id(3)}},
+	}
+
+	for name, tt := range tests {
+		if got := match(t, db, tt.terms...); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: matched %v, want %v", name, got, tt.want)
+		}
+	}
+}
+
+func TestSearchIndexUpdateAndRebuild(t *testing.T) {
+	db := newSearchDB(t)
+
+	echo := echoModel.Echo{Content: "旧的内容", UserID: 1}
+	if err := db.Create(&echo).Error; err != nil {
+		t.Fatalf("create echo: %v", err)
+	}
+	if err := IndexEcho(db, &echo); err != nil {
+		t.Fatalf("index: %v", err)
+	}
+
+	// 重新索引时替换旧内容
+	echo.Content = "新的内容"
+	if err := IndexEcho(db, &echo); err != nil {
+		t.Fatalf("reindex: %v", err)
+	}
+	if got := match(t, db, "旧的"); len(got) != 0 {
+		t.Errorf("old content still matches: %v", got)
+	}
+	if got := match(t, db, "新的"); !slices.Equal(got, []uint{echo.ID}) {
+		t.Errorf("new content matched %v", got)
+	}
+
+	if err := RemoveEchoIndex(db, echo.ID); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if got := match(t, db, "内容"); len(got) != 0 {
+		t.Errorf("removed echo still matches: %v", got)
+	}
+
+	// 重建索引时写入数据库中的全部 Echo
+	other := echoModel.Echo{Content: "另一条内容", UserID: 1}
+	if err := db.Create(&other).Error; err != nil {
+		t.Fatalf("create echo: %v", err)
+	}
+	count, err := RebuildSearchIndex(db)
+	if err != nil {
+		t.Fatalf("rebuild: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("rebuilt %d echos, want 2", count)
+	}
+	if got := match(t, db, "内容"); !slices.Equal(got, []uint{echo.ID, other.ID}) {
+		t.Errorf("matched %v after rebuild", got)
+	}
+}
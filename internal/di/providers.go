@@ -1,19 +1,29 @@
 package di
 
 import (
+	"sync"
+
 	"github.com/lin-snow/ech0/internal/cache"
+	"github.com/lin-snow/ech0/internal/event"
 	agentHandler "github.com/lin-snow/ech0/internal/handler/agent"
 	backupHandler "github.com/lin-snow/ech0/internal/handler/backup"
 	commonHandler "github.com/lin-snow/ech0/internal/handler/common"
 	connectHandler "github.com/lin-snow/ech0/internal/handler/connect"
 	dashboardHandler "github.com/lin-snow/ech0/internal/handler/dashboard"
 	echoHandler "github.com/lin-snow/ech0/internal/handler/echo"
+	exporterHandler "github.com/lin-snow/ech0/internal/handler/exporter"
 	fediverseHandler "github.com/lin-snow/ech0/internal/handler/fediverse"
+	importerHandler "github.com/lin-snow/ech0/internal/handler/importer"
 	inboxHandler "github.com/lin-snow/ech0/internal/handler/inbox"
+	queueHandler "github.com/lin-snow/ech0/internal/handler/queue"
+	sessionHandler "github.com/lin-snow/ech0/internal/handler/session"
 	settingHandler "github.com/lin-snow/ech0/internal/handler/setting"
 	todoHandler "github.com/lin-snow/ech0/internal/handler/todo"
+	trashHandler "github.com/lin-snow/ech0/internal/handler/trash"
 	userHandler "github.com/lin-snow/ech0/internal/handler/user"
 	webHandler "github.com/lin-snow/ech0/internal/handler/web"
+	queueRepository "github.com/lin-snow/ech0/internal/repository/queue"
+	webhookRepository "github.com/lin-snow/ech0/internal/repository/webhook"
 	"github.com/lin-snow/ech0/internal/transaction"
 )
 
@@ -31,6 +41,11 @@ type Handlers struct {
 	FediverseHandler *fediverseHandler.FediverseHandler
 	DashboardHandler *dashboardHandler.DashboardHandler
 	AgentHandler     *agentHandler.AgentHandler
+	QueueHandler     *queueHandler.QueueHandler
+	TrashHandler     *trashHandler.TrashHandler
+	ImporterHandler  *importerHandler.ImporterHandler
+	ExporterHandler  *exporterHandler.ExporterHandler
+	SessionHandler   *sessionHandler.SessionHandler
 }
 
 // NewHandlers 创建Handlers实例
@@ -47,6 +62,11 @@ func NewHandlers(
 	fediverseHandler *fediverseHandler.FediverseHandler,
 	dashboardHandler *dashboardHandler.DashboardHandler,
 	agentHandler *agentHandler.AgentHandler,
+	queueHandler *queueHandler.QueueHandler,
+	trashHandler *trashHandler.TrashHandler,
+	importerHandler *importerHandler.ImporterHandler,
+	exporterHandler *exporterHandler.ExporterHandler,
+	sessionHandler *sessionHandler.SessionHandler,
 ) *Handlers {
 	return &Handlers{
 		WebHandler:       webHandler,
@@ -61,6 +81,11 @@ func NewHandlers(
 		FediverseHandler: fediverseHandler,
 		DashboardHandler: dashboardHandler,
 		AgentHandler:     agentHandler,
+		QueueHandler:     queueHandler,
+		TrashHandler:     trashHandler,
+		ImporterHandler:  importerHandler,
+		ExporterHandler:  exporterHandler,
+		SessionHandler:   sessionHandler,
 	}
 }
 
@@ -75,3 +100,22 @@ func ProvideTransactionManager(
 ) transaction.TransactionManager {
 	return factory.TransactionManager()
 }
+
+var (
+	webhookDispatcherOnce sync.Once
+	webhookDispatcher     *event.WebhookDispatcher
+)
+
+// ProvideWebhookDispatcher 提供进程内唯一的 WebhookDispatcher，
+// 各注入器共用同一个工作池，避免重复创建协程池与 HTTP 连接池
+func ProvideWebhookDispatcher(
+	ebp func() event.IEventBus,
+	repo webhookRepository.WebhookRepositoryInterface,
+	queueRepo queueRepository.QueueRepositoryInterface,
+	txManager transaction.TransactionManager,
+) *event.WebhookDispatcher {
+	webhookDispatcherOnce.Do(func() {
+		webhookDispatcher = event.NewWebhookDispatcher(ebp, repo, queueRepo, txManager)
+	})
+	return webhookDispatcher
+}
@@ -14,10 +14,15 @@ import (
 	connectHandler "github.com/lin-snow/ech0/internal/handler/connect"
 	dashboardHandler "github.com/lin-snow/ech0/internal/handler/dashboard"
 	echoHandler "github.com/lin-snow/ech0/internal/handler/echo"
+	exporterHandler "github.com/lin-snow/ech0/internal/handler/exporter"
 	fediverseHandler "github.com/lin-snow/ech0/internal/handler/fediverse"
+	importerHandler "github.com/lin-snow/ech0/internal/handler/importer"
 	inboxHandler "github.com/lin-snow/ech0/internal/handler/inbox"
+	queueHandler "github.com/lin-snow/ech0/internal/handler/queue"
+	sessionHandler "github.com/lin-snow/ech0/internal/handler/session"
 	settingHandler "github.com/lin-snow/ech0/internal/handler/setting"
 	todoHandler "github.com/lin-snow/ech0/internal/handler/todo"
+	trashHandler "github.com/lin-snow/ech0/internal/handler/trash"
 	userHandler "github.com/lin-snow/ech0/internal/handler/user"
 	webHandler "github.com/lin-snow/ech0/internal/handler/web"
 	"github.com/lin-snow/ech0/internal/metric"
@@ -29,6 +34,7 @@ import (
 	inboxRepository "github.com/lin-snow/ech0/internal/repository/inbox"
 	keyvalueRepository "github.com/lin-snow/ech0/internal/repository/keyvalue"
 	queueRepository "github.com/lin-snow/ech0/internal/repository/queue"
+	sessionRepository "github.com/lin-snow/ech0/internal/repository/session"
 	settingRepository "github.com/lin-snow/ech0/internal/repository/setting"
 	todoRepository "github.com/lin-snow/ech0/internal/repository/todo"
 	userRepository "github.com/lin-snow/ech0/internal/repository/user"
@@ -39,10 +45,15 @@ import (
 	connectService "github.com/lin-snow/ech0/internal/service/connect"
 	dashboardService "github.com/lin-snow/ech0/internal/service/dashboard"
 	echoService "github.com/lin-snow/ech0/internal/service/echo"
+	exporterService "github.com/lin-snow/ech0/internal/service/exporter"
 	fediverseService "github.com/lin-snow/ech0/internal/service/fediverse"
+	importerService "github.com/lin-snow/ech0/internal/service/importer"
 	inboxService "github.com/lin-snow/ech0/internal/service/inbox"
+	queueService "github.com/lin-snow/ech0/internal/service/queue"
+	sessionService "github.com/lin-snow/ech0/internal/service/session"
 	settingService "github.com/lin-snow/ech0/internal/service/setting"
 	todoService "github.com/lin-snow/ech0/internal/service/todo"
+	trashService "github.com/lin-snow/ech0/internal/service/trash"
 	userService "github.com/lin-snow/ech0/internal/service/user"
 	"github.com/lin-snow/ech0/internal/task"
 	"github.com/lin-snow/ech0/internal/transaction"
@@ -61,6 +72,7 @@ func BuildHandlers(
 		TransactionManagerSet,
 		WebSet,
 		UserSet,
+		SessionSet,
 		EchoSet,
 		CommonSet,
 		WebhookSet,
@@ -76,6 +88,10 @@ func BuildHandlers(
 		BackupSet,
 		FediverseCoreSet,
 		FediverseSet,
+		QueueSet,
+		TrashSet,
+		ImporterSet,
+		ExporterSet,
 		NewHandlers, // NewHandlers 聚合各个模块的 Handler
 	)
 
@@ -94,14 +110,65 @@ func BuildTasker(
 		TransactionManagerSet,
 		WebhookSet,
 		SettingSet,
+		UserSet,
+		SessionSet,
 		EchoSet,
 		CommonSet,
+		InboxSet,
+		FediverseCoreSet,
+		FediverseSet,
+		TodoSet,
 		QueueSet,
+		TrashSet,
 		TaskSet,
 	)
 	return &task.Tasker{}, nil
 }
 
+// BuildImporter 构建命令行导入使用的 ImportService
+func BuildImporter(
+	dbProvider func() *gorm.DB,
+	cacheFactory *cache.CacheFactory,
+	tmFactory *transaction.TransactionManagerFactory,
+	ebProvider func() event.IEventBus,
+) (importerService.ImportServiceInterface, error) {
+	wire.Build(
+		CacheSet,
+		KeyValueSet,
+		TransactionManagerSet,
+		UserSet,
+		SessionSet,
+		EchoSet,
+		CommonSet,
+		InboxSet,
+		FediverseCoreSet,
+		FediverseSet,
+		importerService.NewImportService,
+	)
+	return nil, nil
+}
+
+// BuildExporter 构建命令行导出使用的 ExportService
+func BuildExporter(
+	dbProvider func() *gorm.DB,
+	cacheFactory *cache.CacheFactory,
+	tmFactory *transaction.TransactionManagerFactory,
+	ebProvider func() event.IEventBus,
+) (exporterService.ExportServiceInterface, error) {
+	wire.Build(
+		CacheSet,
+		KeyValueSet,
+		TransactionManagerSet,
+		wire.NewSet(
+			echoRepository.NewEchoRepository,
+			commonRepository.NewCommonRepository,
+			commonService.NewCommonService,
+		),
+		exporterService.NewExportService,
+	)
+	return nil, nil
+}
+
 func BuildEventRegistrar(
 	dbProvider func() *gorm.DB,
 	ebProvider func() event.IEventBus,
@@ -111,6 +178,7 @@ func BuildEventRegistrar(
 	wire.Build(
 		EchoSet,
 		UserSet,
+		SessionSet,
 		TodoSet,
 		InboxSet,
 		CacheSet,
@@ -148,6 +216,13 @@ var UserSet = wire.NewSet(
 	userHandler.NewUserHandler,
 )
 
+// SessionSet 包含了构建 SessionHandler 所需的所有 Provider
+var SessionSet = wire.NewSet(
+	sessionRepository.NewSessionRepository,
+	sessionService.NewSessionService,
+	sessionHandler.NewSessionHandler,
+)
+
 // EchoSet 包含了构建 EchoHandler 所需的所有 Provider
 var EchoSet = wire.NewSet(
 	echoRepository.NewEchoRepository,
@@ -209,6 +284,8 @@ var AgentSet = wire.NewSet(
 // WebhookSet 包含了构建 WebhookDispatcher 所需的所有 Provider
 var WebhookSet = wire.NewSet(
 	webhookRepository.NewWebhookRepository,
+
+	ProvideWebhookDispatcher,
 )
 
 // InboxSet 包含了构建 InboxRepository 所需的所有 Provider
@@ -226,6 +303,26 @@ var TaskSet = wire.NewSet(
 // QueueSet 包含了构建 Queue 所需的所有 Provider
 var QueueSet = wire.NewSet(
 	queueRepository.NewQueueRepository,
+	queueService.NewQueueService,
+	queueHandler.NewQueueHandler,
+)
+
+// TrashSet 包含了构建回收站所需的所有 Provider
+var TrashSet = wire.NewSet(
+	trashService.NewTrashService,
+	trashHandler.NewTrashHandler,
+)
+
+// ImporterSet 包含了构建导入功能所需的所有 Provider
+var ImporterSet = wire.NewSet(
+	importerService.NewImportService,
+	importerHandler.NewImporterHandler,
+)
+
+// ExporterSet 包含了构建导出功能所需的所有 Provider
+var ExporterSet = wire.NewSet(
+	exporterService.NewExportService,
+	exporterHandler.NewExporterHandler,
 )
 
 // FediverseCoreSet 包含了构建 FediverseCore 所需的所有 Provider
@@ -244,11 +341,11 @@ var FediverseSet = wire.NewSet(
 
 // EventSet 包含了构建 Event 相关所需的所有 Provider
 var EventSet = wire.NewSet(
-	event.NewWebhookDispatcher,
 	event.NewBackupScheduler,
 	event.NewDeadLetterResolver,
 	event.NewAgentProcessor,
 	event.NewInboxDispatcher,
+	event.NewSearchIndexer,
 	event.NewEventHandlers,
 	event.NewEventRegistry,
 )
@@ -17,10 +17,15 @@ import (
 	handler8 "github.com/lin-snow/ech0/internal/handler/connect"
 	handler11 "github.com/lin-snow/ech0/internal/handler/dashboard"
 	handler3 "github.com/lin-snow/ech0/internal/handler/echo"
+	handler16 "github.com/lin-snow/ech0/internal/handler/exporter"
 	handler10 "github.com/lin-snow/ech0/internal/handler/fediverse"
+	handler15 "github.com/lin-snow/ech0/internal/handler/importer"
 	handler6 "github.com/lin-snow/ech0/internal/handler/inbox"
+	handler13 "github.com/lin-snow/ech0/internal/handler/queue"
+	handler17 "github.com/lin-snow/ech0/internal/handler/session"
 	handler5 "github.com/lin-snow/ech0/internal/handler/setting"
 	handler7 "github.com/lin-snow/ech0/internal/handler/todo"
+	handler14 "github.com/lin-snow/ech0/internal/handler/trash"
 	handler2 "github.com/lin-snow/ech0/internal/handler/user"
 	"github.com/lin-snow/ech0/internal/handler/web"
 	"github.com/lin-snow/ech0/internal/metric"
@@ -32,6 +37,7 @@ import (
 	repository7 "github.com/lin-snow/ech0/internal/repository/inbox"
 	"github.com/lin-snow/ech0/internal/repository/keyvalue"
 	repository10 "github.com/lin-snow/ech0/internal/repository/queue"
+	repository11 "github.com/lin-snow/ech0/internal/repository/session"
 	repository4 "github.com/lin-snow/ech0/internal/repository/setting"
 	repository8 "github.com/lin-snow/ech0/internal/repository/todo"
 	"github.com/lin-snow/ech0/internal/repository/user"
@@ -42,10 +48,15 @@ import (
 	service8 "github.com/lin-snow/ech0/internal/service/connect"
 	service10 "github.com/lin-snow/ech0/internal/service/dashboard"
 	service5 "github.com/lin-snow/ech0/internal/service/echo"
+	service15 "github.com/lin-snow/ech0/internal/service/exporter"
 	service4 "github.com/lin-snow/ech0/internal/service/fediverse"
+	service14 "github.com/lin-snow/ech0/internal/service/importer"
 	service6 "github.com/lin-snow/ech0/internal/service/inbox"
+	service12 "github.com/lin-snow/ech0/internal/service/queue"
+	service16 "github.com/lin-snow/ech0/internal/service/session"
 	service2 "github.com/lin-snow/ech0/internal/service/setting"
 	service7 "github.com/lin-snow/ech0/internal/service/todo"
+	service13 "github.com/lin-snow/ech0/internal/service/trash"
 	service3 "github.com/lin-snow/ech0/internal/service/user"
 	"github.com/lin-snow/ech0/internal/task"
 	"github.com/lin-snow/ech0/internal/transaction"
@@ -66,17 +77,21 @@ func BuildHandlers(dbProvider func() *gorm.DB, cacheFactory *cache.CacheFactory,
 	commonServiceInterface := service.NewCommonService(transactionManager, commonRepositoryInterface, echoRepositoryInterface, keyValueRepositoryInterface, ebProvider)
 	settingRepositoryInterface := repository4.NewSettingRepository(dbProvider)
 	webhookRepositoryInterface := repository5.NewWebhookRepository(dbProvider)
-	settingServiceInterface := service2.NewSettingService(transactionManager, commonServiceInterface, keyValueRepositoryInterface, settingRepositoryInterface, webhookRepositoryInterface, ebProvider)
-	userServiceInterface := service3.NewUserService(transactionManager, userRepositoryInterface, settingServiceInterface, ebProvider)
+	queueRepositoryInterface := repository10.NewQueueRepository(dbProvider)
+	webhookDispatcher := ProvideWebhookDispatcher(ebProvider, webhookRepositoryInterface, queueRepositoryInterface, transactionManager)
+	settingServiceInterface := service2.NewSettingService(transactionManager, commonServiceInterface, keyValueRepositoryInterface, settingRepositoryInterface, webhookRepositoryInterface, webhookDispatcher, ebProvider)
+	sessionRepositoryInterface := repository11.NewSessionRepository(dbProvider)
+	sessionServiceInterface := service16.NewSessionService(transactionManager, sessionRepositoryInterface, userRepositoryInterface)
+	userServiceInterface := service3.NewUserService(transactionManager, userRepositoryInterface, settingServiceInterface, sessionServiceInterface, ebProvider)
 	userHandler := handler2.NewUserHandler(userServiceInterface)
 	fediverseRepositoryInterface := repository6.NewFediverseRepository(dbProvider)
-	fediverseCore := fediverse.NewFediverseCore(fediverseRepositoryInterface, keyValueRepositoryInterface, userRepositoryInterface, echoRepositoryInterface)
-	fediverseServiceInterface := service4.NewFediverseService(fediverseCore, transactionManager, fediverseRepositoryInterface, userRepositoryInterface, echoRepositoryInterface)
+	fediverseCore := fediverse.NewFediverseCore(fediverseRepositoryInterface, keyValueRepositoryInterface, userRepositoryInterface, echoRepositoryInterface, iCache)
+	inboxRepositoryInterface := repository7.NewInboxRepository(dbProvider)
+	fediverseServiceInterface := service4.NewFediverseService(fediverseCore, transactionManager, fediverseRepositoryInterface, userRepositoryInterface, echoRepositoryInterface, inboxRepositoryInterface)
 	echoServiceInterface := service5.NewEchoService(transactionManager, commonServiceInterface, echoRepositoryInterface, commonRepositoryInterface, fediverseServiceInterface, keyValueRepositoryInterface, ebProvider)
 	echoHandler := handler3.NewEchoHandler(echoServiceInterface)
 	commonHandler := handler4.NewCommonHandler(commonServiceInterface)
 	settingHandler := handler5.NewSettingHandler(settingServiceInterface)
-	inboxRepositoryInterface := repository7.NewInboxRepository(dbProvider)
 	inboxServiceInterface := service6.NewInboxService(transactionManager, commonServiceInterface, inboxRepositoryInterface)
 	inboxHandler := handler6.NewInboxHandler(inboxServiceInterface)
 	todoRepositoryInterface := repository8.NewTodoRepository(dbProvider, iCache)
@@ -94,7 +109,16 @@ func BuildHandlers(dbProvider func() *gorm.DB, cacheFactory *cache.CacheFactory,
 	dashboardHandler := handler11.NewDashboardHandler(dashboardServiceInterface)
 	agentServiceInterface := service11.NewAgentService(settingServiceInterface, echoServiceInterface, todoServiceInterface, keyValueRepositoryInterface)
 	agentHandler := handler12.NewAgentHandler(agentServiceInterface)
-	handlers := NewHandlers(webHandler, userHandler, echoHandler, commonHandler, settingHandler, inboxHandler, todoHandler, connectHandler, backupHandler, fediverseHandler, dashboardHandler, agentHandler)
+	queueServiceInterface := service12.NewQueueService(transactionManager, commonServiceInterface, queueRepositoryInterface, ebProvider)
+	queueHandler := handler13.NewQueueHandler(queueServiceInterface)
+	trashServiceInterface := service13.NewTrashService(transactionManager, commonServiceInterface, echoRepositoryInterface, todoRepositoryInterface, ebProvider)
+	trashHandler := handler14.NewTrashHandler(trashServiceInterface)
+	importServiceInterface := service14.NewImportService(transactionManager, commonServiceInterface, echoServiceInterface, echoRepositoryInterface)
+	importerHandler := handler15.NewImporterHandler(importServiceInterface)
+	exportServiceInterface := service15.NewExportService(commonServiceInterface, echoRepositoryInterface, keyValueRepositoryInterface)
+	exporterHandler := handler16.NewExporterHandler(exportServiceInterface)
+	sessionHandler := handler17.NewSessionHandler(sessionServiceInterface)
+	handlers := NewHandlers(webHandler, userHandler, echoHandler, commonHandler, settingHandler, inboxHandler, todoHandler, connectHandler, backupHandler, fediverseHandler, dashboardHandler, agentHandler, queueHandler, trashHandler, importerHandler, exporterHandler, sessionHandler)
 	return handlers, nil
 }
 
@@ -107,31 +131,71 @@ func BuildTasker(dbProvider func() *gorm.DB, cacheFactory *cache.CacheFactory, t
 	commonServiceInterface := service.NewCommonService(transactionManager, commonRepositoryInterface, echoRepositoryInterface, keyValueRepositoryInterface, ebProvider)
 	settingRepositoryInterface := repository4.NewSettingRepository(dbProvider)
 	webhookRepositoryInterface := repository5.NewWebhookRepository(dbProvider)
-	settingServiceInterface := service2.NewSettingService(transactionManager, commonServiceInterface, keyValueRepositoryInterface, settingRepositoryInterface, webhookRepositoryInterface, ebProvider)
 	queueRepositoryInterface := repository10.NewQueueRepository(dbProvider)
-	tasker := task.NewTasker(commonServiceInterface, settingServiceInterface, ebProvider, queueRepositoryInterface)
+	webhookDispatcher := ProvideWebhookDispatcher(ebProvider, webhookRepositoryInterface, queueRepositoryInterface, transactionManager)
+	settingServiceInterface := service2.NewSettingService(transactionManager, commonServiceInterface, keyValueRepositoryInterface, settingRepositoryInterface, webhookRepositoryInterface, webhookDispatcher, ebProvider)
+	userRepositoryInterface := repository.NewUserRepository(dbProvider, iCache)
+	fediverseRepositoryInterface := repository6.NewFediverseRepository(dbProvider)
+	fediverseCore := fediverse.NewFediverseCore(fediverseRepositoryInterface, keyValueRepositoryInterface, userRepositoryInterface, echoRepositoryInterface, iCache)
+	inboxRepositoryInterface := repository7.NewInboxRepository(dbProvider)
+	fediverseServiceInterface := service4.NewFediverseService(fediverseCore, transactionManager, fediverseRepositoryInterface, userRepositoryInterface, echoRepositoryInterface, inboxRepositoryInterface)
+	echoServiceInterface := service5.NewEchoService(transactionManager, commonServiceInterface, echoRepositoryInterface, commonRepositoryInterface, fediverseServiceInterface, keyValueRepositoryInterface, ebProvider)
+	todoRepositoryInterface := repository8.NewTodoRepository(dbProvider, iCache)
+	trashServiceInterface := service13.NewTrashService(transactionManager, commonServiceInterface, echoRepositoryInterface, todoRepositoryInterface, ebProvider)
+	tasker := task.NewTasker(commonServiceInterface, settingServiceInterface, echoServiceInterface, trashServiceInterface, ebProvider, queueRepositoryInterface)
 	return tasker, nil
 }
 
+// BuildImporter 构建命令行导入使用的 ImportService
+func BuildImporter(dbProvider func() *gorm.DB, cacheFactory *cache.CacheFactory, tmFactory *transaction.TransactionManagerFactory, ebProvider func() event.IEventBus) (service14.ImportServiceInterface, error) {
+	transactionManager := ProvideTransactionManager(tmFactory)
+	commonRepositoryInterface := repository2.NewCommonRepository(dbProvider)
+	iCache := ProvideCache(cacheFactory)
+	echoRepositoryInterface := repository3.NewEchoRepository(dbProvider, iCache)
+	keyValueRepositoryInterface := keyvalue.NewKeyValueRepository(dbProvider, iCache)
+	commonServiceInterface := service.NewCommonService(transactionManager, commonRepositoryInterface, echoRepositoryInterface, keyValueRepositoryInterface, ebProvider)
+	fediverseRepositoryInterface := repository6.NewFediverseRepository(dbProvider)
+	userRepositoryInterface := repository.NewUserRepository(dbProvider, iCache)
+	fediverseCore := fediverse.NewFediverseCore(fediverseRepositoryInterface, keyValueRepositoryInterface, userRepositoryInterface, echoRepositoryInterface, iCache)
+	inboxRepositoryInterface := repository7.NewInboxRepository(dbProvider)
+	fediverseServiceInterface := service4.NewFediverseService(fediverseCore, transactionManager, fediverseRepositoryInterface, userRepositoryInterface, echoRepositoryInterface, inboxRepositoryInterface)
+	echoServiceInterface := service5.NewEchoService(transactionManager, commonServiceInterface, echoRepositoryInterface, commonRepositoryInterface, fediverseServiceInterface, keyValueRepositoryInterface, ebProvider)
+	importServiceInterface := service14.NewImportService(transactionManager, commonServiceInterface, echoServiceInterface, echoRepositoryInterface)
+	return importServiceInterface, nil
+}
+
+// BuildExporter 构建命令行导出使用的 ExportService
+func BuildExporter(dbProvider func() *gorm.DB, cacheFactory *cache.CacheFactory, tmFactory *transaction.TransactionManagerFactory, ebProvider func() event.IEventBus) (service15.ExportServiceInterface, error) {
+	transactionManager := ProvideTransactionManager(tmFactory)
+	commonRepositoryInterface := repository2.NewCommonRepository(dbProvider)
+	iCache := ProvideCache(cacheFactory)
+	echoRepositoryInterface := repository3.NewEchoRepository(dbProvider, iCache)
+	keyValueRepositoryInterface := keyvalue.NewKeyValueRepository(dbProvider, iCache)
+	commonServiceInterface := service.NewCommonService(transactionManager, commonRepositoryInterface, echoRepositoryInterface, keyValueRepositoryInterface, ebProvider)
+	exportServiceInterface := service15.NewExportService(commonServiceInterface, echoRepositoryInterface, keyValueRepositoryInterface)
+	return exportServiceInterface, nil
+}
+
 func BuildEventRegistrar(dbProvider func() *gorm.DB, ebProvider func() event.IEventBus, cacheFactory *cache.CacheFactory, tmFactory *transaction.TransactionManagerFactory) (*event.EventRegistrar, error) {
 	webhookRepositoryInterface := repository5.NewWebhookRepository(dbProvider)
 	queueRepositoryInterface := repository10.NewQueueRepository(dbProvider)
 	transactionManager := ProvideTransactionManager(tmFactory)
-	webhookDispatcher := event.NewWebhookDispatcher(ebProvider, webhookRepositoryInterface, queueRepositoryInterface, transactionManager)
+	webhookDispatcher := ProvideWebhookDispatcher(ebProvider, webhookRepositoryInterface, queueRepositoryInterface, transactionManager)
 	fediverseRepositoryInterface := repository6.NewFediverseRepository(dbProvider)
 	iCache := ProvideCache(cacheFactory)
 	keyValueRepositoryInterface := keyvalue.NewKeyValueRepository(dbProvider, iCache)
 	userRepositoryInterface := repository.NewUserRepository(dbProvider, iCache)
 	echoRepositoryInterface := repository3.NewEchoRepository(dbProvider, iCache)
-	fediverseCore := fediverse.NewFediverseCore(fediverseRepositoryInterface, keyValueRepositoryInterface, userRepositoryInterface, echoRepositoryInterface)
+	fediverseCore := fediverse.NewFediverseCore(fediverseRepositoryInterface, keyValueRepositoryInterface, userRepositoryInterface, echoRepositoryInterface, iCache)
 	fediverseAgent := event.NewFediverseAgent(fediverseCore, queueRepositoryInterface, transactionManager)
-	deadLetterResolver := event.NewDeadLetterResolver(queueRepositoryInterface, webhookDispatcher, fediverseAgent)
+	inboxRepositoryInterface := repository7.NewInboxRepository(dbProvider)
+	deadLetterResolver := event.NewDeadLetterResolver(ebProvider, queueRepositoryInterface, inboxRepositoryInterface, webhookDispatcher, fediverseAgent)
 	backupScheduler := event.NewBackupScheduler()
 	todoRepositoryInterface := repository8.NewTodoRepository(dbProvider, iCache)
-	inboxRepositoryInterface := repository7.NewInboxRepository(dbProvider)
 	agentProcessor := event.NewAgentProcessor(echoRepositoryInterface, todoRepositoryInterface, userRepositoryInterface, keyValueRepositoryInterface, inboxRepositoryInterface)
 	inboxDispatcher := event.NewInboxDispatcher(inboxRepositoryInterface, keyValueRepositoryInterface)
-	eventHandlers := event.NewEventHandlers(webhookDispatcher, deadLetterResolver, fediverseAgent, backupScheduler, agentProcessor, inboxDispatcher)
+	searchIndexer := event.NewSearchIndexer(echoRepositoryInterface)
+	eventHandlers := event.NewEventHandlers(webhookDispatcher, deadLetterResolver, fediverseAgent, backupScheduler, agentProcessor, inboxDispatcher, searchIndexer)
 	eventRegistrar := event.NewEventRegistry(ebProvider, eventHandlers)
 	return eventRegistrar, nil
 }
@@ -154,6 +218,9 @@ var WebSet = wire.NewSet(handler.NewWebHandler)
 // UserSet 包含了构建 UserHandler 所需的所有 Provider
 var UserSet = wire.NewSet(repository.NewUserRepository, service3.NewUserService, handler2.NewUserHandler)
 
+// SessionSet 包含了构建 SessionHandler 所需的所有 Provider
+var SessionSet = wire.NewSet(repository11.NewSessionRepository, service16.NewSessionService, handler17.NewSessionHandler)
+
 // EchoSet 包含了构建 EchoHandler 所需的所有 Provider
 var EchoSet = wire.NewSet(repository3.NewEchoRepository, service5.NewEchoService, handler3.NewEchoHandler)
 
@@ -182,7 +249,7 @@ var DashboardSet = wire.NewSet(service10.NewDashboardService, handler11.NewDashb
 var AgentSet = wire.NewSet(service11.NewAgentService, handler12.NewAgentHandler)
 
 // WebhookSet 包含了构建 WebhookDispatcher 所需的所有 Provider
-var WebhookSet = wire.NewSet(repository5.NewWebhookRepository)
+var WebhookSet = wire.NewSet(repository5.NewWebhookRepository, ProvideWebhookDispatcher)
 
 // InboxSet 包含了构建 InboxRepository 所需的所有 Provider
 var InboxSet = wire.NewSet(repository7.NewInboxRepository, service6.NewInboxService, handler6.NewInboxHandler)
@@ -191,7 +258,16 @@ var InboxSet = wire.NewSet(repository7.NewInboxRepository, service6.NewInboxServ
 var TaskSet = wire.NewSet(task.NewTasker)
 
 // QueueSet 包含了构建 Queue 所需的所有 Provider
-var QueueSet = wire.NewSet(repository10.NewQueueRepository)
+var QueueSet = wire.NewSet(repository10.NewQueueRepository, service12.NewQueueService, handler13.NewQueueHandler)
+
+// TrashSet 包含了构建回收站所需的所有 Provider
+var TrashSet = wire.NewSet(service13.NewTrashService, handler14.NewTrashHandler)
+
+// ImporterSet 包含了构建导入功能所需的所有 Provider
+var ImporterSet = wire.NewSet(service14.NewImportService, handler15.NewImporterHandler)
+
+// ExporterSet 包含了构建导出功能所需的所有 Provider
+var ExporterSet = wire.NewSet(service15.NewExportService, handler16.NewExporterHandler)
 
 // FediverseCoreSet 包含了构建 FediverseCore 所需的所有 Provider
 var FediverseCoreSet = wire.NewSet(fediverse.NewFediverseCore)
@@ -200,7 +276,7 @@ var FediverseCoreSet = wire.NewSet(fediverse.NewFediverseCore)
 var FediverseSet = wire.NewSet(repository6.NewFediverseRepository, service4.NewFediverseService, handler10.NewFediverseHandler, event.NewFediverseAgent)
 
 // EventSet 包含了构建 Event 相关所需的所有 Provider
-var EventSet = wire.NewSet(event.NewWebhookDispatcher, event.NewBackupScheduler, event.NewDeadLetterResolver, event.NewAgentProcessor, event.NewInboxDispatcher, event.NewEventHandlers, event.NewEventRegistry)
+var EventSet = wire.NewSet(event.NewBackupScheduler, event.NewDeadLetterResolver, event.NewAgentProcessor, event.NewInboxDispatcher, event.NewEventHandlers, event.NewEventRegistry)
 
 // MetricSet 包含了构建 Metric 相关所需的所有 Provider
 var MetricSet = wire.NewSet(metric.NewSystemCollector)
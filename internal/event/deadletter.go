@@ -2,118 +2,176 @@ package event
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
+	"github.com/lin-snow/ech0/internal/config"
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	inboxModel "github.com/lin-snow/ech0/internal/model/inbox"
 	queueModel "github.com/lin-snow/ech0/internal/model/queue"
+	inboxRepository "github.com/lin-snow/ech0/internal/repository/inbox"
 	queueRepository "github.com/lin-snow/ech0/internal/repository/queue"
 )
 
+// errDeadLetterObsolete 死信任务的投递目标已不存在或不再需要投递，任务直接丢弃
+var errDeadLetterObsolete = errors.New("dead letter target is obsolete")
+
 type DeadLetterResolver struct {
+	bus       IEventBus
 	queueRepo queueRepository.QueueRepositoryInterface
+	inboxRepo inboxRepository.InboxRepositoryInterface
 	whd       *WebhookDispatcher
 	fa        *FediverseAgent
 }
 
 func NewDeadLetterResolver(
+	ebp func() IEventBus,
 	queueRepo queueRepository.QueueRepositoryInterface,
+	inboxRepo inboxRepository.InboxRepositoryInterface,
 	whd *WebhookDispatcher,
 	fa *FediverseAgent,
 ) *DeadLetterResolver {
 	return &DeadLetterResolver{
+		bus:       ebp(),
 		queueRepo: queueRepo,
+		inboxRepo: inboxRepo,
 		whd:       whd,
 		fa:        fa,
 	}
 }
 
-func (dlr *DeadLetterResolver) Handle(ctx context.Context, event *Event) error {
-	// 取出 dead letter
-	deadLetter, err := event.Payload[EventPayloadDeadLetter].(queueModel.DeadLetter)
-	if !err {
-		return fmt.Errorf("failed to extract dead letter: %v", err)
-	}
-
-	// 判断死信的状态
-	switch deadLetter.Status {
-	// 如果是待处理状态，尝试重新处理
-	case queueModel.DeadLetterStatusPending:
-		// 更新状态为处理中
-		deadLetter.Status = queueModel.DeadLetterStatusProcessing
-		deadLetter.RetryCount += 1
-		deadLetter.UpdatedAt = time.Now()
-		deadLetter.NextRetry = time.Now().Add(6 * time.Hour)
-
-		if err := dlr.queueRepo.UpdateDeadLetter(ctx, &deadLetter); err != nil {
-			return fmt.Errorf("failed to update dead letter to processing: %v", err)
-		}
+// DeadLetterBackoffPolicy 读取配置中的死信重试退避策略，未配置的字段使用默认值
+func DeadLetterBackoffPolicy() queueModel.BackoffPolicy {
+	policy := queueModel.DefaultBackoffPolicy
+	cfg := config.Config.DeadLetter
 
-		// 开始处理死信任务
-		if err := dlr.processDeadLetter(ctx, &deadLetter); err != nil {
-			// 处理失败，更新状态为失败
-			deadLetter.ErrorMsg = err.Error()
-			deadLetter.Status = queueModel.DeadLetterStatusFailed
-			deadLetter.UpdatedAt = time.Now()
-			if err := dlr.queueRepo.UpdateDeadLetter(ctx, &deadLetter); err != nil {
-				return fmt.Errorf("failed to update dead letter to failed: %v", err)
-			}
-			return fmt.Errorf("failed to process dead letter: %v", err)
-		}
+	if cfg.MaxRetries > 0 {
+		policy.MaxRetries = cfg.MaxRetries
+	}
+	if cfg.InitialBackoff > 0 {
+		policy.InitialBackoff = time.Duration(cfg.InitialBackoff) * time.Second
+	}
+	if cfg.MaxBackoff > 0 {
+		policy.MaxBackoff = time.Duration(cfg.MaxBackoff) * time.Second
+	}
+	if cfg.Multiplier >= 1 {
+		policy.Multiplier = cfg.Multiplier
+	}
 
-		// 处理成功，更新状态为完成
-		deadLetter.Status = queueModel.DeadLetterStatusCompleted
-		deadLetter.UpdatedAt = time.Now()
-		if err := dlr.queueRepo.UpdateDeadLetter(ctx, &deadLetter); err != nil {
-			return fmt.Errorf("failed to update dead letter to completed: %v", err)
-		}
+	return policy
+}
+
+func (dlr *DeadLetterResolver) Handle(ctx context.Context, event *Event) error {
+	// 取出 dead letter
+	deadLetter, ok := event.Payload[EventPayloadDeadLetter].(queueModel.DeadLetter)
+	if !ok {
+		return fmt.Errorf("failed to extract dead letter from event %s", event.ID)
+	}
 
+	// 事件中的死信任务可能已过期或被去除了负载，以数据库中的最新记录为准
+	current, err := dlr.queueRepo.GetDeadLetterByID(deadLetter.ID)
+	if err != nil {
+		return fmt.Errorf("failed to load dead letter %d: %v", deadLetter.ID, err)
+	}
+	if current == nil {
 		return nil
+	}
+	deadLetter = *current
 
-	// 处理中的死信任务，跳过
-	case queueModel.DeadLetterStatusProcessing:
-		// 处理中的死信任务，跳过
+	// 仅处理待处理状态的死信任务，其余状态由管理接口或命令行处理
+	if deadLetter.Status != queueModel.DeadLetterStatusPending {
 		return nil
+	}
 
-	// 失败的死信任务，记录日志并跳过
-	case queueModel.DeadLetterStatusFailed:
-		// 失败的死信任务，检查重试次数
-		if deadLetter.RetryCount <= 3 {
-			// 重试次数未超过限制，更新状态为 pending 以便重新处理
-			deadLetter.Status = queueModel.DeadLetterStatusPending
-		} else {
-			// 超过重试次数，更新状态为 discarded
-			deadLetter.Status = queueModel.DeadLetterStatusDiscarded
-		}
+	// 更新状态为处理中
+	deadLetter.Status = queueModel.DeadLetterStatusProcessing
+	deadLetter.RetryCount += 1
+	deadLetter.UpdatedAt = time.Now()
+	if err := dlr.queueRepo.UpdateDeadLetter(ctx, &deadLetter); err != nil {
+		return fmt.Errorf("failed to update dead letter to processing: %v", err)
+	}
 
-		// 更新死信任务
-		deadLetter.UpdatedAt = time.Now()
-		if err := dlr.queueRepo.UpdateDeadLetter(ctx, &deadLetter); err != nil {
-			return fmt.Errorf("failed to update dead letter: %v", err)
+	// 开始处理死信任务
+	if err := dlr.processDeadLetter(ctx, &deadLetter); err != nil {
+		if errors.Is(err, errDeadLetterObsolete) {
+			deadLetter.Discard(time.Now())
+			deadLetter.ErrorMsg = err.Error()
+			return dlr.queueRepo.UpdateDeadLetter(ctx, &deadLetter)
 		}
-		return nil
+		return dlr.handleFailure(ctx, &deadLetter, err)
+	}
 
-	case queueModel.DeadLetterStatusDiscarded:
-		// discarded 状态的死信任务，删除
-		if err := dlr.queueRepo.DeleteDeadLetter(ctx, deadLetter.ID); err != nil {
-			return fmt.Errorf("failed to delete discarded dead letter: %v", err)
-		}
-		return nil
+	// 处理成功，更新状态为完成
+	deadLetter.Status = queueModel.DeadLetterStatusCompleted
+	deadLetter.ErrorMsg = ""
+	deadLetter.UpdatedAt = time.Now()
+	if err := dlr.queueRepo.UpdateDeadLetter(ctx, &deadLetter); err != nil {
+		return fmt.Errorf("failed to update dead letter to completed: %v", err)
+	}
 
-	case queueModel.DeadLetterStatusCompleted:
-		// completed 状态的死信任务，删除
-		if err := dlr.queueRepo.DeleteDeadLetter(ctx, deadLetter.ID); err != nil {
-			return fmt.Errorf("failed to delete completed dead letter: %v", err)
-		}
-		return nil
+	return nil
+}
 
-	default:
-		// 未知状态，记录日志并修改状态为 discarded
-		deadLetter.Status = queueModel.DeadLetterStatusDiscarded
-		if err := dlr.queueRepo.DeleteDeadLetter(ctx, deadLetter.ID); err != nil {
-			return fmt.Errorf("failed to delete unknown status dead letter: %v", err)
+// handleFailure 处理重试失败：未达到最大重试次数时按退避策略安排下一次重试，否则标记为失败并通知管理员
+func (dlr *DeadLetterResolver) handleFailure(
+	ctx context.Context,
+	deadLetter *queueModel.DeadLetter,
+	processErr error,
+) error {
+	policy := DeadLetterBackoffPolicy()
+	now := time.Now()
+
+	deadLetter.ErrorMsg = processErr.Error()
+	deadLetter.UpdatedAt = now
+
+	exhausted := policy.Exhausted(deadLetter.RetryCount)
+	if exhausted {
+		deadLetter.Status = queueModel.DeadLetterStatusFailed
+	} else {
+		deadLetter.Status = queueModel.DeadLetterStatusPending
+		deadLetter.NextRetry = policy.NextRetry(deadLetter.RetryCount, now)
+	}
+
+	if err := dlr.queueRepo.UpdateDeadLetter(ctx, deadLetter); err != nil {
+		return fmt.Errorf("failed to update dead letter after failure: %v", err)
+	}
+
+	if exhausted {
+		if err := dlr.notifyFailed(ctx, deadLetter); err != nil {
+			return fmt.Errorf("failed to notify failed dead letter: %v", err)
 		}
-		return fmt.Errorf("unknown dead letter status: %s", deadLetter.Status)
 	}
+
+	return fmt.Errorf("failed to process dead letter: %v", processErr)
+}
+
+// notifyFailed 死信任务超过最大重试次数后发送收件箱通知
+func (dlr *DeadLetterResolver) notifyFailed(
+	ctx context.Context,
+	deadLetter *queueModel.DeadLetter,
+) error {
+	meta, _ := json.Marshal(map[string]any{
+		"dead_letter_id": deadLetter.ID,
+		"type":           deadLetter.Type,
+		"retry_count":    deadLetter.RetryCount,
+		"error":          deadLetter.ErrorMsg,
+	})
+
+	return dlr.inboxRepo.PostInbox(ctx, &inboxModel.Inbox{
+		Source: string(commonModel.SystemSource),
+		Content: fmt.Sprintf(
+			"死信任务 #%d (%s) 重试 %d 次后仍然失败：%s",
+			deadLetter.ID,
+			deadLetter.Type,
+			deadLetter.RetryCount,
+			deadLetter.ErrorMsg,
+		),
+		Type:      string(commonModel.NotificationInboxType),
+		Meta:      string(meta),
+		CreatedAt: time.Now().Unix(),
+	})
 }
 
 // 处理死信任务
@@ -131,7 +189,34 @@ func (dlr *DeadLetterResolver) processDeadLetter(
 		// 处理 push echo federiverse 类型的死信任务
 		return dlr.fa.HandlePushEchoDeadLetter(ctx, deadLetter)
 
+	case queueModel.DeadLetterTypeUpdateEchoFediverse:
+		// 处理 update echo federiverse 类型的死信任务
+		return dlr.fa.HandleUpdateEchoDeadLetter(ctx, deadLetter)
+
+	case queueModel.DeadLetterTypeDeleteEchoFediverse:
+		// 处理 delete echo federiverse 类型的死信任务
+		return dlr.fa.HandleDeleteEchoDeadLetter(ctx, deadLetter)
+
+	case queueModel.DeadLetterTypeEvent:
+		// 处理事件总线中超过重试次数的事件
+		return dlr.redeliverEvent(deadLetter)
+
 	default:
 		return fmt.Errorf("unknown dead letter type: %s", deadLetter.Type)
 	}
 }
+
+// redeliverEvent 将死信中的事件重新交给原订阅者处理
+func (dlr *DeadLetterResolver) redeliverEvent(deadLetter *queueModel.DeadLetter) error {
+	var payload EventReplayPayload
+	if err := json.Unmarshal(deadLetter.Payload, &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal dead letter payload: %w", err)
+	}
+
+	// 只有持久化事件总线会写入事件类型的死信任务
+	bus, ok := dlr.bus.(*PersistentEventBus)
+	if !ok {
+		return fmt.Errorf("event bus does not support redelivery: %w", errDeadLetterObsolete)
+	}
+	return bus.Redeliver(payload)
+}
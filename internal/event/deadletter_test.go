@@ -0,0 +1,191 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/lin-snow/ech0/internal/config"
+	inboxModel "github.com/lin-snow/ech0/internal/model/inbox"
+	queueModel "github.com/lin-snow/ech0/internal/model/queue"
+	webhookModel "github.com/lin-snow/ech0/internal/model/webhook"
+	inboxRepository "github.com/lin-snow/ech0/internal/repository/inbox"
+)
+
+// saveWebhookDeadLetter 保存一条 webhook 死信任务，负载中的 webhook 为失败时的快照
+func saveWebhookDeadLetter(t *testing.T, wd *WebhookDispatcher, snapshot webhookModel.Webhook) queueModel.DeadLetter {
+	t.Helper()
+	payload, _ := json.Marshal(WebhookReplayPayload{
+		Webhook: snapshot,
+		Event:   *NewEvent(EventTypeEchoCreated, EventPayload{}),
+	})
+	deadLetter := queueModel.DeadLetter{
+		Type:      queueModel.DeadLetterTypeWebhook,
+		Payload:   payload,
+		Status:    queueModel.DeadLetterStatusPending,
+		NextRetry: time.Now(),
+	}
+	if err := wd.queueRepo.SaveDeadLetter(context.Background(), &deadLetter); err != nil {
+		t.Fatalf("save dead letter: %v", err)
+	}
+	return deadLetter
+}
+
+func resolveDeadLetter(t *testing.T, db *gorm.DB, wd *WebhookDispatcher, deadLetter queueModel.DeadLetter) queueModel.DeadLetter {
+	t.Helper()
+	resolver := NewDeadLetterResolver(
+		func() IEventBus { return wd.bus },
+		wd.queueRepo,
+		inboxRepository.NewInboxRepository(func() *gorm.DB { return db }),
+		wd,
+		nil,
+	)
+	_ = resolver.Handle(context.Background(), NewEvent(EventTypeDeadLetterRetried, EventPayload{
+		EventPayloadDeadLetter: deadLetter,
+	}))
+
+	current, err := wd.queueRepo.GetDeadLetterByID(deadLetter.ID)
+	if err != nil || current == nil {
+		t.Fatalf("load dead letter: %v", err)
+	}
+	return *current
+}
+
+func TestHandleDeadLetterUsesCurrentWebhook(t *testing.T) {
+	db, wd := newTestDispatcher(t)
+	hs := newHookServer(t, "ok")
+	wh := webhookModel.Webhook{Name: "hook", URL: hs.URL, IsActive: true}
+	if err := db.Create(&wh).Error; err != nil {
+		t.Fatalf("create webhook: %v", err)
+	}
+
+	// 快照中是修改前已失效的地址
+	snapshot := wh
+	snapshot.URL = "http://127.0.0.1:1/unreachable"
+	deadLetter := resolveDeadLetter(t, db, wd, saveWebhookDeadLetter(t, wd, snapshot))
+
+	if deadLetter.Status != queueModel.DeadLetterStatusCompleted {
+		t.Fatalf("status = %s (%s), want completed", deadLetter.Status, deadLetter.ErrorMsg)
+	}
+	var delivery webhookModel.WebhookDelivery
+	if err := db.Where("dead_letter_id = ?", deadLetter.ID).First(&delivery).Error; err != nil {
+		t.Fatalf("load delivery: %v", err)
+	}
+	if delivery.RequestURL != hs.URL || !delivery.Success {
+		t.Errorf("delivery = %s success %v", delivery.RequestURL, delivery.Success)
+	}
+}
+
+func TestHandleDeadLetterDiscardsObsoleteWebhook(t *testing.T) {
+	tests := map[string]func(db *gorm.DB, wh *webhookModel.Webhook){
+		"deleted": func(db *gorm.DB, wh *webhookModel.Webhook) {
+			db.Delete(wh)
+		},
+		"inactive": func(db *gorm.DB, wh *webhookModel.Webhook) {
+			db.Model(wh).Update("is_active", false)
+		},
+		"unsubscribed": func(db *gorm.DB, wh *webhookModel.Webhook) {
+			wh.Events = []string{"user.*"}
+			db.Save(wh)
+		},
+	}
+
+	for name, change := range tests {
+		t.Run(name, func(t *testing.T) {
+			db, wd := newTestDispatcher(t)
+			hs := newHookServer(t, "ok")
+			wh := webhookModel.Webhook{Name: "hook", URL: hs.URL, IsActive: true}
+			if err := db.Create(&wh).Error; err != nil {
+				t.Fatalf("create webhook: %v", err)
+			}
+			deadLetter := saveWebhookDeadLetter(t, wd, wh)
+			change(db, &wh)
+
+			deadLetter = resolveDeadLetter(t, db, wd, deadLetter)
+			if deadLetter.Status != queueModel.DeadLetterStatusDiscarded {
+				t.Errorf("status = %s, want discarded", deadLetter.Status)
+			}
+			var deliveries int64
+			db.Model(&webhookModel.WebhookDelivery{}).Count(&deliveries)
+			if deliveries != 0 {
+				t.Errorf("delivered %d times to an obsolete webhook", deliveries)
+			}
+		})
+	}
+}
+
+func TestPurgeDeadLettersBefore(t *testing.T) {
+	db, wd := newTestDispatcher(t)
+	now := time.Now()
+	letters := []queueModel.DeadLetter{
+		{Status: queueModel.DeadLetterStatusCompleted, UpdatedAt: now.Add(-webhookModel.DeliveryRetention - time.Hour)},
+		{Status: queueModel.DeadLetterStatusCompleted, UpdatedAt: now.Add(-time.Hour)},
+		{Status: queueModel.DeadLetterStatusFailed, UpdatedAt: now.Add(-webhookModel.DeliveryRetention - time.Hour)},
+	}
+	if err := db.Create(&letters).Error; err != nil {
+		t.Fatalf("create dead letters: %v", err)
+	}
+
+	// 只删除超过保留期的已完成任务
+	purged, err := wd.queueRepo.PurgeDeadLettersBefore(
+		context.Background(),
+		now.Add(-webhookModel.DeliveryRetention),
+		queueModel.DeadLetterStatusCompleted,
+	)
+	if err != nil {
+		t.Fatalf("purge: %v", err)
+	}
+	if purged != 1 {
+		t.Errorf("purged %d, want 1", purged)
+	}
+	if dl, _ := wd.queueRepo.GetDeadLetterByID(letters[0].ID); dl != nil {
+		t.Error("expired completed dead letter was kept")
+	}
+	for _, kept := range letters[1:] {
+		if dl, _ := wd.queueRepo.GetDeadLetterByID(kept.ID); dl == nil {
+			t.Errorf("dead letter %d (%s) was purged", kept.ID, kept.Status)
+		}
+	}
+}
+
+func TestDeadLetterBackoffAndFailure(t *testing.T) {
+	db, wd := newTestDispatcher(t)
+	saved := config.Config.DeadLetter
+	t.Cleanup(func() { config.Config.DeadLetter = saved })
+	config.Config.DeadLetter.MaxRetries = 2
+	config.Config.DeadLetter.InitialBackoff = 60
+
+	// 未知类型的死信任务每次处理都会失败
+	deadLetter := queueModel.DeadLetter{Type: "unknown", Status: queueModel.DeadLetterStatusPending, NextRetry: time.Now()}
+	if err := wd.queueRepo.SaveDeadLetter(context.Background(), &deadLetter); err != nil {
+		t.Fatalf("save dead letter: %v", err)
+	}
+
+	before := time.Now()
+	deadLetter = resolveDeadLetter(t, db, wd, deadLetter)
+	if deadLetter.Status != queueModel.DeadLetterStatusPending || deadLetter.RetryCount != 1 {
+		t.Fatalf("after first failure: status %s, retries %d", deadLetter.Status, deadLetter.RetryCount)
+	}
+	if deadLetter.NextRetry.Before(before.Add(time.Minute)) {
+		t.Errorf("next retry %v is earlier than the initial backoff", deadLetter.NextRetry)
+	}
+
+	// 达到最大重试次数后标记为失败并通知管理员
+	deadLetter = resolveDeadLetter(t, db, wd, deadLetter)
+	if deadLetter.Status != queueModel.DeadLetterStatusFailed || deadLetter.ErrorMsg == "" {
+		t.Fatalf("after last failure: status %s, error %q", deadLetter.Status, deadLetter.ErrorMsg)
+	}
+	var notifications int64
+	db.Model(&inboxModel.Inbox{}).Count(&notifications)
+	if notifications != 1 {
+		t.Errorf("notifications = %d, want 1", notifications)
+	}
+
+	// 失败的任务不再自动重试
+	if again := resolveDeadLetter(t, db, wd, deadLetter); again.RetryCount != deadLetter.RetryCount {
+		t.Errorf("failed dead letter was retried")
+	}
+}
@@ -4,18 +4,23 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/lin-snow/ech0/internal/async"
+	echoModel "github.com/lin-snow/ech0/internal/model/echo"
 	queueModel "github.com/lin-snow/ech0/internal/model/queue"
 	webhookModel "github.com/lin-snow/ech0/internal/model/webhook"
 	queueRepository "github.com/lin-snow/ech0/internal/repository/queue"
 	webhookRepository "github.com/lin-snow/ech0/internal/repository/webhook"
 	"github.com/lin-snow/ech0/internal/transaction"
 	logUtil "github.com/lin-snow/ech0/internal/util/log"
+	webhookUtil "github.com/lin-snow/ech0/pkg/webhook"
 	"go.uber.org/zap"
 )
 
@@ -57,68 +62,103 @@ func NewWebhookDispatcher(
 }
 
 // Handle 由事件总线调用，负责调度事件到每个活跃的 webhook
+//
+// 各 webhook 在任务池中并发投递，全部投递完成或写入死信队列后才返回，
+// 单个 webhook 投递失败由其死信任务重试，只有死信任务保存失败时才返回错误，交由事件总线重试
 func (wd *WebhookDispatcher) Handle(ctx context.Context, e *Event) error {
 	// 获取所有开启的webhook
 	webhooks, err := wd.repo.ListActiveWebhooks()
 	if err != nil {
 		return err
 	}
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
 	for _, wh := range webhooks {
-		// 可以根据 event type 做过滤
-		// if !wh.ShouldHandle(e.Type) {
-		// 	continue
-		// }
+		// 根据订阅的事件类型和负载条件过滤
+		if !shouldDispatch(&wh, e) {
+			continue
+		}
 		wh := wh // 捕获变量
-		// 提交任务到池中异步处理
+		wg.Add(1)
+		// 提交任务到池中并发处理
 		wd.pool.Submit(func() error {
-			wd.Dispatch(ctx, &wh, e)
+			defer wg.Done()
+			if err := wd.Dispatch(ctx, &wh, e); err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
 			return nil
 		})
 	}
+	wg.Wait()
 
-	return nil
+	return errors.Join(errs...)
 }
 
-// Dispatch 负责将事件发送到指定的 webhook
-func (wd *WebhookDispatcher) Dispatch(ctx context.Context, wh *webhookModel.Webhook, e *Event) {
-	// 发送请求，带重试机制
-	err := wd.retryWithBackoff(3, 500*time.Millisecond, func() error {
-		// 构建 HTTP 请求
-		req, err := wd.buildRequest(wh, e)
-		if err != nil {
-			return err
-		}
+// shouldDispatch 判断事件是否满足 Webhook 的事件订阅与负载过滤条件
+func shouldDispatch(wh *webhookModel.Webhook, e *Event) bool {
+	if !wh.ShouldHandle(string(e.Type)) {
+		return false
+	}
+	if !wh.HasEchoFilter() {
+		return true
+	}
 
-		// 发送 HTTP 请求
-		resp, err := wd.client.Do(req)
-		if err != nil {
-			return err
-		}
-		defer func() { _ = resp.Body.Close() }()
+	// 负载过滤仅作用于携带 Echo 的事件
+	echoData, ok := e.Payload[EventPayloadEcho]
+	if !ok {
+		return true
+	}
+	echo, ok := echoData.(echoModel.Echo)
+	if !ok {
+		return false
+	}
 
-		// 处理响应
-		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-			// 成功处理
-			return nil
-		}
+	tags := make([]string, 0, len(echo.Tags))
+	for _, tag := range echo.Tags {
+		tags = append(tags, tag.Name)
+	}
 
-		// 非成功状态码，视为失败
-		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	// 创建、修改与删除事件都携带完整的 Echo，缺少可见性信息时按非公开处理
+	return wh.MatchEcho(echo.Visibility == echoModel.VisibilityPublic, tags)
+}
+
+// Dispatch 负责将事件发送到指定的 webhook，重试后仍失败时写入死信队列，死信任务保存失败时返回错误
+func (wd *WebhookDispatcher) Dispatch(ctx context.Context, wh *webhookModel.Webhook, e *Event) error {
+	// 发送请求，带重试机制，每次尝试都会保存一条投递记录
+	var deliveryIDs []uint
+	attempt := 0
+	err := wd.retryWithBackoff(3, 500*time.Millisecond, func() error {
+		attempt++
+		delivery := &webhookModel.WebhookDelivery{Attempt: attempt}
+		err := wd.deliver(wh, e, delivery)
+		if delivery.ID != 0 {
+			deliveryIDs = append(deliveryIDs, delivery.ID)
+		}
+		return err
 	})
+	wd.updateStatus(wh, err)
+
 	// 如果最终失败，记录到死信队列
 	if err != nil {
 		// 记录失败日志
 		logUtil.GetLogger().
 			Error("Webhook Handle Failed: ", zap.String("name", wh.Name), zap.String("url", wh.URL))
 
-		// 处理失败的事件
-		e.Meta = map[string]any{
+		// 处理失败的事件，事件会被多个 webhook 并发读取，只修改副本
+		deadEvent := *e
+		deadEvent.Meta = map[string]any{
 			queueModel.DeadLetterMetaKey: true, // 标记为死信任务
 		}
 
 		payloadData := WebhookReplayPayload{
 			Webhook: *wh,
-			Event:   *e,
+			Event:   deadEvent,
 		}
 		payload, _ := json.Marshal(payloadData)
 
@@ -128,18 +168,128 @@ func (wd *WebhookDispatcher) Dispatch(ctx context.Context, wh *webhookModel.Webh
 		deadLetter.Payload = payload
 		deadLetter.ErrorMsg = err.Error()
 		deadLetter.RetryCount = 0
-		deadLetter.NextRetry = time.Now().Add(6 * time.Hour) // 初始重试时间为 6 小时后
+		deadLetter.NextRetry = DeadLetterBackoffPolicy().NextRetry(0, time.Now()) // 按退避策略计算首次重试时间
 		deadLetter.CreatedAt = time.Now()
 		deadLetter.UpdatedAt = time.Now()
 		deadLetter.Status = queueModel.DeadLetterStatusPending // 初始状态为待处理
 
-		// 使用事务保存死信任务
+		// 使用事务保存死信任务，并将本次的投递记录关联到该死信任务
 		if err := wd.txManager.Run(func(ctx context.Context) error {
-			return wd.queueRepo.SaveDeadLetter(ctx, &deadLetter)
+			if err := wd.queueRepo.SaveDeadLetter(ctx, &deadLetter); err != nil {
+				return err
+			}
+			return wd.repo.SetDeliveriesDeadLetter(ctx, deliveryIDs, deadLetter.ID)
 		}); err != nil {
 			logUtil.GetLogger().
 				Error("Failed to save dead letter", zap.String("error", err.Error()))
+			return fmt.Errorf("save dead letter for webhook %s: %w", wh.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// Redeliver 立即重新投递一条历史投递记录中的事件，返回新的投递记录
+func (wd *WebhookDispatcher) Redeliver(
+	wh *webhookModel.Webhook,
+	original *webhookModel.WebhookDelivery,
+) (*webhookModel.WebhookDelivery, error) {
+	var e Event
+	if err := json.Unmarshal([]byte(original.RequestBody), &e); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal delivery body: %w", err)
+	}
+
+	delivery := &webhookModel.WebhookDelivery{
+		Attempt:      1,
+		RedeliveryOf: original.ID,
+	}
+	err := wd.deliver(wh, &e, delivery)
+	wd.updateStatus(wh, err)
+
+	return delivery, err
+}
+
+// deliver 发送一次 webhook 请求并保存投递记录，delivery 中需预先填写尝试次数等信息
+func (wd *WebhookDispatcher) deliver(
+	wh *webhookModel.Webhook,
+	e *Event,
+	delivery *webhookModel.WebhookDelivery,
+) error {
+	delivery.WebhookID = wh.ID
+	delivery.EventID = e.ID
+	delivery.EventType = string(e.Type)
+	delivery.RequestURL = wh.URL
+
+	err := func() error {
+		// 构建 HTTP 请求
+		req, err := wd.buildRequest(wh, e)
+		if err != nil {
+			return err
+		}
+		// 投递记录中不保存签名，避免泄露可用于重放的签名值
+		recorded := req.Header.Clone()
+		if recorded.Get(webhookUtil.SignatureHeader) != "" {
+			recorded.Set(webhookUtil.SignatureHeader, "******")
+		}
+		if headers, err := json.Marshal(recorded); err == nil {
+			delivery.RequestHeaders = string(headers)
+		}
+		if body, err := req.GetBody(); err == nil {
+			raw, _ := io.ReadAll(body)
+			delivery.RequestBody = string(raw)
+		}
+
+		// 发送 HTTP 请求
+		start := time.Now()
+		resp, err := wd.client.Do(req)
+		delivery.LatencyMs = time.Since(start).Milliseconds()
+		if err != nil {
+			return err
 		}
+		defer func() { _ = resp.Body.Close() }()
+
+		// 记录响应（截断）
+		delivery.StatusCode = resp.StatusCode
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, webhookModel.DeliveryResponseBodyLimit))
+		delivery.ResponseBody = string(respBody)
+
+		// 处理响应
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			// 成功处理
+			return nil
+		}
+
+		// 非成功状态码，视为失败
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}()
+
+	delivery.Success = err == nil
+	if err != nil {
+		delivery.Error = err.Error()
+	}
+
+	// 投递记录保存失败不影响投递结果
+	if saveErr := wd.repo.SaveDelivery(context.Background(), delivery); saveErr != nil {
+		logUtil.GetLogger().
+			Error("Failed to save webhook delivery", zap.String("error", saveErr.Error()))
+	}
+
+	return err
+}
+
+// updateStatus 更新 webhook 最近调用状态与触发时间
+func (wd *WebhookDispatcher) updateStatus(wh *webhookModel.Webhook, err error) {
+	if wh.ID == 0 {
+		return
+	}
+
+	status := webhookModel.StatusSuccess
+	if err != nil {
+		status = webhookModel.StatusFailed
+	}
+	if updateErr := wd.repo.UpdateWebhookStatus(context.Background(), wh.ID, status, time.Now()); updateErr != nil {
+		logUtil.GetLogger().
+			Error("Failed to update webhook status", zap.String("error", updateErr.Error()))
 	}
 }
 
@@ -148,17 +298,25 @@ func (wd *WebhookDispatcher) buildRequest(
 	wh *webhookModel.Webhook,
 	e *Event,
 ) (*http.Request, error) {
+	// 构造 HTTP 请求体，不向接收方发送用户密码哈希等凭据
+	body, err := json.Marshal(e.withoutCredentials())
+	if err != nil {
+		return nil, err
+	}
+
 	// 构造 HTTP 请求头
 	headers := make(http.Header)
-	headers.Set("Content-Type", "application/json")  // 内容类型
-	headers.Set("X-Ech0-Event", string(e.Type))      // 事件类型
-	headers.Set("User-Agent", "Ech0-Webhook-Client") // 自定义 User-Agent
-	headers.Set("E-Ech0-Event-ID", e.ID)             // 唯一事件 ID，便于接收方去重，保证幂等性
+	headers.Set("Content-Type", "application/json")      // 内容类型
+	headers.Set(webhookUtil.EventHeader, string(e.Type)) // 事件类型
+	headers.Set("User-Agent", "Ech0-Webhook-Client")     // 自定义 User-Agent
+	headers.Set(webhookUtil.EventIDHeader, e.ID)         // 唯一事件 ID，便于接收方去重，保证幂等性
+	headers.Set(webhookUtil.LegacyEventIDHeader, e.ID)   // 旧版事件 ID 头，兼容已有接收方
 
-	// 构造 HTTP 请求体
-	body, err := json.Marshal(e)
-	if err != nil {
-		return nil, err
+	// 配置了密钥时对请求签名，每次重试都会重新生成时间戳
+	if wh.Secret != "" {
+		timestamp := time.Now().Unix()
+		headers.Set(webhookUtil.TimestampHeader, strconv.FormatInt(timestamp, 10))
+		headers.Set(webhookUtil.SignatureHeader, webhookUtil.Sign(wh.Secret, timestamp, body))
 	}
 	bodyReader := io.NopCloser(bytes.NewReader(body))
 
@@ -191,6 +349,9 @@ func (wd *WebhookDispatcher) retryWithBackoff(
 		if err == nil {
 			return nil // 成功
 		}
+		if i == maxRetries-1 {
+			break // 最后一次失败后不再等待
+		}
 		time.Sleep(delay)
 		delay *= 2 // 指数退避
 	}
@@ -212,33 +373,27 @@ func (wd *WebhookDispatcher) HandleDeadLetter(
 	if err := json.Unmarshal(deadLetter.Payload, &payload); err != nil {
 		return fmt.Errorf("failed to unmarshal dead letter payload: %w", err)
 	}
-	webhook := payload.Webhook
 	event := payload.Event
 
-	// 重新发送请求
-	err := wd.retryWithBackoff(3, 500*time.Millisecond, func() error {
-		// 构建 HTTP 请求
-		req, err := wd.buildRequest(&webhook, &event)
-		if err != nil {
-			return err
-		}
-
-		// 发送 HTTP 请求
-		resp, err := wd.client.Do(req)
-		if err != nil {
-			return err
-		}
-		defer func() { _ = resp.Body.Close() }()
-
-		// 处理响应
-		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-			// 成功处理
-			return nil
-		}
+	// 负载中的 webhook 是失败时的快照，按 ID 重新读取，使用最新的地址、密钥与订阅条件
+	webhook, err := wd.repo.GetWebhookByID(payload.Webhook.ID)
+	if err != nil {
+		return err
+	}
+	if webhook == nil || !webhook.IsActive || !shouldDispatch(webhook, &event) {
+		return fmt.Errorf("webhook %d is deleted, inactive or no longer subscribed: %w", payload.Webhook.ID, errDeadLetterObsolete)
+	}
 
-		// 非成功状态码，视为失败
-		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	// 重新发送请求，投递记录关联到该死信任务
+	attempt := 0
+	err = wd.retryWithBackoff(3, 500*time.Millisecond, func() error {
+		attempt++
+		return wd.deliver(webhook, &event, &webhookModel.WebhookDelivery{
+			Attempt:      attempt,
+			DeadLetterID: deadLetter.ID,
+		})
 	})
+	wd.updateStatus(webhook, err)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,282 @@
+package event
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	echoModel "github.com/lin-snow/ech0/internal/model/echo"
+	queueModel "github.com/lin-snow/ech0/internal/model/queue"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
+	webhookModel "github.com/lin-snow/ech0/internal/model/webhook"
+	webhookRepository "github.com/lin-snow/ech0/internal/repository/webhook"
+	"github.com/lin-snow/ech0/internal/transaction"
+	webhookUtil "github.com/lin-snow/ech0/pkg/webhook"
+)
+
+// newTestDispatcher 基于临时 SQLite 数据库的 Webhook 分发器
+func newTestDispatcher(t *testing.T) (*gorm.DB, *WebhookDispatcher) {
+	t.Helper()
+	db, queueRepo := newQueueRepository(t)
+	dbProvider := func() *gorm.DB { return db }
+	return db, NewWebhookDispatcher(
+		func() IEventBus { return nil },
+		webhookRepository.NewWebhookRepository(dbProvider),
+		queueRepo,
+		transaction.NewTransactionManager(dbProvider),
+	)
+}
+
+func TestBuildRequestSignsBody(t *testing.T) {
+	_, wd := newTestDispatcher(t)
+	wh := &webhookModel.Webhook{URL: "https://hooks.example/ech0", Secret: "s3cret"}
+	user := userModel.User{ID: 1, Username: "alice", Password: "secret-hash"}
+	e := NewEvent(EventTypeUserCreated, EventPayload{EventPayloadUser: user})
+
+	req, err := wd.buildRequest(wh, e)
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	for header, want := range map[string]string{
+		"Content-Type":                  "application/json",
+		webhookUtil.EventHeader:         string(EventTypeUserCreated),
+		webhookUtil.EventIDHeader:       e.ID,
+		webhookUtil.LegacyEventIDHeader: e.ID,
+	} {
+		if got := req.Header.Get(header); got != want {
+			t.Errorf("%s = %q, want %q", header, got, want)
+		}
+	}
+
+	// 接收方使用公开的校验工具可以通过校验
+	body, err := webhookUtil.VerifyRequest(req, wh.Secret, time.Minute)
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if strings.Contains(string(body), "secret-hash") {
+		t.Errorf("body leaks the password hash: %s", body)
+	}
+	if _, err := webhookUtil.VerifyRequest(req, "other", time.Minute); err == nil {
+		t.Error("expected verification with another secret to fail")
+	}
+
+	// 重试时重新读取的请求体与签名一致
+	again, err := req.GetBody()
+	if err != nil {
+		t.Fatalf("get body: %v", err)
+	}
+	retry, _ := http.NewRequest(http.MethodPost, wh.URL, again)
+	retry.Header = req.Header
+	if _, err := webhookUtil.VerifyRequest(retry, wh.Secret, time.Minute); err != nil {
+		t.Errorf("verify retried body: %v", err)
+	}
+}
+
+func TestBuildRequestWithoutSecret(t *testing.T) {
+	_, wd := newTestDispatcher(t)
+	req, err := wd.buildRequest(&webhookModel.Webhook{URL: "https://hooks.example/ech0"}, NewEvent(EventTypeEchoCreated, EventPayload{}))
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	if req.Header.Get(webhookUtil.SignatureHeader) != "" || req.Header.Get(webhookUtil.TimestampHeader) != "" {
+		t.Errorf("unsigned webhook carries signature headers: %v", req.Header)
+	}
+}
+
+func TestShouldDispatch(t *testing.T) {
+	publicEcho := echoModel.Echo{
+		Visibility: echoModel.VisibilityPublic,
+		Tags:       []echoModel.Tag{{Name: "go"}},
+	}
+	privateEcho := echoModel.Echo{Visibility: echoModel.VisibilityPrivate}
+	goOnly := &webhookModel.Webhook{Events: []string{"echo.*"}, OnlyPublic: true, TagFilter: "go"}
+
+	tests := map[string]struct {
+		webhook *webhookModel.Webhook
+		event   *Event
+		want    bool
+	}{
+		"all events": {
+			&webhookModel.Webhook{},
+			NewEvent(EventTypeSystemBackup, EventPayload{}),
+			true,
+		},
+		"not subscribed": {
+			goOnly,
+			NewEvent(EventTypeSystemBackup, EventPayload{}),
+			false,
+		},
+		"matching echo": {
+			goOnly,
+			NewEvent(EventTypeEchoCreated, EventPayload{EventPayloadEcho: publicEcho}),
+			true,
+		},
+		"private echo": {
+			goOnly,
+			NewEvent(EventTypeEchoCreated, EventPayload{EventPayloadEcho: privateEcho}),
+			false,
+		},
+		// 负载过滤只作用于携带 Echo 的事件
+		"subscribed event without echo": {
+			&webhookModel.Webhook{Events: []string{"user.*"}, OnlyPublic: true},
+			NewEvent(EventTypeUserCreated, EventPayload{}),
+			true,
+		},
+		"echo of unexpected type": {
+			goOnly,
+			NewEvent(EventTypeEchoCreated, EventPayload{EventPayloadEcho: map[string]any{"visibility": "public"}}),
+			false,
+		},
+	}
+
+	for name, tt := range tests {
+		if got := shouldDispatch(tt.webhook, tt.event); got != tt.want {
+			t.Errorf("%s: shouldDispatch = %v, want %v", name, got, tt.want)
+		}
+	}
+}
+
+// hookServer 模拟 Webhook 接收方，按 status 返回响应
+type hookServer struct {
+	*httptest.Server
+	status atomic.Int32
+}
+
+func newHookServer(t *testing.T, body string) *hookServer {
+	t.Helper()
+	hs := &hookServer{}
+	hs.status.Store(http.StatusOK)
+	hs.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(int(hs.status.Load()))
+		_, _ = io.WriteString(w, body)
+	}))
+	t.Cleanup(hs.Close)
+	return hs
+}
+
+func TestDeliverRecordsAttempt(t *testing.T) {
+	db, wd := newTestDispatcher(t)
+	hs := newHookServer(t, strings.Repeat("x", webhookModel.DeliveryResponseBodyLimit+100))
+	wh := &webhookModel.Webhook{Name: "hook", URL: hs.URL, Secret: "s3cret", IsActive: true}
+	if err := db.Create(wh).Error; err != nil {
+		t.Fatalf("create webhook: %v", err)
+	}
+	e := NewEvent(EventTypeEchoCreated, EventPayload{})
+
+	hs.status.Store(http.StatusInternalServerError)
+	failed := &webhookModel.WebhookDelivery{Attempt: 2}
+	if err := wd.deliver(wh, e, failed); err == nil {
+		t.Fatal("expected non-2xx response to fail")
+	}
+
+	var saved webhookModel.WebhookDelivery
+	if err := db.First(&saved, failed.ID).Error; err != nil {
+		t.Fatalf("load delivery: %v", err)
+	}
+	if saved.Success || saved.StatusCode != http.StatusInternalServerError || saved.Attempt != 2 || saved.Error == "" {
+		t.Errorf("delivery = %+v", saved)
+	}
+	if saved.WebhookID != wh.ID || saved.EventID != e.ID || saved.EventType != string(e.Type) {
+		t.Errorf("delivery identifies %d %s %s", saved.WebhookID, saved.EventID, saved.EventType)
+	}
+	// 响应体被截断，签名不写入投递记录
+	if len(saved.ResponseBody) != webhookModel.DeliveryResponseBodyLimit {
+		t.Errorf("response body = %d bytes", len(saved.ResponseBody))
+	}
+	if !strings.Contains(saved.RequestHeaders, "******") || strings.Contains(saved.RequestHeaders, webhookUtil.SignaturePrefix) {
+		t.Errorf("request headers = %s", saved.RequestHeaders)
+	}
+	if !strings.Contains(saved.RequestBody, e.ID) {
+		t.Errorf("request body = %s", saved.RequestBody)
+	}
+
+	// 重新投递沿用原请求体中的事件
+	hs.status.Store(http.StatusNoContent)
+	redelivery, err := wd.Redeliver(wh, &saved)
+	if err != nil {
+		t.Fatalf("redeliver: %v", err)
+	}
+	if !redelivery.Success || redelivery.RedeliveryOf != saved.ID || redelivery.EventID != e.ID {
+		t.Errorf("redelivery = %+v", redelivery)
+	}
+
+	var updated webhookModel.Webhook
+	if err := db.First(&updated, wh.ID).Error; err != nil {
+		t.Fatalf("load webhook: %v", err)
+	}
+	if updated.LastStatus != webhookModel.StatusSuccess || updated.LastTrigger.IsZero() {
+		t.Errorf("webhook status = %q at %v", updated.LastStatus, updated.LastTrigger)
+	}
+}
+
+func TestDeleteDeliveriesBefore(t *testing.T) {
+	db, wd := newTestDispatcher(t)
+	now := time.Now()
+	for _, createdAt := range []time.Time{now.Add(-webhookModel.DeliveryRetention - time.Hour), now} {
+		if err := db.Create(&webhookModel.WebhookDelivery{WebhookID: 1, CreatedAt: createdAt}).Error; err != nil {
+			t.Fatalf("create delivery: %v", err)
+		}
+	}
+
+	deleted, err := wd.repo.DeleteDeliveriesBefore(context.Background(), now.Add(-webhookModel.DeliveryRetention))
+	if err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	var left int64
+	db.Model(&webhookModel.WebhookDelivery{}).Count(&left)
+	if deleted != 1 || left != 1 {
+		t.Errorf("deleted %d, left %d", deleted, left)
+	}
+}
+
+func TestHandleWaitsForDeliveries(t *testing.T) {
+	db, wd := newTestDispatcher(t)
+	ok := newHookServer(t, "ok")
+	broken := newHookServer(t, "down")
+	broken.status.Store(http.StatusBadGateway)
+
+	hooks := map[string]*webhookModel.Webhook{
+		"ok":     {Name: "ok", URL: ok.URL, IsActive: true},
+		"broken": {Name: "broken", URL: broken.URL, IsActive: true},
+	}
+	for _, wh := range hooks {
+		if err := db.Create(wh).Error; err != nil {
+			t.Fatalf("create webhook: %v", err)
+		}
+	}
+
+	// 单个 webhook 失败写入死信队列，不作为事件处理失败
+	if err := wd.Handle(context.Background(), NewEvent(EventTypeEchoCreated, EventPayload{})); err != nil {
+		t.Fatalf("handle: %v", err)
+	}
+
+	// 返回时所有投递都已完成，事件总线此时推进游标不会丢失事件
+	count := func(query string, args ...any) int64 {
+		t.Helper()
+		var n int64
+		if err := db.Model(&webhookModel.WebhookDelivery{}).Where(query, args...).Count(&n).Error; err != nil {
+			t.Fatalf("count deliveries: %v", err)
+		}
+		return n
+	}
+	if n := count("webhook_id = ? AND success = ?", hooks["ok"].ID, true); n != 1 {
+		t.Errorf("successful deliveries = %d, want 1", n)
+	}
+	if n := count("webhook_id = ? AND success = ? AND dead_letter_id <> 0", hooks["broken"].ID, false); n != 3 {
+		t.Errorf("failed deliveries linked to a dead letter = %d, want 3", n)
+	}
+	deadLetters, err := wd.queueRepo.ListDeadLetters(10)
+	if err != nil {
+		t.Fatalf("list dead letters: %v", err)
+	}
+	if len(deadLetters) != 1 || deadLetters[0].Type != queueModel.DeadLetterTypeWebhook {
+		t.Errorf("dead letters = %+v", deadLetters)
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"sync"
 	"time"
 
+	queueModel "github.com/lin-snow/ech0/internal/model/queue"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
 	logUtil "github.com/lin-snow/ech0/internal/util/log"
 	"github.com/oklog/ulid/v2"
 	"go.uber.org/zap"
@@ -17,9 +19,10 @@ const (
 	EventTypeUserUpdated EventType = "user.updated" // 更新用户
 	EventTypeUserDeleted EventType = "user.deleted" // 删除用户
 
-	EventTypeEchoCreated EventType = "echo.created" // 创建Echo
-	EventTypeEchoUpdated EventType = "echo.updated" // 更新Echo
-	EventTypeEchoDeleted EventType = "echo.deleted" // 删除Echo
+	EventTypeEchoCreated  EventType = "echo.created"  // 创建Echo
+	EventTypeEchoUpdated  EventType = "echo.updated"  // 更新Echo
+	EventTypeEchoDeleted  EventType = "echo.deleted"  // 删除Echo（移入回收站）
+	EventTypeEchoRestored EventType = "echo.restored" // 从回收站恢复Echo
 
 	EventTypeResourceUploaded EventType = "resource.uploaded" // 资源上传
 
@@ -39,6 +42,7 @@ const (
 const (
 	EventPayloadUser       = "user"
 	EventPayloadEcho       = "echo"
+	EventPayloadPrevious   = "previous" // 更新前的数据
 	EventPayloadData       = "data"
 	EventPayloadSchedule   = "schedule"
 	EventPayloadInfo       = "info"
@@ -203,3 +207,44 @@ func (eb *EventBus) SubscribeAll(handler EventHandler, exclude ...EventType) err
 	})
 	return nil
 }
+
+// withoutCredentials 返回负载中去除了凭据的事件副本，用于写入 outbox 或发送给外部
+func (e *Event) withoutCredentials() *Event {
+	return &Event{
+		ID:        e.ID,
+		Type:      e.Type,
+		Payload:   redactCredentials(e.Payload),
+		Timestamp: e.Timestamp,
+		Meta:      e.Meta,
+	}
+}
+
+// redactCredentials 返回去除了凭据的负载副本：用户的密码哈希被清空，
+// 死信任务的负载（可能包含 webhook 密钥与用户信息）被移除，由 DeadLetterResolver 按 ID 重新读取
+func redactCredentials(payload EventPayload) EventPayload {
+	redacted := make(EventPayload, len(payload))
+	for key, value := range payload {
+		switch v := value.(type) {
+		case userModel.User:
+			v.Password = ""
+			value = v
+		case *userModel.User:
+			if v != nil {
+				user := *v
+				user.Password = ""
+				value = user
+			}
+		case queueModel.DeadLetter:
+			v.Payload = nil
+			value = v
+		case *queueModel.DeadLetter:
+			if v != nil {
+				deadLetter := *v
+				deadLetter.Payload = nil
+				value = deadLetter
+			}
+		}
+		redacted[key] = value
+	}
+	return redacted
+}
@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/lin-snow/ech0/internal/async"
 	"github.com/lin-snow/ech0/internal/fediverse"
 	echoModel "github.com/lin-snow/ech0/internal/model/echo"
 	queueModel "github.com/lin-snow/ech0/internal/model/queue"
@@ -18,13 +17,13 @@ import (
 )
 
 type PushEchoReplayPayload struct {
-	Echo echoModel.Echo `json:"echo"`
-	User userModel.User `json:"user"`
+	Echo     echoModel.Echo `json:"echo"`
+	Previous echoModel.Echo `json:"previous"` // 更新前的 Echo，仅更新事件使用
+	User     userModel.User `json:"user"`
 }
 
 // FediverseAgent 处理联邦相关的事件
 type FediverseAgent struct {
-	pool      *async.WorkerPool                        // 任务池
 	core      *fediverse.FediverseCore                 // 联邦宇宙相关组件
 	queueRepo queueRepository.QueueRepositoryInterface // 死信任务仓储
 	txManager transaction.TransactionManager           // 事务管理器
@@ -36,21 +35,34 @@ func NewFediverseAgent(
 	txManager transaction.TransactionManager,
 ) *FediverseAgent {
 	return &FediverseAgent{
-		pool:      async.NewWorkerPool(3, 3), // 假设最大并发数为 3，任务队列大小为 3
 		core:      core,
 		queueRepo: queueRepo,
 		txManager: txManager,
 	}
 }
 
+// Handle 由事件总线调用，同步完成投递或写入死信队列后返回，死信任务保存失败时返回错误交由事件总线重试
 func (fa *FediverseAgent) Handle(ctx context.Context, e *Event) error {
 	// 处理事件，与联邦宇宙交互
 	switch e.Type {
 	case EventTypeEchoCreated:
 		if err := fa.HandleCreateEchoEvent(ctx, e); err != nil {
-			logUtil.GetLogger().
-				Error("Failed to handle create echo event", zap.String("error", err.Error()))
-			return nil
+			return fmt.Errorf("failed to handle create echo event: %w", err)
+		}
+
+	case EventTypeEchoUpdated:
+		if err := fa.HandleUpdateEchoEvent(ctx, e); err != nil {
+			return fmt.Errorf("failed to handle update echo event: %w", err)
+		}
+
+	case EventTypeEchoDeleted:
+		if err := fa.HandleDeleteEchoEvent(ctx, e); err != nil {
+			return fmt.Errorf("failed to handle delete echo event: %w", err)
+		}
+
+	case EventTypeEchoRestored:
+		if err := fa.HandleRestoreEchoEvent(ctx, e); err != nil {
+			return fmt.Errorf("failed to handle restore echo event: %w", err)
 		}
 
 	default:
@@ -60,69 +72,127 @@ func (fa *FediverseAgent) Handle(ctx context.Context, e *Event) error {
 	return nil
 }
 
-func (fa *FediverseAgent) Wait() {
-	fa.pool.Wait()
-}
-
 func (fa *FediverseAgent) HandleCreateEchoEvent(ctx context.Context, e *Event) error {
 	// 将 Echo 推送到联邦宇宙
+	echo, user, ok := extractEchoAndUser(e)
+	if !ok {
+		return nil
+	}
+
+	replay := PushEchoReplayPayload{Echo: echo, User: user}
+	return fa.deliver(queueModel.DeadLetterTypePushEchoFediverse, replay, func() error {
+		return fa.core.PushEchoToFediverse(user.ID, echo)
+	})
+}
+
+func (fa *FediverseAgent) HandleUpdateEchoEvent(ctx context.Context, e *Event) error {
+	// 将 Echo 的修改推送到联邦宇宙
+	echo, user, ok := extractEchoAndUser(e)
+	if !ok {
+		return nil
+	}
+
+	// 旧版本写入的事件没有更新前的 Echo，零值按可推送处理，与之前的行为一致
+	previous, _ := e.Payload[EventPayloadPrevious].(echoModel.Echo)
+
+	replay := PushEchoReplayPayload{Echo: echo, Previous: previous, User: user}
+	return fa.deliver(queueModel.DeadLetterTypeUpdateEchoFediverse, replay, func() error {
+		return fa.core.PushEchoUpdateToFediverse(previous, echo)
+	})
+}
+
+func (fa *FediverseAgent) HandleDeleteEchoEvent(ctx context.Context, e *Event) error {
+	// 将 Echo 的删除推送到联邦宇宙
+	echo, user, ok := extractEchoAndUser(e)
+	if !ok {
+		return nil
+	}
+
+	// 记录 Tombstone，之后请求该对象时返回 410
+	if err := fa.core.RecordTombstone(echo.ID); err != nil {
+		return fmt.Errorf("failed to record tombstone: %w", err)
+	}
+
+	replay := PushEchoReplayPayload{Echo: echo, User: user}
+	return fa.deliver(queueModel.DeadLetterTypeDeleteEchoFediverse, replay, func() error {
+		return fa.core.PushEchoDeleteToFediverse(echo)
+	})
+}
+
+func (fa *FediverseAgent) HandleRestoreEchoEvent(ctx context.Context, e *Event) error {
+	// 移入回收站时已推送 Delete，恢复后重新推送 Create
+	echo, user, ok := extractEchoAndUser(e)
+	if !ok {
+		return nil
+	}
+
+	// 删除 Tombstone，之后请求该对象时重新返回 Note
+	if err := fa.core.RemoveTombstone(echo.ID); err != nil {
+		return fmt.Errorf("failed to remove tombstone: %w", err)
+	}
+
+	// 无论由谁恢复，都以 Echo 作者的身份推送
+	replay := PushEchoReplayPayload{Echo: echo, User: user}
+	return fa.deliver(queueModel.DeadLetterTypePushEchoFediverse, replay, func() error {
+		return fa.core.PushEchoToFediverse(echo.UserID, echo)
+	})
+}
+
+// extractEchoAndUser 从事件负载中取出 Echo 和 User
+func extractEchoAndUser(e *Event) (echoModel.Echo, userModel.User, bool) {
 	payload := e.Payload
 	echoData, ok := payload[EventPayloadEcho]
 	if !ok {
-		return nil
+		return echoModel.Echo{}, userModel.User{}, false
 	}
 	echo, ok := echoData.(echoModel.Echo)
 	if !ok {
-		return nil
+		return echoModel.Echo{}, userModel.User{}, false
 	}
 	userData, ok := payload[EventPayloadUser]
 	if !ok {
-		return nil
+		return echoModel.Echo{}, userModel.User{}, false
 	}
 	user, ok := userData.(userModel.User)
 	if !ok {
+		return echoModel.Echo{}, userModel.User{}, false
+	}
+	return echo, user, true
+}
+
+// deliver 同步投递，重试后仍失败时记录到死信队列，死信任务保存失败时返回错误
+func (fa *FediverseAgent) deliver(
+	deadLetterType string,
+	replay PushEchoReplayPayload,
+	deliver func() error,
+) error {
+	// 重试机制，最多尝试3次，初始延迟1秒
+	err := fa.retryWithBackoff(3, time.Second, deliver)
+	if err == nil {
 		return nil
 	}
+	logUtil.GetLogger().Error("Failed to deliver to fediverse",
+		zap.String("type", deadLetterType),
+		zap.String("error", err.Error()))
 
-	fa.pool.Submit(func() error {
-		// 重试机制，最多重试3次，初始延迟1秒
-		return fa.retryWithBackoff(3, time.Second, func() error {
-			if err := fa.core.PushEchoToFediverse(user.ID, echo); err != nil {
-				logUtil.GetLogger().Error(err.Error())
-
-				// 处理失败，记录到死信队列
-				e.Meta = map[string]any{
-					queueModel.DeadLetterMetaKey: true, // 标记为死信任务
-				}
-
-				payloadData := PushEchoReplayPayload{
-					Echo: echo,
-					User: user,
-				}
-				payload, _ := json.Marshal(payloadData)
-
-				// 保存到死信队列
-				var deadLetter queueModel.DeadLetter
-				deadLetter.SetType(queueModel.DeadLetterTypePushEchoFediverse)
-				deadLetter.Payload = payload
-				deadLetter.ErrorMsg = err.Error()
-				deadLetter.RetryCount = 0
-				deadLetter.NextRetry = time.Now().Add(12 * time.Hour) // 初始重试时间为 12 小时后
-				deadLetter.CreatedAt = time.Now()
-				deadLetter.UpdatedAt = time.Now()
-				deadLetter.Status = queueModel.DeadLetterStatusPending // 初始状态为待处理
-
-				if err := fa.txManager.Run(func(ctx context.Context) error {
-					return fa.queueRepo.SaveDeadLetter(ctx, &deadLetter)
-				}); err != nil {
-					logUtil.GetLogger().
-						Error("Failed to save dead letter", zap.String("error", err.Error()))
-				}
-			}
-			return nil
-		})
-	})
+	payload, _ := json.Marshal(replay)
+
+	// 保存到死信队列
+	var deadLetter queueModel.DeadLetter
+	deadLetter.SetType(deadLetterType)
+	deadLetter.Payload = payload
+	deadLetter.ErrorMsg = err.Error()
+	deadLetter.RetryCount = 0
+	deadLetter.NextRetry = DeadLetterBackoffPolicy().NextRetry(0, time.Now()) // 按退避策略计算首次重试时间
+	deadLetter.CreatedAt = time.Now()
+	deadLetter.UpdatedAt = time.Now()
+	deadLetter.Status = queueModel.DeadLetterStatusPending // 初始状态为待处理
 
+	if err := fa.txManager.Run(func(ctx context.Context) error {
+		return fa.queueRepo.SaveDeadLetter(ctx, &deadLetter)
+	}); err != nil {
+		return fmt.Errorf("failed to save dead letter: %w", err)
+	}
 	return nil
 }
 
@@ -137,6 +207,9 @@ func (fa *FediverseAgent) retryWithBackoff(
 		if err == nil {
 			return nil
 		}
+		if i == retries-1 {
+			break // 最后一次失败后不再等待
+		}
 		time.Sleep(delay)
 		delay *= 2
 	}
@@ -146,22 +219,53 @@ func (fa *FediverseAgent) retryWithBackoff(
 func (fa *FediverseAgent) HandlePushEchoDeadLetter(
 	ctx context.Context,
 	deadLetter *queueModel.DeadLetter,
+) error {
+	return fa.replayDeadLetter(deadLetter, false, func(payload PushEchoReplayPayload) error {
+		return fa.core.PushEchoToFediverse(payload.Echo.UserID, payload.Echo)
+	})
+}
+
+func (fa *FediverseAgent) HandleUpdateEchoDeadLetter(
+	ctx context.Context,
+	deadLetter *queueModel.DeadLetter,
+) error {
+	return fa.replayDeadLetter(deadLetter, false, func(payload PushEchoReplayPayload) error {
+		return fa.core.PushEchoUpdateToFediverse(payload.Previous, payload.Echo)
+	})
+}
+
+func (fa *FediverseAgent) HandleDeleteEchoDeadLetter(
+	ctx context.Context,
+	deadLetter *queueModel.DeadLetter,
+) error {
+	return fa.replayDeadLetter(deadLetter, true, func(payload PushEchoReplayPayload) error {
+		return fa.core.PushEchoDeleteToFediverse(payload.Echo)
+	})
+}
+
+// replayDeadLetter 解析死信负载并重试投递，deleted 为任务要求 Echo 所处的删除状态，
+// 两者不符时（推送的 Echo 已被删除，或删除的 Echo 已被恢复）任务已过时，不再投递
+func (fa *FediverseAgent) replayDeadLetter(
+	deadLetter *queueModel.DeadLetter,
+	deleted bool,
+	deliver func(payload PushEchoReplayPayload) error,
 ) error {
 	// 解析负载
 	var payload PushEchoReplayPayload
 	if err := json.Unmarshal(deadLetter.Payload, &payload); err != nil {
 		return fmt.Errorf("failed to unmarshal dead letter payload: %w", err)
 	}
-	echo := payload.Echo
-	user := payload.User
 
-	// 重试
-	err := fa.retryWithBackoff(3, 1*time.Minute, func() error {
-		return fa.core.PushEchoToFediverse(user.ID, echo)
-	})
+	isDeleted, err := fa.core.IsEchoDeleted(payload.Echo.ID)
 	if err != nil {
 		return err
 	}
+	if isDeleted != deleted {
+		return fmt.Errorf("echo %d changed after the delivery failed: %w", payload.Echo.ID, errDeadLetterObsolete)
+	}
 
-	return nil
+	// 重试
+	return fa.retryWithBackoff(3, 1*time.Minute, func() error {
+		return deliver(payload)
+	})
 }
@@ -2,6 +2,12 @@ package event
 
 import (
 	"sync/atomic"
+
+	"github.com/lin-snow/ech0/internal/config"
+	"github.com/lin-snow/ech0/internal/database"
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	queueRepository "github.com/lin-snow/ech0/internal/repository/queue"
+	errUtil "github.com/lin-snow/ech0/internal/util/err"
 )
 
 // 使用 atomic.Value 存储全局 EventBus，线程安全
@@ -21,8 +27,23 @@ func SetEventBus(bus IEventBus) {
 	globalEventBus.Store(bus)
 }
 
-// InitEventBus 初始化并注册全局 EventBus
+// InitEventBus 根据配置初始化并注册全局 EventBus，持久化事件总线需要先初始化数据库
 func InitEventBus() {
+	if config.Config.EventBus.Type == EventBusTypePersistent {
+		bus, err := NewPersistentEventBus(
+			queueRepository.NewQueueRepository(database.GetDB),
+			PersistentEventBusOptionsFromConfig(),
+		)
+		if err != nil {
+			errUtil.HandlePanicError(&commonModel.ServerError{
+				Msg: commonModel.INIT_EVENT_BUS_PANIC,
+				Err: err,
+			})
+		}
+		SetEventBus(bus)
+		return
+	}
+
 	bus := NewEventBus()
 	SetEventBus(bus)
 }
@@ -0,0 +1,567 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/lin-snow/ech0/internal/config"
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	echoModel "github.com/lin-snow/ech0/internal/model/echo"
+	queueModel "github.com/lin-snow/ech0/internal/model/queue"
+	settingModel "github.com/lin-snow/ech0/internal/model/setting"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
+	queueRepository "github.com/lin-snow/ech0/internal/repository/queue"
+	logUtil "github.com/lin-snow/ech0/internal/util/log"
+	"go.uber.org/zap"
+)
+
+// 事件总线类型
+const (
+	EventBusTypeMemory     = "memory"     // 内存事件总线，事件不落盘
+	EventBusTypePersistent = "persistent" // 持久化事件总线，事件写入数据库后按序投递
+)
+
+const outboxPruneInterval = time.Hour // 清理已处理事件的间隔
+
+var errEventBusStopping = errors.New("event bus is stopping")
+
+// EventReplayPayload 事件总线死信任务的负载，重试时将事件重新交给原订阅者处理
+type EventReplayPayload struct {
+	Subscriber string `json:"subscriber"` // 订阅者名称
+	Data       string `json:"data"`       // 写入 outbox 的事件内容
+}
+
+// PersistentEventBusOptions 持久化事件总线配置
+type PersistentEventBusOptions struct {
+	PollInterval time.Duration            // 轮询新事件的间隔
+	BatchSize    int                      // 每次读取的事件数量
+	Retry        queueModel.BackoffPolicy // 事件处理失败后的重试策略
+	Retention    time.Duration            // 已处理事件的保留时长
+	GapGrace     time.Duration            // 序号出现空缺时等待未提交事务的时长
+}
+
+// DefaultPersistentEventBusOptions 默认的持久化事件总线配置
+var DefaultPersistentEventBusOptions = PersistentEventBusOptions{
+	PollInterval: 2 * time.Second,
+	BatchSize:    50,
+	Retry: queueModel.BackoffPolicy{
+		InitialBackoff: time.Second,
+		MaxBackoff:     time.Minute,
+		Multiplier:     2,
+		MaxRetries:     5,
+	},
+	Retention: 7 * 24 * time.Hour,
+	GapGrace:  30 * time.Second,
+}
+
+// PersistentEventBusOptionsFromConfig 读取配置中的持久化事件总线参数，未配置的字段使用默认值
+func PersistentEventBusOptionsFromConfig() PersistentEventBusOptions {
+	opts := DefaultPersistentEventBusOptions
+	cfg := config.Config.EventBus
+
+	if cfg.PollInterval > 0 {
+		opts.PollInterval = time.Duration(cfg.PollInterval) * time.Second
+	}
+	if cfg.BatchSize > 0 {
+		opts.BatchSize = cfg.BatchSize
+	}
+	if cfg.MaxRetries > 0 {
+		opts.Retry.MaxRetries = cfg.MaxRetries
+	}
+	if cfg.InitialBackoff > 0 {
+		opts.Retry.InitialBackoff = time.Duration(cfg.InitialBackoff) * time.Second
+	}
+	if cfg.MaxBackoff > 0 {
+		opts.Retry.MaxBackoff = time.Duration(cfg.MaxBackoff) * time.Second
+	}
+	if cfg.Retention > 0 {
+		opts.Retention = time.Duration(cfg.Retention) * time.Second
+	}
+	if cfg.GapGrace > 0 {
+		opts.GapGrace = time.Duration(cfg.GapGrace) * time.Second
+	}
+
+	return opts
+}
+
+// persistentSubscriber 持久化事件总线的订阅者，每个订阅者拥有独立的游标与投递协程
+type persistentSubscriber struct {
+	name    string        // 订阅者名称，用于持久化游标
+	handler EventHandler  // 事件处理函数
+	filter  EventFilter   // 事件过滤器
+	wake    chan struct{} // 有新事件写入时唤醒投递协程
+	cursor  atomic.Uint64 // 已处理的最后一个事件序号
+}
+
+// PersistentEventBus 基于数据库的持久化事件总线
+//
+// 事件先写入 outbox 表，再由每个订阅者独立的协程按写入顺序逐个投递，
+// 处理成功后推进该订阅者的游标。进程崩溃或重启后从游标处继续投递，保证至少一次投递。
+// postgres、mysql 等数据库的自增序号在插入时分配、在提交时可见，后分配序号的事务可能先提交，
+// 因此遇到序号空缺时会等待 GapGrace 后再越过空缺，避免游标越过稍后提交的事件。
+type PersistentEventBus struct {
+	repo    queueRepository.QueueRepositoryInterface
+	opts    PersistentEventBusOptions
+	startID uint // 总线启动时的最新事件序号，新订阅者从这里开始投递
+
+	mu    sync.RWMutex
+	subs  []*persistentSubscriber
+	names map[string]int
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewPersistentEventBus 创建一个新的持久化事件总线
+func NewPersistentEventBus(
+	repo queueRepository.QueueRepositoryInterface,
+	opts PersistentEventBusOptions,
+) (*PersistentEventBus, error) {
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = DefaultPersistentEventBusOptions.PollInterval
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultPersistentEventBusOptions.BatchSize
+	}
+
+	startID, err := repo.GetLatestOutboxEventID()
+	if err != nil {
+		return nil, err
+	}
+
+	eb := &PersistentEventBus{
+		repo:    repo,
+		opts:    opts,
+		startID: startID,
+		names:   make(map[string]int),
+		stop:    make(chan struct{}),
+	}
+
+	eb.wg.Add(1)
+	go eb.prune()
+
+	return eb, nil
+}
+
+// Publish 发布事件，事件写入数据库后即返回，写入失败时返回错误
+func (eb *PersistentEventBus) Publish(ctx context.Context, event *Event) error {
+	// 凭据不写入 outbox，处理器需要时应按 ID 重新读取
+	data, err := json.Marshal(event.withoutCredentials())
+	if err != nil {
+		return fmt.Errorf("marshal event %s: %w", event.Type, err)
+	}
+
+	if err := eb.repo.SaveOutboxEvent(ctx, &queueModel.OutboxEvent{
+		EventID: event.ID,
+		Type:    string(event.Type),
+		Data:    string(data),
+	}); err != nil {
+		return fmt.Errorf("save event %s: %w", event.Type, err)
+	}
+
+	// 唤醒订阅者，未唤醒的订阅者会在下一次轮询时处理
+	eb.mu.RLock()
+	for _, sub := range eb.subs {
+		select {
+		case sub.wake <- struct{}{}:
+		default:
+		}
+	}
+	eb.mu.RUnlock()
+
+	return nil
+}
+
+// Subscribe 订阅指定事件
+func (eb *PersistentEventBus) Subscribe(handler EventHandler, eventType EventType) error {
+	return eb.subscribe(handler, func(et EventType) bool {
+		return et == eventType
+	})
+}
+
+// Subscribes 订阅多个指定事件
+func (eb *PersistentEventBus) Subscribes(handler EventHandler, eventTypes ...EventType) error {
+	set := make(map[EventType]struct{}, len(eventTypes))
+	for _, et := range eventTypes {
+		set[et] = struct{}{}
+	}
+
+	return eb.subscribe(handler, func(et EventType) bool {
+		_, ok := set[et]
+		return ok
+	})
+}
+
+// SubscribeAll 订阅所有事件
+func (eb *PersistentEventBus) SubscribeAll(handler EventHandler, exclude ...EventType) error {
+	excludeSet := make(map[EventType]struct{}, len(exclude))
+	for _, et := range exclude {
+		excludeSet[et] = struct{}{}
+	}
+
+	return eb.subscribe(handler, func(et EventType) bool {
+		_, skip := excludeSet[et]
+		return !skip
+	})
+}
+
+// Close 停止投递，处理完已写入的事件后返回，未能处理的事件会在下次启动时继续投递
+func (eb *PersistentEventBus) Close() {
+	eb.stopOnce.Do(func() {
+		close(eb.stop)
+	})
+	eb.wg.Wait()
+}
+
+// subscribe 注册订阅者并启动其投递协程
+func (eb *PersistentEventBus) subscribe(handler EventHandler, filter EventFilter) error {
+	if eb.stopping() {
+		return errEventBusStopping
+	}
+
+	// 同一处理函数多次订阅时按注册顺序追加序号，保证重启后名称一致
+	name := handlerName(handler)
+	eb.mu.Lock()
+	eb.names[name]++
+	if n := eb.names[name]; n > 1 {
+		name = fmt.Sprintf("%s#%d", name, n)
+	}
+	eb.mu.Unlock()
+
+	cursor, err := eb.repo.GetEventCursor(name)
+	if err != nil {
+		return err
+	}
+	if cursor == nil {
+		// 新订阅者不回放总线启动前的历史事件
+		cursor = &queueModel.EventCursor{Subscriber: name, LastEventID: eb.startID}
+		if err := eb.repo.SaveEventCursor(context.Background(), cursor); err != nil {
+			return err
+		}
+	}
+
+	sub := &persistentSubscriber{
+		name:    name,
+		handler: handler,
+		filter:  filter,
+		wake:    make(chan struct{}, 1),
+	}
+	sub.cursor.Store(uint64(cursor.LastEventID))
+
+	eb.mu.Lock()
+	eb.subs = append(eb.subs, sub)
+	eb.mu.Unlock()
+
+	eb.wg.Add(1)
+	go eb.run(sub)
+
+	return nil
+}
+
+// run 订阅者的投递循环
+func (eb *PersistentEventBus) run(sub *persistentSubscriber) {
+	defer eb.wg.Done()
+
+	ticker := time.NewTicker(eb.opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := eb.deliverPending(sub, false); err != nil &&
+			!errors.Is(err, errEventBusStopping) {
+			logUtil.GetLogger().Error("Persistent event bus delivery error",
+				zap.String("subscriber", sub.name),
+				zap.String("err", err.Error()))
+		}
+
+		select {
+		case <-eb.stop:
+			// 停止前不再重试，尽量投递完已写入的事件
+			if err := eb.deliverPending(sub, true); err != nil {
+				logUtil.GetLogger().Warn("Persistent event bus stopped with pending events",
+					zap.String("subscriber", sub.name),
+					zap.String("err", err.Error()))
+			}
+			return
+		case <-sub.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// deliverPending 按顺序投递游标之后的所有事件，直到没有新事件或遇到尚在宽限期内的序号空缺为止
+func (eb *PersistentEventBus) deliverPending(sub *persistentSubscriber, draining bool) error {
+	for {
+		last := uint(sub.cursor.Load())
+		outboxEvents, err := eb.repo.ListOutboxEventsAfter(last, eb.opts.BatchSize)
+		if err != nil {
+			return err
+		}
+		if len(outboxEvents) == 0 {
+			return nil
+		}
+
+		for i := range outboxEvents {
+			// 缺失的序号可能属于尚未提交的事务，等到下一轮再投递，超过宽限期后视为已回滚
+			if !eb.gapSettled(last, &outboxEvents[i]) {
+				return nil
+			}
+			if err := eb.deliver(sub, &outboxEvents[i], draining); err != nil {
+				return err
+			}
+			eb.advance(sub, outboxEvents[i].ID)
+			last = outboxEvents[i].ID
+		}
+	}
+}
+
+// gapSettled 判断 last 与下一个事件之间的序号空缺是否可以越过，没有空缺时返回 true
+func (eb *PersistentEventBus) gapSettled(last uint, next *queueModel.OutboxEvent) bool {
+	if next.ID == last+1 || eb.opts.GapGrace <= 0 {
+		return true
+	}
+	return time.Since(next.CreatedAt) >= eb.opts.GapGrace
+}
+
+// deliver 将单个事件投递给订阅者，失败时按退避策略重试，超过最大重试次数后写入死信队列
+func (eb *PersistentEventBus) deliver(
+	sub *persistentSubscriber,
+	outboxEvent *queueModel.OutboxEvent,
+	draining bool,
+) error {
+	if sub.filter != nil && !sub.filter(EventType(outboxEvent.Type)) {
+		return nil
+	}
+
+	event, err := decodeOutboxEvent(outboxEvent)
+	if err != nil {
+		// 无法解析的事件重试也不会成功，直接跳过
+		logUtil.GetLogger().Error("Persistent event bus skipped undecodable event",
+			zap.String("subscriber", sub.name),
+			zap.Uint("seq", outboxEvent.ID),
+			zap.String("err", err.Error()))
+		return nil
+	}
+
+	for retryCount := 0; ; retryCount++ {
+		err := invokeHandler(sub.handler, event)
+		if err == nil {
+			return nil
+		}
+		if draining {
+			return err
+		}
+		if eb.opts.Retry.Exhausted(retryCount) {
+			logUtil.GetLogger().Error("Persistent event bus gave up on event",
+				zap.String("subscriber", sub.name),
+				zap.String("event_id", event.ID),
+				zap.String("type", string(event.Type)),
+				zap.Int("retries", retryCount),
+				zap.String("err", err.Error()))
+			return eb.saveDeadLetter(sub, outboxEvent, err)
+		}
+
+		select {
+		case <-time.After(eb.opts.Retry.Delay(retryCount)):
+		case <-eb.stop:
+			return errEventBusStopping
+		}
+	}
+}
+
+// saveDeadLetter 将超过重试次数的事件写入死信队列，写入失败时返回错误，游标停留在该事件之前
+func (eb *PersistentEventBus) saveDeadLetter(
+	sub *persistentSubscriber,
+	outboxEvent *queueModel.OutboxEvent,
+	handleErr error,
+) error {
+	payload, err := json.Marshal(EventReplayPayload{Subscriber: sub.name, Data: outboxEvent.Data})
+	if err != nil {
+		return fmt.Errorf("marshal dead letter for event %s: %w", outboxEvent.EventID, err)
+	}
+
+	now := time.Now()
+	var deadLetter queueModel.DeadLetter
+	deadLetter.SetType(queueModel.DeadLetterTypeEvent)
+	deadLetter.Payload = payload
+	deadLetter.ErrorMsg = handleErr.Error()
+	deadLetter.NextRetry = DeadLetterBackoffPolicy().NextRetry(0, now)
+	deadLetter.CreatedAt = now
+	deadLetter.UpdatedAt = now
+	deadLetter.Status = queueModel.DeadLetterStatusPending
+
+	if err := eb.repo.SaveDeadLetter(context.Background(), &deadLetter); err != nil {
+		return fmt.Errorf("save dead letter for event %s: %w", outboxEvent.EventID, err)
+	}
+	return nil
+}
+
+// Redeliver 将死信队列中的事件重新交给原订阅者处理，订阅者已不存在时返回 errDeadLetterObsolete
+func (eb *PersistentEventBus) Redeliver(payload EventReplayPayload) error {
+	var handler EventHandler
+	eb.mu.RLock()
+	for _, sub := range eb.subs {
+		if sub.name == payload.Subscriber {
+			handler = sub.handler
+			break
+		}
+	}
+	eb.mu.RUnlock()
+	if handler == nil {
+		return fmt.Errorf("subscriber %s: %w", payload.Subscriber, errDeadLetterObsolete)
+	}
+
+	event, err := decodeOutboxEvent(&queueModel.OutboxEvent{Data: payload.Data})
+	if err != nil {
+		return fmt.Errorf("decode event: %w", err)
+	}
+	return invokeHandler(handler, event)
+}
+
+// advance 推进订阅者游标并持久化，持久化失败时事件可能在重启后被重复投递
+func (eb *PersistentEventBus) advance(sub *persistentSubscriber, id uint) {
+	sub.cursor.Store(uint64(id))
+	if err := eb.repo.SaveEventCursor(context.Background(), &queueModel.EventCursor{
+		Subscriber:  sub.name,
+		LastEventID: id,
+	}); err != nil {
+		logUtil.GetLogger().Error("Persistent event bus failed to save cursor",
+			zap.String("subscriber", sub.name),
+			zap.String("err", err.Error()))
+	}
+}
+
+// prune 定期清理所有订阅者都已处理且超过保留时长的事件
+func (eb *PersistentEventBus) prune() {
+	defer eb.wg.Done()
+
+	ticker := time.NewTicker(outboxPruneInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-eb.stop:
+			return
+		case <-ticker.C:
+		}
+
+		eb.mu.RLock()
+		if len(eb.subs) == 0 {
+			eb.mu.RUnlock()
+			continue
+		}
+		minID := eb.subs[0].cursor.Load()
+		for _, sub := range eb.subs[1:] {
+			minID = min(minID, sub.cursor.Load())
+		}
+		eb.mu.RUnlock()
+
+		if _, err := eb.repo.DeleteOutboxEventsBefore(
+			context.Background(),
+			uint(minID),
+			time.Now().Add(-eb.opts.Retention),
+		); err != nil {
+			logUtil.GetLogger().Error("Persistent event bus failed to prune events",
+				zap.String("err", err.Error()))
+		}
+	}
+}
+
+// stopping 判断事件总线是否正在停止
+func (eb *PersistentEventBus) stopping() bool {
+	select {
+	case <-eb.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// invokeHandler 调用事件处理函数，将 panic 转换为错误
+func invokeHandler(handler EventHandler, event *Event) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("event handler panic: %v", r)
+		}
+	}()
+
+	return handler(context.Background(), event)
+}
+
+// handlerName 根据处理函数生成稳定的订阅者名称，如 event.(*WebhookDispatcher).Handle
+func handlerName(handler EventHandler) string {
+	name := runtime.FuncForPC(reflect.ValueOf(handler).Pointer()).Name()
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	return strings.TrimSuffix(name, "-fm")
+}
+
+// storedEvent 持久化事件的解析结构，负载保留原始 JSON 以便按字段还原类型
+type storedEvent struct {
+	ID        string                     `json:"id"`
+	Type      EventType                  `json:"type"`
+	Payload   map[string]json.RawMessage `json:"data"`
+	Timestamp time.Time                  `json:"timestamp"`
+	Meta      map[string]any             `json:"meta"`
+}
+
+// payloadDecoders 需要还原为具体类型的负载字段，处理器会对这些字段做类型断言
+var payloadDecoders = map[string]func(raw json.RawMessage) (any, error){
+	EventPayloadEcho:       decodePayload[echoModel.Echo],
+	EventPayloadPrevious:   decodePayload[echoModel.Echo],
+	EventPayloadUser:       decodePayload[userModel.User],
+	EventPayloadDeadLetter: decodePayload[queueModel.DeadLetter],
+	EventPayloadSchedule:   decodePayload[settingModel.BackupSchedule],
+	EventPayloadSize:       decodePayload[int64],
+	EventPayloadType:       decodePayload[commonModel.UploadFileType],
+	EventPayloadFile:       decodePayload[string],
+	EventPayloadURL:        decodePayload[string],
+	EventPayloadInfo:       decodePayload[string],
+}
+
+// decodeOutboxEvent 将持久化事件还原为 Event
+func decodeOutboxEvent(outboxEvent *queueModel.OutboxEvent) (*Event, error) {
+	var stored storedEvent
+	if err := json.Unmarshal([]byte(outboxEvent.Data), &stored); err != nil {
+		return nil, err
+	}
+
+	payload := make(EventPayload, len(stored.Payload))
+	for key, raw := range stored.Payload {
+		decode, ok := payloadDecoders[key]
+		if !ok {
+			decode = decodePayload[any]
+		}
+		value, err := decode(raw)
+		if err != nil {
+			return nil, fmt.Errorf("decode payload %s: %w", key, err)
+		}
+		payload[key] = value
+	}
+
+	return &Event{
+		ID:        stored.ID,
+		Type:      stored.Type,
+		Payload:   payload,
+		Timestamp: stored.Timestamp,
+		Meta:      stored.Meta,
+	}, nil
+}
+
+// decodePayload 将负载字段解析为指定类型
+func decodePayload[T any](raw json.RawMessage) (any, error) {
+	var value T
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
@@ -0,0 +1,398 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/lin-snow/ech0/internal/database"
+	echoModel "github.com/lin-snow/ech0/internal/model/echo"
+	queueModel "github.com/lin-snow/ech0/internal/model/queue"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
+	inboxRepository "github.com/lin-snow/ech0/internal/repository/inbox"
+	queueRepository "github.com/lin-snow/ech0/internal/repository/queue"
+	logUtil "github.com/lin-snow/ech0/internal/util/log"
+	"go.uber.org/zap"
+)
+
+// newQueueRepository 基于临时 SQLite 数据库的队列存储
+func newQueueRepository(t *testing.T) (*gorm.DB, queueRepository.QueueRepositoryInterface) {
+	t.Helper()
+	logUtil.Logger = zap.NewNop()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "ech0.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.AutoMigrate(database.Models()...); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db, queueRepository.NewQueueRepository(func() *gorm.DB { return db })
+}
+
+// saveOutboxEvent 以指定序号与写入时间写入事件，用于构造序号空缺
+func saveOutboxEvent(t *testing.T, db *gorm.DB, id uint, eventType EventType, createdAt time.Time) {
+	t.Helper()
+	data, _ := json.Marshal(NewEvent(eventType, EventPayload{}))
+	if err := db.Create(&queueModel.OutboxEvent{
+		ID:        id,
+		EventID:   string(eventType) + "-" + time.Now().Format(time.RFC3339Nano),
+		Type:      string(eventType),
+		Data:      string(data),
+		CreatedAt: createdAt,
+	}).Error; err != nil {
+		t.Fatalf("save event %d: %v", id, err)
+	}
+}
+
+// recorder 记录收到的事件序列
+type recorder struct {
+	mu     sync.Mutex
+	events []*Event
+	seen   chan struct{}
+}
+
+func newRecorder() *recorder {
+	return &recorder{seen: make(chan struct{}, 100)}
+}
+
+func (r *recorder) handle(_ context.Context, event *Event) error {
+	r.mu.Lock()
+	r.events = append(r.events, event)
+	r.mu.Unlock()
+	r.seen <- struct{}{}
+	return nil
+}
+
+func (r *recorder) types() []EventType {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	types := make([]EventType, 0, len(r.events))
+	for _, event := range r.events {
+		types = append(types, event.Type)
+	}
+	return types
+}
+
+func (r *recorder) event(i int) *Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.events[i]
+}
+
+func (r *recorder) wait(t *testing.T, n int) {
+	t.Helper()
+	for range n {
+		select {
+		case <-r.seen:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for events, got %v", r.types())
+		}
+	}
+}
+
+// newTestSubscriber 创建不启动投递协程的订阅者，由测试直接调用 deliverPending
+func newTestSubscriber(name string, handler EventHandler, filter EventFilter) *persistentSubscriber {
+	return &persistentSubscriber{
+		name:    name,
+		handler: handler,
+		filter:  filter,
+		wake:    make(chan struct{}, 1),
+	}
+}
+
+func cursorOf(t *testing.T, repo queueRepository.QueueRepositoryInterface, name string) uint {
+	t.Helper()
+	cursor, err := repo.GetEventCursor(name)
+	if err != nil {
+		t.Fatalf("get cursor: %v", err)
+	}
+	if cursor == nil {
+		return 0
+	}
+	return cursor.LastEventID
+}
+
+func TestDeliverPendingWaitsForGap(t *testing.T) {
+	db, repo := newQueueRepository(t)
+	eb := &PersistentEventBus{
+		repo: repo,
+		opts: PersistentEventBusOptions{BatchSize: 2, GapGrace: time.Minute},
+		stop: make(chan struct{}),
+	}
+	rec := newRecorder()
+	sub := newTestSubscriber("test", rec.handle, nil)
+
+	saveOutboxEvent(t, db, 1, EventTypeEchoCreated, time.Now())
+	saveOutboxEvent(t, db, 2, EventTypeEchoUpdated, time.Now())
+	// 序号 3 可能属于尚未提交的事务
+	saveOutboxEvent(t, db, 4, EventTypeEchoDeleted, time.Now())
+
+	if err := eb.deliverPending(sub, false); err != nil {
+		t.Fatalf("deliver: %v", err)
+	}
+	if got := rec.types(); len(got) != 2 || got[1] != EventTypeEchoUpdated {
+		t.Fatalf("delivered %v, want events before the gap", got)
+	}
+	if c := cursorOf(t, repo, "test"); c != 2 {
+		t.Errorf("cursor = %d, want 2", c)
+	}
+
+	// 稍后提交的事件补上空缺后按序投递
+	saveOutboxEvent(t, db, 3, EventTypeEchoRestored, time.Now())
+	if err := eb.deliverPending(sub, false); err != nil {
+		t.Fatalf("deliver: %v", err)
+	}
+	want := []EventType{EventTypeEchoCreated, EventTypeEchoUpdated, EventTypeEchoRestored, EventTypeEchoDeleted}
+	if got := rec.types(); len(got) != len(want) || got[2] != want[2] || got[3] != want[3] {
+		t.Errorf("delivered %v, want %v", got, want)
+	}
+	if c := cursorOf(t, repo, "test"); c != 4 {
+		t.Errorf("cursor = %d, want 4", c)
+	}
+}
+
+func TestDeliverPendingSkipsSettledGap(t *testing.T) {
+	db, repo := newQueueRepository(t)
+	eb := &PersistentEventBus{
+		repo: repo,
+		opts: PersistentEventBusOptions{BatchSize: 10, GapGrace: time.Minute},
+		stop: make(chan struct{}),
+	}
+	rec := newRecorder()
+	sub := newTestSubscriber("test", rec.handle, nil)
+
+	saveOutboxEvent(t, db, 1, EventTypeEchoCreated, time.Now().Add(-time.Hour))
+	// 超过宽限期仍未出现的序号视为事务已回滚
+	saveOutboxEvent(t, db, 3, EventTypeEchoDeleted, time.Now().Add(-2*time.Minute))
+
+	if err := eb.deliverPending(sub, false); err != nil {
+		t.Fatalf("deliver: %v", err)
+	}
+	if got := rec.types(); len(got) != 2 {
+		t.Errorf("delivered %v, want both events", got)
+	}
+	if c := cursorOf(t, repo, "test"); c != 3 {
+		t.Errorf("cursor = %d, want 3", c)
+	}
+}
+
+func TestDeliverPendingFilterAdvancesCursor(t *testing.T) {
+	db, repo := newQueueRepository(t)
+	eb := &PersistentEventBus{
+		repo: repo,
+		opts: PersistentEventBusOptions{BatchSize: 10},
+		stop: make(chan struct{}),
+	}
+	rec := newRecorder()
+	sub := newTestSubscriber("test", rec.handle, func(et EventType) bool {
+		return et == EventTypeUserCreated
+	})
+
+	saveOutboxEvent(t, db, 1, EventTypeEchoCreated, time.Now())
+	saveOutboxEvent(t, db, 2, EventTypeUserCreated, time.Now())
+	saveOutboxEvent(t, db, 3, EventTypeEchoDeleted, time.Now())
+
+	if err := eb.deliverPending(sub, false); err != nil {
+		t.Fatalf("deliver: %v", err)
+	}
+	if got := rec.types(); len(got) != 1 || got[0] != EventTypeUserCreated {
+		t.Errorf("delivered %v", got)
+	}
+	// 被过滤的事件同样推进游标，避免重启后反复读取
+	if c := cursorOf(t, repo, "test"); c != 3 {
+		t.Errorf("cursor = %d, want 3", c)
+	}
+}
+
+func TestDeliverRetriesAndGivesUp(t *testing.T) {
+	db, repo := newQueueRepository(t)
+	eb := &PersistentEventBus{
+		repo: repo,
+		opts: PersistentEventBusOptions{
+			BatchSize: 10,
+			Retry: queueModel.BackoffPolicy{
+				InitialBackoff: time.Millisecond,
+				MaxBackoff:     time.Millisecond,
+				Multiplier:     1,
+				MaxRetries:     2,
+			},
+		},
+		stop: make(chan struct{}),
+	}
+
+	var calls int
+	sub := newTestSubscriber("test", func(context.Context, *Event) error {
+		calls++
+		panic("handler bug")
+	}, nil)
+	saveOutboxEvent(t, db, 1, EventTypeEchoCreated, time.Now())
+
+	if err := eb.deliverPending(sub, false); err != nil {
+		t.Fatalf("deliver: %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("handler called %d times, want 1 attempt and 2 retries", calls)
+	}
+	// 放弃后写入死信队列并跳过该事件
+	if c := cursorOf(t, repo, "test"); c != 1 {
+		t.Errorf("cursor = %d, want 1", c)
+	}
+	deadLetters, err := repo.ListDeadLetters(10)
+	if err != nil {
+		t.Fatalf("list dead letters: %v", err)
+	}
+	if len(deadLetters) != 1 || deadLetters[0].Type != queueModel.DeadLetterTypeEvent ||
+		deadLetters[0].Status != queueModel.DeadLetterStatusPending {
+		t.Fatalf("dead letters = %+v", deadLetters)
+	}
+	var replay EventReplayPayload
+	if err := json.Unmarshal(deadLetters[0].Payload, &replay); err != nil || replay.Subscriber != "test" {
+		t.Errorf("dead letter payload = %s", deadLetters[0].Payload)
+	}
+
+	// 停止时不重试，游标停留在失败的事件之前
+	saveOutboxEvent(t, db, 2, EventTypeEchoUpdated, time.Now())
+	calls = 0
+	if err := eb.deliverPending(sub, true); err == nil {
+		t.Error("expected draining delivery to return the handler error")
+	}
+	if calls != 1 {
+		t.Errorf("handler called %d times while draining, want 1", calls)
+	}
+	if c := cursorOf(t, repo, "test"); c != 1 {
+		t.Errorf("cursor = %d, want 1", c)
+	}
+}
+
+func TestPersistentEventBusResumesFromCursor(t *testing.T) {
+	db, repo := newQueueRepository(t)
+	opts := PersistentEventBusOptions{PollInterval: 10 * time.Millisecond, BatchSize: 10, GapGrace: time.Minute}
+	ctx := context.Background()
+
+	// 总线启动前的历史事件不会投递给新订阅者
+	saveOutboxEvent(t, db, 1, EventTypeSystemBackup, time.Now())
+
+	rec := newRecorder()
+	eb, err := NewPersistentEventBus(repo, opts)
+	if err != nil {
+		t.Fatalf("new bus: %v", err)
+	}
+	if err := eb.SubscribeAll(rec.handle); err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	user := userModel.User{ID: 1, Username: "alice", Password: "secret-hash"}
+	if err := eb.Publish(ctx, NewEvent(EventTypeUserCreated, EventPayload{EventPayloadUser: user})); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	rec.wait(t, 1)
+	eb.Close()
+
+	got := rec.event(0)
+	if got.Type != EventTypeUserCreated {
+		t.Fatalf("delivered %v", rec.types())
+	}
+	// 负载还原为具体类型，且凭据没有写入 outbox
+	decoded, ok := got.Payload[EventPayloadUser].(userModel.User)
+	if !ok || decoded.Username != "alice" || decoded.Password != "" {
+		t.Errorf("user payload = %#v", got.Payload[EventPayloadUser])
+	}
+
+	// 停机期间写入的事件在重启后从游标处继续投递
+	offline, err := NewPersistentEventBus(repo, opts)
+	if err != nil {
+		t.Fatalf("new bus: %v", err)
+	}
+	echo := echoModel.Echo{ID: 7, Content: "hello"}
+	if err := offline.Publish(ctx, NewEvent(EventTypeEchoCreated, EventPayload{EventPayloadEcho: echo})); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	offline.Close()
+
+	eb, err = NewPersistentEventBus(repo, opts)
+	if err != nil {
+		t.Fatalf("new bus: %v", err)
+	}
+	defer eb.Close()
+	// 同一处理函数的订阅者名称不变，复用重启前的游标
+	if err := eb.SubscribeAll(rec.handle); err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	rec.wait(t, 1)
+
+	types := rec.types()
+	if len(types) != 2 || types[1] != EventTypeEchoCreated {
+		t.Fatalf("delivered %v, want the offline event once", types)
+	}
+	if e, ok := rec.event(1).Payload[EventPayloadEcho].(echoModel.Echo); !ok || e.ID != 7 {
+		t.Errorf("echo payload = %#v", rec.event(1).Payload[EventPayloadEcho])
+	}
+}
+
+func TestRedeliverEventDeadLetter(t *testing.T) {
+	db, repo := newQueueRepository(t)
+	eb, err := NewPersistentEventBus(repo, PersistentEventBusOptions{PollInterval: time.Hour, BatchSize: 10})
+	if err != nil {
+		t.Fatalf("new bus: %v", err)
+	}
+	defer eb.Close()
+	rec := newRecorder()
+	if err := eb.Subscribe(rec.handle, EventTypeEchoCreated); err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	resolver := NewDeadLetterResolver(
+		func() IEventBus { return eb },
+		repo,
+		inboxRepository.NewInboxRepository(func() *gorm.DB { return db }),
+		nil,
+		nil,
+	)
+
+	data, _ := json.Marshal(NewEvent(EventTypeEchoCreated, EventPayload{EventPayloadEcho: echoModel.Echo{ID: 7}}))
+	resolve := func(subscriber string) queueModel.DeadLetter {
+		t.Helper()
+		payload, _ := json.Marshal(EventReplayPayload{Subscriber: subscriber, Data: string(data)})
+		deadLetter := queueModel.DeadLetter{
+			Type:      queueModel.DeadLetterTypeEvent,
+			Payload:   payload,
+			Status:    queueModel.DeadLetterStatusPending,
+			NextRetry: time.Now(),
+		}
+		if err := repo.SaveDeadLetter(context.Background(), &deadLetter); err != nil {
+			t.Fatalf("save dead letter: %v", err)
+		}
+		_ = resolver.Handle(context.Background(), NewEvent(EventTypeDeadLetterRetried, EventPayload{
+			EventPayloadDeadLetter: deadLetter,
+		}))
+		current, err := repo.GetDeadLetterByID(deadLetter.ID)
+		if err != nil || current == nil {
+			t.Fatalf("load dead letter: %v", err)
+		}
+		return *current
+	}
+
+	// 重新交给原订阅者处理，负载还原为具体类型
+	if got := resolve(eb.subs[0].name); got.Status != queueModel.DeadLetterStatusCompleted {
+		t.Fatalf("status = %s (%s), want completed", got.Status, got.ErrorMsg)
+	}
+	if got := rec.types(); len(got) != 1 || got[0] != EventTypeEchoCreated {
+		t.Fatalf("delivered %v", got)
+	}
+	if e, ok := rec.event(0).Payload[EventPayloadEcho].(echoModel.Echo); !ok || e.ID != 7 {
+		t.Errorf("echo payload = %#v", rec.event(0).Payload[EventPayloadEcho])
+	}
+
+	// 订阅者已不存在时丢弃
+	if got := resolve("removed.Handle"); got.Status != queueModel.DeadLetterStatusDiscarded {
+		t.Errorf("status = %s, want discarded", got.Status)
+	}
+}
@@ -8,6 +8,7 @@ type EventHandlers struct {
 	bs  *BackupScheduler    // 备份事件调度器
 	ap  *AgentProcessor     // Agent事件处理器
 	id  *InboxDispatcher    // Inbox事件处理器
+	si  *SearchIndexer      // 全文索引事件处理器
 }
 
 // NewEventHandlers 创建一个新的事件处理器集合
@@ -18,8 +19,9 @@ func NewEventHandlers(
 	bs *BackupScheduler,
 	ap *AgentProcessor,
 	id *InboxDispatcher,
+	si *SearchIndexer,
 ) *EventHandlers {
-	return &EventHandlers{wbd: wbd, dlr: dlr, fa: fa, bs: bs, ap: ap, id: id}
+	return &EventHandlers{wbd: wbd, dlr: dlr, fa: fa, bs: bs, ap: ap, id: id, si: si}
 }
 
 // EventRegistrar 事件注册器
@@ -44,10 +46,23 @@ func (er *EventRegistrar) Register() error {
 	if err != nil {
 		return err
 	}
-	err = er.eb.Subscribe(
+	err = er.eb.Subscribes(
 		er.eh.fa.Handle,
 		EventTypeEchoCreated,
-	) // 订阅 EchoCreated 事件，交给 FediverseAgent 处理
+		EventTypeEchoUpdated,
+		EventTypeEchoDeleted,
+		EventTypeEchoRestored,
+	) // 订阅 Echo 创建、更新、删除、恢复事件，交给 FediverseAgent 处理
+	if err != nil {
+		return err
+	}
+	err = er.eb.Subscribes(
+		er.eh.si.Handle,
+		EventTypeEchoCreated,
+		EventTypeEchoUpdated,
+		EventTypeEchoDeleted,
+		EventTypeEchoRestored,
+	) // 订阅 Echo 创建、更新、删除、恢复事件，交给 SearchIndexer 维护全文索引
 	if err != nil {
 		return err
 	}
@@ -94,6 +109,9 @@ func (er *EventRegistrar) Register() error {
 
 // Wait 等待所有事件处理完成
 func (er *EventRegistrar) Wait() {
+	// 持久化事件总线需要先停止投递，处理完已写入的事件后再等待各处理器的任务
+	if closer, ok := er.eb.(interface{ Close() }); ok {
+		closer.Close()
+	}
 	er.eh.wbd.Wait()
-	er.eh.fa.Wait()
 }
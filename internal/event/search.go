@@ -0,0 +1,34 @@
+package event
+
+import (
+	"context"
+
+	echoModel "github.com/lin-snow/ech0/internal/model/echo"
+	echoRepository "github.com/lin-snow/ech0/internal/repository/echo"
+)
+
+// SearchIndexer 处理 Echo 事件，保持全文索引与 Echo 同步
+type SearchIndexer struct {
+	echoRepo echoRepository.EchoRepositoryInterface
+}
+
+func NewSearchIndexer(echoRepo echoRepository.EchoRepositoryInterface) *SearchIndexer {
+	return &SearchIndexer{echoRepo: echoRepo}
+}
+
+func (si *SearchIndexer) Handle(ctx context.Context, e *Event) error {
+	echo, ok := e.Payload[EventPayloadEcho].(echoModel.Echo)
+	if !ok || echo.ID == 0 {
+		return nil
+	}
+
+	switch e.Type {
+	case EventTypeEchoCreated, EventTypeEchoUpdated, EventTypeEchoRestored:
+		// 以数据库中的最新内容为准，避免事件乱序导致索引内容过期
+		return si.echoRepo.IndexEcho(ctx, echo.ID)
+	case EventTypeEchoDeleted:
+		return si.echoRepo.RemoveEchoIndex(ctx, echo.ID)
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package exporter
+
+import (
+	"archive/zip"
+	"errors"
+	"io"
+	"time"
+
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	echoModel "github.com/lin-snow/ech0/internal/model/echo"
+	model "github.com/lin-snow/ech0/internal/model/exporter"
+)
+
+// Options 导出选项
+type Options struct {
+	Title     string                                             // 静态站点的标题
+	Echos     func(offset, limit int) ([]echoModel.Echo, error)  // 分批读取待导出的 Echo
+	OpenImage func(image echoModel.Image) (io.ReadCloser, error) // 打开图片文件，返回 nil 时保留图片的原始地址
+}
+
+// Export 按指定格式将全部 Echo 写入 writer，Markdown 与 HTML 格式写入的是 ZIP 归档
+func Export(format string, writer io.Writer, options Options) error {
+	switch format {
+	case model.ExportFormatMarkdown:
+		return writeMarkdown(writer, options)
+	case model.ExportFormatJSONL:
+		return writeJSONL(writer, options)
+	case model.ExportFormatHTML:
+		return writeHTML(writer, options)
+	default:
+		return errors.New(commonModel.EXPORT_FORMAT_INVALID)
+	}
+}
+
+// eachEcho 分批遍历全部 Echo，避免一次性加载到内存
+func eachEcho(options Options, fn func(echo *echoModel.Echo) error) error {
+	for offset := 0; ; offset += model.ExportBatchSize {
+		echos, err := options.Echos(offset, model.ExportBatchSize)
+		if err != nil {
+			return err
+		}
+		for i := range echos {
+			if err := fn(&echos[i]); err != nil {
+				return err
+			}
+		}
+		if len(echos) < model.ExportBatchSize {
+			return nil
+		}
+	}
+}
+
+// tagNames 获取 Echo 的标签名称
+func tagNames(echo *echoModel.Echo) []string {
+	names := make([]string, 0, len(echo.Tags))
+	for _, tag := range echo.Tags {
+		names = append(names, tag.Name)
+	}
+	return names
+}
+
+// createEntry 在归档中创建文件并记录修改时间
+func createEntry(archive *zip.Writer, name string, modified time.Time) (io.Writer, error) {
+	return archive.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: modified,
+	})
+}
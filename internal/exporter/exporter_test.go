@@ -0,0 +1,290 @@
+package exporter
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	echoModel "github.com/lin-snow/ech0/internal/model/echo"
+	model "github.com/lin-snow/ech0/internal/model/exporter"
+)
+
+// echoSource 以切片模拟分批读取，记录每批的偏移量
+type echoSource struct {
+	echos   []echoModel.Echo
+	offsets []int
+}
+
+func (source *echoSource) read(offset, limit int) ([]echoModel.Echo, error) {
+	source.offsets = append(source.offsets, offset)
+	if offset >= len(source.echos) {
+		return nil, nil
+	}
+	return source.echos[offset:min(offset+limit, len(source.echos))], nil
+}
+
+// numberedEchos 生成 n 条按时间倒序排列的 Echo，ID 从 n 递减到 1
+func numberedEchos(n int) []echoModel.Echo {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	echos := make([]echoModel.Echo, 0, n)
+	for id := n; id >= 1; id-- {
+		echos = append(echos, echoModel.Echo{
+			ID:         uint(id),
+			Content:    fmt.Sprintf("第 %d 条", id),
+			Visibility: echoModel.VisibilityPublic,
+			Status:     echoModel.EchoStatusPublished,
+			CreatedAt:  start.Add(time.Duration(id) * time.Hour),
+		})
+	}
+	return echos
+}
+
+// readZip 读取归档中的全部文件
+func readZip(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("open zip: %v", err)
+	}
+	files := make(map[string]string, len(reader.File))
+	for _, file := range reader.File {
+		rc, err := file.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", file.Name, err)
+		}
+		content, err := io.ReadAll(rc)
+		_ = rc.Close()
+		if err != nil {
+			t.Fatalf("read %s: %v", file.Name, err)
+		}
+		files[file.Name] = string(content)
+	}
+	return files
+}
+
+// openImages 按图片地址返回内容：local 开头的图片可以读取，url 开头的保留原始地址，其余读取失败
+func openImages(image echoModel.Image) (io.ReadCloser, error) {
+	switch {
+	case strings.HasPrefix(image.ImageURL, "/images/local"):
+		return io.NopCloser(strings.NewReader("image " + image.ImageURL)), nil
+	case strings.HasPrefix(image.ImageURL, "https://"):
+		return nil, nil
+	default:
+		return nil, errors.New("missing")
+	}
+}
+
+func TestExportBatches(t *testing.T) {
+	tests := map[string]struct {
+		count       int
+		wantOffsets []int
+	}{
+		"没有 Echo":   {count: 0, wantOffsets: []int{0}},
+		"不足一批":      {count: 3, wantOffsets: []int{0}},
+		"刚好一批时再读一次": {count: model.ExportBatchSize, wantOffsets: []int{0, model.ExportBatchSize}},
+		"多批": {
+			count:       2*model.ExportBatchSize + 1,
+			wantOffsets: []int{0, model.ExportBatchSize, 2 * model.ExportBatchSize},
+		},
+	}
+
+	for name, tt := range tests {
+		source := &echoSource{echos: numberedEchos(tt.count)}
+		var buf bytes.Buffer
+		if err := Export(model.ExportFormatJSONL, &buf, Options{Echos: source.read}); err != nil {
+			t.Fatalf("%s: export: %v", name, err)
+		}
+		if lines := strings.Count(buf.String(), "\n"); lines != tt.count {
+			t.Errorf("%s: %d lines", name, lines)
+		}
+		if !slices.Equal(source.offsets, tt.wantOffsets) {
+			t.Errorf("%s: offsets = %v, want %v", name, source.offsets, tt.wantOffsets)
+		}
+	}
+
+	source := &echoSource{}
+	if err := Export("pdf", io.Discard, Options{Echos: source.read}); err == nil || err.Error() != commonModel.EXPORT_FORMAT_INVALID {
+		t.Errorf("invalid format err = %v", err)
+	}
+
+	failing := func(int, int) ([]echoModel.Echo, error) { return nil, errors.New("db closed") }
+	if err := Export(model.ExportFormatMarkdown, io.Discard, Options{Echos: failing}); err == nil {
+		t.Error("read error was swallowed")
+	}
+}
+
+func TestExportJSONL(t *testing.T) {
+	echos := numberedEchos(2)
+	echos[0].Content = "<b>粗体</b> & 链接"
+	echos[0].Tags = []echoModel.Tag{{Name: "日记"}}
+	source := &echoSource{echos: echos}
+
+	var buf bytes.Buffer
+	if err := Export(model.ExportFormatJSONL, &buf, Options{Echos: source.read}); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if strings.Contains(buf.String(), `\u003c`) {
+		t.Error("html characters were escaped")
+	}
+
+	var got []echoModel.Echo
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var echo echoModel.Echo
+		if err := json.Unmarshal(scanner.Bytes(), &echo); err != nil {
+			t.Fatalf("decode %q: %v", scanner.Text(), err)
+		}
+		got = append(got, echo)
+	}
+	if len(got) != 2 || got[0].ID != 2 || got[0].Content != echos[0].Content ||
+		len(got[0].Tags) != 1 || got[0].Tags[0].Name != "日记" || !got[1].CreatedAt.Equal(echos[1].CreatedAt) {
+		t.Errorf("decoded = %+v", got)
+	}
+}
+
+func TestExportMarkdown(t *testing.T) {
+	pinnedAt := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	echo := echoModel.Echo{
+		ID:            7,
+		Content:       "  正文内容  ",
+		Visibility:    echoModel.VisibilityMembers,
+		Status:        echoModel.EchoStatusPublished,
+		Layout:        echoModel.LayoutWaterfall,
+		ReplyToID:     3,
+		PinnedAt:      &pinnedAt,
+		ExtensionType: "MUSIC",
+		Extension:     "https://music.example/1",
+		CreatedAt:     time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Tags:          []echoModel.Tag{{Name: "日记"}, {Name: "go"}},
+		Images: []echoModel.Image{
+			{ID: 1, ImageURL: "/images/local.png"},
+			{ID: 2, ImageURL: "https://cdn.example/remote.jpg"},
+			{ID: 3, ImageURL: "/images/missing.png"},
+			{ID: 4},
+		},
+	}
+	source := &echoSource{echos: []echoModel.Echo{echo}}
+
+	var buf bytes.Buffer
+	if err := Export(model.ExportFormatMarkdown, &buf, Options{Echos: source.read, OpenImage: openImages}); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	files := readZip(t, buf.Bytes())
+
+	// 能读取的图片写入媒体目录，其余保留原始地址
+	if files["media/1-local.png"] != "image /images/local.png" || len(files) != 2 {
+		t.Errorf("files = %v", slices.Sorted(func(yield func(string) bool) {
+			for name := range files {
+				if !yield(name) {
+					return
+				}
+			}
+		}))
+	}
+
+	markdown, ok := files["echos/2024-01-02-7.md"]
+	if !ok {
+		t.Fatal("markdown file missing")
+	}
+	parts := strings.SplitN(markdown, "---\n", 3)
+	if len(parts) != 3 || parts[0] != "" {
+		t.Fatalf("markdown = %q", markdown)
+	}
+	var frontMatter markdownFrontMatter
+	if err := yaml.Unmarshal([]byte(parts[1]), &frontMatter); err != nil {
+		t.Fatalf("front matter: %v", err)
+	}
+	images := []string{"../media/1-local.png", "https://cdn.example/remote.jpg", "/images/missing.png"}
+	if frontMatter.ID != 7 || !frontMatter.CreatedAt.Equal(echo.CreatedAt) ||
+		frontMatter.Visibility != echoModel.VisibilityMembers || frontMatter.ReplyToID != 3 ||
+		frontMatter.PinnedAt == nil || !frontMatter.PinnedAt.Equal(pinnedAt) ||
+		frontMatter.ExtensionType != "MUSIC" || !slices.Equal(frontMatter.Tags, []string{"日记", "go"}) ||
+		!slices.Equal(frontMatter.Images, images) {
+		t.Errorf("front matter = %+v", frontMatter)
+	}
+
+	wantBody := "\n正文内容\n"
+	for _, image := range images {
+		wantBody += "\n![](" + image + ")\n"
+	}
+	if parts[2] != wantBody {
+		t.Errorf("body = %q, want %q", parts[2], wantBody)
+	}
+}
+
+func TestExportHTML(t *testing.T) {
+	count := 2*model.ExportHTMLPageSize + 1
+	echos := numberedEchos(count)
+	echos[0].Content = "**加粗** <script>alert(1)</script>"
+	echos[0].Visibility = echoModel.VisibilityPrivate
+	echos[0].QuoteOfID = 5
+	echos[0].Images = []echoModel.Image{{ID: 9, ImageURL: "/images/local.png"}}
+	source := &echoSource{echos: echos}
+
+	var buf bytes.Buffer
+	if err := Export(model.ExportFormatHTML, &buf, Options{Echos: source.read, OpenImage: openImages}); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	files := readZip(t, buf.Bytes())
+
+	// 首页分页：最后一页没有“下一页”，第一页没有“上一页”
+	tests := map[string]struct {
+		title      string
+		first      uint
+		prev, next bool
+	}{
+		"index.html":  {title: "<title>Ech0</title>", first: uint(count), next: true},
+		"page-2.html": {title: "<title>Ech0 - 第 2 页</title>", first: uint(count - model.ExportHTMLPageSize), prev: true, next: true},
+		"page-3.html": {title: "<title>Ech0 - 第 3 页</title>", first: 1, prev: true},
+	}
+	for name, tt := range tests {
+		page, ok := files[name]
+		if !ok {
+			t.Errorf("%s: missing", name)
+			continue
+		}
+		if !strings.Contains(page, tt.title) ||
+			!strings.Contains(page, fmt.Sprintf(`id="echo-%d"`, tt.first)) ||
+			strings.Contains(page, "上一页") != tt.prev || strings.Contains(page, "下一页") != tt.next {
+			t.Errorf("%s: unexpected page", name)
+		}
+	}
+	if _, ok := files["page-4.html"]; ok {
+		t.Error("empty page written")
+	}
+	if files["style.css"] == "" || files["media/9-local.png"] == "" {
+		t.Error("style sheet or media missing")
+	}
+
+	// 每条 Echo 都有独立页面，正文按 Markdown 渲染，非公开的可见性显示标记
+	for id := 1; id <= count; id++ {
+		if _, ok := files[fmt.Sprintf("echo-%d.html", id)]; !ok {
+			t.Errorf("echo-%d.html missing", id)
+		}
+	}
+	page := files[fmt.Sprintf("echo-%d.html", count)]
+	for _, want := range []string{
+		"<strong>加粗</strong>",
+		`<span class="badge">private</span>`,
+		`引用 <a href="echo-5.html">#5</a>`,
+		`<img src="media/9-local.png"`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("echo page missing %q", want)
+		}
+	}
+	if strings.Contains(page, "<script>") {
+		t.Error("raw html rendered")
+	}
+}
@@ -0,0 +1,229 @@
+package exporter
+
+import (
+	"archive/zip"
+	"fmt"
+	"html/template"
+	"io"
+	"time"
+
+	echoModel "github.com/lin-snow/ech0/internal/model/echo"
+	model "github.com/lin-snow/ech0/internal/model/exporter"
+	mdUtil "github.com/lin-snow/ech0/internal/util/md"
+)
+
+// htmlEcho 静态站点中展示的 Echo
+type htmlEcho struct {
+	ID            uint
+	Permalink     string
+	CreatedAt     time.Time
+	Visibility    string
+	Status        string
+	Content       template.HTML
+	Images        []string
+	Tags          []string
+	ReplyToID     uint
+	QuoteOfID     uint
+	ExtensionType string
+	Extension     string
+}
+
+// htmlPage 静态站点的页面
+type htmlPage struct {
+	SiteTitle  string
+	Title      string
+	Echos      []htmlEcho
+	Prev       string
+	Next       string
+	ExportedAt time.Time
+}
+
+// writeHTML 生成可直接浏览的静态站点：分页的首页、每条 Echo 的独立页面、样式表与媒体目录，打包为 ZIP
+func writeHTML(writer io.Writer, options Options) error {
+	archive := zip.NewWriter(writer)
+	media := &mediaWriter{archive: archive, open: options.OpenImage}
+
+	title := options.Title
+	if title == "" {
+		title = "Ech0"
+	}
+	exportedAt := time.Now()
+
+	style, err := createEntry(archive, "style.css", exportedAt)
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(style, htmlStyle); err != nil {
+		return err
+	}
+
+	// 首页按页写入，确认还有下一条 Echo 时才写入已满的一页，保证最后一页没有“下一页”链接
+	pageNumber := 1
+	var page []htmlEcho
+	writePage := func(hasNext bool) error {
+		current := htmlPage{
+			SiteTitle:  title,
+			Title:      title,
+			Echos:      page,
+			ExportedAt: exportedAt,
+		}
+		if pageNumber > 1 {
+			current.Title = fmt.Sprintf("%s - 第 %d 页", title, pageNumber)
+			current.Prev = htmlPageName(pageNumber - 1)
+		}
+		if hasNext {
+			current.Next = htmlPageName(pageNumber + 1)
+		}
+		return writeHTMLPage(archive, htmlPageName(pageNumber), exportedAt, current)
+	}
+
+	if err := eachEcho(options, func(echo *echoModel.Echo) error {
+		if len(page) == model.ExportHTMLPageSize {
+			if err := writePage(true); err != nil {
+				return err
+			}
+			pageNumber++
+			page = nil
+		}
+
+		// 正文可能来自导入的外部内容，渲染后清理脚本等不安全的 HTML
+		item := htmlEcho{
+			ID:            echo.ID,
+			Permalink:     htmlEchoName(echo.ID),
+			CreatedAt:     echo.CreatedAt,
+			Visibility:    echo.Visibility,
+			Status:        echo.Status,
+			Content:       template.HTML(mdUtil.SanitizeHTML(string(mdUtil.MdToHTML([]byte(echo.Content))))),
+			Images:        media.write(echo),
+			Tags:          tagNames(echo),
+			ReplyToID:     echo.ReplyToID,
+			QuoteOfID:     echo.QuoteOfID,
+			ExtensionType: echo.ExtensionType,
+			Extension:     echo.Extension,
+		}
+		page = append(page, item)
+
+		return writeHTMLPage(archive, item.Permalink, echo.CreatedAt, htmlPage{
+			SiteTitle:  title,
+			Title:      fmt.Sprintf("%s - #%d", title, echo.ID),
+			Echos:      []htmlEcho{item},
+			ExportedAt: exportedAt,
+		})
+	}); err != nil {
+		return err
+	}
+	if err := writePage(false); err != nil {
+		return err
+	}
+
+	return archive.Close()
+}
+
+// htmlPageName 首页分页的文件名
+func htmlPageName(number int) string {
+	if number <= 1 {
+		return "index.html"
+	}
+	return fmt.Sprintf("page-%d.html", number)
+}
+
+// htmlEchoName Echo 独立页面的文件名
+func htmlEchoName(id uint) string {
+	return fmt.Sprintf("echo-%d.html", id)
+}
+
+// writeHTMLPage 渲染并写入一个页面
+func writeHTMLPage(archive *zip.Writer, name string, modified time.Time, page htmlPage) error {
+	file, err := createEntry(archive, name, modified)
+	if err != nil {
+		return err
+	}
+	return htmlTemplate.Execute(file, page)
+}
+
+var htmlTemplate = template.Must(template.New("page").Funcs(template.FuncMap{
+	"echoPage": htmlEchoName,
+	"date": func(t time.Time) string {
+		return t.Format("2006-01-02 15:04")
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<link rel="stylesheet" href="style.css">
+</head>
+<body>
+<header><h1><a href="index.html">{{.SiteTitle}}</a></h1></header>
+<main>
+{{- range .Echos}}
+<article class="echo" id="echo-{{.ID}}">
+<div class="meta">
+<a href="{{.Permalink}}"><time datetime="{{.CreatedAt.Format "2006-01-02T15:04:05Z07:00"}}">{{date .CreatedAt}}</time></a>
+{{- if ne .Visibility "public"}} <span class="badge">{{.Visibility}}</span>{{end}}
+{{- if ne .Status "published"}} <span class="badge">{{.Status}}</span>{{end}}
+</div>
+{{- if .ReplyToID}}
+<p class="ref">回复 <a href="{{echoPage .ReplyToID}}">#{{.ReplyToID}}</a></p>
+{{- end}}
+{{- if .QuoteOfID}}
+<p class="ref">引用 <a href="{{echoPage .QuoteOfID}}">#{{.QuoteOfID}}</a></p>
+{{- end}}
+<div class="content">{{.Content}}</div>
+{{- if .Images}}
+<div class="images">{{range .Images}}<a href="{{.}}"><img src="{{.}}" loading="lazy" alt=""></a>{{end}}</div>
+{{- end}}
+{{- if .Extension}}
+<p class="extension">{{.ExtensionType}}: {{.Extension}}</p>
+{{- end}}
+{{- if .Tags}}
+<p class="tags">{{range .Tags}}<span>#{{.}}</span> {{end}}</p>
+{{- end}}
+</article>
+{{- end}}
+</main>
+{{- if or .Prev .Next}}
+<nav class="pager">
+{{- if .Prev}}<a href="{{.Prev}}">上一页</a>{{end}}
+{{- if .Next}}<a href="{{.Next}}">下一页</a>{{end}}
+</nav>
+{{- end}}
+<footer>导出于 {{date .ExportedAt}}</footer>
+</body>
+</html>
+`))
+
+const htmlStyle = `body {
+  max-width: 720px;
+  margin: 0 auto;
+  padding: 1rem;
+  font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", "PingFang SC", "Microsoft YaHei", sans-serif;
+  line-height: 1.6;
+  color: #333;
+  background: #fafafa;
+}
+header a { color: inherit; text-decoration: none; }
+a { color: #d97706; }
+.echo {
+  margin: 1rem 0;
+  padding: 1rem;
+  background: #fff;
+  border-radius: 8px;
+  box-shadow: 0 1px 3px rgba(0, 0, 0, 0.08);
+}
+.meta { font-size: 0.875rem; color: #888; }
+.meta a { color: inherit; }
+.badge {
+  margin-left: 0.5rem;
+  padding: 0 0.4rem;
+  border-radius: 4px;
+  background: #eee;
+}
+.ref, .extension, .tags { font-size: 0.875rem; color: #666; }
+.content img, .images img { max-width: 100%; border-radius: 4px; }
+.images { display: flex; flex-wrap: wrap; gap: 0.5rem; }
+.images a { flex: 1 1 30%; }
+.pager { display: flex; justify-content: space-between; margin: 1rem 0; }
+footer { margin: 2rem 0; font-size: 0.75rem; color: #aaa; text-align: center; }
+`
@@ -0,0 +1,17 @@
+package exporter
+
+import (
+	"encoding/json"
+	"io"
+
+	echoModel "github.com/lin-snow/ech0/internal/model/echo"
+)
+
+// writeJSONL 每行写入一条 Echo 的 JSON，字段与 API 返回的 Echo 一致
+func writeJSONL(writer io.Writer, options Options) error {
+	encoder := json.NewEncoder(writer)
+	encoder.SetEscapeHTML(false)
+	return eachEcho(options, func(echo *echoModel.Echo) error {
+		return encoder.Encode(echo)
+	})
+}
@@ -0,0 +1,98 @@
+package exporter
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	echoModel "github.com/lin-snow/ech0/internal/model/echo"
+	"gopkg.in/yaml.v3"
+)
+
+// markdownFrontMatter Markdown 文件的 YAML front-matter
+type markdownFrontMatter struct {
+	ID            uint       `yaml:"id"`
+	CreatedAt     time.Time  `yaml:"created_at"`
+	Visibility    string     `yaml:"visibility"`
+	Status        string     `yaml:"status"`
+	PublishAt     *time.Time `yaml:"publish_at,omitempty"`
+	PinnedAt      *time.Time `yaml:"pinned_at,omitempty"`
+	Layout        string     `yaml:"layout,omitempty"`
+	Tags          []string   `yaml:"tags,omitempty"`
+	ReplyToID     uint       `yaml:"reply_to_id,omitempty"`
+	QuoteOfID     uint       `yaml:"quote_of_id,omitempty"`
+	ExtensionType string     `yaml:"extension_type,omitempty"`
+	Extension     string     `yaml:"extension,omitempty"`
+	Images        []string   `yaml:"images,omitempty"`
+}
+
+// writeMarkdown 将每条 Echo 写为 echos 目录下的 Markdown 文件，图片写入 media 目录，打包为 ZIP
+func writeMarkdown(writer io.Writer, options Options) error {
+	archive := zip.NewWriter(writer)
+	media := &mediaWriter{archive: archive, open: options.OpenImage}
+
+	if err := eachEcho(options, func(echo *echoModel.Echo) error {
+		images := media.write(echo)
+
+		// Markdown 文件位于 echos 目录中，归档内的图片需要使用上级目录的相对路径
+		for i, image := range images {
+			if !isRemote(image) {
+				images[i] = "../" + image
+			}
+		}
+
+		file, err := createEntry(archive, markdownFileName(echo), echo.CreatedAt)
+		if err != nil {
+			return err
+		}
+		return writeMarkdownFile(file, echo, images)
+	}); err != nil {
+		return err
+	}
+
+	return archive.Close()
+}
+
+// markdownFileName Markdown 文件名，按日期排序
+func markdownFileName(echo *echoModel.Echo) string {
+	return fmt.Sprintf("echos/%s-%d.md", echo.CreatedAt.Format("2006-01-02"), echo.ID)
+}
+
+// writeMarkdownFile 写入 front-matter 与正文，图片追加在正文之后
+func writeMarkdownFile(writer io.Writer, echo *echoModel.Echo, images []string) error {
+	frontMatter, err := yaml.Marshal(markdownFrontMatter{
+		ID:            echo.ID,
+		CreatedAt:     echo.CreatedAt,
+		Visibility:    echo.Visibility,
+		Status:        echo.Status,
+		PublishAt:     echo.PublishAt,
+		PinnedAt:      echo.PinnedAt,
+		Layout:        echo.Layout,
+		Tags:          tagNames(echo),
+		ReplyToID:     echo.ReplyToID,
+		QuoteOfID:     echo.QuoteOfID,
+		ExtensionType: echo.ExtensionType,
+		Extension:     echo.Extension,
+		Images:        images,
+	})
+	if err != nil {
+		return err
+	}
+
+	var builder strings.Builder
+	builder.WriteString("---\n")
+	builder.Write(frontMatter)
+	builder.WriteString("---\n\n")
+	if content := strings.TrimSpace(echo.Content); content != "" {
+		builder.WriteString(content)
+		builder.WriteString("\n")
+	}
+	for _, image := range images {
+		fmt.Fprintf(&builder, "\n![](%s)\n", image)
+	}
+
+	_, err = io.WriteString(writer, builder.String())
+	return err
+}
@@ -0,0 +1,68 @@
+package exporter
+
+import (
+	"archive/zip"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	echoModel "github.com/lin-snow/ech0/internal/model/echo"
+)
+
+// mediaDir 归档中存放媒体文件的目录
+const mediaDir = "media"
+
+// mediaWriter 将 Echo 的图片写入归档的媒体目录
+type mediaWriter struct {
+	archive *zip.Writer
+	open    func(image echoModel.Image) (io.ReadCloser, error)
+}
+
+// write 写入 Echo 的全部图片，返回每张图片相对于归档根目录的路径，无法读取的图片保留原始地址
+//
+// 必须在创建 Echo 自身的文件之前调用，zip.Writer 同一时间只能写入一个文件
+func (media *mediaWriter) write(echo *echoModel.Echo) []string {
+	paths := make([]string, 0, len(echo.Images))
+	for _, image := range echo.Images {
+		if image.ImageURL == "" {
+			continue
+		}
+		local, err := media.writeImage(image, echo.CreatedAt)
+		if err != nil || local == "" {
+			paths = append(paths, image.ImageURL)
+			continue
+		}
+		paths = append(paths, local)
+	}
+	return paths
+}
+
+// writeImage 写入单张图片，文件名以图片 ID 开头避免重名
+func (media *mediaWriter) writeImage(image echoModel.Image, modified time.Time) (string, error) {
+	if media.open == nil {
+		return "", nil
+	}
+	reader, err := media.open(image)
+	if err != nil || reader == nil {
+		return "", err
+	}
+	defer reader.Close()
+
+	name, _, _ := strings.Cut(path.Base(image.ImageURL), "?")
+	local := path.Join(mediaDir, strconv.FormatUint(uint64(image.ID), 10)+"-"+name)
+	file, err := createEntry(media.archive, local, modified)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(file, reader); err != nil {
+		return "", err
+	}
+	return local, nil
+}
+
+// isRemote 判断路径是否为图片的原始地址
+func isRemote(imagePath string) bool {
+	return strings.HasPrefix(imagePath, "/") || strings.Contains(imagePath, "://")
+}
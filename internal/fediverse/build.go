@@ -8,6 +8,7 @@ import (
 
 	"github.com/lin-snow/ech0/internal/config"
 	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	echoModel "github.com/lin-snow/ech0/internal/model/echo"
 	model "github.com/lin-snow/ech0/internal/model/fediverse"
 	settingModel "github.com/lin-snow/ech0/internal/model/setting"
 	userModel "github.com/lin-snow/ech0/internal/model/user"
@@ -51,6 +52,13 @@ func (core *FediverseCore) BuildActor(
 		Context: []any{
 			"https://www.w3.org/ns/activitystreams",
 			"https://w3id.org/security/v1",
+			map[string]any{
+				"toot": "http://joinmastodon.org/ns#",
+				"featured": map[string]any{
+					"@id":   "toot:featured",
+					"@type": "@id",
+				},
+			},
 		},
 		ID:                serverURL + "/users/" + user.Username, // 实例地址拼接 域名 + /users/ + username
 		Type:              "Person",                              // 固定值
@@ -67,10 +75,11 @@ func (core *FediverseCore) BuildActor(
 			MediaType: "image/png",
 			URL:       serverURL + "/banner.png", // 封面图片，固定为 /banner.png
 		},
-		Followers: serverURL + "/users/" + user.Username + "/followers", // 粉丝列表地址
-		Following: serverURL + "/users/" + user.Username + "/following", // 关注列表地址
-		Inbox:     serverURL + "/users/" + user.Username + "/inbox",     // 收件箱地址
-		Outbox:    serverURL + "/users/" + user.Username + "/outbox",    // 发件箱地址
+		Followers: serverURL + "/users/" + user.Username + "/followers",            // 粉丝列表地址
+		Following: serverURL + "/users/" + user.Username + "/following",            // 关注列表地址
+		Inbox:     serverURL + "/users/" + user.Username + "/inbox",                // 收件箱地址
+		Outbox:    serverURL + "/users/" + user.Username + "/outbox",               // 发件箱地址
+		Featured:  serverURL + "/users/" + user.Username + "/collections/featured", // 置顶帖子地址
 		PublicKey: model.PublicKey{
 			ID:           serverURL + "/users/" + user.Username + "#main-key",
 			Owner:        serverURL + "/users/" + user.Username,
@@ -100,7 +109,7 @@ func (core *FediverseCore) BuildOutbox(username string) (model.OutboxResponse, e
 	}
 
 	// 查 Echos
-	_, total := core.echoRepository.GetEchosByPage(1, 10, "", false)
+	_, total := core.echoRepository.GetEchosByPage(1, 10, "", echoModel.ReachableVisibilities())
 
 	firstPage := fmt.Sprintf("%s?page=1", actor.Outbox)
 	lastPage := ""
@@ -122,6 +131,48 @@ func (core *FediverseCore) BuildOutbox(username string) (model.OutboxResponse, e
 	}, nil
 }
 
+// BuildFeatured 构建置顶帖子集合，只包含无需登录即可访问的 Echo
+func (core *FediverseCore) BuildFeatured(username string) (model.FeaturedResponse, error) {
+	// 查询用户，确保用户存在
+	user, err := core.userRepository.GetUserByUsername(username)
+	if err != nil {
+		return model.FeaturedResponse{}, errors.New(commonModel.USER_NOTFOUND)
+	}
+
+	// 获取 Actor和 setting
+	actor, setting, err := core.BuildActor(&user)
+	if err != nil {
+		return model.FeaturedResponse{}, err
+	}
+
+	serverURL, err := NormalizeServerURL(setting.ServerURL)
+	if err != nil {
+		return model.FeaturedResponse{}, err
+	}
+
+	pinned, err := core.echoRepository.GetPinnedEchos(echoModel.ReachableVisibilities())
+	if err != nil {
+		return model.FeaturedResponse{}, err
+	}
+
+	// 置顶是全站的，精选集合只包含该用户自己的 Echo
+	objects := make([]model.Object, 0, len(pinned))
+	for i := range pinned {
+		if pinned[i].UserID != user.ID {
+			continue
+		}
+		objects = append(objects, core.ConvertEchoToObject(&pinned[i], &actor, serverURL))
+	}
+
+	return model.FeaturedResponse{
+		Context:      "https://www.w3.org/ns/activitystreams",
+		ID:           actor.Featured,
+		Type:         "OrderedCollection",
+		TotalItems:   len(objects),
+		OrderedItems: objects,
+	}, nil
+}
+
 // BuildAcceptActivityPayload 构建 Accept Activity 的 JSON Payload
 func (core *FediverseCore) BuildAcceptActivityPayload(
 	actor *model.Actor,
@@ -296,3 +347,87 @@ func BuildUndoLikeActivityPayload(
 
 	return json.Marshal(payload)
 }
+
+// BuildUpdateActivityPayload 构建 Update{Note} Activity 的 JSON Payload
+func BuildUpdateActivityPayload(
+	actor *model.Actor,
+	object *model.Object,
+	serverURL string,
+	updated time.Time,
+) ([]byte, error) {
+	if actor == nil {
+		return nil, errors.New("actor is nil")
+	}
+	if object == nil || object.ObjectID == "" {
+		return nil, errors.New("object is empty")
+	}
+
+	objectMap := map[string]any{}
+	objectBytes, err := json.Marshal(object)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(objectBytes, &objectMap); err != nil {
+		return nil, err
+	}
+	delete(objectMap, "@context")
+	objectMap["updated"] = updated.Format(time.RFC3339)
+
+	payload := map[string]any{
+		"@context": []any{"https://www.w3.org/ns/activitystreams"},
+		"id": fmt.Sprintf(
+			"%s/activities/%s/update/%d",
+			serverURL,
+			actor.PreferredUsername,
+			updated.UnixNano(),
+		),
+		"type":      model.ActivityTypeUpdate,
+		"actor":     actor.ID,
+		"object":    objectMap,
+		"to":        object.To,
+		"cc":        object.Cc,
+		"published": updated.Format(time.RFC3339),
+	}
+
+	return json.Marshal(payload)
+}
+
+// BuildDeleteActivityPayload 构建 Delete{Tombstone} Activity 的 JSON Payload，
+// 收件人与原 Echo 一致，避免非公开 Echo 的删除被公开投递
+func BuildDeleteActivityPayload(
+	actor *model.Actor,
+	objectID string,
+	serverURL string,
+	to, cc []string,
+	deleted time.Time,
+) ([]byte, error) {
+	if actor == nil {
+		return nil, errors.New("actor is nil")
+	}
+	if objectID == "" {
+		return nil, errors.New("object id is empty")
+	}
+
+	payload := map[string]any{
+		"@context": []any{"https://www.w3.org/ns/activitystreams"},
+		"id": fmt.Sprintf(
+			"%s/activities/%s/delete/%d",
+			serverURL,
+			actor.PreferredUsername,
+			deleted.UnixNano(),
+		),
+		"type":  model.ActivityTypeDelete,
+		"actor": actor.ID,
+		"object": map[string]any{
+			"id":         objectID,
+			"type":       model.ObjectTypeTombstone,
+			"formerType": "Note",
+			"deleted":    deleted.Format(time.RFC3339),
+		},
+		"to":        to,
+		"cc":        cc,
+		"published": deleted.Format(time.RFC3339),
+	}
+
+	return json.Marshal(payload)
+}
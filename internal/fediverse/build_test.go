@@ -0,0 +1,95 @@
+package fediverse
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+
+	echoModel "github.com/lin-snow/ech0/internal/model/echo"
+	model "github.com/lin-snow/ech0/internal/model/fediverse"
+)
+
+func testActor() *model.Actor {
+	return &model.Actor{
+		ID:                "https://ech0.example/users/alice",
+		PreferredUsername: "alice",
+		Followers:         "https://ech0.example/users/alice/followers",
+	}
+}
+
+func decodeActivity(t *testing.T, payload []byte) map[string]any {
+	t.Helper()
+	var activity map[string]any
+	if err := json.Unmarshal(payload, &activity); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	return activity
+}
+
+func TestBuildUpdateActivityPayload(t *testing.T) {
+	actor := testActor()
+	updated := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	object := &model.Object{
+		ObjectID: "https://ech0.example/objects/1",
+		Type:     "Note",
+		Content:  "edited",
+		To:       []string{model.PublicAddress},
+		Cc:       []string{actor.Followers},
+	}
+
+	payload, err := BuildUpdateActivityPayload(actor, object, "https://ech0.example", updated)
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	activity := decodeActivity(t, payload)
+	if activity["type"] != model.ActivityTypeUpdate || activity["actor"] != actor.ID {
+		t.Errorf("activity = %v", activity)
+	}
+	inner, _ := activity["object"].(map[string]any)
+	if inner["id"] != object.ObjectID || inner["content"] != "edited" || inner["updated"] != "2026-01-02T03:04:05Z" {
+		t.Errorf("object = %v", inner)
+	}
+	// 内嵌对象不重复携带 @context
+	if _, ok := inner["@context"]; ok {
+		t.Error("embedded object carries @context")
+	}
+
+	if _, err := BuildUpdateActivityPayload(actor, &model.Object{}, "https://ech0.example", updated); err == nil {
+		t.Error("expected object without id to be rejected")
+	}
+}
+
+func TestBuildDeleteActivityPayload(t *testing.T) {
+	actor := testActor()
+	deleted := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	// Delete 的收件人与原 Echo 的可见性一致
+	for visibility, wantTo := range map[string]string{
+		echoModel.VisibilityPublic:    model.PublicAddress,
+		echoModel.VisibilityUnlisted:  actor.Followers,
+		echoModel.VisibilityFollowers: actor.Followers,
+	} {
+		to, cc := addressEcho(&echoModel.Echo{Visibility: visibility}, actor)
+		payload, err := BuildDeleteActivityPayload(actor, "https://ech0.example/objects/1", "https://ech0.example", to, cc, deleted)
+		if err != nil {
+			t.Fatalf("%s: build: %v", visibility, err)
+		}
+		activity := decodeActivity(t, payload)
+		if activity["type"] != model.ActivityTypeDelete {
+			t.Errorf("%s: type = %v", visibility, activity["type"])
+		}
+		gotTo, _ := activity["to"].([]any)
+		if !slices.Contains(gotTo, any(wantTo)) {
+			t.Errorf("%s: to = %v, want %s", visibility, gotTo, wantTo)
+		}
+		inner, _ := activity["object"].(map[string]any)
+		if inner["type"] != model.ObjectTypeTombstone || inner["formerType"] != "Note" || inner["deleted"] != "2026-01-02T03:04:05Z" {
+			t.Errorf("%s: object = %v", visibility, inner)
+		}
+	}
+
+	if _, err := BuildDeleteActivityPayload(actor, "", "https://ech0.example", nil, nil, deleted); err == nil {
+		t.Error("expected empty object id to be rejected")
+	}
+}
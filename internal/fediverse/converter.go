@@ -40,7 +40,7 @@ func (core *FediverseCore) ConvertEchoToActivity(
 		ObjectType: obj.Type,
 		Published:  echo.CreatedAt,
 		To:         obj.To,
-		Cc:         []string{actor.Followers},
+		Cc:         obj.Cc,
 		Summary:    "",
 		Delivered:  false,
 		CreatedAt:  time.Now(),
@@ -66,22 +66,48 @@ func (core *FediverseCore) ConvertEchoToObject(
 		})
 	}
 
+	// 回复与引用指向本地 Echo 对应的 Object
+	var inReplyTo, quoteURL string
+	if echo.ReplyToID != 0 {
+		inReplyTo = fmt.Sprintf("%s/objects/%d", serverURL, echo.ReplyToID)
+	}
+	if echo.QuoteOfID != 0 {
+		quoteURL = fmt.Sprintf("%s/objects/%d", serverURL, echo.QuoteOfID)
+	}
+
+	to, cc := addressEcho(echo, actor)
+
 	return model.Object{
 		Context: []any{
 			"https://www.w3.org/ns/activitystreams",
 		},
-		ObjectID: fmt.Sprintf("%s/objects/%d", serverURL, echo.ID),
-		Type:     "Note",
-		Content:  string(mdUtil.MdToHTML([]byte(echo.Content))),
+		ObjectID:  fmt.Sprintf("%s/objects/%d", serverURL, echo.ID),
+		InReplyTo: inReplyTo,
+		QuoteURL:  quoteURL,
+		Type:      "Note",
+		Content:   string(mdUtil.MdToHTML([]byte(echo.Content))),
 		Source: map[string]any{
 			"mediaType": "text/markdown",
 			"content":   echo.Content,
 		},
 		AttributedTo: actor.ID,
 		Published:    echo.CreatedAt,
-		To: []string{
-			"https://www.w3.org/ns/activitystreams#Public",
-		},
-		Attachments: attachments,
+		To:           to,
+		Cc:           cc,
+		Attachments:  attachments,
+	}
+}
+
+// addressEcho 根据 Echo 的可见性生成收件人
+//
+// 公开：to 为 Public、cc 为关注者；不公开列出：to 为关注者、cc 为 Public；仅关注者：只发给关注者
+func addressEcho(echo *echoModel.Echo, actor *model.Actor) (to, cc []string) {
+	switch echo.Visibility {
+	case echoModel.VisibilityUnlisted:
+		return []string{actor.Followers}, []string{model.PublicAddress}
+	case echoModel.VisibilityFollowers:
+		return []string{actor.Followers}, nil
+	default:
+		return []string{model.PublicAddress}, []string{actor.Followers}
 	}
 }
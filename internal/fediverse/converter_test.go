@@ -0,0 +1,38 @@
+package fediverse
+
+import (
+	"slices"
+	"testing"
+
+	echoModel "github.com/lin-snow/ech0/internal/model/echo"
+	model "github.com/lin-snow/ech0/internal/model/fediverse"
+)
+
+func TestConvertEchoToActivityAddressing(t *testing.T) {
+	actor := testActor()
+	core := &FediverseCore{}
+
+	tests := map[string]struct {
+		visibility string
+		to, cc     []string
+	}{
+		"公开":     {visibility: echoModel.VisibilityPublic, to: []string{model.PublicAddress}, cc: []string{actor.Followers}},
+		"旧数据未设置": {visibility: "", to: []string{model.PublicAddress}, cc: []string{actor.Followers}},
+		"不公开列出":  {visibility: echoModel.VisibilityUnlisted, to: []string{actor.Followers}, cc: []string{model.PublicAddress}},
+		"仅关注者":   {visibility: echoModel.VisibilityFollowers, to: []string{actor.Followers}},
+	}
+
+	for name, tt := range tests {
+		echo := &echoModel.Echo{ID: 1, Content: "hello", Visibility: tt.visibility}
+		activity := core.ConvertEchoToActivity(echo, actor, "https://ech0.example")
+		if !slices.Equal(activity.To, tt.to) || !slices.Equal(activity.Cc, tt.cc) {
+			t.Errorf("%s: activity to %v cc %v", name, activity.To, activity.Cc)
+		}
+
+		// Activity 与 Object 的收件人保持一致
+		object := core.ConvertEchoToObject(echo, actor, "https://ech0.example")
+		if !slices.Equal(object.To, tt.to) || !slices.Equal(object.Cc, tt.cc) {
+			t.Errorf("%s: object to %v cc %v", name, object.To, object.Cc)
+		}
+	}
+}
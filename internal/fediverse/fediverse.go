@@ -1,6 +1,10 @@
 package fediverse
 
 import (
+	"sync"
+	"time"
+
+	"github.com/lin-snow/ech0/internal/cache"
 	echoRepository "github.com/lin-snow/ech0/internal/repository/echo"
 	repository "github.com/lin-snow/ech0/internal/repository/fediverse"
 	keyvalueRepository "github.com/lin-snow/ech0/internal/repository/keyvalue"
@@ -13,6 +17,10 @@ type FediverseCore struct {
 	userRepository userRepository.UserRepositoryInterface
 	echoRepository echoRepository.EchoRepositoryInterface
 	keyvalueRepo   keyvalueRepository.KeyValueRepositoryInterface
+	cache          cache.ICache[string, any]
+
+	signatureMu sync.Mutex           // 保证同一签名只被接受一次
+	signatures  map[string]time.Time // 已接收签名的摘要及其过期时间
 }
 
 func NewFediverseCore(
@@ -20,11 +28,14 @@ func NewFediverseCore(
 	keyvalueRepo keyvalueRepository.KeyValueRepositoryInterface,
 	userRepository userRepository.UserRepositoryInterface,
 	echoRepository echoRepository.EchoRepositoryInterface,
+	cache cache.ICache[string, any],
 ) *FediverseCore {
 	return &FediverseCore{
 		repo:           repo,
 		keyvalueRepo:   keyvalueRepo,
 		userRepository: userRepository,
 		echoRepository: echoRepository,
+		cache:          cache,
+		signatures:     make(map[string]time.Time),
 	}
 }
@@ -3,7 +3,10 @@ package fediverse
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"time"
 
 	httpUtil "github.com/lin-snow/ech0/internal/util/http"
 )
@@ -12,16 +15,58 @@ import (
 //	Fetch
 //==============================================================================
 
+const (
+	remoteRequestTimeout   = 10 * time.Second // 访问远端实例的超时时间
+	maxRemoteDocumentBytes = 1 << 20          // 远端文档的最大字节数
+	activityJSONType       = "application/activity+json"
+)
+
+// remoteClient 访问远端实例使用的 HTTP 客户端。keyId、Actor、Inbox 等地址都由远端提供，
+// 只允许连接公网地址，防止被用来探测内网服务
+var remoteClient = httpUtil.NewPublicClient(remoteRequestTimeout)
+
+// FetchRemoteDocument 拉取远端文档，只接受 2xx 响应，响应体超过大小限制时返回错误
+func FetchRemoteDocument(documentURL, accept string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, documentURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", accept)
+
+	resp, err := remoteClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("fetch %s: unexpected status %d", documentURL, resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxRemoteDocumentBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > maxRemoteDocumentBytes {
+		return nil, fmt.Errorf("fetch %s: document is too large", documentURL)
+	}
+	return body, nil
+}
+
+// PostActivity 签名并投递 Activity 到远端 Inbox
+func PostActivity(payload []byte, inboxURL, actorID string) error {
+	return httpUtil.PostActivity(remoteClient, payload, inboxURL, actorID)
+}
+
 // FetchRemoteActorInbox 获取远程 Actor 的 Inbox URL
 func (core *FediverseCore) FetchRemoteActorInbox(actorURL string) (string, error) {
 	if actorURL == "" {
 		return "", errors.New("remote actor url is empty")
 	}
 
-	body, err := httpUtil.SendRequest(actorURL, http.MethodGet, httpUtil.Header{
-		Header:  "Accept",
-		Content: "application/activity+json",
-	})
+	body, err := FetchRemoteDocument(actorURL, activityJSONType)
 	if err != nil {
 		return "", err
 	}
@@ -45,3 +90,21 @@ func (core *FediverseCore) FetchRemoteActorInbox(actorURL string) (string, error
 
 	return "", errors.New("remote actor inbox not found")
 }
+
+// FetchRemoteObject 获取远程 ActivityPub 对象，仅允许与 actorURL 同源的对象
+func (core *FediverseCore) FetchRemoteObject(objectURL, actorURL string) (map[string]any, error) {
+	if !SameOrigin(objectURL, actorURL) {
+		return nil, errors.New("remote object is not on the actor origin")
+	}
+
+	body, err := FetchRemoteDocument(objectURL, activityJSONType)
+	if err != nil {
+		return nil, err
+	}
+
+	var object map[string]any
+	if err := json.Unmarshal(body, &object); err != nil {
+		return nil, err
+	}
+	return object, nil
+}
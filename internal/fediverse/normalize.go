@@ -6,14 +6,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
-	"time"
 
 	commonModel "github.com/lin-snow/ech0/internal/model/common"
 	model "github.com/lin-snow/ech0/internal/model/fediverse"
-	httpUtil "github.com/lin-snow/ech0/internal/util/http"
 )
 
 //==============================================================================
@@ -32,6 +30,20 @@ func NormalizeServerURL(raw string) (string, error) {
 	return strings.TrimRight(trimmed, "/"), nil
 }
 
+// ResolveLocalEchoID 从本地 Object URL 中解析 Echo ID，格式为 serverURL/objects/{id}
+func ResolveLocalEchoID(objectID, serverURL string) (uint, bool) {
+	prefix := strings.TrimRight(serverURL, "/") + "/objects/"
+	if serverURL == "" || !strings.HasPrefix(objectID, prefix) {
+		return 0, false
+	}
+
+	id, err := strconv.ParseUint(strings.TrimPrefix(objectID, prefix), 10, 64)
+	if err != nil || id == 0 {
+		return 0, false
+	}
+	return uint(id), true
+}
+
 // NormalizePageParams 标准化分页参数
 func NormalizePageParams(page, pageSize int) (int, int) {
 	if page < 1 {
@@ -82,10 +94,7 @@ func ResolveActorURL(input string) (string, error) {
 		domain,
 		url.QueryEscape("acct:"+username+"@"+domain),
 	)
-	body, err := httpUtil.SendRequest(webfingerURL, http.MethodGet, httpUtil.Header{
-		Header:  "Accept",
-		Content: "application/jrd+json, application/json",
-	}, 5*time.Second)
+	body, err := FetchRemoteDocument(webfingerURL, "application/jrd+json, application/json")
 	if err != nil {
 		return "", fmt.Errorf("%s: %w", commonModel.GET_ACTOR_ERROR, err)
 	}
@@ -1,93 +1,184 @@
 package fediverse
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
 	commonModel "github.com/lin-snow/ech0/internal/model/common"
 	echoModel "github.com/lin-snow/ech0/internal/model/echo"
+	model "github.com/lin-snow/ech0/internal/model/fediverse"
 	settingModel "github.com/lin-snow/ech0/internal/model/setting"
-	httpUtil "github.com/lin-snow/ech0/internal/util/http"
 	jsonUtil "github.com/lin-snow/ech0/internal/util/json"
 )
 
 // PushEchoToFediverse 将 Echo 推送到联邦网络
 func (core *FediverseCore) PushEchoToFediverse(userId uint, echo echoModel.Echo) error {
-	// 检查是否开启了联邦网络功能
-	var fediverseSetting settingModel.FediverseSetting
-	if fediverseSettingJSON, err := core.keyvalueRepo.GetKeyValue(commonModel.FediverseSettingKey); err == nil {
-		if err := jsonUtil.JSONUnmarshal([]byte(fediverseSettingJSON.(string)), &fediverseSetting); err != nil {
-			return err
-		}
-	} else {
-		return err
+	if !echo.IsFederated() {
+		return nil
 	}
 
-	if !fediverseSetting.Enable {
-		return nil
+	actor, serverURL, followers, err := core.prepareDelivery(userId)
+	if err != nil || actor == nil {
+		return err
 	}
 
-	if echo.Private {
-		return nil
+	activity := core.ConvertEchoToActivity(&echo, actor, serverURL)
+	object := core.ConvertEchoToObject(&echo, actor, serverURL)
+
+	activityMap := map[string]any{}
+	activityBytes, err := json.Marshal(activity)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(activityBytes, &activityMap); err != nil {
+		return err
 	}
 
-	// 获取用户
-	user, err := core.userRepository.GetUserByID(int(userId))
+	objectMap := map[string]any{}
+	objectBytes, err := json.Marshal(object)
 	if err != nil {
 		return err
 	}
+	if err := json.Unmarshal(objectBytes, &objectMap); err != nil {
+		return err
+	}
 
-	// 获取粉丝列表
-	followers, err := core.repo.GetFollowers(user.ID)
+	activityMap["object"] = objectMap
+
+	payloadBytes, err := json.Marshal(activityMap)
 	if err != nil {
 		return err
 	}
-	if len(followers) == 0 {
+
+	return core.deliverToFollowers(actor, followers, payloadBytes)
+}
+
+// PushEchoUpdateToFediverse 将 Echo 的修改以 Update{Note} 推送到联邦网络，previous 为修改前的 Echo
+// 可见性由推送转为不推送时推送 Delete，由不推送转为推送时推送 Create，两者都不推送时忽略
+// 无论由谁修改，都以 Echo 作者的身份签名并投递给作者的粉丝
+func (core *FediverseCore) PushEchoUpdateToFediverse(previous, echo echoModel.Echo) error {
+	switch {
+	case !previous.IsFederated() && !echo.IsFederated():
 		return nil
+	case !echo.IsFederated():
+		return core.PushEchoDeleteToFediverse(echo)
+	case !previous.IsFederated():
+		return core.PushEchoToFediverse(echo.UserID, echo)
 	}
 
-	// 获取 Actor 和 setting
-	actor, setting, err := core.BuildActor(&user)
-	if err != nil {
+	actor, serverURL, followers, err := core.prepareDelivery(echo.UserID)
+	if err != nil || actor == nil {
 		return err
 	}
 
-	serverURL, err := NormalizeServerURL(setting.ServerURL)
+	object := core.ConvertEchoToObject(&echo, actor, serverURL)
+	payloadBytes, err := BuildUpdateActivityPayload(actor, &object, serverURL, time.Now().UTC())
 	if err != nil {
 		return err
 	}
 
-	activity := core.ConvertEchoToActivity(&echo, &actor, serverURL)
-	object := core.ConvertEchoToObject(&echo, &actor, serverURL)
+	return core.deliverToFollowers(actor, followers, payloadBytes)
+}
 
-	activityMap := map[string]any{}
-	activityBytes, err := json.Marshal(activity)
-	if err != nil {
+// PushEchoDeleteToFediverse 将 Echo 的删除以 Delete{Tombstone} 推送到联邦网络
+// 无论由谁删除，都以 Echo 作者的身份签名并投递给作者的粉丝
+func (core *FediverseCore) PushEchoDeleteToFediverse(echo echoModel.Echo) error {
+	actor, serverURL, followers, err := core.prepareDelivery(echo.UserID)
+	if err != nil || actor == nil {
 		return err
 	}
-	if err := json.Unmarshal(activityBytes, &activityMap); err != nil {
+
+	objectID := fmt.Sprintf("%s/objects/%d", serverURL, echo.ID)
+	to, cc := addressEcho(&echo, actor)
+	payloadBytes, err := BuildDeleteActivityPayload(actor, objectID, serverURL, to, cc, time.Now().UTC())
+	if err != nil {
 		return err
 	}
 
-	objectMap := map[string]any{}
-	objectBytes, err := json.Marshal(object)
+	return core.deliverToFollowers(actor, followers, payloadBytes)
+}
+
+// RecordTombstone 记录已删除的 Echo，使 GetObject 能够返回 Tombstone
+func (core *FediverseCore) RecordTombstone(echoID uint) error {
+	return core.repo.SaveTombstone(context.Background(), &model.Tombstone{
+		Type:       model.ObjectTypeTombstone,
+		FormerType: "Note",
+		EchoID:     echoID,
+		Deleted:    time.Now().UTC(),
+	})
+}
+
+// RemoveTombstone 删除 Echo 的 Tombstone，Echo 从回收站恢复后 GetObject 重新返回对象
+func (core *FediverseCore) RemoveTombstone(echoID uint) error {
+	return core.repo.DeleteTombstone(context.Background(), echoID)
+}
+
+// IsEchoDeleted 判断 Echo 当前是否已删除（移入回收站或彻底删除）
+func (core *FediverseCore) IsEchoDeleted(echoID uint) (bool, error) {
+	echo, err := core.echoRepository.GetEchosById(echoID)
 	if err != nil {
-		return err
+		return false, err
 	}
-	if err := json.Unmarshal(objectBytes, &objectMap); err != nil {
-		return err
+	return echo == nil, nil
+}
+
+// prepareDelivery 检查联邦开关并加载推送所需的 Actor、服务器地址和粉丝列表，无需推送时返回 nil Actor
+func (core *FediverseCore) prepareDelivery(
+	userId uint,
+) (*model.Actor, string, []model.Follower, error) {
+	// 检查是否开启了联邦网络功能
+	var fediverseSetting settingModel.FediverseSetting
+	if fediverseSettingJSON, err := core.keyvalueRepo.GetKeyValue(commonModel.FediverseSettingKey); err == nil {
+		if err := jsonUtil.JSONUnmarshal([]byte(fediverseSettingJSON.(string)), &fediverseSetting); err != nil {
+			return nil, "", nil, err
+		}
+	} else {
+		return nil, "", nil, err
 	}
 
-	activityMap["object"] = objectMap
+	if !fediverseSetting.Enable {
+		return nil, "", nil, nil
+	}
 
-	payloadBytes, err := json.Marshal(activityMap)
+	// 获取用户
+	user, err := core.userRepository.GetUserByID(int(userId))
 	if err != nil {
-		return err
+		return nil, "", nil, err
+	}
+
+	// 获取粉丝列表
+	followers, err := core.repo.GetFollowers(user.ID)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	if len(followers) == 0 {
+		return nil, "", nil, nil
 	}
 
+	// 获取 Actor 和 setting
+	actor, setting, err := core.BuildActor(&user)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	serverURL, err := NormalizeServerURL(setting.ServerURL)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	return &actor, serverURL, followers, nil
+}
+
+// deliverToFollowers 将 Activity 投递到每个粉丝的 Inbox
+func (core *FediverseCore) deliverToFollowers(
+	actor *model.Actor,
+	followers []model.Follower,
+	payload []byte,
+) error {
 	var errs []error
-	// 推送到每个粉丝的Inbox
 	for _, follower := range followers {
 		inboxURL, err := core.FetchRemoteActorInbox(follower.ActorID)
 		if err != nil {
@@ -95,7 +186,7 @@ func (core *FediverseCore) PushEchoToFediverse(userId uint, echo echoModel.Echo)
 			continue
 		}
 
-		if err := httpUtil.PostActivity(payloadBytes, inboxURL, actor.ID); err != nil {
+		if err := PostActivity(payload, inboxURL, actor.ID); err != nil {
 			errs = append(errs, fmt.Errorf("post activity to %s: %w", inboxURL, err))
 		}
 	}
@@ -0,0 +1,256 @@
+package fediverse
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/lin-snow/ech0/internal/config"
+	"github.com/lin-snow/ech0/internal/database"
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	echoModel "github.com/lin-snow/ech0/internal/model/echo"
+	model "github.com/lin-snow/ech0/internal/model/fediverse"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
+	echoRepository "github.com/lin-snow/ech0/internal/repository/echo"
+	repository "github.com/lin-snow/ech0/internal/repository/fediverse"
+	keyvalueRepository "github.com/lin-snow/ech0/internal/repository/keyvalue"
+	userRepository "github.com/lin-snow/ech0/internal/repository/user"
+)
+
+// pushFixture 开启联邦功能、有一个远端粉丝的推送测试环境
+type pushFixture struct {
+	db     *gorm.DB
+	core   *FediverseCore
+	remote *remoteInstance
+	user   userModel.User
+}
+
+func newPushFixture(t *testing.T) *pushFixture {
+	t.Helper()
+	config.RSA_PRIVATE = generateKey(t)
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "ech0.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.AutoMigrate(database.Models()...); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	dbProvider := func() *gorm.DB { return db }
+	cache := newMemoryCache()
+
+	settings := []commonModel.KeyValue{
+		{Key: commonModel.SystemSettingsKey, Value: `{"server_url":"https://ech0.example"}`},
+		{Key: commonModel.FediverseSettingKey, Value: `{"enable":true}`},
+	}
+	if err := db.Create(&settings).Error; err != nil {
+		t.Fatalf("save settings: %v", err)
+	}
+
+	f := &pushFixture{db: db, remote: newRemoteInstance(t)}
+	f.user = userModel.User{Username: "alice", Password: "x"}
+	if err := db.Create(&f.user).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	// 远端粉丝的 Actor 文档声明了 Inbox 地址
+	f.remote.docs["/users/bob"] = map[string]any{
+		"id":    f.remote.url("/users/bob"),
+		"type":  "Person",
+		"inbox": f.remote.url("/users/bob/inbox"),
+	}
+	if err := db.Create(&model.Follower{ActorID: f.remote.url("/users/bob"), UserID: f.user.ID}).Error; err != nil {
+		t.Fatalf("create follower: %v", err)
+	}
+
+	f.core = NewFediverseCore(
+		repository.NewFediverseRepository(dbProvider),
+		keyvalueRepository.NewKeyValueRepository(dbProvider, cache),
+		userRepository.NewUserRepository(dbProvider, cache),
+		echoRepository.NewEchoRepository(dbProvider, cache),
+		cache,
+	)
+	return f
+}
+
+func (f *pushFixture) delivered() []string {
+	f.remote.mu.Lock()
+	defer f.remote.mu.Unlock()
+	delivered := slices.Clone(f.remote.delivered)
+	f.remote.delivered = nil
+	return delivered
+}
+
+func TestPushEchoUpdateToFediverse(t *testing.T) {
+	f := newPushFixture(t)
+
+	echo := func(visibility string) echoModel.Echo {
+		return echoModel.Echo{ID: 1, UserID: f.user.ID, Content: "hello", Visibility: visibility}
+	}
+
+	// 按更新前后的可见性决定推送的 Activity
+	tests := map[string]struct {
+		previous, current string
+		want              []string
+	}{
+		"public edited":       {echoModel.VisibilityPublic, echoModel.VisibilityPublic, []string{model.ActivityTypeUpdate}},
+		"public to unlisted":  {echoModel.VisibilityPublic, echoModel.VisibilityUnlisted, []string{model.ActivityTypeUpdate}},
+		"public to private":   {echoModel.VisibilityPublic, echoModel.VisibilityPrivate, []string{model.ActivityTypeDelete}},
+		"private to public":   {echoModel.VisibilityPrivate, echoModel.VisibilityPublic, []string{model.ActivityTypeCreate}},
+		"private edited":      {echoModel.VisibilityPrivate, echoModel.VisibilityPrivate, nil},
+		"members to private":  {echoModel.VisibilityMembers, echoModel.VisibilityPrivate, nil},
+		"followers to public": {echoModel.VisibilityFollowers, echoModel.VisibilityPublic, []string{model.ActivityTypeUpdate}},
+	}
+
+	for name, tt := range tests {
+		if err := f.core.PushEchoUpdateToFediverse(echo(tt.previous), echo(tt.current)); err != nil {
+			t.Errorf("%s: push: %v", name, err)
+			continue
+		}
+		if got := f.delivered(); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: delivered %v, want %v", name, got, tt.want)
+		}
+	}
+}
+
+func TestPushEchoToFediverseSkipsPrivateEcho(t *testing.T) {
+	f := newPushFixture(t)
+
+	private := echoModel.Echo{ID: 1, UserID: f.user.ID, Content: "hello", Visibility: echoModel.VisibilityPrivate}
+	if err := f.core.PushEchoToFediverse(f.user.ID, private); err != nil {
+		t.Fatalf("push: %v", err)
+	}
+	if got := f.delivered(); len(got) != 0 {
+		t.Errorf("delivered %v for a private echo", got)
+	}
+
+	public := echoModel.Echo{ID: 2, UserID: f.user.ID, Content: "hello", Visibility: echoModel.VisibilityPublic}
+	if err := f.core.PushEchoToFediverse(f.user.ID, public); err != nil {
+		t.Fatalf("push: %v", err)
+	}
+	if got := f.delivered(); !slices.Equal(got, []string{model.ActivityTypeCreate}) {
+		t.Errorf("delivered %v", got)
+	}
+}
+
+func TestTombstoneFollowsTrashState(t *testing.T) {
+	f := newPushFixture(t)
+	ctx := context.Background()
+	echo := echoModel.Echo{UserID: f.user.ID, Content: "hello", Status: echoModel.EchoStatusPublished}
+	if err := f.db.Create(&echo).Error; err != nil {
+		t.Fatalf("create echo: %v", err)
+	}
+
+	check := func(step string, wantDeleted, wantTombstone bool) {
+		t.Helper()
+		deleted, err := f.core.IsEchoDeleted(echo.ID)
+		if err != nil {
+			t.Fatalf("%s: is deleted: %v", step, err)
+		}
+		tombstone, err := f.core.repo.GetTombstoneByEchoID(echo.ID)
+		if err != nil {
+			t.Fatalf("%s: tombstone: %v", step, err)
+		}
+		if deleted != wantDeleted || (tombstone != nil) != wantTombstone {
+			t.Errorf("%s: deleted = %v, tombstone = %v", step, deleted, tombstone != nil)
+		}
+	}
+	check("发布后", false, false)
+
+	// 移入回收站时记录 Tombstone
+	if err := f.core.echoRepository.DeleteEchoById(ctx, echo.ID); err != nil {
+		t.Fatalf("trash: %v", err)
+	}
+	if err := f.core.RecordTombstone(echo.ID); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	check("移入回收站后", true, true)
+
+	// 恢复后删除 Tombstone，重复删除不报错
+	if err := f.core.echoRepository.RestoreEchoById(ctx, echo.ID); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	for range 2 {
+		if err := f.core.RemoveTombstone(echo.ID); err != nil {
+			t.Fatalf("remove: %v", err)
+		}
+	}
+	check("恢复后", false, false)
+}
+
+func TestBuildFeatured(t *testing.T) {
+	f := newPushFixture(t)
+	other := userModel.User{Username: "carol", Password: "x"}
+	if err := f.db.Create(&other).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	pinnedAt := time.Now()
+	pin := func(author userModel.User, visibility string, pinned bool) uint {
+		t.Helper()
+		echo := echoModel.Echo{
+			UserID:     author.ID,
+			Content:    visibility,
+			Visibility: visibility,
+			Status:     echoModel.EchoStatusPublished,
+		}
+		if pinned {
+			// 越晚创建的置顶时间越早
+			pinnedAt = pinnedAt.Add(-time.Minute)
+			at := pinnedAt
+			echo.PinnedAt = &at
+		}
+		if err := f.db.Create(&echo).Error; err != nil {
+			t.Fatalf("create echo: %v", err)
+		}
+		return echo.ID
+	}
+	public := pin(f.user, echoModel.VisibilityPublic, true)
+	unlisted := pin(f.user, echoModel.VisibilityUnlisted, true)
+	pin(f.user, echoModel.VisibilityFollowers, true)
+	pin(f.user, echoModel.VisibilityPrivate, true)
+	pin(f.user, echoModel.VisibilityPublic, false)
+	pin(other, echoModel.VisibilityPublic, true)
+
+	// 精选集合只包含该用户置顶的、无需登录即可访问的 Echo
+	featured, err := f.core.BuildFeatured(f.user.Username)
+	if err != nil {
+		t.Fatalf("featured: %v", err)
+	}
+	var got []string
+	for _, object := range featured.OrderedItems {
+		got = append(got, object.ObjectID)
+	}
+	want := []string{
+		fmt.Sprintf("https://ech0.example/objects/%d", public),
+		fmt.Sprintf("https://ech0.example/objects/%d", unlisted),
+	}
+	if !slices.Equal(got, want) || featured.TotalItems != len(want) {
+		t.Errorf("featured = %v (total %d), want %v", got, featured.TotalItems, want)
+	}
+	if featured.ID != "https://ech0.example/users/alice/collections/featured" {
+		t.Errorf("featured id = %q", featured.ID)
+	}
+
+	// Actor 声明精选集合的地址
+	actor, _, err := f.core.BuildActor(&f.user)
+	if err != nil {
+		t.Fatalf("actor: %v", err)
+	}
+	if actor.Featured != featured.ID {
+		t.Errorf("actor featured = %q", actor.Featured)
+	}
+
+	if _, err := f.core.BuildFeatured("nobody"); err == nil || err.Error() != commonModel.USER_NOTFOUND {
+		t.Errorf("unknown user err = %v", err)
+	}
+}
@@ -0,0 +1,230 @@
+package fediverse
+
+import (
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	model "github.com/lin-snow/ech0/internal/model/fediverse"
+	httpUtil "github.com/lin-snow/ech0/internal/util/http"
+)
+
+//==============================================================================
+//	Signature
+//==============================================================================
+
+// VerifyInboxRequest 校验投递到 Inbox 的请求签名，返回签名公钥的所有者 Actor URL
+func (core *FediverseCore) VerifyInboxRequest(req *http.Request, body []byte) (string, error) {
+	params, err := httpUtil.ParseSignatureHeader(req.Header.Get("Signature"))
+	if err != nil {
+		return "", err
+	}
+
+	// 必须对请求目标、Host、Date 和 Digest 签名，否则无法防止转投、重放和篡改请求体
+	for _, header := range model.RequiredSignedHeaders {
+		if !params.HasSignedHeader(header) {
+			return "", fmt.Errorf("signature must cover %s header", header)
+		}
+	}
+	if err := httpUtil.VerifyDate(req, model.SignatureDateTolerance); err != nil {
+		return "", err
+	}
+	if err := httpUtil.VerifyDigest(req, body); err != nil {
+		return "", err
+	}
+
+	// 同一签名在 Date 有效期内只接受一次，先占用再校验，避免并发的重放请求同时通过
+	digest, err := core.claimSignature(params.Signature)
+	if err != nil {
+		return "", err
+	}
+	owner, err := core.verifySignature(req, params)
+	if err != nil {
+		// 校验失败的签名不算已接收
+		core.releaseSignature(digest)
+		return "", err
+	}
+
+	return owner, nil
+}
+
+// verifySignature 使用 keyId 对应的远端公钥校验签名，返回公钥所有者
+func (core *FediverseCore) verifySignature(req *http.Request, params httpUtil.SignatureParams) (string, error) {
+	key, err := core.fetchPublicKey(params.KeyID, false)
+	if err != nil {
+		return "", err
+	}
+	if err := httpUtil.VerifySignature(req, params, key.rsaKey); err != nil {
+		// 校验失败可能是远端轮换了密钥，强制刷新后重试一次
+		refreshed, fetchErr := core.fetchPublicKey(params.KeyID, true)
+		if fetchErr != nil {
+			return "", fetchErr
+		}
+		if refreshed.pem == key.pem {
+			return "", errors.New("signature verification failed")
+		}
+		if err := httpUtil.VerifySignature(req, params, refreshed.rsaKey); err != nil {
+			return "", err
+		}
+		key = refreshed
+	}
+
+	return key.owner, nil
+}
+
+// claimSignature 记录签名，返回签名摘要；签名已被记录时返回错误。检查与记录在同一把锁内完成
+func (core *FediverseCore) claimSignature(signature []byte) (string, error) {
+	sum := sha256.Sum256(signature)
+	digest := hex.EncodeToString(sum[:])
+	now := time.Now()
+
+	core.signatureMu.Lock()
+	defer core.signatureMu.Unlock()
+
+	if expires, ok := core.signatures[digest]; ok && now.Before(expires) {
+		return "", errors.New("signature has already been used")
+	}
+	for d, expires := range core.signatures {
+		if !now.Before(expires) {
+			delete(core.signatures, d)
+		}
+	}
+	// Date 允许向前和向后偏移，记录的时间需覆盖整个窗口
+	core.signatures[digest] = now.Add(2 * model.SignatureDateTolerance)
+	return digest, nil
+}
+
+// releaseSignature 移除 claimSignature 记录的签名
+func (core *FediverseCore) releaseSignature(digest string) {
+	core.signatureMu.Lock()
+	defer core.signatureMu.Unlock()
+	delete(core.signatures, digest)
+}
+
+// remotePublicKey 缓存的远端公钥
+type remotePublicKey struct {
+	owner  string
+	pem    string
+	rsaKey *rsa.PublicKey
+}
+
+// fetchPublicKey 获取远端公钥，优先读取缓存，refresh 为 true 时强制重新拉取
+func (core *FediverseCore) fetchPublicKey(keyID string, refresh bool) (remotePublicKey, error) {
+	cacheKey := model.PublicKeyCachePrefix + keyID
+	if !refresh {
+		if cached, err := core.cache.Get(cacheKey); err == nil {
+			if key, ok := cached.(remotePublicKey); ok {
+				return key, nil
+			}
+		}
+	}
+
+	key, err := core.fetchRemotePublicKey(keyID)
+	if err != nil {
+		return remotePublicKey{}, err
+	}
+
+	core.cache.SetWithTTL(cacheKey, key, 1, model.PublicKeyCacheTTL)
+	return key, nil
+}
+
+// keyDocument keyId 指向的文档，可能是 Key 文档，也可能是包含 publicKey 的 Actor 文档
+type keyDocument struct {
+	ID           string          `json:"id"`
+	Owner        string          `json:"owner"`
+	PublicKeyPem string          `json:"publicKeyPem"`
+	PublicKey    model.PublicKey `json:"publicKey"`
+}
+
+// fetchRemotePublicKey 通过 keyId 拉取远端公钥
+//
+// 公钥文档中声明的 owner 不可信，必须再拉取 owner 的 Actor 文档，
+// 确认该 Actor 的 publicKey.id 就是 keyId 且两者同源，才把 owner 作为签名者。
+func (core *FediverseCore) fetchRemotePublicKey(keyID string) (remotePublicKey, error) {
+	if keyID == "" {
+		return remotePublicKey{}, errors.New("key id is empty")
+	}
+
+	// keyId 通常为 Actor URL + "#main-key"，去掉片段后请求 Actor 文档
+	keyURL, _, _ := strings.Cut(keyID, "#")
+	doc, err := fetchKeyDocument(keyURL)
+	if err != nil {
+		return remotePublicKey{}, err
+	}
+
+	owner := doc.PublicKey.Owner
+	if doc.PublicKeyPem != "" {
+		owner = doc.Owner
+	}
+	if owner == "" {
+		owner = doc.ID
+	}
+	if owner == "" {
+		return remotePublicKey{}, fmt.Errorf("public key owner not found for %s", keyID)
+	}
+	if !SameOrigin(owner, keyID) {
+		return remotePublicKey{}, fmt.Errorf("public key owner %s is not on the key origin", owner)
+	}
+
+	// keyId 指向的就是 owner 的 Actor 文档时无需重复请求
+	actor := doc
+	if ownerURL, _, _ := strings.Cut(owner, "#"); ownerURL != keyURL {
+		if actor, err = fetchKeyDocument(ownerURL); err != nil {
+			return remotePublicKey{}, err
+		}
+	}
+	if actor.ID != owner {
+		return remotePublicKey{}, fmt.Errorf("actor id mismatch: %s", actor.ID)
+	}
+	if actor.PublicKey.ID != keyID {
+		return remotePublicKey{}, fmt.Errorf("actor %s does not own key %s", owner, keyID)
+	}
+	if actor.PublicKey.PublicKeyPem == "" {
+		return remotePublicKey{}, fmt.Errorf("public key not found for %s", keyID)
+	}
+
+	rsaKey, err := httpUtil.ParseRSAPublicKeyPEM(actor.PublicKey.PublicKeyPem)
+	if err != nil {
+		return remotePublicKey{}, err
+	}
+
+	return remotePublicKey{
+		owner:  owner,
+		pem:    actor.PublicKey.PublicKeyPem,
+		rsaKey: rsaKey,
+	}, nil
+}
+
+// fetchKeyDocument 以 ActivityPub 格式拉取远端文档
+func fetchKeyDocument(documentURL string) (keyDocument, error) {
+	body, err := FetchRemoteDocument(documentURL, activityJSONType)
+	if err != nil {
+		return keyDocument{}, err
+	}
+
+	var doc keyDocument
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return keyDocument{}, err
+	}
+	return doc, nil
+}
+
+// SameOrigin 判断两个 URL 的协议与主机是否相同
+func SameOrigin(a, b string) bool {
+	ua, err := url.Parse(a)
+	if err != nil || ua.Host == "" {
+		return false
+	}
+	ub, err := url.Parse(b)
+	if err != nil || ub.Host == "" {
+		return false
+	}
+	return strings.EqualFold(ua.Scheme, ub.Scheme) && strings.EqualFold(ua.Host, ub.Host)
+}
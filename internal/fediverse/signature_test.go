@@ -0,0 +1,419 @@
+package fediverse
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	model "github.com/lin-snow/ech0/internal/model/fediverse"
+	httpUtil "github.com/lin-snow/ech0/internal/util/http"
+)
+
+// memoryCache 同步写入的内存缓存，避免 Ristretto 异步写入导致测试不稳定
+type memoryCache struct {
+	mu    sync.Mutex
+	items map[string]any
+}
+
+func newMemoryCache() *memoryCache {
+	return &memoryCache{items: make(map[string]any)}
+}
+
+func (c *memoryCache) Set(key string, value any, _ int64) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.items[key] = value
+	return true
+}
+
+func (c *memoryCache) SetWithTTL(key string, value any, cost int64, _ time.Duration) bool {
+	return c.Set(key, value, cost)
+}
+
+func (c *memoryCache) Get(key string) (any, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	value, ok := c.items[key]
+	if !ok {
+		return nil, errors.New("key not found")
+	}
+	return value, nil
+}
+
+func (c *memoryCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.items, key)
+}
+
+func (c *memoryCache) GetOrSet(key string, cost int64, fn func() (any, error)) (any, error) {
+	if value, err := c.Get(key); err == nil {
+		return value, nil
+	}
+	value, err := fn()
+	if err != nil {
+		return nil, err
+	}
+	c.Set(key, value, cost)
+	return value, nil
+}
+
+// remoteInstance 模拟远端实例，按路径返回 Actor 或 Key 文档，统计请求次数并记录投递的 Activity 类型
+type remoteInstance struct {
+	server    *httptest.Server
+	mu        sync.Mutex
+	docs      map[string]any
+	delivered []string
+	fetches   atomic.Int32
+}
+
+func newRemoteInstance(t *testing.T) *remoteInstance {
+	t.Helper()
+	remote := &remoteInstance{docs: make(map[string]any)}
+	remote.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			var activity struct {
+				Type string `json:"type"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&activity)
+			remote.mu.Lock()
+			remote.delivered = append(remote.delivered, activity.Type)
+			remote.mu.Unlock()
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		remote.fetches.Add(1)
+		remote.mu.Lock()
+		doc, ok := remote.docs[r.URL.Path]
+		remote.mu.Unlock()
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/activity+json")
+		_ = json.NewEncoder(w).Encode(doc)
+	}))
+	t.Cleanup(remote.server.Close)
+
+	// 测试服务监听回环地址，临时换用不限制地址的客户端
+	client := remoteClient
+	remoteClient = remote.server.Client()
+	t.Cleanup(func() { remoteClient = client })
+	return remote
+}
+
+func (remote *remoteInstance) url(path string) string {
+	return remote.server.URL + path
+}
+
+// serveActor 发布 Actor 文档，publicKey.id 为 keyID
+func (remote *remoteInstance) serveActor(path, keyID, owner string, key *rsa.PrivateKey) {
+	remote.mu.Lock()
+	defer remote.mu.Unlock()
+	remote.docs[path] = map[string]any{
+		"id":   remote.url(path),
+		"type": "Person",
+		"publicKey": map[string]any{
+			"id":           keyID,
+			"owner":        owner,
+			"publicKeyPem": publicKeyPEM(key),
+		},
+	}
+}
+
+func publicKeyPEM(key *rsa.PrivateKey) string {
+	der, _ := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+func generateKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	return key
+}
+
+// inboxRequest 构造投递到本地 Inbox 的请求，使用 SignRequest 签名
+func inboxRequest(t *testing.T, key *rsa.PrivateKey, keyID, body string) *http.Request {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "https://ech0.example/users/alice/inbox", strings.NewReader(body))
+	if err := httpUtil.SignRequest(req, key, keyID, []byte(body)); err != nil {
+		t.Fatalf("sign request: %v", err)
+	}
+	return req
+}
+
+// signWithHeaders 只对指定的头部签名，用于构造签名范围不足的请求
+func signWithHeaders(t *testing.T, req *http.Request, key *rsa.PrivateKey, keyID string, headers []string) {
+	t.Helper()
+	signingString, err := httpUtil.BuildSigningString(req, headers)
+	if err != nil {
+		t.Fatalf("build signing string: %v", err)
+	}
+	hashed := sha256.Sum256([]byte(signingString))
+	sig, err := rsa.SignPKCS1v15(nil, key, crypto.SHA256, hashed[:])
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	req.Header.Set("Signature", fmt.Sprintf(
+		`keyId="%s",algorithm="rsa-sha256",headers="%s",signature="%s"`,
+		keyID,
+		strings.Join(headers, " "),
+		base64.StdEncoding.EncodeToString(sig),
+	))
+}
+
+func TestVerifyInboxRequest(t *testing.T) {
+	remote := newRemoteInstance(t)
+	key := generateKey(t)
+	actorURL := remote.url("/users/bob")
+	keyID := actorURL + "#main-key"
+	remote.serveActor("/users/bob", keyID, actorURL, key)
+
+	core := NewFediverseCore(nil, nil, nil, nil, newMemoryCache())
+
+	body := `{"type":"Like"}`
+	req := inboxRequest(t, key, keyID, body)
+	owner, err := core.VerifyInboxRequest(req, []byte(body))
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if owner != actorURL {
+		t.Errorf("owner = %q, want %q", owner, actorURL)
+	}
+
+	// 同一签名只能使用一次
+	if _, err := core.VerifyInboxRequest(req, []byte(body)); err == nil {
+		t.Error("expected replayed signature to be rejected")
+	}
+
+	// 公钥已缓存，不再请求远端
+	other := `{"type":"Announce"}`
+	if _, err := core.VerifyInboxRequest(inboxRequest(t, key, keyID, other), []byte(other)); err != nil {
+		t.Fatalf("verify second request: %v", err)
+	}
+	if n := remote.fetches.Load(); n != 1 {
+		t.Errorf("remote fetched %d times, want 1", n)
+	}
+}
+
+func TestVerifyInboxRequestAcceptsSignatureOnce(t *testing.T) {
+	remote := newRemoteInstance(t)
+	key := generateKey(t)
+	actorURL := remote.url("/users/bob")
+	keyID := actorURL + "#main-key"
+	core := NewFediverseCore(nil, nil, nil, nil, newMemoryCache())
+
+	body := `{"type":"Like"}`
+	req := inboxRequest(t, key, keyID, body)
+
+	// 公钥暂时无法获取，校验失败的签名不算已接收
+	if _, err := core.VerifyInboxRequest(req, []byte(body)); err == nil {
+		t.Fatal("expected verification to fail before the actor is published")
+	}
+	remote.serveActor("/users/bob", keyID, actorURL, key)
+
+	// 并发重放同一请求只有一个能通过
+	var (
+		wg       sync.WaitGroup
+		accepted atomic.Int32
+	)
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := core.VerifyInboxRequest(req, []byte(body)); err == nil {
+				accepted.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := accepted.Load(); n != 1 {
+		t.Errorf("signature accepted %d times, want 1", n)
+	}
+}
+
+func TestFetchRemoteDocumentRejectsPrivateAddress(t *testing.T) {
+	var fetched atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fetched.Store(true)
+	}))
+	defer server.Close()
+
+	// 默认客户端不能访问回环地址
+	_, err := FetchRemoteDocument(server.URL+"/users/bob", activityJSONType)
+	if !errors.Is(err, httpUtil.ErrNonPublicAddress) {
+		t.Errorf("err = %v, want %v", err, httpUtil.ErrNonPublicAddress)
+	}
+	if fetched.Load() {
+		t.Error("request reached the loopback server")
+	}
+}
+
+func TestFetchRemoteDocumentLimitsSize(t *testing.T) {
+	remote := newRemoteInstance(t)
+	remote.mu.Lock()
+	remote.docs["/large"] = strings.Repeat("x", maxRemoteDocumentBytes)
+	remote.mu.Unlock()
+
+	if _, err := FetchRemoteDocument(remote.url("/large"), activityJSONType); err == nil {
+		t.Error("expected oversized document to be rejected")
+	}
+	if _, err := FetchRemoteDocument(remote.url("/missing"), activityJSONType); err == nil {
+		t.Error("expected non-2xx response to be rejected")
+	}
+}
+
+func TestVerifyInboxRequestRejectsInvalidRequests(t *testing.T) {
+	remote := newRemoteInstance(t)
+	key := generateKey(t)
+	actorURL := remote.url("/users/bob")
+	keyID := actorURL + "#main-key"
+	remote.serveActor("/users/bob", keyID, actorURL, key)
+
+	tests := map[string]func() (*http.Request, []byte){
+		"unsigned": func() (*http.Request, []byte) {
+			return httptest.NewRequest(http.MethodPost, "/users/alice/inbox", nil), nil
+		},
+		"body replaced": func() (*http.Request, []byte) {
+			return inboxRequest(t, key, keyID, `{"type":"Like"}`), []byte(`{"type":"Delete"}`)
+		},
+		"digest not signed": func() (*http.Request, []byte) {
+			body := []byte(`{"type":"Like"}`)
+			req := inboxRequest(t, key, keyID, string(body))
+			signWithHeaders(t, req, key, keyID, []string{"(request-target)", "host", "date"})
+			return req, body
+		},
+		"stale date": func() (*http.Request, []byte) {
+			body := []byte(`{"type":"Like"}`)
+			req := inboxRequest(t, key, keyID, string(body))
+			req.Header.Set("Date", time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat))
+			signWithHeaders(t, req, key, keyID, model.RequiredSignedHeaders)
+			return req, body
+		},
+		"signed by another key": func() (*http.Request, []byte) {
+			body := []byte(`{"type":"Like"}`)
+			return inboxRequest(t, generateKey(t), keyID, string(body)), body
+		},
+		"unknown key": func() (*http.Request, []byte) {
+			body := []byte(`{"type":"Like"}`)
+			return inboxRequest(t, key, remote.url("/users/nobody#main-key"), string(body)), body
+		},
+	}
+
+	for name, build := range tests {
+		t.Run(name, func(t *testing.T) {
+			core := NewFediverseCore(nil, nil, nil, nil, newMemoryCache())
+			req, body := build()
+			if _, err := core.VerifyInboxRequest(req, body); err == nil {
+				t.Error("expected verification to fail")
+			}
+		})
+	}
+}
+
+func TestVerifyInboxRequestRefreshesRotatedKey(t *testing.T) {
+	remote := newRemoteInstance(t)
+	oldKey, newKey := generateKey(t), generateKey(t)
+	actorURL := remote.url("/users/bob")
+	keyID := actorURL + "#main-key"
+	remote.serveActor("/users/bob", keyID, actorURL, newKey)
+
+	// 缓存中仍是轮换前的公钥
+	cache := newMemoryCache()
+	cache.Set(model.PublicKeyCachePrefix+keyID, remotePublicKey{
+		owner:  actorURL,
+		pem:    publicKeyPEM(oldKey),
+		rsaKey: &oldKey.PublicKey,
+	}, 1)
+	core := NewFediverseCore(nil, nil, nil, nil, cache)
+
+	body := `{"type":"Like"}`
+	owner, err := core.VerifyInboxRequest(inboxRequest(t, newKey, keyID, body), []byte(body))
+	if err != nil {
+		t.Fatalf("verify with rotated key: %v", err)
+	}
+	if owner != actorURL {
+		t.Errorf("owner = %q, want %q", owner, actorURL)
+	}
+	if n := remote.fetches.Load(); n != 1 {
+		t.Errorf("remote fetched %d times, want 1", n)
+	}
+
+	cached, _ := cache.Get(model.PublicKeyCachePrefix + keyID)
+	if cached.(remotePublicKey).pem != publicKeyPEM(newKey) {
+		t.Error("rotated key was not cached")
+	}
+}
+
+func TestFetchRemotePublicKeyChecksOwner(t *testing.T) {
+	remote := newRemoteInstance(t)
+	key := generateKey(t)
+	actorURL := remote.url("/users/bob")
+
+	// 公钥文档声明的 owner 不在 keyId 所在的实例上
+	remote.serveActor("/users/mallory", remote.url("/users/mallory#main-key"), "https://evil.example/users/mallory", key)
+	if _, err := NewFediverseCore(nil, nil, nil, nil, newMemoryCache()).
+		fetchRemotePublicKey(remote.url("/users/mallory#main-key")); err == nil {
+		t.Error("expected cross-origin owner to be rejected")
+	}
+
+	// 独立的 Key 文档声称属于 bob，但 bob 的 Actor 文档并未声明该公钥
+	remote.serveActor("/users/bob", actorURL+"#main-key", actorURL, key)
+	remote.mu.Lock()
+	remote.docs["/keys/1"] = map[string]any{
+		"id":           remote.url("/keys/1"),
+		"owner":        actorURL,
+		"publicKeyPem": publicKeyPEM(key),
+	}
+	remote.mu.Unlock()
+	if _, err := NewFediverseCore(nil, nil, nil, nil, newMemoryCache()).
+		fetchRemotePublicKey(remote.url("/keys/1")); err == nil {
+		t.Error("expected key not declared by its owner to be rejected")
+	}
+
+	got, err := NewFediverseCore(nil, nil, nil, nil, newMemoryCache()).
+		fetchRemotePublicKey(actorURL + "#main-key")
+	if err != nil {
+		t.Fatalf("fetch actor key: %v", err)
+	}
+	if got.owner != actorURL || got.rsaKey.N.Cmp(key.N) != 0 {
+		t.Errorf("fetched key owner = %q", got.owner)
+	}
+}
+
+func TestSameOrigin(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"https://a.example/users/bob", "https://a.example/objects/1", true},
+		{"https://A.example/users/bob", "https://a.example/users/bob#main-key", true},
+		{"https://a.example/users/bob", "http://a.example/users/bob", false},
+		{"https://a.example/users/bob", "https://a.example:8443/users/bob", false},
+		{"https://a.example/users/bob", "https://b.example/users/bob", false},
+		{"/users/bob", "/users/bob", false},
+	}
+	for _, tt := range tests {
+		if got := SameOrigin(tt.a, tt.b); got != tt.want {
+			t.Errorf("SameOrigin(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -25,7 +25,7 @@ func NewEchoHandler(echoService service.EchoServiceInterface) *EchoHandler {
 // PostEcho 创建新的Echo
 //
 //	@Summary		创建新的Echo
-//	@Description	用户创建一条新的Echo动态
+//	@Description	用户创建一条新的Echo动态，status 为 draft 时保存为草稿，为 scheduled 时在 publish_at 到达后自动发布
 //	@Tags			Echo
 //	@Accept			json
 //	@Produce		json
@@ -298,6 +298,227 @@ func (echoHandler *EchoHandler) GetEchoById() gin.HandlerFunc {
 	})
 }
 
+// GetEchoThread 获取 Echo 所在的会话树
+//
+//	@Summary		获取Echo会话
+//	@Description	根据ID获取Echo所在的完整会话树，包括上级回复、所有下级回复以及联邦网络中的远端回复
+//	@Tags			Echo
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		int									true	"Echo ID"
+//	@Success		200	{object}	res.Response{data=model.EchoThread}	"获取成功"
+//	@Failure		200	{object}	res.Response						"获取失败"
+//	@Router			/echo/{id}/thread [get]
+func (echoHandler *EchoHandler) GetEchoThread() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+		if err != nil {
+			return res.Response{
+				Msg: commonModel.INVALID_PARAMS,
+				Err: err,
+			}
+		}
+
+		userId := ctx.MustGet("userid").(uint)
+
+		thread, err := echoHandler.echoService.GetEchoThread(userId, uint(id))
+		if err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Data: thread,
+			Msg:  commonModel.GET_ECHO_THREAD_SUCCESS,
+		}
+	})
+}
+
+// GetUnpublishedEchos 获取草稿与定时发布的 Echo
+//
+//	@Summary		获取草稿与定时Echo
+//	@Description	获取所有尚未发布的草稿与定时发布的Echo，按创建时间倒序，仅管理员可用
+//	@Tags			Echo
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	res.Response{data=[]model.Echo}	"获取成功"
+//	@Failure		200	{object}	res.Response					"获取失败"
+//	@Router			/echo/drafts [get]
+func (echoHandler *EchoHandler) GetUnpublishedEchos() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		userId := ctx.MustGet("userid").(uint)
+
+		echos, err := echoHandler.echoService.GetUnpublishedEchos(userId)
+		if err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Data: echos,
+			Msg:  commonModel.GET_UNPUBLISHED_ECHOS_SUCCESS,
+		}
+	})
+}
+
+// GetEchoRevisions 获取 Echo 的历史版本
+//
+//	@Summary		获取Echo历史版本
+//	@Description	根据ID获取Echo的所有历史版本，按版本号倒序，包含修改者、修改时间、内容差异以及当时的图片和标签，仅管理员可用
+//	@Tags			Echo
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		int										true	"Echo ID"
+//	@Success		200	{object}	res.Response{data=[]model.EchoRevision}	"获取成功"
+//	@Failure		200	{object}	res.Response							"获取失败"
+//	@Router			/echo/{id}/revisions [get]
+func (echoHandler *EchoHandler) GetEchoRevisions() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+		if err != nil {
+			return res.Response{
+				Msg: commonModel.INVALID_PARAMS,
+				Err: err,
+			}
+		}
+
+		userId := ctx.MustGet("userid").(uint)
+
+		revisions, err := echoHandler.echoService.GetEchoRevisions(userId, uint(id))
+		if err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Data: revisions,
+			Msg:  commonModel.GET_ECHO_REVISIONS_SUCCESS,
+		}
+	})
+}
+
+// RestoreEchoRevision 将 Echo 恢复到指定的历史版本
+//
+//	@Summary		恢复Echo历史版本
+//	@Description	将Echo的内容、图片、标签和扩展恢复到指定版本，恢复后会生成新的版本并推送更新事件，仅管理员可用
+//	@Tags			Echo
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		int				true	"Echo ID"
+//	@Param			rev	path		int				true	"版本号"
+//	@Success		200	{object}	res.Response	"恢复成功"
+//	@Failure		200	{object}	res.Response	"恢复失败"
+//	@Router			/echo/{id}/revisions/{rev}/restore [post]
+func (echoHandler *EchoHandler) RestoreEchoRevision() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+		if err != nil {
+			return res.Response{
+				Msg: commonModel.INVALID_PARAMS,
+				Err: err,
+			}
+		}
+		revision, err := strconv.Atoi(ctx.Param("rev"))
+		if err != nil || revision <= 0 {
+			return res.Response{
+				Msg: commonModel.INVALID_PARAMS,
+				Err: err,
+			}
+		}
+
+		userId := ctx.MustGet("userid").(uint)
+
+		if err := echoHandler.echoService.RestoreEchoRevision(userId, uint(id), revision); err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Msg: commonModel.RESTORE_ECHO_REVISION_SUCCESS,
+		}
+	})
+}
+
+// PinEcho 置顶 Echo
+//
+//	@Summary		置顶Echo
+//	@Description	将已发布的Echo置顶，置顶的Echo在分页列表第一页中排在最前面，并作为联邦网络的置顶帖子展示，仅管理员可用
+//	@Tags			Echo
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		int				true	"Echo ID"
+//	@Success		200	{object}	res.Response	"置顶成功"
+//	@Failure		200	{object}	res.Response	"置顶失败"
+//	@Router			/echo/{id}/pin [post]
+func (echoHandler *EchoHandler) PinEcho() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+		if err != nil {
+			return res.Response{
+				Msg: commonModel.INVALID_PARAMS,
+				Err: err,
+			}
+		}
+
+		userId := ctx.MustGet("userid").(uint)
+
+		if err := echoHandler.echoService.PinEcho(userId, uint(id)); err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Msg: commonModel.PIN_ECHO_SUCCESS,
+		}
+	})
+}
+
+// UnpinEcho 取消置顶 Echo
+//
+//	@Summary		取消置顶Echo
+//	@Description	取消Echo的置顶，仅管理员可用
+//	@Tags			Echo
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		int				true	"Echo ID"
+//	@Success		200	{object}	res.Response	"取消置顶成功"
+//	@Failure		200	{object}	res.Response	"取消置顶失败"
+//	@Router			/echo/{id}/pin [delete]
+func (echoHandler *EchoHandler) UnpinEcho() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+		if err != nil {
+			return res.Response{
+				Msg: commonModel.INVALID_PARAMS,
+				Err: err,
+			}
+		}
+
+		userId := ctx.MustGet("userid").(uint)
+
+		if err := echoHandler.echoService.UnpinEcho(userId, uint(id)); err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Msg: commonModel.UNPIN_ECHO_SUCCESS,
+		}
+	})
+}
+
 // GetAllTags 获取所有标签
 //
 //	@Summary		获取所有标签
@@ -412,3 +633,83 @@ func (echoHandler *EchoHandler) GetEchosByTagId() gin.HandlerFunc {
 		}
 	})
 }
+
+// GetEchoTimeline 按游标获取 Echo 时间线
+//
+//	@Summary		按游标获取 Echo 时间线
+//	@Description	使用 before_id / since_id 游标获取 Echo 时间线，新 Echo 发布后翻页不会出现重复；响应携带 ETag 与 Last-Modified，内容未变化时返回 304
+//	@Tags			Echo
+//	@Accept			json
+//	@Produce		json
+//	@Param			before_id	query		int							false	"返回发布时间早于该 Echo 的较旧 Echo"
+//	@Param			since_id	query		int							false	"返回发布时间晚于该 Echo 的较新 Echo"
+//	@Param			limit		query		int							false	"返回数量"
+//	@Param			search		query		string						false	"搜索关键字"
+//	@Success		200			{object}	res.Response{data=object}	"获取成功"
+//	@Success		304			"内容未变化"
+//	@Failure		200			{object}	res.Response				"获取失败"
+//	@Router			/echo/timeline [get]
+func (echoHandler *EchoHandler) GetEchoTimeline() gin.HandlerFunc {
+	return res.ExecuteConditional(func(ctx *gin.Context) res.Response {
+		return echoHandler.getEchoTimeline(ctx, 0)
+	})
+}
+
+// GetEchoTimelineByTagId 按游标获取指定标签的 Echo 时间线
+//
+//	@Summary		按游标获取指定标签的 Echo 时间线
+//	@Description	使用 before_id / since_id 游标获取包含指定标签的 Echo；响应携带 ETag 与 Last-Modified，内容未变化时返回 304
+//	@Tags			Echo
+//	@Accept			json
+//	@Produce		json
+//	@Param			tagid		path		int							true	"标签 ID"
+//	@Param			before_id	query		int							false	"返回发布时间早于该 Echo 的较旧 Echo"
+//	@Param			since_id	query		int							false	"返回发布时间晚于该 Echo 的较新 Echo"
+//	@Param			limit		query		int							false	"返回数量"
+//	@Param			search		query		string						false	"搜索关键字"
+//	@Success		200			{object}	res.Response{data=object}	"获取成功"
+//	@Success		304			"内容未变化"
+//	@Failure		200			{object}	res.Response				"获取失败"
+//	@Router			/echo/tag/{tagid}/timeline [get]
+func (echoHandler *EchoHandler) GetEchoTimelineByTagId() gin.HandlerFunc {
+	return res.ExecuteConditional(func(ctx *gin.Context) res.Response {
+		tagId, err := strconv.ParseUint(ctx.Param("tagid"), 10, 64)
+		if err != nil || tagId == 0 {
+			return res.Response{
+				Msg: commonModel.INVALID_PARAMS,
+				Err: errors.New(commonModel.INVALID_PARAMS),
+			}
+		}
+
+		return echoHandler.getEchoTimeline(ctx, uint(tagId))
+	})
+}
+
+// getEchoTimeline 解析游标参数并获取时间线，tagId 为 0 时获取全部 Echo
+func (echoHandler *EchoHandler) getEchoTimeline(ctx *gin.Context, tagId uint) res.Response {
+	// 先记录修改时间，避免读取期间发生变化导致 Last-Modified 晚于实际内容
+	lastModified := echoHandler.echoService.TimelineUpdatedAt()
+
+	var cursorRequest commonModel.CursorQueryDto
+	if err := ctx.ShouldBindQuery(&cursorRequest); err != nil {
+		return res.Response{
+			Msg: commonModel.INVALID_QUERY_PARAMS,
+			Err: err,
+		}
+	}
+
+	userid := ctx.MustGet("userid").(uint)
+	result, err := echoHandler.echoService.GetEchoTimeline(userid, tagId, cursorRequest)
+	if err != nil {
+		return res.Response{
+			Msg: "",
+			Err: err,
+		}
+	}
+
+	return res.Response{
+		Data:         result,
+		Msg:          commonModel.GET_ECHOS_BY_PAGE_SUCCESS,
+		LastModified: lastModified,
+	}
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	res "github.com/lin-snow/ech0/internal/handler/response"
+	model "github.com/lin-snow/ech0/internal/model/exporter"
+	service "github.com/lin-snow/ech0/internal/service/exporter"
+	logUtil "github.com/lin-snow/ech0/internal/util/log"
+	"go.uber.org/zap"
+)
+
+// ExporterHandler 负责处理内容导出相关 HTTP 请求
+type ExporterHandler struct {
+	exportService service.ExportServiceInterface
+}
+
+// NewExporterHandler 创建新的 ExporterHandler 实例
+func NewExporterHandler(exportService service.ExportServiceInterface) *ExporterHandler {
+	return &ExporterHandler{exportService: exportService}
+}
+
+// Export 导出全部 Echo
+//
+//	@Summary		导出全部 Echo
+//	@Description	以流的形式下载全部 Echo：md 为带 YAML front-matter 的 Markdown 与媒体目录，jsonl 为每行一条 JSON，html 为静态站点
+//	@Tags			导出
+//	@Produce		application/zip
+//	@Produce		application/x-ndjson
+//	@Param			format	query		string			false	"导出格式 (md/jsonl/html)，默认为 md"
+//	@Success		200		{file}		file			"导出文件"
+//	@Failure		200		{object}	res.Response	"导出失败"
+//	@Router			/export [get]
+func (exporterHandler *ExporterHandler) Export() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userId := ctx.MustGet("userid").(uint)
+		format := ctx.DefaultQuery("format", model.ExportFormatMarkdown)
+
+		// 开始写入文件前检查权限与格式，失败时仍返回 JSON
+		file, err := exporterHandler.exportService.CheckExport(userId, format)
+		if err != nil {
+			res.Execute(func(ctx *gin.Context) res.Response {
+				return res.Response{Err: err}
+			})(ctx)
+			return
+		}
+
+		ctx.Header("Content-Type", file.ContentType)
+		ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", file.Filename))
+		ctx.Header("Cache-Control", "no-cache, no-store, must-revalidate")
+		ctx.Status(http.StatusOK)
+
+		// 响应头已发送，导出中途失败只能中断下载
+		if err := exporterHandler.exportService.Export(ctx.Request.Context(), format, ctx.Writer); err != nil {
+			logUtil.GetLogger().Error("Failed to export echos", zap.String("error", err.Error()))
+			_ = ctx.Error(err)
+		}
+	}
+}
@@ -1,12 +1,15 @@
 package handler
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
 	model "github.com/lin-snow/ech0/internal/model/fediverse"
 	service "github.com/lin-snow/ech0/internal/service/fediverse"
 )
@@ -104,8 +107,31 @@ func (h *FediverseHandler) PostInbox(ctx *gin.Context) {
 	// 从 URL 参数中获取用户名
 	username := ctx.Param("username")
 
+	// 读取原始请求体，Digest 校验需要未经解析的字节，超过大小限制直接拒绝
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, model.MaxInboxBodyBytes)
+	body, err := ctx.GetRawData()
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			ctx.JSON(http.StatusRequestEntityTooLarge, model.ActivityPubError{
+				Context: "https://www.w3.org/ns/activitystreams",
+				Type:    "Error",
+				Error:   "Body too large",
+				Status:  http.StatusRequestEntityTooLarge,
+			})
+			return
+		}
+		ctx.JSON(http.StatusBadRequest, model.ActivityPubError{
+			Context: "https://www.w3.org/ns/activitystreams",
+			Type:    "Error",
+			Error:   "Invalid body",
+			Status:  http.StatusBadRequest,
+		})
+		return
+	}
+
 	var activity model.Activity
-	if err := ctx.ShouldBindJSON(&activity); err != nil {
+	if err := json.Unmarshal(body, &activity); err != nil {
 		ctx.JSON(http.StatusBadRequest, model.ActivityPubError{
 			Context: "https://www.w3.org/ns/activitystreams",
 			Type:    "Error",
@@ -115,6 +141,17 @@ func (h *FediverseHandler) PostInbox(ctx *gin.Context) {
 		return
 	}
 
+	// 校验 HTTP 签名
+	if err := h.service.VerifyInboxSignature(ctx.Request, body, &activity); err != nil {
+		ctx.JSON(http.StatusUnauthorized, model.ActivityPubError{
+			Context: "https://www.w3.org/ns/activitystreams",
+			Type:    "Error",
+			Error:   err.Error(),
+			Status:  http.StatusUnauthorized,
+		})
+		return
+	}
+
 	if err := h.service.HandleInbox(username, &activity); err != nil {
 		ctx.JSON(http.StatusInternalServerError, model.ActivityPubError{
 			Context: "https://www.w3.org/ns/activitystreams",
@@ -201,6 +238,29 @@ func (h *FediverseHandler) GetOutbox(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, outbox)
 }
 
+// GetFeatured 获取置顶帖子集合
+func (h *FediverseHandler) GetFeatured(ctx *gin.Context) {
+	// 从 URL 参数中获取用户名
+	username := ctx.Param("username")
+
+	featured, err := h.service.HandleFeatured(username)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, model.ActivityPubError{
+			Context: "https://www.w3.org/ns/activitystreams",
+			Type:    "Error",
+			Error:   err.Error(),
+			Status:  http.StatusInternalServerError,
+		})
+		return
+	}
+
+	// 设置 Content-Type 为 application/activity+json
+	ctx.Header("Content-Type", "application/activity+json")
+
+	// 返回置顶帖子集合
+	ctx.JSON(http.StatusOK, featured)
+}
+
 // GetFollowers 获取粉丝列表
 func (h *FediverseHandler) GetFollowers(ctx *gin.Context) {
 	// 从 URL 参数中获取用户名
@@ -342,6 +402,24 @@ func (h *FediverseHandler) GetObject(ctx *gin.Context) {
 
 	// 调用服务层获取对象信息
 	object, err := h.service.GetObjectByID(uint(uintID))
+	if err != nil && err.Error() == commonModel.OBJECT_DELETED {
+		// 已删除的对象返回 Tombstone
+		tombstone, tombstoneErr := h.service.GetTombstoneByID(uint(uintID))
+		if tombstoneErr == nil {
+			ctx.Header("Content-Type", "application/activity+json")
+			ctx.JSON(http.StatusGone, tombstone)
+			return
+		}
+	}
+	if err != nil && err.Error() == commonModel.OBJECT_NOT_FOUND {
+		ctx.JSON(http.StatusNotFound, model.ActivityPubError{
+			Context: "https://www.w3.org/ns/activitystreams",
+			Type:    "Error",
+			Error:   err.Error(),
+			Status:  http.StatusNotFound,
+		})
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, model.ActivityPubError{
 			Context: "https://www.w3.org/ns/activitystreams",
@@ -15,6 +15,9 @@ type FediverseHandlerInterface interface {
 	// GetOutbox 获取 Outbox 消息
 	GetOutbox(ctx *gin.Context)
 
+	// GetFeatured 获取置顶帖子集合
+	GetFeatured(ctx *gin.Context)
+
 	// GetFollowers 获取粉丝列表
 	GetFollowers(ctx *gin.Context)
 
@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	res "github.com/lin-snow/ech0/internal/handler/response"
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	model "github.com/lin-snow/ech0/internal/model/importer"
+	service "github.com/lin-snow/ech0/internal/service/importer"
+)
+
+// ImporterHandler 负责处理从其他平台导入内容的 HTTP 请求
+type ImporterHandler struct {
+	importService service.ImportServiceInterface
+}
+
+// NewImporterHandler 创建新的 ImporterHandler 实例
+func NewImporterHandler(importService service.ImportServiceInterface) *ImporterHandler {
+	return &ImporterHandler{importService: importService}
+}
+
+// StartImport 开始导入
+//
+//	@Summary		从其他平台导入内容
+//	@Description	上传 Memos、Mastodon 或 Twitter/X 的导出文件，在后台导入为 Echo，返回导入任务的进度
+//	@Tags			导入
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Param			file		formData	file			true	"导出文件 (zip/tar.gz/db/json/js)"
+//	@Param			source		formData	string			true	"导入来源 (memos/mastodon/twitter)"
+//	@Param			dry_run		formData	bool			false	"只解析并统计，不写入数据"
+//	@Param			storage		formData	string			false	"媒体文件的存储方式 (local/s3)"
+//	@Param			visibility	formData	string			false	"覆盖所有导入内容的可见性"
+//	@Param			base_url	formData	string			false	"来源站点地址，用于下载 Memos API 导出数据中的媒体文件"
+//	@Success		200			{object}	res.Response	"导入任务已开始"
+//	@Failure		200			{object}	res.Response	"导入失败"
+//	@Router			/import [post]
+func (importerHandler *ImporterHandler) StartImport() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		userId := ctx.MustGet("userid").(uint)
+
+		file, err := ctx.FormFile("file")
+		if err != nil {
+			return res.Response{
+				Msg: commonModel.INVALID_REQUEST_BODY,
+				Err: err,
+			}
+		}
+
+		dryRun, _ := strconv.ParseBool(ctx.PostForm("dry_run"))
+		options := model.ImportOptions{
+			Source:     ctx.PostForm("source"),
+			DryRun:     dryRun,
+			Storage:    ctx.PostForm("storage"),
+			Visibility: ctx.PostForm("visibility"),
+			BaseURL:    ctx.PostForm("base_url"),
+		}
+
+		progress, err := importerHandler.importService.StartImport(ctx, userId, options, file)
+		if err != nil {
+			return res.Response{Err: err}
+		}
+
+		return res.Response{
+			Data: progress,
+			Msg:  commonModel.START_IMPORT_SUCCESS,
+		}
+	})
+}
+
+// GetImportJob 获取导入进度
+//
+//	@Summary		获取导入进度
+//	@Description	根据任务 ID 获取导入任务的进度与错误信息
+//	@Tags			导入
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string			true	"导入任务 ID"
+//	@Success		200	{object}	res.Response	"获取导入进度成功"
+//	@Failure		200	{object}	res.Response	"获取导入进度失败"
+//	@Router			/import/{id} [get]
+func (importerHandler *ImporterHandler) GetImportJob() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		userId := ctx.MustGet("userid").(uint)
+
+		progress, err := importerHandler.importService.GetImportJob(userId, ctx.Param("id"))
+		if err != nil {
+			return res.Response{Err: err}
+		}
+
+		return res.Response{
+			Data: progress,
+			Msg:  commonModel.GET_IMPORT_JOB_SUCCESS,
+		}
+	})
+}
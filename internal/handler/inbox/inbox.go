@@ -56,6 +56,45 @@ func (inboxHandler *InboxHandler) GetInboxList() gin.HandlerFunc {
 	})
 }
 
+// GetInboxTimeline 按游标获取收件箱消息
+//
+//	@Summary		按游标获取收件箱消息
+//	@Description	使用 before_id / since_id 游标获取收件箱消息，响应携带 ETag，内容未变化时返回 304
+//	@Tags			收件箱
+//	@Accept			json
+//	@Produce		json
+//	@Param			before_id	query		int				false	"返回 ID 小于该值的较旧消息"
+//	@Param			since_id	query		int				false	"返回 ID 大于该值的较新消息"
+//	@Param			limit		query		int				false	"返回数量"
+//	@Param			search		query		string			false	"搜索关键词"
+//	@Success		200			{object}	res.Response	"获取成功"
+//	@Success		304			"内容未变化"
+//	@Failure		200			{object}	res.Response	"获取失败"
+//	@Router			/inbox/timeline [get]
+func (inboxHandler *InboxHandler) GetInboxTimeline() gin.HandlerFunc {
+	return res.ExecuteConditional(func(ctx *gin.Context) res.Response {
+		userid := ctx.MustGet("userid").(uint)
+
+		var cursorQuery commonModel.CursorQueryDto
+		if err := ctx.ShouldBindQuery(&cursorQuery); err != nil {
+			return res.Response{
+				Msg: commonModel.INVALID_QUERY_PARAMS,
+				Err: err,
+			}
+		}
+
+		result, err := inboxHandler.inboxService.GetInboxByCursor(userid, cursorQuery)
+		if err != nil {
+			return res.Response{Err: err}
+		}
+
+		return res.Response{
+			Data: result,
+			Msg:  commonModel.GET_INBOX_LIST_SUCCESS,
+		}
+	})
+}
+
 // GetUnreadInbox 获取所有未读消息
 //
 //	@Summary		获取未读消息
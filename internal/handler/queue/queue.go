@@ -0,0 +1,185 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	res "github.com/lin-snow/ech0/internal/handler/response"
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	queueModel "github.com/lin-snow/ech0/internal/model/queue"
+	service "github.com/lin-snow/ech0/internal/service/queue"
+)
+
+// QueueHandler 负责处理死信队列相关 HTTP 请求
+type QueueHandler struct {
+	queueService service.QueueServiceInterface
+}
+
+// NewQueueHandler 创建新的 QueueHandler 实例
+func NewQueueHandler(queueService service.QueueServiceInterface) *QueueHandler {
+	return &QueueHandler{queueService: queueService}
+}
+
+// ListDeadLetters 获取死信任务列表
+//
+//	@Summary		获取死信任务列表
+//	@Description	分页获取死信任务，可按状态过滤
+//	@Tags			死信队列
+//	@Accept			json
+//	@Produce		json
+//	@Param			page		query		int				false	"页码"
+//	@Param			pageSize	query		int				false	"每页数量"
+//	@Param			status		query		string			false	"任务状态 (pending/processing/failed/completed/discarded)"
+//	@Success		200			{object}	res.Response	"获取成功"
+//	@Failure		200			{object}	res.Response	"获取失败"
+//	@Router			/dead-letters [get]
+func (queueHandler *QueueHandler) ListDeadLetters() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		userid := ctx.MustGet("userid").(uint)
+
+		var query queueModel.DeadLetterQueryDto
+		if err := ctx.ShouldBindQuery(&query); err != nil {
+			return res.Response{
+				Msg: commonModel.INVALID_QUERY_PARAMS,
+				Err: err,
+			}
+		}
+
+		result, err := queueHandler.queueService.ListDeadLetters(userid, query)
+		if err != nil {
+			return res.Response{Err: err}
+		}
+
+		return res.Response{
+			Data: result,
+			Msg:  commonModel.GET_DEAD_LETTERS_SUCCESS,
+		}
+	})
+}
+
+// GetDeadLetter 获取死信任务详情
+//
+//	@Summary		获取死信任务详情
+//	@Description	根据 ID 获取死信任务，包括原始负载与失败原因
+//	@Tags			死信队列
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		int				true	"死信任务ID"
+//	@Success		200	{object}	res.Response	"获取成功"
+//	@Failure		200	{object}	res.Response	"获取失败"
+//	@Router			/dead-letters/{id} [get]
+func (queueHandler *QueueHandler) GetDeadLetter() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		userid := ctx.MustGet("userid").(uint)
+
+		id, err := parseIDParam(ctx.Param("id"))
+		if err != nil {
+			return res.Response{
+				Msg: commonModel.INVALID_PARAMS_BODY,
+				Err: err,
+			}
+		}
+
+		deadLetter, err := queueHandler.queueService.GetDeadLetter(userid, id)
+		if err != nil {
+			return res.Response{Err: err}
+		}
+
+		return res.Response{
+			Data: deadLetter,
+			Msg:  commonModel.GET_DEAD_LETTER_SUCCESS,
+		}
+	})
+}
+
+// RetryDeadLetter 立即重试死信任务
+//
+//	@Summary		重试死信任务
+//	@Description	重置重试次数并立即提交重试
+//	@Tags			死信队列
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		int				true	"死信任务ID"
+//	@Success		200	{object}	res.Response	"提交成功"
+//	@Failure		200	{object}	res.Response	"提交失败"
+//	@Router			/dead-letters/{id}/retry [post]
+func (queueHandler *QueueHandler) RetryDeadLetter() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		userid := ctx.MustGet("userid").(uint)
+
+		id, err := parseIDParam(ctx.Param("id"))
+		if err != nil {
+			return res.Response{
+				Msg: commonModel.INVALID_PARAMS_BODY,
+				Err: err,
+			}
+		}
+
+		if err := queueHandler.queueService.RetryDeadLetter(userid, id); err != nil {
+			return res.Response{Err: err}
+		}
+
+		return res.Response{Msg: commonModel.RETRY_DEAD_LETTER_SUCCESS}
+	})
+}
+
+// DiscardDeadLetter 丢弃死信任务
+//
+//	@Summary		丢弃死信任务
+//	@Description	将死信任务标记为已丢弃，不再重试
+//	@Tags			死信队列
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		int				true	"死信任务ID"
+//	@Success		200	{object}	res.Response	"丢弃成功"
+//	@Failure		200	{object}	res.Response	"丢弃失败"
+//	@Router			/dead-letters/{id}/discard [post]
+func (queueHandler *QueueHandler) DiscardDeadLetter() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		userid := ctx.MustGet("userid").(uint)
+
+		id, err := parseIDParam(ctx.Param("id"))
+		if err != nil {
+			return res.Response{
+				Msg: commonModel.INVALID_PARAMS_BODY,
+				Err: err,
+			}
+		}
+
+		if err := queueHandler.queueService.DiscardDeadLetter(userid, id); err != nil {
+			return res.Response{Err: err}
+		}
+
+		return res.Response{Msg: commonModel.DISCARD_DEAD_LETTER_SUCCESS}
+	})
+}
+
+// PurgeDeadLetters 清理死信任务
+//
+//	@Summary		清理死信任务
+//	@Description	删除所有已完成和已丢弃的死信任务，返回删除数量
+//	@Tags			死信队列
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	res.Response	"清理成功"
+//	@Failure		200	{object}	res.Response	"清理失败"
+//	@Router			/dead-letters [delete]
+func (queueHandler *QueueHandler) PurgeDeadLetters() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		userid := ctx.MustGet("userid").(uint)
+
+		purged, err := queueHandler.queueService.PurgeDeadLetters(userid)
+		if err != nil {
+			return res.Response{Err: err}
+		}
+
+		return res.Response{
+			Data: purged,
+			Msg:  commonModel.PURGE_DEAD_LETTERS_SUCCESS,
+		}
+	})
+}
+
+func parseIDParam(raw string) (int64, error) {
+	return strconv.ParseInt(raw, 10, 64)
+}
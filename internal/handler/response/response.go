@@ -1,7 +1,12 @@
 package handler
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	commonModel "github.com/lin-snow/ech0/internal/model/common"
@@ -24,6 +29,10 @@ type Response struct {
 	// Err 错误信息，序列化时忽略（仅供内部日志使用）
 	// swagger:ignore
 	Err error `json:"-"`
+
+	// LastModified 数据最后修改时间，仅 ExecuteConditional 使用，序列化时忽略
+	// swagger:ignore
+	LastModified time.Time `json:"-"`
 }
 
 // Execute 包装器，自动根据 Response 返回统一格式的 HTTP 响应 (仅处理返回类型为JSON的handler)
@@ -48,3 +57,77 @@ func Execute(fn func(ctx *gin.Context) Response) gin.HandlerFunc {
 		}
 	}
 }
+
+// ExecuteConditional 与 Execute 相同，并为成功的响应添加 ETag 与 Last-Modified 响应头，
+// 客户端携带的 If-None-Match 或 If-Modified-Since 未过期时返回 304
+func ExecuteConditional(fn func(ctx *gin.Context) Response) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		res := fn(ctx)
+		if res.Err != nil {
+			ctx.JSON(http.StatusBadRequest, commonModel.Fail[string](
+				errorUtil.HandleError(&commonModel.ServerError{
+					Msg: res.Msg,
+					Err: res.Err,
+				}),
+			))
+			return
+		}
+
+		var body commonModel.Result[any]
+		if res.Code != 0 {
+			body = commonModel.OKWithCode(res.Data, res.Code, res.Msg)
+		} else {
+			body = commonModel.OK(res.Data, res.Msg)
+		}
+		payload, err := json.Marshal(body)
+		if err != nil {
+			ctx.JSON(http.StatusOK, body)
+			return
+		}
+
+		// ETag 由响应内容计算，内容不变时保持不变
+		sum := sha256.Sum256(payload)
+		etag := `W/"` + hex.EncodeToString(sum[:16]) + `"`
+
+		// 允许客户端缓存，但每次使用前都需要重新验证；同时移除 NoCache 中间件设置的禁止缓存头
+		ctx.Header("Cache-Control", "private, no-cache")
+		ctx.Header("Pragma", "")
+		ctx.Header("Expires", "")
+		ctx.Header("Surrogate-Control", "")
+		ctx.Header("Vary", "Authorization")
+		ctx.Header("ETag", etag)
+		if !res.LastModified.IsZero() {
+			ctx.Header("Last-Modified", res.LastModified.UTC().Format(http.TimeFormat))
+		}
+
+		if notModified(ctx.Request, etag, res.LastModified) {
+			ctx.Status(http.StatusNotModified)
+			return
+		}
+
+		ctx.Data(http.StatusOK, "application/json; charset=utf-8", payload)
+	}
+}
+
+// notModified 按 RFC 9110 判断条件请求是否可以返回 304，If-None-Match 优先于 If-Modified-Since
+func notModified(req *http.Request, etag string, lastModified time.Time) bool {
+	if inm := req.Header.Get("If-None-Match"); inm != "" {
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+
+	if ims := req.Header.Get("If-Modified-Since"); ims != "" && !lastModified.IsZero() {
+		since, err := http.ParseTime(ims)
+		if err != nil {
+			return false
+		}
+		return !lastModified.Truncate(time.Second).After(since)
+	}
+
+	return false
+}
@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	logUtil "github.com/lin-snow/ech0/internal/util/log"
+	"go.uber.org/zap"
+)
+
+// serveConditional 使用 ExecuteConditional 处理一次 GET 请求
+func serveConditional(res Response, headers map[string]string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/", ExecuteConditional(func(*gin.Context) Response { return res }))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestExecuteConditional(t *testing.T) {
+	modified := time.Date(2024, 5, 1, 12, 0, 0, 500, time.UTC)
+	res := Response{Data: []int{1, 2, 3}, Msg: "ok", LastModified: modified}
+
+	first := serveConditional(res, nil)
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || etag == "" || etag[:2] != `W/` {
+		t.Fatalf("first response = %d etag %q", first.Code, etag)
+	}
+	if got := first.Header().Get("Last-Modified"); got != modified.Format(http.TimeFormat) {
+		t.Errorf("Last-Modified = %q", got)
+	}
+	if got := first.Header().Get("Cache-Control"); got != "private, no-cache" {
+		t.Errorf("Cache-Control = %q", got)
+	}
+
+	// 相同内容的 ETag 保持不变，内容变化后 ETag 随之变化
+	if again := serveConditional(res, nil).Header().Get("ETag"); again != etag {
+		t.Errorf("etag changed from %s to %s", etag, again)
+	}
+	changed := res
+	changed.Data = []int{1, 2}
+	if other := serveConditional(changed, nil).Header().Get("ETag"); other == etag {
+		t.Error("etag did not change with the body")
+	}
+
+	strong := etag[2:]
+	before := modified.Add(-time.Hour).Format(http.TimeFormat)
+	after := modified.Add(time.Hour).Format(http.TimeFormat)
+	tests := map[string]struct {
+		headers map[string]string
+		want    int
+	}{
+		"ETag 匹配":               {headers: map[string]string{"If-None-Match": etag}, want: http.StatusNotModified},
+		"强校验形式的 ETag 匹配":        {headers: map[string]string{"If-None-Match": strong}, want: http.StatusNotModified},
+		"多个 ETag 之一匹配":          {headers: map[string]string{"If-None-Match": `"other", ` + etag}, want: http.StatusNotModified},
+		"通配符":                   {headers: map[string]string{"If-None-Match": "*"}, want: http.StatusNotModified},
+		"ETag 不匹配":              {headers: map[string]string{"If-None-Match": `W/"stale"`}, want: http.StatusOK},
+		"修改时间之后":                {headers: map[string]string{"If-Modified-Since": after}, want: http.StatusNotModified},
+		"秒级精度相同时间":              {headers: map[string]string{"If-Modified-Since": modified.Format(http.TimeFormat)}, want: http.StatusNotModified},
+		"修改时间之前":                {headers: map[string]string{"If-Modified-Since": before}, want: http.StatusOK},
+		"无法解析的时间":               {headers: map[string]string{"If-Modified-Since": "yesterday"}, want: http.StatusOK},
+		"If-None-Match 优先于修改时间": {headers: map[string]string{"If-None-Match": `W/"stale"`, "If-Modified-Since": after}, want: http.StatusOK},
+	}
+
+	for name, tt := range tests {
+		w := serveConditional(res, tt.headers)
+		if w.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d", name, w.Code, tt.want)
+			continue
+		}
+		if tt.want == http.StatusNotModified && w.Body.Len() != 0 {
+			t.Errorf("%s: 304 has a body: %s", name, w.Body.String())
+		}
+		// 304 同样携带 ETag，客户端据此更新缓存
+		if w.Header().Get("ETag") != etag {
+			t.Errorf("%s: etag = %q", name, w.Header().Get("ETag"))
+		}
+	}
+}
+
+func TestExecuteConditionalWithoutLastModified(t *testing.T) {
+	w := serveConditional(Response{Data: "x"}, map[string]string{
+		"If-Modified-Since": time.Now().Add(time.Hour).Format(http.TimeFormat),
+	})
+	if w.Code != http.StatusOK || w.Header().Get("Last-Modified") != "" {
+		t.Errorf("status = %d, Last-Modified = %q", w.Code, w.Header().Get("Last-Modified"))
+	}
+}
+
+func TestExecuteConditionalError(t *testing.T) {
+	logUtil.Logger = zap.NewNop()
+	w := serveConditional(Response{Msg: "failed", Err: errors.New("boom")}, map[string]string{"If-None-Match": "*"})
+	// 失败的响应不参与条件请求，也不能被缓存
+	if w.Code != http.StatusBadRequest || w.Header().Get("ETag") != "" {
+		t.Errorf("status = %d, etag = %q", w.Code, w.Header().Get("ETag"))
+	}
+}
@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	res "github.com/lin-snow/ech0/internal/handler/response"
+	authModel "github.com/lin-snow/ech0/internal/model/auth"
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	service "github.com/lin-snow/ech0/internal/service/session"
+)
+
+type SessionHandler struct {
+	sessionService service.SessionServiceInterface
+}
+
+// NewSessionHandler SessionHandler 的构造函数
+func NewSessionHandler(sessionService service.SessionServiceInterface) *SessionHandler {
+	return &SessionHandler{
+		sessionService: sessionService,
+	}
+}
+
+// RefreshToken 刷新访问令牌
+//
+//	@Summary		刷新访问令牌
+//	@Description	使用刷新令牌换取新的访问令牌与刷新令牌，旧的刷新令牌随即失效
+//	@Tags			用户认证
+//	@Accept			json
+//	@Produce		json
+//	@Param			refresh	body		authModel.RefreshTokenDto						true	"刷新令牌"
+//	@Success		200		{object}	res.Response{data=authModel.TokenPairDto}	"刷新令牌成功"
+//	@Failure		200		{object}	res.Response								"刷新令牌失败"
+//	@Router			/auth/refresh [post]
+func (sessionHandler *SessionHandler) RefreshToken() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		var refreshDto authModel.RefreshTokenDto
+		if err := ctx.ShouldBindJSON(&refreshDto); err != nil {
+			return res.Response{
+				Msg: commonModel.INVALID_REQUEST_BODY,
+				Err: err,
+			}
+		}
+
+		client := authModel.ClientInfo{
+			IP:        ctx.ClientIP(),
+			UserAgent: ctx.Request.UserAgent(),
+		}
+		tokenPair, err := sessionHandler.sessionService.RefreshSession(refreshDto.RefreshToken, client)
+		if err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Data: tokenPair,
+			Msg:  commonModel.REFRESH_TOKEN_SUCCESS,
+		}
+	})
+}
+
+// Logout 退出登录
+//
+//	@Summary		退出登录
+//	@Description	注销当前登录会话，会话的访问令牌与刷新令牌立即失效
+//	@Tags			用户认证
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	res.Response	"退出登录成功"
+//	@Failure		200	{object}	res.Response	"退出登录失败"
+//	@Router			/logout [post]
+func (sessionHandler *SessionHandler) Logout() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		userid := ctx.MustGet("userid").(uint)
+		sessionID := ctx.GetUint("session_id")
+
+		if err := sessionHandler.sessionService.RevokeSession(userid, sessionID); err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Msg: commonModel.LOGOUT_SUCCESS,
+		}
+	})
+}
+
+// ListSessions 获取登录会话列表
+//
+//	@Summary		获取登录会话列表
+//	@Description	获取当前用户所有未过期的登录会话，包含设备、IP 与最近活跃时间
+//	@Tags			用户认证
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	res.Response{data=[]authModel.SessionDto}	"获取登录会话列表成功"
+//	@Failure		200	{object}	res.Response								"获取登录会话列表失败"
+//	@Router			/sessions [get]
+func (sessionHandler *SessionHandler) ListSessions() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		userid := ctx.MustGet("userid").(uint)
+		sessionID := ctx.GetUint("session_id")
+
+		sessions, err := sessionHandler.sessionService.ListSessions(userid, sessionID)
+		if err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Data: sessions,
+			Msg:  commonModel.LIST_SESSIONS_SUCCESS,
+		}
+	})
+}
+
+// DeleteSession 注销登录会话
+//
+//	@Summary		注销登录会话
+//	@Description	注销指定的登录会话，id 为 all 时注销当前用户的全部登录会话
+//	@Tags			用户认证
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string			true	"登录会话 ID 或 all"
+//	@Success		200	{object}	res.Response	"注销登录会话成功"
+//	@Failure		200	{object}	res.Response	"注销登录会话失败"
+//	@Router			/sessions/{id} [delete]
+func (sessionHandler *SessionHandler) DeleteSession() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		userid := ctx.MustGet("userid").(uint)
+
+		idStr := ctx.Param("id")
+		if idStr == "all" {
+			if err := sessionHandler.sessionService.RevokeAllSessions(userid); err != nil {
+				return res.Response{
+					Msg: "",
+					Err: err,
+				}
+			}
+
+			return res.Response{
+				Msg: commonModel.REVOKE_ALL_SESSIONS_SUCCESS,
+			}
+		}
+
+		id, err := strconv.ParseUint(idStr, 10, 64)
+		if err != nil {
+			return res.Response{
+				Msg: commonModel.INVALID_PARAMS,
+			}
+		}
+
+		if err := sessionHandler.sessionService.RevokeSession(userid, uint(id)); err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Msg: commonModel.REVOKE_SESSION_SUCCESS,
+		}
+	})
+}
@@ -468,6 +468,146 @@ func (settingHandler *SettingHandler) CreateWebhook() gin.HandlerFunc {
 	})
 }
 
+// GetWebhookDeliveries 获取 Webhook 投递记录
+//
+//	@Summary		获取 Webhook 投递记录
+//	@Description	分页获取指定 Webhook 的投递记录，按时间倒序
+//	@Tags			系统设置
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		int				true	"Webhook ID"
+//	@Param			page		query		int				false	"页码"
+//	@Param			pageSize	query		int				false	"每页数量"
+//	@Success		200			{object}	res.Response	"获取 Webhook 投递记录成功"
+//	@Failure		200			{object}	res.Response	"获取 Webhook 投递记录失败"
+//	@Router			/webhook/{id}/deliveries [get]
+func (settingHandler *SettingHandler) GetWebhookDeliveries() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		// 获取当前用户 ID
+		userid := ctx.MustGet("userid").(uint)
+
+		// 从路径参数中获取 Webhook ID
+		id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+		if err != nil {
+			return res.Response{
+				Msg: commonModel.INVALID_PARAMS,
+			}
+		}
+
+		var pageQuery commonModel.PageQueryDto
+		if err := ctx.ShouldBindQuery(&pageQuery); err != nil {
+			return res.Response{
+				Msg: commonModel.INVALID_QUERY_PARAMS,
+				Err: err,
+			}
+		}
+
+		result, err := settingHandler.settingService.ListWebhookDeliveries(userid, uint(id), pageQuery)
+		if err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Data: result,
+			Msg:  commonModel.GET_WEBHOOK_DELIVERIES_SUCCESS,
+		}
+	})
+}
+
+// GetWebhookDelivery 获取 Webhook 投递详情
+//
+//	@Summary		获取 Webhook 投递详情
+//	@Description	获取单条投递记录的请求头、请求体与响应内容
+//	@Tags			系统设置
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		int												true	"Webhook ID"
+//	@Param			deliveryId	path		int												true	"投递记录 ID"
+//	@Success		200			{object}	res.Response{data=webhookModel.WebhookDelivery}	"获取 Webhook 投递详情成功"
+//	@Failure		200			{object}	res.Response									"获取 Webhook 投递详情失败"
+//	@Router			/webhook/{id}/deliveries/{deliveryId} [get]
+func (settingHandler *SettingHandler) GetWebhookDelivery() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		// 获取当前用户 ID
+		userid := ctx.MustGet("userid").(uint)
+
+		id, deliveryID, err := parseWebhookDeliveryParams(ctx)
+		if err != nil {
+			return res.Response{
+				Msg: commonModel.INVALID_PARAMS,
+			}
+		}
+
+		delivery, err := settingHandler.settingService.GetWebhookDelivery(userid, id, deliveryID)
+		if err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Data: delivery,
+			Msg:  commonModel.GET_WEBHOOK_DELIVERY_SUCCESS,
+		}
+	})
+}
+
+// RedeliverWebhookDelivery 重新投递 Webhook
+//
+//	@Summary		重新投递 Webhook
+//	@Description	使用当前 Webhook 配置立即重新投递一条历史记录，返回新的投递记录
+//	@Tags			系统设置
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		int												true	"Webhook ID"
+//	@Param			deliveryId	path		int												true	"投递记录 ID"
+//	@Success		200			{object}	res.Response{data=webhookModel.WebhookDelivery}	"重新投递 Webhook 成功"
+//	@Failure		200			{object}	res.Response									"重新投递 Webhook 失败"
+//	@Router			/webhook/{id}/deliveries/{deliveryId}/redeliver [post]
+func (settingHandler *SettingHandler) RedeliverWebhookDelivery() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		// 获取当前用户 ID
+		userid := ctx.MustGet("userid").(uint)
+
+		id, deliveryID, err := parseWebhookDeliveryParams(ctx)
+		if err != nil {
+			return res.Response{
+				Msg: commonModel.INVALID_PARAMS,
+			}
+		}
+
+		delivery, err := settingHandler.settingService.RedeliverWebhookDelivery(userid, id, deliveryID)
+		if err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Data: delivery,
+			Msg:  commonModel.REDELIVER_WEBHOOK_SUCCESS,
+		}
+	})
+}
+
+// parseWebhookDeliveryParams 解析路径中的 Webhook ID 与投递记录 ID
+func parseWebhookDeliveryParams(ctx *gin.Context) (uint, uint, error) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	deliveryID, err := strconv.ParseUint(ctx.Param("deliveryId"), 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	return uint(id), uint(deliveryID), nil
+}
+
 // ListAccessTokens 列出访问令牌
 //
 //	@Summary		列出访问令牌
@@ -501,13 +641,13 @@ func (settingHandler *SettingHandler) ListAccessTokens() gin.HandlerFunc {
 // CreateAccessToken 创建访问令牌
 //
 //	@Summary		创建访问令牌
-//	@Description	为当前用户创建一个新的访问令牌
+//	@Description	为当前用户创建一个带权限范围的访问令牌，令牌明文仅在创建时返回一次
 //	@Tags			系统设置
 //	@Accept			json
 //	@Produce		json
-//	@Param			accessToken	body		model.AccessTokenSettingDto					true	"新的访问令牌信息"
-//	@Success		200			{object}	res.Response{data=model.AccessTokenSetting}	"创建访问令牌成功"
-//	@Failure		200			{object}	res.Response								"创建访问令牌失败"
+//	@Param			accessToken	body		model.AccessTokenSettingDto		true	"新的访问令牌信息"
+//	@Success		200			{object}	res.Response{data=string}	"创建访问令牌成功"
+//	@Failure		200			{object}	res.Response				"创建访问令牌失败"
 //	@Router			/access-tokens [post]
 func (settingHandler *SettingHandler) CreateAccessToken() gin.HandlerFunc {
 	return res.Execute(func(ctx *gin.Context) res.Response {
@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	res "github.com/lin-snow/ech0/internal/handler/response"
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	trashModel "github.com/lin-snow/ech0/internal/model/trash"
+	service "github.com/lin-snow/ech0/internal/service/trash"
+)
+
+// TrashHandler 负责处理回收站相关 HTTP 请求
+type TrashHandler struct {
+	trashService service.TrashServiceInterface
+}
+
+// NewTrashHandler 创建新的 TrashHandler 实例
+func NewTrashHandler(trashService service.TrashServiceInterface) *TrashHandler {
+	return &TrashHandler{trashService: trashService}
+}
+
+// ListTrash 获取回收站内容
+//
+//	@Summary		获取回收站内容
+//	@Description	获取已删除的 Echo、标签与待办事项，可按类型过滤
+//	@Tags			回收站
+//	@Accept			json
+//	@Produce		json
+//	@Param			type	query		string			false	"内容类型 (echo/tag/todo)"
+//	@Success		200		{object}	res.Response	"获取成功"
+//	@Failure		200		{object}	res.Response	"获取失败"
+//	@Router			/trash [get]
+func (trashHandler *TrashHandler) ListTrash() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		userid := ctx.MustGet("userid").(uint)
+
+		var query trashModel.TrashQueryDto
+		if err := ctx.ShouldBindQuery(&query); err != nil {
+			return res.Response{
+				Msg: commonModel.INVALID_QUERY_PARAMS,
+				Err: err,
+			}
+		}
+
+		items, err := trashHandler.trashService.ListTrash(userid, query)
+		if err != nil {
+			return res.Response{Err: err}
+		}
+
+		return res.Response{
+			Data: items,
+			Msg:  commonModel.GET_TRASH_SUCCESS,
+		}
+	})
+}
+
+// RestoreTrashItem 恢复回收站中的内容
+//
+//	@Summary		恢复回收站中的内容
+//	@Description	将已删除的 Echo、标签或待办事项恢复
+//	@Tags			回收站
+//	@Accept			json
+//	@Produce		json
+//	@Param			type	path		string			true	"内容类型 (echo/tag/todo)"
+//	@Param			id		path		int				true	"内容ID"
+//	@Success		200		{object}	res.Response	"恢复成功"
+//	@Failure		200		{object}	res.Response	"恢复失败"
+//	@Router			/trash/{type}/{id}/restore [post]
+func (trashHandler *TrashHandler) RestoreTrashItem() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		userid := ctx.MustGet("userid").(uint)
+
+		id, err := parseIDParam(ctx.Param("id"))
+		if err != nil {
+			return res.Response{
+				Msg: commonModel.INVALID_PARAMS_BODY,
+				Err: err,
+			}
+		}
+
+		if err := trashHandler.trashService.RestoreTrashItem(userid, ctx.Param("type"), id); err != nil {
+			return res.Response{Err: err}
+		}
+
+		return res.Response{Msg: commonModel.RESTORE_TRASH_SUCCESS}
+	})
+}
+
+// PurgeTrashItem 彻底删除回收站中的内容
+//
+//	@Summary		彻底删除回收站中的内容
+//	@Description	彻底删除已删除的内容，Echo 的图片文件会一并删除且无法恢复
+//	@Tags			回收站
+//	@Accept			json
+//	@Produce		json
+//	@Param			type	path		string			true	"内容类型 (echo/tag/todo)"
+//	@Param			id		path		int				true	"内容ID"
+//	@Success		200		{object}	res.Response	"删除成功"
+//	@Failure		200		{object}	res.Response	"删除失败"
+//	@Router			/trash/{type}/{id} [delete]
+func (trashHandler *TrashHandler) PurgeTrashItem() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		userid := ctx.MustGet("userid").(uint)
+
+		id, err := parseIDParam(ctx.Param("id"))
+		if err != nil {
+			return res.Response{
+				Msg: commonModel.INVALID_PARAMS_BODY,
+				Err: err,
+			}
+		}
+
+		if err := trashHandler.trashService.PurgeTrashItem(userid, ctx.Param("type"), id); err != nil {
+			return res.Response{Err: err}
+		}
+
+		return res.Response{Msg: commonModel.PURGE_TRASH_SUCCESS}
+	})
+}
+
+// EmptyTrash 清空回收站
+//
+//	@Summary		清空回收站
+//	@Description	彻底删除回收站中的全部内容，返回删除数量
+//	@Tags			回收站
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	res.Response	"清空成功"
+//	@Failure		200	{object}	res.Response	"清空失败"
+//	@Router			/trash [delete]
+func (trashHandler *TrashHandler) EmptyTrash() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		userid := ctx.MustGet("userid").(uint)
+
+		purged, err := trashHandler.trashService.EmptyTrash(userid)
+		if err != nil {
+			return res.Response{Err: err}
+		}
+
+		return res.Response{
+			Data: purged,
+			Msg:  commonModel.EMPTY_TRASH_SUCCESS,
+		}
+	})
+}
+
+func parseIDParam(raw string) (uint, error) {
+	id, err := strconv.ParseUint(raw, 10, 64)
+	return uint(id), err
+}
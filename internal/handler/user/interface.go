@@ -60,6 +60,9 @@ type UserHandlerInterface interface {
 	// BindCustomOAuth 绑定自定义 OAuth2 账号
 	BindCustomOAuth() gin.HandlerFunc
 
+	// OAuthExchange 使用 OAuth2 一次性登录码换取令牌
+	OAuthExchange() gin.HandlerFunc
+
 	// GetOAuthInfo 获取 OAuth2 配置信息
 	GetOAuthInfo() gin.HandlerFunc
 
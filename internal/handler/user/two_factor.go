@@ -0,0 +1,312 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	res "github.com/lin-snow/ech0/internal/handler/response"
+	authModel "github.com/lin-snow/ech0/internal/model/auth"
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+)
+
+// TwoFactorLogin 两步验证登录
+//
+//	@Summary		两步验证登录
+//	@Description	使用密码登录返回的登录挑战令牌与 TOTP 验证码或恢复码完成登录，登录过程中完成认证器绑定时同时返回恢复码
+//	@Tags			用户认证
+//	@Accept			json
+//	@Produce		json
+//	@Param			login	body		authModel.TwoFactorLoginDto								true	"登录挑战令牌与验证码"
+//	@Success		200		{object}	res.Response{data=authModel.TwoFactorLoginResultDto}	"登录成功"
+//	@Failure		200		{object}	res.Response											"验证失败"
+//	@Router			/login/2fa [post]
+func (userHandler *UserHandler) TwoFactorLogin() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		var loginDto authModel.TwoFactorLoginDto
+		if err := ctx.ShouldBindJSON(&loginDto); err != nil {
+			return res.Response{
+				Msg: commonModel.INVALID_REQUEST_BODY,
+				Err: err,
+			}
+		}
+
+		result, err := userHandler.userService.VerifyTwoFactorLogin(
+			loginDto.Token,
+			loginDto.Code,
+			getClientInfo(ctx),
+		)
+		if err != nil {
+			setRetryAfter(ctx, err)
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Data: result,
+			Msg:  commonModel.LOGIN_SUCCESS,
+		}
+	})
+}
+
+// TwoFactorLoginSetup 登录过程中绑定认证器
+//
+//	@Summary		登录过程中绑定认证器
+//	@Description	被要求使用两步验证但尚未绑定认证器的用户，使用登录挑战令牌生成 TOTP 密钥与二维码
+//	@Tags			用户认证
+//	@Accept			json
+//	@Produce		json
+//	@Param			setup	body		authModel.TwoFactorLoginSetupDto					true	"登录挑战令牌"
+//	@Success		200		{object}	res.Response{data=authModel.TwoFactorSetupDto}	"生成两步验证密钥成功"
+//	@Failure		200		{object}	res.Response									"生成两步验证密钥失败"
+//	@Router			/login/2fa/setup [post]
+func (userHandler *UserHandler) TwoFactorLoginSetup() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		var setupDto authModel.TwoFactorLoginSetupDto
+		if err := ctx.ShouldBindJSON(&setupDto); err != nil {
+			return res.Response{
+				Msg: commonModel.INVALID_REQUEST_BODY,
+				Err: err,
+			}
+		}
+
+		setup, err := userHandler.userService.SetupTwoFactorForLogin(setupDto.Token)
+		if err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Data: setup,
+			Msg:  commonModel.TWO_FACTOR_SETUP_SUCCESS,
+		}
+	})
+}
+
+// GetTwoFactorStatus 获取两步验证状态
+//
+//	@Summary		获取两步验证状态
+//	@Description	获取当前用户是否已启用两步验证、是否被要求启用以及剩余恢复码数量
+//	@Tags			用户认证
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	res.Response{data=authModel.TwoFactorStatusDto}	"获取两步验证状态成功"
+//	@Failure		200	{object}	res.Response									"获取两步验证状态失败"
+//	@Security		ApiKeyAuth
+//	@Router			/2fa [get]
+func (userHandler *UserHandler) GetTwoFactorStatus() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		userid := ctx.MustGet("userid").(uint)
+
+		status, err := userHandler.userService.GetTwoFactorStatus(userid)
+		if err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Data: status,
+			Msg:  commonModel.GET_TWO_FACTOR_SUCCESS,
+		}
+	})
+}
+
+// SetupTwoFactor 生成两步验证密钥
+//
+//	@Summary		生成两步验证密钥
+//	@Description	生成新的 TOTP 密钥、otpauth URI 与二维码，提交认证器中的验证码后才会启用
+//	@Tags			用户认证
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	res.Response{data=authModel.TwoFactorSetupDto}	"生成两步验证密钥成功"
+//	@Failure		200	{object}	res.Response									"生成两步验证密钥失败"
+//	@Security		ApiKeyAuth
+//	@Router			/2fa/setup [post]
+func (userHandler *UserHandler) SetupTwoFactor() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		userid := ctx.MustGet("userid").(uint)
+
+		setup, err := userHandler.userService.SetupTwoFactor(userid)
+		if err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Data: setup,
+			Msg:  commonModel.TWO_FACTOR_SETUP_SUCCESS,
+		}
+	})
+}
+
+// EnableTwoFactor 启用两步验证
+//
+//	@Summary		启用两步验证
+//	@Description	提交认证器中的验证码以启用两步验证，成功后返回恢复码，恢复码仅显示一次
+//	@Tags			用户认证
+//	@Accept			json
+//	@Produce		json
+//	@Param			code	body		authModel.TwoFactorCodeDto							true	"认证器中的验证码"
+//	@Success		200		{object}	res.Response{data=authModel.RecoveryCodesDto}	"启用两步验证成功"
+//	@Failure		200		{object}	res.Response									"启用两步验证失败"
+//	@Security		ApiKeyAuth
+//	@Router			/2fa/enable [post]
+func (userHandler *UserHandler) EnableTwoFactor() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		userid := ctx.MustGet("userid").(uint)
+
+		var codeDto authModel.TwoFactorCodeDto
+		if err := ctx.ShouldBindJSON(&codeDto); err != nil {
+			return res.Response{
+				Msg: commonModel.INVALID_REQUEST_BODY,
+				Err: err,
+			}
+		}
+
+		codes, err := userHandler.userService.EnableTwoFactor(userid, codeDto.Code)
+		if err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Data: codes,
+			Msg:  commonModel.ENABLE_TWO_FACTOR_SUCCESS,
+		}
+	})
+}
+
+// DisableTwoFactor 关闭两步验证
+//
+//	@Summary		关闭两步验证
+//	@Description	提交验证码或恢复码以关闭两步验证，被管理员要求使用两步验证时无法关闭
+//	@Tags			用户认证
+//	@Accept			json
+//	@Produce		json
+//	@Param			code	body		authModel.TwoFactorCodeDto	true	"验证码或恢复码"
+//	@Success		200		{object}	res.Response				"关闭两步验证成功"
+//	@Failure		200		{object}	res.Response				"关闭两步验证失败"
+//	@Security		ApiKeyAuth
+//	@Router			/2fa/disable [post]
+func (userHandler *UserHandler) DisableTwoFactor() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		userid := ctx.MustGet("userid").(uint)
+
+		var codeDto authModel.TwoFactorCodeDto
+		if err := ctx.ShouldBindJSON(&codeDto); err != nil {
+			return res.Response{
+				Msg: commonModel.INVALID_REQUEST_BODY,
+				Err: err,
+			}
+		}
+
+		if err := userHandler.userService.DisableTwoFactor(userid, codeDto.Code); err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Msg: commonModel.DISABLE_TWO_FACTOR_SUCCESS,
+		}
+	})
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码
+//
+//	@Summary		重新生成恢复码
+//	@Description	提交验证码或恢复码以重新生成恢复码，旧的恢复码全部失效
+//	@Tags			用户认证
+//	@Accept			json
+//	@Produce		json
+//	@Param			code	body		authModel.TwoFactorCodeDto							true	"验证码或恢复码"
+//	@Success		200		{object}	res.Response{data=authModel.RecoveryCodesDto}	"生成恢复码成功"
+//	@Failure		200		{object}	res.Response									"生成恢复码失败"
+//	@Security		ApiKeyAuth
+//	@Router			/2fa/recovery-codes [post]
+func (userHandler *UserHandler) RegenerateRecoveryCodes() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		userid := ctx.MustGet("userid").(uint)
+
+		var codeDto authModel.TwoFactorCodeDto
+		if err := ctx.ShouldBindJSON(&codeDto); err != nil {
+			return res.Response{
+				Msg: commonModel.INVALID_REQUEST_BODY,
+				Err: err,
+			}
+		}
+
+		codes, err := userHandler.userService.RegenerateRecoveryCodes(userid, codeDto.Code)
+		if err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Data: codes,
+			Msg:  commonModel.RECOVERY_CODES_SUCCESS,
+		}
+	})
+}
+
+// UpdateTwoFactorRequired 要求用户使用两步验证
+//
+//	@Summary		要求用户使用两步验证
+//	@Description	要求或取消要求指定用户使用两步验证登录，被要求的用户下次使用密码登录时必须先绑定认证器，接口调用者需拥有用户管理权限
+//	@Tags			用户管理
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		int								true	"用户ID"
+//	@Param			required	body		authModel.TwoFactorRequiredDto	true	"是否要求使用两步验证"
+//	@Success		200			{object}	res.Response					"更新两步验证要求成功"
+//	@Failure		200			{object}	res.Response					"更新两步验证要求失败"
+//	@Security		ApiKeyAuth
+//	@Router			/user/{id}/2fa [put]
+func (userHandler *UserHandler) UpdateTwoFactorRequired() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		userid := ctx.MustGet("userid").(uint)
+
+		id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+		if err != nil {
+			return res.Response{
+				Msg: commonModel.INVALID_PARAMS,
+				Err: err,
+			}
+		}
+
+		var requiredDto authModel.TwoFactorRequiredDto
+		if err := ctx.ShouldBindJSON(&requiredDto); err != nil {
+			return res.Response{
+				Msg: commonModel.INVALID_REQUEST_BODY,
+				Err: err,
+			}
+		}
+
+		if err := userHandler.userService.UpdateTwoFactorRequired(
+			userid,
+			uint(id),
+			requiredDto.Required,
+		); err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Msg: commonModel.TWO_FACTOR_REQUIRED_SUCCESS,
+		}
+	})
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"net/url"
 	"strconv"
 	"strings"
@@ -10,6 +11,7 @@ import (
 	authModel "github.com/lin-snow/ech0/internal/model/auth"
 	commonModel "github.com/lin-snow/ech0/internal/model/common"
 	model "github.com/lin-snow/ech0/internal/model/user"
+	"github.com/lin-snow/ech0/internal/ratelimit"
 	service "github.com/lin-snow/ech0/internal/service/user"
 )
 
@@ -27,13 +29,13 @@ func NewUserHandler(userService service.UserServiceInterface) *UserHandler {
 // Login 用户登录
 //
 //	@Summary		用户登录接口
-//	@Description	用户通过用户名和密码登录，返回 JWT Token
+//	@Description	用户通过用户名和密码登录，返回短期访问令牌与刷新令牌；启用两步验证时返回登录挑战令牌，需再调用 /login/2fa 完成登录
 //	@Tags			用户认证
 //	@Accept			application/json
 //	@Produce		application/json
-//	@Param			login	body		authModel.LoginDto	true	"登录请求体"
-//	@Success		200		{object}	res.Response		"登录成功，返回JWT Token"
-//	@Failure		200		{object}	res.Response		"登录失败，返回错误信息"
+//	@Param			login	body		authModel.LoginDto								true	"登录请求体"
+//	@Success		200		{object}	res.Response{data=authModel.LoginResultDto}	"登录成功，返回令牌对或两步验证登录挑战"
+//	@Failure		200		{object}	res.Response								"登录失败，返回错误信息"
 //	@Router			/login [post]
 func (userHandler *UserHandler) Login() gin.HandlerFunc {
 	return res.Execute(func(ctx *gin.Context) res.Response {
@@ -47,18 +49,19 @@ func (userHandler *UserHandler) Login() gin.HandlerFunc {
 		}
 
 		// 调用 Service 层处理登陆
-		token, err := userHandler.userService.Login(&loginDto)
+		result, err := userHandler.userService.Login(&loginDto, getClientInfo(ctx))
 		if err != nil {
+			setRetryAfter(ctx, err)
 			return res.Response{
 				Msg: "",
 				Err: err,
 			}
 		}
 
-		// 返回成功响应， 包含 JWT Token
+		// 返回令牌对，需要两步验证时返回登录挑战
 		return res.Response{
-			Data: token,
-			Msg:  commonModel.LOGIN_SUCCESS,
+			Data: result,
+			Msg:  loginResultMsg(result),
 		}
 	})
 }
@@ -175,6 +178,71 @@ func (userHandler *UserHandler) UpdateUserAdmin() gin.HandlerFunc {
 	})
 }
 
+// UpdateUserRole 修改用户角色
+//
+//	@Summary		修改用户角色
+//	@Description	将指定用户设为管理员、编辑或成员，不能修改自己或站长的角色，接口调用者需拥有用户管理权限
+//	@Tags			用户管理
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int					true	"用户ID"
+//	@Param			role	body		model.UserRoleDto	true	"用户角色"
+//	@Success		200		{object}	res.Response		"更新用户角色成功"
+//	@Failure		200		{object}	res.Response		"更新用户角色失败"
+//	@Security		ApiKeyAuth
+//	@Router			/user/{id}/role [put]
+func (userHandler *UserHandler) UpdateUserRole() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		userid := ctx.MustGet("userid").(uint)
+
+		id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+		if err != nil {
+			return res.Response{
+				Msg: commonModel.INVALID_PARAMS,
+				Err: err,
+			}
+		}
+
+		var roleDto model.UserRoleDto
+		if err := ctx.ShouldBindJSON(&roleDto); err != nil {
+			return res.Response{
+				Msg: commonModel.INVALID_REQUEST_BODY,
+				Err: err,
+			}
+		}
+
+		if err := userHandler.userService.UpdateUserRole(userid, uint(id), roleDto.Role); err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Msg: commonModel.UPDATE_USER_ROLE_SUCCESS,
+		}
+	})
+}
+
+// GetRoles 获取角色权限表
+//
+//	@Summary		获取角色权限表
+//	@Description	获取全部角色及每个角色拥有的权限
+//	@Tags			用户管理
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	res.Response{data=[]model.RoleDto}	"获取角色权限成功"
+//	@Security		ApiKeyAuth
+//	@Router			/roles [get]
+func (userHandler *UserHandler) GetRoles() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		return res.Response{
+			Data: userHandler.userService.GetRoles(),
+			Msg:  commonModel.GET_ROLES_SUCCESS,
+		}
+	})
+}
+
 // GetAllUsers 获取所有用户
 //
 //	@Summary		获取所有用户
@@ -600,6 +668,45 @@ func (userHandler *UserHandler) BindCustomOAuth() gin.HandlerFunc {
 	})
 }
 
+// OAuthExchange 使用 OAuth2 一次性登录码换取令牌
+//
+//	@Summary		OAuth2 登录换取令牌
+//	@Description	OAuth2 回调重定向到前端时附带一次性登录码 login_code，前端通过此接口换取访问令牌与刷新令牌，登录码只能使用一次
+//	@Tags			用户认证
+//	@Accept			json
+//	@Produce		json
+//	@Param			exchange	body		authModel.OAuthExchangeDto					true	"一次性登录码"
+//	@Success		200			{object}	res.Response{data=authModel.LoginResultDto}	"登录成功，返回令牌对或两步验证登录挑战"
+//	@Failure		200			{object}	res.Response								"登录码无效或已过期"
+//	@Router			/oauth/exchange [post]
+func (userHandler *UserHandler) OAuthExchange() gin.HandlerFunc {
+	return res.Execute(func(ctx *gin.Context) res.Response {
+		var exchangeDto authModel.OAuthExchangeDto
+		if err := ctx.ShouldBindJSON(&exchangeDto); err != nil {
+			return res.Response{
+				Msg: commonModel.INVALID_REQUEST_BODY,
+				Err: err,
+			}
+		}
+
+		result, err := userHandler.userService.ExchangeOAuthLoginCode(
+			exchangeDto.Code,
+			getClientInfo(ctx),
+		)
+		if err != nil {
+			return res.Response{
+				Msg: "",
+				Err: err,
+			}
+		}
+
+		return res.Response{
+			Data: result,
+			Msg:  loginResultMsg(result),
+		}
+	})
+}
+
 // GetOAuthInfo 获取 OAuth2 配置信息
 func (userHandler *UserHandler) GetOAuthInfo() gin.HandlerFunc {
 	return res.Execute(func(ctx *gin.Context) res.Response {
@@ -628,6 +735,34 @@ func (userHandler *UserHandler) GetOAuthInfo() gin.HandlerFunc {
 	})
 }
 
+// loginResultMsg 返回登录结果对应的提示信息，需要两步验证时提示继续完成第二步验证
+func loginResultMsg(result authModel.LoginResultDto) string {
+	switch {
+	case result.TwoFactorSetup:
+		return commonModel.TWO_FACTOR_SETUP_REQUIRED
+	case result.TwoFactorRequired:
+		return commonModel.TWO_FACTOR_REQUIRED
+	default:
+		return commonModel.LOGIN_SUCCESS
+	}
+}
+
+// getClientInfo 提取登录客户端的 IP 与 User-Agent，用于记录会话设备信息
+func getClientInfo(ctx *gin.Context) authModel.ClientInfo {
+	return authModel.ClientInfo{
+		IP:        ctx.ClientIP(),
+		UserAgent: ctx.Request.UserAgent(),
+	}
+}
+
+// setRetryAfter 登录因连续失败被锁定时设置 Retry-After 响应头
+func setRetryAfter(ctx *gin.Context, err error) {
+	var locked *ratelimit.LockedError
+	if errors.As(err, &locked) {
+		ctx.Header("Retry-After", strconv.Itoa(ratelimit.Seconds(locked.RetryAfter)))
+	}
+}
+
 func getOriginAndRPID(ctx *gin.Context) (origin string, rpID string) {
 	origin = strings.TrimSpace(ctx.GetHeader("Origin"))
 	if origin == "" {
@@ -684,16 +819,17 @@ func (userHandler *UserHandler) PasskeyLoginFinish() gin.HandlerFunc {
 		}
 		origin, rpID := getOriginAndRPID(ctx)
 
-		token, err := userHandler.userService.PasskeyLoginFinish(
+		result, err := userHandler.userService.PasskeyLoginFinish(
 			rpID,
 			origin,
 			req.Nonce,
 			req.Credential,
+			getClientInfo(ctx),
 		)
 		if err != nil {
 			return res.Response{Err: err}
 		}
-		return res.Response{Data: token}
+		return res.Response{Data: result, Msg: loginResultMsg(result)}
 	})
 }
 
@@ -0,0 +1,136 @@
+package importer
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	fileUtil "github.com/lin-snow/ech0/internal/util/file"
+)
+
+// PrepareInput 准备待解析的导出数据，ZIP 与 tar.gz 归档会解压到 workDir，其他文件和目录原样返回
+func PrepareInput(path, workDir string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if info.IsDir() {
+		return path, nil
+	}
+
+	lower := strings.ToLower(path)
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		if err := fileUtil.UnzipFile(path, workDir); err != nil {
+			return "", err
+		}
+		return workDir, nil
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		if err := untarGz(path, workDir); err != nil {
+			return "", err
+		}
+		return workDir, nil
+	default:
+		return path, nil
+	}
+}
+
+// untarGz 解压 tar.gz 归档，Mastodon 的归档使用该格式
+func untarGz(src, dest string) error {
+	file, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return fmt.Errorf("打开 tar.gz 文件失败: %w", err)
+	}
+	defer gz.Close()
+
+	dest = filepath.Clean(dest)
+	if err := os.MkdirAll(dest, 0o755); err != nil {
+		return err
+	}
+
+	reader := tar.NewReader(gz)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(dest, header.Name)
+		// 防止路径穿越攻击
+		if !isWithinDir(dest, target) {
+			return fmt.Errorf("无效的文件路径: %s", header.Name)
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return err
+			}
+			if err := writeFile(target, reader); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// writeFile 将数据写入文件
+func writeFile(path string, reader io.Reader) error {
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, reader)
+	return err
+}
+
+// findFile 在目录中查找第一个满足条件的文件，path 本身是文件时直接判断
+func findFile(path string, match func(name string) bool) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		if match(filepath.Base(path)) {
+			return path, nil
+		}
+		return "", nil
+	}
+
+	var found string
+	err = filepath.WalkDir(path, func(current string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() && match(entry.Name()) {
+			found = current
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	return found, err
+}
+
+// isWithinDir 判断路径是否位于目录之内，防止导出数据中的相对路径访问目录之外的文件
+func isWithinDir(dir, path string) bool {
+	relative, err := filepath.Rel(dir, path)
+	return err == nil && relative != ".." && !strings.HasPrefix(relative, ".."+string(os.PathSeparator))
+}
@@ -0,0 +1,99 @@
+package importer
+
+import (
+	"errors"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+)
+
+// Item 从其他平台解析出的一条内容
+type Item struct {
+	SourceID   string    // 在来源平台中的 ID
+	ReplyTo    string    // 回复的内容在来源平台中的 ID，为空表示不是回复
+	Content    string    // Markdown 或纯文本内容
+	CreatedAt  time.Time // 原始发布时间
+	Visibility string    // 映射后的 Ech0 可见性
+	Tags       []string  // 标签名称，不含 #
+	Media      []Media   // 附带的图片
+}
+
+// Media 内容附带的媒体文件
+type Media struct {
+	Filename string                        // 文件名，用于推断文件类型
+	Open     func() (io.ReadCloser, error) // 打开媒体文件，来自归档中的本地文件或远程地址
+}
+
+// Options 解析选项
+type Options struct {
+	BaseURL string // 来源站点地址，用于下载 API 导出数据中引用的媒体文件
+}
+
+// Source 导入来源，负责将其他平台的导出数据解析为统一的 Item
+type Source interface {
+	// Name 来源名称
+	Name() string
+
+	// Parse 解析文件或目录中的导出数据
+	Parse(path string, options Options) ([]Item, error)
+}
+
+var (
+	sourcesMu sync.RWMutex
+	sources   = make(map[string]Source)
+)
+
+// Register 注册导入来源，同名来源会被覆盖
+func Register(source Source) {
+	sourcesMu.Lock()
+	defer sourcesMu.Unlock()
+	sources[source.Name()] = source
+}
+
+// GetSource 获取指定名称的导入来源
+func GetSource(name string) (Source, bool) {
+	sourcesMu.RLock()
+	defer sourcesMu.RUnlock()
+	source, ok := sources[name]
+	return source, ok
+}
+
+// SourceNames 获取所有已注册的导入来源名称
+func SourceNames() []string {
+	sourcesMu.RLock()
+	defer sourcesMu.RUnlock()
+
+	names := make([]string, 0, len(sources))
+	for name := range sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Parse 使用指定来源解析导出数据，结果按发布时间正序排列，保证回复排在被回复的内容之后
+func Parse(sourceName, path string, options Options) ([]Item, error) {
+	source, ok := GetSource(sourceName)
+	if !ok {
+		return nil, errors.New(commonModel.IMPORT_SOURCE_INVALID)
+	}
+
+	items, err := source.Parse(path, options)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].CreatedAt.Before(items[j].CreatedAt)
+	})
+	return items, nil
+}
+
+func init() {
+	Register(&MemosSource{})
+	Register(&MastodonSource{})
+	Register(&TwitterSource{})
+}
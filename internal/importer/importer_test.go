@@ -0,0 +1,500 @@
+package importer
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"image"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	echoModel "github.com/lin-snow/ech0/internal/model/echo"
+	model "github.com/lin-snow/ech0/internal/model/importer"
+)
+
+// pngBytes 生成一张 1x1 的 PNG 图片
+func pngBytes(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 1, 1))); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// writeFiles 在目录中写入文件，文件名可以包含子目录
+func writeFiles(t *testing.T, dir string, files map[string][]byte) {
+	t.Helper()
+	for name, data := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+}
+
+// readMedia 打开媒体并读取全部内容
+func readMedia(media Media) ([]byte, error) {
+	reader, err := media.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+// bySourceID 按来源 ID 索引解析结果
+func bySourceID(items []Item) map[string]Item {
+	result := make(map[string]Item, len(items))
+	for _, item := range items {
+		result[item.SourceID] = item
+	}
+	return result
+}
+
+func TestNormalizeTags(t *testing.T) {
+	long := strings.Repeat("长", maxTagLength+5)
+
+	tests := map[string]struct {
+		tags []string
+		want []string
+	}{
+		"去掉 # 与空白": {tags: []string{" #go ", "日记"}, want: []string{"go", "日记"}},
+		"去重":       {tags: []string{"go", "#go", "Go"}, want: []string{"go", "Go"}},
+		"忽略空标签":    {tags: []string{"", "#", "  "}, want: []string{}},
+		"按字符截断":    {tags: []string{long}, want: []string{strings.Repeat("长", maxTagLength)}},
+	}
+
+	for name, tt := range tests {
+		if got := normalizeTags(tt.tags); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: normalizeTags = %q, want %q", name, got, tt.want)
+		}
+	}
+}
+
+func TestExtractHashtags(t *testing.T) {
+	tests := map[string]struct {
+		content string
+		want    []string
+	}{
+		"行首与行中":       {content: "#日记 今天 #go_lang", want: []string{"日记", "go_lang"}},
+		"Markdown 标题": {content: "# 标题\n正文", want: nil},
+		"紧跟文字不算标签":    {content: "issue#12", want: nil},
+		"多级标签":        {content: "记录 #work/ech0", want: []string{"work/ech0"}},
+	}
+
+	for name, tt := range tests {
+		if got := extractHashtags(tt.content); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: extractHashtags = %q, want %q", name, got, tt.want)
+		}
+	}
+}
+
+func TestHTMLToText(t *testing.T) {
+	tests := map[string]struct {
+		html string
+		want string
+	}{
+		"段落与换行": {html: "<p>第一段</p><p>第二行<br>第三行</p>", want: "第一段\n\n第二行\n第三行"},
+		"链接使用完整地址": {
+			html: `<p>看 <a href="https://example.com/a/very/long/path">example.com/a/very/…</a></p>`,
+			want: "看 https://example.com/a/very/long/path",
+		},
+		"话题标签与提及保留文本": {
+			html: `<p><a href="https://m.example/tags/go" class="mention hashtag" rel="tag">#<span>go</span></a> ` +
+				`<span class="h-card"><a href="https://m.example/@bob" class="u-url mention">@<span>bob</span></a></span></p>`,
+			want: "#go @bob",
+		},
+		"合并多余空行": {html: "<p>a</p><p></p><p></p><p>b</p>", want: "a\n\nb"},
+		"转义字符":   {html: "<p>1 &lt; 2 &amp;&amp; 3</p>", want: "1 < 2 && 3"},
+	}
+
+	for name, tt := range tests {
+		if got := htmlToText(tt.html); got != tt.want {
+			t.Errorf("%s: htmlToText = %q, want %q", name, got, tt.want)
+		}
+	}
+}
+
+func TestMastodonSource(t *testing.T) {
+	dir := t.TempDir()
+	image := pngBytes(t)
+	writeFiles(t, dir, map[string][]byte{
+		"media_attachments/files/1.png": image,
+		"outbox.json": []byte(`{"orderedItems": [
+			{"type": "Create", "object": {
+				"id": "https://m.example/statuses/1", "type": "Note",
+				"content": "<p>Hello <a href=\"https://m.example/tags/go\" class=\"mention hashtag\" rel=\"tag\">#<span>go</span></a></p>",
+				"published": "2024-01-02T03:04:05Z",
+				"to": ["https://www.w3.org/ns/activitystreams#Public"],
+				"cc": ["https://m.example/users/alice/followers"],
+				"attachment": [
+					{"mediaType": "image/png", "url": "https://files.m.example/media_attachments/files/1.png"},
+					{"mediaType": "image/png", "url": "/media_attachments/files/missing.png"},
+					{"mediaType": "video/mp4", "url": "/media_attachments/files/2.mp4"}
+				],
+				"tag": [{"type": "Hashtag", "name": "#go"}, {"type": "Mention", "name": "@bob"}]
+			}},
+			{"type": "Announce", "object": "https://other.example/statuses/9"},
+			{"type": "Create", "object": {
+				"id": "https://m.example/statuses/2", "type": "Note", "summary": "剧透",
+				"content": "<p>回复</p>", "inReplyTo": "https://m.example/statuses/1",
+				"published": "2024-01-01T00:00:00Z",
+				"to": ["https://m.example/users/alice/followers"], "cc": ["as:Public"]
+			}},
+			{"type": "Create", "object": {
+				"id": "https://m.example/statuses/3", "type": "Note", "content": "<p>仅关注者</p>",
+				"to": ["https://m.example/users/alice/followers"]
+			}},
+			{"type": "Create", "object": {
+				"id": "https://m.example/statuses/4", "type": "Note", "content": "<p>私信</p>",
+				"to": ["https://other.example/users/bob"]
+			}},
+			{"type": "Create", "object": {"id": "https://m.example/questions/5", "type": "Question"}}
+		]}`),
+	})
+
+	items, err := (&MastodonSource{}).Parse(dir, Options{})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(items) != 4 {
+		t.Fatalf("got %d items", len(items))
+	}
+
+	got := bySourceID(items)
+	tests := map[string]struct {
+		content    string
+		visibility string
+		replyTo    string
+		tags       []string
+		media      int
+	}{
+		"https://m.example/statuses/1": {
+			content: "Hello #go", visibility: echoModel.VisibilityPublic, tags: []string{"go"}, media: 2,
+		},
+		"https://m.example/statuses/2": {
+			content: "剧透\n\n回复", visibility: echoModel.VisibilityUnlisted, replyTo: "https://m.example/statuses/1",
+		},
+		"https://m.example/statuses/3": {content: "仅关注者", visibility: echoModel.VisibilityFollowers},
+		"https://m.example/statuses/4": {content: "私信", visibility: echoModel.VisibilityPrivate},
+	}
+	for id, tt := range tests {
+		item := got[id]
+		if item.Content != tt.content || item.Visibility != tt.visibility || item.ReplyTo != tt.replyTo ||
+			!slices.Equal(item.Tags, tt.tags) || len(item.Media) != tt.media {
+			t.Errorf("%s: item = %+v", id, item)
+		}
+	}
+
+	first := got["https://m.example/statuses/1"]
+	if want := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC); !first.CreatedAt.Equal(want) {
+		t.Errorf("created at = %v", first.CreatedAt)
+	}
+	// 完整 URL 优先读取归档中的同路径文件，找不到的相对路径打开时报错
+	if data, err := readMedia(first.Media[0]); err != nil || !bytes.Equal(data, image) {
+		t.Errorf("local media = %d bytes, %v", len(data), err)
+	}
+	if _, err := readMedia(first.Media[1]); err == nil || !strings.HasPrefix(err.Error(), commonModel.IMPORT_MEDIA_UNAVAILABLE) {
+		t.Errorf("missing media err = %v", err)
+	}
+
+	if _, err := (&MastodonSource{}).Parse(t.TempDir(), Options{}); err == nil || err.Error() != commonModel.IMPORT_DATA_NOT_FOUND {
+		t.Errorf("empty archive err = %v", err)
+	}
+}
+
+func TestTwitterSource(t *testing.T) {
+	dir := t.TempDir()
+	image := pngBytes(t)
+	writeFiles(t, dir, map[string][]byte{
+		"data/tweets_media/1-abc.png": image,
+		"data/tweets.js": []byte(`window.YTD.tweets.part0 = [
+			{"tweet": {
+				"id_str": "1",
+				"full_text": "Hi #go https://t.co/a https://t.co/img &amp; more",
+				"created_at": "Wed Jan 03 04:05:06 +0000 2024",
+				"entities": {
+					"hashtags": [{"text": "go"}],
+					"urls": [{"url": "https://t.co/a", "expanded_url": "https://example.com/a"}]
+				},
+				"extended_entities": {"media": [
+					{"url": "https://t.co/img", "media_url_https": "https://pbs.twimg.com/media/abc.png", "type": "photo"}
+				]}
+			}},
+			{"tweet": {"id_str": "2", "full_text": "RT @bob: hello", "created_at": "Wed Jan 03 05:00:00 +0000 2024"}}
+		]`),
+		// 数据较多时拆分为多个文件，旧版归档不包裹 tweet 字段
+		"data/tweets-part1.js": []byte(`window.YTD.tweets.part1 = [
+			{
+				"id_str": "3",
+				"full_text": "回复 https://t.co/vid",
+				"created_at": "Thu Jan 04 00:00:00 +0000 2024",
+				"in_reply_to_status_id_str": "1",
+				"entities": {"media": [
+					{"url": "https://t.co/vid", "expanded_url": "https://x.com/alice/status/3/video/1", "type": "video"}
+				]}
+			}
+		]`),
+	})
+
+	items, err := (&TwitterSource{}).Parse(dir, Options{})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("got %d items", len(items))
+	}
+
+	got := bySourceID(items)
+	first := got["1"]
+	if first.Content != "Hi #go https://example.com/a  & more" || !slices.Equal(first.Tags, []string{"go"}) ||
+		first.Visibility != echoModel.VisibilityPublic {
+		t.Errorf("tweet 1 = %+v", first)
+	}
+	if want := time.Date(2024, 1, 3, 4, 5, 6, 0, time.UTC); !first.CreatedAt.Equal(want) {
+		t.Errorf("created at = %v", first.CreatedAt)
+	}
+	if len(first.Media) != 1 {
+		t.Fatalf("tweet 1 media = %d", len(first.Media))
+	}
+	if data, err := readMedia(first.Media[0]); err != nil || !bytes.Equal(data, image) {
+		t.Errorf("local media = %d bytes, %v", len(data), err)
+	}
+
+	reply := got["3"]
+	if reply.Content != "回复 https://x.com/alice/status/3/video/1" || reply.ReplyTo != "1" || len(reply.Media) != 0 {
+		t.Errorf("tweet 3 = %+v", reply)
+	}
+}
+
+func TestMemosSourceJSON(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string][]byte{
+		"memos.json": []byte(`{"memos": [
+			{
+				"name": "memos/1", "content": "今天 #日记", "visibility": "PUBLIC", "state": "NORMAL",
+				"createTime": "2024-01-01T00:00:00Z", "displayTime": "2024-01-05T00:00:00Z",
+				"attachments": [
+					{"name": "attachments/9", "filename": "a.png", "type": "image/png"},
+					{"name": "attachments/10", "filename": "doc.pdf", "type": "application/pdf"},
+					{"filename": "b.jpg", "externalLink": "https://cdn.example/b.jpg"}
+				]
+			},
+			{"name": "memos/2", "parent": "memos/1", "content": "评论", "visibility": "PROTECTED", "createTime": "2024-01-06T00:00:00Z"},
+			{"name": "memos/3", "content": "归档", "visibility": "PUBLIC", "state": "ARCHIVED", "tags": ["旧"], "createTime": "2024-01-07T00:00:00Z"},
+			{"id": 4, "content": "v0", "visibility": "PRIVATE", "createdTs": 1704067200}
+		]}`),
+	})
+
+	items, err := (&MemosSource{}).Parse(dir, Options{BaseURL: "https://memos.example/"})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	got := bySourceID(items)
+	tests := map[string]struct {
+		visibility string
+		replyTo    string
+		tags       []string
+		createdAt  time.Time
+		media      []string
+	}{
+		"memos/1": {
+			visibility: echoModel.VisibilityPublic,
+			tags:       []string{"日记"},
+			createdAt:  time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC),
+			media:      []string{"a.png", "b.jpg"},
+		},
+		"memos/2": {visibility: echoModel.VisibilityMembers, replyTo: "memos/1", createdAt: time.Date(2024, 1, 6, 0, 0, 0, 0, time.UTC)},
+		"memos/3": {visibility: echoModel.VisibilityPrivate, tags: []string{"旧"}, createdAt: time.Date(2024, 1, 7, 0, 0, 0, 0, time.UTC)},
+		"4":       {visibility: echoModel.VisibilityPrivate, createdAt: time.Unix(1704067200, 0)},
+	}
+	for id, tt := range tests {
+		item, ok := got[id]
+		if !ok {
+			t.Errorf("%s: missing", id)
+			continue
+		}
+		var media []string
+		for _, m := range item.Media {
+			media = append(media, m.Filename)
+		}
+		if item.Visibility != tt.visibility || item.ReplyTo != tt.replyTo || !slices.Equal(item.Tags, tt.tags) ||
+			!item.CreatedAt.Equal(tt.createdAt) || !slices.Equal(media, tt.media) {
+			t.Errorf("%s: item = %+v", id, item)
+		}
+	}
+
+	// 没有来源站点地址时无法下载未提供外部链接的文件
+	items, err = (&MemosSource{}).Parse(filepath.Join(dir, "memos.json"), Options{})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	first := bySourceID(items)["memos/1"]
+	if _, err := readMedia(first.Media[0]); err == nil || !strings.HasPrefix(err.Error(), commonModel.IMPORT_MEDIA_UNAVAILABLE) {
+		t.Errorf("media without base url err = %v", err)
+	}
+}
+
+func TestMemosSourceDatabase(t *testing.T) {
+	dir := t.TempDir()
+	image := pngBytes(t)
+	writeFiles(t, dir, map[string][]byte{"assets/p.png": image})
+
+	dbPath := filepath.Join(dir, "memos_prod.db")
+	db, err := gorm.Open(sqlite.Open(dbPath), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	for _, statement := range []string{
+		"CREATE TABLE memo (id INTEGER PRIMARY KEY, created_ts BIGINT, content TEXT, visibility TEXT, row_status TEXT, payload TEXT)",
+		"CREATE TABLE memo_relation (memo_id INTEGER, related_memo_id INTEGER, type TEXT)",
+		"CREATE TABLE resource (id INTEGER PRIMARY KEY, filename TEXT, type TEXT, blob BLOB, internal_path TEXT, external_link TEXT, memo_id INTEGER)",
+		`INSERT INTO memo VALUES (1, 1704067200, '第一条 #正文标签', 'PUBLIC', 'NORMAL', '{"tags":["标签"]}')`,
+		"INSERT INTO memo VALUES (2, 1704153600, '评论', 'PROTECTED', 'NORMAL', '')",
+		"INSERT INTO memo VALUES (3, 1704240000, '#私密', 'PRIVATE', 'ARCHIVED', NULL)",
+		"INSERT INTO memo_relation VALUES (2, 1, 'COMMENT'), (3, 1, 'REFERENCE')",
+		"INSERT INTO resource VALUES (2, 'p.png', 'image/png', NULL, '/var/opt/memos/assets/p.png', '', 2)",
+		"INSERT INTO resource VALUES (3, 'notes.txt', 'text/plain', NULL, '', '', 1)",
+		"INSERT INTO resource VALUES (4, 'gone.png', 'image/png', NULL, '/etc/passwd', '', 3)",
+	} {
+		if err := db.Exec(statement).Error; err != nil {
+			t.Fatalf("exec %q: %v", statement, err)
+		}
+	}
+	if err := db.Exec("INSERT INTO resource VALUES (1, 'blob.png', 'image/png', ?, '', '', 1)", image).Error; err != nil {
+		t.Fatalf("insert blob: %v", err)
+	}
+	if sqlDB, err := db.DB(); err == nil {
+		_ = sqlDB.Close()
+	}
+
+	items, err := (&MemosSource{}).Parse(dbPath, Options{})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	got := bySourceID(items)
+	tests := map[string]struct {
+		visibility string
+		replyTo    string
+		tags       []string
+		media      int
+	}{
+		"1": {visibility: echoModel.VisibilityPublic, tags: []string{"标签"}, media: 1},
+		"2": {visibility: echoModel.VisibilityMembers, replyTo: "1", media: 1},
+		"3": {visibility: echoModel.VisibilityPrivate, tags: []string{"私密"}, media: 1},
+	}
+	for id, tt := range tests {
+		item := got[id]
+		if item.Visibility != tt.visibility || item.ReplyTo != tt.replyTo ||
+			!slices.Equal(item.Tags, tt.tags) || len(item.Media) != tt.media {
+			t.Errorf("%s: item = %+v", id, item)
+		}
+	}
+	if !got["2"].CreatedAt.Equal(time.Unix(1704153600, 0)) {
+		t.Errorf("created at = %v", got["2"].CreatedAt)
+	}
+
+	// 数据库中的图片与数据目录中的文件都能读取，目录之外的路径不会被访问
+	for id, wantErr := range map[string]bool{"1": false, "2": false, "3": true} {
+		data, err := readMedia(got[id].Media[0])
+		if (err != nil) != wantErr || (!wantErr && !bytes.Equal(data, image)) {
+			t.Errorf("%s: media = %d bytes, %v", id, len(data), err)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	if _, err := Parse("wordpress", t.TempDir(), Options{}); err == nil || err.Error() != commonModel.IMPORT_SOURCE_INVALID {
+		t.Errorf("unknown source err = %v", err)
+	}
+	if names := SourceNames(); !slices.Equal(names, []string{model.SourceMastodon, model.SourceMemos, model.SourceTwitter}) {
+		t.Errorf("sources = %v", names)
+	}
+
+	// 结果按发布时间正序排列，回复排在被回复的内容之后
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string][]byte{"memos.json": []byte(`[
+		{"name": "memos/2", "parent": "memos/1", "content": "b", "createTime": "2024-02-01T00:00:00Z"},
+		{"name": "memos/3", "content": "c", "createTime": "2024-03-01T00:00:00Z"},
+		{"name": "memos/1", "content": "a", "createTime": "2024-01-01T00:00:00Z"}
+	]`)})
+	items, err := Parse(model.SourceMemos, dir, Options{})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	var ids []string
+	for _, item := range items {
+		ids = append(ids, item.SourceID)
+	}
+	if want := []string{"memos/1", "memos/2", "memos/3"}; !slices.Equal(ids, want) {
+		t.Errorf("order = %v, want %v", ids, want)
+	}
+}
+
+func TestPrepareInput(t *testing.T) {
+	// tarGz 生成包含指定文件的 tar.gz 归档
+	tarGz := func(files map[string]string) string {
+		t.Helper()
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		tw := tar.NewWriter(gz)
+		for name, content := range files {
+			if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+				t.Fatalf("tar header: %v", err)
+			}
+			if _, err := tw.Write([]byte(content)); err != nil {
+				t.Fatalf("tar write: %v", err)
+			}
+		}
+		if err := tw.Close(); err != nil {
+			t.Fatalf("tar close: %v", err)
+		}
+		if err := gz.Close(); err != nil {
+			t.Fatalf("gzip close: %v", err)
+		}
+		path := filepath.Join(t.TempDir(), "archive.tar.gz")
+		if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+			t.Fatalf("write archive: %v", err)
+		}
+		return path
+	}
+
+	workDir := filepath.Join(t.TempDir(), "work")
+	input, err := PrepareInput(tarGz(map[string]string{"archive/outbox.json": "{}"}), workDir)
+	if err != nil {
+		t.Fatalf("prepare: %v", err)
+	}
+	if input != workDir || !fileExists(filepath.Join(workDir, "archive", "outbox.json")) {
+		t.Errorf("extracted to %q", input)
+	}
+
+	// 防止路径穿越
+	if _, err := PrepareInput(tarGz(map[string]string{"../evil.txt": "x"}), filepath.Join(t.TempDir(), "work")); err == nil {
+		t.Error("path traversal accepted")
+	}
+
+	// 目录与其他文件原样返回
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string][]byte{"memos.json": []byte("[]")})
+	for _, path := range []string{dir, filepath.Join(dir, "memos.json")} {
+		if input, err := PrepareInput(path, workDir); err != nil || input != path {
+			t.Errorf("%s: prepare = %q, %v", path, input, err)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package importer
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	echoModel "github.com/lin-snow/ech0/internal/model/echo"
+	fediverseModel "github.com/lin-snow/ech0/internal/model/fediverse"
+	model "github.com/lin-snow/ech0/internal/model/importer"
+)
+
+// MastodonSource 从 Mastodon 归档导入，解析其中的 outbox.json 与 media_attachments 目录
+type MastodonSource struct{}
+
+// mastodonActivity outbox.json 中的一条 Activity
+type mastodonActivity struct {
+	Type   string          `json:"type"`
+	Object json.RawMessage `json:"object"`
+}
+
+// mastodonNote Activity 中的 Note 对象
+type mastodonNote struct {
+	ID         string   `json:"id"`
+	Type       string   `json:"type"`
+	Summary    string   `json:"summary"`
+	Content    string   `json:"content"`
+	InReplyTo  string   `json:"inReplyTo"`
+	Published  string   `json:"published"`
+	To         []string `json:"to"`
+	Cc         []string `json:"cc"`
+	Attachment []struct {
+		MediaType string `json:"mediaType"`
+		URL       string `json:"url"`
+	} `json:"attachment"`
+	Tag []struct {
+		Type string `json:"type"`
+		Name string `json:"name"`
+	} `json:"tag"`
+}
+
+// Name 来源名称
+func (source *MastodonSource) Name() string {
+	return model.SourceMastodon
+}
+
+// Parse 解析 Mastodon 归档，只导入自己发布的嘟文，转嘟会被忽略
+func (source *MastodonSource) Parse(path string, options Options) ([]Item, error) {
+	file, err := findFile(path, func(name string) bool {
+		return name == "outbox.json"
+	})
+	if err != nil {
+		return nil, err
+	}
+	if file == "" {
+		return nil, errors.New(commonModel.IMPORT_DATA_NOT_FOUND)
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var outbox struct {
+		OrderedItems []mastodonActivity `json:"orderedItems"`
+	}
+	if err := json.Unmarshal(data, &outbox); err != nil {
+		return nil, err
+	}
+
+	baseDir := filepath.Dir(file)
+	items := make([]Item, 0, len(outbox.OrderedItems))
+	for _, activity := range outbox.OrderedItems {
+		if activity.Type != fediverseModel.ActivityTypeCreate {
+			continue
+		}
+
+		var note mastodonNote
+		if err := json.Unmarshal(activity.Object, &note); err != nil || note.Type != "Note" {
+			continue
+		}
+		items = append(items, mastodonItem(note, baseDir))
+	}
+	return items, nil
+}
+
+// mastodonItem 将 Note 转换为 Item
+func mastodonItem(note mastodonNote, baseDir string) Item {
+	content := htmlToText(note.Content)
+	// 内容警告作为正文的第一段保留
+	if summary := strings.TrimSpace(note.Summary); summary != "" {
+		content = summary + "\n\n" + content
+	}
+
+	createdAt, _ := time.Parse(time.RFC3339, note.Published)
+
+	var tags []string
+	for _, tag := range note.Tag {
+		if tag.Type == "Hashtag" {
+			tags = append(tags, tag.Name)
+		}
+	}
+
+	item := Item{
+		SourceID:   note.ID,
+		ReplyTo:    note.InReplyTo,
+		Content:    content,
+		CreatedAt:  createdAt,
+		Visibility: mastodonVisibility(note),
+		Tags:       normalizeTags(tags),
+	}
+
+	for _, attachment := range note.Attachment {
+		if !isImageFile(attachment.MediaType, attachment.URL) {
+			continue
+		}
+		item.Media = append(item.Media, mastodonMedia(baseDir, attachment.URL))
+	}
+	return item
+}
+
+// mastodonVisibility 根据收件人判断可见性：公开、不公开列出、仅关注者，私信设为私密
+func mastodonVisibility(note mastodonNote) string {
+	if containsAddress(note.To, fediverseModel.PublicAddress) {
+		return echoModel.VisibilityPublic
+	}
+	if containsAddress(note.Cc, fediverseModel.PublicAddress) {
+		return echoModel.VisibilityUnlisted
+	}
+	for _, address := range note.To {
+		if strings.HasSuffix(address, "/followers") {
+			return echoModel.VisibilityFollowers
+		}
+	}
+	return echoModel.VisibilityPrivate
+}
+
+// containsAddress 判断收件人中是否包含指定地址，兼容 as:Public 等简写
+func containsAddress(addresses []string, target string) bool {
+	for _, address := range addresses {
+		if address == target || address == "as:Public" || address == "Public" {
+			return true
+		}
+	}
+	return false
+}
+
+// mastodonMedia 归档中的媒体地址是相对路径，使用对象存储的实例会是完整 URL，两者都优先读取归档中的文件
+func mastodonMedia(baseDir, mediaURL string) Media {
+	relative := mediaURL
+	if parsed, err := url.Parse(mediaURL); err == nil && parsed.Host != "" {
+		relative = parsed.Path
+	}
+
+	local := filepath.Join(baseDir, filepath.FromSlash(strings.TrimPrefix(relative, "/")))
+	if isWithinDir(baseDir, local) && fileExists(local) {
+		return localMedia(local)
+	}
+	if relative != mediaURL {
+		return remoteMedia(mediaURL, "")
+	}
+	return unavailableMedia(mediaURL)
+}
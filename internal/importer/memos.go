@@ -0,0 +1,506 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	echoModel "github.com/lin-snow/ech0/internal/model/echo"
+	model "github.com/lin-snow/ech0/internal/model/importer"
+	httpUtil "github.com/lin-snow/ech0/internal/util/http"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// MemosSource 从 Memos 导入，支持 SQLite 数据库文件（memos_prod.db）与 API 导出的 JSON
+type MemosSource struct{}
+
+// Name 来源名称
+func (source *MemosSource) Name() string {
+	return model.SourceMemos
+}
+
+// Parse 解析 Memos 的数据库文件或 JSON 导出
+func (source *MemosSource) Parse(path string, options Options) ([]Item, error) {
+	file, err := findFile(path, func(name string) bool {
+		switch strings.ToLower(filepath.Ext(name)) {
+		case ".db", ".sqlite", ".sqlite3", ".json":
+			return true
+		default:
+			return false
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	if file == "" {
+		return nil, errors.New(commonModel.IMPORT_DATA_NOT_FOUND)
+	}
+
+	if isSQLiteFile(file) {
+		return parseMemosDatabase(file)
+	}
+	return parseMemosJSON(file, options)
+}
+
+// isSQLiteFile 根据文件头判断是否为 SQLite 数据库
+func isSQLiteFile(path string) bool {
+	file, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	header := make([]byte, 16)
+	if _, err := io.ReadFull(file, header); err != nil {
+		return false
+	}
+	return string(header) == "SQLite format 3\x00"
+}
+
+// memosVisibility 将 Memos 的可见性映射为 Ech0 的可见性，已归档的内容设为私密
+func memosVisibility(visibility, status string) string {
+	if strings.EqualFold(status, "ARCHIVED") {
+		return echoModel.VisibilityPrivate
+	}
+	switch strings.ToUpper(visibility) {
+	case "PUBLIC":
+		return echoModel.VisibilityPublic
+	case "PROTECTED":
+		return echoModel.VisibilityMembers
+	default:
+		return echoModel.VisibilityPrivate
+	}
+}
+
+// memosTags 优先使用 Memos 记录的标签，没有时从正文中提取
+func memosTags(tags []string, content string) []string {
+	if len(tags) == 0 {
+		tags = extractHashtags(content)
+	}
+	return normalizeTags(tags)
+}
+
+// isImageFile 根据 MIME 类型或文件名判断是否为图片
+func isImageFile(mimeType, filename string) bool {
+	if mimeType != "" {
+		return strings.HasPrefix(mimeType, "image/")
+	}
+	return strings.HasPrefix(httpUtil.GetMIMETypeFromFilenameOrURL(filename), "image/")
+}
+
+//==============================================================================
+// SQLite
+//==============================================================================
+
+// openMemosDatabase 打开 Memos 的数据库文件
+func openMemosDatabase(path string) (*gorm.DB, func(), error) {
+	db, err := gorm.Open(sqlite.Open(path), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	closeDB := func() {
+		if sqlDB, err := db.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	}
+	return db, closeDB, nil
+}
+
+// parseMemosDatabase 解析 Memos 的 SQLite 数据库，兼容不同版本的表结构
+func parseMemosDatabase(path string) ([]Item, error) {
+	db, closeDB, err := openMemosDatabase(path)
+	if err != nil {
+		return nil, err
+	}
+	defer closeDB()
+
+	if !db.Migrator().HasTable("memo") {
+		return nil, errors.New(commonModel.IMPORT_DATA_NOT_FOUND)
+	}
+
+	var memos []map[string]any
+	if err := db.Table("memo").Order("id ASC").Find(&memos).Error; err != nil {
+		return nil, err
+	}
+
+	parents, err := memosCommentParents(db)
+	if err != nil {
+		return nil, err
+	}
+	media, err := memosDatabaseMedia(db, path)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]Item, 0, len(memos))
+	for _, memo := range memos {
+		id := toInt64(memo["id"])
+		content := toString(memo["content"])
+		status := toString(memo["row_status"])
+		if status == "" {
+			status = toString(memo["state"])
+		}
+
+		item := Item{
+			SourceID:   strconv.FormatInt(id, 10),
+			Content:    content,
+			CreatedAt:  time.Unix(toInt64(memo["created_ts"]), 0),
+			Visibility: memosVisibility(toString(memo["visibility"]), status),
+			Tags:       memosTags(memosPayloadTags(toString(memo["payload"])), content),
+			Media:      media[id],
+		}
+		if parent, ok := parents[id]; ok {
+			item.ReplyTo = strconv.FormatInt(parent, 10)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// memosPayloadTags 读取新版本 Memos 在 payload 中记录的标签
+func memosPayloadTags(payload string) []string {
+	if payload == "" {
+		return nil
+	}
+	var parsed struct {
+		Tags     []string `json:"tags"`
+		Property struct {
+			Tags []string `json:"tags"`
+		} `json:"property"`
+	}
+	if err := json.Unmarshal([]byte(payload), &parsed); err != nil {
+		return nil
+	}
+	if len(parsed.Tags) > 0 {
+		return parsed.Tags
+	}
+	return parsed.Property.Tags
+}
+
+// memosCommentParents 读取评论关系，返回评论 ID 到被评论 Memo ID 的映射
+func memosCommentParents(db *gorm.DB) (map[int64]int64, error) {
+	parents := make(map[int64]int64)
+	if !db.Migrator().HasTable("memo_relation") {
+		return parents, nil
+	}
+
+	var relations []map[string]any
+	if err := db.Table("memo_relation").Where("type = ?", "COMMENT").Find(&relations).Error; err != nil {
+		return nil, err
+	}
+	for _, relation := range relations {
+		parents[toInt64(relation["memo_id"])] = toInt64(relation["related_memo_id"])
+	}
+	return parents, nil
+}
+
+// memosDatabaseMedia 读取 Memo 关联的图片，新版本的表名为 attachment，旧版本通过 memo_resource 关联
+//
+// 存在数据库中的图片在导入时才按需读取，避免一次性将所有文件加载到内存
+func memosDatabaseMedia(db *gorm.DB, dbPath string) (map[int64][]Media, error) {
+	media := make(map[int64][]Media)
+
+	table := "resource"
+	if !db.Migrator().HasTable(table) {
+		table = "attachment"
+		if !db.Migrator().HasTable(table) {
+			return media, nil
+		}
+	}
+
+	columnTypes, err := db.Migrator().ColumnTypes(table)
+	if err != nil {
+		return nil, err
+	}
+	columns := make(map[string]bool, len(columnTypes))
+	selects := make([]string, 0, len(columnTypes))
+	for _, columnType := range columnTypes {
+		name := columnType.Name()
+		columns[name] = true
+		if name == "blob" {
+			selects = append(selects, "length(r.blob) AS blob_size")
+			continue
+		}
+		selects = append(selects, "r."+name)
+	}
+
+	query := db.Table(table + " AS r")
+	switch {
+	case columns["memo_id"]:
+		query = query.Select(selects).Where("r.memo_id IS NOT NULL")
+	case db.Migrator().HasTable("memo_resource"):
+		query = query.Select(append(selects, "mr.memo_id AS memo_id")).
+			Joins("JOIN memo_resource AS mr ON mr.resource_id = r.id")
+	default:
+		return media, nil
+	}
+
+	var rows []map[string]any
+	if err := query.Order("r.id ASC").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	baseDir := filepath.Dir(dbPath)
+	for _, row := range rows {
+		filename := toString(row["filename"])
+		if !isImageFile(toString(row["type"]), filename) {
+			continue
+		}
+
+		memoID := toInt64(row["memo_id"])
+		media[memoID] = append(media[memoID], memosDatabaseFile(dbPath, baseDir, table, row))
+	}
+	return media, nil
+}
+
+// memosDatabaseFile 根据存储方式定位 Memos 中的文件：数据库、本地文件或外部链接
+func memosDatabaseFile(dbPath, baseDir, table string, row map[string]any) Media {
+	id := toInt64(row["id"])
+	filename := toString(row["filename"])
+
+	if toInt64(row["blob_size"]) > 0 {
+		return Media{
+			Filename: filename,
+			Open: func() (io.ReadCloser, error) {
+				db, closeDB, err := openMemosDatabase(dbPath)
+				if err != nil {
+					return nil, err
+				}
+				defer closeDB()
+
+				var blob []byte
+				if err := db.Table(table).Select("blob").Where("id = ?", id).Row().Scan(&blob); err != nil {
+					return nil, err
+				}
+				return io.NopCloser(bytes.NewReader(blob)), nil
+			},
+		}
+	}
+
+	for _, key := range []string{"external_link", "reference", "internal_path"} {
+		location := toString(row[key])
+		if location == "" {
+			continue
+		}
+		if strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://") {
+			return remoteMedia(location, filename)
+		}
+
+		if local := memosLocalPath(baseDir, location); local != "" {
+			return localMedia(local)
+		}
+	}
+
+	return unavailableMedia(filename)
+}
+
+// memosLocalPath 在数据库所在目录中定位本地文件，只读取该目录之内的文件
+//
+// 旧版本记录的是 Memos 服务器上的绝对路径，按其中 assets 之后的部分在数据目录中查找
+func memosLocalPath(baseDir, location string) string {
+	relative := filepath.FromSlash(location)
+	if filepath.IsAbs(relative) {
+		index := strings.LastIndex(location, "/assets/")
+		if index < 0 {
+			return ""
+		}
+		relative = filepath.FromSlash(location[index+1:])
+	}
+
+	local := filepath.Join(baseDir, relative)
+	if !isWithinDir(baseDir, local) || !fileExists(local) {
+		return ""
+	}
+	return local
+}
+
+//==============================================================================
+// JSON
+//==============================================================================
+
+// memosJSONResource Memos API 返回的资源
+type memosJSONResource struct {
+	ID           int64  `json:"id"`
+	Name         string `json:"name"`
+	Filename     string `json:"filename"`
+	ExternalLink string `json:"externalLink"`
+	Type         string `json:"type"`
+}
+
+// memosJSONMemo Memos API 返回的 Memo，兼容 v0 与 v1 接口
+type memosJSONMemo struct {
+	ID           int64               `json:"id"`
+	Name         string              `json:"name"`
+	Parent       string              `json:"parent"`
+	Content      string              `json:"content"`
+	Visibility   string              `json:"visibility"`
+	RowStatus    string              `json:"rowStatus"`
+	State        string              `json:"state"`
+	CreateTime   string              `json:"createTime"`
+	DisplayTime  string              `json:"displayTime"`
+	CreatedTs    int64               `json:"createdTs"`
+	Tags         []string            `json:"tags"`
+	Resources    []memosJSONResource `json:"resources"`
+	Attachments  []memosJSONResource `json:"attachments"`
+	ResourceList []memosJSONResource `json:"resourceList"`
+}
+
+// parseMemosJSON 解析 Memos API 导出的 JSON，可以是 Memo 数组或包含 memos 字段的对象
+func parseMemosJSON(path string, options Options) ([]Item, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var memos []memosJSONMemo
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("[")) {
+		err = json.Unmarshal(trimmed, &memos)
+	} else {
+		var wrapper struct {
+			Memos []memosJSONMemo `json:"memos"`
+		}
+		err = json.Unmarshal(trimmed, &wrapper)
+		memos = wrapper.Memos
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", commonModel.IMPORT_DATA_NOT_FOUND, err)
+	}
+
+	baseURL := strings.TrimRight(options.BaseURL, "/")
+	items := make([]Item, 0, len(memos))
+	for _, memo := range memos {
+		status := memo.RowStatus
+		if status == "" {
+			status = memo.State
+		}
+
+		item := Item{
+			SourceID:   memosJSONID(memo.Name, memo.ID),
+			ReplyTo:    memo.Parent,
+			Content:    memo.Content,
+			CreatedAt:  memosJSONTime(memo),
+			Visibility: memosVisibility(memo.Visibility, status),
+			Tags:       memosTags(memo.Tags, memo.Content),
+		}
+
+		resources := append(append(memo.Resources, memo.Attachments...), memo.ResourceList...)
+		for _, resource := range resources {
+			if !isImageFile(resource.Type, resource.Filename) {
+				continue
+			}
+			item.Media = append(item.Media, memosJSONFile(baseURL, resource))
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// memosJSONID v1 接口使用 name（如 memos/123）作为标识，v0 接口使用数字 ID
+func memosJSONID(name string, id int64) string {
+	if name != "" {
+		return name
+	}
+	return strconv.FormatInt(id, 10)
+}
+
+// memosJSONTime 解析 Memo 的发布时间
+func memosJSONTime(memo memosJSONMemo) time.Time {
+	for _, value := range []string{memo.DisplayTime, memo.CreateTime} {
+		if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+			return parsed
+		}
+	}
+	return time.Unix(memo.CreatedTs, 0)
+}
+
+// memosJSONFile 定位 API 导出数据中的文件，没有外部链接时需要通过来源站点地址下载
+func memosJSONFile(baseURL string, resource memosJSONResource) Media {
+	if resource.ExternalLink != "" {
+		return remoteMedia(resource.ExternalLink, resource.Filename)
+	}
+	if baseURL == "" {
+		return unavailableMedia(resource.Filename)
+	}
+	if resource.Name != "" {
+		return remoteMedia(
+			fmt.Sprintf("%s/file/%s/%s", baseURL, resource.Name, resource.Filename),
+			resource.Filename,
+		)
+	}
+	return remoteMedia(
+		fmt.Sprintf("%s/o/r/%d/%s", baseURL, resource.ID, resource.Filename),
+		resource.Filename,
+	)
+}
+
+//==============================================================================
+// Helpers
+//==============================================================================
+
+// toInt64 将数据库中读取的值转换为整数
+func toInt64(value any) int64 {
+	switch v := value.(type) {
+	case int64:
+		return v
+	case int:
+		return int64(v)
+	case int32:
+		return int64(v)
+	case float64:
+		return int64(v)
+	case []byte:
+		n, _ := strconv.ParseInt(string(v), 10, 64)
+		return n
+	case string:
+		n, _ := strconv.ParseInt(v, 10, 64)
+		return n
+	case time.Time:
+		return v.Unix()
+	case *any:
+		// 表达式列没有声明类型，gorm 会以指针形式返回
+		if v != nil {
+			return toInt64(*v)
+		}
+		return 0
+	default:
+		return 0
+	}
+}
+
+// toString 将数据库中读取的值转换为字符串
+func toString(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case nil:
+		return ""
+	case *any:
+		if v != nil {
+			return toString(*v)
+		}
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// fileExists 判断文件是否存在
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
@@ -0,0 +1,176 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/lin-snow/ech0/internal/config"
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	httpUtil "github.com/lin-snow/ech0/internal/util/http"
+	"golang.org/x/net/html"
+)
+
+const (
+	maxTagLength       = 50               // 标签名称的最大长度，与 Tag 表的字段长度一致
+	remoteMediaTimeout = 30 * time.Second // 下载远程媒体文件的超时时间
+)
+
+var (
+	// hashtagPattern 匹配正文中的 #标签，# 后紧跟空格的 Markdown 标题不会被匹配
+	hashtagPattern = regexp.MustCompile(`(?:^|\s)#([\p{L}\p{N}_\-/]+)`)
+
+	// blankLinesPattern 匹配连续的空行
+	blankLinesPattern = regexp.MustCompile(`\n{3,}`)
+
+	// remoteMediaClient 下载远程媒体的客户端，导入文件中的地址不可信，只允许访问公网地址
+	remoteMediaClient = httpUtil.NewPublicClient(remoteMediaTimeout)
+)
+
+// extractHashtags 提取正文中的 #标签
+func extractHashtags(content string) []string {
+	var tags []string
+	for _, match := range hashtagPattern.FindAllStringSubmatch(content, -1) {
+		tags = append(tags, match[1])
+	}
+	return tags
+}
+
+// normalizeTags 去掉标签的 # 前缀、空白与重复项，并截断过长的标签
+func normalizeTags(tags []string) []string {
+	seen := make(map[string]struct{}, len(tags))
+	result := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		name := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+		if name == "" {
+			continue
+		}
+		for utf8.RuneCountInString(name) > maxTagLength {
+			_, size := utf8.DecodeLastRuneInString(name)
+			name = name[:len(name)-size]
+		}
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		result = append(result, name)
+	}
+	return result
+}
+
+// htmlToText 将 HTML 正文转换为纯文本，保留段落与换行，普通链接替换为链接地址
+func htmlToText(content string) string {
+	doc, err := html.Parse(strings.NewReader(content))
+	if err != nil {
+		return content
+	}
+
+	var builder strings.Builder
+	var walk func(node *html.Node)
+	walk = func(node *html.Node) {
+		if node.Type == html.TextNode {
+			builder.WriteString(node.Data)
+			return
+		}
+		if node.Type == html.ElementNode {
+			switch node.Data {
+			case "br":
+				builder.WriteString("\n")
+				return
+			case "a":
+				// 提及与话题标签保留显示文本，其余链接的显示文本可能被截断，使用完整地址
+				if href := attr(node, "href"); href != "" && !isMentionOrHashtag(node) {
+					builder.WriteString(href)
+					return
+				}
+			}
+		}
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+		if node.Type == html.ElementNode {
+			switch node.Data {
+			case "p", "div", "blockquote", "li", "pre":
+				builder.WriteString("\n\n")
+			}
+		}
+	}
+	walk(doc)
+
+	text := blankLinesPattern.ReplaceAllString(builder.String(), "\n\n")
+	return strings.TrimSpace(text)
+}
+
+// attr 获取 HTML 节点的属性值
+func attr(node *html.Node, key string) string {
+	for _, a := range node.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// isMentionOrHashtag 判断链接是否为提及或话题标签
+func isMentionOrHashtag(node *html.Node) bool {
+	class := attr(node, "class")
+	return strings.Contains(class, "mention") || strings.Contains(class, "hashtag") ||
+		attr(node, "rel") == "tag"
+}
+
+// localMedia 读取本地文件的媒体
+func localMedia(path string) Media {
+	return Media{
+		Filename: path,
+		Open: func() (io.ReadCloser, error) {
+			return os.Open(path)
+		},
+	}
+}
+
+// remoteMedia 下载远程地址的媒体，超过图片大小上限的响应会被拒绝
+func remoteMedia(url, filename string) Media {
+	if filename == "" {
+		filename = url
+	}
+	return Media{
+		Filename: filename,
+		Open: func() (io.ReadCloser, error) {
+			resp, err := remoteMediaClient.Get(url)
+			if err != nil {
+				return nil, err
+			}
+			if resp.StatusCode != http.StatusOK {
+				_ = resp.Body.Close()
+				return nil, fmt.Errorf("%s: %s", url, resp.Status)
+			}
+
+			maxSize := int64(config.Config.Upload.ImageMaxSize)
+			if resp.ContentLength > maxSize {
+				_ = resp.Body.Close()
+				return nil, errors.New(commonModel.FILE_SIZE_EXCEED_LIMIT)
+			}
+			// 多读一个字节，由保存时的大小检查拒绝超限的文件
+			return struct {
+				io.Reader
+				io.Closer
+			}{io.LimitReader(resp.Body, maxSize+1), resp.Body}, nil
+		},
+	}
+}
+
+// unavailableMedia 无法获取的媒体，打开时返回错误，用于在导入进度中记录失败原因
+func unavailableMedia(filename string) Media {
+	return Media{
+		Filename: filename,
+		Open: func() (io.ReadCloser, error) {
+			return nil, errors.New(commonModel.IMPORT_MEDIA_UNAVAILABLE + ": " + filename)
+		},
+	}
+}
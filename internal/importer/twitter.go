@@ -0,0 +1,192 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	echoModel "github.com/lin-snow/ech0/internal/model/echo"
+	model "github.com/lin-snow/ech0/internal/model/importer"
+)
+
+// twitterDataFilePattern 匹配归档中的推文数据文件，数据较多时会拆分为 tweets-part1.js 等多个文件
+var twitterDataFilePattern = regexp.MustCompile(`^tweets?(-part\d+)?\.js$`)
+
+// TwitterSource 从 Twitter/X 归档导入，解析 data 目录中的 tweets.js 与 tweets_media 目录
+type TwitterSource struct{}
+
+// twitterMedia 推文中的媒体
+type twitterMedia struct {
+	URL           string `json:"url"`
+	ExpandedURL   string `json:"expanded_url"`
+	MediaURLHTTPS string `json:"media_url_https"`
+	Type          string `json:"type"`
+}
+
+// twitterTweet 归档中的推文
+type twitterTweet struct {
+	IDStr                string `json:"id_str"`
+	FullText             string `json:"full_text"`
+	CreatedAt            string `json:"created_at"`
+	InReplyToStatusIDStr string `json:"in_reply_to_status_id_str"`
+	Entities             struct {
+		Hashtags []struct {
+			Text string `json:"text"`
+		} `json:"hashtags"`
+		URLs []struct {
+			URL         string `json:"url"`
+			ExpandedURL string `json:"expanded_url"`
+		} `json:"urls"`
+		Media []twitterMedia `json:"media"`
+	} `json:"entities"`
+	ExtendedEntities struct {
+		Media []twitterMedia `json:"media"`
+	} `json:"extended_entities"`
+}
+
+// Name 来源名称
+func (source *TwitterSource) Name() string {
+	return model.SourceTwitter
+}
+
+// Parse 解析 Twitter/X 归档，转推会被忽略
+func (source *TwitterSource) Parse(dataPath string, options Options) ([]Item, error) {
+	first, err := findFile(dataPath, twitterDataFilePattern.MatchString)
+	if err != nil {
+		return nil, err
+	}
+	if first == "" {
+		return nil, errors.New(commonModel.IMPORT_DATA_NOT_FOUND)
+	}
+
+	dataDir := filepath.Dir(first)
+	entries, err := os.ReadDir(dataDir)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() && twitterDataFilePattern.MatchString(entry.Name()) {
+			files = append(files, filepath.Join(dataDir, entry.Name()))
+		}
+	}
+	sort.Strings(files)
+
+	mediaDir := filepath.Join(dataDir, "tweets_media")
+	if _, err := os.Stat(mediaDir); err != nil {
+		mediaDir = filepath.Join(dataDir, "tweet_media")
+	}
+
+	var items []Item
+	for _, file := range files {
+		tweets, err := readTwitterDataFile(file)
+		if err != nil {
+			return nil, err
+		}
+		for _, tweet := range tweets {
+			if strings.HasPrefix(tweet.FullText, "RT @") {
+				continue
+			}
+			items = append(items, twitterItem(tweet, mediaDir))
+		}
+	}
+	return items, nil
+}
+
+// readTwitterDataFile 读取 window.YTD.tweets.part0 = [...] 格式的数据文件
+func readTwitterDataFile(file string) ([]twitterTweet, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	start := bytes.IndexByte(data, '[')
+	if start < 0 {
+		return nil, fmt.Errorf("%s: %s", commonModel.IMPORT_DATA_NOT_FOUND, filepath.Base(file))
+	}
+
+	var records []json.RawMessage
+	if err := json.Unmarshal(data[start:], &records); err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(file), err)
+	}
+
+	tweets := make([]twitterTweet, 0, len(records))
+	for _, record := range records {
+		// 新版归档的每条记录包裹在 tweet 字段中，旧版直接是推文对象
+		var wrapper struct {
+			Tweet *twitterTweet `json:"tweet"`
+		}
+		if err := json.Unmarshal(record, &wrapper); err == nil && wrapper.Tweet != nil {
+			tweets = append(tweets, *wrapper.Tweet)
+			continue
+		}
+		var tweet twitterTweet
+		if err := json.Unmarshal(record, &tweet); err != nil {
+			return nil, fmt.Errorf("%s: %w", filepath.Base(file), err)
+		}
+		tweets = append(tweets, tweet)
+	}
+	return tweets, nil
+}
+
+// twitterItem 将推文转换为 Item，短链接还原为原始地址，图片链接从正文中移除
+func twitterItem(tweet twitterTweet, mediaDir string) Item {
+	text := tweet.FullText
+	for _, link := range tweet.Entities.URLs {
+		if link.URL != "" && link.ExpandedURL != "" {
+			text = strings.ReplaceAll(text, link.URL, link.ExpandedURL)
+		}
+	}
+
+	media := tweet.ExtendedEntities.Media
+	if len(media) == 0 {
+		media = tweet.Entities.Media
+	}
+
+	createdAt, _ := time.Parse(time.RubyDate, tweet.CreatedAt)
+	item := Item{
+		SourceID:   tweet.IDStr,
+		ReplyTo:    tweet.InReplyToStatusIDStr,
+		CreatedAt:  createdAt,
+		Visibility: echoModel.VisibilityPublic,
+	}
+
+	for _, m := range media {
+		// 视频与动图只保留链接
+		if m.Type != "" && m.Type != "photo" {
+			if m.URL != "" && m.ExpandedURL != "" {
+				text = strings.ReplaceAll(text, m.URL, m.ExpandedURL)
+			}
+			continue
+		}
+		if m.URL != "" {
+			text = strings.ReplaceAll(text, m.URL, "")
+		}
+
+		// 归档中的图片文件名为 推文ID-原始文件名
+		local := filepath.Join(mediaDir, tweet.IDStr+"-"+path.Base(m.MediaURLHTTPS))
+		if fileExists(local) {
+			item.Media = append(item.Media, localMedia(local))
+		} else {
+			item.Media = append(item.Media, remoteMedia(m.MediaURLHTTPS, ""))
+		}
+	}
+
+	var tags []string
+	for _, hashtag := range tweet.Entities.Hashtags {
+		tags = append(tags, hashtag.Text)
+	}
+
+	item.Content = strings.TrimSpace(html.UnescapeString(text))
+	item.Tags = normalizeTags(tags)
+	return item
+}
@@ -1,16 +1,46 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/lin-snow/ech0/internal/authz"
+	"github.com/lin-snow/ech0/internal/database"
 	authModel "github.com/lin-snow/ech0/internal/model/auth"
 	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	settingModel "github.com/lin-snow/ech0/internal/model/setting"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
+	commonRepository "github.com/lin-snow/ech0/internal/repository/common"
+	sessionRepository "github.com/lin-snow/ech0/internal/repository/session"
+	settingRepository "github.com/lin-snow/ech0/internal/repository/setting"
+	cryptoUtil "github.com/lin-snow/ech0/internal/util/crypto"
 	errUtil "github.com/lin-snow/ech0/internal/util/err"
 	jwtUtil "github.com/lin-snow/ech0/internal/util/jwt"
+	logUtil "github.com/lin-snow/ech0/internal/util/log"
+	"go.uber.org/zap"
 )
 
+const (
+	// accessTokenContextKey 上下文中保存当前请求所用访问令牌的键，登录会话请求不设置
+	accessTokenContextKey = "access_token"
+	// sessionContextKey 上下文中保存当前请求所属登录会话 ID 的键，访问令牌请求不设置
+	sessionContextKey = "session_id"
+	// accessTokenUsageInterval 同一 IP 下记录访问令牌使用情况的最小间隔
+	accessTokenUsageInterval = time.Minute
+)
+
+// accessTokenRepository 访问令牌查询仓储，鉴权中间件在路由初始化前创建，不经过依赖注入
+var accessTokenRepository = settingRepository.NewSettingRepository(database.GetDB)
+
+// loginSessionRepository 登录会话查询仓储，用于即时识别已注销的会话
+var loginSessionRepository = sessionRepository.NewSessionRepository(database.GetDB)
+
+// permissionUserRepository 用户查询仓储，用于在路由层按角色权限表鉴权，不经过缓存以便角色变更即时生效
+var permissionUserRepository = commonRepository.NewCommonRepository(database.GetDB)
+
 // JWTAuthMiddleware JWT 拦截器中间件
 func JWTAuthMiddleware() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
@@ -91,6 +121,12 @@ func JWTAuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		// 访问令牌为不透明字符串，需要查询数据库
+		if strings.HasPrefix(parts[1], settingModel.AccessTokenPrefix) {
+			authenticateAccessToken(ctx, parts[1], commonModel.ACCESS_TOKEN_REVOKED)
+			return
+		}
+
 		// 解析 token
 		mc, err := jwtUtil.ParseToken(parts[1])
 		if err != nil {
@@ -106,8 +142,139 @@ func JWTAuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		// 如果 token 解析成功，则将用户 ID 存入上下文
+		// 不属于任何登录会话的 JWT 只可能是旧版本的访问令牌，需要按访问令牌校验以支持吊销
+		if mc.SessionID == 0 {
+			authenticateAccessToken(ctx, parts[1], commonModel.TOKEN_NOT_VALID)
+			return
+		}
+
+		// 登录会话被注销、过期或密码变更后，尚未过期的访问令牌也立即失效
+		session, err := loginSessionRepository.GetSessionByID(mc.SessionID)
+		if err != nil || session == nil || session.IsExpired(time.Now()) ||
+			session.UserID != mc.Userid || session.TokenVersion != mc.TokenVersion {
+			ctx.JSON(
+				http.StatusUnauthorized,
+				commonModel.Fail[any](errUtil.HandleError(&commonModel.ServerError{
+					Msg: commonModel.SESSION_INVALID,
+					Err: err,
+				})),
+			)
+			ctx.Abort()
+			return
+		}
+
+		// 如果 token 解析成功，则将用户 ID 与会话 ID 存入上下文
 		ctx.Set("userid", mc.Userid)
+		ctx.Set(sessionContextKey, session.ID)
 		ctx.Next()
 	}
 }
+
+// RequireScope 要求访问令牌具备指定权限范围，登录会话与游客请求不受限制
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		token, ok := currentAccessToken(ctx)
+		if ok && !token.HasScope(scope) {
+			abortForbidden(ctx, commonModel.ACCESS_TOKEN_SCOPE_DENIED)
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// RequirePermission 要求当前用户的角色拥有指定权限，需放在鉴权中间件之后
+func RequirePermission(permission userModel.Permission) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		user, err := permissionUserRepository.GetUserByUserId(ctx.GetUint("userid"))
+		if err != nil {
+			abortForbidden(ctx, commonModel.NO_PERMISSION_DENIED)
+			return
+		}
+		if err := authz.Authorize(user, permission); err != nil {
+			abortForbidden(ctx, err.Error())
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// RejectAccessToken 拒绝使用访问令牌的请求，用于仅允许登录会话访问的路由组
+func RejectAccessToken() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if _, ok := currentAccessToken(ctx); ok {
+			abortForbidden(ctx, commonModel.ACCESS_TOKEN_NOT_ALLOWED)
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// authenticateAccessToken 根据令牌摘要查询访问令牌，校验通过后记录使用情况并放行，
+// 查询不到令牌时以 notFoundMsg 拒绝请求
+func authenticateAccessToken(ctx *gin.Context, tokenString string, notFoundMsg string) {
+	token, err := accessTokenRepository.GetAccessTokenByHash(cryptoUtil.SHA256Hex(tokenString))
+	if err != nil || token == nil {
+		ctx.JSON(
+			http.StatusUnauthorized,
+			commonModel.Fail[any](errUtil.HandleError(&commonModel.ServerError{
+				Msg: notFoundMsg,
+				Err: err,
+			})),
+		)
+		ctx.Abort()
+		return
+	}
+
+	now := time.Now()
+	if token.IsExpired(now) {
+		ctx.JSON(
+			http.StatusUnauthorized,
+			commonModel.Fail[any](errUtil.HandleError(&commonModel.ServerError{
+				Msg: commonModel.ACCESS_TOKEN_EXPIRED,
+				Err: nil,
+			})),
+		)
+		ctx.Abort()
+		return
+	}
+
+	// 记录最近使用时间与 IP，短时间内同一 IP 的重复请求不再写库
+	ip := ctx.ClientIP()
+	if token.LastUsedAt == nil || token.LastUsedIP != ip ||
+		now.Sub(*token.LastUsedAt) >= accessTokenUsageInterval {
+		if err := accessTokenRepository.UpdateAccessTokenUsage(
+			context.Background(),
+			token.ID,
+			now,
+			ip,
+		); err != nil {
+			logUtil.GetLogger().Warn("Failed to record access token usage", zap.Error(err))
+		}
+	}
+
+	ctx.Set("userid", token.UserID)
+	ctx.Set(accessTokenContextKey, token)
+	ctx.Next()
+}
+
+// currentAccessToken 获取当前请求使用的访问令牌
+func currentAccessToken(ctx *gin.Context) (*settingModel.AccessTokenSetting, bool) {
+	value, ok := ctx.Get(accessTokenContextKey)
+	if !ok {
+		return nil, false
+	}
+	token, ok := value.(*settingModel.AccessTokenSetting)
+	return token, ok
+}
+
+// abortForbidden 以 403 中断请求
+func abortForbidden(ctx *gin.Context, msg string) {
+	ctx.JSON(
+		http.StatusForbidden,
+		commonModel.Fail[any](errUtil.HandleError(&commonModel.ServerError{
+			Msg: msg,
+			Err: nil,
+		})),
+	)
+	ctx.Abort()
+}
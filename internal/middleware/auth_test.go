@@ -0,0 +1,256 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/lin-snow/ech0/internal/config"
+	"github.com/lin-snow/ech0/internal/database"
+	authModel "github.com/lin-snow/ech0/internal/model/auth"
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	settingModel "github.com/lin-snow/ech0/internal/model/setting"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
+	sessionRepository "github.com/lin-snow/ech0/internal/repository/session"
+	settingRepository "github.com/lin-snow/ech0/internal/repository/setting"
+	cryptoUtil "github.com/lin-snow/ech0/internal/util/crypto"
+	jwtUtil "github.com/lin-snow/ech0/internal/util/jwt"
+	logUtil "github.com/lin-snow/ech0/internal/util/log"
+	"go.uber.org/zap"
+)
+
+// authFixture 基于临时 SQLite 数据库的鉴权中间件测试环境，路由分组与 router.setupRouterGroup 一致
+type authFixture struct {
+	db     *gorm.DB
+	engine *gin.Engine
+	user   userModel.User
+}
+
+func newAuthFixture(t *testing.T) *authFixture {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	logUtil.Logger = zap.NewNop()
+	secret, expires, accessExpires := config.JWT_SECRET, config.Config.Auth.Jwt.Expires, config.Config.Auth.Jwt.AccessExpires
+	t.Cleanup(func() {
+		config.JWT_SECRET = secret
+		config.Config.Auth.Jwt.Expires = expires
+		config.Config.Auth.Jwt.AccessExpires = accessExpires
+	})
+	config.JWT_SECRET = []byte("test-secret")
+	config.Config.Auth.Jwt.Expires = 3600
+	config.Config.Auth.Jwt.AccessExpires = 300
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "ech0.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.AutoMigrate(database.Models()...); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	dbProvider := func() *gorm.DB { return db }
+	tokenRepo, sessionRepo := accessTokenRepository, loginSessionRepository
+	t.Cleanup(func() {
+		accessTokenRepository, loginSessionRepository = tokenRepo, sessionRepo
+	})
+	accessTokenRepository = settingRepository.NewSettingRepository(dbProvider)
+	loginSessionRepository = sessionRepository.NewSessionRepository(dbProvider)
+
+	f := &authFixture{db: db, engine: gin.New()}
+	f.user = userModel.User{Username: "alice", Password: "x"}
+	if err := db.Create(&f.user).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	ok := func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, strconv.FormatUint(uint64(ctx.GetUint("userid")), 10))
+	}
+	auth := f.engine.Group("/api", JWTAuthMiddleware(), RejectAccessToken())
+	auth.GET("/access-tokens", ok)
+	token := f.engine.Group("/api", JWTAuthMiddleware())
+	token.Group("", RequireScope(settingModel.ScopeEchoRead)).GET("/echos", ok)
+	token.Group("", RequireScope(settingModel.ScopeEchoWrite)).POST("/echos", ok)
+	token.Group("", RequireScope(settingModel.ScopeTodoWrite)).POST("/todos", ok)
+	token.Group("", RequireScope(settingModel.ScopeSettingsAdmin)).PUT("/settings", ok)
+	return f
+}
+
+// createAccessToken 以明文令牌的摘要保存访问令牌
+func (f *authFixture) createAccessToken(t *testing.T, plain string, scopes []string, expiry *time.Time) settingModel.AccessTokenSetting {
+	t.Helper()
+	token := settingModel.AccessTokenSetting{
+		UserID:    f.user.ID,
+		TokenHash: cryptoUtil.SHA256Hex(plain),
+		Prefix:    settingModel.AccessTokenDisplayPrefix(plain),
+		Name:      plain,
+		Scopes:    scopes,
+		Expiry:    expiry,
+		CreatedAt: time.Now(),
+	}
+	if err := f.db.Create(&token).Error; err != nil {
+		t.Fatalf("create access token: %v", err)
+	}
+	return token
+}
+
+// sessionToken 创建登录会话并签发属于该会话的 JWT
+func (f *authFixture) sessionToken(t *testing.T) string {
+	t.Helper()
+	session := authModel.Session{
+		UserID:           f.user.ID,
+		RefreshTokenHash: cryptoUtil.SHA256Hex("refresh"),
+		LastUsedAt:       time.Now(),
+		ExpiresAt:        time.Now().Add(time.Hour),
+	}
+	if err := f.db.Create(&session).Error; err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+	return f.sign(t, jwtUtil.CreateClaims(f.user, session.ID))
+}
+
+// sign 签发 JWT
+func (f *authFixture) sign(t *testing.T, claims jwt.Claims) string {
+	t.Helper()
+	token, err := jwtUtil.GenerateToken(claims)
+	if err != nil {
+		t.Fatalf("generate token: %v", err)
+	}
+	return token
+}
+
+func (f *authFixture) do(method, path, token, remoteAddr string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if remoteAddr != "" {
+		req.RemoteAddr = remoteAddr
+	}
+	w := httptest.NewRecorder()
+	f.engine.ServeHTTP(w, req)
+	return w
+}
+
+func TestAccessTokenScopes(t *testing.T) {
+	f := newAuthFixture(t)
+	hour := time.Now().Add(time.Hour)
+	expired := time.Now().Add(-time.Minute)
+	f.createAccessToken(t, "ech0_reader", []string{settingModel.ScopeEchoRead}, &hour)
+	f.createAccessToken(t, "ech0_todo", []string{"todo:*"}, nil)
+	f.createAccessToken(t, "ech0_expired", []string{"*"}, &expired)
+
+	// 旧版本签发的访问令牌是没有会话的 JWT，迁移后以摘要保存并拥有全部权限
+	legacy := f.sign(t, jwtUtil.CreateClaimsWithExpiry(f.user, 0))
+	f.createAccessToken(t, legacy, []string{"*"}, nil)
+	unknownLegacy := f.sign(t, jwtUtil.CreateClaimsWithExpiry(f.user, 3600))
+	session := f.sessionToken(t)
+
+	tests := map[string]struct {
+		method, path, token string
+		wantStatus          int
+		wantMsg             string
+	}{
+		"读令牌读取 Echo":    {http.MethodGet, "/api/echos", "ech0_reader", http.StatusOK, ""},
+		"读令牌不能发布 Echo":  {http.MethodPost, "/api/echos", "ech0_reader", http.StatusForbidden, commonModel.ACCESS_TOKEN_SCOPE_DENIED},
+		"通配符授予待办写权限":    {http.MethodPost, "/api/todos", "ech0_todo", http.StatusOK, ""},
+		"通配符不跨资源":       {http.MethodGet, "/api/echos", "ech0_todo", http.StatusForbidden, commonModel.ACCESS_TOKEN_SCOPE_DENIED},
+		"令牌不能管理访问令牌":    {http.MethodGet, "/api/access-tokens", "ech0_todo", http.StatusForbidden, commonModel.ACCESS_TOKEN_NOT_ALLOWED},
+		"过期令牌":          {http.MethodGet, "/api/echos", "ech0_expired", http.StatusUnauthorized, commonModel.ACCESS_TOKEN_EXPIRED},
+		"已吊销的令牌":        {http.MethodGet, "/api/echos", "ech0_revoked", http.StatusUnauthorized, commonModel.ACCESS_TOKEN_REVOKED},
+		"旧令牌保留全部权限":     {http.MethodPut, "/api/settings", legacy, http.StatusOK, ""},
+		"旧令牌同样不能管理访问令牌": {http.MethodGet, "/api/access-tokens", legacy, http.StatusForbidden, commonModel.ACCESS_TOKEN_NOT_ALLOWED},
+		"未迁移或已吊销的旧令牌":   {http.MethodGet, "/api/echos", unknownLegacy, http.StatusUnauthorized, commonModel.TOKEN_NOT_VALID},
+		"登录会话不受权限范围限制":  {http.MethodPut, "/api/settings", session, http.StatusOK, ""},
+		"登录会话可以管理访问令牌":  {http.MethodGet, "/api/access-tokens", session, http.StatusOK, ""},
+		"缺少令牌":          {http.MethodPost, "/api/todos", "", http.StatusUnauthorized, commonModel.TOKEN_NOT_FOUND},
+	}
+
+	for name, tt := range tests {
+		w := f.do(tt.method, tt.path, tt.token, "")
+		if w.Code != tt.wantStatus {
+			t.Errorf("%s: status = %d, want %d", name, w.Code, tt.wantStatus)
+			continue
+		}
+		if tt.wantStatus == http.StatusOK {
+			if w.Body.String() != strconv.FormatUint(uint64(f.user.ID), 10) {
+				t.Errorf("%s: userid = %q", name, w.Body.String())
+			}
+			continue
+		}
+		var result commonModel.Result[any]
+		if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil || result.Message != tt.wantMsg {
+			t.Errorf("%s: msg = %q, want %q", name, result.Message, tt.wantMsg)
+		}
+	}
+
+	// 删除后立即失效
+	if err := f.db.Where("prefix = ?", "ech0_reader").Delete(&settingModel.AccessTokenSetting{}).Error; err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+	if w := f.do(http.MethodGet, "/api/echos", "ech0_reader", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("revoked token status = %d", w.Code)
+	}
+}
+
+func TestAccessTokenUsage(t *testing.T) {
+	f := newAuthFixture(t)
+	token := f.createAccessToken(t, "ech0_usage", []string{settingModel.ScopeEchoRead}, nil)
+
+	load := func() settingModel.AccessTokenSetting {
+		t.Helper()
+		var current settingModel.AccessTokenSetting
+		if err := f.db.First(&current, token.ID).Error; err != nil {
+			t.Fatalf("load token: %v", err)
+		}
+		return current
+	}
+	request := func(remoteAddr string) {
+		t.Helper()
+		if w := f.do(http.MethodGet, "/api/echos", "ech0_usage", remoteAddr); w.Code != http.StatusOK {
+			t.Fatalf("status = %d", w.Code)
+		}
+	}
+
+	if current := load(); current.LastUsedAt != nil || current.LastUsedIP != "" {
+		t.Fatalf("unused token has usage: %+v", current)
+	}
+
+	request("10.0.0.1:1234")
+	first := load()
+	if first.LastUsedAt == nil || first.LastUsedIP != "10.0.0.1" {
+		t.Fatalf("usage not recorded: %+v", first)
+	}
+
+	// 同一 IP 在间隔内的请求不再写库
+	request("10.0.0.1:1234")
+	if current := load(); !current.LastUsedAt.Equal(*first.LastUsedAt) {
+		t.Errorf("usage rewritten within interval: %v", current.LastUsedAt)
+	}
+
+	// 换了 IP 立即记录
+	request("10.0.0.2:1234")
+	if current := load(); current.LastUsedIP != "10.0.0.2" {
+		t.Errorf("ip = %q, want 10.0.0.2", current.LastUsedIP)
+	}
+
+	// 超过间隔后同一 IP 也会更新
+	stale := time.Now().Add(-2 * accessTokenUsageInterval)
+	if err := f.db.Model(&settingModel.AccessTokenSetting{}).Where("id = ?", token.ID).Update("last_used_at", stale).Error; err != nil {
+		t.Fatalf("age usage: %v", err)
+	}
+	request("10.0.0.2:1234")
+	if current := load(); !current.LastUsedAt.After(stale.Add(accessTokenUsageInterval)) {
+		t.Errorf("stale usage not refreshed: %v", current.LastUsedAt)
+	}
+}
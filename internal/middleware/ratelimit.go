@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lin-snow/ech0/internal/config"
+	authModel "github.com/lin-snow/ech0/internal/model/auth"
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	"github.com/lin-snow/ech0/internal/ratelimit"
+	errUtil "github.com/lin-snow/ech0/internal/util/err"
+)
+
+// RateLimitKeyFunc 返回请求所属的限流 key，返回空字符串时不限流
+type RateLimitKeyFunc func(ctx *gin.Context) string
+
+// ClientIPKey 按客户端 IP 限流，客户端 IP 的解析受 server.trustedproxies 控制
+func ClientIPKey(ctx *gin.Context) string {
+	return "ip:" + ctx.ClientIP()
+}
+
+// UserKey 按登录用户限流，未登录的请求已由按 IP 的限流覆盖，不再限流，需在鉴权中间件之后使用
+func UserKey(ctx *gin.Context) string {
+	if userid, ok := ctx.Get("userid"); ok {
+		if id, ok := userid.(uint); ok && id != authModel.NO_USER_LOGINED {
+			return fmt.Sprintf("user:%d", id)
+		}
+	}
+	return ""
+}
+
+// RateLimit 按规则对请求进行令牌桶限流，每次调用都会创建独立的令牌桶，
+// 同一限流器需要覆盖多个路由组时应复用返回的中间件
+func RateLimit(rule config.RateLimitRule, key RateLimitKeyFunc) gin.HandlerFunc {
+	limiter := ratelimit.NewLimiter(rule.Rate, rule.Burst)
+	if !config.Config.RateLimit.Enable || limiter == nil {
+		return func(ctx *gin.Context) {
+			ctx.Next()
+		}
+	}
+
+	return func(ctx *gin.Context) {
+		k := key(ctx)
+		if k == "" {
+			ctx.Next()
+			return
+		}
+		result := limiter.Allow(k)
+
+		ctx.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+		ctx.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		ctx.Header("RateLimit-Reset", strconv.Itoa(ratelimit.Seconds(result.Reset)))
+
+		if result.Allowed {
+			ctx.Next()
+			return
+		}
+
+		ctx.Header("Retry-After", strconv.Itoa(ratelimit.Seconds(result.RetryAfter)))
+		ctx.AbortWithStatusJSON(
+			http.StatusTooManyRequests,
+			commonModel.Fail[any](errUtil.HandleError(&commonModel.ServerError{
+				Msg: commonModel.TOO_MANY_REQUESTS,
+				Err: nil,
+			})),
+		)
+	}
+}
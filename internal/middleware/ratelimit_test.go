@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lin-snow/ech0/internal/config"
+)
+
+// newRateLimitedEngine 创建只信任本机代理、按客户端 IP 限流的路由
+func newRateLimitedEngine(t *testing.T, rule config.RateLimitRule) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	enabled := config.Config.RateLimit.Enable
+	config.Config.RateLimit.Enable = true
+	t.Cleanup(func() { config.Config.RateLimit.Enable = enabled })
+
+	engine := gin.New()
+	if err := engine.SetTrustedProxies([]string{"127.0.0.1/8", "::1/128"}); err != nil {
+		t.Fatalf("set trusted proxies: %v", err)
+	}
+	engine.GET("/ping", RateLimit(rule, ClientIPKey), func(ctx *gin.Context) {
+		ctx.Status(http.StatusNoContent)
+	})
+	return engine
+}
+
+func get(engine *gin.Engine, remoteAddr, forwardedFor string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.RemoteAddr = remoteAddr
+	if forwardedFor != "" {
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+	}
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	return w
+}
+
+func TestRateLimit(t *testing.T) {
+	engine := newRateLimitedEngine(t, config.RateLimitRule{Rate: 1, Burst: 2})
+
+	for i, remaining := range []string{"1", "0"} {
+		w := get(engine, "10.0.0.1:1234", "")
+		if w.Code != http.StatusNoContent {
+			t.Fatalf("request %d: status = %d", i+1, w.Code)
+		}
+		if w.Header().Get("RateLimit-Limit") != "2" || w.Header().Get("RateLimit-Remaining") != remaining {
+			t.Errorf("request %d: headers = %v", i+1, w.Header())
+		}
+	}
+
+	w := get(engine, "10.0.0.1:1234", "")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want 429", w.Code)
+	}
+	if w.Header().Get("Retry-After") != "1" || w.Header().Get("RateLimit-Reset") != "2" {
+		t.Errorf("headers = %v", w.Header())
+	}
+
+	// 其他客户端不受影响
+	if w := get(engine, "10.0.0.2:1234", ""); w.Code != http.StatusNoContent {
+		t.Errorf("other client status = %d", w.Code)
+	}
+}
+
+func TestRateLimitIgnoresSpoofedForwardedFor(t *testing.T) {
+	engine := newRateLimitedEngine(t, config.RateLimitRule{Rate: 1, Burst: 1})
+
+	// 非受信任的来源伪造 X-Forwarded-For 不能获得新的令牌桶
+	get(engine, "10.0.0.1:1234", "")
+	if w := get(engine, "10.0.0.1:1234", "203.0.113.7"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("spoofed status = %d, want 429", w.Code)
+	}
+
+	// 本机反向代理转发的请求按真实客户端 IP 限流
+	if w := get(engine, "127.0.0.1:5678", "203.0.113.8"); w.Code != http.StatusNoContent {
+		t.Fatalf("proxied status = %d", w.Code)
+	}
+	if w := get(engine, "127.0.0.1:5678", "203.0.113.9"); w.Code != http.StatusNoContent {
+		t.Errorf("second proxied client status = %d", w.Code)
+	}
+	if w := get(engine, "127.0.0.1:5678", "203.0.113.8"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("repeated proxied client status = %d, want 429", w.Code)
+	}
+}
+
+func TestRateLimitDisabled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	enabled := config.Config.RateLimit.Enable
+	config.Config.RateLimit.Enable = false
+	t.Cleanup(func() { config.Config.RateLimit.Enable = enabled })
+
+	engine := gin.New()
+	engine.GET("/ping", RateLimit(config.RateLimitRule{Rate: 1, Burst: 1}, ClientIPKey), func(ctx *gin.Context) {
+		ctx.Status(http.StatusNoContent)
+	})
+	for range 3 {
+		if w := get(engine, "10.0.0.1:1234", ""); w.Code != http.StatusNoContent || w.Header().Get("RateLimit-Limit") != "" {
+			t.Fatalf("status = %d, headers = %v", w.Code, w.Header())
+		}
+	}
+}
+
+func TestUserKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	if key := UserKey(ctx); key != "" {
+		t.Errorf("anonymous key = %q, want empty", key)
+	}
+	ctx.Set("userid", uint(7))
+	if key := UserKey(ctx); key != "user:7" {
+		t.Errorf("key = %q", key)
+	}
+}
@@ -10,14 +10,14 @@ import (
 
 // MyClaims 是自定义的 JWT 声明结构体
 type MyClaims struct {
-	Userid   uint   `json:"user_id"`
-	Username string `json:"username"`
+	Userid       uint   `json:"user_id"`
+	Username     string `json:"username"`
+	SessionID    uint   `json:"sid,omitempty"` // 登录会话 ID，旧版本签发的令牌为 0
+	TokenVersion uint   `json:"ver,omitempty"` // 签发时用户的令牌版本
 	jwt.RegisteredClaims
 }
 
 const (
-	// MAX_USER_COUNT 定义最大用户数量
-	MAX_USER_COUNT = 5
 	// NO_USER_LOGINED 定义未登录用户的 ID
 	NO_USER_LOGINED = uint(0)
 )
@@ -48,6 +48,14 @@ type OAuthState struct {
 	Provider string `json:"provider,omitempty"`
 }
 
+// OAuthLoginCodeTTL OAuth2 登录成功后一次性登录码的有效期
+const OAuthLoginCodeTTL = time.Minute
+
+// OAuthLoginCode OAuth2 回调完成后发给前端的一次性登录码，前端通过 POST 换取令牌，保存在缓存中
+type OAuthLoginCode struct {
+	UserID uint // 登录用户 ID
+}
+
 // GitHubTokenResponse GitHub token 响应结构
 type GitHubTokenResponse struct {
 	AccessToken string `json:"access_token"`
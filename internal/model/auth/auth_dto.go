@@ -1,5 +1,7 @@
 package model
 
+import "time"
+
 // LoginDto 是用户登录时的请求数据传输对象
 type LoginDto struct {
 	Username string `json:"username" binding:"required"`
@@ -11,3 +13,88 @@ type RegisterDto struct {
 	Username string `json:"username" binding:"required"`
 	Password string `json:"password" binding:"required"`
 }
+
+// ClientInfo 发起登录或刷新请求的客户端信息
+type ClientInfo struct {
+	IP        string // 客户端 IP
+	UserAgent string // 客户端 User-Agent
+}
+
+// TokenPairDto 登录成功后返回的令牌对
+type TokenPairDto struct {
+	AccessToken  string `json:"access_token"`  // 短期有效的访问令牌（JWT）
+	RefreshToken string `json:"refresh_token"` // 用于换取新令牌的刷新令牌，每次刷新后轮换
+	TokenType    string `json:"token_type"`    // 令牌类型，固定为 Bearer
+	ExpiresIn    int    `json:"expires_in"`    // 访问令牌有效期，单位为秒
+}
+
+// RefreshTokenDto 刷新令牌请求体
+type RefreshTokenDto struct {
+	RefreshToken string `json:"refresh_token" binding:"required"` // 刷新令牌
+}
+
+// OAuthExchangeDto 使用 OAuth2 一次性登录码换取令牌的请求体
+type OAuthExchangeDto struct {
+	Code string `json:"code" binding:"required"` // OAuth2 回调重定向时附带的一次性登录码
+}
+
+// SessionDto 会话列表项
+type SessionDto struct {
+	Session
+	Current bool `json:"current"` // 是否为当前请求所在的会话
+}
+
+// LoginResultDto 登录结果，启用两步验证时不返回令牌，而是返回登录挑战
+type LoginResultDto struct {
+	TokenPairDto
+	TwoFactorRequired bool   `json:"two_factor_required"`        // 是否需要进行两步验证
+	TwoFactorSetup    bool   `json:"two_factor_setup"`           // 是否需要先绑定认证器，管理员要求启用两步验证但用户尚未绑定时为 true
+	TwoFactorToken    string `json:"two_factor_token,omitempty"` // 两步验证的登录挑战令牌
+}
+
+// TwoFactorLoginDto 两步验证登录请求体
+type TwoFactorLoginDto struct {
+	Token string `json:"token" binding:"required"` // 登录挑战令牌
+	Code  string `json:"code"  binding:"required"` // TOTP 验证码或恢复码
+}
+
+// TwoFactorLoginSetupDto 登录过程中绑定认证器的请求体
+type TwoFactorLoginSetupDto struct {
+	Token string `json:"token" binding:"required"` // 登录挑战令牌
+}
+
+// TwoFactorLoginResultDto 两步验证登录结果，登录过程中完成绑定时附带恢复码
+type TwoFactorLoginResultDto struct {
+	TokenPairDto
+	RecoveryCodes []string `json:"recovery_codes,omitempty"` // 新生成的恢复码，仅返回一次
+}
+
+// TwoFactorCodeDto 携带验证码的请求体
+type TwoFactorCodeDto struct {
+	Code string `json:"code" binding:"required"` // TOTP 验证码或恢复码
+}
+
+// TwoFactorSetupDto 绑定认证器所需的信息
+type TwoFactorSetupDto struct {
+	Secret string `json:"secret"`  // Base32 编码的密钥，用于手动输入
+	URI    string `json:"uri"`     // otpauth URI
+	QRCode string `json:"qr_code"` // otpauth URI 的二维码，PNG 格式的 data URI
+}
+
+// TwoFactorStatusDto 两步验证状态
+type TwoFactorStatusDto struct {
+	Enabled            bool       `json:"enabled"`              // 是否已启用
+	Required           bool       `json:"required"`             // 是否被管理员要求启用
+	EnabledAt          *time.Time `json:"enabled_at"`           // 启用时间
+	RecoveryCodesCount int64      `json:"recovery_codes_count"` // 剩余可用的恢复码数量
+}
+
+// RecoveryCodesDto 恢复码列表，仅在生成时返回一次
+type RecoveryCodesDto struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// TwoFactorRequiredDto 要求用户启用两步验证的请求体
+type TwoFactorRequiredDto struct {
+	Required bool `json:"required"` // 是否要求启用
+}
@@ -0,0 +1,31 @@
+package model
+
+import "time"
+
+// 登录方式
+const (
+	LoginMethodPassword = "password" // 用户名密码登录
+	LoginMethodPasskey  = "passkey"  // Passkey 登录
+	LoginMethodOAuth    = "oauth"    // OAuth2 / OIDC 登录
+)
+
+// Session 定义登录会话实体，每次登录创建一个会话，刷新令牌轮换时复用同一会话
+type Session struct {
+	ID                uint      `gorm:"primaryKey"                   json:"id"`           // 会话 ID
+	UserID            uint      `gorm:"not null;index"               json:"user_id"`      // 会话所属用户 ID
+	RefreshTokenHash  string    `gorm:"type:varchar(64);uniqueIndex" json:"-"`            // 当前刷新令牌的 SHA-256 摘要
+	PreviousTokenHash string    `gorm:"type:varchar(64);index"       json:"-"`            // 上一个刷新令牌的摘要，用于发现刷新令牌被重放
+	TokenVersion      uint      `gorm:"not null;default:0"           json:"-"`            // 创建会话时用户的令牌版本
+	LoginMethod       string    `gorm:"type:varchar(32)"             json:"login_method"` // 登录方式
+	Device            string    `gorm:"type:varchar(128)"            json:"device"`       // 根据 User-Agent 识别的设备
+	UserAgent         string    `gorm:"type:text"                    json:"user_agent"`   // 客户端 User-Agent
+	IP                string    `gorm:"type:varchar(64)"             json:"ip"`           // 最近一次使用的客户端 IP
+	LastUsedAt        time.Time `                                    json:"last_used_at"` // 最近一次登录或刷新时间
+	ExpiresAt         time.Time `gorm:"index"                        json:"expires_at"`   // 会话过期时间，每次刷新后顺延
+	CreatedAt         time.Time `                                    json:"created_at"`   // 会话创建时间
+}
+
+// IsExpired 判断会话是否已过期
+func (session *Session) IsExpired(now time.Time) bool {
+	return !session.ExpiresAt.After(now)
+}
@@ -0,0 +1,43 @@
+package model
+
+import "time"
+
+const (
+	// TwoFactorIssuer 认证器应用中显示的发行方名称
+	TwoFactorIssuer = "Ech0"
+	// RecoveryCodeCount 每次生成的恢复码数量
+	RecoveryCodeCount = 10
+	// TwoFactorChallengeTTL 第一步验证通过后完成第二步验证的有效期
+	TwoFactorChallengeTTL = 5 * time.Minute
+	// TwoFactorMaxAttempts 同一次登录挑战允许输入错误验证码的次数
+	TwoFactorMaxAttempts = 5
+)
+
+// TwoFactor 定义用户的 TOTP 两步验证配置，每个用户最多一条，确认验证码后才会启用
+type TwoFactor struct {
+	ID           uint       `gorm:"primaryKey"             json:"id"`         // 主键 ID
+	UserID       uint       `gorm:"not null;uniqueIndex"   json:"user_id"`    // 所属用户 ID
+	Secret       string     `gorm:"size:64;not null"       json:"-"`          // Base32 编码的 TOTP 密钥
+	Enabled      bool       `gorm:"not null;default:false" json:"enabled"`    // 是否已启用
+	LastUsedStep int64      `gorm:"not null;default:0"     json:"-"`          // 最近一次通过验证的时间步，用于拒绝重放的验证码
+	EnabledAt    *time.Time `                              json:"enabled_at"` // 启用时间
+	CreatedAt    time.Time  `                              json:"created_at"` // 创建时间
+	UpdatedAt    time.Time  `                              json:"updated_at"` // 更新时间
+}
+
+// RecoveryCode 定义两步验证的一次性恢复码，仅保存摘要
+type RecoveryCode struct {
+	ID        uint       `gorm:"primaryKey"                      json:"id"`         // 主键 ID
+	UserID    uint       `gorm:"not null;index"                  json:"user_id"`    // 所属用户 ID
+	CodeHash  string     `gorm:"type:varchar(64);not null;index" json:"-"`          // 恢复码的 SHA-256 摘要
+	UsedAt    *time.Time `                                       json:"used_at"`    // 使用时间，为空表示未使用
+	CreatedAt time.Time  `                                       json:"created_at"` // 创建时间
+}
+
+// TwoFactorChallenge 第一步验证通过、等待第二步验证的登录挑战，保存在缓存中
+type TwoFactorChallenge struct {
+	UserID        uint   // 登录用户 ID
+	LoginMethod   string // 第一步使用的登录方式，完成验证后记录到登录会话
+	SetupRequired bool   // 用户被要求启用两步验证但尚未绑定，需要先完成绑定
+	Attempts      int    // 已输入错误验证码的次数
+}
@@ -5,6 +5,7 @@ type UserStatus struct {
 	UserID   uint   `json:"user_id"`  // 用户ID
 	UserName string `json:"username"` // 用户名
 	IsAdmin  bool   `json:"is_admin"` // 是否是管理员
+	Role     string `json:"role"`     // 用户角色
 }
 
 // Status 用于存储Echo状态信息
@@ -105,9 +106,10 @@ const (
 	NotificationInboxType InboxType = "notification"
 
 	// Inbox 来源
-	SystemSource InboxSource = "system"
-	AgentSource  InboxSource = "agent"
-	UserSource   InboxSource = "user"
+	SystemSource    InboxSource = "system"
+	AgentSource     InboxSource = "agent"
+	UserSource      InboxSource = "user"
+	FediverseSource InboxSource = "fediverse"
 )
 
 // key value表
@@ -146,6 +148,40 @@ type PageQueryResult[T any] struct {
 	Items T     `json:"items"`
 }
 
+const (
+	// DefaultCursorLimit 是游标分页默认返回的数量
+	DefaultCursorLimit = 20
+	// MaxCursorLimit 是游标分页单次返回的最大数量
+	MaxCursorLimit = 100
+)
+
+// CursorQueryResult 用于游标分页查询的结果数据传输对象
+type CursorQueryResult[T any] struct {
+	Items        T    `json:"items"`
+	HasMore      bool `json:"has_more"`                 // 按请求方向是否还有更多数据
+	NextBeforeID uint `json:"next_before_id,omitempty"` // 继续获取更旧数据时使用的 before_id
+	NextSinceID  uint `json:"next_since_id,omitempty"`  // 继续获取更新数据时使用的 since_id
+}
+
+// NewCursorQueryResult 根据从新到旧排列的数据构建游标分页结果，没有新数据时 next_since_id 保持为请求中的 sinceID
+func NewCursorQueryResult[T any](
+	items []T,
+	hasMore bool,
+	sinceID uint,
+	idOf func(T) uint,
+) CursorQueryResult[[]T] {
+	result := CursorQueryResult[[]T]{
+		Items:       items,
+		HasMore:     hasMore,
+		NextSinceID: sinceID,
+	}
+	if len(items) > 0 {
+		result.NextSinceID = idOf(items[0])
+		result.NextBeforeID = idOf(items[len(items)-1])
+	}
+	return result
+}
+
 const (
 	// InitInstallCode 是初始化安装的标志
 	InitInstallCode = 666
@@ -6,7 +6,20 @@ package model
 type PageQueryDto struct {
 	Page     int    `json:"page"     form:"page"`     // 页码，从1开始
 	PageSize int    `json:"pageSize" form:"pageSize"` // 每页大小
-	Search   string `json:"search"   form:"search"`   // 用于搜索的关键字
+	Search   string `json:"search"   form:"search"`   // 用于搜索的关键字，支持 tag:、before:、after:、has:image、is:private、ext:MUSIC、author: 等过滤语法
+}
+
+// CursorQueryDto 用于游标分页查询的请求数据传输对象
+//
+// before_id 与 since_id 同时为空时返回最新的数据；指定 since_id 时从该 ID 之后按顺序返回较新的数据，
+// 同步时间线时不会出现重复或遗漏
+//
+// swagger:model CursorQueryDto
+type CursorQueryDto struct {
+	BeforeID uint   `json:"before_id" form:"before_id"` // 返回排在该 ID 对应数据之后的较旧数据
+	SinceID  uint   `json:"since_id"  form:"since_id"`  // 返回排在该 ID 对应数据之前的较新数据
+	Limit    int    `json:"limit"     form:"limit"`     // 每次返回的数量
+	Search   string `json:"search"    form:"search"`    // 用于搜索的关键字，语法与 PageQueryDto 相同
 }
 
 // ImageDto 用于图片相关的请求数据传输对象
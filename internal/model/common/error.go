@@ -26,13 +26,39 @@ const (
 	TOKEN_NOT_VALID                   = "令牌无效，请重新登录"
 	TOKEN_PARSE_ERROR                 = "令牌解析失败，请尝试重新登陆"
 	USER_REGISTER_NOT_ALLOW           = "当前系统禁止注册新用户"
+	ACCESS_TOKEN_REVOKED              = "访问令牌不存在或已被吊销"
+	ACCESS_TOKEN_EXPIRED              = "访问令牌已过期"
+	ACCESS_TOKEN_SCOPE_DENIED         = "访问令牌未被授予该接口所需的权限范围"
+	ACCESS_TOKEN_NOT_ALLOWED          = "该接口仅允许登录会话访问，不支持访问令牌"
+	SESSION_INVALID                   = "登录会话已失效，请重新登录"
+	SESSION_NOT_FOUND                 = "登录会话不存在"
+	OAUTH_LOGIN_CODE_INVALID          = "登录码无效或已过期，请重新登录"
+	TWO_FACTOR_CHALLENGE_INVALID      = "两步验证已过期，请重新登录"
+	TWO_FACTOR_CODE_INVALID           = "验证码错误"
+	TWO_FACTOR_TOO_MANY_ATTEMPTS      = "验证码错误次数过多，请重新登录"
+	TWO_FACTOR_ALREADY_ENABLED        = "已启用两步验证"
+	TWO_FACTOR_NOT_ENABLED            = "未启用两步验证"
+	TWO_FACTOR_NOT_SETUP              = "请先生成两步验证密钥"
+	TWO_FACTOR_SETUP_NOT_REQUIRED     = "当前登录无需绑定认证器"
+	TWO_FACTOR_REQUIRED_CANNOT_CLOSE  = "管理员要求使用两步验证，无法关闭"
+	TWO_FACTOR_REQUIRED_CANNOT_CHANGE = "无权修改站长的两步验证要求"
+	LOGIN_LOCKED                      = "登录失败次数过多"
+	TOO_MANY_REQUESTS                 = "请求过于频繁，请稍后再试"
 )
 
 // Echo 错误相关常量
 const (
-	NO_PERMISSION_DENIED  = "没有权限,请联系系统管理员"
-	ECHO_CAN_NOT_BE_EMPTY = "ECHO 内容不能为空"
-	ECHO_NOT_FOUND        = "找不到Echo"
+	NO_PERMISSION_DENIED     = "没有权限,请联系系统管理员"
+	ECHO_CAN_NOT_BE_EMPTY    = "ECHO 内容不能为空"
+	ECHO_NOT_FOUND           = "找不到Echo"
+	REPLY_TARGET_NOT_FOUND   = "回复的Echo不存在"
+	QUOTE_TARGET_NOT_FOUND   = "引用的Echo不存在"
+	ECHO_REVISION_NOT_FOUND  = "找不到Echo历史版本"
+	INVALID_ECHO_STATUS      = "无效的Echo发布状态"
+	INVALID_ECHO_VISIBILITY  = "无效的Echo可见性"
+	ECHO_PUBLISH_AT_REQUIRED = "定时发布需要指定发布时间"
+	ECHO_PIN_LIMIT           = "置顶Echo数量已达上限"
+	ECHO_PIN_NOT_PUBLISHED   = "只能置顶已发布的Echo"
 )
 
 // Common 错误相关常量
@@ -68,6 +94,8 @@ const (
 	NO_PERMISSION_BINDING_GOOGLE   = "没有权限绑定 Google 账号"
 	NO_PERMISSION_BINDING_QQ       = "没有权限绑定 QQ 账号"
 	NO_PERMISSION_BINDING_CUSTOM   = "没有权限绑定自定义 OAuth2 账号"
+	USER_ROLE_INVALID              = "无效的用户角色"
+	USER_ROLE_CANNOT_CHANGE        = "不能修改自己或站长的角色"
 )
 
 // TO DO 错误相关常量
@@ -85,22 +113,33 @@ const (
 const (
 	NO_SUCH_COMMENT_PROVIDER            = "无效的评论服务提供者"
 	WEBHOOK_NAME_OR_URL_CANNOT_BE_EMPTY = "未填写 Webhook 名称或 URL"
+	WEBHOOK_EVENT_PATTERN_INVALID       = "无效的 Webhook 事件类型"
+	WEBHOOK_NOT_FOUND                   = "Webhook 不存在"
+	WEBHOOK_DELIVERY_NOT_FOUND          = "Webhook 投递记录不存在"
 	INVALID_CRON_EXPRESSION             = "无效的 Cron 表达式"
+	ACCESS_TOKEN_SCOPE_REQUIRED         = "访问令牌至少需要一个权限范围"
+	ACCESS_TOKEN_SCOPE_INVALID          = "无效的访问令牌权限范围"
 )
 
 // Backup 错误相关常量
 const (
-	SNAPSHOT_UPLOAD_FAILED  = "快照上传失败"
-	SNAPSHOT_RESTORE_FAILED = "快照恢复失败"
-	DATABASE_CLOSE_FAILED   = "数据库关闭失败"
+	SNAPSHOT_UPLOAD_FAILED          = "快照上传失败"
+	SNAPSHOT_RESTORE_FAILED         = "快照恢复失败"
+	DATABASE_CLOSE_FAILED           = "数据库关闭失败"
+	HOT_CHANGE_DATABASE_UNSUPPORTED = "仅 SQLite 数据库支持热切换"
+	DATABASE_DUMP_VERSION_INVALID   = "不支持的数据库导出文件版本"
 )
 
 // Fediverse 错误相关常量
 const (
-	GET_ACTOR_ERROR         = "获取 Actor 信息失败"
-	ACTIVEPUB_NOT_ENABLED   = "ActivityPub 未启用"
-	FEDIVERSE_INVALID_INPUT = "无效的联邦参数"
-	FOLLOW_RELATION_MISSING = "未找到关注关系"
+	GET_ACTOR_ERROR          = "获取 Actor 信息失败"
+	ACTIVEPUB_NOT_ENABLED    = "ActivityPub 未启用"
+	FEDIVERSE_INVALID_INPUT  = "无效的联邦参数"
+	FOLLOW_RELATION_MISSING  = "未找到关注关系"
+	SIGNATURE_INVALID        = "HTTP 签名校验失败"
+	SIGNATURE_ACTOR_MISMATCH = "签名者与 Activity 发起者不一致"
+	OBJECT_NOT_FOUND         = "对象不存在"
+	OBJECT_DELETED           = "对象已删除"
 )
 
 // Agent 错误相关常量
@@ -111,3 +150,33 @@ const (
 	AGENT_MODEL_MISSING      = "未配置 Agent 模型名称或模型名称不能为空"
 	AGENT_SETTING_NOT_FOUND  = "未找到 Agent 设置"
 )
+
+// DeadLetter 错误相关常量
+const (
+	DEAD_LETTER_NOT_FOUND         = "死信任务不存在"
+	DEAD_LETTER_INVALID_STATUS    = "无效的死信任务状态"
+	DEAD_LETTER_ALREADY_COMPLETED = "死信任务已完成，无需重试"
+)
+
+// Trash 错误相关常量
+const (
+	TRASH_ITEM_NOT_FOUND = "回收站中不存在该内容"
+	TRASH_INVALID_TYPE   = "无效的回收站内容类型"
+	TRASH_TODO_LIMIT     = "待办事项数量已达上限，无法恢复"
+)
+
+// Import 错误相关常量
+const (
+	IMPORT_SOURCE_INVALID    = "不支持的导入来源"
+	IMPORT_DATA_NOT_FOUND    = "找不到可导入的数据"
+	IMPORT_STORAGE_INVALID   = "无效的媒体存储方式"
+	IMPORT_ALREADY_RUNNING   = "已有导入任务正在进行"
+	IMPORT_JOB_NOT_FOUND     = "找不到导入任务"
+	IMPORT_UPLOAD_FAILED     = "导入文件上传失败"
+	IMPORT_MEDIA_UNAVAILABLE = "媒体文件不可用"
+)
+
+// Export 错误相关常量
+const (
+	EXPORT_FORMAT_INVALID = "不支持的导出格式"
+)
@@ -4,12 +4,15 @@ package model
 const (
 	INIT_LOGGER_PANIC          = "初始化 Logger 失败"
 	READ_CONFIG_PANIC          = "读取配置文件失败"
+	INVALID_CONFIG_PANIC       = "配置校验失败"
 	CREATE_DB_PATH_PANIC       = "创建数据库路径失败"
 	DATABASE_NOT_INITED        = "数据库未初始化"
 	INIT_DATABASE_PANIC        = "数据库初始化失败"
 	MIGRATE_DB_PANIC           = "数据库迁移失败"
+	SET_TRUSTED_PROXIES_PANIC  = "设置可信代理失败"
 	INIT_HANDLERS_PANIC        = "初始化 Handlers 失败"
 	INIT_TASKER_PANIC          = "初始化 Tasker 失败"
+	INIT_EVENT_BUS_PANIC       = "初始化 EventBus 失败"
 	INIT_EVENT_REGISTRAR_PANIC = "初始化 EventRegistrar 失败"
 	GIN_RUN_FAILED             = "启动 GIN 服务器失败"
 )
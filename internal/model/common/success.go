@@ -7,22 +7,41 @@ const (
 
 // Auth 成功相关常量
 const (
-	LOGIN_SUCCESS    = "登陆成功"
-	REGISTER_SUCCESS = "注册成功"
+	LOGIN_SUCCESS               = "登陆成功"
+	REGISTER_SUCCESS            = "注册成功"
+	REFRESH_TOKEN_SUCCESS       = "刷新令牌成功"
+	LOGOUT_SUCCESS              = "退出登录成功"
+	LIST_SESSIONS_SUCCESS       = "获取登录会话成功"
+	REVOKE_SESSION_SUCCESS      = "注销登录会话成功"
+	REVOKE_ALL_SESSIONS_SUCCESS = "已注销全部登录会话"
+	TWO_FACTOR_REQUIRED         = "请输入两步验证码"
+	TWO_FACTOR_SETUP_REQUIRED   = "管理员要求使用两步验证，请先绑定认证器"
+	GET_TWO_FACTOR_SUCCESS      = "获取两步验证状态成功"
+	TWO_FACTOR_SETUP_SUCCESS    = "生成两步验证密钥成功"
+	ENABLE_TWO_FACTOR_SUCCESS   = "启用两步验证成功"
+	DISABLE_TWO_FACTOR_SUCCESS  = "关闭两步验证成功"
+	RECOVERY_CODES_SUCCESS      = "生成恢复码成功"
+	TWO_FACTOR_REQUIRED_SUCCESS = "更新两步验证要求成功"
 )
 
 // Echo 成功相关常量
 const (
-	POST_ECHO_SUCCESS           = "发布Echo成功！"
-	GET_ECHOS_BY_PAGE_SUCCESS   = "获取Echos成功！"
-	DELETE_ECHO_SUCCESS         = "删除Echo成功"
-	GET_TODAY_ECHOS_SUCCESS     = "获取当日Echos成功"
-	UPDATE_ECHO_SUCCESS         = "更新Echo成功"
-	LIKE_ECHO_SUCCESS           = "点赞Echo成功"
-	GET_ECHO_BY_ID_SUCCESS      = "获取Echo成功"
-	GET_ALL_TAGS_SUCCESS        = "获取所有标签成功"
-	DELETE_TAG_SUCCESS          = "删除标签成功"
-	GET_ECHOS_BY_TAG_ID_SUCCESS = "获取标签下的Echos成功"
+	POST_ECHO_SUCCESS             = "发布Echo成功！"
+	GET_ECHOS_BY_PAGE_SUCCESS     = "获取Echos成功！"
+	DELETE_ECHO_SUCCESS           = "删除Echo成功"
+	GET_TODAY_ECHOS_SUCCESS       = "获取当日Echos成功"
+	UPDATE_ECHO_SUCCESS           = "更新Echo成功"
+	LIKE_ECHO_SUCCESS             = "点赞Echo成功"
+	GET_ECHO_BY_ID_SUCCESS        = "获取Echo成功"
+	GET_ALL_TAGS_SUCCESS          = "获取所有标签成功"
+	DELETE_TAG_SUCCESS            = "删除标签成功"
+	GET_ECHOS_BY_TAG_ID_SUCCESS   = "获取标签下的Echos成功"
+	GET_ECHO_THREAD_SUCCESS       = "获取Echo会话成功"
+	GET_ECHO_REVISIONS_SUCCESS    = "获取Echo历史版本成功"
+	RESTORE_ECHO_REVISION_SUCCESS = "恢复Echo历史版本成功"
+	PIN_ECHO_SUCCESS              = "置顶Echo成功"
+	UNPIN_ECHO_SUCCESS            = "取消置顶Echo成功"
+	GET_UNPUBLISHED_ECHOS_SUCCESS = "获取草稿与定时Echo成功"
 )
 
 // Common 成功相关常量
@@ -62,6 +81,9 @@ const (
 	DELETE_WEBHOOK_SUCCESS            = "删除 Webhook 成功"
 	UPDATE_WEBHOOK_SUCCESS            = "更新 Webhook 成功"
 	CREATE_WEBHOOK_SUCCESS            = "创建 Webhook 成功"
+	GET_WEBHOOK_DELIVERIES_SUCCESS    = "获取 Webhook 投递记录成功"
+	GET_WEBHOOK_DELIVERY_SUCCESS      = "获取 Webhook 投递详情成功"
+	REDELIVER_WEBHOOK_SUCCESS         = "重新投递 Webhook 成功"
 	LIST_ACCESS_TOKENS_SUCCESS        = "列出访问令牌成功"
 	CREATE_ACCESS_TOKEN_SUCCESS       = "创建访问令牌成功"
 	DELETE_ACCESS_TOKEN_SUCCESS       = "删除访问令牌成功"
@@ -87,6 +109,8 @@ const (
 	BIND_GITHUB_SUCCESS       = "绑定 GitHub 账号成功"
 	GET_OAUTH_BINGURL_SUCCESS = "获取绑定 URL 成功"
 	GET_OAUTH_INFO_SUCCESS    = "获取 OAuth2 信息成功"
+	UPDATE_USER_ROLE_SUCCESS  = "更新用户角色成功"
+	GET_ROLES_SUCCESS         = "获取角色权限成功"
 )
 
 // Connect 成功相关常量
@@ -120,3 +144,26 @@ const (
 const (
 	AGENT_GET_RECENT_SUCCESS = "获取近期活动总结成功"
 )
+
+// DeadLetter 成功相关常量
+const (
+	GET_DEAD_LETTERS_SUCCESS    = "获取死信任务列表成功"
+	GET_DEAD_LETTER_SUCCESS     = "获取死信任务成功"
+	RETRY_DEAD_LETTER_SUCCESS   = "死信任务已重新提交"
+	DISCARD_DEAD_LETTER_SUCCESS = "丢弃死信任务成功"
+	PURGE_DEAD_LETTERS_SUCCESS  = "清理死信任务成功"
+)
+
+// Trash 成功相关常量
+const (
+	GET_TRASH_SUCCESS     = "获取回收站内容成功"
+	RESTORE_TRASH_SUCCESS = "恢复成功"
+	PURGE_TRASH_SUCCESS   = "彻底删除成功"
+	EMPTY_TRASH_SUCCESS   = "清空回收站成功"
+)
+
+// Import 成功相关常量
+const (
+	START_IMPORT_SUCCESS   = "导入任务已开始"
+	GET_IMPORT_JOB_SUCCESS = "获取导入进度成功"
+)
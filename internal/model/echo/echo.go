@@ -1,21 +1,63 @@
 package model
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 // Echo 定义Echo实体
 type Echo struct {
-	ID            uint      `gorm:"primaryKey"                                       json:"id"`
-	Content       string    `gorm:"type:text;not null"                               json:"content"`
-	Username      string    `gorm:"type:varchar(100)"                                json:"username,omitempty"`
-	Images        []Image   `gorm:"foreignKey:MessageID;constraint:OnDelete:CASCADE" json:"images,omitempty"`
-	Layout        string    `gorm:"type:varchar(50);default:'waterfall'"             json:"layout,omitempty"`
-	Private       bool      `gorm:"default:false"                                    json:"private"`
-	UserID        uint      `gorm:"not null;index"                                   json:"user_id"`
-	Extension     string    `gorm:"type:text"                                        json:"extension,omitempty"`
-	ExtensionType string    `gorm:"type:varchar(100)"                                json:"extension_type,omitempty"`
-	Tags          []Tag     `gorm:"many2many:echo_tags;"                             json:"tags,omitempty"`
-	FavCount      int       `gorm:"default:0"                                        json:"fav_count"`
-	CreatedAt     time.Time `                                                        json:"created_at"`
+	ID            uint           `gorm:"primaryKey"                                       json:"id"`
+	Content       string         `gorm:"type:text;not null"                               json:"content"`
+	Username      string         `gorm:"type:varchar(100)"                                json:"username,omitempty"`
+	Images        []Image        `gorm:"foreignKey:MessageID;constraint:OnDelete:CASCADE" json:"images,omitempty"`
+	Layout        string         `gorm:"type:varchar(50);default:'waterfall'"             json:"layout,omitempty"`
+	Visibility    string         `gorm:"type:varchar(20);default:'public';index"          json:"visibility"`        // 可见性：public/unlisted/followers/members/private
+	Private       *bool          `gorm:"-"                                                json:"private,omitempty"` // 是否私密，由 visibility 推导，兼容旧版客户端
+	UserID        uint           `gorm:"not null;index"                                   json:"user_id"`
+	Extension     string         `gorm:"type:text"                                        json:"extension,omitempty"`
+	ExtensionType string         `gorm:"type:varchar(100)"                                json:"extension_type,omitempty"`
+	Tags          []Tag          `gorm:"many2many:echo_tags;"                             json:"tags,omitempty"`
+	FavCount      int            `gorm:"default:0"                                        json:"fav_count"`
+	AnnounceCount int            `gorm:"default:0"                                        json:"announce_count"`        // 联邦网络转发数
+	ReplyToID     uint           `gorm:"default:0;index"                                  json:"reply_to_id,omitempty"` // 回复的 Echo ID，0 表示不是回复
+	QuoteOfID     uint           `gorm:"default:0;index"                                  json:"quote_of_id,omitempty"` // 引用的 Echo ID，0 表示没有引用
+	Status        string         `gorm:"type:varchar(20);default:'published';index"       json:"status"`                // 发布状态：published/draft/scheduled
+	PublishAt     *time.Time     `gorm:"index"                                            json:"publish_at,omitempty"`  // 定时发布时间，仅 scheduled 状态有效
+	PinnedAt      *time.Time     `gorm:"index"                                            json:"pinned_at,omitempty"`   // 置顶时间，为空表示未置顶
+	CreatedAt     time.Time      `                                                        json:"created_at"`
+	DeletedAt     gorm.DeletedAt `gorm:"index"                                            json:"deleted_at,omitzero"`   // 移入回收站的时间
+	QuotedEcho    *Echo          `gorm:"-"                                                json:"quoted_echo,omitempty"` // 被引用的 Echo，按需加载，不入库
+	Snippet       string         `gorm:"-"                                                json:"snippet,omitempty"`     // 搜索结果的高亮摘要，不入库
+}
+
+// RemoteReply 联邦网络中远端用户对本地 Echo 的回复
+type RemoteReply struct {
+	ID                     uint      `gorm:"primaryKey"              json:"id"`
+	EchoID                 uint      `gorm:"not null;index"          json:"echo_id"`                  // 被回复的本地 Echo ID
+	ObjectID               string    `gorm:"size:512;uniqueIndex"    json:"object_id"`                // 远端回复 Object 的唯一 URL
+	URL                    string    `gorm:"size:512"                json:"url"`                      // 远端回复的网页地址
+	ActorID                string    `gorm:"size:512;not null;index" json:"actor_id"`                 // 回复者 Actor URL
+	ActorPreferredUsername string    `gorm:"size:128"                json:"actor_preferred_username"` // 回复者用户名
+	ActorDisplayName       string    `gorm:"size:255"                json:"actor_display_name"`       // 回复者显示名称
+	ActorAvatar            string    `gorm:"size:512"                json:"actor_avatar"`             // 回复者头像 URL
+	Content                string    `gorm:"type:text"               json:"content"`                  // 回复内容，通常为 HTML
+	PublishedAt            time.Time `gorm:"index"                   json:"published_at"`             // 回复发布时间
+	CreatedAt              time.Time `gorm:"autoCreateTime"          json:"created_at"`
+}
+
+// EchoThreadNode 会话树中的一个节点
+type EchoThreadNode struct {
+	Echo          Echo             `json:"echo"`                     // 当前 Echo
+	RemoteReplies []RemoteReply    `json:"remote_replies,omitempty"` // 远端用户的回复
+	Replies       []EchoThreadNode `json:"replies,omitempty"`        // 本地回复
+}
+
+// EchoThread 以根 Echo 为起点的完整会话树
+type EchoThread struct {
+	FocusID uint           `json:"focus_id"` // 请求的 Echo ID
+	Root    EchoThreadNode `json:"root"`     // 会话的根节点
 }
 
 // Message 定义Message实体 (注意⚠️: 该模型为旧版Echo模型,新版已经弃用)
@@ -46,10 +88,11 @@ type Image struct {
 
 // Tag 定义Tag实体
 type Tag struct {
-	ID         uint      `gorm:"primaryKey"                            json:"id"`
-	Name       string    `gorm:"type:varchar(50);uniqueIndex;not null" json:"name"`        // 标签名称
-	UsageCount int       `gorm:"default:0"                             json:"usage_count"` // 使用计数
-	CreatedAt  time.Time `                                             json:"created_at"`  // 创建时间
+	ID         uint           `gorm:"primaryKey"                            json:"id"`
+	Name       string         `gorm:"type:varchar(50);uniqueIndex;not null" json:"name"`                // 标签名称
+	UsageCount int            `gorm:"default:0"                             json:"usage_count"`         // 使用计数
+	CreatedAt  time.Time      `                                             json:"created_at"`          // 创建时间
+	DeletedAt  gorm.DeletedAt `gorm:"index"                                 json:"deleted_at,omitzero"` // 移入回收站的时间
 }
 
 // EchoTag 纯关系表，联合主键
@@ -74,4 +117,18 @@ const (
 	LayoutHorizontal = "horizontal" // 横向布局
 	LayoutCarousel   = "carousel"   // 单图轮播布局
 
+	MaxThreadDepth = 64 // 会话树的最大深度，防止异常数据导致无限遍历
+
+	MaxPinnedEchoCount = 5 // 最多置顶的 Echo 数量
 )
+
+const (
+	EchoStatusPublished = "published" // 已发布
+	EchoStatusDraft     = "draft"     // 草稿，仅管理员可见
+	EchoStatusScheduled = "scheduled" // 定时发布，到达 PublishAt 后自动发布
+)
+
+// IsPublished 判断 Echo 是否已发布，旧数据没有状态时视为已发布
+func (echo *Echo) IsPublished() bool {
+	return echo.Status == "" || echo.Status == EchoStatusPublished
+}
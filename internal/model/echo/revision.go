@@ -0,0 +1,70 @@
+package model
+
+import "time"
+
+// EchoRevision Echo 的历史版本，每次更新 Echo 时写入，写入后不再修改
+//
+// 每个版本保存更新后的完整快照，首次更新时会先补录 Echo 的原始内容作为第 1 个版本
+type EchoRevision struct {
+	ID            uint      `gorm:"primaryKey"                             json:"id"`
+	EchoID        uint      `gorm:"not null;uniqueIndex:idx_echo_revision" json:"echo_id"`
+	Revision      int       `gorm:"not null;uniqueIndex:idx_echo_revision" json:"revision"`                 // 版本号，从 1 开始递增
+	UserID        uint      `gorm:"not null;index"                         json:"user_id"`                  // 修改者 ID
+	Username      string    `gorm:"type:varchar(100)"                      json:"username"`                 // 修改者用户名
+	Content       string    `gorm:"type:text"                              json:"content"`                  // 该版本的完整内容
+	Diff          string    `gorm:"type:text"                              json:"diff"`                     // 相对上一版本的内容差异（unified diff）
+	Images        []Image   `gorm:"serializer:json;type:text"              json:"images"`                   // 该版本的图片
+	Tags          []string  `gorm:"serializer:json;type:text"              json:"tags"`                     // 该版本的标签名称
+	Layout        string    `gorm:"type:varchar(50)"                       json:"layout,omitempty"`         // 该版本的图片布局
+	Visibility    string    `gorm:"type:varchar(20);default:'public'"      json:"visibility"`               // 该版本的可见性
+	Extension     string    `gorm:"type:text"                              json:"extension,omitempty"`      // 该版本的扩展内容
+	ExtensionType string    `gorm:"type:varchar(100)"                      json:"extension_type,omitempty"` // 该版本的扩展类型
+	RestoredFrom  int       `gorm:"default:0"                              json:"restored_from,omitempty"`  // 恢复自的版本号，0 表示普通编辑
+	CreatedAt     time.Time `                                              json:"created_at"`
+}
+
+// NewEchoRevision 根据 Echo 的当前状态生成版本快照，版本号、修改者与差异由调用方填写
+func NewEchoRevision(echo *Echo) EchoRevision {
+	images := make([]Image, 0, len(echo.Images))
+	for _, image := range echo.Images {
+		image.ID = 0
+		image.MessageID = 0
+		images = append(images, image)
+	}
+	tags := make([]string, 0, len(echo.Tags))
+	for _, tag := range echo.Tags {
+		tags = append(tags, tag.Name)
+	}
+
+	return EchoRevision{
+		EchoID:        echo.ID,
+		Content:       echo.Content,
+		Images:        images,
+		Tags:          tags,
+		Layout:        echo.Layout,
+		Visibility:    echo.Visibility,
+		Extension:     echo.Extension,
+		ExtensionType: echo.ExtensionType,
+	}
+}
+
+// ToEcho 将版本快照还原为可用于更新的 Echo
+func (revision *EchoRevision) ToEcho() Echo {
+	echo := Echo{
+		ID:            revision.EchoID,
+		Content:       revision.Content,
+		Layout:        revision.Layout,
+		Visibility:    revision.Visibility,
+		Extension:     revision.Extension,
+		ExtensionType: revision.ExtensionType,
+	}
+	for _, image := range revision.Images {
+		image.ID = 0
+		image.MessageID = revision.EchoID
+		echo.Images = append(echo.Images, image)
+	}
+	for _, name := range revision.Tags {
+		echo.Tags = append(echo.Tags, Tag{Name: name})
+	}
+	return echo
+}
@@ -0,0 +1,122 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// 搜索语法中支持的过滤前缀
+const (
+	SearchFilterTag    = "tag"    // tag:标签名，按标签过滤，可重复
+	SearchFilterBefore = "before" // before:2006-01-02，只保留该日期之前（不含当天）发布的 Echo
+	SearchFilterAfter  = "after"  // after:2006-01-02，只保留该日期及之后发布的 Echo
+	SearchFilterHas    = "has"    // has:image，只保留带图片的 Echo
+	SearchFilterIs     = "is"     // is:public / is:unlisted / is:private 等，按可见性过滤
+	SearchFilterExt    = "ext"    // ext:MUSIC，按扩展类型过滤
+	SearchFilterAuthor = "author" // author:用户名，按作者过滤
+
+	SearchDateLayout = "2006-01-02" // before/after 使用的日期格式
+)
+
+// SearchQuery 解析后的 Echo 搜索条件
+type SearchQuery struct {
+	Terms         []string   // 全文检索词，多个词之间为 AND 关系
+	Tags          []string   // 必须同时包含的标签
+	Before        *time.Time // 发布时间上限（不含）
+	After         *time.Time // 发布时间下限（含）
+	HasImage      bool       // 是否只保留带图片的 Echo
+	Visibility    string     // 可见性过滤，为空时不过滤
+	ExtensionType string     // 扩展类型
+	Author        string     // 作者用户名
+}
+
+// IsEmpty 判断是否没有任何搜索条件
+func (q SearchQuery) IsEmpty() bool {
+	return len(q.Terms) == 0 && len(q.Tags) == 0 && q.Before == nil && q.After == nil &&
+		!q.HasImage && q.Visibility == "" && q.ExtensionType == "" && q.Author == ""
+}
+
+// ParseSearchQuery 解析搜索语法
+//
+// 例如 `咖啡 tag:生活 after:2024-01-01 has:image`，带引号的内容作为一个完整的词，
+// 无法识别的过滤条件按普通检索词处理
+func ParseSearchQuery(raw string) SearchQuery {
+	var q SearchQuery
+	for _, token := range splitSearchTokens(raw) {
+		key, value, ok := strings.Cut(token, ":")
+		if ok && value != "" && q.applyFilter(strings.ToLower(key), value) {
+			continue
+		}
+		if term := strings.Trim(token, `"`); term != "" {
+			q.Terms = append(q.Terms, term)
+		}
+	}
+	return q
+}
+
+// applyFilter 应用单个过滤条件，返回 false 表示不是合法的过滤条件
+func (q *SearchQuery) applyFilter(key, value string) bool {
+	value = strings.Trim(value, `"`)
+	switch key {
+	case SearchFilterTag:
+		q.Tags = append(q.Tags, strings.TrimPrefix(value, "#"))
+	case SearchFilterBefore, SearchFilterAfter:
+		date, err := time.ParseInLocation(SearchDateLayout, value, time.Local)
+		if err != nil {
+			return false
+		}
+		if key == SearchFilterBefore {
+			q.Before = &date
+		} else {
+			q.After = &date
+		}
+	case SearchFilterHas:
+		if strings.ToLower(value) != "image" {
+			return false
+		}
+		q.HasImage = true
+	case SearchFilterIs:
+		visibility := strings.ToLower(value)
+		if !IsValidVisibility(visibility) {
+			return false
+		}
+		q.Visibility = visibility
+	case SearchFilterExt:
+		q.ExtensionType = strings.ToUpper(value)
+	case SearchFilterAuthor:
+		q.Author = value
+	default:
+		return false
+	}
+	return true
+}
+
+// splitSearchTokens 按空白拆分搜索语句，双引号内的空白不拆分
+func splitSearchTokens(raw string) []string {
+	var (
+		tokens  []string
+		current strings.Builder
+		quoted  bool
+	)
+	flush := func() {
+		if current.Len() > 0 {
+			tokens = append(tokens, current.String())
+			current.Reset()
+		}
+	}
+
+	for _, r := range raw {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			current.WriteRune(r)
+		case !quoted && (r == ' ' || r == '\t' || r == '\n' || r == '　'):
+			flush()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	flush()
+
+	return tokens
+}
@@ -0,0 +1,74 @@
+package model
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParseSearchQuery(t *testing.T) {
+	date := func(s string) *time.Time {
+		d, _ := time.ParseInLocation(SearchDateLayout, s, time.Local)
+		return &d
+	}
+
+	tests := map[string]struct {
+		raw  string
+		want SearchQuery
+	}{
+		"只有检索词": {
+			raw:  "咖啡  tea",
+			want: SearchQuery{Terms: []string{"咖啡", "tea"}},
+		},
+		"全角空格分隔": {
+			raw:  "咖啡　tea",
+			want: SearchQuery{Terms: []string{"咖啡", "tea"}},
+		},
+		"引号内作为一个词": {
+			raw:  `"hello world" go`,
+			want: SearchQuery{Terms: []string{"hello world", "go"}},
+		},
+		"过滤条件": {
+			raw: "咖啡 tag:生活 tag:#日常 after:2024-01-01 before:2024-02-01 has:image is:Unlisted ext:music author:alice",
+			want: SearchQuery{
+				Terms:         []string{"咖啡"},
+				Tags:          []string{"生活", "日常"},
+				After:         date("2024-01-01"),
+				Before:        date("2024-02-01"),
+				HasImage:      true,
+				Visibility:    VisibilityUnlisted,
+				ExtensionType: "MUSIC",
+				Author:        "alice",
+			},
+		},
+		"过滤值带引号": {
+			raw:  `tag:"旅行 日记"`,
+			want: SearchQuery{Tags: []string{"旅行 日记"}},
+		},
+		"无法识别的过滤条件按检索词处理": {
+			raw:  "after:yesterday has:video is:secret http://x tag:",
+			want: SearchQuery{Terms: []string{"after:yesterday", "has:video", "is:secret", "http://x", "tag:"}},
+		},
+		"空查询": {raw: "  ", want: SearchQuery{}},
+	}
+
+	for name, tt := range tests {
+		got := ParseSearchQuery(tt.raw)
+		if !slices.Equal(got.Terms, tt.want.Terms) || !slices.Equal(got.Tags, tt.want.Tags) ||
+			!sameDate(got.Before, tt.want.Before) || !sameDate(got.After, tt.want.After) ||
+			got.HasImage != tt.want.HasImage || got.Visibility != tt.want.Visibility ||
+			got.ExtensionType != tt.want.ExtensionType || got.Author != tt.want.Author {
+			t.Errorf("%s: ParseSearchQuery(%q) = %+v, want %+v", name, tt.raw, got, tt.want)
+		}
+		if got.IsEmpty() != (name == "空查询") {
+			t.Errorf("%s: IsEmpty = %v", name, got.IsEmpty())
+		}
+	}
+}
+
+func sameDate(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
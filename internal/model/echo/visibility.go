@@ -0,0 +1,108 @@
+package model
+
+import "gorm.io/gorm"
+
+// Echo 可见性
+const (
+	VisibilityPublic    = "public"    // 公开，出现在时间线中并推送到联邦网络
+	VisibilityUnlisted  = "unlisted"  // 不公开列出，不出现在时间线中，但可以通过链接访问
+	VisibilityFollowers = "followers" // 仅关注者，联邦网络中只推送给关注者，本站仅登录用户可见
+	VisibilityMembers   = "members"   // 仅登录用户可见，不推送到联邦网络
+	VisibilityPrivate   = "private"   // 私密，仅管理员可见
+)
+
+// Audience 查看 Echo 的用户身份
+type Audience int
+
+const (
+	AudienceGuest  Audience = iota // 未登录的访客
+	AudienceMember                 // 已登录的普通用户
+	AudienceAdmin                  // 管理员
+)
+
+// IsValidVisibility 判断可见性是否有效
+func IsValidVisibility(visibility string) bool {
+	switch visibility {
+	case VisibilityPublic, VisibilityUnlisted, VisibilityFollowers, VisibilityMembers, VisibilityPrivate:
+		return true
+	default:
+		return false
+	}
+}
+
+// ListedVisibilities 返回出现在该身份可见的时间线、列表与统计中的可见性
+//
+// 不公开列出的 Echo 只出现在管理员的列表中
+func ListedVisibilities(audience Audience) []string {
+	switch audience {
+	case AudienceAdmin:
+		return []string{
+			VisibilityPublic,
+			VisibilityUnlisted,
+			VisibilityFollowers,
+			VisibilityMembers,
+			VisibilityPrivate,
+		}
+	case AudienceMember:
+		return []string{VisibilityPublic, VisibilityFollowers, VisibilityMembers}
+	default:
+		return []string{VisibilityPublic}
+	}
+}
+
+// ReachableVisibilities 返回无需登录即可通过链接访问的可见性，用于联邦网络的 Outbox 与 Object
+func ReachableVisibilities() []string {
+	return []string{VisibilityPublic, VisibilityUnlisted}
+}
+
+// CanView 判断该身份能否通过链接查看指定可见性的 Echo
+func CanView(audience Audience, visibility string) bool {
+	switch visibility {
+	case VisibilityPublic, VisibilityUnlisted, "":
+		return true
+	case VisibilityFollowers, VisibilityMembers:
+		return audience >= AudienceMember
+	default:
+		return audience == AudienceAdmin
+	}
+}
+
+// IsFederated 判断 Echo 是否推送到联邦网络
+func (echo *Echo) IsFederated() bool {
+	switch echo.Visibility {
+	case VisibilityPublic, VisibilityUnlisted, VisibilityFollowers, "":
+		return true
+	default:
+		return false
+	}
+}
+
+// NormalizeVisibility 补全 Echo 的可见性
+//
+// 旧版客户端只提交 private 字段：private 为 true 时设为私密，
+// 为 false 且原可见性为私密时设为公开，其余情况以 visibility 为准
+func (echo *Echo) NormalizeVisibility() {
+	if echo.Private != nil {
+		if *echo.Private && echo.Visibility != VisibilityPrivate {
+			echo.Visibility = VisibilityPrivate
+		}
+		if !*echo.Private && echo.Visibility == VisibilityPrivate {
+			echo.Visibility = VisibilityPublic
+		}
+	}
+	if echo.Visibility == "" {
+		echo.Visibility = VisibilityPublic
+	}
+	echo.syncPrivate()
+}
+
+// AfterFind 查询后填充兼容旧版客户端的 private 字段
+func (echo *Echo) AfterFind(tx *gorm.DB) error {
+	echo.syncPrivate()
+	return nil
+}
+
+func (echo *Echo) syncPrivate() {
+	private := echo.Visibility == VisibilityPrivate
+	echo.Private = &private
+}
@@ -0,0 +1,73 @@
+package model
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestVisibilityAccess(t *testing.T) {
+	// 每种可见性在各身份下能否通过链接查看、能否出现在列表中，以及是否推送到联邦网络
+	tests := map[string]struct {
+		canView   [3]bool // 访客、登录用户、管理员
+		listed    [3]bool
+		federated bool
+	}{
+		VisibilityPublic:    {canView: [3]bool{true, true, true}, listed: [3]bool{true, true, true}, federated: true},
+		VisibilityUnlisted:  {canView: [3]bool{true, true, true}, listed: [3]bool{false, false, true}, federated: true},
+		VisibilityFollowers: {canView: [3]bool{false, true, true}, listed: [3]bool{false, true, true}, federated: true},
+		VisibilityMembers:   {canView: [3]bool{false, true, true}, listed: [3]bool{false, true, true}},
+		VisibilityPrivate:   {canView: [3]bool{false, false, true}, listed: [3]bool{false, false, true}},
+	}
+
+	audiences := []Audience{AudienceGuest, AudienceMember, AudienceAdmin}
+	for visibility, tt := range tests {
+		if !IsValidVisibility(visibility) {
+			t.Errorf("%s: not valid", visibility)
+		}
+		for i, audience := range audiences {
+			if got := CanView(audience, visibility); got != tt.canView[i] {
+				t.Errorf("%s: CanView(%d) = %v", visibility, audience, got)
+			}
+			if got := slices.Contains(ListedVisibilities(audience), visibility); got != tt.listed[i] {
+				t.Errorf("%s: listed for %d = %v", visibility, audience, got)
+			}
+		}
+		echo := Echo{Visibility: visibility}
+		if got := echo.IsFederated(); got != tt.federated {
+			t.Errorf("%s: IsFederated = %v", visibility, got)
+		}
+		reachable := slices.Contains(ReachableVisibilities(), visibility)
+		if reachable != tt.canView[0] {
+			t.Errorf("%s: reachable = %v", visibility, reachable)
+		}
+	}
+
+	if IsValidVisibility("") || IsValidVisibility("secret") {
+		t.Error("invalid visibility accepted")
+	}
+}
+
+func TestNormalizeVisibility(t *testing.T) {
+	private := func(b bool) *bool { return &b }
+
+	tests := map[string]struct {
+		echo        Echo
+		want        string
+		wantPrivate bool
+	}{
+		"未指定时公开":              {echo: Echo{}, want: VisibilityPublic},
+		"以 visibility 为准":     {echo: Echo{Visibility: VisibilityMembers}, want: VisibilityMembers},
+		"旧版客户端设为私密":           {echo: Echo{Private: private(true)}, want: VisibilityPrivate, wantPrivate: true},
+		"旧版客户端取消私密":           {echo: Echo{Visibility: VisibilityPrivate, Private: private(false)}, want: VisibilityPublic},
+		"private 为 false 不降级": {echo: Echo{Visibility: VisibilityFollowers, Private: private(false)}, want: VisibilityFollowers},
+		"private 为 true 优先":   {echo: Echo{Visibility: VisibilityUnlisted, Private: private(true)}, want: VisibilityPrivate, wantPrivate: true},
+	}
+
+	for name, tt := range tests {
+		echo := tt.echo
+		echo.NormalizeVisibility()
+		if echo.Visibility != tt.want || echo.Private == nil || *echo.Private != tt.wantPrivate {
+			t.Errorf("%s: visibility %q private %v", name, echo.Visibility, echo.Private)
+		}
+	}
+}
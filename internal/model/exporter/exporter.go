@@ -0,0 +1,43 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// 导出格式
+const (
+	ExportFormatMarkdown = "md"    // 每条 Echo 一个带 YAML front-matter 的 Markdown 文件，附带媒体目录
+	ExportFormatJSONL    = "jsonl" // 每行一条 Echo 的 JSON
+	ExportFormatHTML     = "html"  // 可直接浏览的静态 HTML 站点，附带媒体目录
+)
+
+const (
+	ExportBatchSize    = 200 // 导出时每批读取的 Echo 数量
+	ExportHTMLPageSize = 20  // 静态站点每页展示的 Echo 数量
+)
+
+// ExportFile 导出文件的信息
+type ExportFile struct {
+	Filename    string `json:"filename"`     // 文件名
+	ContentType string `json:"content_type"` // 文件类型
+}
+
+// IsValidExportFormat 判断导出格式是否有效
+func IsValidExportFormat(format string) bool {
+	switch format {
+	case ExportFormatMarkdown, ExportFormatJSONL, ExportFormatHTML:
+		return true
+	default:
+		return false
+	}
+}
+
+// NewExportFile 根据导出格式生成导出文件的信息，Markdown 与 HTML 打包为 ZIP
+func NewExportFile(format string, now time.Time) ExportFile {
+	name := fmt.Sprintf("ech0-export-%s-%s", format, now.Format("2006-01-02-150405"))
+	if format == ExportFormatJSONL {
+		return ExportFile{Filename: name + ".jsonl", ContentType: "application/x-ndjson"}
+	}
+	return ExportFile{Filename: name + ".zip", ContentType: "application/zip"}
+}
@@ -0,0 +1,34 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewExportFile(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := map[string]struct {
+		format string
+		valid  bool
+		want   ExportFile
+	}{
+		"Markdown": {format: ExportFormatMarkdown, valid: true, want: ExportFile{Filename: "ech0-export-md-2024-01-02-030405.zip", ContentType: "application/zip"}},
+		"JSONL":    {format: ExportFormatJSONL, valid: true, want: ExportFile{Filename: "ech0-export-jsonl-2024-01-02-030405.jsonl", ContentType: "application/x-ndjson"}},
+		"HTML":     {format: ExportFormatHTML, valid: true, want: ExportFile{Filename: "ech0-export-html-2024-01-02-030405.zip", ContentType: "application/zip"}},
+		"不支持的格式":   {format: "pdf"},
+		"大小写敏感":    {format: "MD"},
+	}
+
+	for name, tt := range tests {
+		if got := IsValidExportFormat(tt.format); got != tt.valid {
+			t.Errorf("%s: IsValidExportFormat = %v", name, got)
+		}
+		if !tt.valid {
+			continue
+		}
+		if got := NewExportFile(tt.format, now); got != tt.want {
+			t.Errorf("%s: NewExportFile = %+v, want %+v", name, got, tt.want)
+		}
+	}
+}
@@ -21,8 +21,30 @@ const (
 	ActivityTypeAccept   string = "Accept"
 	ActivityTypeAnnounce string = "Announce"
 	ActivityTypeUndo     string = "Undo"
+	ActivityTypeReject   string = "Reject"
+	ActivityTypeDelete   string = "Delete"
+	ActivityTypeUpdate   string = "Update"
 )
 
+// ObjectTypeTombstone 已删除对象的占位类型
+const ObjectTypeTombstone string = "Tombstone"
+
+// PublicAddress 表示所有人可见的特殊收件人地址
+const PublicAddress string = "https://www.w3.org/ns/activitystreams#Public"
+
+// HTTP 签名校验相关配置
+const (
+	SignatureDateTolerance = 5 * time.Minute // Date 头允许的最大时间偏差
+	PublicKeyCacheTTL      = 24 * time.Hour  // 远端公钥缓存时间
+	PublicKeyCachePrefix   = "fediverse:publickey:"
+)
+
+// MaxInboxBodyBytes Inbox 请求体的最大字节数
+const MaxInboxBodyBytes = 1 << 20
+
+// RequiredSignedHeaders Inbox 请求签名必须覆盖的头部
+var RequiredSignedHeaders = []string{"(request-target)", "host", "date", "digest"}
+
 const (
 	DefaultCollectionPageSize = 20
 	MaxCollectionPageSize     = 80
@@ -69,6 +91,8 @@ type Object struct {
 	ObjectID     string         `gorm:"size:512;unique;not null" json:"id"`                     // 全局唯一 URL
 	Type         string         `gorm:"size:64;not null"         json:"type"`                   // Note, Article, Image...
 	AttributedTo string         `gorm:"size:512"                 json:"attributedTo,omitempty"` // actor URL
+	InReplyTo    string         `gorm:"size:512"                 json:"inReplyTo,omitempty"`    // 回复的 Object URL
+	QuoteURL     string         `gorm:"size:512"                 json:"quoteUrl,omitempty"`     // 引用的 Object URL (FEP-e232 / Misskey 兼容)
 	Content      string         `gorm:"type:text"                json:"content,omitempty"`      // 主要内容
 	Source       map[string]any `gorm:"-"                        json:"source,omitempty"`       // 原始内容，可能包含 mediaType 和 content 字段
 	Attachments  []Attachment   `gorm:"-"                        json:"attachment,omitempty"`   // 附件 URL 列表，序列化存储
@@ -79,6 +103,17 @@ type Object struct {
 	CreatedAt    time.Time      `gorm:"autoCreateTime"           json:"-"`
 }
 
+// Tombstone 表：记录已删除的本地对象，供 GetObject 返回 410
+type Tombstone struct {
+	Context    any       `gorm:"-"                        json:"@context,omitempty"`
+	ID         uint      `gorm:"primaryKey;autoIncrement" json:"-"`
+	ObjectID   string    `gorm:"-"                        json:"id"`         // 被删除对象的全局 URL，按当前服务器地址生成
+	Type       string    `gorm:"size:64;not null"         json:"type"`       // 固定为 Tombstone
+	FormerType string    `gorm:"size:64"                  json:"formerType"` // 删除前的类型，例如 Note
+	EchoID     uint      `gorm:"not null;uniqueIndex"     json:"-"`          // 被删除的 Echo ID
+	Deleted    time.Time `                                json:"deleted"`    // 删除时间
+}
+
 // Attachment 附件对象
 type Attachment struct {
 	Type      string   `json:"type"`               // "Image"、"Video" 等
@@ -109,19 +144,20 @@ type PublicKey struct {
 
 // Actor ActivityPub Actor 信息
 type Actor struct {
-	Context           []interface{} `json:"@context"`          // 上下文，可以是字符串或对象的数组
-	ID                string        `json:"id"`                // Actor 的唯一标识 URL，格式通常为 http(s)://domain/users/username
-	Type              string        `json:"type"`              // Actor 类型，通常为 "Person"
-	Name              string        `json:"name"`              // 显示名称
-	PreferredUsername string        `json:"preferredUsername"` // 用户名
-	Summary           string        `json:"summary"`           // 简短介绍
-	Icon              Preview       `json:"icon,omitempty"`    // 头像信息
-	Image             Preview       `json:"image,omitempty"`   // 封面图片
-	Followers         string        `json:"followers"`         // 粉丝列表 URL
-	Following         string        `json:"following"`         // 关注列表 URL
-	Inbox             string        `json:"inbox"`             // 收件箱 URL
-	Outbox            string        `json:"outbox"`            // 发件箱 URL
-	PublicKey         PublicKey     `json:"publicKey"`         // 公钥信息
+	Context           []interface{} `json:"@context"`           // 上下文，可以是字符串或对象的数组
+	ID                string        `json:"id"`                 // Actor 的唯一标识 URL，格式通常为 http(s)://domain/users/username
+	Type              string        `json:"type"`               // Actor 类型，通常为 "Person"
+	Name              string        `json:"name"`               // 显示名称
+	PreferredUsername string        `json:"preferredUsername"`  // 用户名
+	Summary           string        `json:"summary"`            // 简短介绍
+	Icon              Preview       `json:"icon,omitempty"`     // 头像信息
+	Image             Preview       `json:"image,omitempty"`    // 封面图片
+	Followers         string        `json:"followers"`          // 粉丝列表 URL
+	Following         string        `json:"following"`          // 关注列表 URL
+	Inbox             string        `json:"inbox"`              // 收件箱 URL
+	Outbox            string        `json:"outbox"`             // 发件箱 URL
+	Featured          string        `json:"featured,omitempty"` // 置顶帖子集合 URL
+	PublicKey         PublicKey     `json:"publicKey"`          // 公钥信息
 }
 
 // Follow 表：存储关注请求及状态
@@ -138,10 +174,22 @@ type Follow struct {
 
 // Follower 表：存储已接受的关注关系
 type Follower struct {
-	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
-	ActorID   string    `gorm:"size:512;not null;index"  json:"actor_id"` // 粉丝 Actor URL, 格式通常为 http(s)://domain/users/username
-	UserID    uint      `gorm:"not null;index"           json:"user_id"`  // 被关注用户的数据库 ID
-	CreatedAt time.Time `gorm:"autoCreateTime"           json:"created_at"`
+	ID         uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	ActorID    string    `gorm:"size:512;not null;index"  json:"actor_id"`    // 粉丝 Actor URL, 格式通常为 http(s)://domain/users/username
+	UserID     uint      `gorm:"not null;index"           json:"user_id"`     // 被关注用户的数据库 ID
+	ActivityID string    `gorm:"size:512;index"           json:"activity_id"` // 对方 Follow 活动 ID，用于识别仅携带 ID 的 Undo
+	CreatedAt  time.Time `gorm:"autoCreateTime"           json:"created_at"`
+}
+
+// Interaction 表：存储远端 Actor 对本地 Echo 的互动 (Like, Announce)，便于撤销时回滚计数
+type Interaction struct {
+	ID         uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID     uint      `gorm:"not null;index"           json:"user_id"`     // 被互动用户的数据库 ID
+	EchoID     uint      `gorm:"not null;index"           json:"echo_id"`     // 被互动的 Echo ID
+	ActorID    string    `gorm:"size:512;not null;index"  json:"actor_id"`    // 发起互动的 Actor URL
+	ActivityID string    `gorm:"size:512;unique;not null" json:"activity_id"` // Like/Announce 活动 ID，便于撤销
+	Type       string    `gorm:"size:64;not null"         json:"type"`        // Like, Announce
+	CreatedAt  time.Time `gorm:"autoCreateTime"           json:"created_at"`
 }
 
 // InboxStatus 收件箱中存储的远端推文记录，供后续时间线展示使用
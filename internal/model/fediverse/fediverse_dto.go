@@ -40,6 +40,15 @@ type OutboxPage struct {
 	OrderedItems []Activity `json:"orderedItems"`
 }
 
+// FeaturedResponse 置顶帖子集合，Mastodon 等实现会将其展示为置顶帖子
+type FeaturedResponse struct {
+	Context      any      `json:"@context"`
+	ID           string   `json:"id"`
+	Type         string   `json:"type"` // "OrderedCollection"
+	TotalItems   int      `json:"totalItems"`
+	OrderedItems []Object `json:"orderedItems"`
+}
+
 // FollowersResponse 跟 OutboxResponse 类似
 type FollowersResponse struct {
 	Context    any    `json:"@context"`
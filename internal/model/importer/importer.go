@@ -0,0 +1,56 @@
+package model
+
+import "time"
+
+// 导入来源
+const (
+	SourceMemos    = "memos"    // Memos 的 SQLite 数据库或 API 导出的 JSON
+	SourceMastodon = "mastodon" // Mastodon 归档中的 outbox.json
+	SourceTwitter  = "twitter"  // Twitter/X 归档中的 tweets.js
+)
+
+// 导入任务状态
+const (
+	ImportStatusRunning   = "running"   // 进行中
+	ImportStatusCompleted = "completed" // 已完成
+	ImportStatusFailed    = "failed"    // 失败
+)
+
+const (
+	MaxImportErrors = 50 // 导入进度中最多保留的错误信息数量
+	MaxFinishedJobs = 10 // 内存中最多保留的已结束导入任务数量
+)
+
+// ImportOptions 导入选项
+type ImportOptions struct {
+	Source     string `json:"source"`     // 导入来源
+	DryRun     bool   `json:"dry_run"`    // 只解析并统计，不写入数据
+	Storage    string `json:"storage"`    // 媒体文件的存储方式：local/s3，默认为 local
+	Visibility string `json:"visibility"` // 覆盖所有导入内容的可见性，为空时按来源中的可见性映射
+	BaseURL    string `json:"base_url"`   // 来源站点地址，用于下载 API 导出数据中引用的媒体文件
+}
+
+// ImportProgress 导入任务的进度
+type ImportProgress struct {
+	ID            string     `json:"id"`                    // 任务 ID
+	Source        string     `json:"source"`                // 导入来源
+	DryRun        bool       `json:"dry_run"`               // 是否为试运行
+	Status        string     `json:"status"`                // 任务状态
+	Total         int        `json:"total"`                 // 解析出的内容总数
+	Processed     int        `json:"processed"`             // 已处理的内容数
+	Imported      int        `json:"imported"`              // 已导入的内容数，试运行时为将要导入的数量
+	Skipped       int        `json:"skipped"`               // 跳过的内容数（重复导入、回复他人等）
+	Failed        int        `json:"failed"`                // 导入失败的内容数
+	MediaImported int        `json:"media_imported"`        // 已导入的媒体文件数
+	MediaFailed   int        `json:"media_failed"`          // 导入失败的媒体文件数
+	Errors        []string   `json:"errors,omitempty"`      // 错误信息，最多保留 MaxImportErrors 条
+	StartedAt     time.Time  `json:"started_at"`            // 开始时间
+	FinishedAt    *time.Time `json:"finished_at,omitempty"` // 结束时间
+}
+
+// AddError 记录一条错误信息，超过上限后不再记录
+func (progress *ImportProgress) AddError(message string) {
+	if len(progress.Errors) < MaxImportErrors {
+		progress.Errors = append(progress.Errors, message)
+	}
+}
@@ -1,6 +1,7 @@
 package model
 
 import (
+	"encoding/json"
 	"time"
 )
 
@@ -9,6 +10,12 @@ const (
 	DeadLetterTypeWebhook = "webhook"
 	// DeadLetterTypePushEchoFediverse 联邦宇宙相关的 push 类型的死信任务
 	DeadLetterTypePushEchoFediverse = "push_echo_fediverse"
+	// DeadLetterTypeUpdateEchoFediverse 联邦宇宙相关的 update 类型的死信任务
+	DeadLetterTypeUpdateEchoFediverse = "update_echo_fediverse"
+	// DeadLetterTypeDeleteEchoFediverse 联邦宇宙相关的 delete 类型的死信任务
+	DeadLetterTypeDeleteEchoFediverse = "delete_echo_fediverse"
+	// DeadLetterTypeEvent 持久化事件总线中超过重试次数的事件
+	DeadLetterTypeEvent = "event"
 )
 
 const (
@@ -28,22 +35,151 @@ const (
 	DeadLetterMetaKey = "dead_letter" // 存储在 Payload 中的元数据键
 )
 
+// DeadLetterProcessingTimeout 死信任务处于处理中状态的最长时间，超过后视为进程中断，重新置为待处理
+const DeadLetterProcessingTimeout = 30 * time.Minute
+
+// redactedPayloadValue 敏感字段隐藏后的占位值
+const redactedPayloadValue = "******"
+
+// sensitivePayloadKeys 负载中需要隐藏的字段，包括 webhook 签名密钥与用户密码哈希
+var sensitivePayloadKeys = map[string]struct{}{
+	"secret":   {},
+	"password": {},
+}
+
 // ReplayPayload 重放任务的载荷，使用 map 以支持灵活的字段
 type ReplayPayload map[string]any
 
 // DeadLetter 死信任务模型
 type DeadLetter struct {
-	ID         int64     `gorm:"primaryKey"  json:"id"`          // 任务 ID
-	Type       string    `gorm:"type"        json:"type"`        // 业务类型，如 "webhook" / "push" / "email"
-	Payload    []byte    `gorm:"payload"     json:"payload"`     // 原始任务数据（序列化 JSON）
-	ErrorMsg   string    `gorm:"error_msg"   json:"error_msg"`   // 失败原因（错误信息）
-	RetryCount int       `gorm:"retry_count" json:"retry_count"` // 重试次数
-	NextRetry  time.Time `gorm:"next_retry"  json:"next_retry"`  // 下次重试时间（指数退避）
-	Status     string    `gorm:"status"      json:"status"`      // 任务状态，如 "pending", "processing", "failed", "completed","discarded"
-	CreatedAt  time.Time `gorm:"created_at"  json:"created_at"`
-	UpdatedAt  time.Time `gorm:"updated_at"  json:"updated_at"`
+	ID         int64     `gorm:"primaryKey"       json:"id"`          // 任务 ID
+	Type       string    `gorm:"type:varchar(50)" json:"type"`        // 业务类型，如 "webhook" / "push" / "email"
+	Payload    []byte    `gorm:"payload"          json:"payload"`     // 原始任务数据（序列化 JSON）
+	ErrorMsg   string    `gorm:"error_msg"        json:"error_msg"`   // 失败原因（错误信息）
+	RetryCount int       `gorm:"retry_count"      json:"retry_count"` // 重试次数
+	NextRetry  time.Time `gorm:"next_retry"       json:"next_retry"`  // 下次重试时间（指数退避）
+	Status     string    `gorm:"status"           json:"status"`      // 任务状态，如 "pending", "processing", "failed", "completed","discarded"
+	CreatedAt  time.Time `gorm:"created_at"       json:"created_at"`
+	UpdatedAt  time.Time `gorm:"updated_at"       json:"updated_at"`
 }
 
 func (dl *DeadLetter) SetType(t string) {
 	dl.Type = t
 }
+
+// IsValidDeadLetterStatus 判断是否为合法的死信任务状态
+func IsValidDeadLetterStatus(status string) bool {
+	switch status {
+	case DeadLetterStatusPending,
+		DeadLetterStatusProcessing,
+		DeadLetterStatusFailed,
+		DeadLetterStatusCompleted,
+		DeadLetterStatusDiscarded:
+		return true
+	}
+	return false
+}
+
+// Redacted 返回隐藏了负载中敏感字段的副本，用于管理接口与命令行展示，重试仍使用原始负载
+func (dl DeadLetter) Redacted() DeadLetter {
+	var payload any
+	if err := json.Unmarshal(dl.Payload, &payload); err != nil {
+		dl.Payload = nil
+		return dl
+	}
+	if redacted, err := json.Marshal(redactPayload(payload)); err == nil {
+		dl.Payload = redacted
+	} else {
+		dl.Payload = nil
+	}
+	return dl
+}
+
+// redactPayload 递归隐藏负载中的敏感字段
+func redactPayload(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			if _, ok := sensitivePayloadKeys[key]; ok {
+				if s, isString := item.(string); !isString || s != "" {
+					v[key] = redactedPayloadValue
+				}
+				continue
+			}
+			v[key] = redactPayload(item)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = redactPayload(item)
+		}
+	}
+	return value
+}
+
+// ResetForRetry 重置死信任务，使其在下一次消费时立即重试
+func (dl *DeadLetter) ResetForRetry(now time.Time) {
+	dl.Status = DeadLetterStatusPending
+	dl.RetryCount = 0
+	dl.NextRetry = now
+	dl.UpdatedAt = now
+}
+
+// Discard 丢弃死信任务，不再重试
+func (dl *DeadLetter) Discard(now time.Time) {
+	dl.Status = DeadLetterStatusDiscarded
+	dl.UpdatedAt = now
+}
+
+// BackoffPolicy 死信任务的重试退避策略
+type BackoffPolicy struct {
+	InitialBackoff time.Duration // 首次重试间隔
+	MaxBackoff     time.Duration // 最大重试间隔
+	Multiplier     float64       // 每次重试间隔的增长倍数
+	MaxRetries     int           // 最大重试次数
+}
+
+// DefaultBackoffPolicy 默认的重试退避策略
+var DefaultBackoffPolicy = BackoffPolicy{
+	InitialBackoff: 10 * time.Minute,
+	MaxBackoff:     24 * time.Hour,
+	Multiplier:     4,
+	MaxRetries:     5,
+}
+
+// Delay 计算已重试 retryCount 次后的下一次重试间隔
+func (p BackoffPolicy) Delay(retryCount int) time.Duration {
+	delay := float64(p.InitialBackoff)
+	for i := 0; i < retryCount; i++ {
+		delay *= p.Multiplier
+		if delay >= float64(p.MaxBackoff) {
+			return p.MaxBackoff
+		}
+	}
+	return time.Duration(delay)
+}
+
+// NextRetry 计算已重试 retryCount 次后的下一次重试时间
+func (p BackoffPolicy) NextRetry(retryCount int, now time.Time) time.Time {
+	return now.Add(p.Delay(retryCount))
+}
+
+// Exhausted 判断是否已达到最大重试次数
+func (p BackoffPolicy) Exhausted(retryCount int) bool {
+	return retryCount >= p.MaxRetries
+}
+
+// OutboxEvent 持久化事件总线的事件记录，自增 ID 决定投递顺序
+type OutboxEvent struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement"        json:"id"`         // 自增序号
+	EventID   string    `gorm:"type:varchar(64);uniqueIndex"    json:"event_id"`   // 事件 ID
+	Type      string    `gorm:"type:varchar(100);index"         json:"type"`       // 事件类型
+	Data      string    `gorm:"type:text"                       json:"data"`       // 序列化后的事件（JSON）
+	CreatedAt time.Time `gorm:"index"                           json:"created_at"` // 写入时间
+}
+
+// EventCursor 持久化事件总线的订阅者游标，记录订阅者已处理到的事件序号
+type EventCursor struct {
+	Subscriber  string    `gorm:"primaryKey;type:varchar(255)" json:"subscriber"`    // 订阅者名称
+	LastEventID uint      `gorm:"default:0"                    json:"last_event_id"` // 已处理的最后一个事件序号
+	UpdatedAt   time.Time `                                    json:"updated_at"`    // 更新时间
+}
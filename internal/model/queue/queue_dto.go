@@ -0,0 +1,8 @@
+package model
+
+// DeadLetterQueryDto 死信任务分页查询参数
+type DeadLetterQueryDto struct {
+	Page     int    `json:"page"     form:"page"`     // 页码，从1开始
+	PageSize int    `json:"pageSize" form:"pageSize"` // 每页大小
+	Status   string `json:"status"   form:"status"`   // 按状态过滤，为空表示全部
+}
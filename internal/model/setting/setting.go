@@ -1,6 +1,9 @@
 package model
 
-import "time"
+import (
+	"path"
+	"time"
+)
 
 const (
 	EIGHT_HOUR_EXPIRY string = "8_hours"
@@ -8,6 +11,35 @@ const (
 	NEVER_EXPIRY      string = "never"
 )
 
+const (
+	// AccessTokenPrefix 访问令牌前缀，用于与登录 JWT 区分
+	AccessTokenPrefix = "ech0_"
+	// AccessTokenDisplayLength 列表中展示的令牌前缀长度
+	AccessTokenDisplayLength = 12
+)
+
+// 访问令牌权限范围
+const (
+	ScopeEchoRead       = "echo:read"       // 读取 Echo
+	ScopeEchoWrite      = "echo:write"      // 发布、修改、删除 Echo 及上传媒体
+	ScopeTodoRead       = "todo:read"       // 读取待办事项
+	ScopeTodoWrite      = "todo:write"      // 修改待办事项
+	ScopeSettingsAdmin  = "settings:admin"  // 管理系统设置
+	ScopeFediverseRead  = "fediverse:read"  // 读取联邦网络设置
+	ScopeFediverseWrite = "fediverse:write" // 修改联邦网络设置
+)
+
+// AccessTokenScopes 所有可授予访问令牌的权限范围
+var AccessTokenScopes = []string{
+	ScopeEchoRead,
+	ScopeEchoWrite,
+	ScopeTodoRead,
+	ScopeTodoWrite,
+	ScopeSettingsAdmin,
+	ScopeFediverseRead,
+	ScopeFediverseWrite,
+}
+
 // SystemSetting 定义系统设置实体
 type SystemSetting struct {
 	SiteTitle       string `json:"site_title"`        // 站点标题
@@ -63,12 +95,49 @@ type OAuth2Setting struct {
 
 // AccessTokenSetting 定义访问令牌设置实体
 type AccessTokenSetting struct {
-	ID        int        `json:"id"`         // 访问令牌 ID
-	UserID    uint       `json:"user_id"`    // 创建该访问令牌的用户 ID
-	Token     string     `json:"token"`      // 访问令牌
-	Name      string     `json:"name"`       // 访问令牌名称
-	Expiry    *time.Time `json:"expiry"`     // 指针类型，NULL 表示永不过期
-	CreatedAt time.Time  `json:"created_at"` // 访问令牌创建时间，Unix 时间戳格式
+	ID         int        `                                    json:"id"`           // 访问令牌 ID
+	UserID     uint       `                                    json:"user_id"`      // 创建该访问令牌的用户 ID
+	TokenHash  string     `gorm:"type:varchar(64);uniqueIndex" json:"-"`            // 访问令牌的 SHA-256 摘要，明文仅在创建时返回一次
+	Prefix     string     `gorm:"type:varchar(32)"             json:"prefix"`       // 访问令牌前缀，便于辨认
+	Name       string     `                                    json:"name"`         // 访问令牌名称
+	Scopes     []string   `gorm:"serializer:json;type:text"    json:"scopes"`       // 权限范围，支持通配符（如 todo:*）
+	Expiry     *time.Time `                                    json:"expiry"`       // 指针类型，NULL 表示永不过期
+	LastUsedAt *time.Time `                                    json:"last_used_at"` // 最近使用时间
+	LastUsedIP string     `gorm:"type:varchar(64)"             json:"last_used_ip"` // 最近使用的客户端 IP
+	CreatedAt  time.Time  `                                    json:"created_at"`   // 访问令牌创建时间，Unix 时间戳格式
+}
+
+// HasScope 判断访问令牌是否被授予了指定权限范围
+func (token *AccessTokenSetting) HasScope(scope string) bool {
+	for _, pattern := range token.Scopes {
+		if matched, err := path.Match(pattern, scope); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// IsExpired 判断访问令牌是否已过期
+func (token *AccessTokenSetting) IsExpired(now time.Time) bool {
+	return token.Expiry != nil && !token.Expiry.After(now)
+}
+
+// IsValidScopePattern 判断权限范围（可含通配符）是否至少匹配一个已知权限范围
+func IsValidScopePattern(pattern string) bool {
+	for _, scope := range AccessTokenScopes {
+		if matched, err := path.Match(pattern, scope); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// AccessTokenDisplayPrefix 返回访问令牌用于展示的前缀
+func AccessTokenDisplayPrefix(token string) string {
+	if len(token) <= AccessTokenDisplayLength {
+		return token
+	}
+	return token[:AccessTokenDisplayLength]
 }
 
 // FediverseSetting 定义联邦网络设置实体
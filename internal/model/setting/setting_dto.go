@@ -56,15 +56,19 @@ type OAuth2Status struct {
 }
 
 type WebhookDto struct {
-	Name     string `json:"name"`                                 // Webhook 名称
-	URL      string `json:"url"`                                  // Webhook URL
-	Secret   string `json:"secret,omitempty"`                     // 签名密钥，用于请求验证（HMAC等）
-	IsActive bool   `json:"is_active"        gorm:"default:true"` // 启用/禁用状态
+	Name       string   `json:"name"`                                 // Webhook 名称
+	URL        string   `json:"url"`                                  // Webhook URL
+	Secret     string   `json:"secret,omitempty"`                     // 签名密钥，用于请求验证（HMAC等）
+	IsActive   bool     `json:"is_active"        gorm:"default:true"` // 启用/禁用状态
+	Events     []string `json:"events"`                               // 订阅的事件类型，支持通配符（如 echo.*），为空表示订阅全部事件
+	OnlyPublic bool     `json:"only_public"`                          // 仅推送公开 Echo 的事件
+	TagFilter  string   `json:"tag_filter"`                           // 仅推送包含该标签的 Echo 事件，为空表示不过滤
 }
 
 type AccessTokenSettingDto struct {
-	Name   string `json:"name"`   // 访问令牌名称
-	Expiry string `json:"expiry"` // 访问令牌过期时间，Unix 时间戳格式
+	Name   string   `json:"name"`   // 访问令牌名称
+	Expiry string   `json:"expiry"` // 访问令牌过期时间，Unix 时间戳格式
+	Scopes []string `json:"scopes"` // 权限范围，支持通配符（如 todo:*）
 }
 
 type FediverseSettingDto struct {
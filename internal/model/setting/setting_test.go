@@ -0,0 +1,88 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAccessTokenHasScope(t *testing.T) {
+	tests := map[string]struct {
+		scopes []string
+		scope  string
+		want   bool
+	}{
+		"没有权限范围":    {nil, ScopeEchoRead, false},
+		"精确匹配":      {[]string{ScopeEchoRead}, ScopeEchoRead, true},
+		"读权限不含写权限":  {[]string{ScopeEchoRead}, ScopeEchoWrite, false},
+		"资源通配符":     {[]string{"todo:*"}, ScopeTodoWrite, true},
+		"资源通配符不跨资源": {[]string{"todo:*"}, ScopeEchoRead, false},
+		"旧令牌的全部权限":  {[]string{"*"}, ScopeSettingsAdmin, true},
+		"多个权限范围之一":  {[]string{ScopeEchoRead, ScopeFediverseWrite}, ScopeFediverseWrite, true},
+		"非法的通配符":    {[]string{"echo:["}, ScopeEchoRead, false},
+	}
+
+	for name, tt := range tests {
+		token := AccessTokenSetting{Scopes: tt.scopes}
+		if got := token.HasScope(tt.scope); got != tt.want {
+			t.Errorf("%s: HasScope(%q) = %v, want %v", name, tt.scope, got, tt.want)
+		}
+	}
+}
+
+func TestIsValidScopePattern(t *testing.T) {
+	tests := map[string]bool{
+		ScopeEchoRead:      true,
+		ScopeSettingsAdmin: true,
+		"fediverse:*":      true,
+		"*":                true,
+		"echo:delete":      false,
+		"users:*":          false,
+		"echo:[":           false,
+		"":                 false,
+	}
+
+	for pattern, want := range tests {
+		if got := IsValidScopePattern(pattern); got != want {
+			t.Errorf("IsValidScopePattern(%q) = %v, want %v", pattern, got, want)
+		}
+	}
+}
+
+func TestAccessTokenIsExpired(t *testing.T) {
+	now := time.Now()
+	at := func(d time.Duration) *time.Time {
+		expiry := now.Add(d)
+		return &expiry
+	}
+
+	tests := map[string]struct {
+		expiry *time.Time
+		want   bool
+	}{
+		"永不过期": {nil, false},
+		"尚未过期": {at(time.Minute), false},
+		"恰好到期": {at(0), true},
+		"已经过期": {at(-time.Minute), true},
+	}
+
+	for name, tt := range tests {
+		token := AccessTokenSetting{Expiry: tt.expiry}
+		if got := token.IsExpired(now); got != tt.want {
+			t.Errorf("%s: IsExpired = %v, want %v", name, got, tt.want)
+		}
+	}
+}
+
+func TestAccessTokenDisplayPrefix(t *testing.T) {
+	tests := map[string]string{
+		"ech0_0123456789abcdef": "ech0_0123456",
+		"ech0_short":            "ech0_short",
+		"":                      "",
+	}
+
+	for token, want := range tests {
+		if got := AccessTokenDisplayPrefix(token); got != want {
+			t.Errorf("AccessTokenDisplayPrefix(%q) = %q, want %q", token, got, want)
+		}
+	}
+}
@@ -1,15 +1,20 @@
 package model
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 // Todo 定义待办事项实体
 type Todo struct {
-	ID        uint      `gorm:"primaryKey"         json:"id"`
-	Content   string    `gorm:"type:text;not null" json:"content"`
-	UserID    uint      `gorm:"not null;index"     json:"user_id"`
-	Username  string    `gorm:"type:varchar(100)"  json:"username,omitempty"`
-	Status    uint      `gorm:"default:0"          json:"status"` // 0:未完成 1:已完成
-	CreatedAt time.Time `                          json:"created_at"`
+	ID        uint           `gorm:"primaryKey"         json:"id"`
+	Content   string         `gorm:"type:text;not null" json:"content"`
+	UserID    uint           `gorm:"not null;index"     json:"user_id"`
+	Username  string         `gorm:"type:varchar(100)"  json:"username,omitempty"`
+	Status    uint           `gorm:"default:0"          json:"status"` // 0:未完成 1:已完成
+	CreatedAt time.Time      `                          json:"created_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index"              json:"deleted_at,omitzero"` // 移入回收站的时间
 }
 
 // Todo 相关状态常量
@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+// 回收站内容类型
+const (
+	TrashTypeEcho = "echo" // Echo
+	TrashTypeTag  = "tag"  // 标签
+	TrashTypeTodo = "todo" // 待办事项
+)
+
+// TrashSummaryLength 回收站列表中内容摘要的最大字符数
+const TrashSummaryLength = 100
+
+// TrashItem 回收站中的一项内容
+type TrashItem struct {
+	Type      string     `json:"type"`               // 内容类型
+	ID        uint       `json:"id"`                 // 内容 ID
+	Summary   string     `json:"summary"`            // 内容摘要
+	DeletedAt time.Time  `json:"deleted_at"`         // 移入回收站的时间
+	PurgeAt   *time.Time `json:"purge_at,omitempty"` // 自动彻底删除的时间，未开启自动清理时为空
+}
+
+// IsValidTrashType 判断回收站内容类型是否有效
+func IsValidTrashType(itemType string) bool {
+	switch itemType {
+	case TrashTypeEcho, TrashTypeTag, TrashTypeTodo:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,6 @@
+package model
+
+// TrashQueryDto 回收站查询参数
+type TrashQueryDto struct {
+	Type string `json:"type" form:"type"` // 按内容类型过滤，为空表示全部
+}
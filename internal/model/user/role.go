@@ -0,0 +1,89 @@
+package model
+
+import "slices"
+
+// Role 用户角色
+type Role string
+
+const (
+	RoleOwner  Role = "owner"  // 站长，首个注册的用户，拥有全部权限
+	RoleAdmin  Role = "admin"  // 管理员，可以管理全部内容、用户与大部分设置
+	RoleEditor Role = "editor" // 编辑，可以以自己的名义发布并管理自己的 Echo
+	RoleViewer Role = "viewer" // 成员，只能浏览登录用户可见的内容
+)
+
+// Permission 权限
+type Permission string
+
+const (
+	PermissionEchoWrite      Permission = "echo:write"          // 发布 Echo，编辑与删除自己的 Echo
+	PermissionEchoManage     Permission = "echo:manage"         // 管理所有人的 Echo、标签、置顶与定时发布
+	PermissionMediaUpload    Permission = "media:upload"        // 上传与删除图片、音频等媒体文件
+	PermissionTodoManage     Permission = "todo:manage"         // 管理自己的待办事项
+	PermissionAccessToken    Permission = "access_token:manage" // 管理自己的访问令牌
+	PermissionOAuthBind      Permission = "oauth:bind"          // 绑定第三方登录账号
+	PermissionInboxManage    Permission = "inbox:manage"        // 管理收件箱
+	PermissionQueueManage    Permission = "queue:manage"        // 管理任务队列中的死信任务
+	PermissionTrashManage    Permission = "trash:manage"        // 管理回收站
+	PermissionConnectManage  Permission = "connect:manage"      // 管理 Ech0 Connect 连接
+	PermissionContentImport  Permission = "content:import"      // 从其他平台导入内容
+	PermissionContentExport  Permission = "content:export"      // 导出全部内容
+	PermissionUserManage     Permission = "user:manage"         // 管理用户与用户角色
+	PermissionSettingsManage Permission = "settings:manage"     // 管理评论、存储、OAuth2、Webhook、联邦与 Agent 等设置
+	PermissionSystemSetting  Permission = "system:setting"      // 修改系统设置
+	PermissionBackupCreate   Permission = "backup:create"       // 创建与导出备份
+	PermissionBackupRestore  Permission = "backup:restore"      // 恢复备份
+)
+
+// Roles 全部角色，按权限从高到低排列
+var Roles = []Role{RoleOwner, RoleAdmin, RoleEditor, RoleViewer}
+
+// editorPermissions 编辑拥有的权限
+var editorPermissions = []Permission{
+	PermissionEchoWrite,
+	PermissionMediaUpload,
+	PermissionTodoManage,
+	PermissionAccessToken,
+	PermissionOAuthBind,
+}
+
+// adminPermissions 管理员拥有的权限，站长额外拥有修改系统设置与恢复备份的权限
+var adminPermissions = append(slices.Clone(editorPermissions),
+	PermissionEchoManage,
+	PermissionInboxManage,
+	PermissionQueueManage,
+	PermissionTrashManage,
+	PermissionConnectManage,
+	PermissionContentImport,
+	PermissionContentExport,
+	PermissionUserManage,
+	PermissionSettingsManage,
+	PermissionBackupCreate,
+)
+
+// rolePermissions 角色权限表
+var rolePermissions = map[Role][]Permission{
+	RoleOwner: append(slices.Clone(adminPermissions),
+		PermissionSystemSetting,
+		PermissionBackupRestore,
+	),
+	RoleAdmin:  adminPermissions,
+	RoleEditor: editorPermissions,
+	RoleViewer: {},
+}
+
+// IsValidRole 检查角色是否有效
+func IsValidRole(role string) bool {
+	_, ok := rolePermissions[Role(role)]
+	return ok
+}
+
+// Can 检查角色是否拥有指定权限
+func (role Role) Can(permission Permission) bool {
+	return slices.Contains(rolePermissions[role], permission)
+}
+
+// Permissions 返回角色拥有的全部权限
+func (role Role) Permissions() []Permission {
+	return slices.Clone(rolePermissions[role])
+}
@@ -6,11 +6,25 @@ const (
 
 // User 定义用户实体
 type User struct {
-	ID       uint   `gorm:"primaryKey"               json:"id"`
-	Username string `gorm:"size:255;not null;unique" json:"username"`
-	Password string `gorm:"size:255;not null"        json:"password"`
-	IsAdmin  bool   `gorm:"bool"                     json:"is_admin"`
-	Avatar   string `gorm:"size:255"                 json:"avatar"`
+	ID                uint   `gorm:"primaryKey"                      json:"id"`
+	Username          string `gorm:"size:255;not null;unique"        json:"username"`
+	Password          string `gorm:"size:255;not null"               json:"password"`
+	Role              Role   `gorm:"size:16;not null;default:viewer" json:"role"`
+	IsAdmin           bool   `gorm:"bool"                            json:"is_admin"` // 兼容旧版本的管理员标记，由角色决定，站长与管理员为 true
+	Avatar            string `gorm:"size:255"                        json:"avatar"`
+	TokenVersion      uint   `gorm:"not null;default:0"              json:"-"`                   // 令牌版本，修改密码时递增，使已签发的登录令牌全部失效
+	TwoFactorRequired bool   `gorm:"not null;default:false"          json:"two_factor_required"` // 是否被管理员要求使用两步验证登录
+}
+
+// SetRole 设置用户角色，并同步兼容旧版本的管理员标记
+func (user *User) SetRole(role Role) {
+	user.Role = role
+	user.IsAdmin = role == RoleOwner || role == RoleAdmin
+}
+
+// Can 检查用户是否拥有指定权限
+func (user *User) Can(permission Permission) bool {
+	return user.Role.Can(permission)
 }
 
 type OAuthBinding struct {
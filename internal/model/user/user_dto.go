@@ -21,6 +21,21 @@ type UserInfoDto struct {
 	Avatar string `json:"avatar"`
 }
 
+// UserRoleDto 修改用户角色数据传输对象
+//
+// swagger:model UserRoleDto
+type UserRoleDto struct {
+	// 新的角色，可选 admin、editor、viewer
+	// example: editor
+	Role string `json:"role" binding:"required"`
+}
+
+// RoleDto 角色及其拥有的权限
+type RoleDto struct {
+	Role        Role         `json:"role"`
+	Permissions []Permission `json:"permissions"`
+}
+
 // OAuthInfoDto OAuth2 信息数据传输对象
 type OAuthInfoDto struct {
 	Provider string `json:"provider"`
@@ -1,16 +1,103 @@
 package model
 
-import "time"
+import (
+	"path"
+	"strings"
+	"time"
+)
 
 // Webhook 定义 Webhook 设置实体
 type Webhook struct {
-	ID          uint      `gorm:"primaryKey"   json:"id"`           // Webhook ID
-	Name        string    `                    json:"name"`         // Webhook 名称
-	URL         string    `                    json:"url"`          // Webhook URL
-	Secret      string    `                    json:"secret"`       // 签名密钥，用于请求验证（HMAC等）
-	IsActive    bool      `gorm:"default:true" json:"is_active"`    // 启用/禁用状态
-	LastStatus  string    `                    json:"last_status"`  // 最近调用状态（如 success, failed）
-	LastTrigger time.Time `                    json:"last_trigger"` // 最近触发时间
-	CreatedAt   time.Time `                    json:"created_at"`   // 创建时间
-	UpdatedAt   time.Time `                    json:"updated_at"`   // 更新时间
+	ID          uint      `gorm:"primaryKey"                json:"id"`           // Webhook ID
+	Name        string    `                                 json:"name"`         // Webhook 名称
+	URL         string    `                                 json:"url"`          // Webhook URL
+	Secret      string    `                                 json:"secret"`       // 签名密钥，用于请求验证（HMAC等）
+	IsActive    bool      `gorm:"default:true"              json:"is_active"`    // 启用/禁用状态
+	Events      []string  `gorm:"serializer:json;type:text" json:"events"`       // 订阅的事件类型，支持通配符（如 echo.*），为空表示订阅全部事件
+	OnlyPublic  bool      `gorm:"default:false"             json:"only_public"`  // 仅推送公开 Echo 的事件
+	TagFilter   string    `gorm:"type:varchar(50)"          json:"tag_filter"`   // 仅推送包含该标签的 Echo 事件，为空表示不过滤
+	LastStatus  string    `                                 json:"last_status"`  // 最近调用状态（如 success, failed）
+	LastTrigger time.Time `                                 json:"last_trigger"` // 最近触发时间
+	CreatedAt   time.Time `                                 json:"created_at"`   // 创建时间
+	UpdatedAt   time.Time `                                 json:"updated_at"`   // 更新时间
+}
+
+// ShouldHandle 判断 Webhook 是否订阅了该事件类型
+func (wh *Webhook) ShouldHandle(eventType string) bool {
+	if len(wh.Events) == 0 {
+		return true
+	}
+
+	for _, pattern := range wh.Events {
+		if matched, err := path.Match(pattern, eventType); err == nil && matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HasEchoFilter 判断 Webhook 是否配置了 Echo 负载过滤条件
+func (wh *Webhook) HasEchoFilter() bool {
+	return wh.OnlyPublic || wh.TagFilter != ""
+}
+
+// MatchEcho 判断 Echo 是否满足 Webhook 的负载过滤条件
+func (wh *Webhook) MatchEcho(public bool, tags []string) bool {
+	if wh.OnlyPublic && !public {
+		return false
+	}
+
+	if wh.TagFilter == "" {
+		return true
+	}
+	for _, tag := range tags {
+		if strings.EqualFold(tag, wh.TagFilter) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ValidateEventPatterns 校验事件通配符是否合法
+func ValidateEventPatterns(patterns []string) bool {
+	for _, pattern := range patterns {
+		if pattern == "" {
+			return false
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+const (
+	StatusSuccess = "success" // 最近一次调用成功
+	StatusFailed  = "failed"  // 最近一次调用失败
+
+	DeliveryResponseBodyLimit = 4 * 1024            // 投递记录中保存的响应体最大字节数
+	DeliveryRetention         = 14 * 24 * time.Hour // 投递记录保留时长，超过后由定时任务清理
+)
+
+// WebhookDelivery 定义 Webhook 投递记录实体，每次请求尝试保存一条
+type WebhookDelivery struct {
+	ID             uint      `gorm:"primaryKey"          json:"id"`                      // 投递记录 ID
+	WebhookID      uint      `gorm:"index;not null"      json:"webhook_id"`              // 关联的 Webhook ID
+	EventID        string    `gorm:"type:varchar(64)"    json:"event_id"`                // 事件 ID
+	EventType      string    `gorm:"type:varchar(100)"   json:"event_type"`              // 事件类型
+	Attempt        int       `gorm:"default:1"           json:"attempt"`                 // 第几次尝试
+	RequestURL     string    `gorm:"type:text"           json:"request_url"`             // 请求地址
+	RequestHeaders string    `gorm:"type:text"           json:"request_headers"`         // 请求头（JSON）
+	RequestBody    string    `gorm:"type:text"           json:"request_body"`            // 请求体
+	StatusCode     int       `gorm:"default:0"           json:"status_code"`             // 响应状态码，请求未发出时为 0
+	ResponseBody   string    `gorm:"type:text"           json:"response_body"`           // 响应体（截断）
+	LatencyMs      int64     `gorm:"default:0"           json:"latency_ms"`              // 请求耗时（毫秒）
+	Success        bool      `gorm:"default:false"       json:"success"`                 // 是否投递成功
+	Error          string    `gorm:"type:text"           json:"error,omitempty"`         // 失败原因
+	DeadLetterID   int64     `gorm:"index;default:0"     json:"dead_letter_id"`          // 转入死信队列后的死信任务 ID
+	RedeliveryOf   uint      `gorm:"default:0"           json:"redelivery_of,omitempty"` // 手动重新投递时对应的原投递记录 ID
+	CreatedAt      time.Time `gorm:"index"               json:"created_at"`              // 创建时间
 }
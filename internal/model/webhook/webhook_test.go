@@ -0,0 +1,69 @@
+package model
+
+import "testing"
+
+func TestShouldHandle(t *testing.T) {
+	tests := map[string]struct {
+		events    []string
+		eventType string
+		want      bool
+	}{
+		"no subscription":    {nil, "system.backup", true},
+		"exact match":        {[]string{"echo.created"}, "echo.created", true},
+		"exact mismatch":     {[]string{"echo.created"}, "echo.updated", false},
+		"wildcard":           {[]string{"echo.*"}, "echo.deleted", true},
+		"wildcard other":     {[]string{"echo.*"}, "user.created", false},
+		"wildcard is prefix": {[]string{"echo.*"}, "echoes.created", false},
+		"match all":          {[]string{"*"}, "deadletter.retried", true},
+		"any of several":     {[]string{"user.*", "echo.created"}, "echo.created", true},
+		"malformed pattern":  {[]string{"echo.["}, "echo.created", false},
+	}
+
+	for name, tt := range tests {
+		wh := Webhook{Events: tt.events}
+		if got := wh.ShouldHandle(tt.eventType); got != tt.want {
+			t.Errorf("%s: ShouldHandle(%q) = %v, want %v", name, tt.eventType, got, tt.want)
+		}
+	}
+}
+
+func TestMatchEcho(t *testing.T) {
+	tests := map[string]struct {
+		webhook Webhook
+		public  bool
+		tags    []string
+		want    bool
+	}{
+		"no filter":           {Webhook{}, false, nil, true},
+		"only public, public": {Webhook{OnlyPublic: true}, true, nil, true},
+		"only public, hidden": {Webhook{OnlyPublic: true}, false, nil, false},
+		"tag present":         {Webhook{TagFilter: "Go"}, false, []string{"rust", "go"}, true},
+		"tag missing":         {Webhook{TagFilter: "go"}, true, []string{"rust"}, false},
+		"both, tag missing":   {Webhook{OnlyPublic: true, TagFilter: "go"}, true, nil, false},
+		"both, not public":    {Webhook{OnlyPublic: true, TagFilter: "go"}, false, []string{"go"}, false},
+	}
+
+	for name, tt := range tests {
+		if got := tt.webhook.MatchEcho(tt.public, tt.tags); got != tt.want {
+			t.Errorf("%s: MatchEcho = %v, want %v", name, got, tt.want)
+		}
+	}
+}
+
+func TestValidateEventPatterns(t *testing.T) {
+	tests := map[string]struct {
+		patterns []string
+		want     bool
+	}{
+		"empty list": {nil, true},
+		"valid":      {[]string{"echo.*", "user.created"}, true},
+		"empty item": {[]string{"echo.*", ""}, false},
+		"malformed":  {[]string{"echo.["}, false},
+	}
+
+	for name, tt := range tests {
+		if got := ValidateEventPatterns(tt.patterns); got != tt.want {
+			t.Errorf("%s: ValidateEventPatterns(%q) = %v, want %v", name, tt.patterns, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+// Package ratelimit 提供基于内存的令牌桶限流与登录失败锁定，供路由中间件与各 service 共用
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval 清理闲置令牌桶与锁定记录的最小间隔
+const sweepInterval = time.Minute
+
+// Result 一次限流判断的结果，用于生成 RateLimit-* 响应头
+type Result struct {
+	Allowed    bool          // 是否放行
+	Limit      int           // 桶容量
+	Remaining  int           // 剩余可用令牌数
+	Reset      time.Duration // 令牌桶恢复满额所需的时间
+	RetryAfter time.Duration // 被拒绝时距下一个可用令牌的时间
+}
+
+// bucket 单个 key 的令牌桶
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// Limiter 按 key 划分的令牌桶限流器，每个 key 以 rate 个/秒的速度补充令牌，最多积攒 burst 个
+type Limiter struct {
+	rate  float64
+	burst int
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// NewLimiter 创建令牌桶限流器，rate 或 burst 不大于 0 时返回 nil 表示不限流
+func NewLimiter(rate float64, burst int) *Limiter {
+	if rate <= 0 || burst <= 0 {
+		return nil
+	}
+	return &Limiter{
+		rate:      rate,
+		burst:     burst,
+		buckets:   make(map[string]*bucket),
+		lastSweep: time.Now(),
+	}
+}
+
+// Allow 为 key 消耗一个令牌，令牌不足时拒绝
+func (l *Limiter) Allow(key string) Result {
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(l.burst), last: now}
+		l.buckets[key] = b
+	} else {
+		b.tokens = l.refill(b, now)
+		b.last = now
+	}
+
+	result := Result{Limit: l.burst}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = l.duration(1 - b.tokens)
+	}
+	result.Remaining = int(math.Floor(b.tokens))
+	result.Reset = l.duration(float64(l.burst) - b.tokens)
+	return result
+}
+
+// refill 计算令牌桶在 now 时刻的令牌数
+func (l *Limiter) refill(b *bucket, now time.Time) float64 {
+	tokens := b.tokens + now.Sub(b.last).Seconds()*l.rate
+	return math.Min(tokens, float64(l.burst))
+}
+
+// duration 补充 tokens 个令牌所需的时间
+func (l *Limiter) duration(tokens float64) time.Duration {
+	if tokens <= 0 {
+		return 0
+	}
+	return time.Duration(tokens / l.rate * float64(time.Second))
+}
+
+// sweep 定期删除已恢复满额的令牌桶，避免大量一次性 key 占用内存
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if l.refill(b, now) >= float64(l.burst) {
+			delete(l.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+// rewind 将 key 的令牌桶时间回拨 d，模拟时间流逝
+func (l *Limiter) rewind(key string, d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.buckets[key].last = l.buckets[key].last.Add(-d)
+}
+
+func TestNewLimiterDisabled(t *testing.T) {
+	if NewLimiter(0, 10) != nil || NewLimiter(1, 0) != nil || NewLimiter(-1, 10) != nil {
+		t.Error("expected nil limiter for non-positive rate or burst")
+	}
+}
+
+func TestLimiterBurstAndRefill(t *testing.T) {
+	l := NewLimiter(1, 3)
+
+	for i := 2; i >= 0; i-- {
+		result := l.Allow("ip:1")
+		if !result.Allowed || result.Limit != 3 || result.Remaining != i {
+			t.Fatalf("request %d: result = %+v", 3-i, result)
+		}
+	}
+
+	result := l.Allow("ip:1")
+	if result.Allowed || result.Remaining != 0 {
+		t.Fatalf("over burst: result = %+v", result)
+	}
+	if result.RetryAfter <= 0 || result.RetryAfter > time.Second {
+		t.Errorf("retry after = %v, want within one second", result.RetryAfter)
+	}
+	if Seconds(result.RetryAfter) != 1 || Seconds(result.Reset) != 3 {
+		t.Errorf("retry after %v, reset %v", result.RetryAfter, result.Reset)
+	}
+
+	// 其他 key 使用独立的令牌桶
+	if !l.Allow("ip:2").Allowed {
+		t.Error("expected other key to be allowed")
+	}
+
+	// 两秒后补充两个令牌
+	l.rewind("ip:1", 2*time.Second)
+	for i := range 2 {
+		if !l.Allow("ip:1").Allowed {
+			t.Fatalf("refilled request %d rejected", i+1)
+		}
+	}
+	if l.Allow("ip:1").Allowed {
+		t.Error("expected refill to stop at elapsed time")
+	}
+
+	// 长时间闲置后最多积攒 burst 个令牌
+	l.rewind("ip:1", time.Hour)
+	if result := l.Allow("ip:1"); !result.Allowed || result.Remaining != 2 {
+		t.Errorf("after idle: result = %+v", result)
+	}
+}
+
+func TestLimiterSweep(t *testing.T) {
+	l := NewLimiter(1, 2)
+	l.Allow("ip:idle")
+	l.Allow("ip:busy")
+	l.Allow("ip:busy")
+
+	// 闲置的令牌桶已恢复满额，清理时删除；仍在消耗中的保留
+	l.rewind("ip:idle", 10*time.Second)
+	l.mu.Lock()
+	l.lastSweep = time.Now().Add(-2 * sweepInterval)
+	l.buckets["ip:busy"].last = time.Now()
+	l.mu.Unlock()
+
+	l.Allow("ip:other")
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.buckets["ip:idle"]; ok {
+		t.Error("idle bucket was not swept")
+	}
+	if _, ok := l.buckets["ip:busy"]; !ok {
+		t.Error("busy bucket was swept")
+	}
+}
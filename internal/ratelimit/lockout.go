@@ -0,0 +1,136 @@
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+)
+
+// LockedError 登录因连续失败被锁定时返回的错误
+type LockedError struct {
+	RetryAfter time.Duration // 距解除锁定的时间
+}
+
+// Error 返回带有剩余等待秒数的错误信息
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("%s，请 %d 秒后再试", commonModel.LOGIN_LOCKED, Seconds(e.RetryAfter))
+}
+
+// lockoutEntry 单个 key 的连续失败记录
+type lockoutEntry struct {
+	failures    int
+	lastFailure time.Time
+	lockedUntil time.Time
+}
+
+// Lockout 记录连续失败次数，达到 threshold 次后按指数增长的时间锁定，
+// 锁定时间从 baseDelay 开始每次翻倍，最长为 maxDelay；距最后一次失败或锁定结束超过 maxDelay 后清零
+type Lockout struct {
+	threshold int
+	baseDelay time.Duration
+	maxDelay  time.Duration
+
+	mu        sync.Mutex
+	entries   map[string]*lockoutEntry
+	lastSweep time.Time
+}
+
+// NewLockout 创建登录失败锁定器，threshold 或 baseDelay 不大于 0 时返回 nil 表示不锁定
+func NewLockout(threshold int, baseDelay, maxDelay time.Duration) *Lockout {
+	if threshold <= 0 || baseDelay <= 0 {
+		return nil
+	}
+	if maxDelay < baseDelay {
+		maxDelay = baseDelay
+	}
+	return &Lockout{
+		threshold: threshold,
+		baseDelay: baseDelay,
+		maxDelay:  maxDelay,
+		entries:   make(map[string]*lockoutEntry),
+		lastSweep: time.Now(),
+	}
+}
+
+// Check 检查 key 是否处于锁定中，锁定时返回 LockedError
+func (l *Lockout) Check(key string) error {
+	if l == nil {
+		return nil
+	}
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry, ok := l.entries[key]
+	if !ok || !now.Before(entry.lockedUntil) {
+		return nil
+	}
+	return &LockedError{RetryAfter: entry.lockedUntil.Sub(now)}
+}
+
+// Fail 记录一次失败，达到阈值后开始锁定
+func (l *Lockout) Fail(key string) {
+	if l == nil {
+		return
+	}
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	entry, ok := l.entries[key]
+	if !ok || l.expired(entry, now) {
+		entry = &lockoutEntry{}
+		l.entries[key] = entry
+	}
+	entry.failures++
+	entry.lastFailure = now
+
+	if over := entry.failures - l.threshold; over >= 0 {
+		delay := float64(l.baseDelay) * math.Pow(2, float64(over))
+		entry.lockedUntil = now.Add(time.Duration(math.Min(delay, float64(l.maxDelay))))
+	}
+}
+
+// Reset 成功后清除 key 的失败记录
+func (l *Lockout) Reset(key string) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.entries, key)
+}
+
+// expired 判断失败记录是否已超过保留时间
+func (l *Lockout) expired(entry *lockoutEntry, now time.Time) bool {
+	last := entry.lastFailure
+	if entry.lockedUntil.After(last) {
+		last = entry.lockedUntil
+	}
+	return now.Sub(last) > l.maxDelay
+}
+
+// sweep 定期删除已过期的失败记录
+func (l *Lockout) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+	for key, entry := range l.entries {
+		if l.expired(entry, now) {
+			delete(l.entries, key)
+		}
+	}
+}
+
+// Seconds 将等待时间向上取整为秒，用于 Retry-After 等响应头
+func Seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
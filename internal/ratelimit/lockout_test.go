@@ -0,0 +1,115 @@
+package ratelimit
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// elapse 将 key 的失败与锁定时间回拨 d，模拟时间流逝
+func (l *Lockout) elapse(key string, d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entry := l.entries[key]
+	entry.lastFailure = entry.lastFailure.Add(-d)
+	entry.lockedUntil = entry.lockedUntil.Add(-d)
+}
+
+func lockedFor(t *testing.T, l *Lockout, key string) time.Duration {
+	t.Helper()
+	err := l.Check(key)
+	if err == nil {
+		return 0
+	}
+	var locked *LockedError
+	if !errors.As(err, &locked) {
+		t.Fatalf("err = %v, want LockedError", err)
+	}
+	return locked.RetryAfter
+}
+
+func TestNilLockout(t *testing.T) {
+	var l *Lockout
+	if NewLockout(0, time.Second, time.Minute) != nil || NewLockout(3, 0, time.Minute) != nil {
+		t.Error("expected nil lockout for non-positive threshold or delay")
+	}
+	l.Fail("alice")
+	l.Reset("alice")
+	if err := l.Check("alice"); err != nil {
+		t.Errorf("nil lockout check = %v", err)
+	}
+}
+
+func TestLockoutBackoff(t *testing.T) {
+	l := NewLockout(3, time.Minute, 4*time.Minute)
+
+	l.Fail("alice")
+	l.Fail("alice")
+	if d := lockedFor(t, l, "alice"); d != 0 {
+		t.Fatalf("locked before threshold for %v", d)
+	}
+
+	// 达到阈值后锁定时间从 baseDelay 开始翻倍，不超过 maxDelay
+	for _, want := range []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, 4 * time.Minute} {
+		l.Fail("alice")
+		d := lockedFor(t, l, "alice")
+		if d <= want-time.Second || d > want {
+			t.Errorf("locked for %v, want %v", d, want)
+		}
+	}
+
+	if d := lockedFor(t, l, "bob"); d != 0 {
+		t.Errorf("other key locked for %v", d)
+	}
+	if err := l.Check("alice"); err == nil || Seconds(err.(*LockedError).RetryAfter) != 240 {
+		t.Errorf("err = %v", err)
+	}
+
+	// 锁定结束后仍保留失败记录，再次失败继续翻倍
+	l.elapse("alice", 4*time.Minute)
+	if d := lockedFor(t, l, "alice"); d != 0 {
+		t.Fatalf("still locked for %v after delay", d)
+	}
+	l.Fail("alice")
+	if d := lockedFor(t, l, "alice"); d <= 3*time.Minute {
+		t.Errorf("locked for %v, want max delay", d)
+	}
+
+	l.Reset("alice")
+	if d := lockedFor(t, l, "alice"); d != 0 {
+		t.Errorf("locked for %v after reset", d)
+	}
+}
+
+func TestLockoutExpires(t *testing.T) {
+	l := NewLockout(2, time.Minute, 4*time.Minute)
+
+	l.Fail("alice")
+	l.Fail("alice")
+	// 距锁定结束超过 maxDelay 后失败记录清零，重新从一次失败开始计数
+	l.elapse("alice", time.Minute+4*time.Minute+time.Second)
+	l.Fail("alice")
+	if d := lockedFor(t, l, "alice"); d != 0 {
+		t.Errorf("locked for %v after failures expired", d)
+	}
+}
+
+func TestLockoutSweep(t *testing.T) {
+	l := NewLockout(2, time.Minute, 2*time.Minute)
+	l.Fail("old")
+	l.elapse("old", time.Hour)
+
+	l.mu.Lock()
+	l.lastSweep = time.Now().Add(-2 * sweepInterval)
+	l.mu.Unlock()
+	l.Fail("new")
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.entries["old"]; ok {
+		t.Error("expired entry was not swept")
+	}
+	if _, ok := l.entries["new"]; !ok {
+		t.Error("new entry missing")
+	}
+}
@@ -39,9 +39,9 @@ func (commonRepository *CommonRepository) GetUserByUserId(userId uint) (userMode
 
 // GetSysAdmin 获取系统管理员信息
 func (commonRepository *CommonRepository) GetSysAdmin() (userModel.User, error) {
-	// 获取系统管理员（首个注册的用户）
+	// 获取系统管理员（站长，即首个注册的用户）
 	user := userModel.User{}
-	err := commonRepository.db().Where("is_admin = ?", true).First(&user).Error
+	err := commonRepository.db().Where("role = ?", userModel.RoleOwner).First(&user).Error
 	if err != nil {
 		return userModel.User{}, err
 	}
@@ -59,19 +59,15 @@ func (commonRepository *CommonRepository) GetAllUsers() ([]userModel.User, error
 	return users, nil
 }
 
-// GetAllEchos 获取所有Echo
-func (commonRepository *CommonRepository) GetAllEchos(showPrivate bool) ([]echoModel.Echo, error) {
+// GetAllEchos 获取所有指定可见性的已发布Echo，不包括草稿与定时发布的Echo
+func (commonRepository *CommonRepository) GetAllEchos(visibilities []string) ([]echoModel.Echo, error) {
 	var echos []echoModel.Echo
 
-	// 是否将私密内容也查询出来
-	if showPrivate {
-		if err := commonRepository.db().Preload("Images").Preload("Tags").Order("created_at DESC").Find(&echos).Error; err != nil {
-			return nil, err
-		}
-	} else {
-		if err := commonRepository.db().Preload("Images").Preload("Tags").Where("private = ?", false).Find(&echos).Error; err != nil {
-			return nil, err
-		}
+	if err := commonRepository.db().Preload("Images").Preload("Tags").
+		Where("visibility IN ? AND status = ?", visibilities, echoModel.EchoStatusPublished).
+		Order("created_at DESC").
+		Find(&echos).Error; err != nil {
+		return nil, err
 	}
 
 	return echos, nil
@@ -80,6 +76,7 @@ func (commonRepository *CommonRepository) GetAllEchos(showPrivate bool) ([]echoM
 // GetHeatMap 获取热力图数据
 func (commonRepository *CommonRepository) GetHeatMap(
 	startDate, endDate string,
+	visibilities []string,
 ) ([]commonModel.Heatmap, error) {
 	var results []commonModel.Heatmap
 
@@ -88,6 +85,9 @@ func (commonRepository *CommonRepository) GetHeatMap(
 	err := commonRepository.db().Table("echos").
 		Select("DATE(created_at) as date, COUNT(*) as count").
 		Where("DATE(created_at) >= ? AND DATE(created_at) <= ?", startDate, endDate).
+		Where("status = ?", echoModel.EchoStatusPublished).
+		Where("deleted_at IS NULL").
+		Where("visibility IN ?", visibilities).
 		Group("DATE(created_at)").
 		Order("date ASC").
 		Scan(&results).Error
@@ -95,6 +95,13 @@ func (commonRepository *CommonRepository) GetHeatMap(
 		return nil, err
 	}
 
+	// 不同数据库 DATE() 的返回类型不同（字符串或时间），统一为 YYYY-MM-DD
+	for i := range results {
+		if len(results[i].Date) > len("2006-01-02") {
+			results[i].Date = results[i].Date[:len("2006-01-02")]
+		}
+	}
+
 	return results, nil
 }
 
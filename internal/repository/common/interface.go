@@ -18,11 +18,11 @@ type CommonRepositoryInterface interface {
 	// GetAllUsers 获取所有用户信息
 	GetAllUsers() ([]userModel.User, error)
 
-	// GetAllEchos 获取所有Echo
-	GetAllEchos(showPrivate bool) ([]echoModel.Echo, error)
+	// GetAllEchos 获取所有指定可见性的已发布Echo
+	GetAllEchos(visibilities []string) ([]echoModel.Echo, error)
 
-	// GetHeatMap 获取热力图数据
-	GetHeatMap(startDate, endDate string) ([]model.Heatmap, error)
+	// GetHeatMap 获取指定可见性的Echo热力图数据
+	GetHeatMap(startDate, endDate string, visibilities []string) ([]model.Heatmap, error)
 
 	// SaveTempFile 保存临时文件记录
 	SaveTempFile(ctx context.Context, file model.TempFile) error
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"errors"
+	"slices"
 	"strings"
 	"time"
 
@@ -43,9 +44,7 @@ func (echoRepository *EchoRepository) CreateEcho(ctx context.Context, echo *mode
 	}
 
 	// 清除相关缓存
-	ClearEchoPageCache(echoRepository.cache)
-	echoRepository.cache.Delete(GetTodayEchosCacheKey(true))  // 删除今天的 Echo 缓存（管理员视图）
-	echoRepository.cache.Delete(GetTodayEchosCacheKey(false)) // 删除今天的 Echo 缓存（非管理员视图）
+	ClearEchoPageCache()
 
 	return nil
 }
@@ -54,10 +53,10 @@ func (echoRepository *EchoRepository) CreateEcho(ctx context.Context, echo *mode
 func (echoRepository *EchoRepository) GetEchosByPage(
 	page, pageSize int,
 	search string,
-	showPrivate bool,
+	visibilities []string,
 ) ([]model.Echo, int64) {
 	// 查找缓存
-	cacheKey := GetEchoPageCacheKey(page, pageSize, search, showPrivate)
+	cacheKey := GetEchoPageCacheKey(page, pageSize, search, visibilities)
 	if cachedResult, err := echoRepository.cache.Get(cacheKey); err == nil {
 		// 缓存命中，直接返回
 		// 类型断言
@@ -76,17 +75,16 @@ func (echoRepository *EchoRepository) GetEchosByPage(
 	var echos []model.Echo
 	var total int64
 
-	query := echoRepository.db().Model(&model.Echo{})
+	// 解析搜索语法，添加全文检索与过滤条件
+	searchQuery := model.ParseSearchQuery(search)
+	query, ranked := applySearchQuery(echoRepository.db().Model(&model.Echo{}).Scopes(publishedScope), searchQuery)
 
-	// 如果 search 不为空，添加模糊查询条件
-	if search != "" {
-		searchPattern := "%" + search + "%" // 模糊匹配模式
-		query = query.Where("content LIKE ?", searchPattern)
-	}
+	// 只保留当前用户可见的 Echo
+	query = query.Where("echos.visibility IN ?", visibilities)
 
-	// 如果不是管理员，过滤私密Echo
-	if !showPrivate {
-		query = query.Where("private = ?", false)
+	// 有检索词时按相关度排序
+	if ranked {
+		query = query.Order("echo_search.score")
 	}
 
 	// 获取总数并进行分页查询
@@ -95,11 +93,13 @@ func (echoRepository *EchoRepository) GetEchosByPage(
 		Preload("Tags").
 		Limit(pageSize).
 		Offset(offset).
-		Order("created_at DESC").
+		Order("echos.created_at DESC").
 		Find(&echos)
 
+	// 生成搜索结果的高亮摘要
+	fillSnippets(echos, searchQuery.Terms)
+
 	// 保存到缓存
-	echoKeyList = append(echoKeyList, cacheKey) // 记录缓存键
 	echoRepository.cache.Set(cacheKey, commonModel.PageQueryResult[[]model.Echo]{
 		Items: echos,
 		Total: total,
@@ -137,12 +137,9 @@ func (echoRepository *EchoRepository) GetEchosById(id uint) (*model.Echo, error)
 	return &echo, nil
 }
 
-// DeleteEchoById 删除 Echo
+// DeleteEchoById 将 Echo 移入回收站，图片、标签等关联数据在彻底删除时才清理
 func (echoRepository *EchoRepository) DeleteEchoById(ctx context.Context, id uint) error {
 	var echo model.Echo
-	// 删除外键images
-	echoRepository.getDB(ctx).Where("message_id = ?", id).Delete(&model.Image{})
-
 	result := echoRepository.getDB(ctx).Delete(&echo, id)
 	if result.Error != nil {
 		return result.Error
@@ -152,20 +149,19 @@ func (echoRepository *EchoRepository) DeleteEchoById(ctx context.Context, id uin
 	}
 
 	// 清除缓存
-	echoRepository.cache.Delete(GetEchoByIDCacheKey(id))      // 删除具体 Echo 的缓存
-	echoRepository.cache.Delete(GetTodayEchosCacheKey(true))  // 删除今天的 Echo 缓存（管理员视图）
-	echoRepository.cache.Delete(GetTodayEchosCacheKey(false)) // 删除今天的 Echo 缓存（非管理员视图）
+	echoRepository.cache.Delete(GetEchoByIDCacheKey(id)) // 删除具体 Echo 的缓存
 
 	// 清除相关缓存
-	ClearEchoPageCache(echoRepository.cache)
+	ClearEchoPageCache()
 
 	return nil
 }
 
 // GetTodayEchos 获取今天的 Echo 列表
-func (echoRepository *EchoRepository) GetTodayEchos(showPrivate bool) []model.Echo {
+func (echoRepository *EchoRepository) GetTodayEchos(visibilities []string) []model.Echo {
 	// 查找缓存
-	if cachedTodayEchos, err := echoRepository.cache.Get(GetTodayEchosCacheKey(showPrivate)); err == nil {
+	cacheKey := GetTodayEchosCacheKey(visibilities)
+	if cachedTodayEchos, err := echoRepository.cache.Get(cacheKey); err == nil {
 		// 缓存命中，直接返回
 		if todayEchos, ok := cachedTodayEchos.([]model.Echo); ok {
 			return todayEchos
@@ -180,11 +176,9 @@ func (echoRepository *EchoRepository) GetTodayEchos(showPrivate bool) []model.Ec
 	startOfDay := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, today.Location())
 	endOfDay := startOfDay.Add(24 * time.Hour)
 
-	query := echoRepository.db().Model(&model.Echo{})
-	// 如果不是管理员，过滤私密Echo
-	if !showPrivate {
-		query = query.Where("private = ?", false)
-	}
+	query := echoRepository.db().Model(&model.Echo{}).Scopes(publishedScope)
+	// 只保留当前用户可见的 Echo
+	query = query.Where("visibility IN ?", visibilities)
 
 	// 添加当天的时间过滤
 	query = query.Where("created_at >= ? AND created_at < ?", startOfDay, endOfDay)
@@ -200,7 +194,7 @@ func (echoRepository *EchoRepository) GetTodayEchos(showPrivate bool) []model.Ec
 	ttl := time.Until(
 		time.Date(today.Year(), today.Month(), today.Day()+1, 0, 0, 0, 0, today.Location()),
 	)
-	echoRepository.cache.SetWithTTL(GetTodayEchosCacheKey(showPrivate), echos, 1, ttl)
+	echoRepository.cache.SetWithTTL(cacheKey, echos, 1, ttl)
 
 	// 返回结果
 	return echos
@@ -209,10 +203,8 @@ func (echoRepository *EchoRepository) GetTodayEchos(showPrivate bool) []model.Ec
 // UpdateEcho 更新 Echo
 func (echoRepository *EchoRepository) UpdateEcho(ctx context.Context, echo *model.Echo) error {
 	// 清空缓存
-	ClearEchoPageCache(echoRepository.cache)
+	ClearEchoPageCache()
 	echoRepository.cache.Delete(GetEchoByIDCacheKey(echo.ID)) // 删除具体 Echo 的缓存
-	echoRepository.cache.Delete(GetTodayEchosCacheKey(true))  // 删除今天的 Echo 缓存（管理员视图）
-	echoRepository.cache.Delete(GetTodayEchosCacheKey(false)) // 删除今天的 Echo 缓存（非管理员视图）
 
 	// 1. 先删除该 Echo 关联的所有旧图片
 	if err := echoRepository.getDB(ctx).Where("message_id = ?", echo.ID).Delete(&model.Image{}).Error; err != nil {
@@ -224,10 +216,12 @@ func (echoRepository *EchoRepository) UpdateEcho(ctx context.Context, echo *mode
 		Where("id = ?", echo.ID).
 		Updates(map[string]interface{}{
 			"content":        echo.Content,
-			"private":        echo.Private,
+			"visibility":     echo.Visibility,
 			"layout":         echo.Layout,
 			"extension":      echo.Extension,
 			"extension_type": echo.ExtensionType,
+			"status":         echo.Status,
+			"publish_at":     echo.PublishAt,
 		}).Error; err != nil {
 		return err
 	}
@@ -278,14 +272,54 @@ func (echoRepository *EchoRepository) LikeEcho(ctx context.Context, id uint) err
 	}
 
 	// 清除相关缓存
-	ClearEchoPageCache(echoRepository.cache)
-	echoRepository.cache.Delete(GetEchoByIDCacheKey(id))      // 删除具体 Echo 的缓存
-	echoRepository.cache.Delete(GetTodayEchosCacheKey(true))  // 删除今天的 Echo 缓存（管理员视图）
-	echoRepository.cache.Delete(GetTodayEchosCacheKey(false)) // 删除今天的 Echo 缓存（非管理员视图）
+	ClearEchoPageCache()
+	echoRepository.cache.Delete(GetEchoByIDCacheKey(id)) // 删除具体 Echo 的缓存
 
 	return nil
 }
 
+// UnlikeEcho 取消点赞 Echo
+func (echoRepository *EchoRepository) UnlikeEcho(ctx context.Context, id uint) error {
+	return echoRepository.decrementCounter(ctx, id, "fav_count")
+}
+
+// AnnounceEcho 增加 Echo 的联邦转发数
+func (echoRepository *EchoRepository) AnnounceEcho(ctx context.Context, id uint) error {
+	if err := echoRepository.getDB(ctx).
+		Model(&model.Echo{}).
+		Where("id = ?", id).
+		UpdateColumn("announce_count", gorm.Expr("announce_count + ?", 1)).Error; err != nil {
+		return err
+	}
+
+	echoRepository.clearEchoCache(id)
+	return nil
+}
+
+// UnannounceEcho 减少 Echo 的联邦转发数
+func (echoRepository *EchoRepository) UnannounceEcho(ctx context.Context, id uint) error {
+	return echoRepository.decrementCounter(ctx, id, "announce_count")
+}
+
+// decrementCounter 原子递减计数字段，且不会减到 0 以下
+func (echoRepository *EchoRepository) decrementCounter(ctx context.Context, id uint, column string) error {
+	if err := echoRepository.getDB(ctx).
+		Model(&model.Echo{}).
+		Where("id = ? AND "+column+" > 0", id).
+		UpdateColumn(column, gorm.Expr(column+" - ?", 1)).Error; err != nil {
+		return err
+	}
+
+	echoRepository.clearEchoCache(id)
+	return nil
+}
+
+// clearEchoCache 清除与指定 Echo 相关的缓存
+func (echoRepository *EchoRepository) clearEchoCache(id uint) {
+	ClearEchoPageCache()
+	echoRepository.cache.Delete(GetEchoByIDCacheKey(id)) // 删除具体 Echo 的缓存
+}
+
 // GetAllTags 获取所有标签
 func (echoRepository *EchoRepository) GetAllTags() ([]model.Tag, error) {
 	var tags []model.Tag
@@ -296,16 +330,9 @@ func (echoRepository *EchoRepository) GetAllTags() ([]model.Tag, error) {
 	return tags, nil
 }
 
-// DeleteTagById 删除标签
+// DeleteTagById 将标签移入回收站，Echo 与标签的关联在彻底删除时才清理
 func (echoRepository *EchoRepository) DeleteTagById(ctx context.Context, id uint) error {
 	var tag model.Tag
-
-	// 删除关联的 EchoTag 关系
-	if err := echoRepository.getDB(ctx).Where("tag_id = ?", id).Delete(&model.EchoTag{}).Error; err != nil {
-		return err
-	}
-
-	// 删除标签
 	result := echoRepository.getDB(ctx).Delete(&tag, id)
 	if result.Error != nil {
 		return result.Error
@@ -314,6 +341,9 @@ func (echoRepository *EchoRepository) DeleteTagById(ctx context.Context, id uint
 		return gorm.ErrRecordNotFound // 如果没有找到记录
 	}
 
+	// 列表中的 Echo 不再显示该标签
+	ClearEchoPageCache()
+
 	return nil
 }
 
@@ -347,6 +377,27 @@ func (echoRepository *EchoRepository) CreateTag(ctx context.Context, tag *model.
 		return errors.New("标签名称不能为空")
 	}
 
+	// 同名标签在回收站中时直接恢复，避免违反唯一索引
+	var trashed []model.Tag
+	if err := echoRepository.getDB(ctx).Unscoped().
+		Where("name = ? AND deleted_at IS NOT NULL", tag.Name).
+		Limit(1).
+		Find(&trashed).Error; err != nil {
+		return err
+	}
+	if len(trashed) > 0 {
+		if err := echoRepository.getDB(ctx).Unscoped().Model(&trashed[0]).
+			Updates(map[string]interface{}{
+				"deleted_at":  nil,
+				"usage_count": tag.UsageCount,
+			}).Error; err != nil {
+			return err
+		}
+		*tag = trashed[0]
+		tag.DeletedAt = gorm.DeletedAt{}
+		return nil
+	}
+
 	result := echoRepository.getDB(ctx).Create(tag)
 	if result.Error != nil {
 		return result.Error
@@ -370,24 +421,23 @@ func (echoRepository *EchoRepository) GetEchosByTagId(
 	tagId uint,
 	page, pageSize int,
 	search string,
-	showPrivate bool,
+	visibilities []string,
 ) ([]model.Echo, int64, error) {
 	var (
 		echos []model.Echo
 		total int64
 	)
 
+	searchQuery := model.ParseSearchQuery(search)
+
 	applyFilters := func(db *gorm.DB) *gorm.DB {
 		db = db.Joins("JOIN echo_tags ON echo_tags.echo_id = echos.id").
-			Where("echo_tags.tag_id = ?", tagId)
+			Where("echo_tags.tag_id = ?", tagId).
+			Scopes(publishedScope).
+			Where("echos.visibility IN ?", visibilities)
 
-		if !showPrivate {
-			db = db.Where("echos.private = ?", false)
-		}
-
-		if search != "" {
-			db = db.Where("echos.content LIKE ?", "%"+search+"%")
-		}
+		// 标签页按时间排序，不使用相关度
+		db, _ = applySearchQuery(db, searchQuery)
 
 		return db
 	}
@@ -423,6 +473,108 @@ func (echoRepository *EchoRepository) GetEchosByTagId(
 		Find(&echos).Error; err != nil {
 		return nil, 0, err
 	}
+	fillSnippets(echos, searchQuery.Terms)
 
 	return echos, total, nil
 }
+
+// GetEchoTimeline 按游标获取 Echo 时间线，tagId 为 0 时不按标签过滤，结果按 (created_at, id) 倒序返回
+//
+// 游标为时间线中某条 Echo 的 ID，按该 Echo 的位置而不是 ID 大小比较：定时发布的 Echo 以发布时间排在最新，
+// 导入的 Echo 按原始时间排在过去。只指定 since_id 时从 since_id 之后按升序取数，保证客户端连续同步时不会遗漏或重复
+func (echoRepository *EchoRepository) GetEchoTimeline(
+	tagId uint,
+	cursor commonModel.CursorQueryDto,
+	visibilities []string,
+) ([]model.Echo, bool, error) {
+	// 查找缓存
+	cacheKey := GetEchoTimelineCacheKey(tagId, cursor, visibilities)
+	if cachedResult, err := echoRepository.cache.Get(cacheKey); err == nil {
+		if result, ok := cachedResult.(commonModel.CursorQueryResult[[]model.Echo]); ok {
+			return result.Items, result.HasMore, nil
+		}
+	}
+
+	// 时间线按 ID 排序，不使用相关度
+	searchQuery := model.ParseSearchQuery(cursor.Search)
+	query, _ := applySearchQuery(echoRepository.db().Model(&model.Echo{}).Scopes(publishedScope), searchQuery)
+
+	if tagId != 0 {
+		query = query.Where("echos.id IN (SELECT echo_id FROM echo_tags WHERE tag_id = ?)", tagId)
+	}
+	query = query.Where("echos.visibility IN ?", visibilities)
+	if cursor.BeforeID != 0 {
+		var err error
+		if query, err = echoRepository.applyTimelineCursor(query, cursor.BeforeID, "<"); err != nil {
+			return nil, false, err
+		}
+	}
+	if cursor.SinceID != 0 {
+		var err error
+		if query, err = echoRepository.applyTimelineCursor(query, cursor.SinceID, ">"); err != nil {
+			return nil, false, err
+		}
+	}
+
+	ascending := cursor.SinceID != 0 && cursor.BeforeID == 0
+	order := "echos.created_at DESC, echos.id DESC"
+	if ascending {
+		order = "echos.created_at ASC, echos.id ASC"
+	}
+
+	// 多取一条用于判断是否还有更多数据
+	var echos []model.Echo
+	if err := query.
+		Preload("Images").
+		Preload("Tags").
+		Order(order).
+		Limit(cursor.Limit + 1).
+		Find(&echos).Error; err != nil {
+		return nil, false, err
+	}
+
+	hasMore := len(echos) > cursor.Limit
+	if hasMore {
+		echos = echos[:cursor.Limit]
+	}
+	if ascending {
+		slices.Reverse(echos)
+	}
+	fillSnippets(echos, searchQuery.Terms)
+
+	// 保存到缓存
+	echoRepository.cache.Set(cacheKey, commonModel.CursorQueryResult[[]model.Echo]{
+		Items:   echos,
+		HasMore: hasMore,
+	}, 1)
+
+	return echos, hasMore, nil
+}
+
+// applyTimelineCursor 只保留位于游标 Echo 之前（op 为 "<"）或之后（op 为 ">"）的 Echo
+//
+// 已移入回收站的 Echo 仍可作为游标；游标 Echo 已被彻底删除时无法确定其位置，退化为按 ID 比较
+func (echoRepository *EchoRepository) applyTimelineCursor(query *gorm.DB, id uint, op string) (*gorm.DB, error) {
+	var positions []model.Echo
+	if err := echoRepository.db().Unscoped().
+		Select("id", "created_at").
+		Where("id = ?", id).
+		Limit(1).
+		Find(&positions).Error; err != nil {
+		return nil, err
+	}
+	if len(positions) == 0 {
+		return query.Where("echos.id "+op+" ?", id), nil
+	}
+
+	createdAt := positions[0].CreatedAt
+	return query.Where(
+		"echos.created_at "+op+" ? OR (echos.created_at = ? AND echos.id "+op+" ?)",
+		createdAt, createdAt, id,
+	), nil
+}
+
+// TimelineUpdatedAt 获取时间线最后一次变化的时间
+func (echoRepository *EchoRepository) TimelineUpdatedAt() time.Time {
+	return TimelineUpdatedAt()
+}
@@ -2,41 +2,89 @@ package repository
 
 import (
 	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
 
-	"github.com/lin-snow/ech0/internal/cache"
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
 )
 
-var echoKeyList []string
+var (
+	// timelineVersion 时间线版本号，Echo 发生变化时递增。列表类缓存的键包含版本号，
+	// 递增后旧的缓存不会再被命中，由缓存自行淘汰，无需记录每一个缓存键
+	timelineVersion atomic.Uint64
+
+	// timelineUpdatedAt 时间线最后一次变化的时间（Unix 纳秒），用于 Last-Modified
+	timelineUpdatedAt atomic.Int64
+)
+
+func init() {
+	timelineUpdatedAt.Store(time.Now().UnixNano())
+}
 
 const (
-	EchoPageCacheKeyPrefix = "echo_page" // echo_page:page:pageSize:search:showPrivate
+	EchoPageCacheKeyPrefix     = "echo_page"     // echo_page:version:page:pageSize:search:visibilities
+	EchoTimelineCacheKeyPrefix = "echo_timeline" // echo_timeline:version:tagId:beforeId:sinceId:limit:search:visibilities
+	EchoUnpinnedCacheKeyPrefix = "echo_unpinned" // echo_unpinned:version:offset:limit:visibilities
 )
 
-func GetEchoPageCacheKey(page, pageSize int, search string, showPrivate bool) string {
-	var showPrivateStr string
-	if showPrivate {
-		showPrivateStr = "true"
-	} else {
-		showPrivateStr = "false"
-	}
-	return EchoPageCacheKeyPrefix + ":" + strconv.Itoa(
+func GetEchoPageCacheKey(page, pageSize int, search string, visibilities []string) string {
+	return EchoPageCacheKeyPrefix + ":" + strconv.FormatUint(
+		timelineVersion.Load(), 10,
+	) + ":" + strconv.Itoa(
 		page,
 	) + ":" + strconv.Itoa(
 		pageSize,
-	) + ":" + search + ":" + showPrivateStr
+	) + ":" + search + ":" + strings.Join(visibilities, ",")
+}
+
+// GetUnpinnedEchosCacheKey 未置顶 Echo 列表的缓存键
+func GetUnpinnedEchosCacheKey(offset, limit int, visibilities []string) string {
+	return EchoUnpinnedCacheKeyPrefix + ":" +
+		strconv.FormatUint(timelineVersion.Load(), 10) + ":" +
+		strconv.Itoa(offset) + ":" +
+		strconv.Itoa(limit) + ":" +
+		strings.Join(visibilities, ",")
 }
 
-func ClearEchoPageCache(cache cache.ICache[string, any]) {
-	for _, key := range echoKeyList {
-		cache.Delete(key)
-	}
-	echoKeyList = []string{}
+func GetEchoTimelineCacheKey(
+	tagId uint,
+	cursor commonModel.CursorQueryDto,
+	visibilities []string,
+) string {
+	return EchoTimelineCacheKeyPrefix + ":" +
+		strconv.FormatUint(timelineVersion.Load(), 10) + ":" +
+		strconv.FormatUint(uint64(tagId), 10) + ":" +
+		strconv.FormatUint(uint64(cursor.BeforeID), 10) + ":" +
+		strconv.FormatUint(uint64(cursor.SinceID), 10) + ":" +
+		strconv.Itoa(cursor.Limit) + ":" +
+		cursor.Search + ":" +
+		strings.Join(visibilities, ",")
+}
+
+// ClearEchoPageCache 使所有 Echo 列表缓存失效
+func ClearEchoPageCache() {
+	timelineVersion.Add(1)
+	timelineUpdatedAt.Store(time.Now().UnixNano())
+}
+
+// TimelineUpdatedAt 返回时间线最后一次变化的时间
+func TimelineUpdatedAt() time.Time {
+	return time.Unix(0, timelineUpdatedAt.Load())
 }
 
 func GetEchoByIDCacheKey(id uint) string {
 	return "echo_id:" + strconv.Itoa(int(id))
 }
 
-func GetTodayEchosCacheKey(showPrivate bool) string {
-	return "echo_today:" + strconv.FormatBool(showPrivate)
+// GetPinnedEchosCacheKey 置顶 Echo 的缓存键，包含时间线版本号，Echo 变化后自动失效
+func GetPinnedEchosCacheKey(visibilities []string) string {
+	return "echo_pinned:" + strconv.FormatUint(timelineVersion.Load(), 10) + ":" +
+		strings.Join(visibilities, ",")
+}
+
+// GetTodayEchosCacheKey 今天的 Echo 缓存键，包含时间线版本号，Echo 变化后自动失效
+func GetTodayEchosCacheKey(visibilities []string) string {
+	return "echo_today:" + strconv.FormatUint(timelineVersion.Load(), 10) + ":" +
+		strings.Join(visibilities, ",")
 }
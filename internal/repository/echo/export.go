@@ -0,0 +1,19 @@
+package repository
+
+import model "github.com/lin-snow/ech0/internal/model/echo"
+
+// GetEchosForExport 按创建时间倒序分批获取全部 Echo（包含草稿与定时发布，不包含回收站），用于导出
+func (echoRepository *EchoRepository) GetEchosForExport(offset, limit int) ([]model.Echo, error) {
+	var echos []model.Echo
+	if err := echoRepository.db().
+		Preload("Images").
+		Preload("Tags").
+		Order("echos.created_at DESC").
+		Order("echos.id DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&echos).Error; err != nil {
+		return nil, err
+	}
+	return echos, nil
+}
@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	model "github.com/lin-snow/ech0/internal/model/echo"
+)
+
+// FindEchoIdByCreatedAt 根据创建时间与内容查找用户的 Echo（包含回收站），不存在时返回 0，用于导入时去重
+func (echoRepository *EchoRepository) FindEchoIdByCreatedAt(
+	ctx context.Context,
+	userId uint,
+	createdAt time.Time,
+	content string,
+) (uint, error) {
+	var ids []uint
+	if err := echoRepository.getDB(ctx).Unscoped().Model(&model.Echo{}).
+		Where("user_id = ? AND created_at = ? AND content = ?", userId, createdAt, strings.TrimSpace(content)).
+		Limit(1).
+		Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	return ids[0], nil
+}
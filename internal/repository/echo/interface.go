@@ -2,7 +2,9 @@ package repository
 
 import (
 	"context"
+	"time"
 
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
 	model "github.com/lin-snow/ech0/internal/model/echo"
 )
 
@@ -10,17 +12,17 @@ type EchoRepositoryInterface interface {
 	// CreateEcho 创建一个新的 Echo
 	CreateEcho(ctx context.Context, echo *model.Echo) error
 
-	// GetEchosByPage 获取分页的 Echo 列表
-	GetEchosByPage(page, pageSize int, search string, showPrivate bool) ([]model.Echo, int64)
+	// GetEchosByPage 获取分页的 Echo 列表，只返回指定可见性的 Echo
+	GetEchosByPage(page, pageSize int, search string, visibilities []string) ([]model.Echo, int64)
 
 	// GetEchosById 根据 ID 获取 Echo
 	GetEchosById(id uint) (*model.Echo, error)
 
-	// DeleteEchoById 删除 Echo
+	// DeleteEchoById 将 Echo 移入回收站
 	DeleteEchoById(ctx context.Context, id uint) error
 
-	// GetTodayEchos 获取今天的 Echo 列表
-	GetTodayEchos(showPrivate bool) []model.Echo
+	// GetTodayEchos 获取今天的 Echo 列表，只返回指定可见性的 Echo
+	GetTodayEchos(visibilities []string) []model.Echo
 
 	// UpdateEcho 更新 Echo
 	UpdateEcho(ctx context.Context, echo *model.Echo) error
@@ -28,10 +30,19 @@ type EchoRepositoryInterface interface {
 	// LikeEcho 点赞 Echo
 	LikeEcho(ctx context.Context, id uint) error
 
+	// UnlikeEcho 取消点赞 Echo
+	UnlikeEcho(ctx context.Context, id uint) error
+
+	// AnnounceEcho 增加 Echo 的联邦转发数
+	AnnounceEcho(ctx context.Context, id uint) error
+
+	// UnannounceEcho 减少 Echo 的联邦转发数
+	UnannounceEcho(ctx context.Context, id uint) error
+
 	// GetAllTags 获取所有标签
 	GetAllTags() ([]model.Tag, error)
 
-	// DeleteTagById 删除标签
+	// DeleteTagById 将标签移入回收站
 	DeleteTagById(ctx context.Context, id uint) error
 
 	// GetTagByName 根据名称获取标签
@@ -46,10 +57,107 @@ type EchoRepositoryInterface interface {
 	// IncrementTagUsageCount 增加标签的使用计数
 	IncrementTagUsageCount(ctx context.Context, tagID uint) error
 
+	// IndexEcho 更新 Echo 的全文索引
+	IndexEcho(ctx context.Context, id uint) error
+
+	// RemoveEchoIndex 删除 Echo 的全文索引
+	RemoveEchoIndex(ctx context.Context, id uint) error
+
+	// RebuildSearchIndex 重建全部 Echo 的全文索引
+	RebuildSearchIndex(ctx context.Context) (int, error)
+
+	// GetEchoTimeline 按游标获取 Echo 时间线，tagId 为 0 时不按标签过滤
+	GetEchoTimeline(
+		tagId uint,
+		cursor commonModel.CursorQueryDto,
+		visibilities []string,
+	) ([]model.Echo, bool, error)
+
+	// GetEchosByIds 根据 ID 列表获取已发布的 Echo
+	GetEchosByIds(ids []uint) ([]model.Echo, error)
+
+	// GetRepliesByEchoIds 获取回复指定 Echo 的所有已发布的本地 Echo
+	GetRepliesByEchoIds(ids []uint) ([]model.Echo, error)
+
+	// SaveRemoteReply 保存远端回复，Object 已存在且回复者不同时返回错误
+	SaveRemoteReply(ctx context.Context, reply *model.RemoteReply) error
+
+	// GetRemoteRepliesByEchoIds 获取指定 Echo 收到的远端回复
+	GetRemoteRepliesByEchoIds(ids []uint) ([]model.RemoteReply, error)
+
+	// DeleteRemoteReplyByObjectID 删除指定远端用户的某条回复
+	DeleteRemoteReplyByObjectID(ctx context.Context, actorID, objectID string) error
+
+	// DeleteRemoteRepliesByActor 删除指定远端用户的所有回复
+	DeleteRemoteRepliesByActor(ctx context.Context, actorID string) error
+
+	// CreateEchoRevision 写入 Echo 的历史版本
+	CreateEchoRevision(ctx context.Context, revision *model.EchoRevision) error
+
+	// GetLatestEchoRevision 获取 Echo 的最新版本
+	GetLatestEchoRevision(ctx context.Context, echoID uint) (*model.EchoRevision, error)
+
+	// GetEchoRevisions 获取 Echo 的所有历史版本
+	GetEchoRevisions(echoID uint) ([]model.EchoRevision, error)
+
+	// GetEchoRevision 获取 Echo 的指定版本
+	GetEchoRevision(echoID uint, revision int) (*model.EchoRevision, error)
+
+	// GetUnpublishedEchos 获取所有草稿与定时发布的 Echo
+	GetUnpublishedEchos() ([]model.Echo, error)
+
+	// GetDueScheduledEchos 获取已到发布时间的定时 Echo
+	GetDueScheduledEchos(now time.Time, limit int) ([]model.Echo, error)
+
+	// PublishEcho 将草稿或定时 Echo 标记为已发布
+	PublishEcho(ctx context.Context, id uint, publishedAt time.Time) (bool, error)
+
+	// GetUnpinnedEchos 按偏移量获取未置顶的 Echo 列表，只返回指定可见性的 Echo，同时返回未置顶 Echo 的总数
+	GetUnpinnedEchos(offset, limit int, visibilities []string) ([]model.Echo, int64)
+
+	// GetPinnedEchos 获取置顶的 Echo
+	GetPinnedEchos(visibilities []string) ([]model.Echo, error)
+
+	// CountPinnedEchos 统计已发布的置顶 Echo 数量
+	CountPinnedEchos(ctx context.Context) (int64, error)
+
+	// SetEchoPinnedAt 设置 Echo 的置顶时间，pinnedAt 为空时取消置顶
+	SetEchoPinnedAt(ctx context.Context, id uint, pinnedAt *time.Time) error
+
+	// FindEchoIdByCreatedAt 根据创建时间与内容查找用户的 Echo，不存在时返回 0
+	FindEchoIdByCreatedAt(ctx context.Context, userId uint, createdAt time.Time, content string) (uint, error)
+
+	// GetEchosForExport 按创建时间倒序分批获取全部 Echo，用于导出
+	GetEchosForExport(offset, limit int) ([]model.Echo, error)
+
+	// GetTrashedEchos 获取回收站中的 Echo
+	GetTrashedEchos() ([]model.Echo, error)
+
+	// GetTrashedEchoById 获取回收站中的 Echo
+	GetTrashedEchoById(ctx context.Context, id uint) (*model.Echo, error)
+
+	// RestoreEchoById 将 Echo 从回收站中恢复
+	RestoreEchoById(ctx context.Context, id uint) error
+
+	// PurgeEchoById 彻底删除回收站中的 Echo
+	PurgeEchoById(ctx context.Context, id uint) error
+
+	// GetTrashedTags 获取回收站中的标签
+	GetTrashedTags() ([]model.Tag, error)
+
+	// RestoreTagById 将标签从回收站中恢复
+	RestoreTagById(ctx context.Context, id uint) error
+
+	// PurgeTagById 彻底删除回收站中的标签
+	PurgeTagById(ctx context.Context, id uint) error
+
+	// TimelineUpdatedAt 获取时间线最后一次变化的时间
+	TimelineUpdatedAt() time.Time
+
 	GetEchosByTagId(
 		tagId uint,
 		page, pageSize int,
 		search string,
-		showPrivate bool,
+		visibilities []string,
 	) ([]model.Echo, int64, error)
 }
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	model "github.com/lin-snow/ech0/internal/model/echo"
+)
+
+// GetPinnedEchos 获取已发布的置顶 Echo，按置顶时间倒序
+func (echoRepository *EchoRepository) GetPinnedEchos(visibilities []string) ([]model.Echo, error) {
+	// 查找缓存
+	cacheKey := GetPinnedEchosCacheKey(visibilities)
+	if cachedEchos, err := echoRepository.cache.Get(cacheKey); err == nil {
+		if echos, ok := cachedEchos.([]model.Echo); ok {
+			return echos, nil
+		}
+	}
+
+	var echos []model.Echo
+	if err := echoRepository.db().
+		Scopes(publishedScope).
+		Where("echos.pinned_at IS NOT NULL").
+		Where("echos.visibility IN ?", visibilities).
+		Preload("Images").
+		Preload("Tags").
+		Order("echos.pinned_at DESC").
+		Limit(model.MaxPinnedEchoCount).
+		Find(&echos).Error; err != nil {
+		return nil, err
+	}
+
+	// 保存到缓存
+	echoRepository.cache.Set(cacheKey, echos, 1)
+
+	return echos, nil
+}
+
+// GetUnpinnedEchos 按偏移量获取未置顶的已发布 Echo，按创建时间倒序，同时返回未置顶 Echo 的总数，limit 为 0 时只统计总数
+func (echoRepository *EchoRepository) GetUnpinnedEchos(
+	offset, limit int,
+	visibilities []string,
+) ([]model.Echo, int64) {
+	// 查找缓存
+	cacheKey := GetUnpinnedEchosCacheKey(offset, limit, visibilities)
+	if cachedResult, err := echoRepository.cache.Get(cacheKey); err == nil {
+		if result, ok := cachedResult.(commonModel.PageQueryResult[[]model.Echo]); ok {
+			return result.Items, result.Total
+		}
+	}
+
+	var (
+		echos []model.Echo
+		total int64
+	)
+	query := echoRepository.db().Model(&model.Echo{}).
+		Scopes(publishedScope).
+		Where("echos.pinned_at IS NULL").
+		Where("echos.visibility IN ?", visibilities)
+
+	query.Count(&total)
+	if limit > 0 {
+		query.Preload("Images").
+			Preload("Tags").
+			Limit(limit).
+			Offset(offset).
+			Order("echos.created_at DESC").
+			Find(&echos)
+	}
+
+	// 保存到缓存
+	echoRepository.cache.Set(cacheKey, commonModel.PageQueryResult[[]model.Echo]{
+		Items: echos,
+		Total: total,
+	}, 1)
+
+	return echos, total
+}
+
+// CountPinnedEchos 统计已发布的置顶 Echo 数量
+func (echoRepository *EchoRepository) CountPinnedEchos(ctx context.Context) (int64, error) {
+	var count int64
+	if err := echoRepository.getDB(ctx).Model(&model.Echo{}).
+		Scopes(publishedScope).
+		Where("echos.pinned_at IS NOT NULL").
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// SetEchoPinnedAt 设置 Echo 的置顶时间，pinnedAt 为空时取消置顶
+func (echoRepository *EchoRepository) SetEchoPinnedAt(ctx context.Context, id uint, pinnedAt *time.Time) error {
+	if err := echoRepository.getDB(ctx).Model(&model.Echo{}).
+		Where("id = ?", id).
+		Update("pinned_at", pinnedAt).Error; err != nil {
+		return err
+	}
+
+	// 清除缓存
+	ClearEchoPageCache()
+	echoRepository.cache.Delete(GetEchoByIDCacheKey(id))
+
+	return nil
+}
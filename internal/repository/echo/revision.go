@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+
+	model "github.com/lin-snow/ech0/internal/model/echo"
+)
+
+// CreateEchoRevision 写入 Echo 的历史版本
+func (echoRepository *EchoRepository) CreateEchoRevision(ctx context.Context, revision *model.EchoRevision) error {
+	return echoRepository.getDB(ctx).Create(revision).Error
+}
+
+// GetLatestEchoRevision 获取 Echo 的最新版本，没有版本时返回 nil
+func (echoRepository *EchoRepository) GetLatestEchoRevision(ctx context.Context, echoID uint) (*model.EchoRevision, error) {
+	var revisions []model.EchoRevision
+	if err := echoRepository.getDB(ctx).
+		Where("echo_id = ?", echoID).
+		Order("revision DESC").
+		Limit(1).
+		Find(&revisions).Error; err != nil {
+		return nil, err
+	}
+	if len(revisions) == 0 {
+		return nil, nil
+	}
+	return &revisions[0], nil
+}
+
+// GetEchoRevisions 获取 Echo 的所有历史版本，按版本号倒序
+func (echoRepository *EchoRepository) GetEchoRevisions(echoID uint) ([]model.EchoRevision, error) {
+	var revisions []model.EchoRevision
+	if err := echoRepository.db().
+		Where("echo_id = ?", echoID).
+		Order("revision DESC").
+		Find(&revisions).Error; err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+// GetEchoRevision 获取 Echo 的指定版本，不存在时返回 nil
+func (echoRepository *EchoRepository) GetEchoRevision(echoID uint, revision int) (*model.EchoRevision, error) {
+	var revisions []model.EchoRevision
+	if err := echoRepository.db().
+		Where("echo_id = ? AND revision = ?", echoID, revision).
+		Limit(1).
+		Find(&revisions).Error; err != nil {
+		return nil, err
+	}
+	if len(revisions) == 0 {
+		return nil, nil
+	}
+	return &revisions[0], nil
+}
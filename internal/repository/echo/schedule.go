@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	model "github.com/lin-snow/ech0/internal/model/echo"
+	"gorm.io/gorm"
+)
+
+// publishedScope 只查询已发布的 Echo，草稿与定时发布的 Echo 不出现在列表中
+func publishedScope(db *gorm.DB) *gorm.DB {
+	return db.Where("echos.status = ?", model.EchoStatusPublished)
+}
+
+// GetUnpublishedEchos 获取所有草稿与定时发布的 Echo，按创建时间倒序
+func (echoRepository *EchoRepository) GetUnpublishedEchos() ([]model.Echo, error) {
+	var echos []model.Echo
+	if err := echoRepository.db().
+		Where("status IN ?", []string{model.EchoStatusDraft, model.EchoStatusScheduled}).
+		Preload("Images").
+		Preload("Tags").
+		Order("created_at DESC").
+		Find(&echos).Error; err != nil {
+		return nil, err
+	}
+	return echos, nil
+}
+
+// GetDueScheduledEchos 获取已到发布时间的定时 Echo，按发布时间正序
+func (echoRepository *EchoRepository) GetDueScheduledEchos(now time.Time, limit int) ([]model.Echo, error) {
+	var echos []model.Echo
+	if err := echoRepository.db().
+		Where("status = ? AND publish_at <= ?", model.EchoStatusScheduled, now).
+		Order("publish_at ASC").
+		Limit(limit).
+		Find(&echos).Error; err != nil {
+		return nil, err
+	}
+	return echos, nil
+}
+
+// PublishEcho 将草稿或定时 Echo 标记为已发布，发布时间作为创建时间，返回是否由本次调用发布
+func (echoRepository *EchoRepository) PublishEcho(ctx context.Context, id uint, publishedAt time.Time) (bool, error) {
+	result := echoRepository.getDB(ctx).Model(&model.Echo{}).
+		Where("id = ? AND status <> ?", id, model.EchoStatusPublished).
+		Updates(map[string]interface{}{
+			"status":     model.EchoStatusPublished,
+			"created_at": publishedAt,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	// 清除缓存
+	ClearEchoPageCache()
+	echoRepository.cache.Delete(GetEchoByIDCacheKey(id))
+
+	return result.RowsAffected > 0, nil
+}
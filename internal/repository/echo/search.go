@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/lin-snow/ech0/internal/database"
+	model "github.com/lin-snow/ech0/internal/model/echo"
+	searchUtil "github.com/lin-snow/ech0/internal/util/search"
+	"gorm.io/gorm"
+)
+
+// applySearchQuery 将搜索条件应用到 Echo 查询上，返回的 ranked 表示结果可以按相关度排序
+//
+// 全文索引可用时检索词通过 FTS5 匹配并连接 echo_search.score（bm25，越小越相关），否则回退为 LIKE 模糊匹配
+func applySearchQuery(db *gorm.DB, q model.SearchQuery) (query *gorm.DB, ranked bool) {
+	query = db
+
+	if len(q.Terms) > 0 {
+		match := searchUtil.BuildMatchQuery(q.Terms)
+		if database.SearchIndexEnabled() && match != "" {
+			// content 列的权重高于 tags 列
+			query = query.Joins(
+				"JOIN (SELECT rowid AS echo_id, bm25("+database.SearchIndexTable+", 1.0, 0.5) AS score FROM "+
+					database.SearchIndexTable+" WHERE "+database.SearchIndexTable+" MATCH ?) AS echo_search "+
+					"ON echo_search.echo_id = echos.id",
+				match,
+			)
+			ranked = true
+		} else {
+			for _, term := range q.Terms {
+				query = query.Where("echos.content LIKE ?", "%"+term+"%")
+			}
+		}
+	}
+
+	for _, tag := range q.Tags {
+		query = query.Where(
+			"echos.id IN (SELECT echo_tags.echo_id FROM echo_tags JOIN tags ON tags.id = echo_tags.tag_id WHERE tags.name = ? AND tags.deleted_at IS NULL)",
+			tag,
+		)
+	}
+	if q.Before != nil {
+		query = query.Where("echos.created_at < ?", *q.Before)
+	}
+	if q.After != nil {
+		query = query.Where("echos.created_at >= ?", *q.After)
+	}
+	if q.HasImage {
+		query = query.Where("EXISTS (SELECT 1 FROM images WHERE images.message_id = echos.id)")
+	}
+	if q.Visibility != "" {
+		query = query.Where("echos.visibility = ?", q.Visibility)
+	}
+	if q.ExtensionType != "" {
+		query = query.Where("echos.extension_type = ?", q.ExtensionType)
+	}
+	if q.Author != "" {
+		query = query.Where("echos.username = ?", q.Author)
+	}
+
+	return query, ranked
+}
+
+// fillSnippets 为搜索结果生成高亮摘要
+func fillSnippets(echos []model.Echo, terms []string) {
+	if len(terms) == 0 {
+		return
+	}
+	for i := range echos {
+		echos[i].Snippet = searchUtil.Highlight(echos[i].Content, terms)
+	}
+}
+
+// IndexEcho 根据数据库中的最新内容更新 Echo 的全文索引，Echo 不存在时删除索引
+func (echoRepository *EchoRepository) IndexEcho(ctx context.Context, id uint) error {
+	db := echoRepository.getDB(ctx)
+
+	var echos []model.Echo
+	if err := db.Preload("Tags").Where("id = ?", id).Limit(1).Find(&echos).Error; err != nil {
+		return err
+	}
+	if len(echos) == 0 {
+		return echoRepository.RemoveEchoIndex(ctx, id)
+	}
+
+	if err := database.IndexEcho(db, &echos[0]); err != nil {
+		return err
+	}
+
+	// 索引在事件中异步更新，期间缓存的搜索结果需要清除
+	ClearEchoPageCache()
+	return nil
+}
+
+// RemoveEchoIndex 删除 Echo 的全文索引
+func (echoRepository *EchoRepository) RemoveEchoIndex(ctx context.Context, id uint) error {
+	if err := database.RemoveEchoIndex(echoRepository.getDB(ctx), id); err != nil {
+		return err
+	}
+
+	ClearEchoPageCache()
+	return nil
+}
+
+// RebuildSearchIndex 重建全部 Echo 的全文索引
+func (echoRepository *EchoRepository) RebuildSearchIndex(ctx context.Context) (int, error) {
+	count, err := database.RebuildSearchIndex(echoRepository.getDB(ctx))
+	if err != nil {
+		return 0, err
+	}
+
+	// 搜索结果缓存可能已过期
+	ClearEchoPageCache()
+	return count, nil
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	model "github.com/lin-snow/ech0/internal/model/echo"
+	"gorm.io/gorm"
+)
+
+// GetEchosByIds 根据 ID 列表获取已发布的 Echo
+func (echoRepository *EchoRepository) GetEchosByIds(ids []uint) ([]model.Echo, error) {
+	var echos []model.Echo
+	if len(ids) == 0 {
+		return echos, nil
+	}
+
+	if err := echoRepository.db().
+		Where("id IN ?", ids).
+		Scopes(publishedScope).
+		Preload("Images").
+		Preload("Tags").
+		Find(&echos).Error; err != nil {
+		return nil, err
+	}
+	return echos, nil
+}
+
+// GetRepliesByEchoIds 获取回复指定 Echo 的所有已发布的本地 Echo，按发布时间正序
+func (echoRepository *EchoRepository) GetRepliesByEchoIds(ids []uint) ([]model.Echo, error) {
+	var echos []model.Echo
+	if len(ids) == 0 {
+		return echos, nil
+	}
+
+	if err := echoRepository.db().
+		Where("reply_to_id IN ?", ids).
+		Scopes(publishedScope).
+		Preload("Images").
+		Preload("Tags").
+		Order("created_at ASC").
+		Order("id ASC").
+		Find(&echos).Error; err != nil {
+		return nil, err
+	}
+	return echos, nil
+}
+
+// SaveRemoteReply 保存远端回复，同一 Object 重复投递时仅在回复者相同时更新内容
+func (echoRepository *EchoRepository) SaveRemoteReply(ctx context.Context, reply *model.RemoteReply) error {
+	db := echoRepository.getDB(ctx)
+
+	var existing model.RemoteReply
+	err := db.Where("object_id = ?", reply.ObjectID).First(&existing).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return db.Create(reply).Error
+	}
+	if err != nil {
+		return err
+	}
+
+	// 同一 Object 只能由原回复者更新
+	if existing.ActorID != reply.ActorID {
+		return errors.New("remote reply belongs to another actor")
+	}
+	reply.ID = existing.ID
+	return db.Model(&existing).Updates(map[string]any{
+		"content":            reply.Content,
+		"url":                reply.URL,
+		"actor_display_name": reply.ActorDisplayName,
+		"actor_avatar":       reply.ActorAvatar,
+	}).Error
+}
+
+// GetRemoteRepliesByEchoIds 获取指定 Echo 收到的远端回复，按发布时间正序
+func (echoRepository *EchoRepository) GetRemoteRepliesByEchoIds(ids []uint) ([]model.RemoteReply, error) {
+	var replies []model.RemoteReply
+	if len(ids) == 0 {
+		return replies, nil
+	}
+
+	if err := echoRepository.db().
+		Where("echo_id IN ?", ids).
+		Order("published_at ASC").
+		Find(&replies).Error; err != nil {
+		return nil, err
+	}
+	return replies, nil
+}
+
+// DeleteRemoteReplyByObjectID 删除远端回复，仅删除属于该远端用户的回复
+func (echoRepository *EchoRepository) DeleteRemoteReplyByObjectID(ctx context.Context, actorID, objectID string) error {
+	return echoRepository.getDB(ctx).
+		Where("actor_id = ? AND object_id = ?", actorID, objectID).
+		Delete(&model.RemoteReply{}).Error
+}
+
+// DeleteRemoteRepliesByActor 删除指定远端用户的所有回复
+func (echoRepository *EchoRepository) DeleteRemoteRepliesByActor(ctx context.Context, actorID string) error {
+	return echoRepository.getDB(ctx).Where("actor_id = ?", actorID).Delete(&model.RemoteReply{}).Error
+}
@@ -0,0 +1,360 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/lin-snow/ech0/internal/database"
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	model "github.com/lin-snow/ech0/internal/model/echo"
+)
+
+// memoryCache 同步写入的内存缓存，避免 Ristretto 异步写入导致测试不稳定
+type memoryCache struct {
+	mu    sync.Mutex
+	items map[string]any
+}
+
+func (c *memoryCache) Set(key string, value any, _ int64) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.items[key] = value
+	return true
+}
+
+func (c *memoryCache) SetWithTTL(key string, value any, cost int64, _ time.Duration) bool {
+	return c.Set(key, value, cost)
+}
+
+func (c *memoryCache) Get(key string) (any, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	value, ok := c.items[key]
+	if !ok {
+		return nil, errors.New("key not found")
+	}
+	return value, nil
+}
+
+func (c *memoryCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.items, key)
+}
+
+func (c *memoryCache) GetOrSet(key string, cost int64, fn func() (any, error)) (any, error) {
+	if value, err := c.Get(key); err == nil {
+		return value, nil
+	}
+	value, err := fn()
+	if err != nil {
+		return nil, err
+	}
+	c.Set(key, value, cost)
+	return value, nil
+}
+
+// newTestRepository 基于临时 SQLite 数据库的 Echo 仓储
+func newTestRepository(t *testing.T) (*gorm.DB, *EchoRepository) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "ech0.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.AutoMigrate(database.Models()...); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db, &EchoRepository{
+		db:    func() *gorm.DB { return db },
+		cache: &memoryCache{items: make(map[string]any)},
+	}
+}
+
+// createEchos 按顺序创建内容为 contents 的公开 Echo
+func createEchos(t *testing.T, repo *EchoRepository, contents ...string) []model.Echo {
+	t.Helper()
+	echos := make([]model.Echo, 0, len(contents))
+	for _, content := range contents {
+		echo := model.Echo{Content: content, UserID: 1, Visibility: model.VisibilityPublic}
+		if err := repo.CreateEcho(context.Background(), &echo); err != nil {
+			t.Fatalf("create echo: %v", err)
+		}
+		echos = append(echos, echo)
+	}
+	return echos
+}
+
+// timelineContents 获取时间线并返回 Echo 内容
+func timelineContents(
+	t *testing.T,
+	repo *EchoRepository,
+	tagId uint,
+	cursor commonModel.CursorQueryDto,
+	visibilities ...string,
+) ([]string, bool) {
+	t.Helper()
+	if len(visibilities) == 0 {
+		visibilities = []string{model.VisibilityPublic}
+	}
+	echos, hasMore, err := repo.GetEchoTimeline(tagId, cursor, visibilities)
+	if err != nil {
+		t.Fatalf("timeline: %v", err)
+	}
+	contents := make([]string, 0, len(echos))
+	for _, echo := range echos {
+		contents = append(contents, echo.Content)
+	}
+	return contents, hasMore
+}
+
+func TestGetEchoTimelineCursor(t *testing.T) {
+	_, repo := newTestRepository(t)
+	echos := createEchos(t, repo, "e1", "e2", "e3", "e4", "e5")
+	id := func(i int) uint { return echos[i-1].ID }
+
+	tests := map[string]struct {
+		cursor      commonModel.CursorQueryDto
+		want        []string
+		wantHasMore bool
+	}{
+		"最新的数据":               {cursor: commonModel.CursorQueryDto{Limit: 2}, want: []string{"e5", "e4"}, wantHasMore: true},
+		"before_id 之前的数据":     {cursor: commonModel.CursorQueryDto{BeforeID: id(4), Limit: 2}, want: []string{"e3", "e2"}, wantHasMore: true},
+		"最后一页":                {cursor: commonModel.CursorQueryDto{BeforeID: id(2), Limit: 2}, want: []string{"e1"}},
+		"恰好取完":                {cursor: commonModel.CursorQueryDto{BeforeID: id(3), Limit: 2}, want: []string{"e2", "e1"}},
+		"since_id 之后按顺序同步":    {cursor: commonModel.CursorQueryDto{SinceID: id(1), Limit: 2}, want: []string{"e3", "e2"}, wantHasMore: true},
+		"since_id 之后没有更多":     {cursor: commonModel.CursorQueryDto{SinceID: id(3), Limit: 2}, want: []string{"e5", "e4"}},
+		"since_id 为最新":        {cursor: commonModel.CursorQueryDto{SinceID: id(5), Limit: 2}, want: []string{}},
+		"同时指定 before 与 since": {cursor: commonModel.CursorQueryDto{BeforeID: id(5), SinceID: id(1), Limit: 2}, want: []string{"e4", "e3"}, wantHasMore: true},
+		"带检索词":                {cursor: commonModel.CursorQueryDto{Search: "e2", Limit: 10}, want: []string{"e2"}},
+	}
+
+	for name, tt := range tests {
+		got, hasMore := timelineContents(t, repo, 0, tt.cursor)
+		if !slices.Equal(got, tt.want) || hasMore != tt.wantHasMore {
+			t.Errorf("%s: got %v hasMore %v, want %v hasMore %v", name, got, hasMore, tt.want, tt.wantHasMore)
+		}
+	}
+}
+
+func TestGetEchoTimelineSyncWalksEveryEcho(t *testing.T) {
+	_, repo := newTestRepository(t)
+	echos := createEchos(t, repo, "e0", "e1", "e2", "e3", "e4", "e5")
+
+	// 客户端已有 e0，按 next_since_id 连续同步，既不遗漏也不重复
+	var synced []string
+	cursor := commonModel.CursorQueryDto{SinceID: echos[0].ID, Limit: 2}
+	for range 5 {
+		echos, hasMore, err := repo.GetEchoTimeline(0, cursor, []string{model.VisibilityPublic})
+		if err != nil {
+			t.Fatalf("timeline: %v", err)
+		}
+		result := commonModel.NewCursorQueryResult(echos, hasMore, cursor.SinceID, func(e model.Echo) uint { return e.ID })
+		for i := len(echos) - 1; i >= 0; i-- {
+			synced = append(synced, echos[i].Content)
+		}
+		cursor.SinceID = result.NextSinceID
+		if !hasMore {
+			break
+		}
+	}
+	if want := []string{"e1", "e2", "e3", "e4", "e5"}; !slices.Equal(synced, want) {
+		t.Errorf("synced %v, want %v", synced, want)
+	}
+}
+
+func TestGetEchoTimelineFilters(t *testing.T) {
+	db, repo := newTestRepository(t)
+	echos := createEchos(t, repo, "tagged", "plain")
+	private := model.Echo{Content: "private", UserID: 1, Visibility: model.VisibilityPrivate}
+	draft := model.Echo{Content: "draft", UserID: 1, Visibility: model.VisibilityPublic, Status: model.EchoStatusDraft}
+	for _, echo := range []*model.Echo{&private, &draft} {
+		if err := repo.CreateEcho(context.Background(), echo); err != nil {
+			t.Fatalf("create echo: %v", err)
+		}
+	}
+	tag := model.Tag{Name: "go"}
+	if err := db.Create(&tag).Error; err != nil {
+		t.Fatalf("create tag: %v", err)
+	}
+	if err := db.Model(&echos[0]).Association("Tags").Append(&tag); err != nil {
+		t.Fatalf("tag echo: %v", err)
+	}
+	ClearEchoPageCache()
+
+	tests := map[string]struct {
+		tagId        uint
+		visibilities []string
+		want         []string
+	}{
+		"公开":    {want: []string{"plain", "tagged"}},
+		"包含私密":  {visibilities: []string{model.VisibilityPublic, model.VisibilityPrivate}, want: []string{"private", "plain", "tagged"}},
+		"按标签过滤": {tagId: tag.ID, want: []string{"tagged"}},
+	}
+
+	for name, tt := range tests {
+		got, _ := timelineContents(t, repo, tt.tagId, commonModel.CursorQueryDto{Limit: 10}, tt.visibilities...)
+		// 草稿不出现在时间线中
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", name, got, tt.want)
+		}
+	}
+}
+
+func TestTimelineCacheInvalidation(t *testing.T) {
+	db, repo := newTestRepository(t)
+	createEchos(t, repo, "e1")
+	cursor := commonModel.CursorQueryDto{Limit: 10}
+
+	if got, _ := timelineContents(t, repo, 0, cursor); !slices.Equal(got, []string{"e1"}) {
+		t.Fatalf("timeline = %v", got)
+	}
+
+	// 绕过仓储直接写入的数据不会使缓存失效
+	if err := db.Create(&model.Echo{Content: "direct", UserID: 1, Visibility: model.VisibilityPublic}).Error; err != nil {
+		t.Fatalf("create echo: %v", err)
+	}
+	if got, _ := timelineContents(t, repo, 0, cursor); !slices.Equal(got, []string{"e1"}) {
+		t.Fatalf("expected cached timeline, got %v", got)
+	}
+
+	// 通过仓储写入后版本号递增，旧的缓存键不再命中，最后修改时间前移
+	version := timelineVersion.Load()
+	updatedAt := TimelineUpdatedAt()
+	oldKey := GetEchoTimelineCacheKey(0, cursor, []string{model.VisibilityPublic})
+	time.Sleep(time.Millisecond)
+	createEchos(t, repo, "e2")
+
+	if timelineVersion.Load() != version+1 {
+		t.Errorf("version = %d, want %d", timelineVersion.Load(), version+1)
+	}
+	if !TimelineUpdatedAt().After(updatedAt) {
+		t.Errorf("updated at %v is not after %v", TimelineUpdatedAt(), updatedAt)
+	}
+	if newKey := GetEchoTimelineCacheKey(0, cursor, []string{model.VisibilityPublic}); newKey == oldKey {
+		t.Errorf("cache key %s did not change", newKey)
+	}
+	if got, _ := timelineContents(t, repo, 0, cursor); !slices.Equal(got, []string{"e2", "direct", "e1"}) {
+		t.Errorf("timeline after write = %v", got)
+	}
+}
+
+func TestCacheKeysSeparateQueries(t *testing.T) {
+	public := []string{model.VisibilityPublic}
+	base := GetEchoTimelineCacheKey(0, commonModel.CursorQueryDto{Limit: 10}, public)
+
+	for name, key := range map[string]string{
+		"标签":        GetEchoTimelineCacheKey(1, commonModel.CursorQueryDto{Limit: 10}, public),
+		"before_id": GetEchoTimelineCacheKey(0, commonModel.CursorQueryDto{BeforeID: 3, Limit: 10}, public),
+		"since_id":  GetEchoTimelineCacheKey(0, commonModel.CursorQueryDto{SinceID: 3, Limit: 10}, public),
+		"数量":        GetEchoTimelineCacheKey(0, commonModel.CursorQueryDto{Limit: 11}, public),
+		"检索词":       GetEchoTimelineCacheKey(0, commonModel.CursorQueryDto{Limit: 10, Search: "go"}, public),
+		"可见性":       GetEchoTimelineCacheKey(0, commonModel.CursorQueryDto{Limit: 10}, []string{model.VisibilityPublic, model.VisibilityPrivate}),
+	} {
+		if key == base {
+			t.Errorf("%s: key %s collides with the default timeline", name, key)
+		}
+	}
+}
+
+func TestGetEchoTimelineOrdersByPublishTime(t *testing.T) {
+	db, repo := newTestRepository(t)
+	echos := createEchos(t, repo, "e1", "e2")
+
+	// 定时 Echo 先于 e3 写入，ID 较小
+	publishAt := time.Now().Add(time.Hour)
+	scheduled := model.Echo{
+		Content:    "scheduled",
+		UserID:     1,
+		Visibility: model.VisibilityPublic,
+		Status:     model.EchoStatusScheduled,
+		PublishAt:  &publishAt,
+	}
+	if err := repo.CreateEcho(context.Background(), &scheduled); err != nil {
+		t.Fatalf("create echo: %v", err)
+	}
+	e3 := createEchos(t, repo, "e3")[0]
+
+	// 导入的 Echo ID 最大，但保留原始的创建时间
+	imported := model.Echo{
+		Content:    "imported",
+		UserID:     1,
+		Visibility: model.VisibilityPublic,
+		CreatedAt:  echos[0].CreatedAt.Add(-24 * time.Hour),
+	}
+	if err := repo.CreateEcho(context.Background(), &imported); err != nil {
+		t.Fatalf("create echo: %v", err)
+	}
+
+	if got, _ := timelineContents(t, repo, 0, commonModel.CursorQueryDto{Limit: 10}); !slices.Equal(got, []string{"e3", "e2", "e1", "imported"}) {
+		t.Fatalf("timeline before publishing = %v", got)
+	}
+
+	// 已同步到 e3 的客户端在定时 Echo 发布后能取到它
+	if _, err := repo.PublishEcho(context.Background(), scheduled.ID, time.Now()); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	tests := map[string]struct {
+		cursor      commonModel.CursorQueryDto
+		want        []string
+		wantHasMore bool
+	}{
+		"发布后排在最新":           {cursor: commonModel.CursorQueryDto{Limit: 10}, want: []string{"scheduled", "e3", "e2", "e1", "imported"}},
+		"since_id 同步到新发布的":  {cursor: commonModel.CursorQueryDto{SinceID: e3.ID, Limit: 10}, want: []string{"scheduled"}},
+		"导入的 Echo 不算作新数据":   {cursor: commonModel.CursorQueryDto{SinceID: echos[1].ID, Limit: 10}, want: []string{"scheduled", "e3"}},
+		"before_id 按发布时间翻页": {cursor: commonModel.CursorQueryDto{BeforeID: scheduled.ID, Limit: 2}, want: []string{"e3", "e2"}, wantHasMore: true},
+		"翻页到导入的 Echo":       {cursor: commonModel.CursorQueryDto{BeforeID: echos[0].ID, Limit: 2}, want: []string{"imported"}},
+		"导入的 Echo 之后没有更旧的":  {cursor: commonModel.CursorQueryDto{BeforeID: imported.ID, Limit: 2}, want: []string{}},
+		"从导入的 Echo 向新同步":    {cursor: commonModel.CursorQueryDto{SinceID: imported.ID, Limit: 2}, want: []string{"e2", "e1"}, wantHasMore: true},
+	}
+
+	for name, tt := range tests {
+		got, hasMore := timelineContents(t, repo, 0, tt.cursor)
+		if !slices.Equal(got, tt.want) || hasMore != tt.wantHasMore {
+			t.Errorf("%s: got %v hasMore %v, want %v hasMore %v", name, got, hasMore, tt.want, tt.wantHasMore)
+		}
+	}
+
+	// 同一时间创建的 Echo 按 ID 区分先后
+	same := echos[1].CreatedAt
+	if err := db.Model(&model.Echo{}).Where("id = ?", echos[0].ID).Update("created_at", same).Error; err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	ClearEchoPageCache()
+	if got, _ := timelineContents(t, repo, 0, commonModel.CursorQueryDto{BeforeID: echos[1].ID, Limit: 10}); !slices.Equal(got, []string{"e1", "imported"}) {
+		t.Errorf("timeline with equal created_at = %v", got)
+	}
+}
+
+func TestGetEchoTimelineCursorOfDeletedEcho(t *testing.T) {
+	db, repo := newTestRepository(t)
+	echos := createEchos(t, repo, "e1", "e2", "e3", "e4")
+
+	// 移入回收站的 Echo 仍按原位置作为游标
+	if err := repo.DeleteEchoById(context.Background(), echos[2].ID); err != nil {
+		t.Fatalf("trash: %v", err)
+	}
+	if got, _ := timelineContents(t, repo, 0, commonModel.CursorQueryDto{BeforeID: echos[2].ID, Limit: 10}); !slices.Equal(got, []string{"e2", "e1"}) {
+		t.Errorf("before trashed echo = %v", got)
+	}
+
+	// 彻底删除后退化为按 ID 比较
+	if err := db.Unscoped().Delete(&model.Echo{}, echos[2].ID).Error; err != nil {
+		t.Fatalf("purge: %v", err)
+	}
+	ClearEchoPageCache()
+	if got, _ := timelineContents(t, repo, 0, commonModel.CursorQueryDto{SinceID: echos[2].ID, Limit: 10}); !slices.Equal(got, []string{"e4"}) {
+		t.Errorf("since purged echo = %v", got)
+	}
+}
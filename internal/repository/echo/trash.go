@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+
+	model "github.com/lin-snow/ech0/internal/model/echo"
+	"gorm.io/gorm"
+)
+
+// GetTrashedEchos 获取回收站中的 Echo，按删除时间倒序
+func (echoRepository *EchoRepository) GetTrashedEchos() ([]model.Echo, error) {
+	var echos []model.Echo
+	if err := echoRepository.db().Unscoped().
+		Where("deleted_at IS NOT NULL").
+		Preload("Images").
+		Order("deleted_at DESC").
+		Find(&echos).Error; err != nil {
+		return nil, err
+	}
+	return echos, nil
+}
+
+// GetTrashedEchoById 获取回收站中的 Echo，不存在时返回 nil
+func (echoRepository *EchoRepository) GetTrashedEchoById(ctx context.Context, id uint) (*model.Echo, error) {
+	var echos []model.Echo
+	if err := echoRepository.getDB(ctx).Unscoped().
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Preload("Images").
+		Preload("Tags").
+		Limit(1).
+		Find(&echos).Error; err != nil {
+		return nil, err
+	}
+	if len(echos) == 0 {
+		return nil, nil
+	}
+	return &echos[0], nil
+}
+
+// RestoreEchoById 将 Echo 从回收站中恢复
+func (echoRepository *EchoRepository) RestoreEchoById(ctx context.Context, id uint) error {
+	result := echoRepository.getDB(ctx).Unscoped().Model(&model.Echo{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	echoRepository.clearEchoCache(id)
+	return nil
+}
+
+// PurgeEchoById 彻底删除回收站中的 Echo 及其图片记录、标签关联、远端回复和历史版本
+func (echoRepository *EchoRepository) PurgeEchoById(ctx context.Context, id uint) error {
+	// 每条语句使用新的会话，避免查询条件相互叠加
+	db := echoRepository.getDB(ctx).Unscoped().Session(&gorm.Session{})
+
+	if err := db.Where("message_id = ?", id).Delete(&model.Image{}).Error; err != nil {
+		return err
+	}
+	if err := db.Where("echo_id = ?", id).Delete(&model.EchoTag{}).Error; err != nil {
+		return err
+	}
+	if err := db.Where("echo_id = ?", id).Delete(&model.RemoteReply{}).Error; err != nil {
+		return err
+	}
+	if err := db.Where("echo_id = ?", id).Delete(&model.EchoRevision{}).Error; err != nil {
+		return err
+	}
+
+	result := db.Where("id = ? AND deleted_at IS NOT NULL", id).Delete(&model.Echo{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	echoRepository.clearEchoCache(id)
+	return nil
+}
+
+// GetTrashedTags 获取回收站中的标签，按删除时间倒序
+func (echoRepository *EchoRepository) GetTrashedTags() ([]model.Tag, error) {
+	var tags []model.Tag
+	if err := echoRepository.db().Unscoped().
+		Where("deleted_at IS NOT NULL").
+		Order("deleted_at DESC").
+		Find(&tags).Error; err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// RestoreTagById 将标签从回收站中恢复
+func (echoRepository *EchoRepository) RestoreTagById(ctx context.Context, id uint) error {
+	result := echoRepository.getDB(ctx).Unscoped().Model(&model.Tag{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	ClearEchoPageCache()
+	return nil
+}
+
+// PurgeTagById 彻底删除回收站中的标签及其与 Echo 的关联
+func (echoRepository *EchoRepository) PurgeTagById(ctx context.Context, id uint) error {
+	// 每条语句使用新的会话，避免查询条件相互叠加
+	db := echoRepository.getDB(ctx).Unscoped().Session(&gorm.Session{})
+
+	if err := db.Where("tag_id = ?", id).Delete(&model.EchoTag{}).Error; err != nil {
+		return err
+	}
+
+	result := db.Where("id = ? AND deleted_at IS NOT NULL", id).Delete(&model.Tag{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	ClearEchoPageCache()
+	return nil
+}
@@ -59,6 +59,21 @@ func (r *FediverseRepository) FollowerExists(
 	return count > 0, nil
 }
 
+func (r *FediverseRepository) FollowerExistsByActivityID(
+	ctx context.Context,
+	userID uint,
+	actor, activityID string,
+) (bool, error) {
+	var count int64
+	if err := r.getDB(ctx).
+		Model(&model.Follower{}).
+		Where("user_id = ? AND actor_id = ? AND activity_id = ?", userID, actor, activityID).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
 func (r *FediverseRepository) SaveOrUpdateFollow(ctx context.Context, follow *model.Follow) error {
 	if follow == nil {
 		return errors.New("follow is nil")
@@ -198,7 +213,7 @@ func (r *FediverseRepository) ListInboxStatuses(
 func (r *FediverseRepository) UpdateFollowStatusByActivityID(
 	ctx context.Context,
 	userID uint,
-	activityID, status string,
+	activityID, objectID, status string,
 ) error {
 	if activityID == "" {
 		return errors.New("activity id is empty")
@@ -214,7 +229,7 @@ func (r *FediverseRepository) UpdateFollowStatusByActivityID(
 
 	result := r.getDB(ctx).
 		Model(&model.Follow{}).
-		Where("user_id = ? AND activity_id = ?", userID, activityID).
+		Where("user_id = ? AND activity_id = ? AND object_id = ?", userID, activityID, objectID).
 		Updates(updates)
 
 	if result.Error != nil {
@@ -227,3 +242,114 @@ func (r *FediverseRepository) UpdateFollowStatusByActivityID(
 
 	return nil
 }
+
+func (r *FediverseRepository) DeleteFollower(ctx context.Context, userID uint, actor string) error {
+	return r.getDB(ctx).
+		Where("user_id = ? AND actor_id = ?", userID, actor).
+		Delete(&model.Follower{}).
+		Error
+}
+
+func (r *FediverseRepository) DeleteInboxStatusesByObjectID(
+	ctx context.Context,
+	userID uint,
+	actor, objectID string,
+) (int64, error) {
+	result := r.getDB(ctx).
+		Where("user_id = ? AND actor_id = ? AND object_id = ?", userID, actor, objectID).
+		Delete(&model.InboxStatus{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *FediverseRepository) DeleteInboxStatusesByActor(
+	ctx context.Context,
+	userID uint,
+	actor string,
+) error {
+	return r.getDB(ctx).
+		Where("user_id = ? AND actor_id = ?", userID, actor).
+		Delete(&model.InboxStatus{}).
+		Error
+}
+
+func (r *FediverseRepository) SaveInteraction(
+	ctx context.Context,
+	interaction *model.Interaction,
+) error {
+	if interaction == nil {
+		return errors.New("interaction is nil")
+	}
+	return r.getDB(ctx).Create(interaction).Error
+}
+
+func (r *FediverseRepository) GetInteraction(
+	ctx context.Context,
+	echoID uint,
+	actor, interactionType string,
+) (*model.Interaction, error) {
+	var interaction model.Interaction
+	err := r.getDB(ctx).
+		Where("echo_id = ? AND actor_id = ? AND type = ?", echoID, actor, interactionType).
+		First(&interaction).
+		Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &interaction, nil
+}
+
+func (r *FediverseRepository) GetInteractionByActivityID(
+	ctx context.Context,
+	activityID string,
+) (*model.Interaction, error) {
+	var interaction model.Interaction
+	err := r.getDB(ctx).
+		Where("activity_id = ?", activityID).
+		First(&interaction).
+		Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &interaction, nil
+}
+
+func (r *FediverseRepository) DeleteInteraction(ctx context.Context, interactionID uint) error {
+	return r.getDB(ctx).Delete(&model.Interaction{}, interactionID).Error
+}
+
+func (r *FediverseRepository) SaveTombstone(ctx context.Context, tombstone *model.Tombstone) error {
+	if tombstone == nil {
+		return errors.New("tombstone is nil")
+	}
+
+	var count int64
+	if err := r.getDB(ctx).Model(&model.Tombstone{}).Where("echo_id = ?", tombstone.EchoID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	return r.getDB(ctx).Create(tombstone).Error
+}
+
+func (r *FediverseRepository) DeleteTombstone(ctx context.Context, echoID uint) error {
+	return r.getDB(ctx).Where("echo_id = ?", echoID).Delete(&model.Tombstone{}).Error
+}
+
+func (r *FediverseRepository) GetTombstoneByEchoID(echoID uint) (*model.Tombstone, error) {
+	var tombstone model.Tombstone
+	err := r.db().Where("echo_id = ?", echoID).First(&tombstone).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &tombstone, nil
+}
@@ -19,6 +19,9 @@ type FediverseRepositoryInterface interface {
 	// 检查粉丝记录是否存在
 	FollowerExists(ctx context.Context, userID uint, actor string) (bool, error)
 
+	// FollowerExistsByActivityID 检查指定 Actor 是否以该 Follow 活动关注了用户
+	FollowerExistsByActivityID(ctx context.Context, userID uint, actor, activityID string) (bool, error)
+
 	// 保存或更新新的关注关系
 	SaveOrUpdateFollow(ctx context.Context, follow *model.Follow) error
 
@@ -42,10 +45,48 @@ type FediverseRepositoryInterface interface {
 		page, pageSize int,
 	) ([]model.InboxStatus, int64, error)
 
-	// UpdateFollowStatusByActivityID 根据 Follow Activity ID 更新关注状态
+	// UpdateFollowStatusByActivityID 根据 Follow Activity ID 更新关注状态，objectID 为被关注的 Actor，需与回应者一致
 	UpdateFollowStatusByActivityID(
 		ctx context.Context,
 		userID uint,
-		activityID, status string,
+		activityID, objectID, status string,
 	) error
+
+	// DeleteFollower 删除粉丝记录
+	DeleteFollower(ctx context.Context, userID uint, actor string) error
+
+	// DeleteInboxStatusesByObjectID 删除指定 Actor 发布的 Object 对应的收件箱推文，返回删除条数
+	DeleteInboxStatusesByObjectID(
+		ctx context.Context,
+		userID uint,
+		actor, objectID string,
+	) (int64, error)
+
+	// DeleteInboxStatusesByActor 删除指定 Actor 的全部收件箱推文
+	DeleteInboxStatusesByActor(ctx context.Context, userID uint, actor string) error
+
+	// SaveInteraction 存储远端互动记录
+	SaveInteraction(ctx context.Context, interaction *model.Interaction) error
+
+	// GetInteraction 根据 Echo、Actor 和互动类型获取互动记录
+	GetInteraction(
+		ctx context.Context,
+		echoID uint,
+		actor, interactionType string,
+	) (*model.Interaction, error)
+
+	// GetInteractionByActivityID 根据 Activity ID 获取互动记录
+	GetInteractionByActivityID(ctx context.Context, activityID string) (*model.Interaction, error)
+
+	// DeleteInteraction 删除互动记录
+	DeleteInteraction(ctx context.Context, interactionID uint) error
+
+	// SaveTombstone 记录已删除的 Echo，重复记录时忽略
+	SaveTombstone(ctx context.Context, tombstone *model.Tombstone) error
+
+	// GetTombstoneByEchoID 根据 Echo ID 获取删除记录，不存在时返回 nil
+	GetTombstoneByEchoID(echoID uint) (*model.Tombstone, error)
+
+	// DeleteTombstone 删除 Echo 的删除记录，Echo 从回收站恢复时调用
+	DeleteTombstone(ctx context.Context, echoID uint) error
 }
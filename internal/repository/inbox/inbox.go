@@ -2,10 +2,12 @@ package repository
 
 import (
 	"context"
+	"slices"
 
 	inboxModel "github.com/lin-snow/ech0/internal/model/inbox"
 	"github.com/lin-snow/ech0/internal/transaction"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type InboxRepository struct {
@@ -79,6 +81,57 @@ func (inboxRepository *InboxRepository) GetInboxList(
 	return inboxes, total, nil
 }
 
+// GetInboxByCursor 按游标获取收件箱消息，结果按 ID 倒序返回
+//
+// 只指定 sinceID 时从 sinceID 之后按升序取数，保证客户端连续同步时不会遗漏或重复
+func (inboxRepository *InboxRepository) GetInboxByCursor(
+	ctx context.Context,
+	beforeID, sinceID uint,
+	limit int,
+	search string,
+) ([]*inboxModel.Inbox, bool, error) {
+	query := inboxRepository.getDB(ctx).
+		Model(&inboxModel.Inbox{})
+
+	if search != "" {
+		searchLike := "%" + search + "%"
+		query = query.Where(
+			"content LIKE ? OR source LIKE ? OR type LIKE ?",
+			searchLike,
+			searchLike,
+			searchLike,
+		)
+	}
+	if beforeID != 0 {
+		query = query.Where("id < ?", beforeID)
+	}
+	if sinceID != 0 {
+		query = query.Where("id > ?", sinceID)
+	}
+
+	ascending := sinceID != 0 && beforeID == 0
+	order := "id DESC"
+	if ascending {
+		order = "id ASC"
+	}
+
+	// 多取一条用于判断是否还有更多数据
+	var inboxes []*inboxModel.Inbox
+	if err := query.Order(order).Limit(limit + 1).Find(&inboxes).Error; err != nil {
+		return nil, false, err
+	}
+
+	hasMore := len(inboxes) > limit
+	if hasMore {
+		inboxes = inboxes[:limit]
+	}
+	if ascending {
+		slices.Reverse(inboxes)
+	}
+
+	return inboxes, hasMore, nil
+}
+
 // GetInboxById 获取指定 ID 的收件箱消息
 func (inboxRepository *InboxRepository) GetInboxById(ctx context.Context, inboxID uint) (*inboxModel.Inbox, error) {
 	var inbox inboxModel.Inbox
@@ -136,7 +189,7 @@ func (inboxRepository *InboxRepository) GetUnreadInbox(
 ) ([]*inboxModel.Inbox, error) {
 	var inboxes []*inboxModel.Inbox
 	if err := inboxRepository.getDB(ctx).
-		Where("read = ?", false).
+		Where(clause.Eq{Column: clause.Column{Name: "read"}, Value: false}). // read 在 MySQL 中是保留字
 		// 以创建时间倒序（最新在前）；同一时间戳内用 id 倒序保证稳定排序
 		Order("created_at DESC").Order("id DESC").
 		Find(&inboxes).Error; err != nil {
@@ -17,6 +17,14 @@ type InboxRepositoryInterface interface {
 		search string,
 	) ([]*inboxModel.Inbox, int64, error)
 
+	// 按游标获取收件箱消息
+	GetInboxByCursor(
+		ctx context.Context,
+		beforeID, sinceID uint,
+		limit int,
+		search string,
+	) ([]*inboxModel.Inbox, bool, error)
+
 	// 获取指定 ID 的收件箱消息
 	GetInboxById(ctx context.Context, inboxID uint) (*inboxModel.Inbox, error)
 
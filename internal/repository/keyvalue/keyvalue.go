@@ -7,6 +7,7 @@ import (
 	model "github.com/lin-snow/ech0/internal/model/common"
 	"github.com/lin-snow/ech0/internal/transaction"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type KeyValueRepository struct {
@@ -44,7 +45,7 @@ func (keyvalueRepository *KeyValueRepository) GetKeyValue(key string) (interface
 
 	// 缓存未命中，查询数据库
 	var kv model.KeyValue
-	if err := keyvalueRepository.db().Where("key = ?", key).First(&kv).Error; err != nil {
+	if err := keyvalueRepository.db().Where(keyEquals(key)).First(&kv).Error; err != nil {
 		return nil, err
 	}
 
@@ -84,7 +85,7 @@ func (keyvalueRepository *KeyValueRepository) DeleteKeyValue(
 	// 删除缓存
 	keyvalueRepository.cache.Delete(key) // 删除该键的缓存
 
-	if err := keyvalueRepository.getDB(ctx).Where("key = ?", key).Delete(&model.KeyValue{}).Error; err != nil {
+	if err := keyvalueRepository.getDB(ctx).Where(keyEquals(key)).Delete(&model.KeyValue{}).Error; err != nil {
 		return err
 	}
 
@@ -100,7 +101,7 @@ func (keyvalueRepository *KeyValueRepository) UpdateKeyValue(
 	// 更新缓存
 	keyvalueRepository.cache.Delete(key) // 删除该键的缓存
 
-	if err := keyvalueRepository.getDB(ctx).Model(&model.KeyValue{}).Where("key = ?", key).Update("value", value.(string)).Error; err != nil {
+	if err := keyvalueRepository.getDB(ctx).Model(&model.KeyValue{}).Where(keyEquals(key)).Update("value", value.(string)).Error; err != nil {
 		return err
 	}
 
@@ -119,7 +120,7 @@ func (keyvalueRepository *KeyValueRepository) AddOrUpdateKeyValue(
 	// 先尝试更新
 	result := keyvalueRepository.getDB(ctx).
 		Model(&model.KeyValue{}).
-		Where("key = ?", key).
+		Where(keyEquals(key)).
 		Update("value", value.(string))
 	if result.Error != nil {
 		return result.Error
@@ -140,3 +141,8 @@ func (keyvalueRepository *KeyValueRepository) AddOrUpdateKeyValue(
 
 	return nil
 }
+
+// keyEquals 构造按键查询的条件，key 在 MySQL 中是保留字，需要由方言引用列名
+func keyEquals(key string) clause.Eq {
+	return clause.Eq{Column: clause.Column{Name: "key"}, Value: key}
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	model "github.com/lin-snow/ech0/internal/model/queue"
 )
@@ -19,4 +20,40 @@ type QueueRepositoryInterface interface {
 
 	// UpdateDeadLetter 更新死信任务
 	UpdateDeadLetter(ctx context.Context, deadLetter *model.DeadLetter) error
+
+	// ListDueDeadLetters 列出已到重试时间的待处理死信任务
+	ListDueDeadLetters(now time.Time, limit int) ([]model.DeadLetter, error)
+
+	// RecoverStaleDeadLetters 将 before 之前进入处理中状态的死信任务重新置为待处理，返回恢复数量
+	RecoverStaleDeadLetters(ctx context.Context, before time.Time) (int64, error)
+
+	// ListDeadLettersByPage 分页列出死信任务，status 为空时不过滤状态
+	ListDeadLettersByPage(status string, offset, limit int) ([]model.DeadLetter, int64, error)
+
+	// GetDeadLetterByID 根据 ID 获取死信任务，不存在时返回 nil
+	GetDeadLetterByID(id int64) (*model.DeadLetter, error)
+
+	// PurgeDeadLetters 删除指定状态的死信任务，返回删除数量
+	PurgeDeadLetters(ctx context.Context, statuses ...string) (int64, error)
+
+	// PurgeDeadLettersBefore 删除 before 之前最后更新的指定状态的死信任务，返回删除数量
+	PurgeDeadLettersBefore(ctx context.Context, before time.Time, statuses ...string) (int64, error)
+
+	// SaveOutboxEvent 写入持久化事件
+	SaveOutboxEvent(ctx context.Context, outboxEvent *model.OutboxEvent) error
+
+	// ListOutboxEventsAfter 按序号升序列出 afterID 之后的事件
+	ListOutboxEventsAfter(afterID uint, limit int) ([]model.OutboxEvent, error)
+
+	// GetLatestOutboxEventID 获取最新的事件序号，没有事件时返回 0
+	GetLatestOutboxEventID() (uint, error)
+
+	// DeleteOutboxEventsBefore 删除序号不超过 maxID 且早于 before 的事件，返回删除数量
+	DeleteOutboxEventsBefore(ctx context.Context, maxID uint, before time.Time) (int64, error)
+
+	// GetEventCursor 获取订阅者游标，不存在时返回 nil
+	GetEventCursor(subscriber string) (*model.EventCursor, error)
+
+	// SaveEventCursor 保存订阅者游标
+	SaveEventCursor(ctx context.Context, cursor *model.EventCursor) error
 }
@@ -2,10 +2,13 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	model "github.com/lin-snow/ech0/internal/model/queue"
 	"github.com/lin-snow/ech0/internal/transaction"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type QueueRepository struct {
@@ -53,3 +56,190 @@ func (queueRepository *QueueRepository) UpdateDeadLetter(
 ) error {
 	return queueRepository.getDB(ctx).Save(deadLetter).Error
 }
+
+// ListDueDeadLetters 列出已到重试时间的待处理死信任务，按重试时间先后排序
+func (queueRepository *QueueRepository) ListDueDeadLetters(
+	now time.Time,
+	limit int,
+) ([]model.DeadLetter, error) {
+	var deadLetters []model.DeadLetter
+	err := queueRepository.db().
+		Where("status = ? AND next_retry <= ?", model.DeadLetterStatusPending, now).
+		Order("next_retry ASC").
+		Limit(limit).
+		Find(&deadLetters).Error
+	if err != nil {
+		return []model.DeadLetter{}, err
+	}
+	return deadLetters, nil
+}
+
+// RecoverStaleDeadLetters 将 before 之前进入处理中状态的死信任务重新置为待处理，
+// 用于恢复处理过程中进程崩溃或重启而停留在处理中的任务
+func (queueRepository *QueueRepository) RecoverStaleDeadLetters(
+	ctx context.Context,
+	before time.Time,
+) (int64, error) {
+	result := queueRepository.getDB(ctx).
+		Model(&model.DeadLetter{}).
+		Where("status = ? AND updated_at < ?", model.DeadLetterStatusProcessing, before).
+		Updates(map[string]any{
+			"status":     model.DeadLetterStatusPending,
+			"next_retry": time.Now(),
+			"updated_at": time.Now(),
+		})
+	return result.RowsAffected, result.Error
+}
+
+// ListDeadLettersByPage 分页列出死信任务，status 为空时不过滤状态
+func (queueRepository *QueueRepository) ListDeadLettersByPage(
+	status string,
+	offset, limit int,
+) ([]model.DeadLetter, int64, error) {
+	var (
+		deadLetters []model.DeadLetter
+		total       int64
+	)
+
+	query := queueRepository.db().Model(&model.DeadLetter{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	query = query.Order("created_at DESC").Order("id DESC")
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	if err := query.Find(&deadLetters).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return deadLetters, total, nil
+}
+
+// GetDeadLetterByID 根据 ID 获取死信任务，不存在时返回 nil
+func (queueRepository *QueueRepository) GetDeadLetterByID(id int64) (*model.DeadLetter, error) {
+	var deadLetter model.DeadLetter
+	if err := queueRepository.db().First(&deadLetter, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &deadLetter, nil
+}
+
+// PurgeDeadLetters 删除指定状态的死信任务，返回删除数量
+func (queueRepository *QueueRepository) PurgeDeadLetters(
+	ctx context.Context,
+	statuses ...string,
+) (int64, error) {
+	if len(statuses) == 0 {
+		return 0, nil
+	}
+
+	result := queueRepository.getDB(ctx).
+		Where("status IN ?", statuses).
+		Delete(&model.DeadLetter{})
+	return result.RowsAffected, result.Error
+}
+
+// PurgeDeadLettersBefore 删除 before 之前最后更新的指定状态的死信任务，返回删除数量
+func (queueRepository *QueueRepository) PurgeDeadLettersBefore(
+	ctx context.Context,
+	before time.Time,
+	statuses ...string,
+) (int64, error) {
+	if len(statuses) == 0 {
+		return 0, nil
+	}
+
+	result := queueRepository.getDB(ctx).
+		Where("status IN ? AND updated_at < ?", statuses, before).
+		Delete(&model.DeadLetter{})
+	return result.RowsAffected, result.Error
+}
+
+// SaveOutboxEvent 写入持久化事件
+func (queueRepository *QueueRepository) SaveOutboxEvent(
+	ctx context.Context,
+	outboxEvent *model.OutboxEvent,
+) error {
+	return queueRepository.getDB(ctx).Create(outboxEvent).Error
+}
+
+// ListOutboxEventsAfter 按序号升序列出 afterID 之后的事件
+func (queueRepository *QueueRepository) ListOutboxEventsAfter(
+	afterID uint,
+	limit int,
+) ([]model.OutboxEvent, error) {
+	var outboxEvents []model.OutboxEvent
+	err := queueRepository.db().
+		Where("id > ?", afterID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&outboxEvents).Error
+	if err != nil {
+		return []model.OutboxEvent{}, err
+	}
+	return outboxEvents, nil
+}
+
+// GetLatestOutboxEventID 获取最新的事件序号，没有事件时返回 0
+func (queueRepository *QueueRepository) GetLatestOutboxEventID() (uint, error) {
+	var latest uint
+	err := queueRepository.db().
+		Model(&model.OutboxEvent{}).
+		Select("COALESCE(MAX(id), 0)").
+		Scan(&latest).Error
+	return latest, err
+}
+
+// DeleteOutboxEventsBefore 删除序号不超过 maxID 且早于 before 的事件，返回删除数量
+func (queueRepository *QueueRepository) DeleteOutboxEventsBefore(
+	ctx context.Context,
+	maxID uint,
+	before time.Time,
+) (int64, error) {
+	result := queueRepository.getDB(ctx).
+		Where("id <= ? AND created_at < ?", maxID, before).
+		Delete(&model.OutboxEvent{})
+	return result.RowsAffected, result.Error
+}
+
+// GetEventCursor 获取订阅者游标，不存在时返回 nil
+func (queueRepository *QueueRepository) GetEventCursor(
+	subscriber string,
+) (*model.EventCursor, error) {
+	var cursor model.EventCursor
+	if err := queueRepository.db().
+		Where("subscriber = ?", subscriber).
+		First(&cursor).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &cursor, nil
+}
+
+// SaveEventCursor 保存订阅者游标
+func (queueRepository *QueueRepository) SaveEventCursor(
+	ctx context.Context,
+	cursor *model.EventCursor,
+) error {
+	return queueRepository.getDB(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "subscriber"}},
+			DoUpdates: clause.AssignmentColumns([]string{"last_event_id", "updated_at"}),
+		}).
+		Create(cursor).Error
+}
@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	model "github.com/lin-snow/ech0/internal/model/auth"
+)
+
+type SessionRepositoryInterface interface {
+	// CreateSession 创建登录会话
+	CreateSession(ctx context.Context, session *model.Session) error
+
+	// GetSessionByID 根据 ID 获取登录会话，不存在时返回 nil
+	GetSessionByID(id uint) (*model.Session, error)
+
+	// GetSessionByRefreshHash 根据当前刷新令牌摘要获取登录会话，不存在时返回 nil
+	GetSessionByRefreshHash(tokenHash string) (*model.Session, error)
+
+	// GetSessionByPreviousHash 根据上一个刷新令牌摘要获取登录会话，不存在时返回 nil
+	GetSessionByPreviousHash(tokenHash string) (*model.Session, error)
+
+	// RotateSession 轮换会话的刷新令牌，仅当当前摘要仍为 oldHash 时生效，返回是否轮换成功
+	RotateSession(ctx context.Context, session *model.Session, oldHash string) (bool, error)
+
+	// ListSessionsByUserID 列出用户未过期的登录会话
+	ListSessionsByUserID(userID uint, now time.Time) ([]model.Session, error)
+
+	// DeleteSession 删除用户的指定登录会话，返回是否删除了会话
+	DeleteSession(ctx context.Context, userID, id uint) (bool, error)
+
+	// DeleteSessionsByUserID 删除用户的全部登录会话
+	DeleteSessionsByUserID(ctx context.Context, userID uint) error
+
+	// DeleteExpiredSessions 删除已过期的登录会话
+	DeleteExpiredSessions(ctx context.Context, now time.Time) error
+}
@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	model "github.com/lin-snow/ech0/internal/model/auth"
+	"github.com/lin-snow/ech0/internal/transaction"
+	"gorm.io/gorm"
+)
+
+type SessionRepository struct {
+	db func() *gorm.DB
+}
+
+func NewSessionRepository(dbProvider func() *gorm.DB) SessionRepositoryInterface {
+	return &SessionRepository{
+		db: dbProvider,
+	}
+}
+
+// getDB 从上下文中获取事务
+func (sessionRepository *SessionRepository) getDB(ctx context.Context) *gorm.DB {
+	if tx, ok := ctx.Value(transaction.TxKey).(*gorm.DB); ok {
+		return tx
+	}
+	return sessionRepository.db()
+}
+
+// CreateSession 创建登录会话
+func (sessionRepository *SessionRepository) CreateSession(
+	ctx context.Context,
+	session *model.Session,
+) error {
+	return sessionRepository.getDB(ctx).Create(session).Error
+}
+
+// GetSessionByID 根据 ID 获取登录会话，不存在时返回 nil
+func (sessionRepository *SessionRepository) GetSessionByID(id uint) (*model.Session, error) {
+	return sessionRepository.findSession("id = ?", id)
+}
+
+// GetSessionByRefreshHash 根据当前刷新令牌摘要获取登录会话，不存在时返回 nil
+func (sessionRepository *SessionRepository) GetSessionByRefreshHash(
+	tokenHash string,
+) (*model.Session, error) {
+	return sessionRepository.findSession("refresh_token_hash = ?", tokenHash)
+}
+
+// GetSessionByPreviousHash 根据上一个刷新令牌摘要获取登录会话，不存在时返回 nil
+func (sessionRepository *SessionRepository) GetSessionByPreviousHash(
+	tokenHash string,
+) (*model.Session, error) {
+	return sessionRepository.findSession("previous_token_hash = ?", tokenHash)
+}
+
+// findSession 按条件查询单个登录会话
+func (sessionRepository *SessionRepository) findSession(
+	query string,
+	args ...any,
+) (*model.Session, error) {
+	var session model.Session
+	if err := sessionRepository.db().Where(query, args...).First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+// RotateSession 轮换会话的刷新令牌，仅当当前摘要仍为 oldHash 时生效，返回是否轮换成功
+func (sessionRepository *SessionRepository) RotateSession(
+	ctx context.Context,
+	session *model.Session,
+	oldHash string,
+) (bool, error) {
+	result := sessionRepository.getDB(ctx).
+		Model(&model.Session{}).
+		Where("id = ? AND refresh_token_hash = ?", session.ID, oldHash).
+		Updates(map[string]any{
+			"refresh_token_hash":  session.RefreshTokenHash,
+			"previous_token_hash": session.PreviousTokenHash,
+			"user_agent":          session.UserAgent,
+			"device":              session.Device,
+			"ip":                  session.IP,
+			"last_used_at":        session.LastUsedAt,
+			"expires_at":          session.ExpiresAt,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// ListSessionsByUserID 列出用户未过期的登录会话
+func (sessionRepository *SessionRepository) ListSessionsByUserID(
+	userID uint,
+	now time.Time,
+) ([]model.Session, error) {
+	var sessions []model.Session
+	if err := sessionRepository.db().
+		Where("user_id = ? AND expires_at > ?", userID, now).
+		Order("last_used_at DESC").
+		Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// DeleteSession 删除用户的指定登录会话，返回是否删除了会话
+func (sessionRepository *SessionRepository) DeleteSession(
+	ctx context.Context,
+	userID, id uint,
+) (bool, error) {
+	result := sessionRepository.getDB(ctx).
+		Where("id = ? AND user_id = ?", id, userID).
+		Delete(&model.Session{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// DeleteSessionsByUserID 删除用户的全部登录会话
+func (sessionRepository *SessionRepository) DeleteSessionsByUserID(
+	ctx context.Context,
+	userID uint,
+) error {
+	return sessionRepository.getDB(ctx).
+		Where("user_id = ?", userID).
+		Delete(&model.Session{}).Error
+}
+
+// DeleteExpiredSessions 删除已过期的登录会话
+func (sessionRepository *SessionRepository) DeleteExpiredSessions(
+	ctx context.Context,
+	now time.Time,
+) error {
+	return sessionRepository.getDB(ctx).
+		Where("expires_at <= ?", now).
+		Delete(&model.Session{}).Error
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	model "github.com/lin-snow/ech0/internal/model/setting"
 )
@@ -13,6 +14,12 @@ type SettingRepositoryInterface interface {
 	// CreateAccessToken 创建访问令牌
 	CreateAccessToken(ctx context.Context, token *model.AccessTokenSetting) error
 
-	// DeleteAccessTokenByID 删除访问令牌
-	DeleteAccessTokenByID(ctx context.Context, id uint) error
+	// DeleteAccessTokenByID 删除指定用户的访问令牌
+	DeleteAccessTokenByID(ctx context.Context, userID, id uint) error
+
+	// GetAccessTokenByHash 根据令牌摘要获取访问令牌
+	GetAccessTokenByHash(tokenHash string) (*model.AccessTokenSetting, error)
+
+	// UpdateAccessTokenUsage 记录访问令牌的最近使用时间与 IP
+	UpdateAccessTokenUsage(ctx context.Context, id int, usedAt time.Time, ip string) error
 }
@@ -2,6 +2,8 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	model "github.com/lin-snow/ech0/internal/model/setting"
 	"github.com/lin-snow/ech0/internal/transaction"
@@ -46,11 +48,42 @@ func (settingRepository *SettingRepository) CreateAccessToken(
 	return db.Create(token).Error
 }
 
-// DeleteAccessTokenByID 删除访问令牌
+// DeleteAccessTokenByID 删除指定用户的访问令牌，不属于该用户的令牌不受影响
 func (settingRepository *SettingRepository) DeleteAccessTokenByID(
 	ctx context.Context,
-	id uint,
+	userID, id uint,
 ) error {
 	db := settingRepository.getDB(ctx)
-	return db.Delete(&model.AccessTokenSetting{}, id).Error
+	return db.Where("user_id = ?", userID).Delete(&model.AccessTokenSetting{}, id).Error
+}
+
+// GetAccessTokenByHash 根据令牌摘要获取访问令牌
+func (settingRepository *SettingRepository) GetAccessTokenByHash(
+	tokenHash string,
+) (*model.AccessTokenSetting, error) {
+	var token model.AccessTokenSetting
+	err := settingRepository.db().Where("token_hash = ?", tokenHash).First(&token).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// UpdateAccessTokenUsage 记录访问令牌的最近使用时间与 IP
+func (settingRepository *SettingRepository) UpdateAccessTokenUsage(
+	ctx context.Context,
+	id int,
+	usedAt time.Time,
+	ip string,
+) error {
+	db := settingRepository.getDB(ctx)
+	return db.Model(&model.AccessTokenSetting{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"last_used_at": usedAt,
+			"last_used_ip": ip,
+		}).Error
 }
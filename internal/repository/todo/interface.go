@@ -19,6 +19,18 @@ type TodoRepositoryInterface interface {
 	// UpdateTodo 更新待办事项
 	UpdateTodo(ctx context.Context, todo *model.Todo) error
 
-	// DeleteTodo 删除待办事项
+	// DeleteTodo 将待办事项移入回收站
 	DeleteTodo(ctx context.Context, id int64) error
+
+	// GetTrashedTodos 获取回收站中的待办事项
+	GetTrashedTodos() ([]model.Todo, error)
+
+	// GetTrashedTodoById 获取回收站中的待办事项
+	GetTrashedTodoById(ctx context.Context, id uint) (*model.Todo, error)
+
+	// RestoreTodoById 将待办事项从回收站中恢复
+	RestoreTodoById(ctx context.Context, id uint) error
+
+	// PurgeTodoById 彻底删除回收站中的待办事项
+	PurgeTodoById(ctx context.Context, id uint) error
 }
@@ -78,7 +78,7 @@ func (todoRepository *TodoRepository) UpdateTodo(ctx context.Context, todo *mode
 	return nil
 }
 
-// DeleteTodo 删除待办事项
+// DeleteTodo 将待办事项移入回收站
 func (todoRepository *TodoRepository) DeleteTodo(ctx context.Context, id int64) error {
 	// 根据 ID 删除 To do
 	if err := todoRepository.getDB(ctx).Delete(&model.Todo{}, id).Error; err != nil {
@@ -87,3 +87,62 @@ func (todoRepository *TodoRepository) DeleteTodo(ctx context.Context, id int64)
 
 	return nil
 }
+
+// GetTrashedTodos 获取回收站中的待办事项，按删除时间倒序
+func (todoRepository *TodoRepository) GetTrashedTodos() ([]model.Todo, error) {
+	var todos []model.Todo
+	if err := todoRepository.db().Unscoped().
+		Where("deleted_at IS NOT NULL").
+		Order("deleted_at DESC").
+		Find(&todos).Error; err != nil {
+		return nil, err
+	}
+
+	return todos, nil
+}
+
+// GetTrashedTodoById 获取回收站中的待办事项，不存在时返回 nil
+func (todoRepository *TodoRepository) GetTrashedTodoById(ctx context.Context, id uint) (*model.Todo, error) {
+	var todos []model.Todo
+	if err := todoRepository.getDB(ctx).Unscoped().
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Limit(1).
+		Find(&todos).Error; err != nil {
+		return nil, err
+	}
+	if len(todos) == 0 {
+		return nil, nil
+	}
+
+	return &todos[0], nil
+}
+
+// RestoreTodoById 将待办事项从回收站中恢复
+func (todoRepository *TodoRepository) RestoreTodoById(ctx context.Context, id uint) error {
+	result := todoRepository.getDB(ctx).Unscoped().Model(&model.Todo{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// PurgeTodoById 彻底删除回收站中的待办事项
+func (todoRepository *TodoRepository) PurgeTodoById(ctx context.Context, id uint) error {
+	result := todoRepository.getDB(ctx).Unscoped().
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Delete(&model.Todo{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
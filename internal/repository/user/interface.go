@@ -62,4 +62,39 @@ type UserRepositoryInterface interface {
 	CacheSetPasskeySession(key string, val any, ttl time.Duration)
 	CacheGetPasskeySession(key string) (any, error)
 	CacheDeletePasskeySession(key string)
+
+	// GetTwoFactorByUserID 获取用户的两步验证配置，不存在时返回 nil
+	GetTwoFactorByUserID(userID uint) (*authModel.TwoFactor, error)
+
+	// SaveTwoFactor 创建或更新用户的两步验证配置
+	SaveTwoFactor(ctx context.Context, twoFactor *authModel.TwoFactor) error
+
+	// UpdateTwoFactorLastUsedStep 记录最近一次通过验证的时间步，时间步不大于已记录的值时返回 false
+	UpdateTwoFactorLastUsedStep(ctx context.Context, id uint, step int64) (bool, error)
+
+	// DeleteTwoFactor 删除用户的两步验证配置与全部恢复码
+	DeleteTwoFactor(ctx context.Context, userID uint) error
+
+	// ReplaceRecoveryCodes 用新的恢复码摘要替换用户的全部恢复码
+	ReplaceRecoveryCodes(ctx context.Context, userID uint, codeHashes []string) error
+
+	// UseRecoveryCode 将未使用的恢复码标记为已使用，恢复码不存在或已使用时返回 false
+	UseRecoveryCode(ctx context.Context, userID uint, codeHash string, usedAt time.Time) (bool, error)
+
+	// CountRecoveryCodes 统计用户剩余可用的恢复码数量
+	CountRecoveryCodes(userID uint) (int64, error)
+
+	// 两步验证登录挑战缓存
+	CacheSetTwoFactorChallenge(
+		key string,
+		challenge *authModel.TwoFactorChallenge,
+		ttl time.Duration,
+	)
+	CacheGetTwoFactorChallenge(key string) (*authModel.TwoFactorChallenge, error)
+	CacheDeleteTwoFactorChallenge(key string)
+
+	// OAuth2 一次性登录码缓存
+	CacheSetOAuthLoginCode(key string, loginCode *authModel.OAuthLoginCode, ttl time.Duration)
+	CacheGetOAuthLoginCode(key string) (*authModel.OAuthLoginCode, error)
+	CacheDeleteOAuthLoginCode(key string)
 }
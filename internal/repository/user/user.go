@@ -2,10 +2,12 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/lin-snow/ech0/internal/cache"
 	authModel "github.com/lin-snow/ech0/internal/model/auth"
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
 	model "github.com/lin-snow/ech0/internal/model/user"
 	"github.com/lin-snow/ech0/internal/transaction"
 	"gorm.io/gorm"
@@ -112,9 +114,9 @@ func (userRepository *UserRepository) GetSysAdmin() (model.User, error) {
 		}
 	}
 
-	// 获取系统管理员（首个注册的用户）
+	// 获取系统管理员（站长，即首个注册的用户）
 	user := model.User{}
-	err := userRepository.db().Where("is_admin = ?", true).First(&user).Error
+	err := userRepository.db().Where("role = ?", model.RoleOwner).First(&user).Error
 	if err != nil {
 		return model.User{}, err
 	}
@@ -372,3 +374,157 @@ func (userRepository *UserRepository) CacheGetPasskeySession(key string) (any, e
 func (userRepository *UserRepository) CacheDeletePasskeySession(key string) {
 	userRepository.cache.Delete(key)
 }
+
+// 两步验证
+
+func (userRepository *UserRepository) GetTwoFactorByUserID(
+	userID uint,
+) (*authModel.TwoFactor, error) {
+	var twoFactor authModel.TwoFactor
+	if err := userRepository.db().
+		Where("user_id = ?", userID).
+		First(&twoFactor).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &twoFactor, nil
+}
+
+func (userRepository *UserRepository) SaveTwoFactor(
+	ctx context.Context,
+	twoFactor *authModel.TwoFactor,
+) error {
+	return userRepository.getDB(ctx).Save(twoFactor).Error
+}
+
+func (userRepository *UserRepository) UpdateTwoFactorLastUsedStep(
+	ctx context.Context,
+	id uint,
+	step int64,
+) (bool, error) {
+	// 条件更新保证同一时间步的验证码只能使用一次，即使并发提交也只有一个请求成功
+	result := userRepository.getDB(ctx).
+		Model(&authModel.TwoFactor{}).
+		Where("id = ? AND last_used_step < ?", id, step).
+		Update("last_used_step", step)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (userRepository *UserRepository) DeleteTwoFactor(ctx context.Context, userID uint) error {
+	if err := userRepository.getDB(ctx).
+		Where("user_id = ?", userID).
+		Delete(&authModel.RecoveryCode{}).Error; err != nil {
+		return err
+	}
+	return userRepository.getDB(ctx).
+		Where("user_id = ?", userID).
+		Delete(&authModel.TwoFactor{}).Error
+}
+
+func (userRepository *UserRepository) ReplaceRecoveryCodes(
+	ctx context.Context,
+	userID uint,
+	codeHashes []string,
+) error {
+	if err := userRepository.getDB(ctx).
+		Where("user_id = ?", userID).
+		Delete(&authModel.RecoveryCode{}).Error; err != nil {
+		return err
+	}
+
+	codes := make([]authModel.RecoveryCode, 0, len(codeHashes))
+	for _, hash := range codeHashes {
+		codes = append(codes, authModel.RecoveryCode{
+			UserID:   userID,
+			CodeHash: hash,
+		})
+	}
+	if len(codes) == 0 {
+		return nil
+	}
+	return userRepository.getDB(ctx).Create(&codes).Error
+}
+
+func (userRepository *UserRepository) UseRecoveryCode(
+	ctx context.Context,
+	userID uint,
+	codeHash string,
+	usedAt time.Time,
+) (bool, error) {
+	result := userRepository.getDB(ctx).
+		Model(&authModel.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", usedAt)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (userRepository *UserRepository) CountRecoveryCodes(userID uint) (int64, error) {
+	var count int64
+	if err := userRepository.db().
+		Model(&authModel.RecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (userRepository *UserRepository) CacheSetTwoFactorChallenge(
+	key string,
+	challenge *authModel.TwoFactorChallenge,
+	ttl time.Duration,
+) {
+	_ = userRepository.cache.SetWithTTL(key, challenge, 1, ttl)
+}
+
+func (userRepository *UserRepository) CacheGetTwoFactorChallenge(
+	key string,
+) (*authModel.TwoFactorChallenge, error) {
+	val, err := userRepository.cache.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	challenge, ok := val.(*authModel.TwoFactorChallenge)
+	if !ok {
+		return nil, errors.New(commonModel.TWO_FACTOR_CHALLENGE_INVALID)
+	}
+	return challenge, nil
+}
+
+func (userRepository *UserRepository) CacheDeleteTwoFactorChallenge(key string) {
+	userRepository.cache.Delete(key)
+}
+
+func (userRepository *UserRepository) CacheSetOAuthLoginCode(
+	key string,
+	loginCode *authModel.OAuthLoginCode,
+	ttl time.Duration,
+) {
+	_ = userRepository.cache.SetWithTTL(key, loginCode, 1, ttl)
+}
+
+func (userRepository *UserRepository) CacheGetOAuthLoginCode(
+	key string,
+) (*authModel.OAuthLoginCode, error) {
+	val, err := userRepository.cache.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	loginCode, ok := val.(*authModel.OAuthLoginCode)
+	if !ok {
+		return nil, errors.New(commonModel.OAUTH_LOGIN_CODE_INVALID)
+	}
+	return loginCode, nil
+}
+
+func (userRepository *UserRepository) CacheDeleteOAuthLoginCode(key string) {
+	userRepository.cache.Delete(key)
+}
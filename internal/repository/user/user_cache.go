@@ -9,6 +9,8 @@ const (
 	SysAdminKey       = "sysadmin"
 	PasskeyRegKey     = "passkey:reg"   // passkey:reg:nonce
 	PasskeyLoginKey   = "passkey:login" // passkey:login:nonce
+	TwoFactorLoginKey = "2fa:login"     // 2fa:login:token
+	OAuthLoginKey     = "oauth:login"   // oauth:login:code
 )
 
 func GetUserIDKey(id uint) string {
@@ -34,3 +36,11 @@ func GetPasskeyRegisterSessionKey(nonce string) string {
 func GetPasskeyLoginSessionKey(nonce string) string {
 	return fmt.Sprintf("%s:%s", PasskeyLoginKey, nonce)
 }
+
+func GetTwoFactorChallengeKey(token string) string {
+	return fmt.Sprintf("%s:%s", TwoFactorLoginKey, token)
+}
+
+func GetOAuthLoginCodeKey(code string) string {
+	return fmt.Sprintf("%s:%s", OAuthLoginKey, code)
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	model "github.com/lin-snow/ech0/internal/model/webhook"
 )
@@ -18,4 +19,25 @@ type WebhookRepositoryInterface interface {
 
 	// ListActiveWebhooks 列出所有激活的 webhook
 	ListActiveWebhooks() ([]model.Webhook, error)
+
+	// GetWebhookByID 根据ID获取webhook，不存在时返回 nil
+	GetWebhookByID(id uint) (*model.Webhook, error)
+
+	// UpdateWebhookStatus 更新webhook最近调用状态与触发时间
+	UpdateWebhookStatus(ctx context.Context, id uint, status string, trigger time.Time) error
+
+	// SaveDelivery 保存一条投递记录
+	SaveDelivery(ctx context.Context, delivery *model.WebhookDelivery) error
+
+	// ListDeliveries 分页获取webhook的投递记录，按时间倒序
+	ListDeliveries(webhookID uint, offset, limit int) ([]model.WebhookDelivery, int64, error)
+
+	// GetDeliveryByID 获取webhook的某条投递记录，不存在时返回 nil
+	GetDeliveryByID(webhookID, id uint) (*model.WebhookDelivery, error)
+
+	// SetDeliveriesDeadLetter 将投递记录关联到死信任务
+	SetDeliveriesDeadLetter(ctx context.Context, ids []uint, deadLetterID int64) error
+
+	// DeleteDeliveriesBefore 删除指定时间之前的投递记录
+	DeleteDeliveriesBefore(ctx context.Context, before time.Time) (int64, error)
 }
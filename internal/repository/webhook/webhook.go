@@ -2,6 +2,8 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	model "github.com/lin-snow/ech0/internal/model/webhook"
 	"github.com/lin-snow/ech0/internal/transaction"
@@ -65,3 +67,116 @@ func (webhookRepository *WebhookRepository) ListActiveWebhooks() ([]model.Webhoo
 
 	return webhooks, nil
 }
+
+// GetWebhookByID 根据ID获取webhook，不存在时返回 nil
+func (webhookRepository *WebhookRepository) GetWebhookByID(id uint) (*model.Webhook, error) {
+	var webhook model.Webhook
+	if err := webhookRepository.db().First(&webhook, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &webhook, nil
+}
+
+// UpdateWebhookStatus 更新webhook最近调用状态与触发时间
+func (webhookRepository *WebhookRepository) UpdateWebhookStatus(
+	ctx context.Context,
+	id uint,
+	status string,
+	trigger time.Time,
+) error {
+	return webhookRepository.getDB(ctx).
+		Model(&model.Webhook{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"last_status":  status,
+			"last_trigger": trigger,
+		}).Error
+}
+
+// SaveDelivery 保存一条投递记录
+func (webhookRepository *WebhookRepository) SaveDelivery(
+	ctx context.Context,
+	delivery *model.WebhookDelivery,
+) error {
+	return webhookRepository.getDB(ctx).Create(delivery).Error
+}
+
+// ListDeliveries 分页获取webhook的投递记录，按时间倒序
+func (webhookRepository *WebhookRepository) ListDeliveries(
+	webhookID uint,
+	offset, limit int,
+) ([]model.WebhookDelivery, int64, error) {
+	var (
+		deliveries []model.WebhookDelivery
+		total      int64
+	)
+
+	query := webhookRepository.db().
+		Model(&model.WebhookDelivery{}).
+		Where("webhook_id = ?", webhookID)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// 列表不返回请求体与响应体，详情接口中查看
+	if err := query.
+		Omit("request_headers", "request_body", "response_body").
+		Order("created_at DESC").Order("id DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&deliveries).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return deliveries, total, nil
+}
+
+// GetDeliveryByID 获取webhook的某条投递记录，不存在时返回 nil
+func (webhookRepository *WebhookRepository) GetDeliveryByID(
+	webhookID, id uint,
+) (*model.WebhookDelivery, error) {
+	var delivery model.WebhookDelivery
+	if err := webhookRepository.db().
+		Where("webhook_id = ? AND id = ?", webhookID, id).
+		First(&delivery).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &delivery, nil
+}
+
+// SetDeliveriesDeadLetter 将投递记录关联到死信任务
+func (webhookRepository *WebhookRepository) SetDeliveriesDeadLetter(
+	ctx context.Context,
+	ids []uint,
+	deadLetterID int64,
+) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	return webhookRepository.getDB(ctx).
+		Model(&model.WebhookDelivery{}).
+		Where("id IN ?", ids).
+		Update("dead_letter_id", deadLetterID).Error
+}
+
+// DeleteDeliveriesBefore 删除指定时间之前的投递记录
+func (webhookRepository *WebhookRepository) DeleteDeliveriesBefore(
+	ctx context.Context,
+	before time.Time,
+) (int64, error) {
+	result := webhookRepository.getDB(ctx).
+		Where("created_at < ?", before).
+		Delete(&model.WebhookDelivery{})
+
+	return result.RowsAffected, result.Error
+}
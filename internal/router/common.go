@@ -1,6 +1,10 @@
 package router
 
-import "github.com/lin-snow/ech0/internal/di"
+import (
+	"github.com/lin-snow/ech0/internal/di"
+	settingModel "github.com/lin-snow/ech0/internal/model/setting"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
+)
 
 // setupCommonRoutes 设置普通路由
 func setupCommonRoutes(appRouterGroup *AppRouterGroup, h *di.Handlers) {
@@ -14,13 +18,19 @@ func setupCommonRoutes(appRouterGroup *AppRouterGroup, h *di.Handlers) {
 	appRouterGroup.PublicRouterGroup.GET("/website/title", h.CommonHandler.GetWebsiteTitle())
 
 	// Auth
-	appRouterGroup.AuthRouterGroup.POST("/images/upload", h.CommonHandler.UploadImage())
-	appRouterGroup.AuthRouterGroup.DELETE("/images/delete", h.CommonHandler.DeleteImage())
-	appRouterGroup.AuthRouterGroup.POST("/audios/upload", h.CommonHandler.UploadAudio())
-	appRouterGroup.AuthRouterGroup.DELETE("/audios/delete", h.CommonHandler.DeleteAudio())
-	appRouterGroup.AuthRouterGroup.POST("/models/upload", h.CommonHandler.UploadModel())
-	appRouterGroup.AuthRouterGroup.DELETE("/models/delete", h.CommonHandler.DeleteModel())
-	appRouterGroup.AuthRouterGroup.GET("/backup", h.BackupHandler.Backup())
-	appRouterGroup.AuthRouterGroup.POST("/backup/import", h.BackupHandler.ImportBackup())
-	appRouterGroup.AuthRouterGroup.PUT("/s3/presign", h.CommonHandler.GetS3PresignURL())
+	appRouterGroup.PermissionRouterGroup(userModel.PermissionBackupCreate).
+		GET("/backup", h.BackupHandler.Backup())
+	// 恢复备份会覆盖全部数据，仅站长可以操作
+	appRouterGroup.PermissionRouterGroup(userModel.PermissionBackupRestore).
+		POST("/backup/import", h.BackupHandler.ImportBackup())
+
+	// 媒体上传用于发布 Echo
+	echoWrite := appRouterGroup.ScopedRouterGroup(settingModel.ScopeEchoWrite)
+	echoWrite.POST("/images/upload", h.CommonHandler.UploadImage())
+	echoWrite.DELETE("/images/delete", h.CommonHandler.DeleteImage())
+	echoWrite.POST("/audios/upload", h.CommonHandler.UploadAudio())
+	echoWrite.DELETE("/audios/delete", h.CommonHandler.DeleteAudio())
+	echoWrite.POST("/models/upload", h.CommonHandler.UploadModel())
+	echoWrite.DELETE("/models/delete", h.CommonHandler.DeleteModel())
+	echoWrite.PUT("/s3/presign", h.CommonHandler.GetS3PresignURL())
 }
@@ -1,22 +1,36 @@
 package router
 
-import "github.com/lin-snow/ech0/internal/di"
+import (
+	"github.com/lin-snow/ech0/internal/di"
+	settingModel "github.com/lin-snow/ech0/internal/model/setting"
+)
 
 // setupEchoRoutes 设置Echo路由
 func setupEchoRoutes(appRouterGroup *AppRouterGroup, h *di.Handlers) {
 	// Public
-	appRouterGroup.PublicRouterGroup.PUT("/echo/like/:id", h.EchoHandler.LikeEcho())
+	appRouterGroup.InteractionRouterGroup.PUT("/echo/like/:id", h.EchoHandler.LikeEcho())
 	appRouterGroup.PublicRouterGroup.GET("/tags", h.EchoHandler.GetAllTags())
 
 	// Auth
-	appRouterGroup.AuthRouterGroup.POST("/echo", h.EchoHandler.PostEcho())
-	appRouterGroup.AuthRouterGroup.GET("/echo/page", h.EchoHandler.GetEchosByPage())
-	appRouterGroup.AuthRouterGroup.POST("/echo/page", h.EchoHandler.GetEchosByPage())
-	appRouterGroup.AuthRouterGroup.DELETE("/echo/:id", h.EchoHandler.DeleteEcho())
-	appRouterGroup.AuthRouterGroup.GET("/echo/today", h.EchoHandler.GetTodayEchos())
-	appRouterGroup.AuthRouterGroup.PUT("/echo", h.EchoHandler.UpdateEcho())
-	appRouterGroup.AuthRouterGroup.GET("/echo/:id", h.EchoHandler.GetEchoById())
-	appRouterGroup.AuthRouterGroup.GET("/echo/tag/:tagid", h.EchoHandler.GetEchosByTagId())
-	appRouterGroup.AuthRouterGroup.DELETE("/tag/:id", h.EchoHandler.DeleteTag())
-	// appRouterGroup.AuthRouterGroup.PUT("/tag", h.EchoHandler.UpdateTag())
+	echoRead := appRouterGroup.ScopedRouterGroup(settingModel.ScopeEchoRead)
+	echoRead.GET("/echo/page", h.EchoHandler.GetEchosByPage())
+	echoRead.POST("/echo/page", h.EchoHandler.GetEchosByPage())
+	echoRead.GET("/echo/today", h.EchoHandler.GetTodayEchos())
+	echoRead.GET("/echo/timeline", h.EchoHandler.GetEchoTimeline())
+	echoRead.GET("/echo/drafts", h.EchoHandler.GetUnpublishedEchos())
+	echoRead.GET("/echo/:id", h.EchoHandler.GetEchoById())
+	echoRead.GET("/echo/:id/thread", h.EchoHandler.GetEchoThread())
+	echoRead.GET("/echo/:id/revisions", h.EchoHandler.GetEchoRevisions())
+	echoRead.GET("/echo/tag/:tagid", h.EchoHandler.GetEchosByTagId())
+	echoRead.GET("/echo/tag/:tagid/timeline", h.EchoHandler.GetEchoTimelineByTagId())
+
+	echoWrite := appRouterGroup.ScopedRouterGroup(settingModel.ScopeEchoWrite)
+	echoWrite.POST("/echo", h.EchoHandler.PostEcho())
+	echoWrite.PUT("/echo", h.EchoHandler.UpdateEcho())
+	echoWrite.DELETE("/echo/:id", h.EchoHandler.DeleteEcho())
+	echoWrite.POST("/echo/:id/revisions/:rev/restore", h.EchoHandler.RestoreEchoRevision())
+	echoWrite.POST("/echo/:id/pin", h.EchoHandler.PinEcho())
+	echoWrite.DELETE("/echo/:id/pin", h.EchoHandler.UnpinEcho())
+	echoWrite.DELETE("/tag/:id", h.EchoHandler.DeleteTag())
+	// echoWrite.PUT("/tag", h.EchoHandler.UpdateTag())
 }
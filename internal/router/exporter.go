@@ -0,0 +1,8 @@
+package router
+
+import "github.com/lin-snow/ech0/internal/di"
+
+// setupExporterRoutes 配置导出相关路由
+func setupExporterRoutes(appRouterGroup *AppRouterGroup, h *di.Handlers) {
+	appRouterGroup.AuthRouterGroup.GET("/export", h.ExporterHandler.Export())
+}
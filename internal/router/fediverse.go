@@ -19,6 +19,9 @@ func setupFediverseRoutes(appRouterGroup *AppRouterGroup, h *di.Handlers) {
 	// Outbox (发布消息)
 	appRouterGroup.ResourceGroup.GET("/users/:username/outbox", h.FediverseHandler.GetOutbox)
 
+	// Featured (置顶帖子)
+	appRouterGroup.ResourceGroup.GET("/users/:username/collections/featured", h.FediverseHandler.GetFeatured)
+
 	// Followers list
 	appRouterGroup.ResourceGroup.GET("/users/:username/followers", h.FediverseHandler.GetFollowers)
 
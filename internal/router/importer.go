@@ -0,0 +1,9 @@
+package router
+
+import "github.com/lin-snow/ech0/internal/di"
+
+// setupImporterRoutes 配置导入相关路由
+func setupImporterRoutes(appRouterGroup *AppRouterGroup, h *di.Handlers) {
+	appRouterGroup.AuthRouterGroup.POST("/import", h.ImporterHandler.StartImport())
+	appRouterGroup.AuthRouterGroup.GET("/import/:id", h.ImporterHandler.GetImportJob())
+}
@@ -0,0 +1,15 @@
+package router
+
+import "github.com/lin-snow/ech0/internal/di"
+
+// setupQueueRoutes 配置死信队列相关路由
+func setupQueueRoutes(appRouterGroup *AppRouterGroup, h *di.Handlers) {
+	appRouterGroup.AuthRouterGroup.GET("/dead-letters", h.QueueHandler.ListDeadLetters())
+	appRouterGroup.AuthRouterGroup.GET("/dead-letters/:id", h.QueueHandler.GetDeadLetter())
+	appRouterGroup.AuthRouterGroup.POST("/dead-letters/:id/retry", h.QueueHandler.RetryDeadLetter())
+	appRouterGroup.AuthRouterGroup.POST(
+		"/dead-letters/:id/discard",
+		h.QueueHandler.DiscardDeadLetter(),
+	)
+	appRouterGroup.AuthRouterGroup.DELETE("/dead-letters", h.QueueHandler.PurgeDeadLetters())
+}
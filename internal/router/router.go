@@ -2,15 +2,45 @@ package router
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/lin-snow/ech0/internal/config"
 	"github.com/lin-snow/ech0/internal/di"
 	"github.com/lin-snow/ech0/internal/middleware"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
 )
 
 type AppRouterGroup struct {
-	ResourceGroup     *gin.RouterGroup
-	PublicRouterGroup *gin.RouterGroup
-	AuthRouterGroup   *gin.RouterGroup
-	WSRouterGroup     *gin.RouterGroup
+	ResourceGroup          *gin.RouterGroup
+	PublicRouterGroup      *gin.RouterGroup
+	LoginRouterGroup       *gin.RouterGroup // 登录、注册等公开认证接口，按 IP 严格限流
+	RefreshRouterGroup     *gin.RouterGroup // 刷新访问令牌接口，按 IP 单独限流
+	InteractionRouterGroup *gin.RouterGroup // 点赞等匿名互动接口，按 IP 限流
+	AuthRouterGroup        *gin.RouterGroup // 仅允许登录会话访问
+	WSRouterGroup          *gin.RouterGroup
+	tokenRouterGroup       *gin.RouterGroup // 同时允许登录会话与访问令牌访问
+}
+
+// ScopedRouterGroup 返回要求访问令牌具备指定权限范围的鉴权路由组，登录会话不受限制
+func (appRouterGroup *AppRouterGroup) ScopedRouterGroup(scope string) *gin.RouterGroup {
+	return appRouterGroup.tokenRouterGroup.Group("", middleware.RequireScope(scope))
+}
+
+// PermissionRouterGroup 返回要求当前用户角色拥有指定权限的鉴权路由组，仅允许登录会话访问
+func (appRouterGroup *AppRouterGroup) PermissionRouterGroup(
+	permission userModel.Permission,
+) *gin.RouterGroup {
+	return appRouterGroup.AuthRouterGroup.Group("", middleware.RequirePermission(permission))
+}
+
+// ScopedPermissionRouterGroup 返回同时要求访问令牌权限范围与用户角色权限的鉴权路由组
+func (appRouterGroup *AppRouterGroup) ScopedPermissionRouterGroup(
+	scope string,
+	permission userModel.Permission,
+) *gin.RouterGroup {
+	return appRouterGroup.tokenRouterGroup.Group(
+		"",
+		middleware.RequireScope(scope),
+		middleware.RequirePermission(permission),
+	)
 }
 
 // SetupRouter 配置路由
@@ -45,6 +75,9 @@ func SetupRouter(r *gin.Engine, h *di.Handlers) {
 	// Setup User Routes
 	setupUserRoutes(appRouterGroup, h)
 
+	// Setup Session Routes
+	setupSessionRoutes(appRouterGroup, h)
+
 	// Setup Echo Routes
 	setupEchoRoutes(appRouterGroup, h)
 
@@ -71,19 +104,59 @@ func SetupRouter(r *gin.Engine, h *di.Handlers) {
 
 	// Setup Inbox Routes
 	setupInboxRoutes(appRouterGroup, h)
+
+	// Setup Queue Routes
+	setupQueueRoutes(appRouterGroup, h)
+
+	// Setup Trash Routes
+	setupTrashRoutes(appRouterGroup, h)
+
+	// Setup Importer Routes
+	setupImporterRoutes(appRouterGroup, h)
+
+	// Setup Exporter Routes
+	setupExporterRoutes(appRouterGroup, h)
 }
 
 // setupRouterGroup 初始化路由组
 func setupRouterGroup(r *gin.Engine) *AppRouterGroup {
+	// 所有 API 请求共用同一组按 IP 的令牌桶，登录后的请求再共用一组按用户的令牌桶
+	globalLimit := middleware.RateLimit(config.Config.RateLimit.Global, middleware.ClientIPKey)
+	userLimit := middleware.RateLimit(config.Config.RateLimit.User, middleware.UserKey)
+
 	resource := r.Group("/")
-	public := r.Group("/api")
+	public := r.Group("/api", globalLimit)
+	login := public.Group(
+		"",
+		middleware.RateLimit(config.Config.RateLimit.Auth, middleware.ClientIPKey),
+	)
+	refresh := public.Group(
+		"",
+		middleware.RateLimit(config.Config.RateLimit.Refresh, middleware.ClientIPKey),
+	)
+	interaction := public.Group(
+		"",
+		middleware.RateLimit(config.Config.RateLimit.Interaction, middleware.ClientIPKey),
+	)
 	auth := r.Group("/api")
-	auth.Use(middleware.NoCache(), middleware.JWTAuthMiddleware())
+	auth.Use(
+		globalLimit,
+		middleware.NoCache(),
+		middleware.JWTAuthMiddleware(),
+		middleware.RejectAccessToken(),
+		userLimit,
+	)
+	token := r.Group("/api")
+	token.Use(globalLimit, middleware.NoCache(), middleware.JWTAuthMiddleware(), userLimit)
 	ws := r.Group("/ws")
 	return &AppRouterGroup{
-		ResourceGroup:     resource,
-		PublicRouterGroup: public,
-		AuthRouterGroup:   auth,
-		WSRouterGroup:     ws,
+		ResourceGroup:          resource,
+		PublicRouterGroup:      public,
+		LoginRouterGroup:       login,
+		RefreshRouterGroup:     refresh,
+		InteractionRouterGroup: interaction,
+		AuthRouterGroup:        auth,
+		WSRouterGroup:          ws,
+		tokenRouterGroup:       token,
 	}
 }
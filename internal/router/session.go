@@ -0,0 +1,15 @@
+package router
+
+import "github.com/lin-snow/ech0/internal/di"
+
+// setupSessionRoutes 设置登录会话路由
+func setupSessionRoutes(appRouterGroup *AppRouterGroup, h *di.Handlers) {
+	// Public
+	// 访问令牌过期后每个客户端都会自动刷新，不与登录接口共用严格的令牌桶
+	appRouterGroup.RefreshRouterGroup.POST("/auth/refresh", h.SessionHandler.RefreshToken())
+
+	// Auth
+	appRouterGroup.AuthRouterGroup.POST("/logout", h.SessionHandler.Logout())
+	appRouterGroup.AuthRouterGroup.GET("/sessions", h.SessionHandler.ListSessions())
+	appRouterGroup.AuthRouterGroup.DELETE("/sessions/:id", h.SessionHandler.DeleteSession())
+}
@@ -1,6 +1,10 @@
 package router
 
-import "github.com/lin-snow/ech0/internal/di"
+import (
+	"github.com/lin-snow/ech0/internal/di"
+	settingModel "github.com/lin-snow/ech0/internal/model/setting"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
+)
 
 // setupSettingRoutes 设置设置路由
 func setupSettingRoutes(appRouterGroup *AppRouterGroup, h *di.Handlers) {
@@ -11,49 +15,76 @@ func setupSettingRoutes(appRouterGroup *AppRouterGroup, h *di.Handlers) {
 	appRouterGroup.PublicRouterGroup.GET("/agent/info", h.SettingHandler.GetAgentInfo())
 
 	// Auth
-	appRouterGroup.AuthRouterGroup.PUT("/settings", h.SettingHandler.UpdateSettings())
+	// 访问令牌只能由登录会话管理，避免令牌为自身提权
+	appRouterGroup.AuthRouterGroup.GET("/access-tokens", h.SettingHandler.ListAccessTokens())
+	appRouterGroup.AuthRouterGroup.POST("/access-tokens", h.SettingHandler.CreateAccessToken())
+	appRouterGroup.AuthRouterGroup.DELETE(
+		"/access-tokens/:id",
+		h.SettingHandler.DeleteAccessToken(),
+	)
+
+	// 系统设置仅站长可以修改
+	appRouterGroup.ScopedPermissionRouterGroup(
+		settingModel.ScopeSettingsAdmin,
+		userModel.PermissionSystemSetting,
+	).PUT("/settings", h.SettingHandler.UpdateSettings())
 
-	appRouterGroup.AuthRouterGroup.PUT(
+	// OAuth2 登录配置决定了谁能登录站点，与系统设置同样仅站长可以修改
+	appRouterGroup.ScopedPermissionRouterGroup(
+		settingModel.ScopeSettingsAdmin,
+		userModel.PermissionSystemSetting,
+	).PUT("/oauth2/settings", h.SettingHandler.UpdateOAuth2Settings())
+
+	settingsAdmin := appRouterGroup.ScopedRouterGroup(settingModel.ScopeSettingsAdmin)
+
+	settingsAdmin.PUT(
 		"/comment/settings",
 		h.SettingHandler.UpdateCommentSettings(),
 	)
 
-	appRouterGroup.AuthRouterGroup.GET("/s3/settings", h.SettingHandler.GetS3Settings())
-	appRouterGroup.AuthRouterGroup.PUT("/s3/settings", h.SettingHandler.UpdateS3Settings())
+	settingsAdmin.GET("/s3/settings", h.SettingHandler.GetS3Settings())
+	settingsAdmin.PUT("/s3/settings", h.SettingHandler.UpdateS3Settings())
 
-	appRouterGroup.AuthRouterGroup.GET("/oauth2/settings", h.SettingHandler.GetOAuth2Settings())
-	appRouterGroup.AuthRouterGroup.PUT("/oauth2/settings", h.SettingHandler.UpdateOAuth2Settings())
+	settingsAdmin.GET("/oauth2/settings", h.SettingHandler.GetOAuth2Settings())
 
-	appRouterGroup.AuthRouterGroup.GET("/webhook", h.SettingHandler.GetWebhook())
-	appRouterGroup.AuthRouterGroup.POST("/webhook", h.SettingHandler.CreateWebhook())
-	appRouterGroup.AuthRouterGroup.PUT("/webhook", h.SettingHandler.UpdateWebhook())
-	appRouterGroup.AuthRouterGroup.DELETE("/webhook/:id", h.SettingHandler.DeleteWebhook())
-
-	appRouterGroup.AuthRouterGroup.GET("/access-tokens", h.SettingHandler.ListAccessTokens())
-	appRouterGroup.AuthRouterGroup.POST("/access-tokens", h.SettingHandler.CreateAccessToken())
-	appRouterGroup.AuthRouterGroup.DELETE(
-		"/access-tokens/:id",
-		h.SettingHandler.DeleteAccessToken(),
+	settingsAdmin.GET("/webhook", h.SettingHandler.GetWebhook())
+	settingsAdmin.POST("/webhook", h.SettingHandler.CreateWebhook())
+	settingsAdmin.PUT("/webhook/:id", h.SettingHandler.UpdateWebhook())
+	settingsAdmin.DELETE("/webhook/:id", h.SettingHandler.DeleteWebhook())
+	settingsAdmin.GET(
+		"/webhook/:id/deliveries",
+		h.SettingHandler.GetWebhookDeliveries(),
 	)
-
-	appRouterGroup.AuthRouterGroup.GET(
-		"/fediverse/settings",
-		h.SettingHandler.GetFediverseSettings(),
+	settingsAdmin.GET(
+		"/webhook/:id/deliveries/:deliveryId",
+		h.SettingHandler.GetWebhookDelivery(),
 	)
-	appRouterGroup.AuthRouterGroup.PUT(
-		"/fediverse/settings",
-		h.SettingHandler.UpdateFediverseSettings(),
+	settingsAdmin.POST(
+		"/webhook/:id/deliveries/:deliveryId/redeliver",
+		h.SettingHandler.RedeliverWebhookDelivery(),
 	)
 
-	appRouterGroup.AuthRouterGroup.GET(
+	settingsAdmin.GET(
 		"/backup/schedule",
 		h.SettingHandler.GetBackupScheduleSetting(),
 	)
-	appRouterGroup.AuthRouterGroup.POST(
+	settingsAdmin.POST(
 		"/backup/schedule",
 		h.SettingHandler.UpdateBackupScheduleSetting(),
 	)
 
-	appRouterGroup.AuthRouterGroup.GET("/agent/settings", h.SettingHandler.GetAgentSettings())
-	appRouterGroup.AuthRouterGroup.PUT("/agent/settings", h.SettingHandler.UpdateAgentSettings())
+	settingsAdmin.GET("/agent/settings", h.SettingHandler.GetAgentSettings())
+	settingsAdmin.PUT("/agent/settings", h.SettingHandler.UpdateAgentSettings())
+
+	fediverseRead := appRouterGroup.ScopedRouterGroup(settingModel.ScopeFediverseRead)
+	fediverseRead.GET(
+		"/fediverse/settings",
+		h.SettingHandler.GetFediverseSettings(),
+	)
+
+	fediverseWrite := appRouterGroup.ScopedRouterGroup(settingModel.ScopeFediverseWrite)
+	fediverseWrite.PUT(
+		"/fediverse/settings",
+		h.SettingHandler.UpdateFediverseSettings(),
+	)
 }
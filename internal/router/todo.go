@@ -2,6 +2,7 @@ package router
 
 import (
 	"github.com/lin-snow/ech0/internal/di"
+	settingModel "github.com/lin-snow/ech0/internal/model/setting"
 )
 
 // setupTodoRoutes 设置待办事项路由
@@ -9,8 +10,11 @@ func setupTodoRoutes(appRouterGroup *AppRouterGroup, h *di.Handlers) {
 	// Public
 
 	// Auth
-	appRouterGroup.AuthRouterGroup.GET("/todo", h.TodoHandler.GetTodoList())
-	appRouterGroup.AuthRouterGroup.POST("/todo", h.TodoHandler.AddTodo())
-	appRouterGroup.AuthRouterGroup.PUT("/todo/:id", h.TodoHandler.UpdateTodo())
-	appRouterGroup.AuthRouterGroup.DELETE("/todo/:id", h.TodoHandler.DeleteTodo())
+	todoRead := appRouterGroup.ScopedRouterGroup(settingModel.ScopeTodoRead)
+	todoRead.GET("/todo", h.TodoHandler.GetTodoList())
+
+	todoWrite := appRouterGroup.ScopedRouterGroup(settingModel.ScopeTodoWrite)
+	todoWrite.POST("/todo", h.TodoHandler.AddTodo())
+	todoWrite.PUT("/todo/:id", h.TodoHandler.UpdateTodo())
+	todoWrite.DELETE("/todo/:id", h.TodoHandler.DeleteTodo())
 }
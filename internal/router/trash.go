@@ -0,0 +1,14 @@
+package router
+
+import "github.com/lin-snow/ech0/internal/di"
+
+// setupTrashRoutes 配置回收站相关路由
+func setupTrashRoutes(appRouterGroup *AppRouterGroup, h *di.Handlers) {
+	appRouterGroup.AuthRouterGroup.GET("/trash", h.TrashHandler.ListTrash())
+	appRouterGroup.AuthRouterGroup.POST(
+		"/trash/:type/:id/restore",
+		h.TrashHandler.RestoreTrashItem(),
+	)
+	appRouterGroup.AuthRouterGroup.DELETE("/trash/:type/:id", h.TrashHandler.PurgeTrashItem())
+	appRouterGroup.AuthRouterGroup.DELETE("/trash", h.TrashHandler.EmptyTrash())
+}
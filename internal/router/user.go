@@ -1,6 +1,9 @@
 package router
 
-import "github.com/lin-snow/ech0/internal/di"
+import (
+	"github.com/lin-snow/ech0/internal/di"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
+)
 
 // setupUserRoutes 设置用户路由
 func setupUserRoutes(appRouterGroup *AppRouterGroup, h *di.Handlers) {
@@ -15,11 +18,14 @@ func setupUserRoutes(appRouterGroup *AppRouterGroup, h *di.Handlers) {
 	appRouterGroup.ResourceGroup.GET("/oauth/custom/callback", h.UserHandler.CustomOAuthCallback())
 
 	// Public
-	appRouterGroup.PublicRouterGroup.POST("/login", h.UserHandler.Login())
-	appRouterGroup.PublicRouterGroup.POST("/register", h.UserHandler.Register())
+	appRouterGroup.LoginRouterGroup.POST("/login", h.UserHandler.Login())
+	appRouterGroup.LoginRouterGroup.POST("/login/2fa", h.UserHandler.TwoFactorLogin())
+	appRouterGroup.LoginRouterGroup.POST("/login/2fa/setup", h.UserHandler.TwoFactorLoginSetup())
+	appRouterGroup.LoginRouterGroup.POST("/oauth/exchange", h.UserHandler.OAuthExchange())
+	appRouterGroup.LoginRouterGroup.POST("/register", h.UserHandler.Register())
 	appRouterGroup.PublicRouterGroup.GET("/allusers", h.UserHandler.GetAllUsers())
-	appRouterGroup.PublicRouterGroup.POST("/passkey/login/begin", h.UserHandler.PasskeyLoginBegin())
-	appRouterGroup.PublicRouterGroup.POST(
+	appRouterGroup.LoginRouterGroup.POST("/passkey/login/begin", h.UserHandler.PasskeyLoginBegin())
+	appRouterGroup.LoginRouterGroup.POST(
 		"/passkey/login/finish",
 		h.UserHandler.PasskeyLoginFinish(),
 	)
@@ -27,8 +33,7 @@ func setupUserRoutes(appRouterGroup *AppRouterGroup, h *di.Handlers) {
 	// Auth
 	appRouterGroup.AuthRouterGroup.GET("/user", h.UserHandler.GetUserInfo())
 	appRouterGroup.AuthRouterGroup.PUT("/user", h.UserHandler.UpdateUser())
-	appRouterGroup.AuthRouterGroup.DELETE("/user/:id", h.UserHandler.DeleteUser())
-	appRouterGroup.AuthRouterGroup.PUT("/user/admin/:id", h.UserHandler.UpdateUserAdmin())
+	appRouterGroup.AuthRouterGroup.GET("/roles", h.UserHandler.GetRoles())
 	appRouterGroup.AuthRouterGroup.POST("/oauth/github/bind", h.UserHandler.BindGitHub())
 	appRouterGroup.AuthRouterGroup.POST("/oauth/google/bind", h.UserHandler.BindGoogle())
 	appRouterGroup.AuthRouterGroup.POST("/oauth/qq/bind", h.UserHandler.BindQQ())
@@ -45,4 +50,18 @@ func setupUserRoutes(appRouterGroup *AppRouterGroup, h *di.Handlers) {
 	appRouterGroup.AuthRouterGroup.GET("/passkeys", h.UserHandler.ListPasskeys())
 	appRouterGroup.AuthRouterGroup.DELETE("/passkeys/:id", h.UserHandler.DeletePasskey())
 	appRouterGroup.AuthRouterGroup.PUT("/passkeys/:id", h.UserHandler.UpdatePasskeyDeviceName())
+	appRouterGroup.AuthRouterGroup.GET("/2fa", h.UserHandler.GetTwoFactorStatus())
+	appRouterGroup.AuthRouterGroup.POST("/2fa/setup", h.UserHandler.SetupTwoFactor())
+	appRouterGroup.AuthRouterGroup.POST("/2fa/enable", h.UserHandler.EnableTwoFactor())
+	appRouterGroup.AuthRouterGroup.POST("/2fa/disable", h.UserHandler.DisableTwoFactor())
+	appRouterGroup.AuthRouterGroup.POST(
+		"/2fa/recovery-codes",
+		h.UserHandler.RegenerateRecoveryCodes(),
+	)
+
+	userManage := appRouterGroup.PermissionRouterGroup(userModel.PermissionUserManage)
+	userManage.DELETE("/user/:id", h.UserHandler.DeleteUser())
+	userManage.PUT("/user/admin/:id", h.UserHandler.UpdateUserAdmin())
+	userManage.PUT("/user/:id/role", h.UserHandler.UpdateUserRole())
+	userManage.PUT("/user/:id/2fa", h.UserHandler.UpdateTwoFactorRequired())
 }
@@ -52,6 +52,14 @@ func (s *Server) Init() {
 	// Gin Engine
 	s.GinEngine = gin.New()
 
+	// Trusted Proxies，仅信任配置中的反向代理转发的客户端 IP
+	if err := s.GinEngine.SetTrustedProxies(config.Config.Server.TrustedProxies); err != nil {
+		errUtil.HandlePanicError(&commonModel.ServerError{
+			Msg: commonModel.SET_TRUSTED_PROXIES_PANIC,
+			Err: err,
+		})
+	}
+
 	// Database
 	database.InitDatabase()
 
@@ -9,9 +9,11 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/lin-snow/ech0/internal/authz"
 	"github.com/lin-snow/ech0/internal/backup"
 	"github.com/lin-snow/ech0/internal/event"
 	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
 	commonService "github.com/lin-snow/ech0/internal/service/common"
 	logUtil "github.com/lin-snow/ech0/internal/util/log"
 	"go.uber.org/zap"
@@ -39,8 +41,8 @@ func (backupService *BackupService) Backup(userid uint) error {
 		return err
 	}
 
-	if !user.IsAdmin {
-		return errors.New(commonModel.NO_PERMISSION_DENIED)
+	if err := authz.Authorize(user, userModel.PermissionBackupCreate); err != nil {
+		return err
 	}
 
 	// 执行备份
@@ -73,8 +75,8 @@ func (backupService *BackupService) ExportBackup(ctx *gin.Context, userid uint)
 		return err
 	}
 
-	if !user.IsAdmin {
-		return errors.New(commonModel.NO_PERMISSION_DENIED)
+	if err := authz.Authorize(user, userModel.PermissionBackupCreate); err != nil {
+		return err
 	}
 
 	// 导出备份
@@ -138,8 +140,8 @@ func (backupService *BackupService) ImportBackup(
 		return err
 	}
 
-	if !user.IsAdmin {
-		return errors.New(commonModel.NO_PERMISSION_DENIED)
+	if err := authz.Authorize(user, userModel.PermissionBackupRestore); err != nil {
+		return err
 	}
 
 	// 保存上传的文件到临时位置, (./temp/snapshot_时间戳.zip)
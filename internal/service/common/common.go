@@ -1,11 +1,13 @@
 package service
 
 import (
+	"bytes"
 	"context"
 	"crypto/rand"
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
 	"mime"
 	"mime/multipart"
 	"net/http"
@@ -17,6 +19,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/feeds"
+	"github.com/lin-snow/ech0/internal/authz"
 	"github.com/lin-snow/ech0/internal/config"
 	"github.com/lin-snow/ech0/internal/event"
 	commonModel "github.com/lin-snow/ech0/internal/model/common"
@@ -78,8 +81,8 @@ func (commonService *CommonService) UploadImage(
 	if err != nil {
 		return commonModel.ImageDto{}, err
 	}
-	if !user.IsAdmin {
-		return commonModel.ImageDto{}, errors.New(commonModel.NO_PERMISSION_DENIED)
+	if err := authz.Authorize(user, userModel.PermissionMediaUpload); err != nil {
+		return commonModel.ImageDto{}, err
 	}
 
 	// 检查文件类型是否合法
@@ -141,8 +144,8 @@ func (commonService *CommonService) DeleteImage(userid uint, url, source, object
 	if err != nil {
 		return err
 	}
-	if !user.IsAdmin {
-		return errors.New(commonModel.NO_PERMISSION_DENIED)
+	if err := authz.Authorize(user, userModel.PermissionMediaUpload); err != nil {
+		return err
 	}
 
 	// 检查图片是否存在
@@ -236,6 +239,83 @@ func (commonService *CommonService) DirectDeleteImage(url, source, object_key st
 	return nil
 }
 
+// SaveImage 将 Reader 中的图片保存到本地或 S3 存储
+func (commonService *CommonService) SaveImage(
+	userId uint,
+	filename string,
+	reader io.Reader,
+	storage commonModel.FileStorageType,
+) (echoModel.Image, error) {
+	// 读取图片内容，超出大小限制则拒绝
+	data, err := io.ReadAll(io.LimitReader(reader, int64(config.Config.Upload.ImageMaxSize)+1))
+	if err != nil {
+		return echoModel.Image{}, err
+	}
+	if len(data) == 0 {
+		return echoModel.Image{}, errors.New(commonModel.IMAGE_NOT_FOUND)
+	}
+	if len(data) > config.Config.Upload.ImageMaxSize {
+		return echoModel.Image{}, errors.New(commonModel.FILE_SIZE_EXCEED_LIMIT)
+	}
+
+	// 优先根据扩展名判断类型，无扩展名时根据内容判断
+	ext := strings.ToLower(filepath.Ext(filename))
+	contentType := mime.TypeByExtension(ext)
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+		if exts, _ := mime.ExtensionsByType(contentType); len(exts) > 0 {
+			ext = exts[0]
+		}
+	}
+	contentType, _, _ = strings.Cut(contentType, ";")
+	if !storageUtil.IsAllowedType(contentType, config.Config.Upload.AllowedTypes) {
+		return echoModel.Image{}, errors.New(commonModel.FILE_TYPE_NOT_ALLOWED)
+	}
+
+	// 获取图片尺寸，失败时不影响保存
+	width, height, _ := imgUtil.GetImageSizeFromReader(bytes.NewReader(data))
+	image := echoModel.Image{
+		Width:  width,
+		Height: height,
+	}
+
+	switch storage {
+	case commonModel.LOCAL_FILE:
+		imageURL, err := storageUtil.SaveImageToLocal(bytes.NewReader(data), userId, ext)
+		if err != nil {
+			return echoModel.Image{}, err
+		}
+		image.ImageURL = imageURL
+		image.ImageSource = echoModel.ImageSourceLocal
+	case commonModel.S3_FILE:
+		client, s3setting, err := commonService.GetS3Client()
+		if err != nil {
+			return echoModel.Image{}, err
+		}
+		if !s3setting.Enable {
+			return echoModel.Image{}, errors.New(commonModel.S3_NOT_ENABLED)
+		}
+		objectKey, err := buildObjectKey(userId, "image"+ext, s3setting.PathPrefix)
+		if err != nil {
+			return echoModel.Image{}, err
+		}
+		if err := client.Upload(context.Background(), objectKey, bytes.NewReader(data), contentType); err != nil {
+			return echoModel.Image{}, err
+		}
+		imageURL, err := commonService.GetS3ObjectURL(s3setting, objectKey)
+		if err != nil {
+			return echoModel.Image{}, err
+		}
+		image.ImageURL = imageURL
+		image.ImageSource = echoModel.ImageSourceS3
+		image.ObjectKey = objectKey
+	default:
+		return echoModel.Image{}, errors.New(commonModel.IMPORT_STORAGE_INVALID)
+	}
+
+	return image, nil
+}
+
 func (commonService *CommonService) GetSysAdmin() (userModel.User, error) {
 	return commonService.commonRepository.GetSysAdmin()
 }
@@ -258,12 +338,15 @@ func (commonService *CommonService) GetStatus() (commonModel.Status, error) {
 			UserID:   user.ID,
 			UserName: user.Username,
 			IsAdmin:  user.IsAdmin,
+			Role:     string(user.Role),
 		})
 	}
 
 	status := commonModel.Status{}
 
-	echos, err := commonService.commonRepository.GetAllEchos(true)
+	echos, err := commonService.commonRepository.GetAllEchos(
+		echoModel.ListedVisibilities(echoModel.AudienceAdmin),
+	)
 	if err != nil {
 		return status, err
 	}
@@ -288,8 +371,12 @@ func (commonService *CommonService) GetHeatMap() ([]commonModel.Heatmap, error)
 	startDate := oneMonthAgo.Format("2006-01-02") // 一个月前的日期
 	endDate := today.Format("2006-01-02")         // 当前日期
 
-	// 数据库查询 （只返回某天count >= 1的item）
-	heatmapData, err := commonService.commonRepository.GetHeatMap(startDate, endDate)
+	// 数据库查询 （只返回某天count >= 1的item），热力图公开展示，只统计公开的Echo
+	heatmapData, err := commonService.commonRepository.GetHeatMap(
+		startDate,
+		endDate,
+		echoModel.ListedVisibilities(echoModel.AudienceGuest),
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -323,8 +410,10 @@ func (commonService *CommonService) GetHeatMap() ([]commonModel.Heatmap, error)
 }
 
 func (commonService *CommonService) GenerateRSS(ctx *gin.Context) (string, error) {
-	// 获取所有Echo
-	echos, err := commonService.commonRepository.GetAllEchos(false)
+	// 获取所有公开的Echo
+	echos, err := commonService.commonRepository.GetAllEchos(
+		echoModel.ListedVisibilities(echoModel.AudienceGuest),
+	)
 	if err != nil {
 		return "", err
 	}
@@ -350,6 +439,12 @@ func (commonService *CommonService) GenerateRSS(ctx *gin.Context) (string, error
 		Updated: time.Now(),
 	}
 
+	// 引用的 Echo 仅在公开列表中查找
+	echoByID := make(map[uint]*echoModel.Echo, len(echos))
+	for i := range echos {
+		echoByID[echos[i].ID] = &echos[i]
+	}
+
 	for _, msg := range echos {
 		renderedContent := mdUtil.MdToHTML([]byte(msg.Content))
 
@@ -387,6 +482,20 @@ func (commonService *CommonService) GenerateRSS(ctx *gin.Context) (string, error
 			}
 		}
 
+		// 添加引用的 Echo 到正文后
+		if quoted, ok := echoByID[msg.QuoteOfID]; ok && msg.QuoteOfID != 0 {
+			renderedContent = fmt.Appendf(
+				renderedContent,
+				"<blockquote class=\"quote\"><p>%s:</p>%s<a href=\"%s://%s/echo/%d\">#%d</a></blockquote>",
+				html.EscapeString(quoted.Username),
+				mdUtil.MdToHTML([]byte(quoted.Content)),
+				schema,
+				host,
+				quoted.ID,
+				quoted.ID,
+			)
+		}
+
 		item := &feeds.Item{
 			Title:       title,
 			Link:        &feeds.Link{Href: fmt.Sprintf("%s://%s/echo/%d", schema, host, msg.ID)},
@@ -415,8 +524,8 @@ func (commonService *CommonService) UploadMusic(
 	if err != nil {
 		return "", err
 	}
-	if !user.IsAdmin {
-		return "", errors.New(commonModel.NO_PERMISSION_DENIED)
+	if err := authz.Authorize(user, userModel.PermissionMediaUpload); err != nil {
+		return "", err
 	}
 
 	// 检查文件类型是否合法
@@ -451,8 +560,8 @@ func (commonService *CommonService) DeleteMusic(userid uint) error {
 	if err != nil {
 		return err
 	}
-	if !user.IsAdmin {
-		return errors.New(commonModel.NO_PERMISSION_DENIED)
+	if err := authz.Authorize(user, userModel.PermissionMediaUpload); err != nil {
+		return err
 	}
 
 	// 支持的音频格式
@@ -473,8 +582,8 @@ func (commonService *CommonService) UploadModel(userId uint, file *multipart.Fil
 	if err != nil {
 		return "", err
 	}
-	if !user.IsAdmin {
-		return "", errors.New(commonModel.NO_PERMISSION_DENIED)
+	if err := authz.Authorize(user, userModel.PermissionMediaUpload); err != nil {
+		return "", err
 	}
 
 	// 检查文件扩展名是否为支持的3D模型格式
@@ -523,8 +632,8 @@ func (commonService *CommonService) DeleteModel(userid uint, url string) error {
 	if err != nil {
 		return err
 	}
-	if !user.IsAdmin {
-		return errors.New(commonModel.NO_PERMISSION_DENIED)
+	if err := authz.Authorize(user, userModel.PermissionMediaUpload); err != nil {
+		return err
 	}
 
 	if url == "" {
@@ -610,8 +719,8 @@ func (commonService *CommonService) GetS3PresignURL(
 	if err != nil {
 		return result, err
 	}
-	if !user.IsAdmin {
-		return result, errors.New(commonModel.NO_PERMISSION_DENIED)
+	if err := authz.Authorize(user, userModel.PermissionMediaUpload); err != nil {
+		return result, err
 	}
 
 	// 参数校验
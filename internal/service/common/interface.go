@@ -1,6 +1,7 @@
 package service
 
 import (
+	"io"
 	"mime/multipart"
 
 	"github.com/gin-gonic/gin"
@@ -24,6 +25,14 @@ type CommonServiceInterface interface {
 	// DirectDeleteImage 直接根据URL和来源删除图片
 	DirectDeleteImage(url, source, object_key string) error
 
+	// SaveImage 将 Reader 中的图片保存到本地或 S3 存储，调用方负责权限检查
+	SaveImage(
+		userId uint,
+		filename string,
+		reader io.Reader,
+		storage model.FileStorageType,
+	) (echoModel.Image, error)
+
 	// GetSysAdmin 获取系统管理员
 	GetSysAdmin() (userModel.User, error)
 
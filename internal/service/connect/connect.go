@@ -8,9 +8,12 @@ import (
 	"sync"
 	"time"
 
+	"github.com/lin-snow/ech0/internal/authz"
 	commonModel "github.com/lin-snow/ech0/internal/model/common"
 	model "github.com/lin-snow/ech0/internal/model/connect"
+	echoModel "github.com/lin-snow/ech0/internal/model/echo"
 	settingModel "github.com/lin-snow/ech0/internal/model/setting"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
 	repository "github.com/lin-snow/ech0/internal/repository/connect"
 	echoRepository "github.com/lin-snow/ech0/internal/repository/echo"
 	commonService "github.com/lin-snow/ech0/internal/service/common"
@@ -53,8 +56,8 @@ func (connectService *ConnectService) AddConnect(userid uint, connected model.Co
 			return err
 		}
 
-		if !user.IsAdmin {
-			return errors.New(commonModel.NO_PERMISSION_DENIED)
+		if err := authz.Authorize(user, userModel.PermissionConnectManage); err != nil {
+			return err
 		}
 
 		// 检查连接地址是否为空
@@ -95,8 +98,8 @@ func (connectService *ConnectService) DeleteConnect(userid, id uint) error {
 			return err
 		}
 
-		if !user.IsAdmin {
-			return errors.New(commonModel.NO_PERMISSION_DENIED)
+		if err := authz.Authorize(user, userModel.PermissionConnectManage); err != nil {
+			return err
 		}
 
 		// 删除连接地址
@@ -125,7 +128,9 @@ func (connectService *ConnectService) GetConnect() (model.Connect, error) {
 	}
 
 	// 统计当天发布的数量
-	todayEchos := connectService.echoRepository.GetTodayEchos(true)
+	todayEchos := connectService.echoRepository.GetTodayEchos(
+		echoModel.ListedVisibilities(echoModel.AudienceAdmin),
+	)
 
 	// 设置 Connect 信息
 	connect.ServerName = setting.ServerName
@@ -4,11 +4,13 @@ import (
 	"context"
 	"errors"
 	"strings"
+	"time"
 
+	"github.com/lin-snow/ech0/internal/authz"
 	"github.com/lin-snow/ech0/internal/event"
-	authModel "github.com/lin-snow/ech0/internal/model/auth"
 	commonModel "github.com/lin-snow/ech0/internal/model/common"
 	model "github.com/lin-snow/ech0/internal/model/echo"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
 	commonRepository "github.com/lin-snow/ech0/internal/repository/common"
 	repository "github.com/lin-snow/ech0/internal/repository/echo"
 	keyvalueRepository "github.com/lin-snow/ech0/internal/repository/keyvalue"
@@ -59,8 +61,8 @@ func (echoService *EchoService) PostEcho(userid uint, newEcho *model.Echo) error
 		return err
 	}
 
-	if !user.IsAdmin {
-		return errors.New(commonModel.NO_PERMISSION_DENIED)
+	if err := authz.Authorize(user, userModel.PermissionEchoWrite); err != nil {
+		return err
 	}
 
 	// 检查图片布局
@@ -103,6 +105,27 @@ func (echoService *EchoService) PostEcho(userid uint, newEcho *model.Echo) error
 		return errors.New(commonModel.ECHO_CAN_NOT_BE_EMPTY)
 	}
 
+	// 检查发布状态，草稿与定时发布的 Echo 暂不推送
+	if err := normalizeEchoStatus(newEcho, time.Now()); err != nil {
+		return err
+	}
+
+	// 检查可见性
+	if err := normalizeEchoVisibility(newEcho); err != nil {
+		return err
+	}
+
+	// 置顶只能通过置顶接口设置
+	newEcho.PinnedAt = nil
+
+	// 检查回复与引用的 Echo 是否存在且当前用户可见
+	if err := echoService.checkEchoReference(user.ID, newEcho.ReplyToID, commonModel.REPLY_TARGET_NOT_FOUND); err != nil {
+		return err
+	}
+	if err := echoService.checkEchoReference(user.ID, newEcho.QuoteOfID, commonModel.QUOTE_TARGET_NOT_FOUND); err != nil {
+		return err
+	}
+
 	if err := echoService.txManager.Run(func(ctx context.Context) error {
 		// 处理标签
 		if err := echoService.ProcessEchoTags(ctx, newEcho); err != nil {
@@ -129,28 +152,10 @@ func (echoService *EchoService) PostEcho(userid uint, newEcho *model.Echo) error
 	}
 
 	// 事务提交成功后再推送，确保已拿到持久化 ID
-	savedEcho, fetchErr := echoService.echoRepository.GetEchosById(newEcho.ID)
-	if fetchErr != nil {
-		return fetchErr
-	}
-	if savedEcho != nil {
-		// 推送事件(Webhook, Fediverse, Agent等)
-		if pubErr := echoService.eventBus.Publish(
-			context.Background(),
-			event.NewEvent(
-				event.EventTypeEchoCreated,
-				event.EventPayload{
-					event.EventPayloadEcho: *savedEcho,
-					event.EventPayloadUser: user,
-				},
-			),
-		); pubErr != nil {
-			// 推送失败不影响发布
-			logUtil.GetLogger().Error(pubErr.Error())
-		}
+	if !newEcho.IsPublished() {
+		return nil
 	}
-
-	return nil
+	return echoService.publishEchoCreatedEvent(newEcho.ID, user)
 }
 
 // GetEchosByPage 获取Echo列表，支持分页
@@ -166,28 +171,36 @@ func (echoService *EchoService) GetEchosByPage(
 		pageQueryDto.PageSize = 10
 	}
 
-	// 管理员登陆则支持查看隐私数据，否则不允许
-	showPrivate := false
-	if userid == authModel.NO_USER_LOGINED {
-		showPrivate = false
-	} else {
-		user, err := echoService.commonService.CommonGetUserByUserId(userid)
+	// 管理员可以查看全部Echo，登录用户可以查看仅登录用户可见的Echo
+	audience, err := echoService.viewerAudience(userid)
+	if err != nil {
+		return commonModel.PageQueryResult[[]model.Echo]{}, err
+	}
+
+	visibilities := model.ListedVisibilities(audience)
+
+	var (
+		echosByPage []model.Echo
+		total       int64
+	)
+	if strings.TrimSpace(pageQueryDto.Search) == "" {
+		// 没有检索条件时置顶的 Echo 排在最前面
+		echosByPage, total, err = echoService.pageWithPinnedEchos(
+			pageQueryDto.Page,
+			pageQueryDto.PageSize,
+			visibilities,
+		)
 		if err != nil {
 			return commonModel.PageQueryResult[[]model.Echo]{}, err
 		}
-		if user.IsAdmin {
-			showPrivate = true
-		} else {
-			showPrivate = false
-		}
+	} else {
+		echosByPage, total = echoService.echoRepository.GetEchosByPage(
+			pageQueryDto.Page,
+			pageQueryDto.PageSize,
+			pageQueryDto.Search,
+			visibilities,
+		)
 	}
-
-	echosByPage, total := echoService.echoRepository.GetEchosByPage(
-		pageQueryDto.Page,
-		pageQueryDto.PageSize,
-		pageQueryDto.Search,
-		showPrivate,
-	)
 	result := commonModel.PageQueryResult[[]model.Echo]{
 		Items: echosByPage,
 		Total: total,
@@ -208,12 +221,12 @@ func (echoService *EchoService) DeleteEchoById(userid, id uint) error {
 	if err != nil {
 		return err
 	}
-	if !user.IsAdmin {
-		return errors.New(commonModel.NO_PERMISSION_DENIED)
+	if err := authz.Authorize(user, userModel.PermissionEchoWrite); err != nil {
+		return err
 	}
 
+	var deleted model.Echo
 	if err := echoService.txManager.Run(func(ctx context.Context) error {
-		// 检查该Echo是否存在图片
 		echo, err := echoService.echoRepository.GetEchosById(id)
 		if err != nil {
 			return err
@@ -221,29 +234,35 @@ func (echoService *EchoService) DeleteEchoById(userid, id uint) error {
 		if echo == nil {
 			return errors.New(commonModel.ECHO_NOT_FOUND)
 		}
-
-		// 删除Echo中的图片
-		if len(echo.Images) > 0 {
-			for _, img := range echo.Images {
-				if err := echoService.commonService.DirectDeleteImage(img.ImageURL, img.ImageSource, img.ObjectKey); err != nil {
-					return err
-				}
-			}
+		if err := authz.AuthorizeOwner(
+			user,
+			echo.UserID,
+			userModel.PermissionEchoManage,
+			userModel.PermissionEchoWrite,
+		); err != nil {
+			return err
 		}
+		deleted = *echo
 
-		// 删除Echo
+		// 移入回收站，图片文件在彻底删除时才清理
 		return echoService.echoRepository.DeleteEchoById(ctx, id)
 	}); err != nil {
 		return err
 	}
 
-	// 删除成功后推送事件
+	// 未发布的 Echo 从未推送过，删除时也无需推送
+	if !deleted.IsPublished() {
+		return nil
+	}
+
+	// 移入回收站后推送删除事件，携带删除前的 Echo，订阅方据此按可见性与标签过滤；
+	// 联邦宇宙在此时收到 Delete，恢复时重新推送 Create
 	if pubErr := echoService.eventBus.Publish(
 		context.Background(),
 		event.NewEvent(
 			event.EventTypeEchoDeleted,
 			event.EventPayload{
-				event.EventPayloadEcho: model.Echo{ID: id},
+				event.EventPayloadEcho: deleted,
 				event.EventPayloadUser: user,
 			},
 		),
@@ -257,24 +276,14 @@ func (echoService *EchoService) DeleteEchoById(userid, id uint) error {
 
 // GetTodayEchos 获取今天的Echo列表
 func (echoService *EchoService) GetTodayEchos(userid uint) ([]model.Echo, error) {
-	// 管理员登陆则支持查看隐私数据，否则不允许
-	showPrivate := false
-	if userid == authModel.NO_USER_LOGINED {
-		showPrivate = false
-	} else {
-		user, err := echoService.commonService.CommonGetUserByUserId(userid)
-		if err != nil {
-			return nil, err
-		}
-		if user.IsAdmin {
-			showPrivate = true
-		} else {
-			showPrivate = false
-		}
+	// 管理员可以查看全部Echo，登录用户可以查看仅登录用户可见的Echo
+	audience, err := echoService.viewerAudience(userid)
+	if err != nil {
+		return nil, err
 	}
 
 	// 获取当日发布的Echos
-	todayEchos := echoService.echoRepository.GetTodayEchos(showPrivate)
+	todayEchos := echoService.echoRepository.GetTodayEchos(model.ListedVisibilities(audience))
 
 	// 处理todayEchos中的图片URL (暂不处理，防止拖慢列表加载速度)
 	// for i := range todayEchos {
@@ -290,10 +299,15 @@ func (echoService *EchoService) UpdateEcho(userid uint, echo *model.Echo) error
 	if err != nil {
 		return err
 	}
-	if !user.IsAdmin {
-		return errors.New(commonModel.NO_PERMISSION_DENIED)
+	if err := authz.Authorize(user, userModel.PermissionEchoWrite); err != nil {
+		return err
 	}
 
+	return echoService.saveEchoUpdate(user, echo, 0)
+}
+
+// saveEchoUpdate 校验并保存 Echo 的更新，同时写入历史版本并推送更新事件，restoredFrom 为恢复自的版本号
+func (echoService *EchoService) saveEchoUpdate(user userModel.User, echo *model.Echo, restoredFrom int) error {
 	// 检查图片布局
 	layout := strings.TrimSpace(echo.Layout)
 	if layout == "" || (layout != model.LayoutWaterfall &&
@@ -336,6 +350,49 @@ func (echoService *EchoService) UpdateEcho(userid uint, echo *model.Echo) error
 		return errors.New(commonModel.ECHO_CAN_NOT_BE_EMPTY)
 	}
 
+	// 回复与引用关系在发布后不可修改，沿用原有的值以便推送更新时保持一致
+	oldEcho, err := echoService.echoRepository.GetEchosById(echo.ID)
+	if err != nil {
+		return err
+	}
+	if oldEcho == nil {
+		return errors.New(commonModel.ECHO_NOT_FOUND)
+	}
+
+	// 编辑只能修改自己的 Echo，作者不随更新改变
+	if err := authz.AuthorizeOwner(
+		user,
+		oldEcho.UserID,
+		userModel.PermissionEchoManage,
+		userModel.PermissionEchoWrite,
+	); err != nil {
+		return err
+	}
+	echo.UserID = oldEcho.UserID
+	echo.Username = oldEcho.Username
+	echo.ReplyToID = oldEcho.ReplyToID
+	echo.QuoteOfID = oldEcho.QuoteOfID
+
+	// 已发布的 Echo 不能改回草稿，未指定状态时沿用原有状态
+	wasPublished := oldEcho.IsPublished()
+	if wasPublished || echo.Status == "" {
+		echo.Status = oldEcho.Status
+		echo.PublishAt = oldEcho.PublishAt
+	}
+	now := time.Now()
+	if err := normalizeEchoStatus(echo, now); err != nil {
+		return err
+	}
+	publishNow := !wasPublished && echo.IsPublished()
+
+	// 未指定可见性时沿用原有的可见性
+	if echo.Visibility == "" && echo.Private == nil {
+		echo.Visibility = oldEcho.Visibility
+	}
+	if err := normalizeEchoVisibility(echo); err != nil {
+		return err
+	}
+
 	if err := echoService.txManager.Run(func(ctx context.Context) error {
 		// 处理标签
 		if err := echoService.ProcessEchoTags(ctx, echo); err != nil {
@@ -356,19 +413,40 @@ func (echoService *EchoService) UpdateEcho(userid uint, echo *model.Echo) error
 		}
 
 		// 更新Echo
-		return echoService.echoRepository.UpdateEcho(ctx, echo)
+		if err := echoService.echoRepository.UpdateEcho(ctx, echo); err != nil {
+			return err
+		}
+
+		// 草稿转为发布时以发布时间作为创建时间
+		if publishNow {
+			if _, err := echoService.echoRepository.PublishEcho(ctx, echo.ID, now); err != nil {
+				return err
+			}
+		}
+
+		// 记录历史版本
+		return echoService.recordEchoRevision(ctx, user, oldEcho, echo, restoredFrom)
 	}); err != nil {
 		return err
 	}
 
+	// 草稿在发布时才推送创建事件，未发布的 Echo 不推送
+	if publishNow {
+		return echoService.publishEchoCreatedEvent(echo.ID, user)
+	}
+	if !wasPublished {
+		return nil
+	}
+
 	// 更新成功后推送事件
 	if pubErr := echoService.eventBus.Publish(
 		context.Background(),
 		event.NewEvent(
 			event.EventTypeEchoUpdated,
 			event.EventPayload{
-				event.EventPayloadEcho: *echo,
-				event.EventPayloadUser: user,
+				event.EventPayloadEcho:     *echo,
+				event.EventPayloadPrevious: *oldEcho,
+				event.EventPayloadUser:     user,
 			},
 		),
 	); pubErr != nil {
@@ -400,34 +478,26 @@ func (echoService *EchoService) GetEchoById(userId, id uint) (*model.Echo, error
 		return nil, errors.New(commonModel.ECHO_NOT_FOUND)
 	}
 
-	// 如果没有登录用户，则不允许获取私密Echo
-	if userId == authModel.NO_USER_LOGINED {
-		// 如果Echo是私密的，则不允许获取
-		if echo.Private {
-			// 不允许通过ID获取私密Echo
-			return nil, errors.New(commonModel.NO_PERMISSION_DENIED)
-		}
-	} else {
-		// 如果用户已经登录,获取当前登录用户
-		user, err := echoService.commonService.CommonGetUserByUserId(userId)
-		if err != nil {
-			return nil, err
-		}
-
-		if echo.Private {
-			if !user.IsAdmin {
-				return nil, errors.New(commonModel.NO_PERMISSION_DENIED)
-			}
-
-			return echo, nil
-		}
+	// 检查当前用户能否查看该Echo
+	audience, err := echoService.viewerAudience(userId)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkEchoVisible(userId, audience, echo); err != nil {
+		return nil, err
 	}
 
 	// 刷新图片URL (暂不处理，防止拖慢详情加载速度)
 	// echoService.commonService.RefreshEchoImageURL(echo)
 
+	// 加载被引用的 Echo，复制一份以免修改缓存中的数据
+	echos := []model.Echo{*echo}
+	if err := echoService.attachQuotedEchos(userId, echos, audience); err != nil {
+		return nil, err
+	}
+
 	// 返回Echo
-	return echo, nil
+	return &echos[0], nil
 }
 
 // GetAllTags 获取所有标签
@@ -446,8 +516,8 @@ func (echoService *EchoService) DeleteTag(userid, id uint) error {
 	if err != nil {
 		return err
 	}
-	if !user.IsAdmin {
-		return errors.New(commonModel.NO_PERMISSION_DENIED)
+	if err := authz.Authorize(user, userModel.PermissionEchoManage); err != nil {
+		return err
 	}
 
 	return echoService.txManager.Run(func(ctx context.Context) error {
@@ -500,6 +570,44 @@ func (echoService *EchoService) ProcessEchoTags(ctx context.Context, echo *model
 	return nil
 }
 
+// GetEchoTimeline 按游标获取 Echo 时间线，tagId 为 0 时获取全部 Echo
+func (echoService *EchoService) GetEchoTimeline(
+	userId, tagId uint,
+	cursorQueryDto commonModel.CursorQueryDto,
+) (commonModel.CursorQueryResult[[]model.Echo], error) {
+	if cursorQueryDto.Limit < 1 || cursorQueryDto.Limit > commonModel.MaxCursorLimit {
+		cursorQueryDto.Limit = commonModel.DefaultCursorLimit
+	}
+	cursorQueryDto.Search = strings.TrimSpace(cursorQueryDto.Search)
+
+	// 管理员可以查看全部Echo，登录用户可以查看仅登录用户可见的Echo
+	audience, err := echoService.viewerAudience(userId)
+	if err != nil {
+		return commonModel.CursorQueryResult[[]model.Echo]{}, err
+	}
+
+	echos, hasMore, err := echoService.echoRepository.GetEchoTimeline(
+		tagId,
+		cursorQueryDto,
+		model.ListedVisibilities(audience),
+	)
+	if err != nil {
+		return commonModel.CursorQueryResult[[]model.Echo]{}, err
+	}
+
+	return commonModel.NewCursorQueryResult(
+		echos,
+		hasMore,
+		cursorQueryDto.SinceID,
+		func(echo model.Echo) uint { return echo.ID },
+	), nil
+}
+
+// TimelineUpdatedAt 获取时间线最后一次变化的时间
+func (echoService *EchoService) TimelineUpdatedAt() time.Time {
+	return echoService.echoRepository.TimelineUpdatedAt()
+}
+
 // GetEchosByTagId 获取指定标签 ID 的 Echo 列表
 func (echoService *EchoService) GetEchosByTagId(
 	userId, tagId uint,
@@ -513,20 +621,10 @@ func (echoService *EchoService) GetEchosByTagId(
 	}
 	pageQueryDto.Search = strings.TrimSpace(pageQueryDto.Search)
 
-	// 管理员登陆则支持查看隐私数据，否则不允许
-	showPrivate := false
-	if userId == authModel.NO_USER_LOGINED {
-		showPrivate = false
-	} else {
-		user, err := echoService.commonService.CommonGetUserByUserId(userId)
-		if err != nil {
-			return commonModel.PageQueryResult[[]model.Echo]{}, err
-		}
-		if user.IsAdmin {
-			showPrivate = true
-		} else {
-			showPrivate = false
-		}
+	// 管理员可以查看全部Echo，登录用户可以查看仅登录用户可见的Echo
+	audience, err := echoService.viewerAudience(userId)
+	if err != nil {
+		return commonModel.PageQueryResult[[]model.Echo]{}, err
 	}
 
 	echos, total, err := echoService.echoRepository.GetEchosByTagId(
@@ -534,7 +632,7 @@ func (echoService *EchoService) GetEchosByTagId(
 		pageQueryDto.Page,
 		pageQueryDto.PageSize,
 		pageQueryDto.Search,
-		showPrivate,
+		model.ListedVisibilities(audience),
 	)
 	if err != nil {
 		return commonModel.PageQueryResult[[]model.Echo]{}, err
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/lin-snow/ech0/internal/database"
+	"github.com/lin-snow/ech0/internal/event"
+	model "github.com/lin-snow/ech0/internal/model/echo"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
+	commonRepository "github.com/lin-snow/ech0/internal/repository/common"
+	repository "github.com/lin-snow/ech0/internal/repository/echo"
+	keyvalueRepository "github.com/lin-snow/ech0/internal/repository/keyvalue"
+	commonService "github.com/lin-snow/ech0/internal/service/common"
+	"github.com/lin-snow/ech0/internal/transaction"
+	logUtil "github.com/lin-snow/ech0/internal/util/log"
+	"go.uber.org/zap"
+)
+
+// memoryCache 同步写入的内存缓存，避免 Ristretto 异步写入导致测试不稳定
+type memoryCache struct {
+	mu    sync.Mutex
+	items map[string]any
+}
+
+func (c *memoryCache) Set(key string, value any, _ int64) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.items[key] = value
+	return true
+}
+
+func (c *memoryCache) SetWithTTL(key string, value any, cost int64, _ time.Duration) bool {
+	return c.Set(key, value, cost)
+}
+
+func (c *memoryCache) Get(key string) (any, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	value, ok := c.items[key]
+	if !ok {
+		return nil, errors.New("key not found")
+	}
+	return value, nil
+}
+
+func (c *memoryCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.items, key)
+}
+
+func (c *memoryCache) GetOrSet(key string, cost int64, fn func() (any, error)) (any, error) {
+	if value, err := c.Get(key); err == nil {
+		return value, nil
+	}
+	value, err := fn()
+	if err != nil {
+		return nil, err
+	}
+	c.Set(key, value, cost)
+	return value, nil
+}
+
+// recordingBus 记录发布的事件，不投递给任何订阅者
+type recordingBus struct {
+	mu     sync.Mutex
+	events []*event.Event
+}
+
+func (b *recordingBus) Publish(_ context.Context, e *event.Event) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.events = append(b.events, e)
+	return nil
+}
+
+func (b *recordingBus) Subscribe(event.EventHandler, event.EventType) error       { return nil }
+func (b *recordingBus) Subscribes(event.EventHandler, ...event.EventType) error   { return nil }
+func (b *recordingBus) SubscribeAll(event.EventHandler, ...event.EventType) error { return nil }
+
+// types 返回已发布事件的类型
+func (b *recordingBus) types() []event.EventType {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	types := make([]event.EventType, 0, len(b.events))
+	for _, e := range b.events {
+		types = append(types, e.Type)
+	}
+	return types
+}
+
+// echoFixture 基于临时 SQLite 数据库的 Echo 服务测试环境
+type echoFixture struct {
+	db      *gorm.DB
+	service *EchoService
+	bus     *recordingBus
+	owner   userModel.User // 站长，以管理员身份查看
+	alice   userModel.User // 编辑，以登录用户身份查看
+	bob     userModel.User // 编辑，以登录用户身份查看
+}
+
+func newEchoFixture(t *testing.T) *echoFixture {
+	t.Helper()
+	logUtil.Logger = zap.NewNop()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "ech0.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.AutoMigrate(database.Models()...); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	dbProvider := func() *gorm.DB { return db }
+	cache := &memoryCache{items: make(map[string]any)}
+	txManager := transaction.NewTransactionManager(dbProvider)
+	echoRepo := repository.NewEchoRepository(dbProvider, cache)
+	commonRepo := commonRepository.NewCommonRepository(dbProvider)
+	kvRepo := keyvalueRepository.NewKeyValueRepository(dbProvider, cache)
+	bus := &recordingBus{}
+	busProvider := func() event.IEventBus { return bus }
+
+	f := &echoFixture{
+		db:  db,
+		bus: bus,
+		service: NewEchoService(
+			txManager,
+			commonService.NewCommonService(txManager, commonRepo, echoRepo, kvRepo, busProvider),
+			echoRepo,
+			commonRepo,
+			nil,
+			kvRepo,
+			busProvider,
+		).(*EchoService),
+	}
+	for _, u := range []struct {
+		user *userModel.User
+		name string
+		role userModel.Role
+	}{
+		{&f.owner, "owner", userModel.RoleOwner},
+		{&f.alice, "alice", userModel.RoleEditor},
+		{&f.bob, "bob", userModel.RoleEditor},
+	} {
+		u.user.Username = u.name
+		u.user.Password = "x"
+		u.user.SetRole(u.role)
+		if err := db.Create(u.user).Error; err != nil {
+			t.Fatalf("create user: %v", err)
+		}
+	}
+	return f
+}
+
+// createEcho 绕过服务层直接写入 Echo，status 为空时视为已发布
+func (f *echoFixture) createEcho(t *testing.T, author userModel.User, visibility, status string) model.Echo {
+	t.Helper()
+	if status == "" {
+		status = model.EchoStatusPublished
+	}
+	echo := model.Echo{
+		Content:    visibility + " " + status,
+		UserID:     author.ID,
+		Username:   author.Username,
+		Visibility: visibility,
+		Status:     status,
+	}
+	if status == model.EchoStatusScheduled {
+		publishAt := time.Now().Add(time.Hour)
+		echo.PublishAt = &publishAt
+	}
+	if err := f.db.Create(&echo).Error; err != nil {
+		t.Fatalf("create echo: %v", err)
+	}
+	repository.ClearEchoPageCache()
+	return echo
+}
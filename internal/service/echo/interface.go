@@ -1,6 +1,9 @@
 package service
 
 import (
+	"context"
+	"time"
+
 	commonModel "github.com/lin-snow/ech0/internal/model/common"
 	model "github.com/lin-snow/ech0/internal/model/echo"
 )
@@ -30,12 +33,45 @@ type EchoServiceInterface interface {
 	// GetEchoById 获取指定 ID 的 Echo
 	GetEchoById(userId, id uint) (*model.Echo, error)
 
+	// GetEchoThread 获取 Echo 所在的完整会话树
+	GetEchoThread(userId, id uint) (model.EchoThread, error)
+
+	// GetUnpublishedEchos 获取所有草稿与定时发布的 Echo
+	GetUnpublishedEchos(userId uint) ([]model.Echo, error)
+
+	// PublishScheduledEchos 发布所有已到发布时间的定时 Echo
+	PublishScheduledEchos() (int, error)
+
+	// GetEchoRevisions 获取 Echo 的历史版本
+	GetEchoRevisions(userId, id uint) ([]model.EchoRevision, error)
+
+	// RestoreEchoRevision 将 Echo 恢复到指定版本
+	RestoreEchoRevision(userId, id uint, revision int) error
+
+	// PinEcho 置顶 Echo
+	PinEcho(userId, id uint) error
+
+	// UnpinEcho 取消置顶 Echo
+	UnpinEcho(userId, id uint) error
+
+	// ProcessEchoTags 处理Echo的标签，需在事务中调用
+	ProcessEchoTags(ctx context.Context, echo *model.Echo) error
+
 	// GetAllTags 获取所有标签
 	GetAllTags() ([]model.Tag, error)
 
 	// DeleteTag 删除标签
 	DeleteTag(userid, id uint) error
 
+	// GetEchoTimeline 按游标获取 Echo 时间线，tagId 为 0 时获取全部 Echo
+	GetEchoTimeline(
+		userId, tagId uint,
+		cursorQueryDto commonModel.CursorQueryDto,
+	) (commonModel.CursorQueryResult[[]model.Echo], error)
+
+	// TimelineUpdatedAt 获取时间线最后一次变化的时间
+	TimelineUpdatedAt() time.Time
+
 	GetEchosByTagId(
 		userId, tagId uint,
 		pageQueryDto commonModel.PageQueryDto,
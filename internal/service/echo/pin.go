@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/lin-snow/ech0/internal/authz"
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	model "github.com/lin-snow/ech0/internal/model/echo"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
+)
+
+// PinEcho 置顶 Echo，仅管理员可以操作，已置顶的 Echo 保持原有的置顶时间
+func (echoService *EchoService) PinEcho(userId, id uint) error {
+	user, err := echoService.commonService.CommonGetUserByUserId(userId)
+	if err != nil {
+		return err
+	}
+	if err := authz.Authorize(user, userModel.PermissionEchoManage); err != nil {
+		return err
+	}
+
+	echo, err := echoService.echoRepository.GetEchosById(id)
+	if err != nil {
+		return err
+	}
+	if echo == nil {
+		return errors.New(commonModel.ECHO_NOT_FOUND)
+	}
+	if !echo.IsPublished() {
+		return errors.New(commonModel.ECHO_PIN_NOT_PUBLISHED)
+	}
+	if echo.PinnedAt != nil {
+		return nil
+	}
+
+	return echoService.txManager.Run(func(ctx context.Context) error {
+		count, err := echoService.echoRepository.CountPinnedEchos(ctx)
+		if err != nil {
+			return err
+		}
+		if count >= model.MaxPinnedEchoCount {
+			return errors.New(commonModel.ECHO_PIN_LIMIT)
+		}
+
+		now := time.Now()
+		return echoService.echoRepository.SetEchoPinnedAt(ctx, id, &now)
+	})
+}
+
+// UnpinEcho 取消置顶 Echo，仅管理员可以操作
+func (echoService *EchoService) UnpinEcho(userId, id uint) error {
+	user, err := echoService.commonService.CommonGetUserByUserId(userId)
+	if err != nil {
+		return err
+	}
+	if err := authz.Authorize(user, userModel.PermissionEchoManage); err != nil {
+		return err
+	}
+
+	echo, err := echoService.echoRepository.GetEchosById(id)
+	if err != nil {
+		return err
+	}
+	if echo == nil {
+		return errors.New(commonModel.ECHO_NOT_FOUND)
+	}
+	if echo.PinnedAt == nil {
+		return nil
+	}
+
+	return echoService.txManager.Run(func(ctx context.Context) error {
+		return echoService.echoRepository.SetEchoPinnedAt(ctx, id, nil)
+	})
+}
+
+// pageWithPinnedEchos 分页获取 Echo 列表，置顶的 Echo 排在最前面并占用分页名额，
+// 其余 Echo 按创建时间倒序接在后面，返回当页的 Echo 与总数
+func (echoService *EchoService) pageWithPinnedEchos(
+	page, pageSize int,
+	visibilities []string,
+) ([]model.Echo, int64, error) {
+	pinned, err := echoService.echoRepository.GetPinnedEchos(visibilities)
+	if err != nil {
+		return nil, 0, err
+	}
+	pinnedCount := len(pinned)
+
+	// 当页在"置顶 + 未置顶"整体列表中的区间为 [start, end)
+	start := (page - 1) * pageSize
+	end := start + pageSize
+
+	// 列表结果可能来自缓存，需要新建切片，不能在原切片上修改
+	items := make([]model.Echo, 0, pageSize)
+	if start < pinnedCount {
+		items = append(items, pinned[start:min(end, pinnedCount)]...)
+	}
+
+	offset := max(start-pinnedCount, 0)
+	limit := max(end-pinnedCount-offset, 0)
+	unpinned, total := echoService.echoRepository.GetUnpinnedEchos(offset, limit, visibilities)
+	items = append(items, unpinned...)
+
+	return items, total + int64(pinnedCount), nil
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"slices"
+	"testing"
+
+	authModel "github.com/lin-snow/ech0/internal/model/auth"
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	model "github.com/lin-snow/ech0/internal/model/echo"
+)
+
+func TestPinEcho(t *testing.T) {
+	f := newEchoFixture(t)
+	published := f.createEcho(t, f.alice, model.VisibilityPublic, "")
+	draft := f.createEcho(t, f.alice, model.VisibilityPublic, model.EchoStatusDraft)
+
+	tests := map[string]struct {
+		run     func() error
+		wantErr string
+	}{
+		"编辑不能置顶":      {run: func() error { return f.service.PinEcho(f.alice.ID, published.ID) }, wantErr: commonModel.NO_PERMISSION_DENIED},
+		"编辑不能取消置顶":    {run: func() error { return f.service.UnpinEcho(f.alice.ID, published.ID) }, wantErr: commonModel.NO_PERMISSION_DENIED},
+		"Echo 不存在":    {run: func() error { return f.service.PinEcho(f.owner.ID, 9999) }, wantErr: commonModel.ECHO_NOT_FOUND},
+		"草稿不能置顶":      {run: func() error { return f.service.PinEcho(f.owner.ID, draft.ID) }, wantErr: commonModel.ECHO_PIN_NOT_PUBLISHED},
+		"未置顶时取消置顶无影响": {run: func() error { return f.service.UnpinEcho(f.owner.ID, published.ID) }},
+	}
+
+	for name, tt := range tests {
+		if got := errString(tt.run()); got != tt.wantErr {
+			t.Errorf("%s: err = %q, want %q", name, got, tt.wantErr)
+		}
+	}
+}
+
+func TestPinEchoLimit(t *testing.T) {
+	f := newEchoFixture(t)
+	echos := make([]model.Echo, model.MaxPinnedEchoCount+1)
+	for i := range echos {
+		echos[i] = f.createEcho(t, f.alice, model.VisibilityPublic, "")
+	}
+	for _, echo := range echos[:model.MaxPinnedEchoCount] {
+		if err := f.service.PinEcho(f.owner.ID, echo.ID); err != nil {
+			t.Fatalf("pin: %v", err)
+		}
+	}
+
+	// 重复置顶已置顶的 Echo 不占用名额
+	if err := f.service.PinEcho(f.owner.ID, echos[0].ID); err != nil {
+		t.Errorf("pin again: %v", err)
+	}
+	last := echos[model.MaxPinnedEchoCount].ID
+	if err := f.service.PinEcho(f.owner.ID, last); errString(err) != commonModel.ECHO_PIN_LIMIT {
+		t.Errorf("over limit err = %v", err)
+	}
+
+	// 取消置顶后腾出名额
+	if err := f.service.UnpinEcho(f.owner.ID, echos[0].ID); err != nil {
+		t.Fatalf("unpin: %v", err)
+	}
+	if err := f.service.PinEcho(f.owner.ID, last); err != nil {
+		t.Errorf("pin after unpin: %v", err)
+	}
+}
+
+func TestPinnedEchosLeadTheList(t *testing.T) {
+	f := newEchoFixture(t)
+	var ids []uint
+	for range 5 {
+		ids = append(ids, f.createEcho(t, f.alice, model.VisibilityPublic, "").ID)
+	}
+	private := f.createEcho(t, f.owner, model.VisibilityPrivate, "").ID
+	// 先置顶的排在后面
+	for _, id := range []uint{ids[0], ids[2], private} {
+		if err := f.service.PinEcho(f.owner.ID, id); err != nil {
+			t.Fatalf("pin: %v", err)
+		}
+	}
+
+	page := func(userId uint, number int) ([]uint, int64) {
+		t.Helper()
+		result, err := f.service.GetEchosByPage(userId, commonModel.PageQueryDto{Page: number, PageSize: 3})
+		if err != nil {
+			t.Fatalf("page: %v", err)
+		}
+		var got []uint
+		for _, echo := range result.Items {
+			got = append(got, echo.ID)
+		}
+		return got, result.Total
+	}
+
+	guest := authModel.NO_USER_LOGINED
+	tests := map[string]struct {
+		userId uint
+		page   int
+		want   []uint
+		total  int64
+	}{
+		"第一页置顶在前":        {userId: guest, page: 1, want: []uint{ids[2], ids[0], ids[4]}, total: 5},
+		"后续页只有未置顶的 Echo": {userId: guest, page: 2, want: []uint{ids[3], ids[1]}, total: 5},
+		"超出范围":           {userId: guest, page: 3, total: 5},
+		"置顶遵循可见性":        {userId: f.owner.ID, page: 1, want: []uint{private, ids[2], ids[0]}, total: 6},
+		"管理员的第二页":        {userId: f.owner.ID, page: 2, want: []uint{ids[4], ids[3], ids[1]}, total: 6},
+	}
+
+	for name, tt := range tests {
+		got, total := page(tt.userId, tt.page)
+		if !slices.Equal(got, tt.want) || total != tt.total {
+			t.Errorf("%s: page = %v (total %d), want %v (total %d)", name, got, total, tt.want, tt.total)
+		}
+	}
+
+	// 取消置顶后恢复按时间排序
+	if err := f.service.UnpinEcho(f.owner.ID, ids[2]); err != nil {
+		t.Fatalf("unpin: %v", err)
+	}
+	if got, _ := page(guest, 1); !slices.Equal(got, []uint{ids[0], ids[4], ids[3]}) {
+		t.Errorf("after unpin = %v", got)
+	}
+}
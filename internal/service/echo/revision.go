@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/lin-snow/ech0/internal/authz"
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	model "github.com/lin-snow/ech0/internal/model/echo"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
+	diffUtil "github.com/lin-snow/ech0/internal/util/diff"
+)
+
+// GetEchoRevisions 获取 Echo 的历史版本，编辑只能查看自己的 Echo
+func (echoService *EchoService) GetEchoRevisions(userId, id uint) ([]model.EchoRevision, error) {
+	user, err := echoService.commonService.CommonGetUserByUserId(userId)
+	if err != nil {
+		return nil, err
+	}
+	if err := authz.Authorize(user, userModel.PermissionEchoWrite); err != nil {
+		return nil, err
+	}
+
+	echo, err := echoService.echoRepository.GetEchosById(id)
+	if err != nil {
+		return nil, err
+	}
+	if echo == nil {
+		return nil, errors.New(commonModel.ECHO_NOT_FOUND)
+	}
+	if err := authz.AuthorizeOwner(
+		user,
+		echo.UserID,
+		userModel.PermissionEchoManage,
+		userModel.PermissionEchoWrite,
+	); err != nil {
+		return nil, err
+	}
+
+	return echoService.echoRepository.GetEchoRevisions(id)
+}
+
+// RestoreEchoRevision 将 Echo 恢复到指定版本，恢复本身也会生成一个新版本
+func (echoService *EchoService) RestoreEchoRevision(userId, id uint, revision int) error {
+	user, err := echoService.commonService.CommonGetUserByUserId(userId)
+	if err != nil {
+		return err
+	}
+	if err := authz.Authorize(user, userModel.PermissionEchoWrite); err != nil {
+		return err
+	}
+
+	target, err := echoService.echoRepository.GetEchoRevision(id, revision)
+	if err != nil {
+		return err
+	}
+	if target == nil {
+		return errors.New(commonModel.ECHO_REVISION_NOT_FOUND)
+	}
+
+	echo := target.ToEcho()
+	return echoService.saveEchoUpdate(user, &echo, revision)
+}
+
+// recordEchoRevision 在更新 Echo 的事务中写入新版本
+//
+// 首次更新时 Echo 还没有任何版本，先以作者身份补录更新前的内容作为第 1 个版本，保证原始内容不会丢失
+func (echoService *EchoService) recordEchoRevision(
+	ctx context.Context,
+	user userModel.User,
+	oldEcho, newEcho *model.Echo,
+	restoredFrom int,
+) error {
+	latest, err := echoService.echoRepository.GetLatestEchoRevision(ctx, newEcho.ID)
+	if err != nil {
+		return err
+	}
+
+	if latest == nil {
+		original := model.NewEchoRevision(oldEcho)
+		original.Revision = 1
+		original.UserID = oldEcho.UserID
+		original.Username = oldEcho.Username
+		original.Diff = diffUtil.Unified("", oldEcho.Content)
+		original.CreatedAt = oldEcho.CreatedAt
+		if err := echoService.echoRepository.CreateEchoRevision(ctx, &original); err != nil {
+			return err
+		}
+		latest = &original
+	}
+
+	revision := model.NewEchoRevision(newEcho)
+	revision.Revision = latest.Revision + 1
+	revision.UserID = user.ID
+	revision.Username = user.Username
+	revision.Diff = diffUtil.Unified(latest.Content, newEcho.Content)
+	revision.RestoredFrom = restoredFrom
+	return echoService.echoRepository.CreateEchoRevision(ctx, &revision)
+}
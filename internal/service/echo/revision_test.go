@@ -0,0 +1,137 @@
+package service
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/lin-snow/ech0/internal/event"
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	model "github.com/lin-snow/ech0/internal/model/echo"
+)
+
+func TestEchoRevisions(t *testing.T) {
+	f := newEchoFixture(t)
+	original := &model.Echo{Content: "第一版", Tags: []model.Tag{{Name: "日记"}}}
+	if err := f.service.PostEcho(f.alice.ID, original); err != nil {
+		t.Fatalf("post: %v", err)
+	}
+
+	// 首次更新时补录原始内容作为第 1 个版本
+	update := func(content string) {
+		t.Helper()
+		echo := &model.Echo{ID: original.ID, Content: content, Tags: []model.Tag{{Name: "日记"}}}
+		if err := f.service.UpdateEcho(f.alice.ID, echo); err != nil {
+			t.Fatalf("update: %v", err)
+		}
+	}
+	update("第二版")
+	update("第三版")
+
+	revisions, err := f.service.GetEchoRevisions(f.alice.ID, original.ID)
+	if err != nil {
+		t.Fatalf("revisions: %v", err)
+	}
+	if len(revisions) != 3 {
+		t.Fatalf("got %d revisions", len(revisions))
+	}
+	byNumber := make(map[int]model.EchoRevision, len(revisions))
+	for _, revision := range revisions {
+		byNumber[revision.Revision] = revision
+	}
+	tests := map[int]struct {
+		content string
+		diff    string
+	}{
+		1: {content: "第一版", diff: "@@ -0,0 +1,1 @@\n+第一版\n"},
+		2: {content: "第二版", diff: "@@ -1,1 +1,1 @@\n-第一版\n+第二版\n"},
+		3: {content: "第三版", diff: "@@ -1,1 +1,1 @@\n-第二版\n+第三版\n"},
+	}
+	for number, tt := range tests {
+		revision := byNumber[number]
+		if revision.Content != tt.content || revision.Diff != tt.diff ||
+			revision.UserID != f.alice.ID || !slices.Equal(revision.Tags, []string{"日记"}) {
+			t.Errorf("revision %d = %+v", number, revision)
+		}
+	}
+
+	// 恢复到第 1 个版本，恢复本身生成第 4 个版本
+	if err := f.service.RestoreEchoRevision(f.owner.ID, original.ID, 1); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	echo, err := f.service.GetEchoById(f.alice.ID, original.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if echo.Content != "第一版" || echo.UserID != f.alice.ID {
+		t.Errorf("restored echo = %q by %d", echo.Content, echo.UserID)
+	}
+	revisions, err = f.service.GetEchoRevisions(f.owner.ID, original.ID)
+	if err != nil {
+		t.Fatalf("revisions: %v", err)
+	}
+	var restored *model.EchoRevision
+	for i := range revisions {
+		if revisions[i].Revision == 4 {
+			restored = &revisions[i]
+		}
+	}
+	if restored == nil || restored.RestoredFrom != 1 || restored.UserID != f.owner.ID ||
+		restored.Diff != "@@ -1,1 +1,1 @@\n-第三版\n+第一版\n" {
+		t.Errorf("restore revision = %+v", restored)
+	}
+
+	want := []event.EventType{
+		event.EventTypeEchoCreated,
+		event.EventTypeEchoUpdated,
+		event.EventTypeEchoUpdated,
+		event.EventTypeEchoUpdated,
+	}
+	if got := f.bus.types(); !slices.Equal(got, want) {
+		t.Errorf("events = %v, want %v", got, want)
+	}
+}
+
+func TestEchoRevisionsPermissions(t *testing.T) {
+	f := newEchoFixture(t)
+	echo := &model.Echo{Content: "v1"}
+	if err := f.service.PostEcho(f.alice.ID, echo); err != nil {
+		t.Fatalf("post: %v", err)
+	}
+	if err := f.service.UpdateEcho(f.alice.ID, &model.Echo{ID: echo.ID, Content: "v2"}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	tests := map[string]struct {
+		run     func() error
+		wantErr string
+	}{
+		"其他编辑不能查看历史版本": {
+			run: func() error {
+				_, err := f.service.GetEchoRevisions(f.bob.ID, echo.ID)
+				return err
+			},
+			wantErr: commonModel.NO_PERMISSION_DENIED,
+		},
+		"其他编辑不能恢复版本": {
+			run:     func() error { return f.service.RestoreEchoRevision(f.bob.ID, echo.ID, 1) },
+			wantErr: commonModel.NO_PERMISSION_DENIED,
+		},
+		"版本不存在": {
+			run:     func() error { return f.service.RestoreEchoRevision(f.alice.ID, echo.ID, 9) },
+			wantErr: commonModel.ECHO_REVISION_NOT_FOUND,
+		},
+		"Echo 不存在": {
+			run: func() error {
+				_, err := f.service.GetEchoRevisions(f.alice.ID, 9999)
+				return err
+			},
+			wantErr: commonModel.ECHO_NOT_FOUND,
+		},
+	}
+
+	for name, tt := range tests {
+		if got := errString(tt.run()); got != tt.wantErr {
+			t.Errorf("%s: err = %q, want %q", name, got, tt.wantErr)
+		}
+	}
+}
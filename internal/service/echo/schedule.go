@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/lin-snow/ech0/internal/authz"
+	"github.com/lin-snow/ech0/internal/event"
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	model "github.com/lin-snow/ech0/internal/model/echo"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
+	logUtil "github.com/lin-snow/ech0/internal/util/log"
+	"go.uber.org/zap"
+)
+
+const scheduledPublishBatchSize = 50 // 每次最多发布的定时 Echo 数量
+
+// GetUnpublishedEchos 获取草稿与定时发布的 Echo，管理员可以查看全部，编辑只能查看自己的
+func (echoService *EchoService) GetUnpublishedEchos(userId uint) ([]model.Echo, error) {
+	user, err := echoService.commonService.CommonGetUserByUserId(userId)
+	if err != nil {
+		return nil, err
+	}
+	if err := authz.Authorize(user, userModel.PermissionEchoWrite); err != nil {
+		return nil, err
+	}
+
+	echos, err := echoService.echoRepository.GetUnpublishedEchos()
+	if err != nil || user.Can(userModel.PermissionEchoManage) {
+		return echos, err
+	}
+
+	own := make([]model.Echo, 0, len(echos))
+	for _, echo := range echos {
+		if echo.UserID == user.ID {
+			own = append(own, echo)
+		}
+	}
+	return own, nil
+}
+
+// PublishScheduledEchos 发布所有已到发布时间的定时 Echo，返回本次发布的数量
+func (echoService *EchoService) PublishScheduledEchos() (int, error) {
+	now := time.Now()
+	dueEchos, err := echoService.echoRepository.GetDueScheduledEchos(now, scheduledPublishBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, echo := range dueEchos {
+		var published bool
+		if err := echoService.txManager.Run(func(ctx context.Context) error {
+			published, err = echoService.echoRepository.PublishEcho(ctx, echo.ID, now)
+			return err
+		}); err != nil {
+			logUtil.GetLogger().Error("Failed to publish scheduled echo",
+				zap.Uint("echo_id", echo.ID),
+				zap.String("error", err.Error()))
+			continue
+		}
+		// 已被其他途径发布时跳过，避免重复推送
+		if !published {
+			continue
+		}
+		count++
+
+		author, err := echoService.commonService.CommonGetUserByUserId(echo.UserID)
+		if err != nil {
+			logUtil.GetLogger().Error("Failed to get author of scheduled echo",
+				zap.Uint("echo_id", echo.ID),
+				zap.String("error", err.Error()))
+			continue
+		}
+		if err := echoService.publishEchoCreatedEvent(echo.ID, author); err != nil {
+			logUtil.GetLogger().Error("Failed to load published echo",
+				zap.Uint("echo_id", echo.ID),
+				zap.String("error", err.Error()))
+		}
+	}
+
+	return count, nil
+}
+
+// publishEchoCreatedEvent 推送 Echo 创建事件(Webhook, Fediverse, Agent等)，在 Echo 实际发布时调用
+func (echoService *EchoService) publishEchoCreatedEvent(id uint, user userModel.User) error {
+	echo, err := echoService.echoRepository.GetEchosById(id)
+	if err != nil {
+		return err
+	}
+	if echo == nil {
+		return nil
+	}
+
+	if pubErr := echoService.eventBus.Publish(
+		context.Background(),
+		event.NewEvent(
+			event.EventTypeEchoCreated,
+			event.EventPayload{
+				event.EventPayloadEcho: *echo,
+				event.EventPayloadUser: user,
+			},
+		),
+	); pubErr != nil {
+		// 推送失败不影响发布
+		logUtil.GetLogger().Error(pubErr.Error())
+	}
+	return nil
+}
+
+// normalizeEchoStatus 校验并规范 Echo 的发布状态，定时发布时间已过时直接发布
+func normalizeEchoStatus(echo *model.Echo, now time.Time) error {
+	switch echo.Status {
+	case "", model.EchoStatusPublished:
+		echo.Status = model.EchoStatusPublished
+		echo.PublishAt = nil
+	case model.EchoStatusDraft:
+		echo.PublishAt = nil
+	case model.EchoStatusScheduled:
+		if echo.PublishAt == nil {
+			return errors.New(commonModel.ECHO_PUBLISH_AT_REQUIRED)
+		}
+		if !echo.PublishAt.After(now) {
+			echo.Status = model.EchoStatusPublished
+			echo.PublishAt = nil
+		}
+	default:
+		return errors.New(commonModel.INVALID_ECHO_STATUS)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/lin-snow/ech0/internal/event"
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	model "github.com/lin-snow/ech0/internal/model/echo"
+)
+
+func TestNormalizeEchoStatus(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Minute)
+	future := now.Add(time.Hour)
+
+	tests := map[string]struct {
+		echo          model.Echo
+		wantStatus    string
+		wantPublishAt bool
+		wantErr       string
+	}{
+		"未指定时直接发布":    {echo: model.Echo{}, wantStatus: model.EchoStatusPublished},
+		"发布时忽略发布时间":   {echo: model.Echo{Status: model.EchoStatusPublished, PublishAt: &future}, wantStatus: model.EchoStatusPublished},
+		"草稿忽略发布时间":    {echo: model.Echo{Status: model.EchoStatusDraft, PublishAt: &future}, wantStatus: model.EchoStatusDraft},
+		"定时发布":        {echo: model.Echo{Status: model.EchoStatusScheduled, PublishAt: &future}, wantStatus: model.EchoStatusScheduled, wantPublishAt: true},
+		"发布时间已过时直接发布": {echo: model.Echo{Status: model.EchoStatusScheduled, PublishAt: &past}, wantStatus: model.EchoStatusPublished},
+		"定时发布缺少发布时间":  {echo: model.Echo{Status: model.EchoStatusScheduled}, wantErr: commonModel.ECHO_PUBLISH_AT_REQUIRED},
+		"无效的状态":       {echo: model.Echo{Status: "archived"}, wantErr: commonModel.INVALID_ECHO_STATUS},
+	}
+
+	for name, tt := range tests {
+		echo := tt.echo
+		err := normalizeEchoStatus(&echo, now)
+		if got := errString(err); got != tt.wantErr {
+			t.Errorf("%s: err = %q, want %q", name, got, tt.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if echo.Status != tt.wantStatus || (echo.PublishAt != nil) != tt.wantPublishAt {
+			t.Errorf("%s: status %q publish_at %v", name, echo.Status, echo.PublishAt)
+		}
+	}
+}
+
+func TestPublishScheduledEchos(t *testing.T) {
+	f := newEchoFixture(t)
+	earlier := f.createEcho(t, f.alice, model.VisibilityPublic, "")
+
+	future := time.Now().Add(time.Hour)
+	scheduled := &model.Echo{Content: "scheduled", Status: model.EchoStatusScheduled, PublishAt: &future}
+	if err := f.service.PostEcho(f.alice.ID, scheduled); err != nil {
+		t.Fatalf("post: %v", err)
+	}
+	draft := &model.Echo{Content: "draft", Status: model.EchoStatusDraft}
+	if err := f.service.PostEcho(f.alice.ID, draft); err != nil {
+		t.Fatalf("post: %v", err)
+	}
+	later := f.createEcho(t, f.alice, model.VisibilityPublic, "")
+	if len(f.bus.types()) != 0 {
+		t.Fatalf("unpublished echos were pushed: %v", f.bus.types())
+	}
+
+	// 未到发布时间时不发布
+	if count, err := f.service.PublishScheduledEchos(); err != nil || count != 0 {
+		t.Fatalf("early publish = %d, %v", count, err)
+	}
+
+	if err := f.db.Model(&model.Echo{}).Where("id = ?", scheduled.ID).
+		Update("publish_at", time.Now().Add(-time.Minute)).Error; err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	before := time.Now()
+	count, err := f.service.PublishScheduledEchos()
+	if err != nil || count != 1 {
+		t.Fatalf("publish = %d, %v", count, err)
+	}
+	if want := []event.EventType{event.EventTypeEchoCreated}; !slices.Equal(f.bus.types(), want) {
+		t.Errorf("events = %v", f.bus.types())
+	}
+
+	// 再次执行时不会重复发布与推送
+	if count, err := f.service.PublishScheduledEchos(); err != nil || count != 0 {
+		t.Errorf("second publish = %d, %v", count, err)
+	}
+	if len(f.bus.types()) != 1 {
+		t.Errorf("events after second run = %v", f.bus.types())
+	}
+
+	published, err := f.service.GetEchoById(f.bob.ID, scheduled.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if !published.IsPublished() || published.CreatedAt.Before(before.Add(-time.Second)) {
+		t.Errorf("published echo status %q created at %v", published.Status, published.CreatedAt)
+	}
+	if _, err := f.service.GetEchoById(f.bob.ID, draft.ID); errString(err) != commonModel.ECHO_NOT_FOUND {
+		t.Errorf("draft is visible: %v", err)
+	}
+
+	// 新发布的 Echo 排在时间线最前，已同步到 later 的客户端能取到它
+	timeline, err := f.service.GetEchoTimeline(f.bob.ID, 0, commonModel.CursorQueryDto{SinceID: later.ID})
+	if err != nil {
+		t.Fatalf("timeline: %v", err)
+	}
+	if len(timeline.Items) != 1 || timeline.Items[0].ID != scheduled.ID || timeline.NextSinceID != scheduled.ID {
+		t.Errorf("sync after publish = %+v", timeline)
+	}
+	timeline, err = f.service.GetEchoTimeline(f.bob.ID, 0, commonModel.CursorQueryDto{})
+	if err != nil {
+		t.Fatalf("timeline: %v", err)
+	}
+	var ids []uint
+	for _, echo := range timeline.Items {
+		ids = append(ids, echo.ID)
+	}
+	if want := []uint{scheduled.ID, later.ID, earlier.ID}; !slices.Equal(ids, want) {
+		t.Errorf("timeline = %v, want %v", ids, want)
+	}
+}
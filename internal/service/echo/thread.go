@@ -0,0 +1,157 @@
+package service
+
+import (
+	"errors"
+
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	model "github.com/lin-snow/ech0/internal/model/echo"
+	mdUtil "github.com/lin-snow/ech0/internal/util/md"
+)
+
+// GetEchoThread 获取 Echo 所在的完整会话树
+func (echoService *EchoService) GetEchoThread(userId, id uint) (model.EchoThread, error) {
+	audience, err := echoService.viewerAudience(userId)
+	if err != nil {
+		return model.EchoThread{}, err
+	}
+
+	focus, err := echoService.echoRepository.GetEchosById(id)
+	if err != nil {
+		return model.EchoThread{}, err
+	}
+	if focus == nil || (!focus.IsPublished() && audience != model.AudienceAdmin) {
+		return model.EchoThread{}, errors.New(commonModel.ECHO_NOT_FOUND)
+	}
+	if !model.CanView(audience, focus.Visibility) {
+		return model.EchoThread{}, errors.New(commonModel.NO_PERMISSION_DENIED)
+	}
+
+	// 向上查找会话的根，父 Echo 已删除或不可见时以当前位置为根
+	root := *focus
+	visited := map[uint]bool{root.ID: true}
+	for depth := 0; root.ReplyToID != 0 && depth < model.MaxThreadDepth; depth++ {
+		parent, err := echoService.echoRepository.GetEchosById(root.ReplyToID)
+		if err != nil {
+			return model.EchoThread{}, err
+		}
+		if parent == nil || visited[parent.ID] || !parent.IsPublished() || !model.CanView(audience, parent.Visibility) {
+			break
+		}
+		visited[parent.ID] = true
+		root = *parent
+	}
+
+	// 按层向下加载所有回复
+	visited = map[uint]bool{root.ID: true}
+	echos := []model.Echo{root}
+	children := make(map[uint][]uint)
+	level := []uint{root.ID}
+	for depth := 0; len(level) > 0 && depth < model.MaxThreadDepth; depth++ {
+		replies, err := echoService.echoRepository.GetRepliesByEchoIds(level)
+		if err != nil {
+			return model.EchoThread{}, err
+		}
+
+		level = nil
+		for _, reply := range replies {
+			if visited[reply.ID] || !model.CanView(audience, reply.Visibility) {
+				continue
+			}
+			visited[reply.ID] = true
+			echos = append(echos, reply)
+			children[reply.ReplyToID] = append(children[reply.ReplyToID], reply.ID)
+			level = append(level, reply.ID)
+		}
+	}
+
+	if err := echoService.attachQuotedEchos(userId, echos, audience); err != nil {
+		return model.EchoThread{}, err
+	}
+
+	ids := make([]uint, 0, len(echos))
+	echoByID := make(map[uint]model.Echo, len(echos))
+	for _, echo := range echos {
+		ids = append(ids, echo.ID)
+		echoByID[echo.ID] = echo
+	}
+
+	remoteReplies, err := echoService.echoRepository.GetRemoteRepliesByEchoIds(ids)
+	if err != nil {
+		return model.EchoThread{}, err
+	}
+	remoteByEcho := make(map[uint][]model.RemoteReply)
+	for _, reply := range remoteReplies {
+		// 远端 HTML 在展示前再次清理，覆盖清理规则生效前保存的回复
+		reply.Content = mdUtil.SanitizeHTML(reply.Content)
+		remoteByEcho[reply.EchoID] = append(remoteByEcho[reply.EchoID], reply)
+	}
+
+	var build func(id uint) model.EchoThreadNode
+	build = func(id uint) model.EchoThreadNode {
+		node := model.EchoThreadNode{
+			Echo:          echoByID[id],
+			RemoteReplies: remoteByEcho[id],
+		}
+		for _, childID := range children[id] {
+			node.Replies = append(node.Replies, build(childID))
+		}
+		return node
+	}
+
+	return model.EchoThread{
+		FocusID: focus.ID,
+		Root:    build(root.ID),
+	}, nil
+}
+
+// attachQuotedEchos 为 Echo 列表加载被引用的 Echo，当前用户不可见的引用会被忽略
+func (echoService *EchoService) attachQuotedEchos(userId uint, echos []model.Echo, audience model.Audience) error {
+	var quoteIDs []uint
+	for _, echo := range echos {
+		if echo.QuoteOfID != 0 {
+			quoteIDs = append(quoteIDs, echo.QuoteOfID)
+		}
+	}
+	if len(quoteIDs) == 0 {
+		return nil
+	}
+
+	quoted, err := echoService.echoRepository.GetEchosByIds(quoteIDs)
+	if err != nil {
+		return err
+	}
+	quotedByID := make(map[uint]*model.Echo, len(quoted))
+	for i := range quoted {
+		if checkEchoVisible(userId, audience, &quoted[i]) != nil {
+			continue
+		}
+		quotedByID[quoted[i].ID] = &quoted[i]
+	}
+
+	for i := range echos {
+		echos[i].QuotedEcho = quotedByID[echos[i].QuoteOfID]
+	}
+	return nil
+}
+
+// checkEchoReference 检查回复或引用的 Echo 是否存在、已发布且对当前用户可见，id 为 0 表示没有引用
+func (echoService *EchoService) checkEchoReference(userId, id uint, notFoundMsg string) error {
+	if id == 0 {
+		return nil
+	}
+
+	echo, err := echoService.echoRepository.GetEchosById(id)
+	if err != nil {
+		return err
+	}
+	if echo == nil || !echo.IsPublished() {
+		return errors.New(notFoundMsg)
+	}
+
+	// 与查看详情相同的可见性规则，避免通过回复或引用探测、转载不可见的 Echo
+	audience, err := echoService.viewerAudience(userId)
+	if err != nil {
+		return err
+	}
+	return checkEchoVisible(userId, audience, echo)
+}
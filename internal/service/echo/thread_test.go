@@ -0,0 +1,130 @@
+package service
+
+import (
+	"testing"
+
+	authModel "github.com/lin-snow/ech0/internal/model/auth"
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	model "github.com/lin-snow/ech0/internal/model/echo"
+)
+
+func TestCheckEchoReference(t *testing.T) {
+	f := newEchoFixture(t)
+	public := f.createEcho(t, f.owner, model.VisibilityPublic, "")
+	members := f.createEcho(t, f.owner, model.VisibilityMembers, "")
+	private := f.createEcho(t, f.owner, model.VisibilityPrivate, "")
+	ownPrivate := f.createEcho(t, f.alice, model.VisibilityPrivate, "")
+	draft := f.createEcho(t, f.owner, model.VisibilityPublic, model.EchoStatusDraft)
+	ownScheduled := f.createEcho(t, f.alice, model.VisibilityPublic, model.EchoStatusScheduled)
+
+	tests := map[string]struct {
+		userId  uint
+		target  uint
+		wantErr string
+	}{
+		"没有引用":        {userId: f.alice.ID},
+		"公开":          {userId: f.alice.ID, target: public.ID},
+		"仅登录用户可见":     {userId: f.alice.ID, target: members.ID},
+		"他人的私密 Echo":  {userId: f.alice.ID, target: private.ID, wantErr: commonModel.NO_PERMISSION_DENIED},
+		"管理员可引用私密":    {userId: f.owner.ID, target: private.ID},
+		"作者可引用自己的私密":  {userId: f.alice.ID, target: ownPrivate.ID},
+		"其他用户看不到的私密":  {userId: f.bob.ID, target: ownPrivate.ID, wantErr: commonModel.NO_PERMISSION_DENIED},
+		"草稿不可引用":      {userId: f.owner.ID, target: draft.ID, wantErr: commonModel.REPLY_TARGET_NOT_FOUND},
+		"自己的定时发布不可引用": {userId: f.alice.ID, target: ownScheduled.ID, wantErr: commonModel.REPLY_TARGET_NOT_FOUND},
+		"不存在":         {userId: f.alice.ID, target: 9999, wantErr: commonModel.REPLY_TARGET_NOT_FOUND},
+	}
+
+	for name, tt := range tests {
+		err := f.service.checkEchoReference(tt.userId, tt.target, commonModel.REPLY_TARGET_NOT_FOUND)
+		if got := errString(err); got != tt.wantErr {
+			t.Errorf("%s: err = %q, want %q", name, got, tt.wantErr)
+		}
+	}
+}
+
+func TestPostEchoRejectsInvisibleReference(t *testing.T) {
+	f := newEchoFixture(t)
+	private := f.createEcho(t, f.owner, model.VisibilityPrivate, "")
+
+	reply := &model.Echo{Content: "reply", ReplyToID: private.ID}
+	if err := f.service.PostEcho(f.alice.ID, reply); errString(err) != commonModel.NO_PERMISSION_DENIED {
+		t.Errorf("reply err = %v", err)
+	}
+	quote := &model.Echo{Content: "quote", QuoteOfID: private.ID}
+	if err := f.service.PostEcho(f.alice.ID, quote); errString(err) != commonModel.NO_PERMISSION_DENIED {
+		t.Errorf("quote err = %v", err)
+	}
+
+	var count int64
+	f.db.Model(&model.Echo{}).Count(&count)
+	if count != 1 || len(f.bus.types()) != 0 {
+		t.Errorf("rejected echos were saved: count %d, events %v", count, f.bus.types())
+	}
+
+	// 管理员可以回复私密 Echo
+	if err := f.service.PostEcho(f.owner.ID, &model.Echo{Content: "reply", ReplyToID: private.ID}); err != nil {
+		t.Errorf("owner reply: %v", err)
+	}
+}
+
+func TestQuotedEchoFollowsViewerVisibility(t *testing.T) {
+	f := newEchoFixture(t)
+	quoteOf := func(target model.Echo) uint {
+		t.Helper()
+		quote := f.createEcho(t, f.alice, model.VisibilityPublic, "")
+		if err := f.db.Model(&quote).Update("quote_of_id", target.ID).Error; err != nil {
+			t.Fatalf("quote: %v", err)
+		}
+		return quote.ID
+	}
+	ofPublic := quoteOf(f.createEcho(t, f.owner, model.VisibilityPublic, ""))
+	ofMembers := quoteOf(f.createEcho(t, f.owner, model.VisibilityMembers, ""))
+	ofPrivate := quoteOf(f.createEcho(t, f.owner, model.VisibilityPrivate, ""))
+	ofOwnPrivate := quoteOf(f.createEcho(t, f.alice, model.VisibilityPrivate, ""))
+	ofDraft := quoteOf(f.createEcho(t, f.owner, model.VisibilityPublic, model.EchoStatusDraft))
+
+	guest := authModel.NO_USER_LOGINED
+	tests := map[string]struct {
+		userId     uint
+		echoId     uint
+		wantQuoted bool
+	}{
+		"访客看到公开引用":         {userId: guest, echoId: ofPublic, wantQuoted: true},
+		"访客看不到仅登录用户可见的引用":  {userId: guest, echoId: ofMembers},
+		"登录用户看到仅登录用户可见的引用": {userId: f.bob.ID, echoId: ofMembers, wantQuoted: true},
+		"登录用户看不到他人的私密引用":   {userId: f.alice.ID, echoId: ofPrivate},
+		"管理员看到私密引用":        {userId: f.owner.ID, echoId: ofPrivate, wantQuoted: true},
+		"作者看到自己的私密引用":      {userId: f.alice.ID, echoId: ofOwnPrivate, wantQuoted: true},
+		"其他用户看不到作者的私密引用":   {userId: f.bob.ID, echoId: ofOwnPrivate},
+		"登录用户看不到草稿引用":      {userId: f.alice.ID, echoId: ofDraft},
+		"未发布的引用对管理员同样隐藏":   {userId: f.owner.ID, echoId: ofDraft},
+	}
+
+	for name, tt := range tests {
+		echo, err := f.service.GetEchoById(tt.userId, tt.echoId)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if got := echo.QuotedEcho != nil; got != tt.wantQuoted {
+			t.Errorf("%s: quoted = %v, want %v", name, got, tt.wantQuoted)
+		}
+	}
+
+	// 会话树中的引用遵循同样的规则
+	thread, err := f.service.GetEchoThread(f.alice.ID, ofPrivate)
+	if err != nil {
+		t.Fatalf("thread: %v", err)
+	}
+	if thread.Root.Echo.QuotedEcho != nil {
+		t.Error("thread exposes a private quoted echo")
+	}
+}
+
+// errString 返回错误信息，没有错误时返回空字符串
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
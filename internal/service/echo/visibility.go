@@ -0,0 +1,47 @@
+package service
+
+import (
+	"errors"
+
+	authModel "github.com/lin-snow/ech0/internal/model/auth"
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	model "github.com/lin-snow/ech0/internal/model/echo"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
+)
+
+// viewerAudience 获取当前用户查看 Echo 时的身份
+func (echoService *EchoService) viewerAudience(userId uint) (model.Audience, error) {
+	if userId == authModel.NO_USER_LOGINED {
+		return model.AudienceGuest, nil
+	}
+
+	user, err := echoService.commonService.CommonGetUserByUserId(userId)
+	if err != nil {
+		return model.AudienceGuest, err
+	}
+	if user.Can(userModel.PermissionEchoManage) {
+		return model.AudienceAdmin, nil
+	}
+	return model.AudienceMember, nil
+}
+
+// checkEchoVisible 检查用户能否查看 Echo，草稿与定时发布的 Echo 仅管理员与作者可见，作者总能查看自己的 Echo
+func checkEchoVisible(userId uint, audience model.Audience, echo *model.Echo) error {
+	isAuthor := userId != authModel.NO_USER_LOGINED && echo.UserID == userId
+	if !echo.IsPublished() && audience != model.AudienceAdmin && !isAuthor {
+		return errors.New(commonModel.ECHO_NOT_FOUND)
+	}
+	if !model.CanView(audience, echo.Visibility) && !isAuthor {
+		return errors.New(commonModel.NO_PERMISSION_DENIED)
+	}
+	return nil
+}
+
+// normalizeEchoVisibility 检查并补全 Echo 的可见性，兼容只提交 private 字段的旧版客户端
+func normalizeEchoVisibility(echo *model.Echo) error {
+	echo.NormalizeVisibility()
+	if !model.IsValidVisibility(echo.Visibility) {
+		return errors.New(commonModel.INVALID_ECHO_VISIBILITY)
+	}
+	return nil
+}
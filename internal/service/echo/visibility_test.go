@@ -0,0 +1,134 @@
+package service
+
+import (
+	"slices"
+	"testing"
+
+	authModel "github.com/lin-snow/ech0/internal/model/auth"
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	model "github.com/lin-snow/ech0/internal/model/echo"
+)
+
+func TestEchoVisibilityByViewer(t *testing.T) {
+	f := newEchoFixture(t)
+	guest := authModel.NO_USER_LOGINED
+	echos := make(map[string]uint)
+	for _, visibility := range []string{
+		model.VisibilityPublic,
+		model.VisibilityUnlisted,
+		model.VisibilityFollowers,
+		model.VisibilityMembers,
+		model.VisibilityPrivate,
+	} {
+		echos[visibility] = f.createEcho(t, f.owner, visibility, "").ID
+	}
+	ownPrivate := f.createEcho(t, f.alice, model.VisibilityPrivate, "").ID
+
+	tests := map[string]struct {
+		userId  uint
+		listed  []uint // 列表与当日 Echo 中出现的 Echo
+		denied  []uint // 通过链接访问时无权限的 Echo
+		allowed []uint // 通过链接可以访问的 Echo
+	}{
+		"访客": {
+			userId:  guest,
+			listed:  []uint{echos[model.VisibilityPublic]},
+			denied:  []uint{echos[model.VisibilityFollowers], echos[model.VisibilityMembers], echos[model.VisibilityPrivate], ownPrivate},
+			allowed: []uint{echos[model.VisibilityPublic], echos[model.VisibilityUnlisted]},
+		},
+		"登录用户": {
+			userId:  f.bob.ID,
+			listed:  []uint{echos[model.VisibilityPublic], echos[model.VisibilityFollowers], echos[model.VisibilityMembers]},
+			denied:  []uint{echos[model.VisibilityPrivate], ownPrivate},
+			allowed: []uint{echos[model.VisibilityUnlisted], echos[model.VisibilityMembers]},
+		},
+		"作者的私密 Echo 只能通过链接访问": {
+			userId:  f.alice.ID,
+			listed:  []uint{echos[model.VisibilityPublic], echos[model.VisibilityFollowers], echos[model.VisibilityMembers]},
+			denied:  []uint{echos[model.VisibilityPrivate]},
+			allowed: []uint{ownPrivate},
+		},
+		"管理员": {
+			userId: f.owner.ID,
+			listed: []uint{
+				echos[model.VisibilityPublic],
+				echos[model.VisibilityUnlisted],
+				echos[model.VisibilityFollowers],
+				echos[model.VisibilityMembers],
+				echos[model.VisibilityPrivate],
+				ownPrivate,
+			},
+			allowed: []uint{echos[model.VisibilityPrivate], ownPrivate},
+		},
+	}
+
+	for name, tt := range tests {
+		page, err := f.service.GetEchosByPage(tt.userId, commonModel.PageQueryDto{Page: 1, PageSize: 20})
+		if err != nil {
+			t.Fatalf("%s: page: %v", name, err)
+		}
+		today, err := f.service.GetTodayEchos(tt.userId)
+		if err != nil {
+			t.Fatalf("%s: today: %v", name, err)
+		}
+		for list, items := range map[string][]model.Echo{"page": page.Items, "today": today} {
+			var ids []uint
+			for _, echo := range items {
+				ids = append(ids, echo.ID)
+			}
+			slices.Sort(ids)
+			if !slices.Equal(ids, tt.listed) {
+				t.Errorf("%s: %s = %v, want %v", name, list, ids, tt.listed)
+			}
+		}
+		if page.Total != int64(len(tt.listed)) {
+			t.Errorf("%s: total = %d", name, page.Total)
+		}
+
+		for _, id := range tt.denied {
+			if _, err := f.service.GetEchoById(tt.userId, id); errString(err) != commonModel.NO_PERMISSION_DENIED {
+				t.Errorf("%s: get %d err = %v", name, id, err)
+			}
+		}
+		for _, id := range tt.allowed {
+			if _, err := f.service.GetEchoById(tt.userId, id); err != nil {
+				t.Errorf("%s: get %d: %v", name, id, err)
+			}
+		}
+	}
+}
+
+func TestPostEchoVisibility(t *testing.T) {
+	private := true
+	tests := map[string]struct {
+		echo    model.Echo
+		want    string
+		wantErr string
+	}{
+		"默认公开":      {echo: model.Echo{}, want: model.VisibilityPublic},
+		"仅关注者":      {echo: model.Echo{Visibility: model.VisibilityFollowers}, want: model.VisibilityFollowers},
+		"旧版客户端私密":   {echo: model.Echo{Private: &private}, want: model.VisibilityPrivate},
+		"无效的可见性被拒绝": {echo: model.Echo{Visibility: "secret"}, wantErr: commonModel.INVALID_ECHO_VISIBILITY},
+	}
+
+	f := newEchoFixture(t)
+	for name, tt := range tests {
+		echo := tt.echo
+		echo.Content = name
+		err := f.service.PostEcho(f.alice.ID, &echo)
+		if got := errString(err); got != tt.wantErr {
+			t.Errorf("%s: err = %q, want %q", name, got, tt.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		saved, err := f.service.GetEchoById(f.alice.ID, echo.ID)
+		if err != nil {
+			t.Fatalf("%s: get: %v", name, err)
+		}
+		if saved.Visibility != tt.want || *saved.Private != (tt.want == model.VisibilityPrivate) {
+			t.Errorf("%s: visibility %q private %v", name, saved.Visibility, *saved.Private)
+		}
+	}
+}
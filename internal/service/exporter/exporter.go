@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"time"
+
+	"github.com/lin-snow/ech0/internal/authz"
+	"github.com/lin-snow/ech0/internal/config"
+	"github.com/lin-snow/ech0/internal/exporter"
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	echoModel "github.com/lin-snow/ech0/internal/model/echo"
+	model "github.com/lin-snow/ech0/internal/model/exporter"
+	settingModel "github.com/lin-snow/ech0/internal/model/setting"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
+	echoRepository "github.com/lin-snow/ech0/internal/repository/echo"
+	keyvalueRepository "github.com/lin-snow/ech0/internal/repository/keyvalue"
+	commonService "github.com/lin-snow/ech0/internal/service/common"
+	fileUtil "github.com/lin-snow/ech0/internal/util/file"
+	jsonUtil "github.com/lin-snow/ech0/internal/util/json"
+	logUtil "github.com/lin-snow/ech0/internal/util/log"
+	"go.uber.org/zap"
+)
+
+type ExportService struct {
+	commonService      commonService.CommonServiceInterface
+	echoRepository     echoRepository.EchoRepositoryInterface
+	keyvalueRepository keyvalueRepository.KeyValueRepositoryInterface
+}
+
+func NewExportService(
+	commonSvc commonService.CommonServiceInterface,
+	echoRepo echoRepository.EchoRepositoryInterface,
+	keyvalueRepo keyvalueRepository.KeyValueRepositoryInterface,
+) ExportServiceInterface {
+	return &ExportService{
+		commonService:      commonSvc,
+		echoRepository:     echoRepo,
+		keyvalueRepository: keyvalueRepo,
+	}
+}
+
+// CheckExport 检查导出权限与格式，返回导出文件的信息
+func (exportService *ExportService) CheckExport(userId uint, format string) (model.ExportFile, error) {
+	user, err := exportService.commonService.CommonGetUserByUserId(userId)
+	if err != nil {
+		return model.ExportFile{}, err
+	}
+	if err := authz.Authorize(user, userModel.PermissionContentExport); err != nil {
+		return model.ExportFile{}, err
+	}
+
+	if !model.IsValidExportFormat(format) {
+		return model.ExportFile{}, errors.New(commonModel.EXPORT_FORMAT_INVALID)
+	}
+
+	return model.NewExportFile(format, time.Now()), nil
+}
+
+// Export 按指定格式导出全部 Echo，本地与 S3 中的图片会一并写入归档
+func (exportService *ExportService) Export(ctx context.Context, format string, writer io.Writer) error {
+	return exporter.Export(format, writer, exporter.Options{
+		Title: exportService.siteTitle(),
+		Echos: exportService.echoRepository.GetEchosForExport,
+		OpenImage: func(image echoModel.Image) (io.ReadCloser, error) {
+			reader, err := exportService.openImage(ctx, image)
+			if err != nil {
+				logUtil.GetLogger().Warn("Failed to export image",
+					zap.String("url", image.ImageURL),
+					zap.String("error", err.Error()))
+			}
+			return reader, err
+		},
+	})
+}
+
+// openImage 打开图片文件，直链图片保留原始地址
+func (exportService *ExportService) openImage(
+	ctx context.Context,
+	image echoModel.Image,
+) (io.ReadCloser, error) {
+	switch image.ImageSource {
+	case echoModel.ImageSourceURL:
+		return nil, nil
+	case echoModel.ImageSourceS3:
+		if image.ObjectKey == "" {
+			return nil, nil
+		}
+		client, _, err := exportService.commonService.GetS3Client()
+		if err != nil {
+			return nil, err
+		}
+		return client.Download(ctx, image.ObjectKey)
+	default:
+		// 未知图片来源按本地图片处理
+		imagePath, err := fileUtil.ValidateAndSanitizePath(config.Config.Upload.ImagePath, image.ImageURL, "/images/")
+		if err != nil {
+			return nil, err
+		}
+		return os.Open(imagePath)
+	}
+}
+
+// siteTitle 获取静态站点的标题，未设置时使用默认标题
+func (exportService *ExportService) siteTitle() string {
+	value, err := exportService.keyvalueRepository.GetKeyValue(commonModel.SystemSettingsKey)
+	if err != nil {
+		return ""
+	}
+	str, ok := value.(string)
+	if !ok {
+		return ""
+	}
+
+	var setting settingModel.SystemSetting
+	if err := jsonUtil.JSONUnmarshal([]byte(str), &setting); err != nil {
+		return ""
+	}
+	if setting.SiteTitle != "" {
+		return setting.SiteTitle
+	}
+	return setting.ServerName
+}
@@ -0,0 +1,248 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/lin-snow/ech0/internal/config"
+	"github.com/lin-snow/ech0/internal/database"
+	"github.com/lin-snow/ech0/internal/event"
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	echoModel "github.com/lin-snow/ech0/internal/model/echo"
+	model "github.com/lin-snow/ech0/internal/model/exporter"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
+	commonRepository "github.com/lin-snow/ech0/internal/repository/common"
+	echoRepository "github.com/lin-snow/ech0/internal/repository/echo"
+	keyvalueRepository "github.com/lin-snow/ech0/internal/repository/keyvalue"
+	commonService "github.com/lin-snow/ech0/internal/service/common"
+	"github.com/lin-snow/ech0/internal/transaction"
+	logUtil "github.com/lin-snow/ech0/internal/util/log"
+	"go.uber.org/zap"
+)
+
+// memoryCache 同步写入的内存缓存，避免 Ristretto 异步写入导致测试不稳定
+type memoryCache struct {
+	mu    sync.Mutex
+	items map[string]any
+}
+
+func (c *memoryCache) Set(key string, value any, _ int64) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.items[key] = value
+	return true
+}
+
+func (c *memoryCache) SetWithTTL(key string, value any, cost int64, _ time.Duration) bool {
+	return c.Set(key, value, cost)
+}
+
+func (c *memoryCache) Get(key string) (any, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	value, ok := c.items[key]
+	if !ok {
+		return nil, errors.New("key not found")
+	}
+	return value, nil
+}
+
+func (c *memoryCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.items, key)
+}
+
+func (c *memoryCache) GetOrSet(key string, cost int64, fn func() (any, error)) (any, error) {
+	if value, err := c.Get(key); err == nil {
+		return value, nil
+	}
+	value, err := fn()
+	if err != nil {
+		return nil, err
+	}
+	c.Set(key, value, cost)
+	return value, nil
+}
+
+// exportFixture 基于临时 SQLite 数据库与本地图片目录的导出服务测试环境
+type exportFixture struct {
+	db      *gorm.DB
+	service *ExportService
+	owner   userModel.User // 站长，拥有导出权限
+	alice   userModel.User // 编辑，没有导出权限
+}
+
+func newExportFixture(t *testing.T) *exportFixture {
+	t.Helper()
+	logUtil.Logger = zap.NewNop()
+
+	dir := t.TempDir()
+	imagePath := config.Config.Upload.ImagePath
+	t.Cleanup(func() { config.Config.Upload.ImagePath = imagePath })
+	config.Config.Upload.ImagePath = filepath.Join(dir, "images")
+	if err := os.MkdirAll(config.Config.Upload.ImagePath, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(dir, "ech0.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.AutoMigrate(database.Models()...); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	dbProvider := func() *gorm.DB { return db }
+	cache := &memoryCache{items: make(map[string]any)}
+	txManager := transaction.NewTransactionManager(dbProvider)
+	echoRepo := echoRepository.NewEchoRepository(dbProvider, cache)
+	commonRepo := commonRepository.NewCommonRepository(dbProvider)
+	kvRepo := keyvalueRepository.NewKeyValueRepository(dbProvider, cache)
+	busProvider := func() event.IEventBus { return nil }
+	commonSvc := commonService.NewCommonService(txManager, commonRepo, echoRepo, kvRepo, busProvider)
+
+	f := &exportFixture{
+		db:      db,
+		service: NewExportService(commonSvc, echoRepo, kvRepo).(*ExportService),
+	}
+	for _, u := range []struct {
+		user *userModel.User
+		name string
+		role userModel.Role
+	}{
+		{&f.owner, "owner", userModel.RoleOwner},
+		{&f.alice, "alice", userModel.RoleEditor},
+	} {
+		u.user.Username = u.name
+		u.user.Password = "x"
+		u.user.SetRole(u.role)
+		if err := db.Create(u.user).Error; err != nil {
+			t.Fatalf("create user: %v", err)
+		}
+	}
+	return f
+}
+
+func TestCheckExport(t *testing.T) {
+	f := newExportFixture(t)
+
+	tests := map[string]struct {
+		userId  uint
+		format  string
+		wantErr string
+	}{
+		"站长导出 Markdown": {userId: f.owner.ID, format: model.ExportFormatMarkdown},
+		"站长导出 JSONL":    {userId: f.owner.ID, format: model.ExportFormatJSONL},
+		"编辑没有导出权限":      {userId: f.alice.ID, format: model.ExportFormatJSONL, wantErr: commonModel.NO_PERMISSION_DENIED},
+		"不支持的格式":        {userId: f.owner.ID, format: "pdf", wantErr: commonModel.EXPORT_FORMAT_INVALID},
+	}
+
+	for name, tt := range tests {
+		file, err := f.service.CheckExport(tt.userId, tt.format)
+		got := ""
+		if err != nil {
+			got = err.Error()
+		}
+		if got != tt.wantErr {
+			t.Errorf("%s: err = %q, want %q", name, got, tt.wantErr)
+			continue
+		}
+		if err == nil && !strings.HasPrefix(file.Filename, "ech0-export-"+tt.format+"-") {
+			t.Errorf("%s: file = %+v", name, file)
+		}
+	}
+}
+
+func TestExportEchosWithImages(t *testing.T) {
+	f := newExportFixture(t)
+	if err := os.WriteFile(filepath.Join(config.Config.Upload.ImagePath, "a.png"), []byte("png"), 0o644); err != nil {
+		t.Fatalf("write image: %v", err)
+	}
+	setting := commonModel.KeyValue{Key: commonModel.SystemSettingsKey, Value: `{"site_title":"我的站点"}`}
+	if err := f.db.Create(&setting).Error; err != nil {
+		t.Fatalf("save setting: %v", err)
+	}
+
+	create := func(echo echoModel.Echo) echoModel.Echo {
+		t.Helper()
+		echo.UserID = f.owner.ID
+		if echo.Status == "" {
+			echo.Status = echoModel.EchoStatusPublished
+		}
+		if err := f.db.Create(&echo).Error; err != nil {
+			t.Fatalf("create echo: %v", err)
+		}
+		return echo
+	}
+	withImages := create(echoModel.Echo{
+		Content: "带图片",
+		Images: []echoModel.Image{
+			{ImageURL: "/images/a.png", ImageSource: echoModel.ImageSourceLocal},
+			{ImageURL: "https://cdn.example/b.jpg", ImageSource: echoModel.ImageSourceURL},
+			{ImageURL: "/images/missing.png", ImageSource: echoModel.ImageSourceLocal},
+		},
+	})
+	draft := create(echoModel.Echo{Content: "草稿", Status: echoModel.EchoStatusDraft})
+	trashed := create(echoModel.Echo{Content: "回收站"})
+	if err := f.db.Delete(&trashed).Error; err != nil {
+		t.Fatalf("trash echo: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := f.service.Export(context.Background(), model.ExportFormatHTML, &buf); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	reader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("open zip: %v", err)
+	}
+	files := make(map[string]string)
+	for _, file := range reader.File {
+		rc, err := file.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", file.Name, err)
+		}
+		content, _ := io.ReadAll(rc)
+		_ = rc.Close()
+		files[file.Name] = string(content)
+	}
+
+	// 导出包含草稿，不包含回收站中的 Echo
+	for name, want := range map[string]bool{
+		"echo-" + strconv.Itoa(int(withImages.ID)) + ".html": true,
+		"echo-" + strconv.Itoa(int(draft.ID)) + ".html":      true,
+		"echo-" + strconv.Itoa(int(trashed.ID)) + ".html":    false,
+	} {
+		if _, ok := files[name]; ok != want {
+			t.Errorf("%s exported = %v", name, ok)
+		}
+	}
+
+	// 本地图片写入归档，直链与读取失败的图片保留原始地址
+	localName := "media/" + strconv.Itoa(int(withImages.Images[0].ID)) + "-a.png"
+	if files[localName] != "png" {
+		t.Errorf("%s = %q", localName, files[localName])
+	}
+	page := files["echo-"+strconv.Itoa(int(withImages.ID))+".html"]
+	for _, want := range []string{localName, "https://cdn.example/b.jpg", "/images/missing.png", "<title>我的站点 - #"} {
+		if !strings.Contains(page, want) {
+			t.Errorf("echo page missing %q", want)
+		}
+	}
+}
@@ -0,0 +1,16 @@
+package service
+
+import (
+	"context"
+	"io"
+
+	model "github.com/lin-snow/ech0/internal/model/exporter"
+)
+
+type ExportServiceInterface interface {
+	// CheckExport 检查导出权限与格式，返回导出文件的信息
+	CheckExport(userId uint, format string) (model.ExportFile, error)
+
+	// Export 按指定格式导出全部 Echo
+	Export(ctx context.Context, format string, writer io.Writer) error
+}
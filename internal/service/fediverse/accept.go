@@ -1,83 +1,109 @@
 package service
 
-// import (
-// 	"context"
-// 	"errors"
-// 	"fmt"
-// 	"strings"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
 
-// 	"gorm.io/gorm"
+	"gorm.io/gorm"
 
-// 	model "github.com/lin-snow/ech0/internal/model/fediverse"
-// 	userModel "github.com/lin-snow/ech0/internal/model/user"
-// )
+	model "github.com/lin-snow/ech0/internal/model/fediverse"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
+	logUtil "github.com/lin-snow/ech0/internal/util/log"
+	"go.uber.org/zap"
+)
 
-// // handleAcceptActivity 处理远端返回的 Accept 活动，将本地关注状态标记为已接受
-// func (fediverseService *FediverseService) handleAcceptActivity(user *userModel.User, activity *model.Activity) error {
-// 	if user == nil {
-// 		return errors.New("user is nil")
-// 	}
-// 	if activity == nil {
-// 		return errors.New("activity is nil")
-// 	}
+// handleAcceptActivity 处理远端返回的 Accept 活动，将本地关注状态标记为已接受
+func (fediverseService *FediverseService) handleAcceptActivity(user *userModel.User, activity *model.Activity) error {
+	return fediverseService.updateFollowStatus(user, activity, model.FollowStatusAccepted)
+}
 
-// 	followActivityID := extractFollowActivityIDFromAccept(activity.Object)
-// 	if followActivityID == "" {
-// 		followActivityID = strings.TrimSpace(activity.ObjectID)
-// 	}
-// 	if followActivityID == "" {
-// 		return errors.New("accept activity missing follow id")
-// 	}
+// handleRejectActivity 处理远端返回的 Reject 活动，将本地关注状态标记为已拒绝
+func (fediverseService *FediverseService) handleRejectActivity(user *userModel.User, activity *model.Activity) error {
+	return fediverseService.updateFollowStatus(user, activity, model.FollowStatusRejected)
+}
 
-// 	return fediverseService.txManager.Run(func(ctx context.Context) error {
-// 		err := fediverseService.fediverseRepository.UpdateFollowStatusByActivityID(
-// 			ctx,
-// 			user.ID,
-// 			followActivityID,
-// 			model.FollowStatusAccepted,
-// 		)
-// 		if err != nil {
-// 			if errors.Is(err, gorm.ErrRecordNotFound) {
-// 				fmt.Printf(
-// 					"accept activity references unknown follow: user=%d activity=%s\n",
-// 					user.ID,
-// 					followActivityID,
-// 				)
-// 				return nil
-// 			}
-// 		}
-// 		return err
-// 	})
-// }
+// updateFollowStatus 根据 Accept/Reject 活动更新本地关注状态并通知
+func (fediverseService *FediverseService) updateFollowStatus(
+	user *userModel.User,
+	activity *model.Activity,
+	status string,
+) error {
+	if user == nil {
+		return errors.New("user is nil")
+	}
+	if activity == nil {
+		return errors.New("activity is nil")
+	}
 
-// // extractFollowActivityIDFromAccept 从 Accept 活动的 object 中提取原始 Follow Activity ID
-// func extractFollowActivityIDFromAccept(object any) string {
-// 	switch value := object.(type) {
-// 	case string:
-// 		return strings.TrimSpace(value)
-// 	case map[string]any:
-// 		if id := strings.TrimSpace(getStringFromMap(value, "id")); id != "" {
-// 			return id
-// 		}
-// 		if nested, ok := value["object"]; ok {
-// 			switch n := nested.(type) {
-// 			case string:
-// 				s := strings.TrimSpace(n)
-// 				if strings.Contains(s, "/activities/") {
-// 					return s
-// 				}
-// 			case map[string]any:
-// 				if id := strings.TrimSpace(getStringFromMap(n, "id")); id != "" {
-// 					return id
-// 				}
-// 			}
-// 		}
-// 	case []any:
-// 		for _, item := range value {
-// 			if id := extractFollowActivityIDFromAccept(item); id != "" {
-// 				return id
-// 			}
-// 		}
-// 	}
-// 	return ""
-// }
+	followActivityID := extractFollowActivityIDFromAccept(activity.Object)
+	if followActivityID == "" {
+		followActivityID = strings.TrimSpace(activity.ObjectID)
+	}
+	if followActivityID == "" {
+		return fmt.Errorf("%s activity missing follow id", strings.ToLower(activity.Type))
+	}
+
+	return fediverseService.txManager.Run(func(ctx context.Context) error {
+		// 只有被关注的 Actor 本人才能接受或拒绝关注请求
+		err := fediverseService.fediverseRepository.UpdateFollowStatusByActivityID(
+			ctx,
+			user.ID,
+			followActivityID,
+			activity.ActorURL,
+			status,
+		)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				logUtil.GetLogger().Warn(
+					"Follow response references unknown follow",
+					zap.String("type", activity.Type),
+					zap.Uint("user_id", user.ID),
+					zap.String("actor", activity.ActorURL),
+					zap.String("follow", followActivityID),
+				)
+				return nil
+			}
+			return err
+		}
+
+		content := fmt.Sprintf("%s 接受了你的关注请求", activity.ActorURL)
+		if status == model.FollowStatusRejected {
+			content = fmt.Sprintf("%s 拒绝了你的关注请求", activity.ActorURL)
+		}
+		return fediverseService.postInboxNotification(ctx, content, activity)
+	})
+}
+
+// extractFollowActivityIDFromAccept 从 Accept 活动的 object 中提取原始 Follow Activity ID
+func extractFollowActivityIDFromAccept(object any) string {
+	switch value := object.(type) {
+	case string:
+		return strings.TrimSpace(value)
+	case map[string]any:
+		if id := strings.TrimSpace(getStringFromMap(value, "id")); id != "" {
+			return id
+		}
+		if nested, ok := value["object"]; ok {
+			switch n := nested.(type) {
+			case string:
+				s := strings.TrimSpace(n)
+				if strings.Contains(s, "/activities/") {
+					return s
+				}
+			case map[string]any:
+				if id := strings.TrimSpace(getStringFromMap(n, "id")); id != "" {
+					return id
+				}
+			}
+		}
+	case []any:
+		for _, item := range value {
+			if id := extractFollowActivityIDFromAccept(item); id != "" {
+				return id
+			}
+		}
+	}
+	return ""
+}
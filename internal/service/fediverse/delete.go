@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	model "github.com/lin-snow/ech0/internal/model/fediverse"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
+)
+
+//=======================================
+//	处理 Delete
+//=======================================
+
+// handleDeleteActivity 处理远端的删除活动，object 为 Actor 自身时表示账号注销，否则为推文删除
+func (fediverseService *FediverseService) handleDeleteActivity(
+	user *userModel.User,
+	activity *model.Activity,
+) error {
+	if activity.ActorURL == "" {
+		return errors.New("delete activity missing actor")
+	}
+
+	objectID := extractObjectID(activity.Object)
+	if objectID == "" {
+		return errors.New("delete activity missing object id")
+	}
+
+	return fediverseService.txManager.Run(func(ctx context.Context) error {
+		// 远端账号注销，清理粉丝关系和收件箱推文
+		if objectID == activity.ActorURL {
+			exists, err := fediverseService.fediverseRepository.FollowerExists(ctx, user.ID, activity.ActorURL)
+			if err != nil {
+				return err
+			}
+			if err := fediverseService.fediverseRepository.DeleteInboxStatusesByActor(ctx, user.ID, activity.ActorURL); err != nil {
+				return err
+			}
+			if err := fediverseService.echoRepository.DeleteRemoteRepliesByActor(ctx, activity.ActorURL); err != nil {
+				return err
+			}
+			if !exists {
+				return nil
+			}
+			if err := fediverseService.fediverseRepository.DeleteFollower(ctx, user.ID, activity.ActorURL); err != nil {
+				return err
+			}
+			return fediverseService.postInboxNotification(
+				ctx,
+				fmt.Sprintf("粉丝 %s 的账号已注销", activity.ActorURL),
+				activity,
+			)
+		}
+
+		// 远端推文删除，同时移除挂在本地 Echo 下的远端回复
+		if err := fediverseService.echoRepository.DeleteRemoteReplyByObjectID(ctx, activity.ActorURL, objectID); err != nil {
+			return err
+		}
+
+		// 仅对已存储在收件箱中的推文发送通知
+		deleted, err := fediverseService.fediverseRepository.DeleteInboxStatusesByObjectID(
+			ctx,
+			user.ID,
+			activity.ActorURL,
+			objectID,
+		)
+		if err != nil || deleted == 0 {
+			return err
+		}
+
+		return fediverseService.postInboxNotification(
+			ctx,
+			fmt.Sprintf("%s 删除了推文 %s", activity.ActorURL, objectID),
+			activity,
+		)
+	})
+}
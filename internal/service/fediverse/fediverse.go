@@ -4,6 +4,7 @@ import (
 	"github.com/lin-snow/ech0/internal/fediverse"
 	echoRepository "github.com/lin-snow/ech0/internal/repository/echo"
 	repository "github.com/lin-snow/ech0/internal/repository/fediverse"
+	inboxRepository "github.com/lin-snow/ech0/internal/repository/inbox"
 	userRepository "github.com/lin-snow/ech0/internal/repository/user"
 	"github.com/lin-snow/ech0/internal/transaction"
 )
@@ -14,6 +15,7 @@ type FediverseService struct {
 	fediverseRepository repository.FediverseRepositoryInterface
 	userRepository      userRepository.UserRepositoryInterface
 	echoRepository      echoRepository.EchoRepositoryInterface
+	inboxRepository     inboxRepository.InboxRepositoryInterface
 }
 
 func NewFediverseService(
@@ -22,6 +24,7 @@ func NewFediverseService(
 	fediverseRepository repository.FediverseRepositoryInterface,
 	userRepository userRepository.UserRepositoryInterface,
 	echoRepository echoRepository.EchoRepositoryInterface,
+	inboxRepository inboxRepository.InboxRepositoryInterface,
 ) FediverseServiceInterface {
 	return &FediverseService{
 		core:                core,
@@ -29,5 +32,6 @@ func NewFediverseService(
 		fediverseRepository: fediverseRepository,
 		userRepository:      userRepository,
 		echoRepository:      echoRepository,
+		inboxRepository:     inboxRepository,
 	}
 }
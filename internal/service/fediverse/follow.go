@@ -54,7 +54,7 @@ func (fediverseService *FediverseService) handleFollowActivity(
 		return err
 	}
 
-	if err := httpUtil.PostActivity(acceptPayload, inboxURL, actor.ID); err != nil {
+	if err := fediverse.PostActivity(acceptPayload, inboxURL, actor.ID); err != nil {
 		fmt.Printf("Error posting accept activity: %v\n", err)
 		return err
 	}
@@ -76,10 +76,19 @@ func (fediverseService *FediverseService) handleFollowActivity(
 	// 如果不存在，则保存
 	if !exists {
 		return fediverseService.txManager.Run(func(ctx context.Context) error {
-			return fediverseService.fediverseRepository.SaveFollower(ctx, &model.Follower{
-				UserID:  user.ID,
-				ActorID: followerActor,
-			})
+			if err := fediverseService.fediverseRepository.SaveFollower(ctx, &model.Follower{
+				UserID:     user.ID,
+				ActorID:    followerActor,
+				ActivityID: activity.ActivityID,
+			}); err != nil {
+				return err
+			}
+
+			return fediverseService.postInboxNotification(
+				ctx,
+				fmt.Sprintf("%s 关注了你", followerActor),
+				activity,
+			)
 		})
 	} else {
 		return nil
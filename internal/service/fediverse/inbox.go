@@ -1,14 +1,41 @@
 package service
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
+	"net/http"
+	"strings"
+	"time"
 
 	commonModel "github.com/lin-snow/ech0/internal/model/common"
 	model "github.com/lin-snow/ech0/internal/model/fediverse"
+	inboxModel "github.com/lin-snow/ech0/internal/model/inbox"
+	logUtil "github.com/lin-snow/ech0/internal/util/log"
+	"go.uber.org/zap"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
 )
 
+// VerifyInboxSignature 校验 Inbox 请求的 HTTP 签名，并确认签名者即 Activity 的发起者
+func (fediverseService *FediverseService) VerifyInboxSignature(
+	req *http.Request,
+	body []byte,
+	activity *model.Activity,
+) error {
+	owner, err := fediverseService.core.VerifyInboxRequest(req, body)
+	if err != nil {
+		logUtil.GetLogger().Warn("Inbox signature verification failed", zap.Error(err))
+		return errors.New(commonModel.SIGNATURE_INVALID)
+	}
+
+	if activity.ActorURL == "" || owner != activity.ActorURL {
+		return errors.New(commonModel.SIGNATURE_ACTOR_MISMATCH)
+	}
+
+	return nil
+}
+
 // ProcessInbox 处理接收到的 ActivityPub 消息
 func (fediverseService *FediverseService) HandleInbox(
 	username string,
@@ -24,25 +51,89 @@ func (fediverseService *FediverseService) HandleInbox(
 	switch activity.Type {
 	// 处理关注请求
 	case model.ActivityTypeFollow:
-		if err := fediverseService.handleFollowActivity(&user, activity); err != nil {
-			return err
-		}
-	// 处理接收到的推文推送
-	// case model.ActivityTypeCreate:
-	// 	if err := fediverseService.handleCreateActivity(&user, activity); err != nil {
-	// 		return err
-	// 	}
+		return fediverseService.handleFollowActivity(&user, activity)
+	// 处理撤销 (取消关注、取消点赞、取消转发)
+	case model.ActivityTypeUndo:
+		return fediverseService.handleUndoActivity(&user, activity)
 	// 处理接收到的接受关注请求
-	// case model.ActivityTypeAccept:
-	// 	if err := fediverseService.handleAcceptActivity(&user, activity); err != nil {
-	// 		return err
-	// 	}
+	case model.ActivityTypeAccept:
+		return fediverseService.handleAcceptActivity(&user, activity)
+	// 处理接收到的拒绝关注请求
+	case model.ActivityTypeReject:
+		return fediverseService.handleRejectActivity(&user, activity)
+	// 处理点赞
+	case model.ActivityTypeLike:
+		return fediverseService.handleLikeActivity(&user, activity)
+	// 处理转发
+	case model.ActivityTypeAnnounce:
+		return fediverseService.handleAnnounceActivity(&user, activity)
+	// 处理删除 (远端推文或 Actor 被删除)
+	case model.ActivityTypeDelete:
+		return fediverseService.handleDeleteActivity(&user, activity)
+	// 处理接收到的推文推送
+	case model.ActivityTypeCreate:
+		return fediverseService.handleCreateActivity(&user, activity)
 
 	default:
 		return errors.New(
 			"Unsupported activity type: " + cases.Title(language.English).String(activity.Type),
 		)
 	}
+}
 
-	return nil
+// postInboxNotification 写入一条联邦互动通知到收件箱
+func (fediverseService *FediverseService) postInboxNotification(
+	ctx context.Context,
+	content string,
+	activity *model.Activity,
+) error {
+	meta, _ := json.Marshal(map[string]string{
+		"activity_id":   activity.ActivityID,
+		"activity_type": activity.Type,
+		"actor":         activity.ActorURL,
+		"object":        extractObjectID(activity.Object),
+	})
+
+	return fediverseService.inboxRepository.PostInbox(ctx, &inboxModel.Inbox{
+		Source:    string(commonModel.FediverseSource),
+		Content:   content,
+		Type:      string(commonModel.NotificationInboxType),
+		Read:      false,
+		ReadCount: 0,
+		ReadAt:    0,
+		Meta:      string(meta),
+		CreatedAt: time.Now().Unix(),
+	})
+}
+
+// extractObjectID 从 Activity 的 object 字段中提取对象 ID，可能是字符串、对象或数组
+func extractObjectID(object any) string {
+	switch value := object.(type) {
+	case string:
+		return strings.TrimSpace(value)
+	case map[string]any:
+		return getStringFromMap(value, "id")
+	case []any:
+		for _, item := range value {
+			if id := extractObjectID(item); id != "" {
+				return id
+			}
+		}
+	}
+	return ""
+}
+
+// extractObjectType 从 Activity 的 object 字段中提取对象类型，object 为字符串时返回空
+func extractObjectType(object any) string {
+	switch value := object.(type) {
+	case map[string]any:
+		return getStringFromMap(value, "type")
+	case []any:
+		for _, item := range value {
+			if objectType := extractObjectType(item); objectType != "" {
+				return objectType
+			}
+		}
+	}
+	return ""
 }
@@ -0,0 +1,455 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/lin-snow/ech0/internal/database"
+	"github.com/lin-snow/ech0/internal/fediverse"
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	echoModel "github.com/lin-snow/ech0/internal/model/echo"
+	model "github.com/lin-snow/ech0/internal/model/fediverse"
+	inboxModel "github.com/lin-snow/ech0/internal/model/inbox"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
+	echoRepository "github.com/lin-snow/ech0/internal/repository/echo"
+	repository "github.com/lin-snow/ech0/internal/repository/fediverse"
+	inboxRepository "github.com/lin-snow/ech0/internal/repository/inbox"
+	keyvalueRepository "github.com/lin-snow/ech0/internal/repository/keyvalue"
+	userRepository "github.com/lin-snow/ech0/internal/repository/user"
+	"github.com/lin-snow/ech0/internal/transaction"
+	logUtil "github.com/lin-snow/ech0/internal/util/log"
+	"go.uber.org/zap"
+)
+
+const (
+	testServerURL = "https://ech0.example"
+	remoteActor   = "https://remote.example/users/bob"
+)
+
+// memoryCache 同步写入的内存缓存，避免 Ristretto 异步写入导致测试不稳定
+type memoryCache struct {
+	mu    sync.Mutex
+	items map[string]any
+}
+
+func (c *memoryCache) Set(key string, value any, _ int64) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.items[key] = value
+	return true
+}
+
+func (c *memoryCache) SetWithTTL(key string, value any, cost int64, _ time.Duration) bool {
+	return c.Set(key, value, cost)
+}
+
+func (c *memoryCache) Get(key string) (any, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	value, ok := c.items[key]
+	if !ok {
+		return nil, errors.New("key not found")
+	}
+	return value, nil
+}
+
+func (c *memoryCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.items, key)
+}
+
+func (c *memoryCache) GetOrSet(key string, cost int64, fn func() (any, error)) (any, error) {
+	if value, err := c.Get(key); err == nil {
+		return value, nil
+	}
+	value, err := fn()
+	if err != nil {
+		return nil, err
+	}
+	c.Set(key, value, cost)
+	return value, nil
+}
+
+// inboxFixture 基于临时 SQLite 数据库的 Inbox 测试环境
+type inboxFixture struct {
+	db      *gorm.DB
+	service *FediverseService
+	user    userModel.User
+	echo    echoModel.Echo
+}
+
+func newInboxFixture(t *testing.T) *inboxFixture {
+	t.Helper()
+	logUtil.Logger = zap.NewNop()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "ech0.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.AutoMigrate(database.Models()...); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	dbProvider := func() *gorm.DB { return db }
+	cache := &memoryCache{items: make(map[string]any)}
+
+	fedRepo := repository.NewFediverseRepository(dbProvider)
+	kvRepo := keyvalueRepository.NewKeyValueRepository(dbProvider, cache)
+	userRepo := userRepository.NewUserRepository(dbProvider, cache)
+	echoRepo := echoRepository.NewEchoRepository(dbProvider, cache)
+
+	if err := db.Create(&commonModel.KeyValue{
+		Key:   commonModel.SystemSettingsKey,
+		Value: fmt.Sprintf(`{"server_url":%q}`, testServerURL),
+	}).Error; err != nil {
+		t.Fatalf("save settings: %v", err)
+	}
+
+	f := &inboxFixture{db: db}
+	f.user = userModel.User{Username: "alice", Password: "x"}
+	if err := db.Create(&f.user).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	f.echo = echoModel.Echo{Content: "hello", UserID: f.user.ID, Visibility: echoModel.VisibilityPublic}
+	if err := db.Create(&f.echo).Error; err != nil {
+		t.Fatalf("create echo: %v", err)
+	}
+
+	f.service = NewFediverseService(
+		fediverse.NewFediverseCore(fedRepo, kvRepo, userRepo, echoRepo, cache),
+		transaction.NewTransactionManager(dbProvider),
+		fedRepo,
+		userRepo,
+		echoRepo,
+		inboxRepository.NewInboxRepository(dbProvider),
+	).(*FediverseService)
+	return f
+}
+
+func (f *inboxFixture) objectURL(id uint) string {
+	return fmt.Sprintf("%s/objects/%d", testServerURL, id)
+}
+
+// counts 返回 Echo 当前的点赞与转发数
+func (f *inboxFixture) counts(t *testing.T) (int, int) {
+	t.Helper()
+	var echo echoModel.Echo
+	if err := f.db.First(&echo, f.echo.ID).Error; err != nil {
+		t.Fatalf("load echo: %v", err)
+	}
+	return echo.FavCount, echo.AnnounceCount
+}
+
+func (f *inboxFixture) count(t *testing.T, value any) int64 {
+	t.Helper()
+	var n int64
+	if err := f.db.Model(value).Count(&n).Error; err != nil {
+		t.Fatalf("count: %v", err)
+	}
+	return n
+}
+
+func TestHandleInboxLikeAndAnnounce(t *testing.T) {
+	f := newInboxFixture(t)
+
+	like := &model.Activity{
+		ActivityID: remoteActor + "/likes/1",
+		Type:       model.ActivityTypeLike,
+		ActorURL:   remoteActor,
+		Object:     f.objectURL(f.echo.ID),
+	}
+	if err := f.service.HandleInbox("alice", like); err != nil {
+		t.Fatalf("like: %v", err)
+	}
+	// 重复投递不重复计数
+	if err := f.service.HandleInbox("alice", like); err != nil {
+		t.Fatalf("duplicate like: %v", err)
+	}
+
+	announce := &model.Activity{
+		ActivityID: remoteActor + "/announces/1",
+		Type:       model.ActivityTypeAnnounce,
+		ActorURL:   remoteActor,
+		Object:     map[string]any{"id": f.objectURL(f.echo.ID), "type": "Note"},
+	}
+	if err := f.service.HandleInbox("alice", announce); err != nil {
+		t.Fatalf("announce: %v", err)
+	}
+
+	if fav, ann := f.counts(t); fav != 1 || ann != 1 {
+		t.Errorf("counts = %d likes, %d announces, want 1 and 1", fav, ann)
+	}
+	if n := f.count(t, &model.Interaction{}); n != 2 {
+		t.Errorf("interactions = %d, want 2", n)
+	}
+	if n := f.count(t, &inboxModel.Inbox{}); n != 2 {
+		t.Errorf("notifications = %d, want 2", n)
+	}
+}
+
+func TestHandleInboxIgnoresForeignObjects(t *testing.T) {
+	f := newInboxFixture(t)
+
+	private := echoModel.Echo{Content: "secret", UserID: f.user.ID, Visibility: echoModel.VisibilityPrivate}
+	if err := f.db.Create(&private).Error; err != nil {
+		t.Fatalf("create echo: %v", err)
+	}
+
+	for name, object := range map[string]string{
+		"other server":  "https://other.example/objects/1",
+		"missing echo":  f.objectURL(9999),
+		"private echo":  f.objectURL(private.ID),
+		"malformed id":  testServerURL + "/objects/abc",
+		"not an object": testServerURL + "/users/alice",
+	} {
+		err := f.service.HandleInbox("alice", &model.Activity{
+			ActivityID: remoteActor + "/likes/" + name,
+			Type:       model.ActivityTypeLike,
+			ActorURL:   remoteActor,
+			Object:     object,
+		})
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+
+	if n := f.count(t, &model.Interaction{}); n != 0 {
+		t.Errorf("interactions = %d, want 0", n)
+	}
+}
+
+func TestHandleInboxUndoInteraction(t *testing.T) {
+	f := newInboxFixture(t)
+
+	like := &model.Activity{
+		ActivityID: remoteActor + "/likes/1",
+		Type:       model.ActivityTypeLike,
+		ActorURL:   remoteActor,
+		Object:     f.objectURL(f.echo.ID),
+	}
+	if err := f.service.HandleInbox("alice", like); err != nil {
+		t.Fatalf("like: %v", err)
+	}
+
+	// 其他 Actor 不能撤销别人的点赞
+	err := f.service.HandleInbox("alice", &model.Activity{
+		ActivityID: "https://evil.example/undo/1",
+		Type:       model.ActivityTypeUndo,
+		ActorURL:   "https://evil.example/users/mallory",
+		Object:     like.ActivityID,
+	})
+	if err == nil {
+		t.Error("expected undo from another actor to fail")
+	}
+	if fav, _ := f.counts(t); fav != 1 {
+		t.Fatalf("likes = %d after foreign undo, want 1", fav)
+	}
+
+	// 仅携带 Activity ID 的 Undo
+	if err := f.service.HandleInbox("alice", &model.Activity{
+		ActivityID: remoteActor + "/undo/1",
+		Type:       model.ActivityTypeUndo,
+		ActorURL:   remoteActor,
+		Object:     like.ActivityID,
+	}); err != nil {
+		t.Fatalf("undo: %v", err)
+	}
+	if fav, _ := f.counts(t); fav != 0 {
+		t.Errorf("likes = %d after undo, want 0", fav)
+	}
+
+	// Activity ID 对不上时按目标对象回退查找
+	announce := &model.Activity{
+		ActivityID: remoteActor + "/announces/1",
+		Type:       model.ActivityTypeAnnounce,
+		ActorURL:   remoteActor,
+		Object:     f.objectURL(f.echo.ID),
+	}
+	if err := f.service.HandleInbox("alice", announce); err != nil {
+		t.Fatalf("announce: %v", err)
+	}
+	if err := f.service.HandleInbox("alice", &model.Activity{
+		ActivityID: remoteActor + "/undo/2",
+		Type:       model.ActivityTypeUndo,
+		ActorURL:   remoteActor,
+		Object: map[string]any{
+			"id":     remoteActor + "/announces/other",
+			"type":   model.ActivityTypeAnnounce,
+			"object": f.objectURL(f.echo.ID),
+		},
+	}); err != nil {
+		t.Fatalf("undo announce: %v", err)
+	}
+	if _, ann := f.counts(t); ann != 0 {
+		t.Errorf("announces = %d after undo, want 0", ann)
+	}
+	if n := f.count(t, &model.Interaction{}); n != 0 {
+		t.Errorf("interactions = %d, want 0", n)
+	}
+}
+
+func TestHandleInboxUndoFollow(t *testing.T) {
+	f := newInboxFixture(t)
+
+	followID := remoteActor + "/follows/1"
+	if err := f.db.Create(&model.Follower{
+		ActorID:    remoteActor,
+		UserID:     f.user.ID,
+		ActivityID: followID,
+	}).Error; err != nil {
+		t.Fatalf("create follower: %v", err)
+	}
+
+	if err := f.service.HandleInbox("alice", &model.Activity{
+		ActivityID: remoteActor + "/undo/1",
+		Type:       model.ActivityTypeUndo,
+		ActorURL:   remoteActor,
+		Object:     followID,
+	}); err != nil {
+		t.Fatalf("undo follow: %v", err)
+	}
+	if n := f.count(t, &model.Follower{}); n != 0 {
+		t.Errorf("followers = %d, want 0", n)
+	}
+}
+
+func TestHandleInboxFollowResponses(t *testing.T) {
+	tests := map[string]struct {
+		activityType string
+		actor        string
+		want         string
+	}{
+		"accept":             {model.ActivityTypeAccept, remoteActor, model.FollowStatusAccepted},
+		"reject":             {model.ActivityTypeReject, remoteActor, model.FollowStatusRejected},
+		"accept by stranger": {model.ActivityTypeAccept, "https://evil.example/users/mallory", model.FollowStatusPending},
+		"reject by stranger": {model.ActivityTypeReject, "https://evil.example/users/mallory", model.FollowStatusPending},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			f := newInboxFixture(t)
+			followID := testServerURL + "/activities/follow-1"
+			follow := model.Follow{
+				UserID:     f.user.ID,
+				ActorID:    testServerURL + "/users/alice",
+				ObjectID:   remoteActor,
+				ActivityID: followID,
+				Status:     model.FollowStatusPending,
+			}
+			if err := f.db.Create(&follow).Error; err != nil {
+				t.Fatalf("create follow: %v", err)
+			}
+
+			if err := f.service.HandleInbox("alice", &model.Activity{
+				ActivityID: tt.actor + "#response",
+				Type:       tt.activityType,
+				ActorURL:   tt.actor,
+				Object: map[string]any{
+					"id":     followID,
+					"type":   model.ActivityTypeFollow,
+					"object": remoteActor,
+				},
+			}); err != nil {
+				t.Fatalf("handle: %v", err)
+			}
+
+			if err := f.db.First(&follow, follow.ID).Error; err != nil {
+				t.Fatalf("load follow: %v", err)
+			}
+			if follow.Status != tt.want {
+				t.Errorf("status = %q, want %q", follow.Status, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleInboxDeleteActor(t *testing.T) {
+	f := newInboxFixture(t)
+
+	if err := f.db.Create(&model.Follower{ActorID: remoteActor, UserID: f.user.ID}).Error; err != nil {
+		t.Fatalf("create follower: %v", err)
+	}
+	if err := f.db.Create(&model.InboxStatus{
+		UserID:      f.user.ID,
+		ActivityID:  remoteActor + "/statuses/1/activity",
+		ActorID:     remoteActor,
+		ObjectID:    remoteActor + "/statuses/1",
+		RawActivity: "{}",
+	}).Error; err != nil {
+		t.Fatalf("create status: %v", err)
+	}
+
+	// 只能删除自己的账号
+	if err := f.service.HandleInbox("alice", &model.Activity{
+		ActivityID: "https://evil.example/delete/1",
+		Type:       model.ActivityTypeDelete,
+		ActorURL:   "https://evil.example/users/mallory",
+		Object:     remoteActor,
+	}); err != nil {
+		t.Fatalf("foreign delete: %v", err)
+	}
+	if n := f.count(t, &model.Follower{}); n != 1 {
+		t.Fatalf("followers = %d after foreign delete, want 1", n)
+	}
+
+	if err := f.service.HandleInbox("alice", &model.Activity{
+		ActivityID: remoteActor + "#delete",
+		Type:       model.ActivityTypeDelete,
+		ActorURL:   remoteActor,
+		Object:     remoteActor,
+	}); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if n := f.count(t, &model.Follower{}); n != 0 {
+		t.Errorf("followers = %d, want 0", n)
+	}
+	if n := f.count(t, &model.InboxStatus{}); n != 0 {
+		t.Errorf("inbox statuses = %d, want 0", n)
+	}
+}
+
+func TestHandleInboxRejectsInvalidActivities(t *testing.T) {
+	f := newInboxFixture(t)
+
+	tests := map[string]struct {
+		username string
+		activity *model.Activity
+	}{
+		"unknown user": {
+			username: "nobody",
+			activity: &model.Activity{Type: model.ActivityTypeLike, ActorURL: remoteActor},
+		},
+		"unsupported type": {
+			username: "alice",
+			activity: &model.Activity{Type: "Move", ActorURL: remoteActor},
+		},
+		"like without id": {
+			username: "alice",
+			activity: &model.Activity{Type: model.ActivityTypeLike, ActorURL: remoteActor, Object: f.objectURL(f.echo.ID)},
+		},
+		"undo without actor": {
+			username: "alice",
+			activity: &model.Activity{Type: model.ActivityTypeUndo, Object: remoteActor + "/likes/1"},
+		},
+		"delete without object": {
+			username: "alice",
+			activity: &model.Activity{Type: model.ActivityTypeDelete, ActorURL: remoteActor},
+		},
+	}
+
+	for name, tt := range tests {
+		if err := f.service.HandleInbox(tt.username, tt.activity); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/lin-snow/ech0/internal/fediverse"
+	model "github.com/lin-snow/ech0/internal/model/fediverse"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
+)
+
+//=======================================
+//	处理 Like / Announce
+//=======================================
+
+// handleLikeActivity 处理远端对本地 Echo 的点赞
+func (fediverseService *FediverseService) handleLikeActivity(
+	user *userModel.User,
+	activity *model.Activity,
+) error {
+	return fediverseService.handleInteractionActivity(user, activity)
+}
+
+// handleAnnounceActivity 处理远端对本地 Echo 的转发
+func (fediverseService *FediverseService) handleAnnounceActivity(
+	user *userModel.User,
+	activity *model.Activity,
+) error {
+	return fediverseService.handleInteractionActivity(user, activity)
+}
+
+// handleInteractionActivity 记录互动并更新 Echo 计数，非本地对象的互动直接忽略
+func (fediverseService *FediverseService) handleInteractionActivity(
+	user *userModel.User,
+	activity *model.Activity,
+) error {
+	if activity.ActorURL == "" || activity.ActivityID == "" {
+		return fmt.Errorf("%s activity missing actor or id", activity.Type)
+	}
+
+	echoID, ok, err := fediverseService.resolveLocalEcho(user, extractObjectID(activity.Object))
+	if err != nil || !ok {
+		return err
+	}
+
+	return fediverseService.txManager.Run(func(ctx context.Context) error {
+		// 同一 Actor 对同一 Echo 的重复互动只记录一次
+		existing, err := fediverseService.fediverseRepository.GetInteraction(
+			ctx,
+			echoID,
+			activity.ActorURL,
+			activity.Type,
+		)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			return nil
+		}
+
+		if err := fediverseService.fediverseRepository.SaveInteraction(ctx, &model.Interaction{
+			UserID:     user.ID,
+			EchoID:     echoID,
+			ActorID:    activity.ActorURL,
+			ActivityID: activity.ActivityID,
+			Type:       activity.Type,
+		}); err != nil {
+			return err
+		}
+
+		var content string
+		switch activity.Type {
+		case model.ActivityTypeLike:
+			if err := fediverseService.echoRepository.LikeEcho(ctx, echoID); err != nil {
+				return err
+			}
+			content = fmt.Sprintf("%s 赞了你的 Echo #%d", activity.ActorURL, echoID)
+		case model.ActivityTypeAnnounce:
+			if err := fediverseService.echoRepository.AnnounceEcho(ctx, echoID); err != nil {
+				return err
+			}
+			content = fmt.Sprintf("%s 转发了你的 Echo #%d", activity.ActorURL, echoID)
+		default:
+			return errors.New("unsupported interaction type: " + activity.Type)
+		}
+
+		return fediverseService.postInboxNotification(ctx, content, activity)
+	})
+}
+
+// undoInteraction 撤销互动记录并回滚 Echo 计数
+func (fediverseService *FediverseService) undoInteraction(
+	ctx context.Context,
+	interaction *model.Interaction,
+) error {
+	if err := fediverseService.fediverseRepository.DeleteInteraction(ctx, interaction.ID); err != nil {
+		return err
+	}
+
+	switch interaction.Type {
+	case model.ActivityTypeLike:
+		return fediverseService.echoRepository.UnlikeEcho(ctx, interaction.EchoID)
+	case model.ActivityTypeAnnounce:
+		return fediverseService.echoRepository.UnannounceEcho(ctx, interaction.EchoID)
+	}
+	return nil
+}
+
+// resolveLocalEcho 判断对象是否为该用户的本地 Echo，并返回 Echo ID
+func (fediverseService *FediverseService) resolveLocalEcho(
+	user *userModel.User,
+	objectID string,
+) (uint, bool, error) {
+	if objectID == "" {
+		return 0, false, nil
+	}
+
+	// BuildActor 会改写头像字段，这里传入副本
+	userCopy := *user
+	_, setting, err := fediverseService.core.BuildActor(&userCopy)
+	if err != nil {
+		return 0, false, err
+	}
+	serverURL, err := fediverse.NormalizeServerURL(setting.ServerURL)
+	if err != nil {
+		return 0, false, err
+	}
+
+	echoID, ok := fediverse.ResolveLocalEchoID(objectID, serverURL)
+	if !ok {
+		return 0, false, nil
+	}
+
+	echo, err := fediverseService.echoRepository.GetEchosById(echoID)
+	if err != nil || echo == nil || !echo.IsFederated() || !echo.IsPublished() || echo.UserID != user.ID {
+		return 0, false, nil
+	}
+
+	return echoID, true, nil
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"net/http"
 
 	model "github.com/lin-snow/ech0/internal/model/fediverse"
 )
@@ -13,6 +14,9 @@ type FediverseServiceInterface interface {
 	// GetActorByUsername 通过用户名获取 Actor 信息
 	GetActorByUsername(username string) (model.Actor, error)
 
+	// VerifyInboxSignature 校验 Inbox 请求的 HTTP 签名
+	VerifyInboxSignature(req *http.Request, body []byte, activity *model.Activity) error
+
 	// HandleInbox 处理接收到的 ActivityPub 消息
 	HandleInbox(username string, activity *model.Activity) error
 
@@ -26,6 +30,9 @@ type FediverseServiceInterface interface {
 		page, pageSize int,
 	) (model.OutboxPage, error)
 
+	// HandleFeatured 构建置顶帖子集合
+	HandleFeatured(username string) (model.FeaturedResponse, error)
+
 	// GetFollowers 获取粉丝列表
 	GetFollowers(username string) (model.FollowersResponse, error)
 
@@ -41,6 +48,9 @@ type FediverseServiceInterface interface {
 	// GetObjectByID 通过 ID 获取内容对象
 	GetObjectByID(id uint) (model.Object, error)
 
+	// GetTombstoneByID 获取已删除 Echo 的 Tombstone
+	GetTombstoneByID(id uint) (model.Tombstone, error)
+
 	// GetTimeline 获取关注人的时间线
 	// GetTimeline(userID uint, page, pageSize int) (commonModel.PageQueryResult[[]model.TimelineItem], error)
 
@@ -1,7 +1,12 @@
 package service
 
 import (
+	"errors"
+	"fmt"
+
 	"github.com/lin-snow/ech0/internal/fediverse"
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	echoModel "github.com/lin-snow/ech0/internal/model/echo"
 	model "github.com/lin-snow/ech0/internal/model/fediverse"
 )
 
@@ -9,9 +14,24 @@ import (
 func (fediverseService *FediverseService) GetObjectByID(id uint) (model.Object, error) {
 	// 获取 Echo
 	echo, err := fediverseService.echoRepository.GetEchosById(id)
-	if err != nil || echo.Private {
+	if err != nil {
 		return model.Object{}, err
 	}
+	if echo == nil {
+		// 已删除的 Echo 返回 Tombstone
+		tombstone, err := fediverseService.fediverseRepository.GetTombstoneByEchoID(id)
+		if err != nil {
+			return model.Object{}, err
+		}
+		if tombstone != nil {
+			return model.Object{}, errors.New(commonModel.OBJECT_DELETED)
+		}
+		return model.Object{}, errors.New(commonModel.OBJECT_NOT_FOUND)
+	}
+	// 仅关注者可见的 Echo 无法确认请求方身份，与不推送的 Echo 一样不对外提供
+	if !echoModel.CanView(echoModel.AudienceGuest, echo.Visibility) || !echo.IsPublished() {
+		return model.Object{}, errors.New(commonModel.OBJECT_NOT_FOUND)
+	}
 
 	// 获取 Actor 和 setting
 	user, err := fediverseService.userRepository.GetUserByUsername(echo.Username)
@@ -30,3 +50,32 @@ func (fediverseService *FediverseService) GetObjectByID(id uint) (model.Object,
 	// 转 Object
 	return fediverseService.core.ConvertEchoToObject(echo, &actor, serverURL), nil
 }
+
+// GetTombstoneByID 获取已删除 Echo 的 Tombstone
+func (fediverseService *FediverseService) GetTombstoneByID(id uint) (model.Tombstone, error) {
+	tombstone, err := fediverseService.fediverseRepository.GetTombstoneByEchoID(id)
+	if err != nil {
+		return model.Tombstone{}, err
+	}
+	if tombstone == nil {
+		return model.Tombstone{}, errors.New(commonModel.OBJECT_NOT_FOUND)
+	}
+
+	// 按当前服务器地址生成对象 URL
+	settingUser, err := fediverseService.userRepository.GetSysAdmin()
+	if err != nil {
+		return model.Tombstone{}, err
+	}
+	_, setting, err := fediverseService.core.BuildActor(&settingUser)
+	if err != nil {
+		return model.Tombstone{}, err
+	}
+	serverURL, err := fediverse.NormalizeServerURL(setting.ServerURL)
+	if err != nil {
+		return model.Tombstone{}, err
+	}
+
+	tombstone.Context = "https://www.w3.org/ns/activitystreams"
+	tombstone.ObjectID = fmt.Sprintf("%s/objects/%d", serverURL, id)
+	return *tombstone, nil
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	echoModel "github.com/lin-snow/ech0/internal/model/echo"
+	model "github.com/lin-snow/ech0/internal/model/fediverse"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
+)
+
+func TestGetObjectByID(t *testing.T) {
+	f := newInboxFixture(t)
+	if err := f.db.Model(&f.echo).Update("username", f.user.Username).Error; err != nil {
+		t.Fatalf("update echo: %v", err)
+	}
+
+	object, err := f.service.GetObjectByID(f.echo.ID)
+	if err != nil {
+		t.Fatalf("get object: %v", err)
+	}
+	if object.ObjectID != f.objectURL(f.echo.ID) || object.Type != "Note" {
+		t.Errorf("object = %s %s", object.ObjectID, object.Type)
+	}
+
+	// 不对外推送的 Echo 与不存在的 Echo 一样返回 404
+	tests := map[string]echoModel.Echo{
+		"private":   {Content: "secret", UserID: f.user.ID, Visibility: echoModel.VisibilityPrivate},
+		"members":   {Content: "members", UserID: f.user.ID, Visibility: echoModel.VisibilityMembers},
+		"followers": {Content: "followers", UserID: f.user.ID, Visibility: echoModel.VisibilityFollowers},
+		"draft":     {Content: "draft", UserID: f.user.ID, Visibility: echoModel.VisibilityPublic, Status: echoModel.EchoStatusDraft},
+	}
+	for name, echo := range tests {
+		if err := f.db.Create(&echo).Error; err != nil {
+			t.Fatalf("%s: create echo: %v", name, err)
+		}
+		if _, err := f.service.GetObjectByID(echo.ID); err == nil || err.Error() != commonModel.OBJECT_NOT_FOUND {
+			t.Errorf("%s: err = %v, want %s", name, err, commonModel.OBJECT_NOT_FOUND)
+		}
+	}
+	if _, err := f.service.GetObjectByID(9999); err == nil || err.Error() != commonModel.OBJECT_NOT_FOUND {
+		t.Errorf("missing: err = %v", err)
+	}
+}
+
+func TestGetObjectByIDReturnsTombstone(t *testing.T) {
+	f := newInboxFixture(t)
+	f.user.SetRole(userModel.RoleOwner)
+	if err := f.db.Save(&f.user).Error; err != nil {
+		t.Fatalf("update user: %v", err)
+	}
+
+	deleted := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := f.service.fediverseRepository.SaveTombstone(context.Background(), &model.Tombstone{
+		Type:       model.ObjectTypeTombstone,
+		FormerType: "Note",
+		EchoID:     f.echo.ID,
+		Deleted:    deleted,
+	}); err != nil {
+		t.Fatalf("save tombstone: %v", err)
+	}
+	if err := f.db.Unscoped().Delete(&echoModel.Echo{}, f.echo.ID).Error; err != nil {
+		t.Fatalf("delete echo: %v", err)
+	}
+
+	// 删除后的对象返回 410，由 Tombstone 说明删除时间
+	if _, err := f.service.GetObjectByID(f.echo.ID); err == nil || err.Error() != commonModel.OBJECT_DELETED {
+		t.Fatalf("err = %v, want %s", err, commonModel.OBJECT_DELETED)
+	}
+	tombstone, err := f.service.GetTombstoneByID(f.echo.ID)
+	if err != nil {
+		t.Fatalf("get tombstone: %v", err)
+	}
+	if tombstone.ObjectID != f.objectURL(f.echo.ID) ||
+		tombstone.Type != model.ObjectTypeTombstone ||
+		!tombstone.Deleted.Equal(deleted) {
+		t.Errorf("tombstone = %+v", tombstone)
+	}
+
+	if _, err := f.service.GetTombstoneByID(9999); err == nil || err.Error() != commonModel.OBJECT_NOT_FOUND {
+		t.Errorf("missing tombstone: err = %v", err)
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/lin-snow/ech0/internal/fediverse"
 	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	echoModel "github.com/lin-snow/ech0/internal/model/echo"
 	model "github.com/lin-snow/ech0/internal/model/fediverse"
 )
 
@@ -32,8 +33,13 @@ func (fediverseService *FediverseService) HandleOutboxPage(
 		return model.OutboxPage{}, err
 	}
 
-	// 查 Echos
-	echosByPage, total := fediverseService.echoRepository.GetEchosByPage(page, pageSize, "", false)
+	// 查 Echos，只包含无需登录即可访问的 Echo
+	echosByPage, total := fediverseService.echoRepository.GetEchosByPage(
+		page,
+		pageSize,
+		"",
+		echoModel.ReachableVisibilities(),
+	)
 
 	// 转 Avtivity
 	var activities []model.Activity
@@ -76,3 +82,10 @@ func (fediverseService *FediverseService) HandleOutbox(
 	}
 	return outbox, nil
 }
+
+// HandleFeatured 构建置顶帖子集合
+func (fediverseService *FediverseService) HandleFeatured(
+	username string,
+) (model.FeaturedResponse, error) {
+	return fediverseService.core.BuildFeatured(username)
+}
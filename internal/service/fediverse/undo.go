@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	model "github.com/lin-snow/ech0/internal/model/fediverse"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
+)
+
+//=======================================
+//	处理 Undo
+//=======================================
+
+// handleUndoActivity 处理远端的撤销活动 (Undo{Follow}、Undo{Like}、Undo{Announce})
+func (fediverseService *FediverseService) handleUndoActivity(
+	user *userModel.User,
+	activity *model.Activity,
+) error {
+	if activity.ActorURL == "" {
+		return errors.New("undo activity missing actor")
+	}
+
+	undoneID := extractObjectID(activity.Object)
+	undoneType := extractObjectType(activity.Object)
+
+	// object 仅为 Activity ID 时，需确认被撤销的活动类型，可能需要请求远端，因此在事务外进行
+	if undoneType == "" && undoneID != "" {
+		resolved, err := fediverseService.resolveUndoneType(user, activity, undoneID)
+		if err != nil {
+			return err
+		}
+		undoneType = resolved
+	}
+
+	return fediverseService.txManager.Run(func(ctx context.Context) error {
+		// 优先按被撤销的 Activity ID 查找互动记录
+		if undoneID != "" {
+			interaction, err := fediverseService.fediverseRepository.GetInteractionByActivityID(ctx, undoneID)
+			if err != nil {
+				return err
+			}
+			if interaction != nil {
+				if interaction.ActorID != activity.ActorURL || interaction.UserID != user.ID {
+					return errors.New("undo actor does not match original activity")
+				}
+				if err := fediverseService.undoInteraction(ctx, interaction); err != nil {
+					return err
+				}
+				return fediverseService.postInboxNotification(
+					ctx,
+					fmt.Sprintf("%s 撤销了对 Echo #%d 的%s", activity.ActorURL, interaction.EchoID, interactionLabel(interaction.Type)),
+					activity,
+				)
+			}
+		}
+
+		switch undoneType {
+		case model.ActivityTypeFollow:
+			return fediverseService.undoFollow(ctx, user, activity)
+		case model.ActivityTypeLike, model.ActivityTypeAnnounce:
+			// 部分实现的 Activity ID 与记录不一致，退回按目标对象查找
+			objectMap, _ := activity.Object.(map[string]any)
+			echoID, ok, err := fediverseService.resolveLocalEcho(user, extractObjectID(objectMap["object"]))
+			if err != nil || !ok {
+				return err
+			}
+			interaction, err := fediverseService.fediverseRepository.GetInteraction(
+				ctx,
+				echoID,
+				activity.ActorURL,
+				undoneType,
+			)
+			if err != nil || interaction == nil {
+				return err
+			}
+			if err := fediverseService.undoInteraction(ctx, interaction); err != nil {
+				return err
+			}
+			return fediverseService.postInboxNotification(
+				ctx,
+				fmt.Sprintf("%s 撤销了对 Echo #%d 的%s", activity.ActorURL, echoID, interactionLabel(undoneType)),
+				activity,
+			)
+		}
+
+		return nil
+	})
+}
+
+// resolveUndoneType 确定仅以 ID 引用的被撤销活动的类型
+// 先匹配本地保存的互动与 Follow 活动 ID，再向发起者所在的实例拉取该活动，且活动的发起者必须一致
+func (fediverseService *FediverseService) resolveUndoneType(
+	user *userModel.User,
+	activity *model.Activity,
+	undoneID string,
+) (string, error) {
+	ctx := context.Background()
+	interaction, err := fediverseService.fediverseRepository.GetInteractionByActivityID(ctx, undoneID)
+	if err != nil {
+		return "", err
+	}
+	if interaction != nil {
+		return interaction.Type, nil
+	}
+
+	followed, err := fediverseService.fediverseRepository.FollowerExistsByActivityID(
+		ctx,
+		user.ID,
+		activity.ActorURL,
+		undoneID,
+	)
+	if err != nil {
+		return "", err
+	}
+	if followed {
+		return model.ActivityTypeFollow, nil
+	}
+
+	object, err := fediverseService.core.FetchRemoteObject(undoneID, activity.ActorURL)
+	if err != nil {
+		return "", fmt.Errorf("resolve undone activity %s: %w", undoneID, err)
+	}
+	if extractObjectID(object["actor"]) != activity.ActorURL {
+		return "", errors.New("undo actor does not match original activity")
+	}
+	return getStringFromMap(object, "type"), nil
+}
+
+// undoFollow 移除远端 Actor 的粉丝记录
+func (fediverseService *FediverseService) undoFollow(
+	ctx context.Context,
+	user *userModel.User,
+	activity *model.Activity,
+) error {
+	exists, err := fediverseService.fediverseRepository.FollowerExists(ctx, user.ID, activity.ActorURL)
+	if err != nil || !exists {
+		return err
+	}
+
+	if err := fediverseService.fediverseRepository.DeleteFollower(ctx, user.ID, activity.ActorURL); err != nil {
+		return err
+	}
+
+	return fediverseService.postInboxNotification(
+		ctx,
+		fmt.Sprintf("%s 取消了对你的关注", activity.ActorURL),
+		activity,
+	)
+}
+
+// interactionLabel 返回互动类型的中文描述
+func interactionLabel(interactionType string) string {
+	switch interactionType {
+	case model.ActivityTypeLike:
+		return "点赞"
+	case model.ActivityTypeAnnounce:
+		return "转发"
+	}
+	return interactionType
+}
@@ -0,0 +1,491 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lin-snow/ech0/internal/authz"
+	"github.com/lin-snow/ech0/internal/importer"
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	echoModel "github.com/lin-snow/ech0/internal/model/echo"
+	model "github.com/lin-snow/ech0/internal/model/importer"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
+	echoRepository "github.com/lin-snow/ech0/internal/repository/echo"
+	commonService "github.com/lin-snow/ech0/internal/service/common"
+	echoService "github.com/lin-snow/ech0/internal/service/echo"
+	"github.com/lin-snow/ech0/internal/transaction"
+	logUtil "github.com/lin-snow/ech0/internal/util/log"
+	"go.uber.org/zap"
+)
+
+type ImportService struct {
+	txManager      transaction.TransactionManager
+	commonService  commonService.CommonServiceInterface
+	echoService    echoService.EchoServiceInterface
+	echoRepository echoRepository.EchoRepositoryInterface
+
+	jobsMu   sync.Mutex
+	jobs     map[string]model.ImportProgress // 导入任务的最新进度
+	finished []string                        // 已结束的任务 ID，按结束顺序排列
+	running  string                          // 正在进行的任务 ID
+}
+
+func NewImportService(
+	tm transaction.TransactionManager,
+	commonSvc commonService.CommonServiceInterface,
+	echoSvc echoService.EchoServiceInterface,
+	echoRepo echoRepository.EchoRepositoryInterface,
+) ImportServiceInterface {
+	return &ImportService{
+		txManager:      tm,
+		commonService:  commonSvc,
+		echoService:    echoSvc,
+		echoRepository: echoRepo,
+		jobs:           make(map[string]model.ImportProgress),
+	}
+}
+
+// Import 同步导入本地的导出文件或目录
+func (importService *ImportService) Import(
+	userId uint,
+	path string,
+	options model.ImportOptions,
+	progress func(model.ImportProgress),
+) (model.ImportProgress, error) {
+	user, err := importService.prepareImport(userId, &options)
+	if err != nil {
+		return model.ImportProgress{}, err
+	}
+
+	id, err := newImportJobID()
+	if err != nil {
+		return model.ImportProgress{}, err
+	}
+	result := newImportProgress(id, options)
+	err = importService.runImport(user, path, options, &result, progress)
+	return result, err
+}
+
+// StartImport 保存上传的导出文件并在后台开始导入，同一时间只允许一个导入任务
+func (importService *ImportService) StartImport(
+	ctx *gin.Context,
+	userId uint,
+	options model.ImportOptions,
+	file *multipart.FileHeader,
+) (model.ImportProgress, error) {
+	user, err := importService.prepareImport(userId, &options)
+	if err != nil {
+		return model.ImportProgress{}, err
+	}
+
+	id, err := newImportJobID()
+	if err != nil {
+		return model.ImportProgress{}, err
+	}
+
+	importService.jobsMu.Lock()
+	if importService.running != "" {
+		importService.jobsMu.Unlock()
+		return model.ImportProgress{}, errors.New(commonModel.IMPORT_ALREADY_RUNNING)
+	}
+	importService.running = id
+	importService.jobsMu.Unlock()
+
+	// 保存上传的文件到临时位置, (./temp/import_时间戳/文件名)
+	uploadDir := fmt.Sprintf("./temp/import_%d", time.Now().UnixNano())
+	uploadPath := filepath.Join(uploadDir, filepath.Base(file.Filename))
+	if err := ctx.SaveUploadedFile(file, uploadPath); err != nil {
+		_ = os.RemoveAll(uploadDir)
+		importService.jobsMu.Lock()
+		importService.running = ""
+		importService.jobsMu.Unlock()
+		return model.ImportProgress{}, errors.New(commonModel.IMPORT_UPLOAD_FAILED + ": " + err.Error())
+	}
+
+	progress := newImportProgress(id, options)
+	importService.saveJob(progress)
+
+	go func() {
+		defer func() {
+			if err := os.RemoveAll(uploadDir); err != nil {
+				logUtil.GetLogger().Error("Failed to remove import upload", zap.String("error", err.Error()))
+			}
+		}()
+
+		if err := importService.runImport(user, uploadPath, options, &progress, importService.saveJob); err != nil {
+			logUtil.GetLogger().Error("Import failed", zap.String("id", id), zap.String("error", err.Error()))
+		}
+		importService.finishJob(progress)
+	}()
+
+	return progress, nil
+}
+
+// GetImportJob 获取导入任务的进度
+func (importService *ImportService) GetImportJob(userId uint, id string) (model.ImportProgress, error) {
+	if _, err := importService.authorize(userId); err != nil {
+		return model.ImportProgress{}, err
+	}
+
+	importService.jobsMu.Lock()
+	defer importService.jobsMu.Unlock()
+
+	progress, ok := importService.jobs[id]
+	if !ok {
+		return model.ImportProgress{}, errors.New(commonModel.IMPORT_JOB_NOT_FOUND)
+	}
+	return progress, nil
+}
+
+// prepareImport 检查权限并校验导入选项
+func (importService *ImportService) prepareImport(
+	userId uint,
+	options *model.ImportOptions,
+) (userModel.User, error) {
+	user, err := importService.authorize(userId)
+	if err != nil {
+		return userModel.User{}, err
+	}
+
+	options.Source = strings.ToLower(strings.TrimSpace(options.Source))
+	if _, ok := importer.GetSource(options.Source); !ok {
+		return userModel.User{}, errors.New(commonModel.IMPORT_SOURCE_INVALID)
+	}
+
+	options.Storage = strings.ToLower(strings.TrimSpace(options.Storage))
+	switch commonModel.FileStorageType(options.Storage) {
+	case "":
+		options.Storage = string(commonModel.LOCAL_FILE)
+	case commonModel.LOCAL_FILE, commonModel.S3_FILE:
+	default:
+		return userModel.User{}, errors.New(commonModel.IMPORT_STORAGE_INVALID)
+	}
+
+	options.Visibility = strings.TrimSpace(options.Visibility)
+	if options.Visibility != "" && !echoModel.IsValidVisibility(options.Visibility) {
+		return userModel.User{}, errors.New(commonModel.INVALID_ECHO_VISIBILITY)
+	}
+
+	options.BaseURL = strings.TrimRight(strings.TrimSpace(options.BaseURL), "/")
+	return user, nil
+}
+
+// runImport 解析导出数据并逐条导入，结束时更新任务状态
+func (importService *ImportService) runImport(
+	user userModel.User,
+	dataPath string,
+	options model.ImportOptions,
+	progress *model.ImportProgress,
+	report func(model.ImportProgress),
+) (err error) {
+	notify := func() {
+		if report != nil {
+			snapshot := *progress
+			snapshot.Errors = slices.Clone(progress.Errors)
+			report(snapshot)
+		}
+	}
+	defer func() {
+		now := time.Now()
+		progress.FinishedAt = &now
+		progress.Status = model.ImportStatusCompleted
+		if err != nil {
+			progress.Status = model.ImportStatusFailed
+			progress.AddError(err.Error())
+		}
+		notify()
+	}()
+
+	// 归档解压到临时目录，导入结束后删除
+	if err := os.MkdirAll("./temp", 0o755); err != nil {
+		return err
+	}
+	workDir, err := os.MkdirTemp("./temp", "import_work_")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := os.RemoveAll(workDir); err != nil {
+			logUtil.GetLogger().Error("Failed to remove import work dir", zap.String("error", err.Error()))
+		}
+	}()
+
+	input, err := importer.PrepareInput(dataPath, workDir)
+	if err != nil {
+		return err
+	}
+	items, err := importer.Parse(options.Source, input, importer.Options{BaseURL: options.BaseURL})
+	if err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		return errors.New(commonModel.IMPORT_DATA_NOT_FOUND)
+	}
+
+	progress.Total = len(items)
+	notify()
+
+	// 来源中的 ID 与 Echo ID 的映射，用于还原回复关系
+	imported := make(map[string]uint)
+	for _, item := range items {
+		importService.importItem(user, item, options, imported, progress)
+		progress.Processed++
+		notify()
+	}
+
+	return nil
+}
+
+// importItem 导入一条内容，回复他人的内容与已导入过的内容会被跳过
+func (importService *ImportService) importItem(
+	user userModel.User,
+	item importer.Item,
+	options model.ImportOptions,
+	imported map[string]uint,
+	progress *model.ImportProgress,
+) {
+	content := strings.TrimSpace(item.Content)
+	if content == "" && len(item.Media) == 0 {
+		progress.Skipped++
+		return
+	}
+
+	var replyToID uint
+	if item.ReplyTo != "" {
+		id, ok := imported[item.ReplyTo]
+		if !ok {
+			progress.Skipped++
+			return
+		}
+		replyToID = id
+	}
+
+	createdAt := item.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+	createdAt = createdAt.Local()
+
+	// 已导入过的内容只记录映射，保证重复导入时回复关系不丢失
+	existingID, err := importService.echoRepository.FindEchoIdByCreatedAt(
+		context.Background(),
+		user.ID,
+		createdAt,
+		content,
+	)
+	if err != nil {
+		progress.Failed++
+		progress.AddError(fmt.Sprintf("%s: %v", item.SourceID, err))
+		return
+	}
+	if existingID != 0 {
+		imported[item.SourceID] = existingID
+		progress.Skipped++
+		return
+	}
+
+	visibility := item.Visibility
+	if options.Visibility != "" {
+		visibility = options.Visibility
+	}
+	if !echoModel.IsValidVisibility(visibility) {
+		visibility = echoModel.VisibilityPublic
+	}
+
+	if options.DryRun {
+		imported[item.SourceID] = 0
+		progress.Imported++
+		progress.MediaImported += len(item.Media)
+		return
+	}
+
+	images := importService.saveMedia(user.ID, item, commonModel.FileStorageType(options.Storage), progress)
+	if content == "" && len(images) == 0 {
+		progress.Failed++
+		return
+	}
+
+	tags := make([]echoModel.Tag, 0, len(item.Tags))
+	for _, name := range item.Tags {
+		tags = append(tags, echoModel.Tag{Name: name})
+	}
+
+	echo := echoModel.Echo{
+		Content:    content,
+		Username:   user.Username,
+		UserID:     user.ID,
+		Images:     images,
+		Layout:     echoModel.LayoutWaterfall,
+		Visibility: visibility,
+		Status:     echoModel.EchoStatusPublished,
+		Tags:       tags,
+		ReplyToID:  replyToID,
+		CreatedAt:  createdAt,
+	}
+	if err := importService.txManager.Run(func(ctx context.Context) error {
+		if err := importService.echoService.ProcessEchoTags(ctx, &echo); err != nil {
+			return err
+		}
+		return importService.echoRepository.CreateEcho(ctx, &echo)
+	}); err != nil {
+		for _, image := range images {
+			_ = importService.commonService.DirectDeleteImage(image.ImageURL, image.ImageSource, image.ObjectKey)
+		}
+		progress.Failed++
+		progress.AddError(fmt.Sprintf("%s: %v", item.SourceID, err))
+		return
+	}
+
+	imported[item.SourceID] = echo.ID
+	progress.Imported++
+
+	// 导入的内容不推送到联邦网络，只更新搜索索引
+	if err := importService.echoRepository.IndexEcho(context.Background(), echo.ID); err != nil {
+		logUtil.GetLogger().Error("Failed to index imported echo", zap.String("error", err.Error()))
+	}
+}
+
+// saveMedia 保存内容附带的媒体文件，失败的文件会记录错误并跳过
+func (importService *ImportService) saveMedia(
+	userId uint,
+	item importer.Item,
+	storage commonModel.FileStorageType,
+	progress *model.ImportProgress,
+) []echoModel.Image {
+	var images []echoModel.Image
+	for _, media := range item.Media {
+		image, err := importService.saveMediaFile(userId, media, storage)
+		if err != nil {
+			progress.MediaFailed++
+			progress.AddError(fmt.Sprintf("%s: %s: %v", item.SourceID, media.Filename, err))
+			continue
+		}
+		images = append(images, image)
+		progress.MediaImported++
+	}
+	return images
+}
+
+// saveMediaFile 打开并保存单个媒体文件
+func (importService *ImportService) saveMediaFile(
+	userId uint,
+	media importer.Media,
+	storage commonModel.FileStorageType,
+) (echoModel.Image, error) {
+	reader, err := media.Open()
+	if err != nil {
+		return echoModel.Image{}, err
+	}
+	defer reader.Close()
+
+	// 远程地址可能带有查询参数，只保留文件名部分
+	filename, _, _ := strings.Cut(media.Filename, "?")
+	filename = path.Base(filename)
+
+	// 导入的文件名与远程地址都不可信，按文件内容判断类型，并替换为与内容一致的扩展名
+	buffered := bufio.NewReaderSize(reader, sniffLen)
+	head, err := buffered.Peek(sniffLen)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return echoModel.Image{}, err
+	}
+	filename, err = sniffedFilename(filename, head)
+	if err != nil {
+		return echoModel.Image{}, err
+	}
+
+	return importService.commonService.SaveImage(userId, filename, buffered, storage)
+}
+
+// sniffLen 判断文件类型时读取的字节数，与 http.DetectContentType 一致
+const sniffLen = 512
+
+// sniffedFilename 根据文件内容判断类型，扩展名与内容不一致时替换为内容对应的扩展名，
+// 无法识别为图片或视频的内容返回错误
+func sniffedFilename(filename string, head []byte) (string, error) {
+	contentType, _, _ := strings.Cut(http.DetectContentType(head), ";")
+	if !strings.HasPrefix(contentType, "image/") && !strings.HasPrefix(contentType, "video/") {
+		return "", errors.New(commonModel.FILE_TYPE_NOT_ALLOWED)
+	}
+
+	ext := path.Ext(filename)
+	if byExt, _, _ := strings.Cut(mime.TypeByExtension(strings.ToLower(ext)), ";"); byExt == contentType {
+		return filename, nil
+	}
+
+	exts, _ := mime.ExtensionsByType(contentType)
+	if len(exts) == 0 {
+		return "", errors.New(commonModel.FILE_TYPE_NOT_ALLOWED)
+	}
+	return strings.TrimSuffix(filename, ext) + exts[0], nil
+}
+
+// saveJob 保存导入任务的最新进度
+func (importService *ImportService) saveJob(progress model.ImportProgress) {
+	importService.jobsMu.Lock()
+	defer importService.jobsMu.Unlock()
+	importService.jobs[progress.ID] = progress
+}
+
+// finishJob 结束导入任务，只保留最近 MaxFinishedJobs 个已结束的任务
+func (importService *ImportService) finishJob(progress model.ImportProgress) {
+	importService.jobsMu.Lock()
+	defer importService.jobsMu.Unlock()
+
+	importService.jobs[progress.ID] = progress
+	importService.finished = append(importService.finished, progress.ID)
+	for len(importService.finished) > model.MaxFinishedJobs {
+		delete(importService.jobs, importService.finished[0])
+		importService.finished = importService.finished[1:]
+	}
+	if importService.running == progress.ID {
+		importService.running = ""
+	}
+}
+
+// authorize 检查用户是否拥有导入内容的权限
+func (importService *ImportService) authorize(userId uint) (userModel.User, error) {
+	user, err := importService.commonService.CommonGetUserByUserId(userId)
+	if err != nil {
+		return userModel.User{}, err
+	}
+	if err := authz.Authorize(user, userModel.PermissionContentImport); err != nil {
+		return userModel.User{}, err
+	}
+	return user, nil
+}
+
+// newImportProgress 创建导入任务的初始进度
+func newImportProgress(id string, options model.ImportOptions) model.ImportProgress {
+	return model.ImportProgress{
+		ID:        id,
+		Source:    options.Source,
+		DryRun:    options.DryRun,
+		Status:    model.ImportStatusRunning,
+		StartedAt: time.Now(),
+	}
+}
+
+// newImportJobID 生成导入任务 ID
+func newImportJobID() (string, error) {
+	bytes := make([]byte, 8)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
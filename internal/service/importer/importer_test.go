@@ -0,0 +1,346 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/lin-snow/ech0/internal/config"
+	"github.com/lin-snow/ech0/internal/database"
+	"github.com/lin-snow/ech0/internal/event"
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	echoModel "github.com/lin-snow/ech0/internal/model/echo"
+	model "github.com/lin-snow/ech0/internal/model/importer"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
+	commonRepository "github.com/lin-snow/ech0/internal/repository/common"
+	echoRepository "github.com/lin-snow/ech0/internal/repository/echo"
+	keyvalueRepository "github.com/lin-snow/ech0/internal/repository/keyvalue"
+	commonService "github.com/lin-snow/ech0/internal/service/common"
+	echoService "github.com/lin-snow/ech0/internal/service/echo"
+	"github.com/lin-snow/ech0/internal/transaction"
+	logUtil "github.com/lin-snow/ech0/internal/util/log"
+	"go.uber.org/zap"
+)
+
+// memoryCache 同步写入的内存缓存，避免 Ristretto 异步写入导致测试不稳定
+type memoryCache struct {
+	mu    sync.Mutex
+	items map[string]any
+}
+
+func (c *memoryCache) Set(key string, value any, _ int64) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.items[key] = value
+	return true
+}
+
+func (c *memoryCache) SetWithTTL(key string, value any, cost int64, _ time.Duration) bool {
+	return c.Set(key, value, cost)
+}
+
+func (c *memoryCache) Get(key string) (any, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	value, ok := c.items[key]
+	if !ok {
+		return nil, errors.New("key not found")
+	}
+	return value, nil
+}
+
+func (c *memoryCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.items, key)
+}
+
+func (c *memoryCache) GetOrSet(key string, cost int64, fn func() (any, error)) (any, error) {
+	if value, err := c.Get(key); err == nil {
+		return value, nil
+	}
+	value, err := fn()
+	if err != nil {
+		return nil, err
+	}
+	c.Set(key, value, cost)
+	return value, nil
+}
+
+// importFixture 基于临时目录与 SQLite 数据库的导入服务测试环境
+type importFixture struct {
+	db      *gorm.DB
+	service *ImportService
+	owner   userModel.User // 站长，拥有导入权限
+	alice   userModel.User // 编辑，没有导入权限
+}
+
+func newImportFixture(t *testing.T) *importFixture {
+	t.Helper()
+	logUtil.Logger = zap.NewNop()
+
+	// 导入时在工作目录中创建临时文件，图片保存到本地存储目录
+	dir := t.TempDir()
+	t.Chdir(dir)
+	upload := config.Config.Upload
+	t.Cleanup(func() { config.Config.Upload = upload })
+	config.Config.Upload.ImagePath = filepath.Join(dir, "images")
+	config.Config.Upload.ImageMaxSize = 1 << 20
+	config.Config.Upload.AllowedTypes = []string{"image/png", "image/jpeg"}
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(dir, "ech0.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.AutoMigrate(database.Models()...); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	dbProvider := func() *gorm.DB { return db }
+	cache := &memoryCache{items: make(map[string]any)}
+	txManager := transaction.NewTransactionManager(dbProvider)
+	echoRepo := echoRepository.NewEchoRepository(dbProvider, cache)
+	commonRepo := commonRepository.NewCommonRepository(dbProvider)
+	kvRepo := keyvalueRepository.NewKeyValueRepository(dbProvider, cache)
+	busProvider := func() event.IEventBus { return nil }
+	commonSvc := commonService.NewCommonService(txManager, commonRepo, echoRepo, kvRepo, busProvider)
+	echoSvc := echoService.NewEchoService(txManager, commonSvc, echoRepo, commonRepo, nil, kvRepo, busProvider)
+
+	f := &importFixture{
+		db:      db,
+		service: NewImportService(txManager, commonSvc, echoSvc, echoRepo).(*ImportService),
+	}
+	for _, u := range []struct {
+		user *userModel.User
+		name string
+		role userModel.Role
+	}{
+		{&f.owner, "owner", userModel.RoleOwner},
+		{&f.alice, "alice", userModel.RoleEditor},
+	} {
+		u.user.Username = u.name
+		u.user.Password = "x"
+		u.user.SetRole(u.role)
+		if err := db.Create(u.user).Error; err != nil {
+			t.Fatalf("create user: %v", err)
+		}
+	}
+	return f
+}
+
+// writeMastodonArchive 写入 Mastodon 归档：一条带图片与标签的嘟文、对它的回复，以及回复他人的嘟文
+func writeMastodonArchive(t *testing.T) string {
+	t.Helper()
+	var image bytes.Buffer
+	if err := png.Encode(&image, imageOf(2, 3)); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+
+	dir := t.TempDir()
+	files := map[string][]byte{
+		// 扩展名与内容不一致，按内容保存为 PNG
+		"media_attachments/files/1.jpg": image.Bytes(),
+		"outbox.json": []byte(`{"orderedItems": [
+			{"type": "Create", "object": {
+				"id": "https://m.example/statuses/1", "type": "Note", "content": "<p>第一条 #go</p>",
+				"published": "2024-01-02T03:04:05Z",
+				"to": ["https://www.w3.org/ns/activitystreams#Public"],
+				"attachment": [
+					{"mediaType": "image/jpeg", "url": "/media_attachments/files/1.jpg"},
+					{"mediaType": "image/png", "url": "/media_attachments/files/missing.png"}
+				],
+				"tag": [{"type": "Hashtag", "name": "#go"}]
+			}},
+			{"type": "Create", "object": {
+				"id": "https://m.example/statuses/2", "type": "Note", "content": "<p>自己的回复</p>",
+				"inReplyTo": "https://m.example/statuses/1", "published": "2024-01-03T00:00:00Z",
+				"to": ["https://m.example/users/alice/followers"]
+			}},
+			{"type": "Create", "object": {
+				"id": "https://m.example/statuses/3", "type": "Note", "content": "<p>回复他人</p>",
+				"inReplyTo": "https://other.example/statuses/9", "published": "2024-01-04T00:00:00Z",
+				"to": ["https://www.w3.org/ns/activitystreams#Public"]
+			}},
+			{"type": "Create", "object": {
+				"id": "https://m.example/statuses/4", "type": "Note", "content": "",
+				"published": "2024-01-05T00:00:00Z", "to": ["https://www.w3.org/ns/activitystreams#Public"]
+			}}
+		]}`),
+	}
+	for name, data := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	return dir
+}
+
+func imageOf(width, height int) image.Image {
+	return image.NewRGBA(image.Rect(0, 0, width, height))
+}
+
+func TestImportMastodonArchive(t *testing.T) {
+	f := newImportFixture(t)
+	archive := writeMastodonArchive(t)
+	countEchos := func() int64 {
+		var count int64
+		f.db.Model(&echoModel.Echo{}).Count(&count)
+		return count
+	}
+
+	// 试运行只统计，不写入数据
+	var reports []model.ImportProgress
+	options := model.ImportOptions{Source: " Mastodon ", DryRun: true}
+	result, err := f.service.Import(f.owner.ID, archive, options, func(progress model.ImportProgress) {
+		reports = append(reports, progress)
+	})
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if result.Status != model.ImportStatusCompleted || result.Total != 4 || result.Processed != 4 ||
+		result.Imported != 2 || result.Skipped != 2 || result.MediaImported != 2 || !result.DryRun {
+		t.Errorf("dry run = %+v", result)
+	}
+	if count := countEchos(); count != 0 {
+		t.Errorf("dry run saved %d echos", count)
+	}
+	if len(reports) == 0 || reports[len(reports)-1].Status != model.ImportStatusCompleted {
+		t.Errorf("progress reports = %+v", reports)
+	}
+
+	result, err = f.service.Import(f.owner.ID, archive, model.ImportOptions{Source: model.SourceMastodon}, nil)
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if result.Imported != 2 || result.Skipped != 2 || result.MediaImported != 1 || result.MediaFailed != 1 ||
+		len(result.Errors) != 1 {
+		t.Errorf("import = %+v", result)
+	}
+
+	var echos []echoModel.Echo
+	if err := f.db.Preload("Images").Preload("Tags").Order("created_at ASC").Find(&echos).Error; err != nil {
+		t.Fatalf("load echos: %v", err)
+	}
+	if len(echos) != 2 {
+		t.Fatalf("got %d echos", len(echos))
+	}
+	first, reply := echos[0], echos[1]
+	if first.Content != "第一条 #go" || first.UserID != f.owner.ID || first.Visibility != echoModel.VisibilityPublic ||
+		!first.IsPublished() || !first.CreatedAt.Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Errorf("first echo = %+v", first)
+	}
+	if len(first.Tags) != 1 || first.Tags[0].Name != "go" {
+		t.Errorf("tags = %+v", first.Tags)
+	}
+	if len(first.Images) != 1 || filepath.Ext(first.Images[0].ImageURL) != ".png" ||
+		first.Images[0].Width != 2 || first.Images[0].Height != 3 {
+		t.Errorf("images = %+v", first.Images)
+	}
+	if reply.ReplyToID != first.ID || reply.Visibility != echoModel.VisibilityFollowers {
+		t.Errorf("reply = %+v", reply)
+	}
+
+	// 重复导入时跳过已导入的内容
+	result, err = f.service.Import(f.owner.ID, archive, model.ImportOptions{Source: model.SourceMastodon}, nil)
+	if err != nil {
+		t.Fatalf("import again: %v", err)
+	}
+	if result.Imported != 0 || result.Skipped != 4 {
+		t.Errorf("import again = %+v", result)
+	}
+	if count := countEchos(); count != 2 {
+		t.Errorf("got %d echos after importing again", count)
+	}
+}
+
+func TestImportVisibilityOverride(t *testing.T) {
+	f := newImportFixture(t)
+	options := model.ImportOptions{Source: model.SourceMastodon, Visibility: echoModel.VisibilityPrivate}
+	if _, err := f.service.Import(f.owner.ID, writeMastodonArchive(t), options, nil); err != nil {
+		t.Fatalf("import: %v", err)
+	}
+
+	var visibilities []string
+	f.db.Model(&echoModel.Echo{}).Distinct().Pluck("visibility", &visibilities)
+	if !slices.Equal(visibilities, []string{echoModel.VisibilityPrivate}) {
+		t.Errorf("visibilities = %v", visibilities)
+	}
+}
+
+func TestImportRejectsInvalidOptions(t *testing.T) {
+	f := newImportFixture(t)
+	archive := writeMastodonArchive(t)
+
+	tests := map[string]struct {
+		userId  uint
+		path    string
+		options model.ImportOptions
+		wantErr string
+	}{
+		"编辑没有导入权限": {userId: f.alice.ID, path: archive, options: model.ImportOptions{Source: model.SourceMastodon}, wantErr: commonModel.NO_PERMISSION_DENIED},
+		"不支持的来源":   {userId: f.owner.ID, path: archive, options: model.ImportOptions{Source: "wordpress"}, wantErr: commonModel.IMPORT_SOURCE_INVALID},
+		"无效的存储方式":  {userId: f.owner.ID, path: archive, options: model.ImportOptions{Source: model.SourceMastodon, Storage: "ftp"}, wantErr: commonModel.IMPORT_STORAGE_INVALID},
+		"无效的可见性":   {userId: f.owner.ID, path: archive, options: model.ImportOptions{Source: model.SourceMastodon, Visibility: "secret"}, wantErr: commonModel.INVALID_ECHO_VISIBILITY},
+		"没有可导入的数据": {userId: f.owner.ID, path: t.TempDir(), options: model.ImportOptions{Source: model.SourceMastodon}, wantErr: commonModel.IMPORT_DATA_NOT_FOUND},
+	}
+
+	for name, tt := range tests {
+		_, err := f.service.Import(tt.userId, tt.path, tt.options, nil)
+		got := ""
+		if err != nil {
+			got = err.Error()
+		}
+		if got != tt.wantErr {
+			t.Errorf("%s: err = %q, want %q", name, got, tt.wantErr)
+		}
+	}
+
+	// 导入结束后清理工作目录
+	if entries, err := os.ReadDir("temp"); err != nil || len(entries) != 0 {
+		t.Errorf("temp dir = %v, %v", entries, err)
+	}
+}
+
+func TestSniffedFilename(t *testing.T) {
+	var image bytes.Buffer
+	if err := png.Encode(&image, imageOf(1, 1)); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+	png := image.Bytes()
+
+	tests := map[string]struct {
+		filename string
+		head     []byte
+		want     string
+		wantErr  bool
+	}{
+		"扩展名与内容一致":  {filename: "a.png", head: png, want: "a.png"},
+		"扩展名大小写不敏感": {filename: "a.PNG", head: png, want: "a.PNG"},
+		"替换为内容的扩展名": {filename: "a.jpg", head: png, want: "a.png"},
+		"补全扩展名":     {filename: "a", head: png, want: "a.png"},
+		"不是图片":      {filename: "a.png", head: []byte("<html></html>"), wantErr: true},
+	}
+
+	for name, tt := range tests {
+		got, err := sniffedFilename(tt.filename, tt.head)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("%s: sniffedFilename = %q, %v", name, got, err)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package service
+
+import (
+	"mime/multipart"
+
+	"github.com/gin-gonic/gin"
+	model "github.com/lin-snow/ech0/internal/model/importer"
+)
+
+type ImportServiceInterface interface {
+	// Import 同步导入本地的导出文件或目录，progress 在每条内容处理后回调
+	Import(
+		userId uint,
+		path string,
+		options model.ImportOptions,
+		progress func(model.ImportProgress),
+	) (model.ImportProgress, error)
+
+	// StartImport 保存上传的导出文件并在后台开始导入
+	StartImport(
+		ctx *gin.Context,
+		userId uint,
+		options model.ImportOptions,
+		file *multipart.FileHeader,
+	) (model.ImportProgress, error)
+
+	// GetImportJob 获取导入任务的进度
+	GetImportJob(userId uint, id string) (model.ImportProgress, error)
+}
@@ -6,8 +6,10 @@ import (
 	"strings"
 	"time"
 
+	"github.com/lin-snow/ech0/internal/authz"
 	commonModel "github.com/lin-snow/ech0/internal/model/common"
 	inboxModel "github.com/lin-snow/ech0/internal/model/inbox"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
 	inboxRepository "github.com/lin-snow/ech0/internal/repository/inbox"
 	commonService "github.com/lin-snow/ech0/internal/service/common"
 	"github.com/lin-snow/ech0/internal/transaction"
@@ -37,7 +39,7 @@ func (inboxService *InboxService) GetInboxList(
 	userid uint,
 	pageQueryDto commonModel.PageQueryDto,
 ) (commonModel.PageQueryResult[[]*inboxModel.Inbox], error) {
-	if err := inboxService.ensureAdmin(userid); err != nil {
+	if err := inboxService.authorize(userid); err != nil {
 		return commonModel.PageQueryResult[[]*inboxModel.Inbox]{}, err
 	}
 
@@ -67,9 +69,42 @@ func (inboxService *InboxService) GetInboxList(
 	}, nil
 }
 
+// GetInboxByCursor 按游标获取收件箱消息
+func (inboxService *InboxService) GetInboxByCursor(
+	userid uint,
+	cursorQueryDto commonModel.CursorQueryDto,
+) (commonModel.CursorQueryResult[[]*inboxModel.Inbox], error) {
+	if err := inboxService.authorize(userid); err != nil {
+		return commonModel.CursorQueryResult[[]*inboxModel.Inbox]{}, err
+	}
+
+	if cursorQueryDto.Limit < 1 || cursorQueryDto.Limit > commonModel.MaxCursorLimit {
+		cursorQueryDto.Limit = commonModel.DefaultCursorLimit
+	}
+	cursorQueryDto.Search = strings.TrimSpace(cursorQueryDto.Search)
+
+	inboxes, hasMore, err := inboxService.inboxRepository.GetInboxByCursor(
+		context.Background(),
+		cursorQueryDto.BeforeID,
+		cursorQueryDto.SinceID,
+		cursorQueryDto.Limit,
+		cursorQueryDto.Search,
+	)
+	if err != nil {
+		return commonModel.CursorQueryResult[[]*inboxModel.Inbox]{}, err
+	}
+
+	return commonModel.NewCursorQueryResult(
+		inboxes,
+		hasMore,
+		cursorQueryDto.SinceID,
+		func(inbox *inboxModel.Inbox) uint { return inbox.ID },
+	), nil
+}
+
 // GetUnreadInbox 获取所有未读消息
 func (inboxService *InboxService) GetUnreadInbox(userid uint) ([]*inboxModel.Inbox, error) {
-	if err := inboxService.ensureAdmin(userid); err != nil {
+	if err := inboxService.authorize(userid); err != nil {
 		return nil, err
 	}
 
@@ -78,7 +113,7 @@ func (inboxService *InboxService) GetUnreadInbox(userid uint) ([]*inboxModel.Inb
 
 // MarkAsRead 将消息标记为已读
 func (inboxService *InboxService) MarkAsRead(userid, inboxID uint) error {
-	if err := inboxService.ensureAdmin(userid); err != nil {
+	if err := inboxService.authorize(userid); err != nil {
 		return err
 	}
 
@@ -107,7 +142,7 @@ func (inboxService *InboxService) MarkAsRead(userid, inboxID uint) error {
 
 // DeleteInbox 删除指定的收件箱消息
 func (inboxService *InboxService) DeleteInbox(userid, inboxID uint) error {
-	if err := inboxService.ensureAdmin(userid); err != nil {
+	if err := inboxService.authorize(userid); err != nil {
 		return err
 	}
 
@@ -121,7 +156,7 @@ func (inboxService *InboxService) DeleteInbox(userid, inboxID uint) error {
 
 // ClearInbox 清空收件箱
 func (inboxService *InboxService) ClearInbox(userid uint) error {
-	if err := inboxService.ensureAdmin(userid); err != nil {
+	if err := inboxService.authorize(userid); err != nil {
 		return err
 	}
 
@@ -130,15 +165,13 @@ func (inboxService *InboxService) ClearInbox(userid uint) error {
 	})
 }
 
-func (inboxService *InboxService) ensureAdmin(userid uint) error {
+// authorize 检查用户是否拥有管理收件箱的权限
+func (inboxService *InboxService) authorize(userid uint) error {
 	user, err := inboxService.commonService.CommonGetUserByUserId(userid)
 	if err != nil {
 		return err
 	}
-	if !user.IsAdmin {
-		return errors.New(commonModel.NO_PERMISSION_DENIED)
-	}
-	return nil
+	return authz.Authorize(user, userModel.PermissionInboxManage)
 }
 
 func (inboxService *InboxService) handleRepoError(err error) error {
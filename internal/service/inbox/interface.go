@@ -12,6 +12,12 @@ type InboxServiceInterface interface {
 		pageQueryDto commonModel.PageQueryDto,
 	) (commonModel.PageQueryResult[[]*inboxModel.Inbox], error)
 
+	// GetInboxByCursor 按游标获取收件箱消息
+	GetInboxByCursor(
+		userid uint,
+		cursorQueryDto commonModel.CursorQueryDto,
+	) (commonModel.CursorQueryResult[[]*inboxModel.Inbox], error)
+
 	// GetUnreadInbox 获取所有未读消息
 	GetUnreadInbox(userid uint) ([]*inboxModel.Inbox, error)
 
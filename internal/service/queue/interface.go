@@ -0,0 +1,26 @@
+package service
+
+import (
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	queueModel "github.com/lin-snow/ech0/internal/model/queue"
+)
+
+type QueueServiceInterface interface {
+	// ListDeadLetters 分页获取死信任务
+	ListDeadLetters(
+		userid uint,
+		query queueModel.DeadLetterQueryDto,
+	) (commonModel.PageQueryResult[[]queueModel.DeadLetter], error)
+
+	// GetDeadLetter 获取死信任务详情
+	GetDeadLetter(userid uint, id int64) (*queueModel.DeadLetter, error)
+
+	// RetryDeadLetter 立即重试死信任务
+	RetryDeadLetter(userid uint, id int64) error
+
+	// DiscardDeadLetter 丢弃死信任务
+	DiscardDeadLetter(userid uint, id int64) error
+
+	// PurgeDeadLetters 清理已完成和已丢弃的死信任务
+	PurgeDeadLetters(userid uint) (int64, error)
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/lin-snow/ech0/internal/authz"
+	"github.com/lin-snow/ech0/internal/event"
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	queueModel "github.com/lin-snow/ech0/internal/model/queue"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
+	queueRepository "github.com/lin-snow/ech0/internal/repository/queue"
+	commonService "github.com/lin-snow/ech0/internal/service/common"
+	"github.com/lin-snow/ech0/internal/transaction"
+)
+
+type QueueService struct {
+	txManager       transaction.TransactionManager
+	commonService   commonService.CommonServiceInterface
+	queueRepository queueRepository.QueueRepositoryInterface
+	eventBus        event.IEventBus
+}
+
+func NewQueueService(
+	tm transaction.TransactionManager,
+	commonSvc commonService.CommonServiceInterface,
+	queueRepo queueRepository.QueueRepositoryInterface,
+	ebProvider func() event.IEventBus,
+) QueueServiceInterface {
+	return &QueueService{
+		txManager:       tm,
+		commonService:   commonSvc,
+		queueRepository: queueRepo,
+		eventBus:        ebProvider(),
+	}
+}
+
+// ListDeadLetters 分页获取死信任务
+func (queueService *QueueService) ListDeadLetters(
+	userid uint,
+	query queueModel.DeadLetterQueryDto,
+) (commonModel.PageQueryResult[[]queueModel.DeadLetter], error) {
+	if err := queueService.authorize(userid); err != nil {
+		return commonModel.PageQueryResult[[]queueModel.DeadLetter]{}, err
+	}
+
+	if query.Page < 1 {
+		query.Page = 1
+	}
+	if query.PageSize < 1 || query.PageSize > 100 {
+		query.PageSize = 10
+	}
+	query.Status = strings.TrimSpace(query.Status)
+	if query.Status != "" && !queueModel.IsValidDeadLetterStatus(query.Status) {
+		return commonModel.PageQueryResult[[]queueModel.DeadLetter]{}, errors.New(
+			commonModel.DEAD_LETTER_INVALID_STATUS,
+		)
+	}
+
+	offset := (query.Page - 1) * query.PageSize
+
+	deadLetters, total, err := queueService.queueRepository.ListDeadLettersByPage(
+		query.Status,
+		offset,
+		query.PageSize,
+	)
+	if err != nil {
+		return commonModel.PageQueryResult[[]queueModel.DeadLetter]{}, err
+	}
+
+	for i := range deadLetters {
+		deadLetters[i] = deadLetters[i].Redacted()
+	}
+
+	return commonModel.PageQueryResult[[]queueModel.DeadLetter]{
+		Items: deadLetters,
+		Total: total,
+	}, nil
+}
+
+// GetDeadLetter 获取死信任务详情
+func (queueService *QueueService) GetDeadLetter(
+	userid uint,
+	id int64,
+) (*queueModel.DeadLetter, error) {
+	if err := queueService.authorize(userid); err != nil {
+		return nil, err
+	}
+
+	deadLetter, err := queueService.getDeadLetter(id)
+	if err != nil {
+		return nil, err
+	}
+
+	redacted := deadLetter.Redacted()
+	return &redacted, nil
+}
+
+// RetryDeadLetter 重置死信任务并立即提交重试
+func (queueService *QueueService) RetryDeadLetter(userid uint, id int64) error {
+	if err := queueService.authorize(userid); err != nil {
+		return err
+	}
+
+	deadLetter, err := queueService.getDeadLetter(id)
+	if err != nil {
+		return err
+	}
+	if deadLetter.Status == queueModel.DeadLetterStatusCompleted {
+		return errors.New(commonModel.DEAD_LETTER_ALREADY_COMPLETED)
+	}
+
+	deadLetter.ResetForRetry(time.Now())
+	if err := queueService.txManager.Run(func(ctx context.Context) error {
+		return queueService.queueRepository.UpdateDeadLetter(ctx, deadLetter)
+	}); err != nil {
+		return err
+	}
+
+	// 发布重试事件，由 DeadLetterResolver 异步处理
+	return queueService.eventBus.Publish(
+		context.Background(),
+		event.NewEvent(
+			event.EventTypeDeadLetterRetried,
+			event.EventPayload{
+				event.EventPayloadDeadLetter: *deadLetter,
+			},
+		),
+	)
+}
+
+// DiscardDeadLetter 丢弃死信任务
+func (queueService *QueueService) DiscardDeadLetter(userid uint, id int64) error {
+	if err := queueService.authorize(userid); err != nil {
+		return err
+	}
+
+	deadLetter, err := queueService.getDeadLetter(id)
+	if err != nil {
+		return err
+	}
+
+	deadLetter.Discard(time.Now())
+	return queueService.txManager.Run(func(ctx context.Context) error {
+		return queueService.queueRepository.UpdateDeadLetter(ctx, deadLetter)
+	})
+}
+
+// PurgeDeadLetters 清理已完成和已丢弃的死信任务
+func (queueService *QueueService) PurgeDeadLetters(userid uint) (int64, error) {
+	if err := queueService.authorize(userid); err != nil {
+		return 0, err
+	}
+
+	var purged int64
+	err := queueService.txManager.Run(func(ctx context.Context) error {
+		var err error
+		purged, err = queueService.queueRepository.PurgeDeadLetters(
+			ctx,
+			queueModel.DeadLetterStatusCompleted,
+			queueModel.DeadLetterStatusDiscarded,
+		)
+		return err
+	})
+
+	return purged, err
+}
+
+func (queueService *QueueService) getDeadLetter(id int64) (*queueModel.DeadLetter, error) {
+	deadLetter, err := queueService.queueRepository.GetDeadLetterByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if deadLetter == nil {
+		return nil, errors.New(commonModel.DEAD_LETTER_NOT_FOUND)
+	}
+	return deadLetter, nil
+}
+
+// authorize 检查用户是否拥有管理死信任务的权限
+func (queueService *QueueService) authorize(userid uint) error {
+	user, err := queueService.commonService.CommonGetUserByUserId(userid)
+	if err != nil {
+		return err
+	}
+	return authz.Authorize(user, userModel.PermissionQueueManage)
+}
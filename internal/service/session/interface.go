@@ -0,0 +1,27 @@
+package service
+
+import (
+	authModel "github.com/lin-snow/ech0/internal/model/auth"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
+)
+
+type SessionServiceInterface interface {
+	// CreateSession 为登录成功的用户创建会话并签发令牌对
+	CreateSession(
+		user userModel.User,
+		method string,
+		client authModel.ClientInfo,
+	) (authModel.TokenPairDto, error)
+
+	// RefreshSession 使用刷新令牌换取新的令牌对，刷新令牌随之轮换
+	RefreshSession(refreshToken string, client authModel.ClientInfo) (authModel.TokenPairDto, error)
+
+	// ListSessions 列出用户的登录会话，currentSessionID 对应的会话标记为当前会话
+	ListSessions(userID, currentSessionID uint) ([]authModel.SessionDto, error)
+
+	// RevokeSession 吊销用户的指定登录会话
+	RevokeSession(userID, sessionID uint) error
+
+	// RevokeAllSessions 吊销用户的全部登录会话
+	RevokeAllSessions(userID uint) error
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/lin-snow/ech0/internal/config"
+	authModel "github.com/lin-snow/ech0/internal/model/auth"
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
+	sessionRepository "github.com/lin-snow/ech0/internal/repository/session"
+	userRepository "github.com/lin-snow/ech0/internal/repository/user"
+	"github.com/lin-snow/ech0/internal/transaction"
+	cryptoUtil "github.com/lin-snow/ech0/internal/util/crypto"
+	httpUtil "github.com/lin-snow/ech0/internal/util/http"
+	jwtUtil "github.com/lin-snow/ech0/internal/util/jwt"
+	logUtil "github.com/lin-snow/ech0/internal/util/log"
+	"go.uber.org/zap"
+)
+
+// refreshTokenSize 刷新令牌的随机字节数
+const refreshTokenSize = 32
+
+type SessionService struct {
+	txManager         transaction.TransactionManager               // 事务管理器
+	sessionRepository sessionRepository.SessionRepositoryInterface // 会话数据层接口
+	userRepository    userRepository.UserRepositoryInterface       // 用户数据层接口
+}
+
+func NewSessionService(
+	tm transaction.TransactionManager,
+	sessionRepository sessionRepository.SessionRepositoryInterface,
+	userRepository userRepository.UserRepositoryInterface,
+) SessionServiceInterface {
+	return &SessionService{
+		txManager:         tm,
+		sessionRepository: sessionRepository,
+		userRepository:    userRepository,
+	}
+}
+
+// CreateSession 为登录成功的用户创建会话并签发令牌对
+func (sessionService *SessionService) CreateSession(
+	user userModel.User,
+	method string,
+	client authModel.ClientInfo,
+) (authModel.TokenPairDto, error) {
+	refreshToken, err := cryptoUtil.GenerateSecureToken(refreshTokenSize)
+	if err != nil {
+		return authModel.TokenPairDto{}, err
+	}
+
+	now := time.Now()
+	session := &authModel.Session{
+		UserID:           user.ID,
+		RefreshTokenHash: cryptoUtil.SHA256Hex(refreshToken),
+		TokenVersion:     user.TokenVersion,
+		LoginMethod:      method,
+		Device:           httpUtil.ParseDevice(client.UserAgent),
+		UserAgent:        client.UserAgent,
+		IP:               client.IP,
+		LastUsedAt:       now,
+		ExpiresAt:        now.Add(sessionLifetime()),
+		CreatedAt:        now,
+	}
+
+	if err := sessionService.txManager.Run(func(ctx context.Context) error {
+		// 顺带清理已过期的会话
+		if err := sessionService.sessionRepository.DeleteExpiredSessions(ctx, now); err != nil {
+			return err
+		}
+		return sessionService.sessionRepository.CreateSession(ctx, session)
+	}); err != nil {
+		return authModel.TokenPairDto{}, err
+	}
+
+	return issueTokenPair(user, session.ID, refreshToken)
+}
+
+// RefreshSession 使用刷新令牌换取新的令牌对，刷新令牌随之轮换
+func (sessionService *SessionService) RefreshSession(
+	refreshToken string,
+	client authModel.ClientInfo,
+) (authModel.TokenPairDto, error) {
+	tokenHash := cryptoUtil.SHA256Hex(refreshToken)
+	session, err := sessionService.sessionRepository.GetSessionByRefreshHash(tokenHash)
+	if err != nil {
+		return authModel.TokenPairDto{}, err
+	}
+
+	if session == nil {
+		// 已轮换掉的刷新令牌再次出现，说明令牌可能被盗用，吊销整个会话
+		if reused, _ := sessionService.sessionRepository.GetSessionByPreviousHash(tokenHash); reused != nil {
+			logUtil.GetLogger().Warn(
+				"Refresh token reuse detected, revoking session",
+				zap.Uint("session_id", reused.ID),
+				zap.Uint("user_id", reused.UserID),
+			)
+			_ = sessionService.revoke(reused.UserID, reused.ID)
+		}
+		return authModel.TokenPairDto{}, errors.New(commonModel.SESSION_INVALID)
+	}
+
+	now := time.Now()
+	if session.IsExpired(now) {
+		_ = sessionService.revoke(session.UserID, session.ID)
+		return authModel.TokenPairDto{}, errors.New(commonModel.SESSION_INVALID)
+	}
+
+	// 用户已删除或修改过密码时，会话随之失效
+	user, err := sessionService.userRepository.GetUserByID(int(session.UserID))
+	if err != nil || user.TokenVersion != session.TokenVersion {
+		_ = sessionService.revoke(session.UserID, session.ID)
+		return authModel.TokenPairDto{}, errors.New(commonModel.SESSION_INVALID)
+	}
+
+	newRefreshToken, err := cryptoUtil.GenerateSecureToken(refreshTokenSize)
+	if err != nil {
+		return authModel.TokenPairDto{}, err
+	}
+	session.PreviousTokenHash = tokenHash
+	session.RefreshTokenHash = cryptoUtil.SHA256Hex(newRefreshToken)
+	session.LastUsedAt = now
+	session.ExpiresAt = now.Add(sessionLifetime())
+	session.IP = client.IP
+	if client.UserAgent != "" {
+		session.UserAgent = client.UserAgent
+		session.Device = httpUtil.ParseDevice(client.UserAgent)
+	}
+
+	var rotated bool
+	if err := sessionService.txManager.Run(func(ctx context.Context) error {
+		var err error
+		rotated, err = sessionService.sessionRepository.RotateSession(ctx, session, tokenHash)
+		return err
+	}); err != nil {
+		return authModel.TokenPairDto{}, err
+	}
+	if !rotated {
+		// 并发刷新时只有一个请求能完成轮换
+		return authModel.TokenPairDto{}, errors.New(commonModel.SESSION_INVALID)
+	}
+
+	return issueTokenPair(user, session.ID, newRefreshToken)
+}
+
+// ListSessions 列出用户的登录会话，currentSessionID 对应的会话标记为当前会话
+func (sessionService *SessionService) ListSessions(
+	userID, currentSessionID uint,
+) ([]authModel.SessionDto, error) {
+	sessions, err := sessionService.sessionRepository.ListSessionsByUserID(userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]authModel.SessionDto, 0, len(sessions))
+	for _, session := range sessions {
+		result = append(result, authModel.SessionDto{
+			Session: session,
+			Current: session.ID == currentSessionID,
+		})
+	}
+	return result, nil
+}
+
+// RevokeSession 吊销用户的指定登录会话
+func (sessionService *SessionService) RevokeSession(userID, sessionID uint) error {
+	var deleted bool
+	if err := sessionService.txManager.Run(func(ctx context.Context) error {
+		var err error
+		deleted, err = sessionService.sessionRepository.DeleteSession(ctx, userID, sessionID)
+		return err
+	}); err != nil {
+		return err
+	}
+	if !deleted {
+		return errors.New(commonModel.SESSION_NOT_FOUND)
+	}
+	return nil
+}
+
+// RevokeAllSessions 吊销用户的全部登录会话
+func (sessionService *SessionService) RevokeAllSessions(userID uint) error {
+	return sessionService.txManager.Run(func(ctx context.Context) error {
+		return sessionService.sessionRepository.DeleteSessionsByUserID(ctx, userID)
+	})
+}
+
+// revoke 删除会话，不关心会话是否存在
+func (sessionService *SessionService) revoke(userID, sessionID uint) error {
+	return sessionService.txManager.Run(func(ctx context.Context) error {
+		_, err := sessionService.sessionRepository.DeleteSession(ctx, userID, sessionID)
+		return err
+	})
+}
+
+// issueTokenPair 为会话签发访问令牌并与刷新令牌组成令牌对
+func issueTokenPair(
+	user userModel.User,
+	sessionID uint,
+	refreshToken string,
+) (authModel.TokenPairDto, error) {
+	accessToken, err := jwtUtil.GenerateToken(jwtUtil.CreateClaims(user, sessionID))
+	if err != nil {
+		return authModel.TokenPairDto{}, err
+	}
+
+	return authModel.TokenPairDto{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    config.Config.Auth.Jwt.AccessExpires,
+	}, nil
+}
+
+// sessionLifetime 登录会话的有效期，每次刷新后顺延
+func sessionLifetime() time.Duration {
+	return time.Duration(config.Config.Auth.Jwt.Expires) * time.Second
+}
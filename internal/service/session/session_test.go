@@ -0,0 +1,297 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/lin-snow/ech0/internal/config"
+	"github.com/lin-snow/ech0/internal/database"
+	authModel "github.com/lin-snow/ech0/internal/model/auth"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
+	sessionRepository "github.com/lin-snow/ech0/internal/repository/session"
+	userRepository "github.com/lin-snow/ech0/internal/repository/user"
+	"github.com/lin-snow/ech0/internal/transaction"
+	jwtUtil "github.com/lin-snow/ech0/internal/util/jwt"
+	logUtil "github.com/lin-snow/ech0/internal/util/log"
+	"go.uber.org/zap"
+)
+
+// memoryCache 同步写入的内存缓存，避免 Ristretto 异步写入导致测试不稳定
+type memoryCache struct {
+	mu    sync.Mutex
+	items map[string]any
+}
+
+func (c *memoryCache) Set(key string, value any, _ int64) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.items[key] = value
+	return true
+}
+
+func (c *memoryCache) SetWithTTL(key string, value any, cost int64, _ time.Duration) bool {
+	return c.Set(key, value, cost)
+}
+
+func (c *memoryCache) Get(key string) (any, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	value, ok := c.items[key]
+	if !ok {
+		return nil, errors.New("key not found")
+	}
+	return value, nil
+}
+
+func (c *memoryCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.items, key)
+}
+
+func (c *memoryCache) GetOrSet(key string, cost int64, fn func() (any, error)) (any, error) {
+	if value, err := c.Get(key); err == nil {
+		return value, nil
+	}
+	value, err := fn()
+	if err != nil {
+		return nil, err
+	}
+	c.Set(key, value, cost)
+	return value, nil
+}
+
+// sessionFixture 基于临时 SQLite 数据库的会话测试环境
+type sessionFixture struct {
+	db       *gorm.DB
+	service  SessionServiceInterface
+	userRepo userRepository.UserRepositoryInterface
+	user     userModel.User
+}
+
+func newSessionFixture(t *testing.T) *sessionFixture {
+	t.Helper()
+	logUtil.Logger = zap.NewNop()
+	config.JWT_SECRET = []byte("test-secret")
+	config.Config.Auth.Jwt.Expires = 3600
+	config.Config.Auth.Jwt.AccessExpires = 300
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "ech0.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.AutoMigrate(database.Models()...); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	dbProvider := func() *gorm.DB { return db }
+	cache := &memoryCache{items: make(map[string]any)}
+
+	f := &sessionFixture{
+		db:       db,
+		userRepo: userRepository.NewUserRepository(dbProvider, cache),
+	}
+	f.user = userModel.User{Username: "alice", Password: "x"}
+	if err := db.Create(&f.user).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	f.service = NewSessionService(
+		transaction.NewTransactionManager(dbProvider),
+		sessionRepository.NewSessionRepository(dbProvider),
+		f.userRepo,
+	)
+	return f
+}
+
+func (f *sessionFixture) login(t *testing.T) authModel.TokenPairDto {
+	t.Helper()
+	pair, err := f.service.CreateSession(f.user, "password", authModel.ClientInfo{IP: "127.0.0.1"})
+	if err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+	return pair
+}
+
+func (f *sessionFixture) sessionCount(t *testing.T) int64 {
+	t.Helper()
+	var n int64
+	if err := f.db.Model(&authModel.Session{}).Count(&n).Error; err != nil {
+		t.Fatalf("count sessions: %v", err)
+	}
+	return n
+}
+
+func TestCreateSessionIssuesTokenPair(t *testing.T) {
+	f := newSessionFixture(t)
+	pair := f.login(t)
+
+	if pair.RefreshToken == "" || pair.TokenType != "Bearer" || pair.ExpiresIn != 300 {
+		t.Errorf("pair = %+v", pair)
+	}
+	claims, err := jwtUtil.ParseToken(pair.AccessToken)
+	if err != nil {
+		t.Fatalf("parse access token: %v", err)
+	}
+	if claims.Userid != f.user.ID || claims.SessionID == 0 {
+		t.Errorf("claims = user %d session %d", claims.Userid, claims.SessionID)
+	}
+
+	var session authModel.Session
+	if err := f.db.First(&session, claims.SessionID).Error; err != nil {
+		t.Fatalf("load session: %v", err)
+	}
+	// 数据库中只保存刷新令牌的摘要
+	if session.RefreshTokenHash == "" || session.RefreshTokenHash == pair.RefreshToken {
+		t.Errorf("refresh token hash = %q", session.RefreshTokenHash)
+	}
+	if session.LoginMethod != "password" || session.IP != "127.0.0.1" {
+		t.Errorf("session = %+v", session)
+	}
+}
+
+func TestRefreshSessionRotatesToken(t *testing.T) {
+	f := newSessionFixture(t)
+	first := f.login(t)
+
+	second, err := f.service.RefreshSession(first.RefreshToken, authModel.ClientInfo{IP: "10.0.0.2"})
+	if err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	if second.RefreshToken == first.RefreshToken {
+		t.Fatal("refresh token was not rotated")
+	}
+	firstClaims, _ := jwtUtil.ParseToken(first.AccessToken)
+	secondClaims, err := jwtUtil.ParseToken(second.AccessToken)
+	if err != nil {
+		t.Fatalf("parse access token: %v", err)
+	}
+	if secondClaims.SessionID != firstClaims.SessionID {
+		t.Errorf("session id changed from %d to %d", firstClaims.SessionID, secondClaims.SessionID)
+	}
+
+	third, err := f.service.RefreshSession(second.RefreshToken, authModel.ClientInfo{IP: "10.0.0.3"})
+	if err != nil {
+		t.Fatalf("refresh rotated token: %v", err)
+	}
+	if third.RefreshToken == second.RefreshToken {
+		t.Error("refresh token was not rotated again")
+	}
+	if n := f.sessionCount(t); n != 1 {
+		t.Errorf("sessions = %d, want 1", n)
+	}
+}
+
+func TestRefreshSessionReuseRevokesSession(t *testing.T) {
+	f := newSessionFixture(t)
+	first := f.login(t)
+	other := f.login(t)
+
+	second, err := f.service.RefreshSession(first.RefreshToken, authModel.ClientInfo{})
+	if err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+
+	// 已轮换的令牌再次出现，视为被盗用，整个会话被吊销
+	if _, err := f.service.RefreshSession(first.RefreshToken, authModel.ClientInfo{}); err == nil {
+		t.Fatal("expected reused refresh token to be rejected")
+	}
+	if _, err := f.service.RefreshSession(second.RefreshToken, authModel.ClientInfo{}); err == nil {
+		t.Error("expected session to be revoked after reuse")
+	}
+
+	// 同一用户的其他会话不受影响
+	if _, err := f.service.RefreshSession(other.RefreshToken, authModel.ClientInfo{}); err != nil {
+		t.Errorf("refresh other session: %v", err)
+	}
+}
+
+func TestRefreshSessionRejectsInvalidSessions(t *testing.T) {
+	t.Run("unknown token", func(t *testing.T) {
+		f := newSessionFixture(t)
+		f.login(t)
+		if _, err := f.service.RefreshSession("not-a-token", authModel.ClientInfo{}); err == nil {
+			t.Error("expected unknown token to be rejected")
+		}
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		f := newSessionFixture(t)
+		pair := f.login(t)
+		if err := f.db.Model(&authModel.Session{}).
+			Where("user_id = ?", f.user.ID).
+			Update("expires_at", time.Now().Add(-time.Minute)).Error; err != nil {
+			t.Fatalf("expire session: %v", err)
+		}
+		if _, err := f.service.RefreshSession(pair.RefreshToken, authModel.ClientInfo{}); err == nil {
+			t.Error("expected expired session to be rejected")
+		}
+		if n := f.sessionCount(t); n != 0 {
+			t.Errorf("sessions = %d, want expired session removed", n)
+		}
+	})
+
+	t.Run("password changed", func(t *testing.T) {
+		f := newSessionFixture(t)
+		pair := f.login(t)
+		f.user.TokenVersion++
+		if err := f.userRepo.UpdateUser(context.Background(), &f.user); err != nil {
+			t.Fatalf("update user: %v", err)
+		}
+		if _, err := f.service.RefreshSession(pair.RefreshToken, authModel.ClientInfo{}); err == nil {
+			t.Error("expected session issued before the password change to be rejected")
+		}
+		if n := f.sessionCount(t); n != 0 {
+			t.Errorf("sessions = %d, want stale session removed", n)
+		}
+	})
+}
+
+func TestRevokeSessions(t *testing.T) {
+	f := newSessionFixture(t)
+	first := f.login(t)
+	second := f.login(t)
+
+	claims, _ := jwtUtil.ParseToken(first.AccessToken)
+	sessions, err := f.service.ListSessions(f.user.ID, claims.SessionID)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(sessions) != 2 {
+		t.Fatalf("sessions = %d, want 2", len(sessions))
+	}
+	for _, session := range sessions {
+		if session.Current != (session.ID == claims.SessionID) {
+			t.Errorf("session %d current = %v", session.ID, session.Current)
+		}
+	}
+
+	// 不能吊销其他用户的会话
+	if err := f.service.RevokeSession(f.user.ID+1, claims.SessionID); err == nil {
+		t.Error("expected revoking another user's session to fail")
+	}
+	if err := f.service.RevokeSession(f.user.ID, claims.SessionID); err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+	if _, err := f.service.RefreshSession(first.RefreshToken, authModel.ClientInfo{}); err == nil {
+		t.Error("expected revoked session to be rejected")
+	}
+	if err := f.service.RevokeSession(f.user.ID, claims.SessionID); err == nil {
+		t.Error("expected revoking a missing session to fail")
+	}
+
+	if err := f.service.RevokeAllSessions(f.user.ID); err != nil {
+		t.Fatalf("revoke all: %v", err)
+	}
+	if _, err := f.service.RefreshSession(second.RefreshToken, authModel.ClientInfo{}); err == nil {
+		t.Error("expected all sessions to be revoked")
+	}
+}
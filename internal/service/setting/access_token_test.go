@@ -0,0 +1,301 @@
+package service
+
+import (
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/lin-snow/ech0/internal/database"
+	"github.com/lin-snow/ech0/internal/event"
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	model "github.com/lin-snow/ech0/internal/model/setting"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
+	commonRepository "github.com/lin-snow/ech0/internal/repository/common"
+	echoRepository "github.com/lin-snow/ech0/internal/repository/echo"
+	keyvalueRepository "github.com/lin-snow/ech0/internal/repository/keyvalue"
+	settingRepository "github.com/lin-snow/ech0/internal/repository/setting"
+	webhookRepository "github.com/lin-snow/ech0/internal/repository/webhook"
+	commonService "github.com/lin-snow/ech0/internal/service/common"
+	"github.com/lin-snow/ech0/internal/transaction"
+	cryptoUtil "github.com/lin-snow/ech0/internal/util/crypto"
+	logUtil "github.com/lin-snow/ech0/internal/util/log"
+	"go.uber.org/zap"
+)
+
+// memoryCache 同步写入的内存缓存，避免 Ristretto 异步写入导致测试不稳定
+type memoryCache struct {
+	mu    sync.Mutex
+	items map[string]any
+}
+
+func (c *memoryCache) Set(key string, value any, _ int64) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.items[key] = value
+	return true
+}
+
+func (c *memoryCache) SetWithTTL(key string, value any, cost int64, _ time.Duration) bool {
+	return c.Set(key, value, cost)
+}
+
+func (c *memoryCache) Get(key string) (any, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	value, ok := c.items[key]
+	if !ok {
+		return nil, errors.New("key not found")
+	}
+	return value, nil
+}
+
+func (c *memoryCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.items, key)
+}
+
+func (c *memoryCache) GetOrSet(key string, cost int64, fn func() (any, error)) (any, error) {
+	if value, err := c.Get(key); err == nil {
+		return value, nil
+	}
+	value, err := fn()
+	if err != nil {
+		return nil, err
+	}
+	c.Set(key, value, cost)
+	return value, nil
+}
+
+// accessTokenFixture 基于临时 SQLite 数据库的访问令牌测试环境
+type accessTokenFixture struct {
+	db      *gorm.DB
+	service SettingServiceInterface
+	alice   userModel.User // 编辑，可以管理自己的访问令牌
+	bob     userModel.User // 另一个编辑
+	carol   userModel.User // 成员，没有管理访问令牌的权限
+}
+
+func newAccessTokenFixture(t *testing.T) *accessTokenFixture {
+	t.Helper()
+	logUtil.Logger = zap.NewNop()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "ech0.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.AutoMigrate(database.Models()...); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	dbProvider := func() *gorm.DB { return db }
+	cache := &memoryCache{items: make(map[string]any)}
+	txManager := transaction.NewTransactionManager(dbProvider)
+	echoRepo := echoRepository.NewEchoRepository(dbProvider, cache)
+	kvRepo := keyvalueRepository.NewKeyValueRepository(dbProvider, cache)
+	busProvider := func() event.IEventBus { return nil }
+	commonSvc := commonService.NewCommonService(
+		txManager,
+		commonRepository.NewCommonRepository(dbProvider),
+		echoRepo,
+		kvRepo,
+		busProvider,
+	)
+
+	f := &accessTokenFixture{
+		db: db,
+		service: NewSettingService(
+			txManager,
+			commonSvc,
+			kvRepo,
+			settingRepository.NewSettingRepository(dbProvider),
+			webhookRepository.NewWebhookRepository(dbProvider),
+			nil,
+			busProvider,
+		),
+	}
+	for _, u := range []struct {
+		user *userModel.User
+		name string
+		role userModel.Role
+	}{
+		{&f.alice, "alice", userModel.RoleEditor},
+		{&f.bob, "bob", userModel.RoleEditor},
+		{&f.carol, "carol", userModel.RoleViewer},
+	} {
+		u.user.Username = u.name
+		u.user.Password = "x"
+		u.user.SetRole(u.role)
+		if err := db.Create(u.user).Error; err != nil {
+			t.Fatalf("create user: %v", err)
+		}
+	}
+	return f
+}
+
+// stored 按明文令牌的摘要读取数据库中的访问令牌
+func (f *accessTokenFixture) stored(t *testing.T, plain string) model.AccessTokenSetting {
+	t.Helper()
+	var token model.AccessTokenSetting
+	if err := f.db.Where("token_hash = ?", cryptoUtil.SHA256Hex(plain)).First(&token).Error; err != nil {
+		t.Fatalf("load token: %v", err)
+	}
+	return token
+}
+
+func TestCreateAccessToken(t *testing.T) {
+	f := newAccessTokenFixture(t)
+
+	tests := map[string]struct {
+		userId     uint
+		dto        model.AccessTokenSettingDto
+		wantErr    string
+		wantScopes []string
+		wantExpiry time.Duration // 0 表示永不过期
+	}{
+		"去除空白与重复项": {
+			userId:     f.alice.ID,
+			dto:        model.AccessTokenSettingDto{Scopes: []string{" echo:read ", "echo:read", "", "todo:*"}},
+			wantScopes: []string{model.ScopeEchoRead, "todo:*"},
+			wantExpiry: 8 * time.Hour,
+		},
+		"一个月有效期": {
+			userId:     f.alice.ID,
+			dto:        model.AccessTokenSettingDto{Expiry: model.ONE_MONTH_EXPIRY, Scopes: []string{model.ScopeEchoWrite}},
+			wantScopes: []string{model.ScopeEchoWrite},
+			wantExpiry: 30 * 24 * time.Hour,
+		},
+		"永不过期": {
+			userId:     f.alice.ID,
+			dto:        model.AccessTokenSettingDto{Expiry: model.NEVER_EXPIRY, Scopes: []string{"*"}},
+			wantScopes: []string{"*"},
+		},
+		"没有权限范围": {
+			userId:  f.alice.ID,
+			dto:     model.AccessTokenSettingDto{Scopes: []string{" "}},
+			wantErr: commonModel.ACCESS_TOKEN_SCOPE_REQUIRED,
+		},
+		"未知的权限范围": {
+			userId:  f.alice.ID,
+			dto:     model.AccessTokenSettingDto{Scopes: []string{model.ScopeEchoRead, "users:*"}},
+			wantErr: commonModel.ACCESS_TOKEN_SCOPE_INVALID,
+		},
+		"成员不能创建": {
+			userId:  f.carol.ID,
+			dto:     model.AccessTokenSettingDto{Scopes: []string{model.ScopeEchoRead}},
+			wantErr: commonModel.NO_PERMISSION_DENIED,
+		},
+	}
+
+	for name, tt := range tests {
+		dto := tt.dto
+		dto.Name = name
+		before := time.Now()
+		plain, err := f.service.CreateAccessToken(tt.userId, &dto)
+		got := ""
+		if err != nil {
+			got = err.Error()
+		}
+		if got != tt.wantErr {
+			t.Errorf("%s: err = %q, want %q", name, got, tt.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+
+		// 明文只返回一次，数据库只保存摘要与展示前缀
+		if !strings.HasPrefix(plain, model.AccessTokenPrefix) || len(plain) != len(model.AccessTokenPrefix)+64 {
+			t.Errorf("%s: token = %q", name, plain)
+		}
+		token := f.stored(t, plain)
+		if token.UserID != tt.userId || token.Name != name || token.Prefix != plain[:model.AccessTokenDisplayLength] ||
+			!slices.Equal(token.Scopes, tt.wantScopes) {
+			t.Errorf("%s: stored = %+v", name, token)
+		}
+		if tt.wantExpiry == 0 {
+			if token.Expiry != nil {
+				t.Errorf("%s: expiry = %v, want never", name, token.Expiry)
+			}
+		} else if token.Expiry == nil || token.Expiry.Before(before.Add(tt.wantExpiry)) ||
+			token.Expiry.After(time.Now().Add(tt.wantExpiry)) {
+			t.Errorf("%s: expiry = %v", name, token.Expiry)
+		}
+	}
+}
+
+func TestListAndDeleteAccessTokens(t *testing.T) {
+	f := newAccessTokenFixture(t)
+	create := func(userId uint, name string) model.AccessTokenSetting {
+		t.Helper()
+		plain, err := f.service.CreateAccessToken(userId, &model.AccessTokenSettingDto{
+			Name:   name,
+			Expiry: model.NEVER_EXPIRY,
+			Scopes: []string{model.ScopeEchoRead},
+		})
+		if err != nil {
+			t.Fatalf("create %s: %v", name, err)
+		}
+		return f.stored(t, plain)
+	}
+	aliceToken := create(f.alice.ID, "alice")
+	expired := create(f.alice.ID, "expired")
+	if err := f.db.Model(&expired).Update("expiry", time.Now().Add(-time.Minute)).Error; err != nil {
+		t.Fatalf("expire token: %v", err)
+	}
+	bobToken := create(f.bob.ID, "bob")
+
+	names := func(userId uint) []string {
+		t.Helper()
+		tokens, err := f.service.ListAccessTokens(userId)
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		var names []string
+		for _, token := range tokens {
+			names = append(names, token.Name)
+		}
+		return names
+	}
+
+	// 只列出自己未过期的令牌，过期令牌在列出时清理
+	if got := names(f.alice.ID); !slices.Equal(got, []string{"alice"}) {
+		t.Errorf("alice tokens = %v", got)
+	}
+	var count int64
+	f.db.Model(&model.AccessTokenSetting{}).Where("id = ?", expired.ID).Count(&count)
+	if count != 0 {
+		t.Error("expired token not purged")
+	}
+	if _, err := f.service.ListAccessTokens(f.carol.ID); err == nil || err.Error() != commonModel.NO_PERMISSION_DENIED {
+		t.Errorf("viewer list err = %v", err)
+	}
+
+	// 不能吊销其他用户的令牌
+	if err := f.service.DeleteAccessToken(f.alice.ID, uint(bobToken.ID)); err != nil {
+		t.Fatalf("delete other token: %v", err)
+	}
+	if got := names(f.bob.ID); !slices.Equal(got, []string{"bob"}) {
+		t.Errorf("bob tokens = %v", got)
+	}
+
+	if err := f.service.DeleteAccessToken(f.carol.ID, uint(aliceToken.ID)); err == nil ||
+		err.Error() != commonModel.NO_PERMISSION_DENIED {
+		t.Errorf("viewer delete err = %v", err)
+	}
+	if err := f.service.DeleteAccessToken(f.alice.ID, uint(aliceToken.ID)); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if got := names(f.alice.ID); len(got) != 0 {
+		t.Errorf("alice tokens after delete = %v", got)
+	}
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
 	model "github.com/lin-snow/ech0/internal/model/setting"
 	webhookModel "github.com/lin-snow/ech0/internal/model/webhook"
 )
@@ -45,6 +46,21 @@ type SettingServiceInterface interface {
 	// CreateWebhook 创建 Webhook
 	CreateWebhook(userid uint, newWebhook *model.WebhookDto) error
 
+	// ListWebhookDeliveries 分页获取 Webhook 投递记录
+	ListWebhookDeliveries(
+		userid, webhookID uint,
+		pageQueryDto commonModel.PageQueryDto,
+	) (commonModel.PageQueryResult[[]webhookModel.WebhookDelivery], error)
+
+	// GetWebhookDelivery 获取 Webhook 投递详情
+	GetWebhookDelivery(userid, webhookID, deliveryID uint) (*webhookModel.WebhookDelivery, error)
+
+	// RedeliverWebhookDelivery 立即重新投递一条历史记录
+	RedeliverWebhookDelivery(userid, webhookID, deliveryID uint) (*webhookModel.WebhookDelivery, error)
+
+	// PruneWebhookDeliveries 清理超过保留时长的 Webhook 投递记录
+	PruneWebhookDeliveries() error
+
 	// ListAccessTokens 列出访问令牌
 	ListAccessTokens(userid uint) ([]model.AccessTokenSetting, error)
 
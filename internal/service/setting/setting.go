@@ -6,21 +6,23 @@ import (
 	"strings"
 	"time"
 
+	"github.com/lin-snow/ech0/internal/authz"
 	"github.com/lin-snow/ech0/internal/config"
 	"github.com/lin-snow/ech0/internal/event"
 	authModel "github.com/lin-snow/ech0/internal/model/auth"
 	commonModel "github.com/lin-snow/ech0/internal/model/common"
 	model "github.com/lin-snow/ech0/internal/model/setting"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
 	webhookModel "github.com/lin-snow/ech0/internal/model/webhook"
 	keyvalueRepository "github.com/lin-snow/ech0/internal/repository/keyvalue"
 	settingRepository "github.com/lin-snow/ech0/internal/repository/setting"
 	webhookRepository "github.com/lin-snow/ech0/internal/repository/webhook"
 	commonService "github.com/lin-snow/ech0/internal/service/common"
 	"github.com/lin-snow/ech0/internal/transaction"
+	cryptoUtil "github.com/lin-snow/ech0/internal/util/crypto"
 	fmtUtil "github.com/lin-snow/ech0/internal/util/format"
 	httpUtil "github.com/lin-snow/ech0/internal/util/http"
 	jsonUtil "github.com/lin-snow/ech0/internal/util/json"
-	jwtUtil "github.com/lin-snow/ech0/internal/util/jwt"
 	logUtil "github.com/lin-snow/ech0/internal/util/log"
 	"go.uber.org/zap"
 )
@@ -31,6 +33,7 @@ type SettingService struct {
 	keyvalueRepository keyvalueRepository.KeyValueRepositoryInterface
 	settingRepository  settingRepository.SettingRepositoryInterface
 	webhookRepository  webhookRepository.WebhookRepositoryInterface
+	webhookDispatcher  *event.WebhookDispatcher
 	eventBus           event.IEventBus
 }
 
@@ -40,6 +43,7 @@ func NewSettingService(
 	keyvalueRepository keyvalueRepository.KeyValueRepositoryInterface,
 	settingRepository settingRepository.SettingRepositoryInterface,
 	webhookRepository webhookRepository.WebhookRepositoryInterface,
+	webhookDispatcher *event.WebhookDispatcher,
 	ebProvider func() event.IEventBus,
 ) SettingServiceInterface {
 	return &SettingService{
@@ -47,6 +51,7 @@ func NewSettingService(
 		commonService:      commonService,
 		keyvalueRepository: keyvalueRepository,
 		webhookRepository:  webhookRepository,
+		webhookDispatcher:  webhookDispatcher,
 		settingRepository:  settingRepository,
 		eventBus:           ebProvider(),
 	}
@@ -109,8 +114,8 @@ func (settingService *SettingService) UpdateSetting(
 		if err != nil {
 			return err
 		}
-		if !user.IsAdmin {
-			return errors.New(commonModel.NO_PERMISSION_DENIED)
+		if err := authz.Authorize(user, userModel.PermissionSystemSetting); err != nil {
+			return err
 		}
 
 		var setting model.SystemSetting
@@ -189,8 +194,8 @@ func (settingService *SettingService) UpdateCommentSetting(
 	if err != nil {
 		return err
 	}
-	if !user.IsAdmin {
-		return errors.New(commonModel.NO_PERMISSION_DENIED)
+	if err := authz.Authorize(user, userModel.PermissionSettingsManage); err != nil {
+		return err
 	}
 
 	return settingService.txManager.Run(func(ctx context.Context) error {
@@ -267,7 +272,7 @@ func (settingService *SettingService) GetS3Setting(userid uint, setting *model.S
 			if err != nil {
 				return err
 			}
-			if !user.IsAdmin {
+			if !user.Can(userModel.PermissionSettingsManage) {
 				setting.AccessKey = "******"
 				setting.SecretKey = "******"
 				setting.BucketName = "******"
@@ -288,8 +293,8 @@ func (settingService *SettingService) UpdateS3Setting(
 	if err != nil {
 		return err
 	}
-	if !user.IsAdmin {
-		return errors.New(commonModel.NO_PERMISSION_DENIED)
+	if err := authz.Authorize(user, userModel.PermissionSettingsManage); err != nil {
+		return err
 	}
 
 	return settingService.txManager.Run(func(ctx context.Context) error {
@@ -368,8 +373,8 @@ func (settingService *SettingService) GetOAuth2Setting(
 			if err != nil {
 				return err
 			}
-			if !user.IsAdmin {
-				return errors.New(commonModel.NO_PERMISSION_DENIED)
+			if err := authz.Authorize(user, userModel.PermissionSettingsManage); err != nil {
+				return err
 			}
 		}
 
@@ -413,7 +418,7 @@ func (settingService *SettingService) GetOAuth2Setting(
 	})
 }
 
-// UpdateOAuth2Setting 更新 OAuth2 设置
+// UpdateOAuth2Setting 更新 OAuth2 设置，登录方式属于系统设置，仅站长可以修改
 func (settingService *SettingService) UpdateOAuth2Setting(
 	userid uint,
 	newSetting *model.OAuth2SettingDto,
@@ -422,8 +427,8 @@ func (settingService *SettingService) UpdateOAuth2Setting(
 	if err != nil {
 		return err
 	}
-	if !user.IsAdmin {
-		return errors.New(commonModel.NO_PERMISSION_DENIED)
+	if err := authz.Authorize(user, userModel.PermissionSystemSetting); err != nil {
+		return err
 	}
 
 	return settingService.txManager.Run(func(ctx context.Context) error {
@@ -476,8 +481,8 @@ func (settingService *SettingService) GetAllWebhooks(userid uint) ([]webhookMode
 	if err != nil {
 		return nil, err
 	}
-	if !user.IsAdmin {
-		return nil, errors.New(commonModel.NO_PERMISSION_DENIED)
+	if err := authz.Authorize(user, userModel.PermissionSettingsManage); err != nil {
+		return nil, err
 	}
 
 	webhooks, err := settingService.webhookRepository.GetAllWebhooks()
@@ -495,8 +500,8 @@ func (settingService *SettingService) DeleteWebhook(userid, id uint) error {
 	if err != nil {
 		return err
 	}
-	if !user.IsAdmin {
-		return errors.New(commonModel.NO_PERMISSION_DENIED)
+	if err := authz.Authorize(user, userModel.PermissionSettingsManage); err != nil {
+		return err
 	}
 
 	return settingService.txManager.Run(func(ctx context.Context) error {
@@ -514,8 +519,8 @@ func (settingService *SettingService) UpdateWebhook(
 	if err != nil {
 		return err
 	}
-	if !user.IsAdmin {
-		return errors.New(commonModel.NO_PERMISSION_DENIED)
+	if err := authz.Authorize(user, userModel.PermissionSettingsManage); err != nil {
+		return err
 	}
 
 	// 数据处理
@@ -526,13 +531,22 @@ func (settingService *SettingService) UpdateWebhook(
 		return errors.New(commonModel.WEBHOOK_NAME_OR_URL_CANNOT_BE_EMPTY)
 	}
 
+	// 检查订阅的事件类型
+	events := normalizeWebhookEvents(newWebhook.Events)
+	if !webhookModel.ValidateEventPatterns(events) {
+		return errors.New(commonModel.WEBHOOK_EVENT_PATTERN_INVALID)
+	}
+
 	// 保存到数据库
 	webhook := &webhookModel.Webhook{
-		ID:       id,
-		Name:     newWebhook.Name,
-		URL:      newWebhook.URL,
-		Secret:   newWebhook.Secret,
-		IsActive: newWebhook.IsActive,
+		ID:         id,
+		Name:       newWebhook.Name,
+		URL:        newWebhook.URL,
+		Secret:     newWebhook.Secret,
+		IsActive:   newWebhook.IsActive,
+		Events:     events,
+		OnlyPublic: newWebhook.OnlyPublic,
+		TagFilter:  strings.TrimSpace(newWebhook.TagFilter),
 	}
 
 	return settingService.txManager.Run(func(ctx context.Context) error {
@@ -554,8 +568,8 @@ func (settingService *SettingService) CreateWebhook(
 	if err != nil {
 		return err
 	}
-	if !user.IsAdmin {
-		return errors.New(commonModel.NO_PERMISSION_DENIED)
+	if err := authz.Authorize(user, userModel.PermissionSettingsManage); err != nil {
+		return err
 	}
 
 	// 数据处理
@@ -566,12 +580,21 @@ func (settingService *SettingService) CreateWebhook(
 		return errors.New(commonModel.WEBHOOK_NAME_OR_URL_CANNOT_BE_EMPTY)
 	}
 
+	// 检查订阅的事件类型
+	events := normalizeWebhookEvents(newWebhook.Events)
+	if !webhookModel.ValidateEventPatterns(events) {
+		return errors.New(commonModel.WEBHOOK_EVENT_PATTERN_INVALID)
+	}
+
 	// 保存到数据库
 	webhook := &webhookModel.Webhook{
-		Name:     newWebhook.Name,
-		URL:      newWebhook.URL,
-		Secret:   newWebhook.Secret,
-		IsActive: newWebhook.IsActive,
+		Name:       newWebhook.Name,
+		URL:        newWebhook.URL,
+		Secret:     newWebhook.Secret,
+		IsActive:   newWebhook.IsActive,
+		Events:     events,
+		OnlyPublic: newWebhook.OnlyPublic,
+		TagFilter:  strings.TrimSpace(newWebhook.TagFilter),
 	}
 
 	return settingService.txManager.Run(func(ctx context.Context) error {
@@ -579,6 +602,134 @@ func (settingService *SettingService) CreateWebhook(
 	})
 }
 
+// ListWebhookDeliveries 分页获取 Webhook 投递记录
+func (settingService *SettingService) ListWebhookDeliveries(
+	userid, webhookID uint,
+	pageQueryDto commonModel.PageQueryDto,
+) (commonModel.PageQueryResult[[]webhookModel.WebhookDelivery], error) {
+	if _, err := settingService.getWebhookAsAdmin(userid, webhookID); err != nil {
+		return commonModel.PageQueryResult[[]webhookModel.WebhookDelivery]{}, err
+	}
+
+	if pageQueryDto.Page < 1 {
+		pageQueryDto.Page = 1
+	}
+	if pageQueryDto.PageSize < 1 || pageQueryDto.PageSize > 100 {
+		pageQueryDto.PageSize = 10
+	}
+	offset := (pageQueryDto.Page - 1) * pageQueryDto.PageSize
+
+	deliveries, total, err := settingService.webhookRepository.ListDeliveries(
+		webhookID,
+		offset,
+		pageQueryDto.PageSize,
+	)
+	if err != nil {
+		return commonModel.PageQueryResult[[]webhookModel.WebhookDelivery]{}, err
+	}
+
+	return commonModel.PageQueryResult[[]webhookModel.WebhookDelivery]{
+		Items: deliveries,
+		Total: total,
+	}, nil
+}
+
+// GetWebhookDelivery 获取 Webhook 投递详情
+func (settingService *SettingService) GetWebhookDelivery(
+	userid, webhookID, deliveryID uint,
+) (*webhookModel.WebhookDelivery, error) {
+	if _, err := settingService.getWebhookAsAdmin(userid, webhookID); err != nil {
+		return nil, err
+	}
+
+	delivery, err := settingService.webhookRepository.GetDeliveryByID(webhookID, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if delivery == nil {
+		return nil, errors.New(commonModel.WEBHOOK_DELIVERY_NOT_FOUND)
+	}
+
+	return delivery, nil
+}
+
+// RedeliverWebhookDelivery 使用当前 Webhook 配置立即重新投递一条历史记录
+func (settingService *SettingService) RedeliverWebhookDelivery(
+	userid, webhookID, deliveryID uint,
+) (*webhookModel.WebhookDelivery, error) {
+	webhook, err := settingService.getWebhookAsAdmin(userid, webhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	original, err := settingService.webhookRepository.GetDeliveryByID(webhookID, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if original == nil {
+		return nil, errors.New(commonModel.WEBHOOK_DELIVERY_NOT_FOUND)
+	}
+
+	// 投递失败时同样返回新的投递记录，便于查看失败原因
+	delivery, err := settingService.webhookDispatcher.Redeliver(webhook, original)
+	if delivery == nil {
+		return nil, err
+	}
+
+	return delivery, nil
+}
+
+// PruneWebhookDeliveries 清理超过保留时长的 Webhook 投递记录
+func (settingService *SettingService) PruneWebhookDeliveries() error {
+	before := time.Now().Add(-webhookModel.DeliveryRetention)
+
+	return settingService.txManager.Run(func(ctx context.Context) error {
+		_, err := settingService.webhookRepository.DeleteDeliveriesBefore(ctx, before)
+		return err
+	})
+}
+
+// getWebhookAsAdmin 校验管理员权限并获取 Webhook
+func (settingService *SettingService) getWebhookAsAdmin(
+	userid, webhookID uint,
+) (*webhookModel.Webhook, error) {
+	user, err := settingService.commonService.CommonGetUserByUserId(userid)
+	if err != nil {
+		return nil, err
+	}
+	if err := authz.Authorize(user, userModel.PermissionSettingsManage); err != nil {
+		return nil, err
+	}
+
+	webhook, err := settingService.webhookRepository.GetWebhookByID(webhookID)
+	if err != nil {
+		return nil, err
+	}
+	if webhook == nil {
+		return nil, errors.New(commonModel.WEBHOOK_NOT_FOUND)
+	}
+
+	return webhook, nil
+}
+
+// normalizeWebhookEvents 去除事件类型中的空白与重复项
+func normalizeWebhookEvents(events []string) []string {
+	normalized := make([]string, 0, len(events))
+	seen := make(map[string]struct{}, len(events))
+	for _, e := range events {
+		e = strings.TrimSpace(e)
+		if e == "" {
+			continue
+		}
+		if _, ok := seen[e]; ok {
+			continue
+		}
+		seen[e] = struct{}{}
+		normalized = append(normalized, e)
+	}
+	return normalized
+}
+
 // ListAccessTokens 列出访问令牌
 func (settingService *SettingService) ListAccessTokens(
 	userid uint,
@@ -588,8 +739,8 @@ func (settingService *SettingService) ListAccessTokens(
 	if err != nil {
 		return nil, err
 	}
-	if !user.IsAdmin {
-		return nil, errors.New(commonModel.NO_PERMISSION_DENIED)
+	if err := authz.Authorize(user, userModel.PermissionAccessToken); err != nil {
+		return nil, err
 	}
 
 	tokens, err := settingService.settingRepository.ListAccessTokens(user.ID)
@@ -602,13 +753,13 @@ func (settingService *SettingService) ListAccessTokens(
 	currentTime := time.Now()
 
 	for _, token := range tokens {
-		if token.Expiry == nil || token.Expiry.After(currentTime) {
-			// nil 表示永不过期，或者还没过期
+		if !token.IsExpired(currentTime) {
+			// 永不过期，或者还没过期
 			validTokens = append(validTokens, token)
 		} else {
 			// 删除过期 token
 			_ = settingService.txManager.Run(func(ctx context.Context) error {
-				return settingService.settingRepository.DeleteAccessTokenByID(ctx, uint(token.ID))
+				return settingService.settingRepository.DeleteAccessTokenByID(ctx, user.ID, uint(token.ID))
 			})
 		}
 	}
@@ -626,15 +777,17 @@ func (settingService *SettingService) CreateAccessToken(
 	if err != nil {
 		return "", err
 	}
-	if !user.IsAdmin {
-		return "", errors.New(commonModel.NO_PERMISSION_DENIED)
+	if err := authz.Authorize(user, userModel.PermissionAccessToken); err != nil {
+		return "", err
 	}
 
-	name := newToken.Name
-	expiry := newToken.Expiry
-	var expiryDuration time.Duration
+	scopes, err := normalizeAccessTokenScopes(newToken.Scopes)
+	if err != nil {
+		return "", err
+	}
 
-	switch expiry {
+	var expiryDuration time.Duration
+	switch newToken.Expiry {
 	case model.EIGHT_HOUR_EXPIRY:
 		expiryDuration = 8 * time.Hour
 	case model.ONE_MONTH_EXPIRY:
@@ -645,29 +798,30 @@ func (settingService *SettingService) CreateAccessToken(
 		expiryDuration = 8 * time.Hour
 	}
 
-	// 生成jwt令牌
-	claims := jwtUtil.CreateClaimsWithExpiry(user, int64(expiryDuration))
-	tokenString, err := jwtUtil.GenerateToken(claims)
+	// 生成不透明令牌，数据库只保存摘要
+	secret, err := cryptoUtil.GenerateSecureToken(32)
 	if err != nil {
 		return "", err
 	}
+	tokenString := model.AccessTokenPrefix + secret
 
 	// 处理数据库存储的 expiry
+	now := time.Now()
 	var expiryPtr *time.Time
-	if expiry == model.NEVER_EXPIRY {
-		expiryPtr = nil // 永不过期，用 NULL
-	} else {
-		t := time.Now().Add(expiryDuration)
+	if expiryDuration > 0 {
+		t := now.Add(expiryDuration)
 		expiryPtr = &t
 	}
 
 	// 保存到数据库
 	accessToken := &model.AccessTokenSetting{
 		UserID:    user.ID,
-		Token:     tokenString,
-		Name:      name,
+		TokenHash: cryptoUtil.SHA256Hex(tokenString),
+		Prefix:    model.AccessTokenDisplayPrefix(tokenString),
+		Name:      newToken.Name,
+		Scopes:    scopes,
 		Expiry:    expiryPtr,
-		CreatedAt: time.Now(),
+		CreatedAt: now,
 	}
 
 	if err := settingService.txManager.Run(func(ctx context.Context) error {
@@ -676,9 +830,34 @@ func (settingService *SettingService) CreateAccessToken(
 		return "", err
 	}
 
+	// 明文令牌仅在创建时返回一次
 	return tokenString, nil
 }
 
+// normalizeAccessTokenScopes 去除权限范围中的空白与重复项，并校验是否为已知权限范围
+func normalizeAccessTokenScopes(scopes []string) ([]string, error) {
+	normalized := make([]string, 0, len(scopes))
+	seen := make(map[string]struct{}, len(scopes))
+	for _, scope := range scopes {
+		scope = strings.TrimSpace(scope)
+		if scope == "" {
+			continue
+		}
+		if !model.IsValidScopePattern(scope) {
+			return nil, errors.New(commonModel.ACCESS_TOKEN_SCOPE_INVALID)
+		}
+		if _, ok := seen[scope]; ok {
+			continue
+		}
+		seen[scope] = struct{}{}
+		normalized = append(normalized, scope)
+	}
+	if len(normalized) == 0 {
+		return nil, errors.New(commonModel.ACCESS_TOKEN_SCOPE_REQUIRED)
+	}
+	return normalized, nil
+}
+
 // DeleteAccessToken 删除访问令牌
 func (settingService *SettingService) DeleteAccessToken(userid, id uint) error {
 	// 鉴权
@@ -686,12 +865,12 @@ func (settingService *SettingService) DeleteAccessToken(userid, id uint) error {
 	if err != nil {
 		return err
 	}
-	if !user.IsAdmin {
-		return errors.New(commonModel.NO_PERMISSION_DENIED)
+	if err := authz.Authorize(user, userModel.PermissionAccessToken); err != nil {
+		return err
 	}
 
 	return settingService.txManager.Run(func(ctx context.Context) error {
-		return settingService.settingRepository.DeleteAccessTokenByID(ctx, id)
+		return settingService.settingRepository.DeleteAccessTokenByID(ctx, user.ID, id)
 	})
 }
 
@@ -740,8 +919,8 @@ func (settingService *SettingService) UpdateFediverseSetting(
 		if err != nil {
 			return err
 		}
-		if !user.IsAdmin {
-			return errors.New(commonModel.NO_PERMISSION_DENIED)
+		if err := authz.Authorize(user, userModel.PermissionSettingsManage); err != nil {
+			return err
 		}
 
 		var setting model.FediverseSetting
@@ -821,8 +1000,8 @@ func (settingService *SettingService) UpdateBackupScheduleSetting(
 	if err != nil {
 		return err
 	}
-	if !user.IsAdmin {
-		return errors.New(commonModel.NO_PERMISSION_DENIED)
+	if err := authz.Authorize(user, userModel.PermissionSettingsManage); err != nil {
+		return err
 	}
 
 	return settingService.txManager.Run(func(ctx context.Context) error {
@@ -908,8 +1087,8 @@ func (settingService *SettingService) GetAgentSettings(
 	if err != nil {
 		return err
 	}
-	if !user.IsAdmin {
-		return errors.New(commonModel.NO_PERMISSION_DENIED)
+	if err := authz.Authorize(user, userModel.PermissionSettingsManage); err != nil {
+		return err
 	}
 
 	return settingService.txManager.Run(func(ctx context.Context) error {
@@ -955,8 +1134,8 @@ func (settingService *SettingService) UpdateAgentSettings(
 	if err != nil {
 		return err
 	}
-	if !user.IsAdmin {
-		return errors.New(commonModel.NO_PERMISSION_DENIED)
+	if err := authz.Authorize(user, userModel.PermissionSettingsManage); err != nil {
+		return err
 	}
 
 	if newSetting.Provider != string(commonModel.OpenAI) &&
@@ -5,8 +5,10 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/lin-snow/ech0/internal/authz"
 	commonModel "github.com/lin-snow/ech0/internal/model/common"
 	model "github.com/lin-snow/ech0/internal/model/todo"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
 	repository "github.com/lin-snow/ech0/internal/repository/todo"
 	commonService "github.com/lin-snow/ech0/internal/service/common"
 	"github.com/lin-snow/ech0/internal/transaction"
@@ -37,8 +39,8 @@ func (todoService *TodoService) GetTodoList(userid uint) ([]model.Todo, error) {
 	if err != nil {
 		return nil, err
 	}
-	if !user.IsAdmin {
-		return nil, errors.New(commonModel.NO_PERMISSION_DENIED)
+	if err := authz.Authorize(user, userModel.PermissionTodoManage); err != nil {
+		return nil, err
 	}
 
 	todos, err := todoService.todoRepository.GetTodosByUserID(userid)
@@ -63,8 +65,8 @@ func (todoService *TodoService) AddTodo(userid uint, todo *model.Todo) error {
 		if err != nil {
 			return err
 		}
-		if !user.IsAdmin {
-			return errors.New(commonModel.NO_PERMISSION_DENIED)
+		if err := authz.Authorize(user, userModel.PermissionTodoManage); err != nil {
+			return err
 		}
 
 		todos, err := todoService.todoRepository.GetTodosByUserID(userid)
@@ -103,8 +105,8 @@ func (todoService *TodoService) UpdateTodo(userid uint, id int64) error {
 		if err != nil {
 			return err
 		}
-		if !user.IsAdmin {
-			return errors.New(commonModel.NO_PERMISSION_DENIED)
+		if err := authz.Authorize(user, userModel.PermissionTodoManage); err != nil {
+			return err
 		}
 
 		// 获取 To do
@@ -139,8 +141,8 @@ func (todoService *TodoService) DeleteTodo(userid uint, id int64) error {
 		if err != nil {
 			return err
 		}
-		if !user.IsAdmin {
-			return errors.New(commonModel.NO_PERMISSION_DENIED)
+		if err := authz.Authorize(user, userModel.PermissionTodoManage); err != nil {
+			return err
 		}
 
 		// 获取 To do
@@ -0,0 +1,20 @@
+package service
+
+import trashModel "github.com/lin-snow/ech0/internal/model/trash"
+
+type TrashServiceInterface interface {
+	// ListTrash 获取回收站中的内容
+	ListTrash(userid uint, query trashModel.TrashQueryDto) ([]trashModel.TrashItem, error)
+
+	// RestoreTrashItem 将内容从回收站中恢复
+	RestoreTrashItem(userid uint, itemType string, id uint) error
+
+	// PurgeTrashItem 彻底删除回收站中的内容
+	PurgeTrashItem(userid uint, itemType string, id uint) error
+
+	// EmptyTrash 清空回收站
+	EmptyTrash(userid uint) (int, error)
+
+	// PurgeExpiredTrash 彻底删除超过保留天数的内容
+	PurgeExpiredTrash() (int, error)
+}
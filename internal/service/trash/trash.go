@@ -0,0 +1,359 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lin-snow/ech0/internal/authz"
+	"github.com/lin-snow/ech0/internal/config"
+	"github.com/lin-snow/ech0/internal/event"
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	echoModel "github.com/lin-snow/ech0/internal/model/echo"
+	todoModel "github.com/lin-snow/ech0/internal/model/todo"
+	trashModel "github.com/lin-snow/ech0/internal/model/trash"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
+	echoRepository "github.com/lin-snow/ech0/internal/repository/echo"
+	todoRepository "github.com/lin-snow/ech0/internal/repository/todo"
+	commonService "github.com/lin-snow/ech0/internal/service/common"
+	"github.com/lin-snow/ech0/internal/transaction"
+	logUtil "github.com/lin-snow/ech0/internal/util/log"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type TrashService struct {
+	txManager      transaction.TransactionManager
+	commonService  commonService.CommonServiceInterface
+	echoRepository echoRepository.EchoRepositoryInterface
+	todoRepository todoRepository.TodoRepositoryInterface
+	eventBus       event.IEventBus
+}
+
+func NewTrashService(
+	tm transaction.TransactionManager,
+	commonSvc commonService.CommonServiceInterface,
+	echoRepo echoRepository.EchoRepositoryInterface,
+	todoRepo todoRepository.TodoRepositoryInterface,
+	ebProvider func() event.IEventBus,
+) TrashServiceInterface {
+	return &TrashService{
+		txManager:      tm,
+		commonService:  commonSvc,
+		echoRepository: echoRepo,
+		todoRepository: todoRepo,
+		eventBus:       ebProvider(),
+	}
+}
+
+// ListTrash 获取回收站中的内容，按移入回收站的时间倒序
+func (trashService *TrashService) ListTrash(
+	userid uint,
+	query trashModel.TrashQueryDto,
+) ([]trashModel.TrashItem, error) {
+	if _, err := trashService.authorize(userid); err != nil {
+		return nil, err
+	}
+
+	itemType := strings.TrimSpace(query.Type)
+	if itemType != "" && !trashModel.IsValidTrashType(itemType) {
+		return nil, errors.New(commonModel.TRASH_INVALID_TYPE)
+	}
+
+	return trashService.listItems(itemType)
+}
+
+// RestoreTrashItem 将内容从回收站中恢复
+func (trashService *TrashService) RestoreTrashItem(userid uint, itemType string, id uint) error {
+	user, err := trashService.authorize(userid)
+	if err != nil {
+		return err
+	}
+
+	switch itemType {
+	case trashModel.TrashTypeEcho:
+		return trashService.restoreEcho(user, id)
+	case trashModel.TrashTypeTag:
+		return trashService.txManager.Run(func(ctx context.Context) error {
+			return notFound(trashService.echoRepository.RestoreTagById(ctx, id))
+		})
+	case trashModel.TrashTypeTodo:
+		return trashService.restoreTodo(id)
+	default:
+		return errors.New(commonModel.TRASH_INVALID_TYPE)
+	}
+}
+
+// PurgeTrashItem 彻底删除回收站中的内容
+func (trashService *TrashService) PurgeTrashItem(userid uint, itemType string, id uint) error {
+	if _, err := trashService.authorize(userid); err != nil {
+		return err
+	}
+	if !trashModel.IsValidTrashType(itemType) {
+		return errors.New(commonModel.TRASH_INVALID_TYPE)
+	}
+
+	return trashService.purgeItem(itemType, id)
+}
+
+// EmptyTrash 彻底删除回收站中的全部内容，返回删除的数量
+func (trashService *TrashService) EmptyTrash(userid uint) (int, error) {
+	if _, err := trashService.authorize(userid); err != nil {
+		return 0, err
+	}
+
+	items, err := trashService.listItems("")
+	if err != nil {
+		return 0, err
+	}
+
+	return trashService.purgeItems(items)
+}
+
+// PurgeExpiredTrash 彻底删除超过保留天数的内容，保留天数为 0 时不清理
+func (trashService *TrashService) PurgeExpiredTrash() (int, error) {
+	if config.Config.Trash.RetentionDays <= 0 {
+		return 0, nil
+	}
+
+	items, err := trashService.listItems("")
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	expired := make([]trashModel.TrashItem, 0, len(items))
+	for _, item := range items {
+		if item.PurgeAt != nil && !item.PurgeAt.After(now) {
+			expired = append(expired, item)
+		}
+	}
+
+	return trashService.purgeItems(expired)
+}
+
+// restoreEcho 恢复 Echo，已发布的 Echo 恢复后推送事件，由订阅方重建索引、删除 Tombstone 并向联邦宇宙重新推送 Create
+func (trashService *TrashService) restoreEcho(user userModel.User, id uint) error {
+	var echo *echoModel.Echo
+	if err := trashService.txManager.Run(func(ctx context.Context) error {
+		var err error
+		echo, err = trashService.echoRepository.GetTrashedEchoById(ctx, id)
+		if err != nil {
+			return err
+		}
+		if echo == nil {
+			return errors.New(commonModel.TRASH_ITEM_NOT_FOUND)
+		}
+		return notFound(trashService.echoRepository.RestoreEchoById(ctx, id))
+	}); err != nil {
+		return err
+	}
+
+	if !echo.IsPublished() {
+		return nil
+	}
+
+	if pubErr := trashService.eventBus.Publish(
+		context.Background(),
+		event.NewEvent(
+			event.EventTypeEchoRestored,
+			event.EventPayload{
+				event.EventPayloadEcho: *echo,
+				event.EventPayloadUser: user,
+			},
+		),
+	); pubErr != nil {
+		// 推送失败不影响恢复
+		logUtil.GetLogger().Error(pubErr.Error())
+	}
+
+	return nil
+}
+
+// restoreTodo 恢复待办事项，未完成的待办事项不能超过数量上限
+func (trashService *TrashService) restoreTodo(id uint) error {
+	return trashService.txManager.Run(func(ctx context.Context) error {
+		todo, err := trashService.todoRepository.GetTrashedTodoById(ctx, id)
+		if err != nil {
+			return err
+		}
+		if todo == nil {
+			return errors.New(commonModel.TRASH_ITEM_NOT_FOUND)
+		}
+
+		if todo.Status != uint(todoModel.Done) {
+			todos, err := trashService.todoRepository.GetTodosByUserID(todo.UserID)
+			if err != nil {
+				return err
+			}
+			notDone := 0
+			for _, t := range todos {
+				if t.Status != uint(todoModel.Done) {
+					notDone++
+				}
+			}
+			if notDone >= todoModel.MaxTodoCount {
+				return errors.New(commonModel.TRASH_TODO_LIMIT)
+			}
+		}
+
+		return notFound(trashService.todoRepository.RestoreTodoById(ctx, id))
+	})
+}
+
+// purgeItems 逐项彻底删除，单项失败不影响其余内容，返回成功删除的数量
+func (trashService *TrashService) purgeItems(items []trashModel.TrashItem) (int, error) {
+	purged := 0
+	var errs []error
+	for _, item := range items {
+		if err := trashService.purgeItem(item.Type, item.ID); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		purged++
+	}
+
+	return purged, errors.Join(errs...)
+}
+
+// purgeItem 彻底删除单项内容，Echo 的图片文件在数据库删除成功后清理；
+// 删除事件在移入回收站时已推送，彻底删除只清理本地数据
+func (trashService *TrashService) purgeItem(itemType string, id uint) error {
+	switch itemType {
+	case trashModel.TrashTypeEcho:
+		var echo *echoModel.Echo
+		if err := trashService.txManager.Run(func(ctx context.Context) error {
+			var err error
+			echo, err = trashService.echoRepository.GetTrashedEchoById(ctx, id)
+			if err != nil {
+				return err
+			}
+			if echo == nil {
+				return errors.New(commonModel.TRASH_ITEM_NOT_FOUND)
+			}
+			return notFound(trashService.echoRepository.PurgeEchoById(ctx, id))
+		}); err != nil {
+			return err
+		}
+
+		// 文件删除失败只记录日志，数据库记录已不存在，无法重试
+		for _, img := range echo.Images {
+			if err := trashService.commonService.DirectDeleteImage(
+				img.ImageURL,
+				img.ImageSource,
+				img.ObjectKey,
+			); err != nil {
+				logUtil.GetLogger().Error(
+					"Failed to delete image of purged echo",
+					zap.Uint("echo_id", id),
+					zap.String("image_url", img.ImageURL),
+					zap.String("error", err.Error()),
+				)
+			}
+		}
+		return nil
+	case trashModel.TrashTypeTag:
+		return trashService.txManager.Run(func(ctx context.Context) error {
+			return notFound(trashService.echoRepository.PurgeTagById(ctx, id))
+		})
+	case trashModel.TrashTypeTodo:
+		return trashService.txManager.Run(func(ctx context.Context) error {
+			return notFound(trashService.todoRepository.PurgeTodoById(ctx, id))
+		})
+	default:
+		return errors.New(commonModel.TRASH_INVALID_TYPE)
+	}
+}
+
+// listItems 汇总回收站中的内容，itemType 为空时返回全部类型
+func (trashService *TrashService) listItems(itemType string) ([]trashModel.TrashItem, error) {
+	items := []trashModel.TrashItem{}
+
+	if itemType == "" || itemType == trashModel.TrashTypeEcho {
+		echos, err := trashService.echoRepository.GetTrashedEchos()
+		if err != nil {
+			return nil, err
+		}
+		for _, echo := range echos {
+			items = append(items, newTrashItem(
+				trashModel.TrashTypeEcho,
+				echo.ID,
+				echo.Content,
+				echo.DeletedAt,
+			))
+		}
+	}
+
+	if itemType == "" || itemType == trashModel.TrashTypeTag {
+		tags, err := trashService.echoRepository.GetTrashedTags()
+		if err != nil {
+			return nil, err
+		}
+		for _, tag := range tags {
+			items = append(items, newTrashItem(trashModel.TrashTypeTag, tag.ID, tag.Name, tag.DeletedAt))
+		}
+	}
+
+	if itemType == "" || itemType == trashModel.TrashTypeTodo {
+		todos, err := trashService.todoRepository.GetTrashedTodos()
+		if err != nil {
+			return nil, err
+		}
+		for _, todo := range todos {
+			items = append(items, newTrashItem(
+				trashModel.TrashTypeTodo,
+				todo.ID,
+				todo.Content,
+				todo.DeletedAt,
+			))
+		}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].DeletedAt.After(items[j].DeletedAt)
+	})
+
+	return items, nil
+}
+
+// newTrashItem 生成回收站条目，并根据保留天数计算自动清理时间
+func newTrashItem(itemType string, id uint, content string, deletedAt gorm.DeletedAt) trashModel.TrashItem {
+	summary := []rune(strings.TrimSpace(content))
+	if len(summary) > trashModel.TrashSummaryLength {
+		summary = append(summary[:trashModel.TrashSummaryLength], []rune("…")...)
+	}
+
+	item := trashModel.TrashItem{
+		Type:      itemType,
+		ID:        id,
+		Summary:   string(summary),
+		DeletedAt: deletedAt.Time,
+	}
+	if days := config.Config.Trash.RetentionDays; days > 0 {
+		purgeAt := deletedAt.Time.AddDate(0, 0, days)
+		item.PurgeAt = &purgeAt
+	}
+	return item
+}
+
+// notFound 将记录不存在的错误转换为回收站的错误提示
+func notFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.New(commonModel.TRASH_ITEM_NOT_FOUND)
+	}
+	return err
+}
+
+// authorize 检查用户是否拥有管理回收站的权限
+func (trashService *TrashService) authorize(userid uint) (userModel.User, error) {
+	user, err := trashService.commonService.CommonGetUserByUserId(userid)
+	if err != nil {
+		return userModel.User{}, err
+	}
+	if err := authz.Authorize(user, userModel.PermissionTrashManage); err != nil {
+		return userModel.User{}, err
+	}
+	return user, nil
+}
@@ -0,0 +1,401 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/lin-snow/ech0/internal/config"
+	"github.com/lin-snow/ech0/internal/database"
+	"github.com/lin-snow/ech0/internal/event"
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	echoModel "github.com/lin-snow/ech0/internal/model/echo"
+	todoModel "github.com/lin-snow/ech0/internal/model/todo"
+	trashModel "github.com/lin-snow/ech0/internal/model/trash"
+	userModel "github.com/lin-snow/ech0/internal/model/user"
+	commonRepository "github.com/lin-snow/ech0/internal/repository/common"
+	echoRepository "github.com/lin-snow/ech0/internal/repository/echo"
+	keyvalueRepository "github.com/lin-snow/ech0/internal/repository/keyvalue"
+	todoRepository "github.com/lin-snow/ech0/internal/repository/todo"
+	commonService "github.com/lin-snow/ech0/internal/service/common"
+	echoService "github.com/lin-snow/ech0/internal/service/echo"
+	"github.com/lin-snow/ech0/internal/transaction"
+	logUtil "github.com/lin-snow/ech0/internal/util/log"
+	"go.uber.org/zap"
+)
+
+// memoryCache 同步写入的内存缓存，避免 Ristretto 异步写入导致测试不稳定
+type memoryCache struct {
+	mu    sync.Mutex
+	items map[string]any
+}
+
+func (c *memoryCache) Set(key string, value any, _ int64) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.items[key] = value
+	return true
+}
+
+func (c *memoryCache) SetWithTTL(key string, value any, cost int64, _ time.Duration) bool {
+	return c.Set(key, value, cost)
+}
+
+func (c *memoryCache) Get(key string) (any, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	value, ok := c.items[key]
+	if !ok {
+		return nil, errors.New("key not found")
+	}
+	return value, nil
+}
+
+func (c *memoryCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.items, key)
+}
+
+func (c *memoryCache) GetOrSet(key string, cost int64, fn func() (any, error)) (any, error) {
+	if value, err := c.Get(key); err == nil {
+		return value, nil
+	}
+	value, err := fn()
+	if err != nil {
+		return nil, err
+	}
+	c.Set(key, value, cost)
+	return value, nil
+}
+
+// recordingBus 记录发布的事件，不投递给任何订阅者
+type recordingBus struct {
+	mu     sync.Mutex
+	events []*event.Event
+}
+
+func (b *recordingBus) Publish(_ context.Context, e *event.Event) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.events = append(b.events, e)
+	return nil
+}
+
+func (b *recordingBus) Subscribe(event.EventHandler, event.EventType) error       { return nil }
+func (b *recordingBus) Subscribes(event.EventHandler, ...event.EventType) error   { return nil }
+func (b *recordingBus) SubscribeAll(event.EventHandler, ...event.EventType) error { return nil }
+
+// types 返回已发布事件的类型
+func (b *recordingBus) types() []event.EventType {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	types := make([]event.EventType, 0, len(b.events))
+	for _, e := range b.events {
+		types = append(types, e.Type)
+	}
+	return types
+}
+
+// last 返回最后发布的事件
+func (b *recordingBus) last() *event.Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.events) == 0 {
+		return nil
+	}
+	return b.events[len(b.events)-1]
+}
+
+// trashFixture 基于临时 SQLite 数据库的回收站服务测试环境，Echo 通过 Echo 服务移入回收站
+type trashFixture struct {
+	db      *gorm.DB
+	service *TrashService
+	echos   echoService.EchoServiceInterface
+	bus     *recordingBus
+	owner   userModel.User // 站长，拥有管理回收站的权限
+	alice   userModel.User // 编辑，没有管理回收站的权限
+}
+
+func newTrashFixture(t *testing.T) *trashFixture {
+	t.Helper()
+	logUtil.Logger = zap.NewNop()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "ech0.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.AutoMigrate(database.Models()...); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	dbProvider := func() *gorm.DB { return db }
+	cache := &memoryCache{items: make(map[string]any)}
+	txManager := transaction.NewTransactionManager(dbProvider)
+	echoRepo := echoRepository.NewEchoRepository(dbProvider, cache)
+	todoRepo := todoRepository.NewTodoRepository(dbProvider, cache)
+	commonRepo := commonRepository.NewCommonRepository(dbProvider)
+	kvRepo := keyvalueRepository.NewKeyValueRepository(dbProvider, cache)
+	bus := &recordingBus{}
+	busProvider := func() event.IEventBus { return bus }
+	commonSvc := commonService.NewCommonService(txManager, commonRepo, echoRepo, kvRepo, busProvider)
+
+	f := &trashFixture{
+		db:      db,
+		bus:     bus,
+		service: NewTrashService(txManager, commonSvc, echoRepo, todoRepo, busProvider).(*TrashService),
+		echos:   echoService.NewEchoService(txManager, commonSvc, echoRepo, commonRepo, nil, kvRepo, busProvider),
+	}
+	for _, u := range []struct {
+		user *userModel.User
+		name string
+		role userModel.Role
+	}{
+		{&f.owner, "owner", userModel.RoleOwner},
+		{&f.alice, "alice", userModel.RoleEditor},
+	} {
+		u.user.Username = u.name
+		u.user.Password = "x"
+		u.user.SetRole(u.role)
+		if err := db.Create(u.user).Error; err != nil {
+			t.Fatalf("create user: %v", err)
+		}
+	}
+	return f
+}
+
+// trashEcho 发布一条带标签的 Echo 后将其移入回收站
+func (f *trashFixture) trashEcho(t *testing.T, echo *echoModel.Echo) {
+	t.Helper()
+	if err := f.echos.PostEcho(f.alice.ID, echo); err != nil {
+		t.Fatalf("post: %v", err)
+	}
+	if err := f.echos.DeleteEchoById(f.alice.ID, echo.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+}
+
+// trashTodo 创建待办事项，trashed 为 true 时移入回收站
+func (f *trashFixture) trashTodo(t *testing.T, status uint, trashed bool) todoModel.Todo {
+	t.Helper()
+	todo := todoModel.Todo{Content: "todo", UserID: f.owner.ID, Status: status}
+	if err := f.db.Create(&todo).Error; err != nil {
+		t.Fatalf("create todo: %v", err)
+	}
+	if trashed {
+		if err := f.db.Delete(&todo).Error; err != nil {
+			t.Fatalf("trash todo: %v", err)
+		}
+	}
+	return todo
+}
+
+func TestEchoTrashLifecycleEvents(t *testing.T) {
+	f := newTrashFixture(t)
+	echo := &echoModel.Echo{Content: "hello", Tags: []echoModel.Tag{{Name: "日记"}}}
+	f.trashEcho(t, echo)
+
+	// 移入回收站时即推送删除事件，订阅方据此发送联邦 Delete 与 Tombstone
+	want := []event.EventType{event.EventTypeEchoCreated, event.EventTypeEchoDeleted}
+	if got := f.bus.types(); !slices.Equal(got, want) {
+		t.Fatalf("events after trash = %v, want %v", got, want)
+	}
+
+	if err := f.service.RestoreTrashItem(f.owner.ID, trashModel.TrashTypeEcho, echo.ID); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	want = append(want, event.EventTypeEchoRestored)
+	if got := f.bus.types(); !slices.Equal(got, want) {
+		t.Fatalf("events after restore = %v, want %v", got, want)
+	}
+	// 恢复事件携带完整的 Echo，订阅方据此重新推送 Create
+	restored, ok := f.bus.last().Payload[event.EventPayloadEcho].(echoModel.Echo)
+	if !ok || restored.ID != echo.ID || restored.Content != "hello" || len(restored.Tags) != 1 {
+		t.Errorf("restored payload = %+v", f.bus.last().Payload[event.EventPayloadEcho])
+	}
+
+	// 再次删除后彻底删除，不再推送任何事件
+	if err := f.echos.DeleteEchoById(f.alice.ID, echo.ID); err != nil {
+		t.Fatalf("delete again: %v", err)
+	}
+	want = append(want, event.EventTypeEchoDeleted)
+	if err := f.service.PurgeTrashItem(f.owner.ID, trashModel.TrashTypeEcho, echo.ID); err != nil {
+		t.Fatalf("purge: %v", err)
+	}
+	if got := f.bus.types(); !slices.Equal(got, want) {
+		t.Errorf("events after purge = %v, want %v", got, want)
+	}
+
+	// 彻底删除清理本地的 Echo 与标签关联
+	for name, model := range map[string]any{
+		"echo":     &echoModel.Echo{},
+		"echo_tag": &echoModel.EchoTag{},
+	} {
+		var count int64
+		if err := f.db.Unscoped().Model(model).Count(&count).Error; err != nil {
+			t.Fatalf("count %s: %v", name, err)
+		}
+		if count != 0 {
+			t.Errorf("%d %s rows left after purge", count, name)
+		}
+	}
+}
+
+func TestRestoreUnpublishedEchoIsSilent(t *testing.T) {
+	f := newTrashFixture(t)
+	draft := &echoModel.Echo{Content: "draft", Status: echoModel.EchoStatusDraft}
+	f.trashEcho(t, draft)
+	if err := f.service.RestoreTrashItem(f.owner.ID, trashModel.TrashTypeEcho, draft.ID); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	// 草稿从未推送，删除与恢复都无需推送
+	if got := f.bus.types(); len(got) != 0 {
+		t.Errorf("events = %v", got)
+	}
+}
+
+func TestTrashItemErrors(t *testing.T) {
+	f := newTrashFixture(t)
+	echo := &echoModel.Echo{Content: "hello"}
+	f.trashEcho(t, echo)
+	live := &echoModel.Echo{Content: "live"}
+	if err := f.echos.PostEcho(f.alice.ID, live); err != nil {
+		t.Fatalf("post: %v", err)
+	}
+
+	tests := map[string]struct {
+		run     func() error
+		wantErr string
+	}{
+		"编辑不能管理回收站": {
+			run:     func() error { return f.service.RestoreTrashItem(f.alice.ID, trashModel.TrashTypeEcho, echo.ID) },
+			wantErr: commonModel.NO_PERMISSION_DENIED,
+		},
+		"无效的类型": {
+			run:     func() error { return f.service.PurgeTrashItem(f.owner.ID, "file", echo.ID) },
+			wantErr: commonModel.TRASH_INVALID_TYPE,
+		},
+		"未删除的 Echo 不能恢复": {
+			run:     func() error { return f.service.RestoreTrashItem(f.owner.ID, trashModel.TrashTypeEcho, live.ID) },
+			wantErr: commonModel.TRASH_ITEM_NOT_FOUND,
+		},
+		"未删除的 Echo 不能彻底删除": {
+			run:     func() error { return f.service.PurgeTrashItem(f.owner.ID, trashModel.TrashTypeEcho, live.ID) },
+			wantErr: commonModel.TRASH_ITEM_NOT_FOUND,
+		},
+		"不存在的标签": {
+			run:     func() error { return f.service.RestoreTrashItem(f.owner.ID, trashModel.TrashTypeTag, 9999) },
+			wantErr: commonModel.TRASH_ITEM_NOT_FOUND,
+		},
+		"不存在的待办事项": {
+			run:     func() error { return f.service.PurgeTrashItem(f.owner.ID, trashModel.TrashTypeTodo, 9999) },
+			wantErr: commonModel.TRASH_ITEM_NOT_FOUND,
+		},
+	}
+
+	for name, tt := range tests {
+		err := tt.run()
+		got := ""
+		if err != nil {
+			got = err.Error()
+		}
+		if got != tt.wantErr {
+			t.Errorf("%s: err = %q, want %q", name, got, tt.wantErr)
+		}
+	}
+}
+
+func TestRestoreTodoLimit(t *testing.T) {
+	tests := map[string]struct {
+		notDone int  // 未删除且未完成的待办事项数量
+		status  uint // 回收站中待办事项的状态
+		wantErr string
+	}{
+		"未达上限":       {notDone: todoModel.MaxTodoCount - 1},
+		"已达上限":       {notDone: todoModel.MaxTodoCount, wantErr: commonModel.TRASH_TODO_LIMIT},
+		"已完成的不受上限限制": {notDone: todoModel.MaxTodoCount, status: todoModel.Done},
+	}
+
+	for name, tt := range tests {
+		f := newTrashFixture(t)
+		for range tt.notDone {
+			f.trashTodo(t, 0, false)
+		}
+		todo := f.trashTodo(t, tt.status, true)
+
+		err := f.service.RestoreTrashItem(f.owner.ID, trashModel.TrashTypeTodo, todo.ID)
+		got := ""
+		if err != nil {
+			got = err.Error()
+		}
+		if got != tt.wantErr {
+			t.Errorf("%s: err = %q, want %q", name, got, tt.wantErr)
+		}
+	}
+}
+
+func TestEmptyAndPurgeExpiredTrash(t *testing.T) {
+	original := config.Config.Trash.RetentionDays
+	t.Cleanup(func() { config.Config.Trash.RetentionDays = original })
+
+	f := newTrashFixture(t)
+	old := &echoModel.Echo{Content: "old"}
+	f.trashEcho(t, old)
+	recent := &echoModel.Echo{Content: "recent"}
+	f.trashEcho(t, recent)
+	todo := f.trashTodo(t, 0, true)
+	if err := f.db.Unscoped().Model(&echoModel.Echo{}).Where("id = ?", old.ID).
+		Update("deleted_at", time.Now().AddDate(0, 0, -31)).Error; err != nil {
+		t.Fatalf("age echo: %v", err)
+	}
+	events := len(f.bus.types())
+
+	// 保留天数为 0 时不自动清理
+	config.Config.Trash.RetentionDays = 0
+	if count, err := f.service.PurgeExpiredTrash(); err != nil || count != 0 {
+		t.Fatalf("purge disabled = %d, %v", count, err)
+	}
+
+	config.Config.Trash.RetentionDays = 30
+	items, err := f.service.ListTrash(f.owner.ID, trashModel.TrashQueryDto{})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(items) != 3 || items[len(items)-1].ID != old.ID || items[0].PurgeAt == nil {
+		t.Fatalf("trash = %+v", items)
+	}
+
+	if count, err := f.service.PurgeExpiredTrash(); err != nil || count != 1 {
+		t.Fatalf("purge expired = %d, %v", count, err)
+	}
+	items, err = f.service.ListTrash(f.owner.ID, trashModel.TrashQueryDto{Type: trashModel.TrashTypeEcho})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(items) != 1 || items[0].ID != recent.ID {
+		t.Errorf("echos left = %+v", items)
+	}
+
+	count, err := f.service.EmptyTrash(f.owner.ID)
+	if err != nil || count != 2 {
+		t.Fatalf("empty = %d, %v", count, err)
+	}
+	var left int64
+	f.db.Unscoped().Model(&todoModel.Todo{}).Where("id = ?", todo.ID).Count(&left)
+	if left != 0 {
+		t.Error("todo left after emptying trash")
+	}
+	// 清理只删除本地数据，不推送事件
+	if got := len(f.bus.types()); got != events {
+		t.Errorf("purging published %d events", got-events)
+	}
+}
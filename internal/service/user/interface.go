@@ -9,7 +9,7 @@ import (
 
 type UserServiceInterface interface {
 	// Login 用户登录
-	Login(user *authModel.LoginDto) (string, error)
+	Login(user *authModel.LoginDto, client authModel.ClientInfo) (authModel.LoginResultDto, error)
 
 	// GetUserByID 根据用户ID获取用户信息
 	GetUserByID(userId int) (model.User, error)
@@ -20,9 +20,15 @@ type UserServiceInterface interface {
 	// UpdateUser 更新用户信息
 	UpdateUser(userid uint, userdto model.UserInfoDto) error
 
-	// UpdateUserAdmin 更新用户的管理员权限
+	// UpdateUserAdmin 切换用户的管理员身份
 	UpdateUserAdmin(userid uint, id uint) error
 
+	// UpdateUserRole 修改用户角色
+	UpdateUserRole(userid uint, id uint, role string) error
+
+	// GetRoles 获取全部角色及其拥有的权限
+	GetRoles() []model.RoleDto
+
 	// GetAllUsers 获取所有用户
 	GetAllUsers() ([]model.User, error)
 
@@ -39,7 +45,14 @@ type UserServiceInterface interface {
 	GetOAuthLoginURL(provider string, redirectURI string) (string, error)
 
 	// HandleOAuthCallback 处理 OAuth2 回调
-	HandleOAuthCallback(provider string, code string, state string) string
+	HandleOAuthCallback(
+		provider string,
+		code string,
+		state string,
+	) string
+
+	// ExchangeOAuthLoginCode 使用 OAuth2 一次性登录码换取令牌
+	ExchangeOAuthLoginCode(code string, client authModel.ClientInfo) (authModel.LoginResultDto, error)
 
 	// GetOAuthInfo 获取 OAuth2 配置信息
 	GetOAuthInfo(userId uint, provider string) (model.OAuthInfoDto, error)
@@ -51,8 +64,25 @@ type UserServiceInterface interface {
 	) (authModel.PasskeyRegisterBeginResp, error)
 	PasskeyRegisterFinish(userID uint, rpID, origin, nonce string, credential json.RawMessage) error
 	PasskeyLoginBegin(rpID, origin string) (authModel.PasskeyLoginBeginResp, error)
-	PasskeyLoginFinish(rpID, origin, nonce string, credential json.RawMessage) (string, error)
+	PasskeyLoginFinish(
+		rpID, origin, nonce string,
+		credential json.RawMessage,
+		client authModel.ClientInfo,
+	) (authModel.LoginResultDto, error)
 	ListPasskeys(userID uint) ([]authModel.PasskeyDeviceDto, error)
 	DeletePasskey(userID, passkeyID uint) error
 	UpdatePasskeyDeviceName(userID, passkeyID uint, deviceName string) error
+
+	// 两步验证
+	GetTwoFactorStatus(userID uint) (authModel.TwoFactorStatusDto, error)
+	SetupTwoFactor(userID uint) (authModel.TwoFactorSetupDto, error)
+	EnableTwoFactor(userID uint, code string) (authModel.RecoveryCodesDto, error)
+	DisableTwoFactor(userID uint, code string) error
+	RegenerateRecoveryCodes(userID uint, code string) (authModel.RecoveryCodesDto, error)
+	UpdateTwoFactorRequired(userid, id uint, required bool) error
+	VerifyTwoFactorLogin(
+		token, code string,
+		client authModel.ClientInfo,
+	) (authModel.TwoFactorLoginResultDto, error)
+	SetupTwoFactorForLogin(token string) (authModel.TwoFactorSetupDto, error)
 }
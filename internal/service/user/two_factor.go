@@ -0,0 +1,490 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"encoding/base64"
+	"errors"
+	"image/png"
+	"strings"
+	"time"
+
+	"github.com/lin-snow/ech0/internal/authz"
+	authModel "github.com/lin-snow/ech0/internal/model/auth"
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	model "github.com/lin-snow/ech0/internal/model/user"
+	repository "github.com/lin-snow/ech0/internal/repository/user"
+	cryptoUtil "github.com/lin-snow/ech0/internal/util/crypto"
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+)
+
+const (
+	totpPeriod       = 30  // TOTP 时间步长，单位为秒
+	totpSkew         = 1   // 允许前后偏差的时间步数量，用于容忍设备时钟误差
+	totpQRCodeSize   = 256 // 二维码图片边长，单位为像素
+	recoveryCodeSize = 10  // 恢复码字符数，展示时每 5 个字符以连字符分隔
+)
+
+// GetTwoFactorStatus 获取用户的两步验证状态
+func (userService *UserService) GetTwoFactorStatus(
+	userID uint,
+) (authModel.TwoFactorStatusDto, error) {
+	user, err := userService.userRepository.GetUserByID(int(userID))
+	if err != nil {
+		return authModel.TwoFactorStatusDto{}, err
+	}
+
+	status := authModel.TwoFactorStatusDto{Required: user.TwoFactorRequired}
+	twoFactor, err := userService.userRepository.GetTwoFactorByUserID(userID)
+	if err != nil {
+		return status, err
+	}
+	if twoFactor == nil || !twoFactor.Enabled {
+		return status, nil
+	}
+
+	status.Enabled = true
+	status.EnabledAt = twoFactor.EnabledAt
+	status.RecoveryCodesCount, err = userService.userRepository.CountRecoveryCodes(userID)
+	return status, err
+}
+
+// SetupTwoFactor 为用户生成新的 TOTP 密钥，需要提交认证器中的验证码确认后才会启用
+func (userService *UserService) SetupTwoFactor(userID uint) (authModel.TwoFactorSetupDto, error) {
+	user, err := userService.userRepository.GetUserByID(int(userID))
+	if err != nil {
+		return authModel.TwoFactorSetupDto{}, err
+	}
+	return userService.setupTwoFactor(user)
+}
+
+// EnableTwoFactor 校验认证器中的验证码并启用两步验证，返回新生成的恢复码
+func (userService *UserService) EnableTwoFactor(
+	userID uint,
+	code string,
+) (authModel.RecoveryCodesDto, error) {
+	twoFactor, err := userService.userRepository.GetTwoFactorByUserID(userID)
+	if err != nil {
+		return authModel.RecoveryCodesDto{}, err
+	}
+	if twoFactor == nil {
+		return authModel.RecoveryCodesDto{}, errors.New(commonModel.TWO_FACTOR_NOT_SETUP)
+	}
+	if twoFactor.Enabled {
+		return authModel.RecoveryCodesDto{}, errors.New(commonModel.TWO_FACTOR_ALREADY_ENABLED)
+	}
+
+	ok, err := userService.checkTOTP(twoFactor, code)
+	if err != nil {
+		return authModel.RecoveryCodesDto{}, err
+	}
+	if !ok {
+		return authModel.RecoveryCodesDto{}, errors.New(commonModel.TWO_FACTOR_CODE_INVALID)
+	}
+
+	codes, err := userService.enableTwoFactor(twoFactor)
+	if err != nil {
+		return authModel.RecoveryCodesDto{}, err
+	}
+	return authModel.RecoveryCodesDto{RecoveryCodes: codes}, nil
+}
+
+// DisableTwoFactor 使用验证码或恢复码关闭两步验证，被管理员要求使用两步验证的用户不能关闭
+func (userService *UserService) DisableTwoFactor(userID uint, code string) error {
+	user, err := userService.userRepository.GetUserByID(int(userID))
+	if err != nil {
+		return err
+	}
+	if user.TwoFactorRequired {
+		return errors.New(commonModel.TWO_FACTOR_REQUIRED_CANNOT_CLOSE)
+	}
+
+	twoFactor, err := userService.getEnabledTwoFactor(userID)
+	if err != nil {
+		return err
+	}
+	if err := userService.verifyTwoFactorCode(twoFactor, code); err != nil {
+		return err
+	}
+
+	return userService.txManager.Run(func(ctx context.Context) error {
+		return userService.userRepository.DeleteTwoFactor(ctx, userID)
+	})
+}
+
+// RegenerateRecoveryCodes 校验验证码后重新生成恢复码，旧的恢复码全部失效
+func (userService *UserService) RegenerateRecoveryCodes(
+	userID uint,
+	code string,
+) (authModel.RecoveryCodesDto, error) {
+	twoFactor, err := userService.getEnabledTwoFactor(userID)
+	if err != nil {
+		return authModel.RecoveryCodesDto{}, err
+	}
+	if err := userService.verifyTwoFactorCode(twoFactor, code); err != nil {
+		return authModel.RecoveryCodesDto{}, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return authModel.RecoveryCodesDto{}, err
+	}
+	if err := userService.txManager.Run(func(ctx context.Context) error {
+		return userService.userRepository.ReplaceRecoveryCodes(ctx, userID, hashes)
+	}); err != nil {
+		return authModel.RecoveryCodesDto{}, err
+	}
+	return authModel.RecoveryCodesDto{RecoveryCodes: codes}, nil
+}
+
+// UpdateTwoFactorRequired 要求或取消要求指定用户使用两步验证登录，需要拥有用户管理权限
+// 被要求的用户下次登录时必须先绑定认证器，站长的设置只能由站长本人修改
+func (userService *UserService) UpdateTwoFactorRequired(userid, id uint, required bool) error {
+	operator, err := userService.userRepository.GetUserByID(int(userid))
+	if err != nil {
+		return err
+	}
+	if err := authz.Authorize(operator, model.PermissionUserManage); err != nil {
+		return err
+	}
+
+	user, err := userService.userRepository.GetUserByID(int(id))
+	if err != nil {
+		return err
+	}
+	if user.Role == model.RoleOwner && operator.ID != user.ID {
+		return errors.New(commonModel.TWO_FACTOR_REQUIRED_CANNOT_CHANGE)
+	}
+	user.TwoFactorRequired = required
+
+	return userService.txManager.Run(func(ctx context.Context) error {
+		return userService.userRepository.UpdateUser(ctx, &user)
+	})
+}
+
+// VerifyTwoFactorLogin 完成登录的第二步验证，验证通过后以第一步的登录方式创建登录会话
+// 登录挑战要求先绑定认证器时，验证通过即启用两步验证，并一并返回恢复码
+func (userService *UserService) VerifyTwoFactorLogin(
+	token, code string,
+	client authModel.ClientInfo,
+) (authModel.TwoFactorLoginResultDto, error) {
+	var result authModel.TwoFactorLoginResultDto
+
+	cacheKey := repository.GetTwoFactorChallengeKey(token)
+	challenge, err := userService.userRepository.CacheGetTwoFactorChallenge(cacheKey)
+	if err != nil {
+		return result, errors.New(commonModel.TWO_FACTOR_CHALLENGE_INVALID)
+	}
+
+	user, err := userService.userRepository.GetUserByID(int(challenge.UserID))
+	if err != nil {
+		userService.userRepository.CacheDeleteTwoFactorChallenge(cacheKey)
+		return result, errors.New(commonModel.TWO_FACTOR_CHALLENGE_INVALID)
+	}
+	if err := userService.loginLockout.Check(user.Username); err != nil {
+		return result, err
+	}
+	twoFactor, err := userService.userRepository.GetTwoFactorByUserID(user.ID)
+	if err != nil {
+		return result, err
+	}
+
+	if challenge.SetupRequired {
+		// 登录过程中绑定认证器，只接受认证器生成的验证码
+		if twoFactor == nil || twoFactor.Enabled {
+			return result, errors.New(commonModel.TWO_FACTOR_NOT_SETUP)
+		}
+		ok, err := userService.checkTOTP(twoFactor, code)
+		if err != nil {
+			return result, err
+		}
+		if !ok {
+			return result, userService.failTwoFactorChallenge(cacheKey, challenge, user.Username)
+		}
+		if result.RecoveryCodes, err = userService.enableTwoFactor(twoFactor); err != nil {
+			return result, err
+		}
+	} else {
+		// 两步验证在登录过程中被关闭时，要求重新登录
+		if twoFactor == nil || !twoFactor.Enabled {
+			userService.userRepository.CacheDeleteTwoFactorChallenge(cacheKey)
+			return result, errors.New(commonModel.TWO_FACTOR_CHALLENGE_INVALID)
+		}
+		if err := userService.verifyTwoFactorCode(twoFactor, code); err != nil {
+			if err.Error() != commonModel.TWO_FACTOR_CODE_INVALID {
+				return result, err
+			}
+			return result, userService.failTwoFactorChallenge(cacheKey, challenge, user.Username)
+		}
+	}
+
+	// 登录挑战只能成功使用一次
+	userService.userRepository.CacheDeleteTwoFactorChallenge(cacheKey)
+	userService.loginLockout.Reset(user.Username)
+
+	result.TokenPairDto, err = userService.sessionService.CreateSession(
+		user,
+		challenge.LoginMethod,
+		client,
+	)
+	return result, err
+}
+
+// SetupTwoFactorForLogin 为被要求使用两步验证但尚未绑定认证器的用户在登录过程中生成密钥
+func (userService *UserService) SetupTwoFactorForLogin(
+	token string,
+) (authModel.TwoFactorSetupDto, error) {
+	challenge, err := userService.userRepository.CacheGetTwoFactorChallenge(
+		repository.GetTwoFactorChallengeKey(token),
+	)
+	if err != nil {
+		return authModel.TwoFactorSetupDto{}, errors.New(commonModel.TWO_FACTOR_CHALLENGE_INVALID)
+	}
+	if !challenge.SetupRequired {
+		return authModel.TwoFactorSetupDto{}, errors.New(commonModel.TWO_FACTOR_SETUP_NOT_REQUIRED)
+	}
+
+	user, err := userService.userRepository.GetUserByID(int(challenge.UserID))
+	if err != nil {
+		return authModel.TwoFactorSetupDto{}, err
+	}
+	return userService.setupTwoFactor(user)
+}
+
+// completeLogin 第一步验证通过后完成登录，启用了两步验证或被要求使用两步验证时返回登录挑战，否则直接创建登录会话
+// 密码、OAuth2 / OIDC 与 Passkey 登录都经由这里创建会话，任何登录方式都不能绕过两步验证
+func (userService *UserService) completeLogin(
+	user model.User,
+	loginMethod string,
+	client authModel.ClientInfo,
+) (authModel.LoginResultDto, error) {
+	twoFactor, err := userService.userRepository.GetTwoFactorByUserID(user.ID)
+	if err != nil {
+		return authModel.LoginResultDto{}, err
+	}
+	enabled := twoFactor != nil && twoFactor.Enabled
+	if enabled || user.TwoFactorRequired {
+		return userService.newTwoFactorChallenge(user.ID, loginMethod, !enabled)
+	}
+
+	tokenPair, err := userService.sessionService.CreateSession(user, loginMethod, client)
+	return authModel.LoginResultDto{TokenPairDto: tokenPair}, err
+}
+
+// newTwoFactorChallenge 第一步验证通过后创建两步验证登录挑战
+func (userService *UserService) newTwoFactorChallenge(
+	userID uint,
+	loginMethod string,
+	setupRequired bool,
+) (authModel.LoginResultDto, error) {
+	token, err := cryptoUtil.GenerateSecureToken(32)
+	if err != nil {
+		return authModel.LoginResultDto{}, err
+	}
+
+	userService.userRepository.CacheSetTwoFactorChallenge(
+		repository.GetTwoFactorChallengeKey(token),
+		&authModel.TwoFactorChallenge{
+			UserID:        userID,
+			LoginMethod:   loginMethod,
+			SetupRequired: setupRequired,
+		},
+		authModel.TwoFactorChallengeTTL,
+	)
+
+	return authModel.LoginResultDto{
+		TwoFactorRequired: true,
+		TwoFactorSetup:    setupRequired,
+		TwoFactorToken:    token,
+	}, nil
+}
+
+// failTwoFactorChallenge 记录一次验证失败并计入登录失败锁定，失败次数过多时作废登录挑战
+func (userService *UserService) failTwoFactorChallenge(
+	cacheKey string,
+	challenge *authModel.TwoFactorChallenge,
+	username string,
+) error {
+	userService.loginLockout.Fail(username)
+	challenge.Attempts++
+	if challenge.Attempts >= authModel.TwoFactorMaxAttempts {
+		userService.userRepository.CacheDeleteTwoFactorChallenge(cacheKey)
+		return errors.New(commonModel.TWO_FACTOR_TOO_MANY_ATTEMPTS)
+	}
+	return errors.New(commonModel.TWO_FACTOR_CODE_INVALID)
+}
+
+// setupTwoFactor 生成新的 TOTP 密钥并保存为未启用状态，已启用两步验证时拒绝覆盖
+func (userService *UserService) setupTwoFactor(
+	user model.User,
+) (authModel.TwoFactorSetupDto, error) {
+	twoFactor, err := userService.userRepository.GetTwoFactorByUserID(user.ID)
+	if err != nil {
+		return authModel.TwoFactorSetupDto{}, err
+	}
+	if twoFactor != nil && twoFactor.Enabled {
+		return authModel.TwoFactorSetupDto{}, errors.New(commonModel.TWO_FACTOR_ALREADY_ENABLED)
+	}
+	if twoFactor == nil {
+		twoFactor = &authModel.TwoFactor{UserID: user.ID}
+	}
+
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      authModel.TwoFactorIssuer,
+		AccountName: user.Username,
+		Period:      totpPeriod,
+		Digits:      otp.DigitsSix,
+		Algorithm:   otp.AlgorithmSHA1,
+	})
+	if err != nil {
+		return authModel.TwoFactorSetupDto{}, err
+	}
+
+	twoFactor.Secret = key.Secret()
+	twoFactor.LastUsedStep = 0
+	if err := userService.txManager.Run(func(ctx context.Context) error {
+		return userService.userRepository.SaveTwoFactor(ctx, twoFactor)
+	}); err != nil {
+		return authModel.TwoFactorSetupDto{}, err
+	}
+
+	img, err := key.Image(totpQRCodeSize, totpQRCodeSize)
+	if err != nil {
+		return authModel.TwoFactorSetupDto{}, err
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return authModel.TwoFactorSetupDto{}, err
+	}
+
+	return authModel.TwoFactorSetupDto{
+		Secret: key.Secret(),
+		URI:    key.URL(),
+		QRCode: "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes()),
+	}, nil
+}
+
+// enableTwoFactor 启用两步验证并生成恢复码
+func (userService *UserService) enableTwoFactor(twoFactor *authModel.TwoFactor) ([]string, error) {
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	twoFactor.Enabled = true
+	twoFactor.EnabledAt = &now
+	if err := userService.txManager.Run(func(ctx context.Context) error {
+		if err := userService.userRepository.SaveTwoFactor(ctx, twoFactor); err != nil {
+			return err
+		}
+		return userService.userRepository.ReplaceRecoveryCodes(ctx, twoFactor.UserID, hashes)
+	}); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// getEnabledTwoFactor 获取用户已启用的两步验证配置
+func (userService *UserService) getEnabledTwoFactor(userID uint) (*authModel.TwoFactor, error) {
+	twoFactor, err := userService.userRepository.GetTwoFactorByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if twoFactor == nil || !twoFactor.Enabled {
+		return nil, errors.New(commonModel.TWO_FACTOR_NOT_ENABLED)
+	}
+	return twoFactor, nil
+}
+
+// verifyTwoFactorCode 校验 TOTP 验证码或恢复码，恢复码校验通过后即作废
+func (userService *UserService) verifyTwoFactorCode(
+	twoFactor *authModel.TwoFactor,
+	code string,
+) error {
+	code = normalizeTwoFactorCode(code)
+
+	var (
+		ok  bool
+		err error
+	)
+	if len(code) == otp.DigitsSix.Length() {
+		ok, err = userService.checkTOTP(twoFactor, code)
+	} else {
+		ok, err = userService.userRepository.UseRecoveryCode(
+			context.Background(),
+			twoFactor.UserID,
+			cryptoUtil.SHA256Hex(code),
+			time.Now(),
+		)
+	}
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New(commonModel.TWO_FACTOR_CODE_INVALID)
+	}
+	return nil
+}
+
+// checkTOTP 校验 TOTP 验证码，同一时间步的验证码只能使用一次
+func (userService *UserService) checkTOTP(
+	twoFactor *authModel.TwoFactor,
+	code string,
+) (bool, error) {
+	code = normalizeTwoFactorCode(code)
+	opts := totp.ValidateOpts{
+		Period:    totpPeriod,
+		Digits:    otp.DigitsSix,
+		Algorithm: otp.AlgorithmSHA1,
+	}
+
+	now := time.Now()
+	for offset := -totpSkew; offset <= totpSkew; offset++ {
+		at := now.Add(time.Duration(offset*totpPeriod) * time.Second)
+		ok, err := totp.ValidateCustom(code, twoFactor.Secret, at, opts)
+		if err != nil || !ok {
+			continue
+		}
+
+		step := at.Unix() / totpPeriod
+		ok, err = userService.userRepository.UpdateTwoFactorLastUsedStep(
+			context.Background(),
+			twoFactor.ID,
+			step,
+		)
+		if err != nil || !ok {
+			return false, err
+		}
+		twoFactor.LastUsedStep = step
+		return true, nil
+	}
+	return false, nil
+}
+
+// generateRecoveryCodes 生成一组恢复码，返回展示给用户的恢复码与用于保存的摘要
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, authModel.RecoveryCodeCount)
+	hashes := make([]string, 0, authModel.RecoveryCodeCount)
+	encoding := base32.StdEncoding.WithPadding(base32.NoPadding)
+
+	for range authModel.RecoveryCodeCount {
+		b := make([]byte, recoveryCodeSize)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+		code := strings.ToLower(encoding.EncodeToString(b)[:recoveryCodeSize])
+		codes = append(codes, code[:recoveryCodeSize/2]+"-"+code[recoveryCodeSize/2:])
+		hashes = append(hashes, cryptoUtil.SHA256Hex(code))
+	}
+	return codes, hashes, nil
+}
+
+// normalizeTwoFactorCode 去掉验证码中的空格与连字符并转为小写，便于用户按展示格式输入
+func normalizeTwoFactorCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.NewReplacer(" ", "", "-", "").Replace(code)
+}
@@ -0,0 +1,282 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	authModel "github.com/lin-snow/ech0/internal/model/auth"
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+)
+
+// totpCode 生成相对当前时间偏移 steps 个时间步的验证码，同一时间步的验证码只能使用一次
+func totpCode(t *testing.T, secret string, steps int) string {
+	t.Helper()
+	code, err := totp.GenerateCodeCustom(
+		secret,
+		time.Now().Add(time.Duration(steps*totpPeriod)*time.Second),
+		totp.ValidateOpts{Period: totpPeriod, Digits: otp.DigitsSix, Algorithm: otp.AlgorithmSHA1},
+	)
+	if err != nil {
+		t.Fatalf("generate code: %v", err)
+	}
+	return code
+}
+
+// enableTwoFactor 为测试用户启用两步验证，使用上一个时间步的验证码，返回密钥与恢复码
+func (f *userFixture) enableTwoFactor(t *testing.T) (string, []string) {
+	t.Helper()
+	setup, err := f.service.SetupTwoFactor(f.user.ID)
+	if err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+	codes, err := f.service.EnableTwoFactor(f.user.ID, totpCode(t, setup.Secret, -1))
+	if err != nil {
+		t.Fatalf("enable: %v", err)
+	}
+	return setup.Secret, codes.RecoveryCodes
+}
+
+func (f *userFixture) login(t *testing.T) authModel.LoginResultDto {
+	t.Helper()
+	result, err := f.service.Login(
+		&authModel.LoginDto{Username: f.user.Username, Password: testPassword},
+		authModel.ClientInfo{},
+	)
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	return result
+}
+
+func expectError(t *testing.T, err error, want string) {
+	t.Helper()
+	if err == nil || err.Error() != want {
+		t.Errorf("err = %v, want %q", err, want)
+	}
+}
+
+func TestEnableTwoFactor(t *testing.T) {
+	f := newUserFixture(t)
+
+	_, err := f.service.EnableTwoFactor(f.user.ID, "123456")
+	expectError(t, err, commonModel.TWO_FACTOR_NOT_SETUP)
+
+	setup, err := f.service.SetupTwoFactor(f.user.ID)
+	if err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+	if setup.Secret == "" || setup.URI == "" || setup.QRCode == "" {
+		t.Errorf("setup = %+v", setup)
+	}
+
+	_, err = f.service.EnableTwoFactor(f.user.ID, "000000")
+	expectError(t, err, commonModel.TWO_FACTOR_CODE_INVALID)
+
+	codes, err := f.service.EnableTwoFactor(f.user.ID, totpCode(t, setup.Secret, 0))
+	if err != nil {
+		t.Fatalf("enable: %v", err)
+	}
+	if len(codes.RecoveryCodes) != authModel.RecoveryCodeCount {
+		t.Errorf("recovery codes = %d, want %d", len(codes.RecoveryCodes), authModel.RecoveryCodeCount)
+	}
+
+	status, err := f.service.GetTwoFactorStatus(f.user.ID)
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if !status.Enabled || status.RecoveryCodesCount != authModel.RecoveryCodeCount {
+		t.Errorf("status = %+v", status)
+	}
+
+	// 已启用时不能重新生成密钥覆盖
+	_, err = f.service.SetupTwoFactor(f.user.ID)
+	expectError(t, err, commonModel.TWO_FACTOR_ALREADY_ENABLED)
+}
+
+func TestLoginWithTwoFactor(t *testing.T) {
+	f := newUserFixture(t)
+	secret, recoveryCodes := f.enableTwoFactor(t)
+
+	result := f.login(t)
+	if !result.TwoFactorRequired || result.TwoFactorSetup || result.TwoFactorToken == "" || result.AccessToken != "" {
+		t.Fatalf("login result = %+v", result)
+	}
+	if methods := f.loginMethods(t); len(methods) != 0 {
+		t.Fatalf("session created before the second factor: %v", methods)
+	}
+
+	code := totpCode(t, secret, 0)
+	tokens, err := f.service.VerifyTwoFactorLogin(result.TwoFactorToken, code, authModel.ClientInfo{})
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if tokens.AccessToken == "" || tokens.RefreshToken == "" {
+		t.Errorf("tokens = %+v", tokens)
+	}
+
+	// 登录挑战只能使用一次
+	_, err = f.service.VerifyTwoFactorLogin(result.TwoFactorToken, totpCode(t, secret, 1), authModel.ClientInfo{})
+	expectError(t, err, commonModel.TWO_FACTOR_CHALLENGE_INVALID)
+
+	// 同一时间步的验证码不能重放
+	result = f.login(t)
+	_, err = f.service.VerifyTwoFactorLogin(result.TwoFactorToken, code, authModel.ClientInfo{})
+	expectError(t, err, commonModel.TWO_FACTOR_CODE_INVALID)
+
+	// 恢复码按展示格式输入，且只能使用一次
+	if _, err := f.service.VerifyTwoFactorLogin(
+		result.TwoFactorToken,
+		" "+recoveryCodes[0]+" ",
+		authModel.ClientInfo{},
+	); err != nil {
+		t.Fatalf("verify with recovery code: %v", err)
+	}
+	result = f.login(t)
+	_, err = f.service.VerifyTwoFactorLogin(result.TwoFactorToken, recoveryCodes[0], authModel.ClientInfo{})
+	expectError(t, err, commonModel.TWO_FACTOR_CODE_INVALID)
+
+	status, err := f.service.GetTwoFactorStatus(f.user.ID)
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if status.RecoveryCodesCount != authModel.RecoveryCodeCount-1 {
+		t.Errorf("recovery codes left = %d", status.RecoveryCodesCount)
+	}
+
+	methods := f.loginMethods(t)
+	if len(methods) != 2 || methods[0] != authModel.LoginMethodPassword || methods[1] != authModel.LoginMethodPassword {
+		t.Errorf("login methods = %v", methods)
+	}
+}
+
+func TestTwoFactorChallengeAttempts(t *testing.T) {
+	f := newUserFixture(t)
+	secret, _ := f.enableTwoFactor(t)
+	result := f.login(t)
+
+	for i := 1; i < authModel.TwoFactorMaxAttempts; i++ {
+		_, err := f.service.VerifyTwoFactorLogin(result.TwoFactorToken, "000000", authModel.ClientInfo{})
+		expectError(t, err, commonModel.TWO_FACTOR_CODE_INVALID)
+	}
+	_, err := f.service.VerifyTwoFactorLogin(result.TwoFactorToken, "000000", authModel.ClientInfo{})
+	expectError(t, err, commonModel.TWO_FACTOR_TOO_MANY_ATTEMPTS)
+
+	// 失败次数过多后挑战作废，正确的验证码也不能再使用
+	_, err = f.service.VerifyTwoFactorLogin(result.TwoFactorToken, totpCode(t, secret, 0), authModel.ClientInfo{})
+	expectError(t, err, commonModel.TWO_FACTOR_CHALLENGE_INVALID)
+
+	// 错误的验证码计入登录失败锁定
+	if _, err := f.service.Login(
+		&authModel.LoginDto{Username: f.user.Username, Password: testPassword},
+		authModel.ClientInfo{},
+	); err == nil {
+		t.Error("expected login to be locked after repeated second-factor failures")
+	}
+}
+
+func TestOtherLoginMethodsRequireTwoFactor(t *testing.T) {
+	f := newUserFixture(t)
+	secret, _ := f.enableTwoFactor(t)
+
+	oauth, err := f.service.ExchangeOAuthLoginCode(f.issueOAuthLoginCode(t, f.user.ID), authModel.ClientInfo{})
+	if err != nil {
+		t.Fatalf("oauth exchange: %v", err)
+	}
+	passkey, err := f.service.completeLogin(f.user, authModel.LoginMethodPasskey, authModel.ClientInfo{})
+	if err != nil {
+		t.Fatalf("passkey login: %v", err)
+	}
+	for name, result := range map[string]authModel.LoginResultDto{"oauth": oauth, "passkey": passkey} {
+		if !result.TwoFactorRequired || result.AccessToken != "" {
+			t.Errorf("%s result = %+v", name, result)
+		}
+	}
+	if methods := f.loginMethods(t); len(methods) != 0 {
+		t.Fatalf("session created before the second factor: %v", methods)
+	}
+
+	// 完成第二步后会话记录第一步的登录方式
+	if _, err := f.service.VerifyTwoFactorLogin(oauth.TwoFactorToken, totpCode(t, secret, 0), authModel.ClientInfo{}); err != nil {
+		t.Fatalf("verify oauth: %v", err)
+	}
+	if _, err := f.service.VerifyTwoFactorLogin(passkey.TwoFactorToken, totpCode(t, secret, 1), authModel.ClientInfo{}); err != nil {
+		t.Fatalf("verify passkey: %v", err)
+	}
+	methods := f.loginMethods(t)
+	if len(methods) != 2 || methods[0] != authModel.LoginMethodOAuth || methods[1] != authModel.LoginMethodPasskey {
+		t.Errorf("login methods = %v", methods)
+	}
+}
+
+func TestRequiredTwoFactorSetupDuringLogin(t *testing.T) {
+	f := newUserFixture(t)
+	if err := f.service.UpdateTwoFactorRequired(f.user.ID, f.user.ID, true); err != nil {
+		t.Fatalf("require: %v", err)
+	}
+
+	result := f.login(t)
+	if !result.TwoFactorRequired || !result.TwoFactorSetup {
+		t.Fatalf("login result = %+v", result)
+	}
+
+	_, err := f.service.VerifyTwoFactorLogin(result.TwoFactorToken, "000000", authModel.ClientInfo{})
+	expectError(t, err, commonModel.TWO_FACTOR_NOT_SETUP)
+
+	setup, err := f.service.SetupTwoFactorForLogin(result.TwoFactorToken)
+	if err != nil {
+		t.Fatalf("setup during login: %v", err)
+	}
+	tokens, err := f.service.VerifyTwoFactorLogin(result.TwoFactorToken, totpCode(t, setup.Secret, 0), authModel.ClientInfo{})
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if tokens.AccessToken == "" || len(tokens.RecoveryCodes) != authModel.RecoveryCodeCount {
+		t.Errorf("tokens = %+v", tokens)
+	}
+
+	// 完成绑定后的登录走普通的两步验证
+	result = f.login(t)
+	if !result.TwoFactorRequired || result.TwoFactorSetup {
+		t.Errorf("login result = %+v", result)
+	}
+	_, err = f.service.SetupTwoFactorForLogin(result.TwoFactorToken)
+	expectError(t, err, commonModel.TWO_FACTOR_SETUP_NOT_REQUIRED)
+
+	// 被要求使用两步验证时不能关闭
+	err = f.service.DisableTwoFactor(f.user.ID, tokens.RecoveryCodes[0])
+	expectError(t, err, commonModel.TWO_FACTOR_REQUIRED_CANNOT_CLOSE)
+}
+
+func TestRegenerateRecoveryCodesAndDisable(t *testing.T) {
+	f := newUserFixture(t)
+	secret, oldCodes := f.enableTwoFactor(t)
+
+	_, err := f.service.RegenerateRecoveryCodes(f.user.ID, "000000")
+	expectError(t, err, commonModel.TWO_FACTOR_CODE_INVALID)
+
+	codes, err := f.service.RegenerateRecoveryCodes(f.user.ID, totpCode(t, secret, 0))
+	if err != nil {
+		t.Fatalf("regenerate: %v", err)
+	}
+
+	// 旧的恢复码全部失效
+	err = f.service.DisableTwoFactor(f.user.ID, oldCodes[0])
+	expectError(t, err, commonModel.TWO_FACTOR_CODE_INVALID)
+
+	if err := f.service.DisableTwoFactor(f.user.ID, codes.RecoveryCodes[0]); err != nil {
+		t.Fatalf("disable: %v", err)
+	}
+	status, err := f.service.GetTwoFactorStatus(f.user.ID)
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if status.Enabled || status.RecoveryCodesCount != 0 {
+		t.Errorf("status = %+v", status)
+	}
+
+	if result := f.login(t); result.TwoFactorRequired || result.AccessToken == "" {
+		t.Errorf("login result = %+v", result)
+	}
+}
@@ -15,16 +15,21 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-webauthn/webauthn/protocol"
 	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/lin-snow/ech0/internal/authz"
+	"github.com/lin-snow/ech0/internal/config"
 	"github.com/lin-snow/ech0/internal/event"
 	authModel "github.com/lin-snow/ech0/internal/model/auth"
 	commonModel "github.com/lin-snow/ech0/internal/model/common"
 	settingModel "github.com/lin-snow/ech0/internal/model/setting"
 	model "github.com/lin-snow/ech0/internal/model/user"
+	"github.com/lin-snow/ech0/internal/ratelimit"
 	repository "github.com/lin-snow/ech0/internal/repository/user"
+	sessionService "github.com/lin-snow/ech0/internal/service/session"
 	settingService "github.com/lin-snow/ech0/internal/service/setting"
 	"github.com/lin-snow/ech0/internal/transaction"
 	cryptoUtil "github.com/lin-snow/ech0/internal/util/crypto"
@@ -38,7 +43,10 @@ type UserService struct {
 	txManager      transaction.TransactionManager         // 事务管理器
 	userRepository repository.UserRepositoryInterface     // 用户数据层接口
 	settingService settingService.SettingServiceInterface // 系统设置数据层接口
+	sessionService sessionService.SessionServiceInterface // 登录会话服务接口
 	eventBus       event.IEventBus                        // 事件总线
+	loginLockout   *ratelimit.Lockout                     // 按用户名记录连续登录失败并锁定
+	loginCodeMu    sync.Mutex                             // 保证 OAuth2 一次性登录码只能被换取一次
 }
 
 // NewUserService 创建并返回新的用户服务实例
@@ -46,6 +54,7 @@ type UserService struct {
 // 参数:
 //   - userRepository: 用户数据层接口实现
 //   - settingService: 系统设置数据层接口实现
+//   - sessionService: 登录会话服务接口实现
 //
 // 返回:
 //   - UserServiceInterface: 用户服务接口实现
@@ -53,29 +62,47 @@ func NewUserService(
 	tm transaction.TransactionManager,
 	userRepository repository.UserRepositoryInterface,
 	settingService settingService.SettingServiceInterface,
+	sessionService sessionService.SessionServiceInterface,
 	eventBusProvider func() event.IEventBus,
 ) UserServiceInterface {
 	return &UserService{
 		txManager:      tm,
 		userRepository: userRepository,
 		settingService: settingService,
+		sessionService: sessionService,
 		eventBus:       eventBusProvider(),
+		loginLockout: ratelimit.NewLockout(
+			config.Config.Auth.Lockout.Threshold,
+			time.Duration(config.Config.Auth.Lockout.BaseDelay)*time.Second,
+			time.Duration(config.Config.Auth.Lockout.MaxDelay)*time.Second,
+		),
 	}
 }
 
 // Login 用户登录验证
-// 验证用户名和密码，成功后生成JWT token
+// 验证用户名和密码，成功后创建登录会话并签发令牌对
+// 用户启用或被要求使用两步验证时不签发令牌，而是返回两步验证登录挑战
+// 同一用户名连续失败达到阈值后按指数增长的时间锁定
 //
 // 参数:
 //   - loginDto: 登录数据传输对象，包含用户名和密码
+//   - client: 发起登录的客户端信息
 //
 // 返回:
-//   - string: 生成的JWT token
+//   - authModel.LoginResultDto: 令牌对或两步验证登录挑战
 //   - error: 登录过程中的错误信息
-func (userService *UserService) Login(loginDto *authModel.LoginDto) (string, error) {
+func (userService *UserService) Login(
+	loginDto *authModel.LoginDto,
+	client authModel.ClientInfo,
+) (authModel.LoginResultDto, error) {
 	// 合法性校验
 	if loginDto.Username == "" || loginDto.Password == "" {
-		return "", errors.New(commonModel.USERNAME_OR_PASSWORD_NOT_BE_EMPTY)
+		return authModel.LoginResultDto{}, errors.New(commonModel.USERNAME_OR_PASSWORD_NOT_BE_EMPTY)
+	}
+
+	// 锁定期间即使密码正确也拒绝登录
+	if err := userService.loginLockout.Check(loginDto.Username); err != nil {
+		return authModel.LoginResultDto{}, err
 	}
 
 	// 将密码进行 MD5 加密
@@ -84,26 +111,27 @@ func (userService *UserService) Login(loginDto *authModel.LoginDto) (string, err
 	// 检查用户是否存在
 	user, err := userService.userRepository.GetUserByUsername(loginDto.Username)
 	if err != nil {
-		return "", errors.New(commonModel.USER_NOTFOUND)
+		userService.loginLockout.Fail(loginDto.Username)
+		return authModel.LoginResultDto{}, errors.New(commonModel.USER_NOTFOUND)
 	}
 
 	// 进行密码验证,查看外界传入的密码是否与数据库一致
 	if user.Password != loginDto.Password {
-		return "", errors.New(commonModel.PASSWORD_INCORRECT)
+		userService.loginLockout.Fail(loginDto.Username)
+		return authModel.LoginResultDto{}, errors.New(commonModel.PASSWORD_INCORRECT)
 	}
 
-	// 生成 Token
-	token, err := jwtUtil.GenerateToken(jwtUtil.CreateClaims(user))
-	if err != nil {
-		return "", err
+	// 创建登录会话并生成 Token，需要两步验证时返回登录挑战
+	result, err := userService.completeLogin(user, authModel.LoginMethodPassword, client)
+	if err == nil && !result.TwoFactorRequired {
+		userService.loginLockout.Reset(user.Username)
 	}
-
-	return token, nil
+	return result, err
 }
 
 // Register 用户注册
 // 注册新用户，包括用户数量限制检查、注册权限检查等
-// 第一个注册的用户自动设置为系统管理员
+// 第一个注册的用户自动成为站长，之后注册的用户为成员
 //
 // 参数:
 //   - registerDto: 注册数据传输对象，包含用户名和密码
@@ -116,7 +144,7 @@ func (userService *UserService) Register(registerDto *authModel.RegisterDto) err
 	if err != nil {
 		return err
 	}
-	if len(users) > authModel.MAX_USER_COUNT {
+	if maxUsers := config.Config.Auth.MaxUsers; maxUsers > 0 && len(users) >= maxUsers {
 		return errors.New(commonModel.USER_COUNT_EXCEED_LIMIT)
 	}
 
@@ -126,8 +154,8 @@ func (userService *UserService) Register(registerDto *authModel.RegisterDto) err
 	newUser := model.User{
 		Username: registerDto.Username,
 		Password: registerDto.Password,
-		IsAdmin:  false,
 	}
+	newUser.SetRole(model.RoleViewer)
 
 	// 检查用户是否已经存在
 	user, err := userService.userRepository.GetUserByUsername(newUser.Username)
@@ -137,8 +165,8 @@ func (userService *UserService) Register(registerDto *authModel.RegisterDto) err
 
 	// 检查是否该系统第一次注册用户
 	if len(users) == 0 {
-		// 第一个注册的用户为系统管理员
-		newUser.IsAdmin = true
+		// 第一个注册的用户为站长
+		newUser.SetRole(model.RoleOwner)
 	}
 
 	// 检查是否开放注册
@@ -178,23 +206,19 @@ func (userService *UserService) Register(registerDto *authModel.RegisterDto) err
 }
 
 // UpdateUser 更新用户信息
-// 只有管理员可以更新用户信息，支持更新用户名、密码和头像
+// 用户只能更新自己的信息，支持更新用户名、密码和头像
 //
 // 参数:
-//   - userid: 执行更新操作的用户ID（必须为管理员）
+//   - userid: 执行更新操作的用户ID
 //   - userdto: 用户信息数据传输对象，包含要更新的用户信息
 //
 // 返回:
 //   - error: 更新过程中的错误信息
 func (userService *UserService) UpdateUser(userid uint, userdto model.UserInfoDto) error {
-	// 检查执行操作的用户是否为管理员
 	user, err := userService.userRepository.GetUserByID(int(userid))
 	if err != nil {
 		return err
 	}
-	if !user.IsAdmin {
-		return errors.New(commonModel.NO_PERMISSION_DENIED)
-	}
 
 	// 检查是否需要更新用户名
 	if userdto.Username != "" && userdto.Username != user.Username {
@@ -207,13 +231,16 @@ func (userService *UserService) UpdateUser(userid uint, userdto model.UserInfoDt
 	}
 
 	// 检查是否需要更新密码
+	passwordChanged := false
 	if userdto.Password != "" && cryptoUtil.MD5Encrypt(userdto.Password) != user.Password {
 		// 检查密码是否为空
 		if userdto.Password == "" {
 			return errors.New(commonModel.USERNAME_OR_PASSWORD_NOT_BE_EMPTY)
 		}
-		// 更新密码
+		// 更新密码，并递增令牌版本使已签发的登录令牌全部失效
 		user.Password = cryptoUtil.MD5Encrypt(userdto.Password)
+		user.TokenVersion++
+		passwordChanged = true
 	}
 
 	// 检查是否需要更新头像
@@ -228,6 +255,13 @@ func (userService *UserService) UpdateUser(userid uint, userdto model.UserInfoDt
 		return err
 	}
 
+	// 修改密码后注销该用户的全部登录会话
+	if passwordChanged {
+		if err := userService.sessionService.RevokeAllSessions(user.ID); err != nil {
+			return err
+		}
+	}
+
 	// 发布用户更新事件
 	user.Password = "" // 不包含密码信息
 	if err := userService.eventBus.Publish(
@@ -246,44 +280,61 @@ func (userService *UserService) UpdateUser(userid uint, userdto model.UserInfoDt
 	return nil
 }
 
-// UpdateUserAdmin 更新用户的管理员权限
-// 只有系统管理员、管理员可以修改其他用户的管理员权限，不能修改自己和系统管理员的权限
+// UpdateUserAdmin 切换用户的管理员身份
+// 兼容旧版本接口：管理员降为成员，其他角色升为管理员
 //
 // 参数:
-//   - userid: 执行操作的用户ID（必须为管理员）
+//   - userid: 执行操作的用户ID（需要拥有用户管理权限）
 //   - id: 要修改权限的用户ID
 //
 // 返回:
 //   - error: 更新过程中的错误信息
 func (userService *UserService) UpdateUserAdmin(userid uint, id uint) error {
-	// 检查执行操作的用户是否为管理员
-	user, err := userService.userRepository.GetUserByID(int(userid))
+	user, err := userService.userRepository.GetUserByID(int(id))
 	if err != nil {
 		return err
 	}
-	if !user.IsAdmin {
-		return errors.New(commonModel.NO_PERMISSION_DENIED)
+
+	role := model.RoleAdmin
+	if user.IsAdmin {
+		role = model.RoleViewer
 	}
+	return userService.UpdateUserRole(userid, id, string(role))
+}
 
-	// 检查要修改权限的用户是否存在
-	user, err = userService.userRepository.GetUserByID(int(id))
+// UpdateUserRole 修改用户角色
+// 需要拥有用户管理权限，不能修改自己和站长的角色，站长角色也不能分配给其他用户
+//
+// 参数:
+//   - userid: 执行操作的用户ID
+//   - id: 要修改角色的用户ID
+//   - role: 新的角色
+//
+// 返回:
+//   - error: 更新过程中的错误信息
+func (userService *UserService) UpdateUserRole(userid uint, id uint, role string) error {
+	operator, err := userService.userRepository.GetUserByID(int(userid))
 	if err != nil {
 		return err
 	}
-
-	// 检查系统管理员信息
-	sysadmin, err := userService.GetSysAdmin()
-	if err != nil {
+	if err := authz.Authorize(operator, model.PermissionUserManage); err != nil {
 		return err
 	}
 
-	// 检查是否尝试修改自己或系统管理员的权限
-	if userid == user.ID || id == sysadmin.ID {
-		return errors.New(commonModel.INVALID_PARAMS_BODY)
+	if !model.IsValidRole(role) || model.Role(role) == model.RoleOwner {
+		return errors.New(commonModel.USER_ROLE_INVALID)
 	}
 
-	user.IsAdmin = !user.IsAdmin
+	// 检查要修改角色的用户是否存在
+	user, err := userService.userRepository.GetUserByID(int(id))
+	if err != nil {
+		return err
+	}
+	if user.ID == operator.ID || user.Role == model.RoleOwner {
+		return errors.New(commonModel.USER_ROLE_CANNOT_CHANGE)
+	}
 
+	user.SetRole(model.Role(role))
 	if err := userService.txManager.Run(func(ctx context.Context) error {
 		// 更新用户信息
 		return userService.userRepository.UpdateUser(ctx, &user)
@@ -309,6 +360,18 @@ func (userService *UserService) UpdateUserAdmin(userid uint, id uint) error {
 	return nil
 }
 
+// GetRoles 获取全部角色及其拥有的权限
+func (userService *UserService) GetRoles() []model.RoleDto {
+	roles := make([]model.RoleDto, 0, len(model.Roles))
+	for _, role := range model.Roles {
+		roles = append(roles, model.RoleDto{
+			Role:        role,
+			Permissions: role.Permissions(),
+		})
+	}
+	return roles
+}
+
 // GetAllUsers 获取所有用户列表
 // 返回除系统管理员外的所有用户，并移除密码信息
 //
@@ -357,23 +420,23 @@ func (userService *UserService) GetSysAdmin() (model.User, error) {
 }
 
 // DeleteUser 删除用户
-// 只有管理员可以删除用户，不能删除自己和系统管理员
+// 需要拥有用户管理权限，不能删除自己和站长
 //
 // 参数:
-//   - userid: 执行删除操作的用户ID（必须为管理员）
+//   - userid: 执行删除操作的用户ID（需要拥有用户管理权限）
 //   - id: 要删除的用户ID
 //
 // 返回:
 //   - error: 删除过程中的错误信息
 func (userService *UserService) DeleteUser(userid, id uint) error {
-	return userService.txManager.Run(func(ctx context.Context) error {
-		// 检查执行操作的用户是否为管理员
+	if err := userService.txManager.Run(func(ctx context.Context) error {
+		// 检查执行操作的用户是否拥有用户管理权限
 		user, err := userService.userRepository.GetUserByID(int(userid))
 		if err != nil {
 			return err
 		}
-		if !user.IsAdmin {
-			return errors.New(commonModel.NO_PERMISSION_DENIED)
+		if err := authz.Authorize(user, model.PermissionUserManage); err != nil {
+			return err
 		}
 
 		// 检查要删除的用户是否存在
@@ -395,8 +458,14 @@ func (userService *UserService) DeleteUser(userid, id uint) error {
 			return err
 		}
 
-		return nil
-	})
+		// 清理被删除用户的两步验证配置与恢复码
+		return userService.userRepository.DeleteTwoFactor(ctx, id)
+	}); err != nil {
+		return err
+	}
+
+	// 注销被删除用户的全部登录会话
+	return userService.sessionService.RevokeAllSessions(id)
 }
 
 // GetUserByID 根据用户ID获取用户信息
@@ -422,7 +491,7 @@ func (userService *UserService) BindOAuth(
 		return "", err
 	}
 
-	if !user.IsAdmin {
+	if !user.Can(model.PermissionOAuthBind) {
 		return "", bindingPermissionError(provider)
 	}
 
@@ -501,13 +570,15 @@ func (userService *UserService) HandleOAuthCallback(
 	case string(commonModel.OAuth2GITHUB):
 		tokenResp, err := exchangeGithubCodeForToken(setting, code)
 		if err != nil {
-			fmt.Printf("Error exchanging %s code for token: %v\n", provider, err)
+			logUtil.GetLogger().Error("Failed to exchange OAuth2 code for token",
+				zap.String("provider", provider), zap.String("error", err.Error()))
 			return ""
 		}
 
 		githubUser, err := fetchGitHubUserInfo(setting, tokenResp.AccessToken)
 		if err != nil {
-			fmt.Printf("Error fetching %s user info: %v\n", provider, err)
+			logUtil.GetLogger().Error("Failed to fetch OAuth2 user info",
+				zap.String("provider", provider), zap.String("error", err.Error()))
 			return ""
 		}
 
@@ -522,13 +593,15 @@ func (userService *UserService) HandleOAuthCallback(
 	case string(commonModel.OAuth2GOOGLE):
 		tokenResp, err := exchangeGoogleCodeForToken(setting, code)
 		if err != nil {
-			fmt.Printf("Error exchanging %s code for token: %v\n", provider, err)
+			logUtil.GetLogger().Error("Failed to exchange OAuth2 code for token",
+				zap.String("provider", provider), zap.String("error", err.Error()))
 			return ""
 		}
 
 		googleUser, err := fetchGoogleUserInfo(setting, tokenResp.AccessToken)
 		if err != nil {
-			fmt.Printf("Error fetching %s user info: %v\n", provider, err)
+			logUtil.GetLogger().Error("Failed to fetch OAuth2 user info",
+				zap.String("provider", provider), zap.String("error", err.Error()))
 			return ""
 		}
 
@@ -543,13 +616,15 @@ func (userService *UserService) HandleOAuthCallback(
 	case string(commonModel.OAuth2QQ):
 		tokenResp, err := exchangeQQCodeForToken(setting, code)
 		if err != nil {
-			fmt.Printf("Error exchanging %s code for token: %v\n", provider, err)
+			logUtil.GetLogger().Error("Failed to exchange OAuth2 code for token",
+				zap.String("provider", provider), zap.String("error", err.Error()))
 			return ""
 		}
 
 		qqOpenIDResp, err := fetchQQUserInfo(tokenResp.AccessToken)
 		if err != nil {
-			fmt.Printf("Error fetching %s user info: %v\n", provider, err)
+			logUtil.GetLogger().Error("Failed to fetch OAuth2 user info",
+				zap.String("provider", provider), zap.String("error", err.Error()))
 			return ""
 		}
 
@@ -565,7 +640,8 @@ func (userService *UserService) HandleOAuthCallback(
 		// 使用 code 换取 access_token
 		accessToken, idToken, err := exchangeCustomCodeForToken(setting, code)
 		if err != nil {
-			fmt.Printf("Error exchanging %s code for token: %v\n", provider, err)
+			logUtil.GetLogger().Error("Failed to exchange OAuth2 code for token",
+				zap.String("provider", provider), zap.String("error", err.Error()))
 			return ""
 		}
 
@@ -576,7 +652,8 @@ func (userService *UserService) HandleOAuthCallback(
 		if setting.IsOIDC {
 			oauthId, err = fetchCustomUserInfo(setting, accessToken, idToken)
 			if err != nil {
-				fmt.Printf("Error fetching %s user info: %v\n", provider, err)
+				logUtil.GetLogger().Error("Failed to fetch OAuth2 user info",
+					zap.String("provider", provider), zap.String("error", err.Error()))
 				return ""
 			}
 			issuer = setting.Issuer
@@ -584,7 +661,8 @@ func (userService *UserService) HandleOAuthCallback(
 		} else {
 			oauthId, err = fetchCustomUserInfo(setting, accessToken, "")
 			if err != nil {
-				fmt.Printf("Error fetching %s user info: %v\n", provider, err)
+				logUtil.GetLogger().Error("Failed to fetch OAuth2 user info",
+					zap.String("provider", provider), zap.String("error", err.Error()))
 				return ""
 			}
 			issuer = ""
@@ -592,13 +670,44 @@ func (userService *UserService) HandleOAuthCallback(
 		}
 
 		// 绑定到本地用户并返回重定向 URL
-		return userService.resolveOAuthCallback(oauthState, provider, oauthId, issuer, authType)
+		return userService.resolveOAuthCallback(
+			oauthState,
+			provider,
+			oauthId,
+			issuer,
+			authType,
+		)
 
 	default:
 		return ""
 	}
 }
 
+// ExchangeOAuthLoginCode 使用 OAuth2 回调发放的一次性登录码创建登录会话，登录码使用后立即失效
+func (userService *UserService) ExchangeOAuthLoginCode(
+	code string,
+	client authModel.ClientInfo,
+) (authModel.LoginResultDto, error) {
+	cacheKey := repository.GetOAuthLoginCodeKey(code)
+
+	userService.loginCodeMu.Lock()
+	loginCode, err := userService.userRepository.CacheGetOAuthLoginCode(cacheKey)
+	if err == nil {
+		userService.userRepository.CacheDeleteOAuthLoginCode(cacheKey)
+	}
+	userService.loginCodeMu.Unlock()
+	if err != nil {
+		return authModel.LoginResultDto{}, errors.New(commonModel.OAUTH_LOGIN_CODE_INVALID)
+	}
+
+	user, err := userService.userRepository.GetUserByID(int(loginCode.UserID))
+	if err != nil {
+		return authModel.LoginResultDto{}, errors.New(commonModel.OAUTH_LOGIN_CODE_INVALID)
+	}
+
+	return userService.completeLogin(user, authModel.LoginMethodOAuth, client)
+}
+
 func (userService *UserService) getOAuthSetting(
 	provider string,
 ) (*settingModel.OAuth2Setting, error) {
@@ -737,22 +846,30 @@ func (userService *UserService) resolveOAuthCallback(
 			)
 		}
 		if err != nil {
-			fmt.Printf("Error fetching user by %s OAuth ID: %v\n", provider, err)
+			logUtil.GetLogger().Error("Failed to fetch user by OAuth2 ID",
+				zap.String("provider", provider), zap.String("error", err.Error()))
 			return ""
 		}
 
-		token, err := jwtUtil.GenerateToken(jwtUtil.CreateClaims(user))
+		// 重定向地址只携带短期有效的一次性登录码，令牌由前端通过 POST 换取，避免出现在 URL 与浏览器历史中
+		code, err := cryptoUtil.GenerateSecureToken(32)
 		if err != nil {
-			fmt.Printf("Error generating token: %v\n", err)
+			logUtil.GetLogger().Error("Failed to generate OAuth2 login code",
+				zap.String("error", err.Error()))
 			return ""
 		}
+		userService.userRepository.CacheSetOAuthLoginCode(
+			repository.GetOAuthLoginCodeKey(code),
+			&authModel.OAuthLoginCode{UserID: user.ID},
+			authModel.OAuthLoginCodeTTL,
+		)
 
 		redirectURL, err := url.Parse(oauthState.Redirect)
 		if err != nil {
 			return ""
 		}
 		query := redirectURL.Query()
-		query.Set("token", token)
+		query.Set("login_code", code)
 		redirectURL.RawQuery = query.Encode()
 
 		return redirectURL.String()
@@ -1114,8 +1231,8 @@ func (userService *UserService) GetOAuthInfo(
 		return oauthInfo, err
 	}
 
-	// 检查用户是否为管理员
-	if !user.IsAdmin {
+	// 检查用户是否可以绑定第三方账号
+	if !user.Can(model.PermissionOAuthBind) {
 		return oauthInfo, bindingPermissionError(provider)
 	}
 
@@ -1412,26 +1529,27 @@ func (userService *UserService) PasskeyLoginBegin(
 func (userService *UserService) PasskeyLoginFinish(
 	rpID, origin, nonce string,
 	credential json.RawMessage,
-) (string, error) {
+	client authModel.ClientInfo,
+) (authModel.LoginResultDto, error) {
 	cacheKey := repository.GetPasskeyLoginSessionKey(nonce)
 	cached, err := userService.userRepository.CacheGetPasskeySession(cacheKey)
 	if err != nil {
-		return "", errors.New(commonModel.INVALID_PARAMS)
+		return authModel.LoginResultDto{}, errors.New(commonModel.INVALID_PARAMS)
 	}
 	// 一次性使用
 	userService.userRepository.CacheDeletePasskeySession(cacheKey)
 
 	sess, ok := cached.(passkeySessionCache)
 	if !ok {
-		return "", errors.New(commonModel.INVALID_PARAMS)
+		return authModel.LoginResultDto{}, errors.New(commonModel.INVALID_PARAMS)
 	}
 	if sess.Origin != origin {
-		return "", errors.New(commonModel.INVALID_PARAMS)
+		return authModel.LoginResultDto{}, errors.New(commonModel.INVALID_PARAMS)
 	}
 
 	wa, err := userService.newWebAuthn(rpID, origin)
 	if err != nil {
-		return "", err
+		return authModel.LoginResultDto{}, err
 	}
 
 	req, _ := http.NewRequest(
@@ -1462,7 +1580,7 @@ func (userService *UserService) PasskeyLoginFinish(
 
 	user, credentialObj, err := wa.FinishPasskeyLogin(handler, sess.Session, req)
 	if err != nil {
-		return "", err
+		return authModel.LoginResultDto{}, err
 	}
 
 	uid := userIDFromHandle(user.WebAuthnID())
@@ -1471,7 +1589,7 @@ func (userService *UserService) PasskeyLoginFinish(
 		credID := base64.RawURLEncoding.EncodeToString(credentialObj.ID)
 		pk, err2 := userService.userRepository.GetPasskeyByCredentialID(credID)
 		if err2 != nil {
-			return "", err
+			return authModel.LoginResultDto{}, err
 		}
 		uid = pk.UserID
 	}
@@ -1492,15 +1610,10 @@ func (userService *UserService) PasskeyLoginFinish(
 
 	u, err := userService.userRepository.GetUserByID(int(uid))
 	if err != nil {
-		return "", err
-	}
-
-	token, err := jwtUtil.GenerateToken(jwtUtil.CreateClaims(u))
-	if err != nil {
-		return "", err
+		return authModel.LoginResultDto{}, err
 	}
 
-	return token, nil
+	return userService.completeLogin(u, authModel.LoginMethodPasskey, client)
 }
 
 func (userService *UserService) ListPasskeys(userID uint) ([]authModel.PasskeyDeviceDto, error) {
@@ -0,0 +1,266 @@
+package service
+
+import (
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/lin-snow/ech0/internal/config"
+	"github.com/lin-snow/ech0/internal/database"
+	authModel "github.com/lin-snow/ech0/internal/model/auth"
+	commonModel "github.com/lin-snow/ech0/internal/model/common"
+	model "github.com/lin-snow/ech0/internal/model/user"
+	"github.com/lin-snow/ech0/internal/ratelimit"
+	sessionRepository "github.com/lin-snow/ech0/internal/repository/session"
+	repository "github.com/lin-snow/ech0/internal/repository/user"
+	sessionService "github.com/lin-snow/ech0/internal/service/session"
+	"github.com/lin-snow/ech0/internal/transaction"
+	cryptoUtil "github.com/lin-snow/ech0/internal/util/crypto"
+	logUtil "github.com/lin-snow/ech0/internal/util/log"
+	"go.uber.org/zap"
+)
+
+// memoryCache 同步写入的内存缓存，避免 Ristretto 异步写入导致测试不稳定
+type memoryCache struct {
+	mu    sync.Mutex
+	items map[string]any
+}
+
+func (c *memoryCache) Set(key string, value any, _ int64) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.items[key] = value
+	return true
+}
+
+func (c *memoryCache) SetWithTTL(key string, value any, cost int64, _ time.Duration) bool {
+	return c.Set(key, value, cost)
+}
+
+func (c *memoryCache) Get(key string) (any, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	value, ok := c.items[key]
+	if !ok {
+		return nil, errors.New("key not found")
+	}
+	return value, nil
+}
+
+func (c *memoryCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.items, key)
+}
+
+func (c *memoryCache) GetOrSet(key string, cost int64, fn func() (any, error)) (any, error) {
+	if value, err := c.Get(key); err == nil {
+		return value, nil
+	}
+	value, err := fn()
+	if err != nil {
+		return nil, err
+	}
+	c.Set(key, value, cost)
+	return value, nil
+}
+
+// userFixture 基于临时 SQLite 数据库的用户服务测试环境，不依赖系统设置与事件总线
+type userFixture struct {
+	db      *gorm.DB
+	service *UserService
+	user    model.User
+}
+
+const testPassword = "correct horse"
+
+func newUserFixture(t *testing.T) *userFixture {
+	t.Helper()
+	logUtil.Logger = zap.NewNop()
+	config.JWT_SECRET = []byte("test-secret")
+	config.Config.Auth.Jwt.Expires = 3600
+	config.Config.Auth.Jwt.AccessExpires = 300
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "ech0.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.AutoMigrate(database.Models()...); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	dbProvider := func() *gorm.DB { return db }
+	txManager := transaction.NewTransactionManager(dbProvider)
+	userRepo := repository.NewUserRepository(dbProvider, &memoryCache{items: make(map[string]any)})
+
+	f := &userFixture{
+		db: db,
+		service: &UserService{
+			txManager:      txManager,
+			userRepository: userRepo,
+			sessionService: sessionService.NewSessionService(
+				txManager,
+				sessionRepository.NewSessionRepository(dbProvider),
+				userRepo,
+			),
+			loginLockout: ratelimit.NewLockout(5, time.Minute, time.Hour),
+		},
+	}
+	f.user = model.User{Username: "alice", Password: cryptoUtil.MD5Encrypt(testPassword)}
+	f.user.SetRole(model.RoleOwner)
+	if err := db.Create(&f.user).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	return f
+}
+
+// loginMethods 返回数据库中全部会话的登录方式
+func (f *userFixture) loginMethods(t *testing.T) []string {
+	t.Helper()
+	var methods []string
+	if err := f.db.Model(&authModel.Session{}).Order("id").Pluck("login_method", &methods).Error; err != nil {
+		t.Fatalf("load sessions: %v", err)
+	}
+	return methods
+}
+
+func (f *userFixture) issueOAuthLoginCode(t *testing.T, userID uint) string {
+	t.Helper()
+	code, err := cryptoUtil.GenerateSecureToken(32)
+	if err != nil {
+		t.Fatalf("generate code: %v", err)
+	}
+	f.service.userRepository.CacheSetOAuthLoginCode(
+		repository.GetOAuthLoginCodeKey(code),
+		&authModel.OAuthLoginCode{UserID: userID},
+		authModel.OAuthLoginCodeTTL,
+	)
+	return code
+}
+
+func TestExchangeOAuthLoginCode(t *testing.T) {
+	f := newUserFixture(t)
+	code := f.issueOAuthLoginCode(t, f.user.ID)
+
+	result, err := f.service.ExchangeOAuthLoginCode(code, authModel.ClientInfo{})
+	if err != nil {
+		t.Fatalf("exchange: %v", err)
+	}
+	if result.TwoFactorRequired || result.AccessToken == "" || result.RefreshToken == "" {
+		t.Errorf("result = %+v", result)
+	}
+	if methods := f.loginMethods(t); len(methods) != 1 || methods[0] != authModel.LoginMethodOAuth {
+		t.Errorf("login methods = %v", methods)
+	}
+
+	// 登录码只能换取一次
+	if _, err := f.service.ExchangeOAuthLoginCode(code, authModel.ClientInfo{}); err == nil ||
+		err.Error() != commonModel.OAUTH_LOGIN_CODE_INVALID {
+		t.Errorf("second exchange err = %v", err)
+	}
+}
+
+func TestExchangeOAuthLoginCodeRejectsInvalidCodes(t *testing.T) {
+	f := newUserFixture(t)
+
+	if _, err := f.service.ExchangeOAuthLoginCode("unknown", authModel.ClientInfo{}); err == nil {
+		t.Error("expected unknown code to be rejected")
+	}
+
+	// 登录码签发后用户被删除
+	code := f.issueOAuthLoginCode(t, f.user.ID+100)
+	if _, err := f.service.ExchangeOAuthLoginCode(code, authModel.ClientInfo{}); err == nil {
+		t.Error("expected code for a missing user to be rejected")
+	}
+	if methods := f.loginMethods(t); len(methods) != 0 {
+		t.Errorf("sessions created: %v", methods)
+	}
+}
+
+func TestExchangeOAuthLoginCodeConcurrently(t *testing.T) {
+	f := newUserFixture(t)
+	code := f.issueOAuthLoginCode(t, f.user.ID)
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		successes int
+	)
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := f.service.ExchangeOAuthLoginCode(code, authModel.ClientInfo{}); err == nil {
+				mu.Lock()
+				successes++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if successes != 1 {
+		t.Errorf("code exchanged %d times, want 1", successes)
+	}
+}
+
+func TestUpdateTwoFactorRequired(t *testing.T) {
+	f := newUserFixture(t)
+
+	users := make(map[model.Role]*model.User)
+	for _, role := range []model.Role{model.RoleAdmin, model.RoleEditor} {
+		user := model.User{Username: string(role), Password: "x"}
+		user.SetRole(role)
+		if err := f.db.Create(&user).Error; err != nil {
+			t.Fatalf("create user: %v", err)
+		}
+		users[role] = &user
+	}
+	admin, editor := users[model.RoleAdmin], users[model.RoleEditor]
+
+	required := func(id uint) bool {
+		t.Helper()
+		var user model.User
+		if err := f.db.First(&user, id).Error; err != nil {
+			t.Fatalf("load user: %v", err)
+		}
+		return user.TwoFactorRequired
+	}
+
+	// 没有用户管理权限
+	if err := f.service.UpdateTwoFactorRequired(editor.ID, admin.ID, true); err == nil {
+		t.Error("expected editor to be denied")
+	}
+
+	if err := f.service.UpdateTwoFactorRequired(admin.ID, editor.ID, true); err != nil {
+		t.Fatalf("admin requires editor: %v", err)
+	}
+	if !required(editor.ID) {
+		t.Error("editor is not required to use two-factor")
+	}
+
+	// 管理员不能修改站长的设置，站长可以修改自己的
+	if err := f.service.UpdateTwoFactorRequired(admin.ID, f.user.ID, true); err == nil ||
+		err.Error() != commonModel.TWO_FACTOR_REQUIRED_CANNOT_CHANGE {
+		t.Errorf("admin changing owner: err = %v", err)
+	}
+	if required(f.user.ID) {
+		t.Fatal("owner requirement changed by admin")
+	}
+	if err := f.service.UpdateTwoFactorRequired(f.user.ID, f.user.ID, true); err != nil {
+		t.Fatalf("owner requires self: %v", err)
+	}
+	if err := f.service.UpdateTwoFactorRequired(admin.ID, f.user.ID, false); err == nil {
+		t.Error("expected admin to be unable to lift the owner's requirement")
+	}
+	if !required(f.user.ID) {
+		t.Error("owner requirement was lifted")
+	}
+}
@@ -9,10 +9,14 @@ import (
 	"github.com/go-co-op/gocron/v2"
 	"github.com/lin-snow/ech0/internal/backup"
 	"github.com/lin-snow/ech0/internal/event"
+	queueModel "github.com/lin-snow/ech0/internal/model/queue"
 	settingModel "github.com/lin-snow/ech0/internal/model/setting"
+	webhookModel "github.com/lin-snow/ech0/internal/model/webhook"
 	queueRepository "github.com/lin-snow/ech0/internal/repository/queue"
 	commonService "github.com/lin-snow/ech0/internal/service/common"
+	echoService "github.com/lin-snow/ech0/internal/service/echo"
 	settingService "github.com/lin-snow/ech0/internal/service/setting"
+	trashService "github.com/lin-snow/ech0/internal/service/trash"
 	logUtil "github.com/lin-snow/ech0/internal/util/log"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -22,6 +26,8 @@ type Tasker struct {
 	scheduler      gocron.Scheduler
 	commonService  commonService.CommonServiceInterface
 	settingService settingService.SettingServiceInterface
+	echoService    echoService.EchoServiceInterface
+	trashService   trashService.TrashServiceInterface
 	eventBus       event.IEventBus
 	queueRepo      queueRepository.QueueRepositoryInterface
 }
@@ -29,6 +35,8 @@ type Tasker struct {
 func NewTasker(
 	commonService commonService.CommonServiceInterface,
 	settingService settingService.SettingServiceInterface,
+	echoService echoService.EchoServiceInterface,
+	trashService trashService.TrashServiceInterface,
 	eventBusProvider func() event.IEventBus,
 	queueRepo queueRepository.QueueRepositoryInterface,
 ) *Tasker {
@@ -44,15 +52,20 @@ func NewTasker(
 		scheduler:      scheduler,
 		commonService:  commonService,
 		settingService: settingService,
+		echoService:    echoService,
+		trashService:   trashService,
 		eventBus:       eventBusProvider(),
 		queueRepo:      queueRepo,
 	}
 }
 
 func (t *Tasker) Start() {
-	t.CleanupTempFilesTask()  // 启动清理临时文件任务
-	t.DeadLetterConsumeTask() // 启动死信任务消费任务
-	t.InboxTask()             // 启动Inbox任务
+	t.CleanupTempFilesTask()     // 启动清理临时文件任务
+	t.DeadLetterConsumeTask()    // 启动死信任务消费任务
+	t.InboxTask()                // 启动Inbox任务
+	t.WebhookDeliveryPruneTask() // 启动Webhook投递记录清理任务
+	t.ScheduledPublishTask()     // 启动定时发布任务
+	t.TrashPurgeTask()           // 启动回收站清理任务
 
 	// 读取自动备份cron设置
 	var backupScheduleSetting settingModel.BackupSchedule
@@ -96,16 +109,109 @@ func (t *Tasker) CleanupTempFilesTask() {
 	}
 }
 
+// ScheduledPublishTask 发布已到时间的定时 Echo 任务
+func (t *Tasker) ScheduledPublishTask() {
+	// 每分钟执行一次
+	_, err := t.scheduler.NewJob(
+		gocron.DurationJob(time.Minute),
+		gocron.NewTask(
+			func() {
+				count, err := t.echoService.PublishScheduledEchos()
+				if err != nil {
+					logUtil.GetLogger().
+						Error("Failed to publish scheduled echos", zap.String("error", err.Error()))
+					return
+				}
+				if count > 0 {
+					logUtil.GetLogger().Info("Published scheduled echos", zap.Int("count", count))
+				}
+			},
+		),
+		gocron.WithSingletonMode(gocron.LimitModeReschedule),
+	)
+	if err != nil {
+		logUtil.GetLogger().
+			Error("Failed to schedule ScheduledPublishTask", zap.String("error", err.Error()))
+	}
+}
+
+// TrashPurgeTask 彻底删除回收站中超过保留天数的内容任务
+func (t *Tasker) TrashPurgeTask() {
+	// 每小时执行一次
+	_, err := t.scheduler.NewJob(
+		gocron.DurationJob(time.Hour),
+		gocron.NewTask(
+			func() {
+				count, err := t.trashService.PurgeExpiredTrash()
+				if err != nil {
+					logUtil.GetLogger().
+						Error("Failed to purge expired trash", zap.String("error", err.Error()))
+				}
+				if count > 0 {
+					logUtil.GetLogger().Info("Purged expired trash", zap.Int("count", count))
+				}
+			},
+		),
+		gocron.WithSingletonMode(gocron.LimitModeReschedule),
+	)
+	if err != nil {
+		logUtil.GetLogger().
+			Error("Failed to schedule TrashPurgeTask", zap.String("error", err.Error()))
+	}
+}
+
+// WebhookDeliveryPruneTask 清理过期的 Webhook 投递记录任务
+func (t *Tasker) WebhookDeliveryPruneTask() {
+	// 每天执行一次
+	_, err := t.scheduler.NewJob(
+		gocron.DurationJob(24*time.Hour),
+		gocron.NewTask(
+			func() {
+				if err := t.settingService.PruneWebhookDeliveries(); err != nil {
+					logUtil.GetLogger().
+						Error("Failed to prune webhook deliveries", zap.String("error", err.Error()))
+				}
+			},
+		),
+	)
+	if err != nil {
+		logUtil.GetLogger().
+			Error("Failed to schedule WebhookDeliveryPruneTask", zap.String("error", err.Error()))
+	}
+}
+
 // DeadLetterConsumeTask 死信任务消费任务
 func (t *Tasker) DeadLetterConsumeTask() {
-	// 每天12点执行一次, 测试时为每30秒执行一次
+	// 每10分钟执行一次，仅取出已到重试时间的死信任务，重试间隔由退避策略决定
 	_, err := t.scheduler.NewJob(
-		gocron.DailyJob(1, gocron.NewAtTimes(gocron.NewAtTime(12, 0, 0))),
-		// gocron.DurationJob(30*time.Second), // 测试时为每30秒执行一次
+		gocron.DurationJob(10*time.Minute),
 		gocron.NewTask(
 			func() {
-				// 取出死信队列中的任务，逐个重试
-				deadLetters, err := t.queueRepo.ListDeadLetters(10)
+				// 清理已完成的死信任务，保留时长与 Webhook 投递记录相同，避免投递记录关联的死信任务提前消失
+				now := time.Now()
+				if _, err := t.queueRepo.PurgeDeadLettersBefore(
+					context.Background(),
+					now.Add(-webhookModel.DeliveryRetention),
+					queueModel.DeadLetterStatusCompleted,
+				); err != nil {
+					logUtil.GetLogger().
+						Error("Failed to purge completed dead letters", zap.String("error", err.Error()))
+				}
+
+				// 恢复长时间停留在处理中状态的死信任务，避免进程中断后任务永远不再重试
+				if recovered, err := t.queueRepo.RecoverStaleDeadLetters(
+					context.Background(),
+					now.Add(-queueModel.DeadLetterProcessingTimeout),
+				); err != nil {
+					logUtil.GetLogger().
+						Error("Failed to recover stale dead letters", zap.String("error", err.Error()))
+				} else if recovered > 0 {
+					logUtil.GetLogger().
+						Warn("Recovered stale processing dead letters", zap.Int64("count", recovered))
+				}
+
+				// 取出到期的死信任务，逐个重试
+				deadLetters, err := t.queueRepo.ListDueDeadLetters(now, 10)
 				if err != nil {
 					logUtil.GetLogger().
 						Error("Failed To Get DeadLetters!", zap.String("error", err.Error()))
@@ -2,6 +2,8 @@ package util
 
 import (
 	"crypto/md5"
+	cryptoRand "crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
 	"math/rand"
 	"time"
@@ -27,3 +29,18 @@ func GenerateRandomString(length int) string {
 	}
 	return string(b)
 }
+
+// SHA256Hex 对内容进行 SHA-256 摘要并返回十六进制字符串
+func SHA256Hex(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
+
+// GenerateSecureToken 使用安全随机数生成指定字节数的十六进制令牌
+func GenerateSecureToken(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := cryptoRand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,190 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	ContextLines = 3       // 每个差异块前后保留的上下文行数
+	maxDiffCells = 1 << 22 // 逐行比较的最大计算量，超出时视为整体替换
+)
+
+type opKind byte
+
+const (
+	opEqual  opKind = ' '
+	opDelete opKind = '-'
+	opInsert opKind = '+'
+)
+
+type lineOp struct {
+	kind opKind
+	line string
+}
+
+// Unified 以 unified diff 格式逐行比较两段文本，内容相同时返回空字符串
+func Unified(oldText, newText string) string {
+	if oldText == newText {
+		return ""
+	}
+
+	ops := diffLines(splitLines(oldText), splitLines(newText))
+	return formatHunks(ops)
+}
+
+// splitLines 按行拆分文本，空文本没有任何行
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// diffLines 基于最长公共子序列计算逐行的编辑操作
+func diffLines(a, b []string) []lineOp {
+	// 去掉相同的前缀和后缀以减少计算量
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix &&
+		a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	ops := make([]lineOp, 0, len(a)+len(b))
+	for _, line := range a[:prefix] {
+		ops = append(ops, lineOp{opEqual, line})
+	}
+
+	midA := a[prefix : len(a)-suffix]
+	midB := b[prefix : len(b)-suffix]
+	if len(midA)*len(midB) > maxDiffCells {
+		for _, line := range midA {
+			ops = append(ops, lineOp{opDelete, line})
+		}
+		for _, line := range midB {
+			ops = append(ops, lineOp{opInsert, line})
+		}
+	} else {
+		ops = append(ops, lcsOps(midA, midB)...)
+	}
+
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, lineOp{opEqual, line})
+	}
+	return ops
+}
+
+// lcsOps 使用动态规划求最长公共子序列并回溯出编辑操作
+func lcsOps(a, b []string) []lineOp {
+	n, m := len(a), len(b)
+	// table[i][j] 为 a[i:] 与 b[j:] 的最长公共子序列长度
+	table := make([][]int, n+1)
+	for i := range table {
+		table[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				table[i][j] = table[i+1][j+1] + 1
+			} else {
+				table[i][j] = max(table[i+1][j], table[i][j+1])
+			}
+		}
+	}
+
+	ops := make([]lineOp, 0, n+m)
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, lineOp{opEqual, a[i]})
+			i++
+			j++
+		case table[i+1][j] >= table[i][j+1]:
+			ops = append(ops, lineOp{opDelete, a[i]})
+			i++
+		default:
+			ops = append(ops, lineOp{opInsert, b[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		ops = append(ops, lineOp{opDelete, a[i]})
+	}
+	for ; j < m; j++ {
+		ops = append(ops, lineOp{opInsert, b[j]})
+	}
+	return ops
+}
+
+// formatHunks 将编辑操作按差异块输出，相距不超过两倍上下文的改动合并到同一块
+func formatHunks(ops []lineOp) string {
+	var b strings.Builder
+
+	for start := 0; start < len(ops); {
+		// 找到下一处改动
+		first := start
+		for first < len(ops) && ops[first].kind == opEqual {
+			first++
+		}
+		if first == len(ops) {
+			break
+		}
+
+		// 向后合并相邻的改动
+		last := first
+		for k := first + 1; k < len(ops); k++ {
+			if ops[k].kind == opEqual {
+				continue
+			}
+			if k-last-1 > 2*ContextLines {
+				break
+			}
+			last = k
+		}
+
+		from := max(first-ContextLines, start)
+		to := min(last+ContextLines+1, len(ops))
+
+		oldLine, newLine := 1, 1
+		for _, op := range ops[:from] {
+			if op.kind != opInsert {
+				oldLine++
+			}
+			if op.kind != opDelete {
+				newLine++
+			}
+		}
+		oldCount, newCount := 0, 0
+		for _, op := range ops[from:to] {
+			if op.kind != opInsert {
+				oldCount++
+			}
+			if op.kind != opDelete {
+				newCount++
+			}
+		}
+		// 没有行时起始行号指向前一行，与 GNU diff 一致
+		if oldCount == 0 {
+			oldLine--
+		}
+		if newCount == 0 {
+			newLine--
+		}
+
+		fmt.Fprintf(&b, "@@ -%d,%d +%d,%d @@\n", oldLine, oldCount, newLine, newCount)
+		for _, op := range ops[from:to] {
+			b.WriteByte(byte(op.kind))
+			b.WriteString(op.line)
+			b.WriteByte('\n')
+		}
+
+		start = to
+	}
+
+	return b.String()
+}
@@ -0,0 +1,65 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestUnified(t *testing.T) {
+	// numbered 返回 1 到 n 的行，changes 中指定的行替换为新内容
+	numbered := func(n int, changes map[int]string) string {
+		lines := make([]string, 0, n)
+		for i := 1; i <= n; i++ {
+			if line, ok := changes[i]; ok {
+				lines = append(lines, line)
+				continue
+			}
+			lines = append(lines, fmt.Sprint(i))
+		}
+		return strings.Join(lines, "\n")
+	}
+
+	tests := map[string]struct {
+		old, new string
+		want     string
+	}{
+		"内容相同":       {old: "a\nb", new: "a\nb", want: ""},
+		"只差末尾换行":     {old: "a\n", new: "a", want: ""},
+		"从空内容新增":     {old: "", new: "a\nb", want: "@@ -0,0 +1,2 @@\n+a\n+b\n"},
+		"删除全部内容":     {old: "a", new: "", want: "@@ -1,1 +0,0 @@\n-a\n"},
+		"修改中间一行":     {old: "a\nb\nc", new: "a\nB\nc", want: "@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n"},
+		"插入一行":       {old: "a\nc", new: "a\nb\nc", want: "@@ -1,2 +1,3 @@\n a\n+b\n c\n"},
+		"中文内容":       {old: "今天\n下雨", new: "今天\n晴天", want: "@@ -1,2 +1,2 @@\n 今天\n-下雨\n+晴天\n"},
+		"只保留三行上下文":   {old: numbered(10, nil), new: numbered(10, map[int]string{5: "five"}), want: "@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n"},
+		"相近的改动合并为一块": {old: numbered(20, nil), new: numbered(20, map[int]string{2: "two", 9: "nine"}), want: "@@ -1,12 +1,12 @@\n 1\n-2\n+two\n 3\n 4\n 5\n 6\n 7\n 8\n-9\n+nine\n 10\n 11\n 12\n"},
+		"相距较远的改动分块": {
+			old:  numbered(20, nil),
+			new:  numbered(20, map[int]string{2: "two", 18: "eighteen"}),
+			want: "@@ -1,5 +1,5 @@\n 1\n-2\n+two\n 3\n 4\n 5\n@@ -15,6 +15,6 @@\n 15\n 16\n 17\n-18\n+eighteen\n 19\n 20\n",
+		},
+	}
+
+	for name, tt := range tests {
+		if got := Unified(tt.old, tt.new); got != tt.want {
+			t.Errorf("%s: Unified = %q, want %q", name, got, tt.want)
+		}
+	}
+}
+
+func TestUnifiedFallsBackToReplacement(t *testing.T) {
+	// 超出计算量上限时整体替换，仍然输出合法的差异
+	n := 2100
+	var a, b strings.Builder
+	for i := range n {
+		fmt.Fprintf(&a, "a%d\n", i)
+		fmt.Fprintf(&b, "b%d\n", i)
+	}
+	got := Unified(a.String(), b.String())
+	if want := fmt.Sprintf("@@ -1,%d +1,%d @@\n-a0\n", n, n); !strings.HasPrefix(got, want) {
+		t.Errorf("diff starts with %q", got[:min(len(got), 40)])
+	}
+	if strings.Count(got, "\n-") != n || strings.Count(got, "\n+") != n {
+		t.Errorf("expected %d deletions and insertions", n)
+	}
+}
@@ -152,8 +152,8 @@ func GetMIMETypeFromFilenameOrURL(filenameOrURL string) string {
 	}
 }
 
-// PostActivity 发送 POST 请求
-func PostActivity(activity []byte, inboxURL string, actorID string) error {
+// PostActivity 使用 client 签名并发送 Activity 到远端 Inbox
+func PostActivity(client *http.Client, activity []byte, inboxURL string, actorID string) error {
 	priv := config.RSA_PRIVATE
 	if priv == nil {
 		return fmt.Errorf("private key is not initialized")
@@ -172,9 +172,6 @@ func PostActivity(activity []byte, inboxURL string, actorID string) error {
 	}
 
 	// 发送请求
-	client := &http.Client{
-		Timeout: 10 * time.Second,
-	}
 	resp, err := client.Do(req)
 	if err != nil {
 		return fmt.Errorf("failed to send request: %w", err)
@@ -192,7 +189,7 @@ func PostActivity(activity []byte, inboxURL string, actorID string) error {
 	return nil
 }
 
-// SignRequest 对请求进行签名
+// SignRequest 对请求进行签名，签名覆盖 (request-target)、host、date 与 digest
 func SignRequest(req *http.Request, priv *rsa.PrivateKey, keyID string, body []byte) error {
 	// 1. Digest
 	digest := sha256.Sum256(body)
@@ -200,16 +197,14 @@ func SignRequest(req *http.Request, priv *rsa.PrivateKey, keyID string, body []b
 	req.Header.Set("Digest", "SHA-256="+digestBase64)
 
 	// 2. Date
-	date := time.Now().UTC().Format(http.TimeFormat)
-	req.Header.Set("Date", date)
+	req.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
 
 	// 3. Signature
-	signingString := fmt.Sprintf("(request-target): %s %s\ndate: %s\ndigest: SHA-256=%s",
-		strings.ToLower(req.Method),
-		req.URL.RequestURI(),
-		date,
-		digestBase64,
-	)
+	headers := []string{"(request-target)", "host", "date", "digest"}
+	signingString, err := BuildSigningString(req, headers)
+	if err != nil {
+		return err
+	}
 
 	// 4. 签名
 	hashed := sha256.Sum256([]byte(signingString))
@@ -221,8 +216,9 @@ func SignRequest(req *http.Request, priv *rsa.PrivateKey, keyID string, body []b
 
 	// 5. 构建 Signature 头
 	signatureHeader := fmt.Sprintf(
-		`keyId="%s",algorithm="rsa-sha256",headers="(request-target) date digest",signature="%s"`,
+		`keyId="%s",algorithm="rsa-sha256",headers="%s",signature="%s"`,
 		keyID,
+		strings.Join(headers, " "),
 		sigBase64,
 	)
 	req.Header.Set("Signature", signatureHeader)
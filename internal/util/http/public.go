@@ -0,0 +1,60 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// ErrNonPublicAddress 目标地址不是公网地址
+var ErrNonPublicAddress = errors.New("refusing to connect to non-public address")
+
+// NewPublicClient 创建只能访问公网地址的 HTTP 客户端，用于下载用户提供的远程地址，
+// 在建立连接时检查解析后的 IP，拒绝回环、内网、链路本地等地址（包括重定向与 DNS 重绑定后的地址），且不使用环境变量中的代理
+func NewPublicClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if !IsPublicAddr(addrPort.Addr()) {
+				return fmt.Errorf("%w: %s", ErrNonPublicAddress, addrPort.Addr())
+			}
+			return nil
+		},
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			Proxy:               nil,
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: 10 * time.Second,
+			MaxIdleConns:        10,
+			IdleConnTimeout:     30 * time.Second,
+		},
+	}
+}
+
+// IsPublicAddr 判断 IP 是否为公网地址
+func IsPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsValid() &&
+		!addr.IsUnspecified() &&
+		!addr.IsLoopback() &&
+		!addr.IsPrivate() &&
+		!addr.IsLinkLocalUnicast() &&
+		!addr.IsLinkLocalMulticast() &&
+		!addr.IsInterfaceLocalMulticast() &&
+		!addr.IsMulticast() &&
+		!sharedAddressSpace.Contains(addr)
+}
+
+// sharedAddressSpace 运营商级 NAT 使用的地址段 (RFC 6598)，同样不应从服务端访问
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
@@ -0,0 +1,238 @@
+package util
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// SignatureParams 解析后的 Signature 头参数 (draft-cavage-http-signatures)
+type SignatureParams struct {
+	KeyID     string   // 公钥 ID，通常为 Actor ID + "#main-key"
+	Algorithm string   // 签名算法，rsa-sha256 或 hs2019
+	Headers   []string // 参与签名的头部列表
+	Signature []byte   // 解码后的签名
+}
+
+// ParseSignatureHeader 解析 Signature 头
+func ParseSignatureHeader(header string) (SignatureParams, error) {
+	var params SignatureParams
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return params, errors.New("missing signature header")
+	}
+
+	for _, part := range splitSignatureParams(header) {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.Trim(strings.TrimSpace(value), `"`)
+
+		switch key {
+		case "keyid":
+			params.KeyID = value
+		case "algorithm":
+			params.Algorithm = strings.ToLower(value)
+		case "headers":
+			params.Headers = strings.Fields(strings.ToLower(value))
+		case "signature":
+			sig, err := base64.StdEncoding.DecodeString(value)
+			if err != nil {
+				return params, fmt.Errorf("invalid signature encoding: %w", err)
+			}
+			params.Signature = sig
+		}
+	}
+
+	if params.KeyID == "" {
+		return params, errors.New("signature missing keyId")
+	}
+	if len(params.Signature) == 0 {
+		return params, errors.New("signature missing signature value")
+	}
+	if params.Algorithm != "" && params.Algorithm != "rsa-sha256" && params.Algorithm != "hs2019" {
+		return params, fmt.Errorf("unsupported signature algorithm: %s", params.Algorithm)
+	}
+	// 未声明 headers 时按规范默认仅签名 date
+	if len(params.Headers) == 0 {
+		params.Headers = []string{"date"}
+	}
+
+	return params, nil
+}
+
+// splitSignatureParams 按逗号拆分 Signature 参数，忽略引号内的逗号
+func splitSignatureParams(header string) []string {
+	var parts []string
+	var current strings.Builder
+	inQuotes := false
+	for _, r := range header {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+			current.WriteRune(r)
+		case r == ',' && !inQuotes:
+			parts = append(parts, current.String())
+			current.Reset()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if current.Len() > 0 {
+		parts = append(parts, current.String())
+	}
+	return parts
+}
+
+// HasSignedHeader 判断指定头部是否参与了签名
+func (params SignatureParams) HasSignedHeader(name string) bool {
+	name = strings.ToLower(name)
+	for _, header := range params.Headers {
+		if header == name {
+			return true
+		}
+	}
+	return false
+}
+
+// BuildSigningString 按 headers 顺序构造待签名字符串，与 SignRequest 的构造方式保持一致
+func BuildSigningString(req *http.Request, headers []string) (string, error) {
+	lines := make([]string, 0, len(headers))
+	for _, header := range headers {
+		switch header {
+		case "(request-target)":
+			lines = append(lines, fmt.Sprintf("(request-target): %s %s",
+				strings.ToLower(req.Method),
+				req.URL.RequestURI(),
+			))
+		case "host":
+			host := req.Host
+			if host == "" {
+				host = req.Header.Get("Host")
+			}
+			lines = append(lines, "host: "+host)
+		default:
+			values := req.Header.Values(header)
+			if len(values) == 0 {
+				return "", fmt.Errorf("signed header %q is missing", header)
+			}
+			lines = append(lines, header+": "+strings.Join(values, ", "))
+		}
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// VerifySignature 使用公钥校验请求签名
+func VerifySignature(req *http.Request, params SignatureParams, pub *rsa.PublicKey) error {
+	if pub == nil {
+		return errors.New("public key is nil")
+	}
+
+	signingString, err := BuildSigningString(req, params.Headers)
+	if err != nil {
+		return err
+	}
+
+	hashed := sha256.Sum256([]byte(signingString))
+	if err := rsa.VerifyPKCS1v15(pub, crypto.SHA256, hashed[:], params.Signature); err != nil {
+		return fmt.Errorf("signature verification failed: %w", err)
+	}
+
+	return nil
+}
+
+// VerifyDigest 校验 Digest 头与请求体是否匹配
+func VerifyDigest(req *http.Request, body []byte) error {
+	digestHeader := strings.TrimSpace(req.Header.Get("Digest"))
+	if digestHeader == "" {
+		return errors.New("missing digest header")
+	}
+
+	// Digest 头可能包含多个算法，只要有一个受支持的算法匹配即可
+	supported := false
+	for _, part := range strings.Split(digestHeader, ",") {
+		algorithm, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+
+		var expected string
+		switch strings.ToUpper(algorithm) {
+		case "SHA-256":
+			sum := sha256.Sum256(body)
+			expected = base64.StdEncoding.EncodeToString(sum[:])
+		case "SHA-512":
+			sum := sha512.Sum512(body)
+			expected = base64.StdEncoding.EncodeToString(sum[:])
+		default:
+			continue
+		}
+
+		supported = true
+		if value == expected {
+			return nil
+		}
+	}
+
+	if !supported {
+		return fmt.Errorf("unsupported digest algorithm: %s", digestHeader)
+	}
+	return errors.New("digest mismatch")
+}
+
+// VerifyDate 校验 Date 头是否在允许的时间偏差内
+func VerifyDate(req *http.Request, tolerance time.Duration) error {
+	dateHeader := strings.TrimSpace(req.Header.Get("Date"))
+	if dateHeader == "" {
+		return errors.New("missing date header")
+	}
+
+	date, err := http.ParseTime(dateHeader)
+	if err != nil {
+		return fmt.Errorf("invalid date header: %w", err)
+	}
+
+	skew := time.Since(date)
+	if skew < 0 {
+		skew = -skew
+	}
+	if skew > tolerance {
+		return fmt.Errorf("date header out of range: %s", dateHeader)
+	}
+
+	return nil
+}
+
+// ParseRSAPublicKeyPEM 解析 PEM 格式的 RSA 公钥 (支持 PKIX 与 PKCS1)
+func ParseRSAPublicKeyPEM(publicKeyPem string) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode([]byte(publicKeyPem))
+	if block == nil {
+		return nil, errors.New("failed to decode public key pem")
+	}
+
+	switch block.Type {
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		rsaPub, ok := pub.(*rsa.PublicKey)
+		if !ok {
+			return nil, errors.New("public key is not an RSA public key")
+		}
+		return rsaPub, nil
+	}
+}
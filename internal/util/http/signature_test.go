@@ -0,0 +1,226 @@
+package util
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha512"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+var (
+	testKeyOnce sync.Once
+	testKey     *rsa.PrivateKey
+)
+
+// signingKey 测试共用的 RSA 密钥，生成较慢只生成一次
+func signingKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	testKeyOnce.Do(func() {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			t.Fatalf("generate key: %v", err)
+		}
+		testKey = key
+	})
+	return testKey
+}
+
+// signedRequest 构造一个已签名的 Inbox 投递请求
+func signedRequest(t *testing.T, body string) *http.Request {
+	t.Helper()
+	req := httptest.NewRequest(
+		http.MethodPost,
+		"https://ech0.example/users/alice/inbox",
+		strings.NewReader(body),
+	)
+	if err := SignRequest(req, signingKey(t), "https://remote.example/users/bob#main-key", []byte(body)); err != nil {
+		t.Fatalf("sign request: %v", err)
+	}
+	return req
+}
+
+func TestParseSignatureHeader(t *testing.T) {
+	sig := base64.StdEncoding.EncodeToString([]byte("signature"))
+
+	params, err := ParseSignatureHeader(
+		`keyId="https://remote.example/users/bob#main-key",algorithm="hs2019",` +
+			`headers="(request-target) Host Date",signature="` + sig + `"`,
+	)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if params.KeyID != "https://remote.example/users/bob#main-key" {
+		t.Errorf("keyId = %q", params.KeyID)
+	}
+	if !params.HasSignedHeader("host") || !params.HasSignedHeader("Date") {
+		t.Errorf("headers = %v, want lower-cased host and date", params.Headers)
+	}
+	if string(params.Signature) != "signature" {
+		t.Errorf("signature = %q", params.Signature)
+	}
+
+	// 未声明 headers 时只签名 date
+	params, err = ParseSignatureHeader(`keyId="k",signature="` + sig + `"`)
+	if err != nil {
+		t.Fatalf("parse without headers: %v", err)
+	}
+	if len(params.Headers) != 1 || params.Headers[0] != "date" {
+		t.Errorf("default headers = %v, want [date]", params.Headers)
+	}
+
+	for name, header := range map[string]string{
+		"empty":             "",
+		"missing keyId":     `signature="` + sig + `"`,
+		"missing signature": `keyId="k"`,
+		"bad encoding":      `keyId="k",signature="%%%"`,
+		"unsupported alg":   `keyId="k",algorithm="rsa-sha1",signature="` + sig + `"`,
+	} {
+		if _, err := ParseSignatureHeader(header); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestSignRequestRoundTrip(t *testing.T) {
+	body := `{"type":"Follow"}`
+	req := signedRequest(t, body)
+
+	params, err := ParseSignatureHeader(req.Header.Get("Signature"))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	for _, header := range []string{"(request-target)", "host", "date", "digest"} {
+		if !params.HasSignedHeader(header) {
+			t.Errorf("signature does not cover %s", header)
+		}
+	}
+	if err := VerifySignature(req, params, &signingKey(t).PublicKey); err != nil {
+		t.Errorf("verify: %v", err)
+	}
+	if err := VerifyDigest(req, []byte(body)); err != nil {
+		t.Errorf("digest: %v", err)
+	}
+	if err := VerifyDate(req, time.Minute); err != nil {
+		t.Errorf("date: %v", err)
+	}
+}
+
+func TestVerifySignatureRejectsTampering(t *testing.T) {
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	tests := map[string]struct {
+		tamper func(req *http.Request)
+		key    *rsa.PublicKey
+	}{
+		"other key": {
+			tamper: func(*http.Request) {},
+			key:    &other.PublicKey,
+		},
+		"retargeted": {
+			tamper: func(req *http.Request) { req.URL.Path = "/users/carol/inbox" },
+		},
+		"other host": {
+			tamper: func(req *http.Request) { req.Host = "evil.example" },
+		},
+		"replaced date": {
+			tamper: func(req *http.Request) {
+				req.Header.Set("Date", time.Now().Add(time.Second).UTC().Format(http.TimeFormat))
+			},
+		},
+		"replaced digest": {
+			tamper: func(req *http.Request) { req.Header.Set("Digest", "SHA-256=AAAA") },
+		},
+		"signed header removed": {
+			tamper: func(req *http.Request) { req.Header.Del("Digest") },
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := signedRequest(t, "{}")
+			params, err := ParseSignatureHeader(req.Header.Get("Signature"))
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			tt.tamper(req)
+			key := tt.key
+			if key == nil {
+				key = &signingKey(t).PublicKey
+			}
+			if err := VerifySignature(req, params, key); err == nil {
+				t.Error("expected verification to fail")
+			}
+		})
+	}
+
+	if err := VerifySignature(signedRequest(t, "{}"), SignatureParams{}, nil); err == nil {
+		t.Error("expected error for nil public key")
+	}
+}
+
+func TestVerifyDigest(t *testing.T) {
+	body := []byte(`{"type":"Like"}`)
+	sum := sha512.Sum512(body)
+	sha512Digest := "SHA-512=" + base64.StdEncoding.EncodeToString(sum[:])
+
+	tests := map[string]struct {
+		digest string
+		body   []byte
+		ok     bool
+	}{
+		"signed body":         {digest: signedRequest(t, string(body)).Header.Get("Digest"), body: body, ok: true},
+		"sha-512":             {digest: sha512Digest, body: body, ok: true},
+		"one of many":         {digest: "MD5=abc, " + sha512Digest, body: body, ok: true},
+		"modified body":       {digest: sha512Digest, body: append(bytes.Clone(body), ' '), ok: false},
+		"missing":             {digest: "", body: body, ok: false},
+		"unsupported only":    {digest: "MD5=abc", body: body, ok: false},
+		"malformed":           {digest: "SHA-256", body: body, ok: false},
+		"empty body mismatch": {digest: sha512Digest, body: nil, ok: false},
+	}
+
+	for name, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/inbox", nil)
+		if tt.digest != "" {
+			req.Header.Set("Digest", tt.digest)
+		}
+		err := VerifyDigest(req, tt.body)
+		if (err == nil) != tt.ok {
+			t.Errorf("%s: err = %v, want ok = %v", name, err, tt.ok)
+		}
+	}
+}
+
+func TestVerifyDate(t *testing.T) {
+	tests := map[string]struct {
+		date string
+		ok   bool
+	}{
+		"now":          {date: time.Now().UTC().Format(http.TimeFormat), ok: true},
+		"slightly old": {date: time.Now().Add(-20 * time.Second).UTC().Format(http.TimeFormat), ok: true},
+		"too old":      {date: time.Now().Add(-2 * time.Minute).UTC().Format(http.TimeFormat), ok: false},
+		"in future":    {date: time.Now().Add(2 * time.Minute).UTC().Format(http.TimeFormat), ok: false},
+		"missing":      {date: "", ok: false},
+		"malformed":    {date: "yesterday", ok: false},
+	}
+
+	for name, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/inbox", nil)
+		if tt.date != "" {
+			req.Header.Set("Date", tt.date)
+		}
+		err := VerifyDate(req, 30*time.Second)
+		if (err == nil) != tt.ok {
+			t.Errorf("%s: err = %v, want ok = %v", name, err, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package util
+
+import "strings"
+
+// uaRule 按关键字识别 User-Agent 中的浏览器或操作系统，规则按顺序匹配
+type uaRule struct {
+	keyword string
+	name    string
+}
+
+var browserRules = []uaRule{
+	{"Edg/", "Edge"},
+	{"OPR/", "Opera"},
+	{"Firefox/", "Firefox"},
+	{"Chrome/", "Chrome"},
+	{"Safari/", "Safari"},
+	{"curl/", "curl"},
+	{"Go-http-client", "Go"},
+	{"python-requests", "Python"},
+}
+
+var osRules = []uaRule{
+	{"iPhone", "iOS"},
+	{"iPad", "iPadOS"},
+	{"Android", "Android"},
+	{"Windows", "Windows"},
+	{"Mac OS X", "macOS"},
+	{"CrOS", "ChromeOS"},
+	{"Linux", "Linux"},
+}
+
+// ParseDevice 根据 User-Agent 生成简短的设备描述，如 "Chrome on macOS"
+func ParseDevice(userAgent string) string {
+	if strings.TrimSpace(userAgent) == "" {
+		return "Unknown"
+	}
+
+	browser := matchUARule(userAgent, browserRules)
+	os := matchUARule(userAgent, osRules)
+	switch {
+	case browser != "" && os != "":
+		return browser + " on " + os
+	case browser != "":
+		return browser
+	case os != "":
+		return os
+	default:
+		return "Unknown"
+	}
+}
+
+// matchUARule 返回第一个命中的规则名称
+func matchUARule(userAgent string, rules []uaRule) string {
+	for _, rule := range rules {
+		if strings.Contains(userAgent, rule.keyword) {
+			return rule.name
+		}
+	}
+	return ""
+}
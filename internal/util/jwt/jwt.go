@@ -21,18 +21,20 @@ import (
 	cryptoUtil "github.com/lin-snow/ech0/internal/util/crypto"
 )
 
-// CreateClaims 创建Claims
-func CreateClaims(user userModel.User) jwt.Claims {
+// CreateClaims 创建登录会话的 Claims，有效期为 auth.jwt.accessexpires
+func CreateClaims(user userModel.User, sessionID uint) jwt.Claims {
 	leeway := time.Second * 60 // 允许的时间偏差
 	claims := authModel.MyClaims{
-		Userid:   user.ID,
-		Username: user.Username,
+		Userid:       user.ID,
+		Username:     user.Username,
+		SessionID:    sessionID,
+		TokenVersion: user.TokenVersion,
 		RegisteredClaims: jwt.RegisteredClaims{
 			Issuer:   config.Config.Auth.Jwt.Issuer,
 			Subject:  user.Username,
 			Audience: jwt.ClaimStrings{config.Config.Auth.Jwt.Audience},
 			ExpiresAt: jwt.NewNumericDate(
-				time.Now().Add(time.Duration(config.Config.Auth.Jwt.Expires) * time.Second),
+				time.Now().Add(time.Duration(config.Config.Auth.Jwt.AccessExpires) * time.Second),
 			),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now().Add(-leeway)),
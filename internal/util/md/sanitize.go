@@ -0,0 +1,15 @@
+package util
+
+import "github.com/microcosm-cc/bluemonday"
+
+// ugcPolicy 用户生成内容的 HTML 白名单，移除脚本、事件属性与危险链接，可并发使用
+var ugcPolicy = func() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	p.AddTargetBlankToFullyQualifiedLinks(true)
+	return p
+}()
+
+// SanitizeHTML 清理来自远端等不可信来源的 HTML，仅保留常见的排版标签与安全链接
+func SanitizeHTML(html string) string {
+	return ugcPolicy.Sanitize(html)
+}
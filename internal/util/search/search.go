@@ -0,0 +1,142 @@
+package util
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+const (
+	SnippetLength = 120 // 摘要的最大字符数
+	MarkOpen      = "<mark>"
+	MarkClose     = "</mark>"
+	ellipsis      = "…"
+)
+
+// IsCJK 判断字符是否为中日韩文字
+func IsCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+// Tokenize 将文本转换为适合 FTS5 unicode61 分词器索引的形式
+//
+// unicode61 会把连续的中日韩文字视为一个词，这里将每个中日韩字符用空格隔开（单字切分），
+// 查询时再以短语的方式匹配，从而支持任意长度的中文子串搜索
+func Tokenize(text string) string {
+	var b strings.Builder
+	b.Grow(len(text) * 2)
+
+	prevCJK := false
+	for _, r := range text {
+		cjk := IsCJK(r)
+		if cjk || prevCJK {
+			b.WriteByte(' ')
+		}
+		b.WriteRune(r)
+		prevCJK = cjk
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// BuildMatchQuery 将搜索词转换为 FTS5 MATCH 表达式，多个词之间为 AND 关系
+func BuildMatchQuery(terms []string) string {
+	phrases := make([]string, 0, len(terms))
+	for _, term := range terms {
+		// 只包含标点的词不会产生任何分词结果，直接忽略
+		if !strings.ContainsFunc(term, isWordRune) {
+			continue
+		}
+		tokens := strings.Fields(Tokenize(term))
+
+		phrase := `"` + strings.ReplaceAll(strings.Join(tokens, " "), `"`, `""`) + `"`
+		// 非中日韩结尾的词使用前缀匹配，保持与原有模糊搜索相近的体验
+		last := []rune(term)
+		if !IsCJK(last[len(last)-1]) {
+			phrase += "*"
+		}
+		phrases = append(phrases, phrase)
+	}
+	return strings.Join(phrases, " AND ")
+}
+
+// Highlight 截取内容中第一个命中位置附近的片段，并用 <mark> 标记所有命中的搜索词
+//
+// 片段中的其余文本会进行 HTML 转义，没有命中时返回内容开头的片段
+func Highlight(content string, terms []string) string {
+	text := []rune(content)
+	lower := make([]rune, len(text))
+	for i, r := range text {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	needles := make([][]rune, 0, len(terms))
+	for _, term := range terms {
+		needle := []rune(strings.TrimSpace(term))
+		for i, r := range needle {
+			needle[i] = unicode.ToLower(r)
+		}
+		if len(needle) > 0 {
+			needles = append(needles, needle)
+		}
+	}
+
+	// 标记每个位置开始的最长命中
+	matches := make([]int, len(lower))
+	first := -1
+	for i := range lower {
+		for _, needle := range needles {
+			if len(needle) > matches[i] && hasPrefixAt(lower, needle, i) {
+				matches[i] = len(needle)
+			}
+		}
+		if first < 0 && matches[i] > 0 {
+			first = i
+		}
+	}
+
+	// 以第一个命中为中心截取片段
+	start := 0
+	if first > SnippetLength/3 {
+		start = first - SnippetLength/3
+	}
+	end := min(start+SnippetLength, len(text))
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString(ellipsis)
+	}
+	for i := start; i < end; {
+		if n := matches[i]; n > 0 {
+			stop := min(i+n, end)
+			b.WriteString(MarkOpen)
+			b.WriteString(html.EscapeString(string(text[i:stop])))
+			b.WriteString(MarkClose)
+			i = stop
+			continue
+		}
+		b.WriteString(html.EscapeString(string(text[i])))
+		i++
+	}
+	if end < len(text) {
+		b.WriteString(ellipsis)
+	}
+	return b.String()
+}
+
+// isWordRune 判断字符是否会被分词器视为词的一部分
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsNumber(r)
+}
+
+// hasPrefixAt 判断 text 从 i 开始是否以 needle 开头
+func hasPrefixAt(text, needle []rune, i int) bool {
+	if i+len(needle) > len(text) {
+		return false
+	}
+	for j, r := range needle {
+		if text[i+j] != r {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,89 @@
+package util
+
+import "testing"
+
+func TestTokenize(t *testing.T) {
+	tests := map[string]struct {
+		text string
+		want string
+	}{
+		"英文保持原样":  {text: "hello world", want: "hello world"},
+		"中文逐字切分":  {text: "今天喝咖啡", want: "今 天 喝 咖 啡"},
+		"中英混排":    {text: "喝coffee了", want: "喝 coffee 了"},
+		"日文假名与韩文": {text: "カフェ카페", want: "カ フ ェ 카 페"},
+		"标点紧跟中文":  {text: "你好，world", want: "你 好 ，world"},
+		"数字与中文":   {text: "2024年", want: "2024 年"},
+		"空字符串":    {text: "", want: ""},
+		"首尾空白被去除": {text: " 中 ", want: "中"},
+	}
+
+	for name, tt := range tests {
+		if got := Tokenize(tt.text); got != tt.want {
+			t.Errorf("%s: Tokenize(%q) = %q, want %q", name, tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestBuildMatchQuery(t *testing.T) {
+	tests := map[string]struct {
+		terms []string
+		want  string
+	}{
+		"英文词前缀匹配":       {terms: []string{"coff"}, want: `"coff"*`},
+		"中文按短语匹配":       {terms: []string{"咖啡"}, want: `"咖 啡"`},
+		"中英混排以英文结尾":     {terms: []string{"喝cof"}, want: `"喝 cof"*`},
+		"多个词为 AND":      {terms: []string{"咖啡", "tea"}, want: `"咖 啡" AND "tea"*`},
+		"转义双引号":         {terms: []string{`say"hi`}, want: `"say""hi"*`},
+		"忽略只有标点的词":      {terms: []string{"!!", "——", "go"}, want: `"go"*`},
+		"FTS5 运算符按字面匹配": {terms: []string{"NEAR(a"}, want: `"NEAR(a"*`},
+		"没有词":           {terms: nil, want: ""},
+	}
+
+	for name, tt := range tests {
+		if got := BuildMatchQuery(tt.terms); got != tt.want {
+			t.Errorf("%s: BuildMatchQuery(%q) = %s, want %s", name, tt.terms, got, tt.want)
+		}
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	tests := map[string]struct {
+		content string
+		terms   []string
+		want    string
+	}{
+		"标记所有命中":    {content: "Go is good, go!", terms: []string{"go"}, want: "<mark>Go</mark> is <mark>go</mark>od, <mark>go</mark>!"},
+		"中文命中":      {content: "今天喝咖啡", terms: []string{"咖啡"}, want: "今天喝<mark>咖啡</mark>"},
+		"优先最长命中":    {content: "coffee", terms: []string{"co", "coffee"}, want: "<mark>coffee</mark>"},
+		"转义其余文本":    {content: "<b>tea</b>", terms: []string{"tea"}, want: "&lt;b&gt;<mark>tea</mark>&lt;/b&gt;"},
+		"没有命中时返回开头": {content: "plain text", terms: []string{"none"}, want: "plain text"},
+		"空白检索词被忽略":  {content: "a b", terms: []string{" "}, want: "a b"},
+	}
+
+	for name, tt := range tests {
+		if got := Highlight(tt.content, tt.terms); got != tt.want {
+			t.Errorf("%s: Highlight = %q, want %q", name, got, tt.want)
+		}
+	}
+}
+
+func TestHighlightTruncatesAroundFirstMatch(t *testing.T) {
+	content := ""
+	for range 100 {
+		content += "啊"
+	}
+	content += "目标"
+	for range 100 {
+		content += "哦"
+	}
+
+	got := []rune(Highlight(content, []string{"目标"}))
+	if string(got[:1]) != ellipsis || string(got[len(got)-1:]) != ellipsis {
+		t.Errorf("snippet is not truncated on both sides: %q", string(got))
+	}
+	// 命中位于片段前三分之一处
+	want := "…" + string([]rune(content)[100-SnippetLength/3:100]) + MarkOpen + "目标" + MarkClose
+	if len(got) < len([]rune(want)) || string(got[:len([]rune(want))]) != want {
+		t.Errorf("snippet = %q", string(got))
+	}
+}
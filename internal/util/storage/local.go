@@ -92,6 +92,40 @@ func UploadImageToLocal(file *multipart.FileHeader, userID uint) (string, error)
 	return imageURL, nil
 }
 
+// SaveImageToLocal 将 Reader 中的图片保存到本地存储
+func SaveImageToLocal(reader io.Reader, userID uint, ext string) (string, error) {
+	// 创建图片存储目录
+	if err := createDirIfNotExist(config.Config.Upload.ImagePath); err != nil {
+		return "", err
+	}
+
+	// 生成新的文件名,格式为[userID]_[timestamp]_[random].[ext]
+	newFileName, err := GenerateRandomFilename(userID, ext)
+	if err != nil {
+		return "", err
+	}
+
+	savePath := filepath.Join(config.Config.Upload.ImagePath, newFileName)
+	out, err := os.Create(savePath)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		// 确保文件被正确关闭
+		if closeErr := out.Close(); closeErr != nil {
+			log.Println("Failed to close destination file:", closeErr)
+		}
+	}()
+
+	if _, err = io.Copy(out, reader); err != nil {
+		return "", err
+	}
+
+	// 返回图片的 URL
+	imageURL := fmt.Sprintf("/images/%s", newFileName)
+	return imageURL, nil
+}
+
 // UploadAudioToLocal 将音频上传到本地存储
 func UploadAudioToLocal(file *multipart.FileHeader, userID uint) (string, error) {
 	// 创建音频存储目录
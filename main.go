@@ -3,7 +3,6 @@ package main
 import (
 	"github.com/lin-snow/ech0/cmd"
 	_ "github.com/lin-snow/ech0/internal/bootstrap"
-	"github.com/lin-snow/ech0/internal/config"
 	logUtil "github.com/lin-snow/ech0/internal/util/log"
 )
 
@@ -11,8 +10,7 @@ func init() {
 	// Logger
 	logUtil.InitLogger()
 
-	// Config
-	config.LoadAppConfig()
+	// Config 在命令行参数解析后由根命令加载，以支持 --config 参数
 }
 
 func main() {
@@ -0,0 +1,96 @@
+// Package webhook 提供 Ech0 Webhook 请求的签名与校验工具，接收方可直接引入本包校验请求来源。
+//
+// 签名算法: HMAC-SHA256(secret, timestamp + "." + body)，结果以
+// "sha256=<hex>" 的形式放在 X-Ech0-Signature 头中，时间戳 (Unix 秒) 放在 X-Ech0-Timestamp 头中。
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	SignatureHeader     = "X-Ech0-Signature" // 签名头
+	TimestampHeader     = "X-Ech0-Timestamp" // 签名时间戳头 (Unix 秒)
+	EventHeader         = "X-Ech0-Event"     // 事件类型头
+	EventIDHeader       = "X-Ech0-Event-ID"  // 唯一事件 ID 头
+	LegacyEventIDHeader = "E-Ech0-Event-ID"  // 旧版拼写错误的事件 ID 头，保留以兼容已有接收方
+
+	SignaturePrefix  = "sha256="       // 签名值前缀
+	DefaultTolerance = 5 * time.Minute // 默认允许的时间偏差
+)
+
+var (
+	ErrMissingSignature = errors.New("missing webhook signature")
+	ErrInvalidTimestamp = errors.New("invalid webhook timestamp")
+	ErrTimestampExpired = errors.New("webhook timestamp out of tolerance")
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+)
+
+// Sign 计算请求签名，返回 "sha256=<hex>" 格式的签名值
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return SignaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify 校验签名与时间戳，tolerance 小于等于 0 时使用 DefaultTolerance
+func Verify(secret, signature, timestamp string, body []byte, tolerance time.Duration) error {
+	if signature == "" || timestamp == "" {
+		return ErrMissingSignature
+	}
+	if tolerance <= 0 {
+		tolerance = DefaultTolerance
+	}
+
+	ts, err := strconv.ParseInt(strings.TrimSpace(timestamp), 10, 64)
+	if err != nil {
+		return ErrInvalidTimestamp
+	}
+	skew := time.Since(time.Unix(ts, 0))
+	if skew < 0 {
+		skew = -skew
+	}
+	if skew > tolerance {
+		return ErrTimestampExpired
+	}
+
+	expected := Sign(secret, ts, body)
+	if !hmac.Equal([]byte(expected), []byte(strings.TrimSpace(signature))) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// VerifyRequest 校验 HTTP 请求的签名，返回读取到的请求体 (请求体会被重新放回 r.Body)
+func VerifyRequest(r *http.Request, secret string, tolerance time.Duration) ([]byte, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	_ = r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	if err := Verify(
+		secret,
+		r.Header.Get(SignatureHeader),
+		r.Header.Get(TimestampHeader),
+		body,
+		tolerance,
+	); err != nil {
+		return nil, err
+	}
+
+	return body, nil
+}
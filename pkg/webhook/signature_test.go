@@ -0,0 +1,75 @@
+package webhook
+
+import (
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVerify(t *testing.T) {
+	const secret = "s3cret"
+	body := []byte(`{"type":"echo.created"}`)
+	now := time.Now().Unix()
+	timestamp := strconv.FormatInt(now, 10)
+	signature := Sign(secret, now, body)
+
+	if !strings.HasPrefix(signature, SignaturePrefix) {
+		t.Fatalf("signature = %q", signature)
+	}
+
+	tests := map[string]struct {
+		secret, signature, timestamp string
+		body                         []byte
+		want                         error
+	}{
+		"valid":             {secret, signature, timestamp, body, nil},
+		"surrounding space": {secret, " " + signature + " ", " " + timestamp, body, nil},
+		"missing signature": {secret, "", timestamp, body, ErrMissingSignature},
+		"missing timestamp": {secret, signature, "", body, ErrMissingSignature},
+		"bad timestamp":     {secret, signature, "yesterday", body, ErrInvalidTimestamp},
+		"expired":           {secret, signature, strconv.FormatInt(now-3600, 10), body, ErrTimestampExpired},
+		"from the future":   {secret, signature, strconv.FormatInt(now+3600, 10), body, ErrTimestampExpired},
+		"body changed":      {secret, signature, timestamp, []byte(`{"type":"echo.deleted"}`), ErrInvalidSignature},
+		"wrong secret":      {"other", signature, timestamp, body, ErrInvalidSignature},
+		// 时间戳参与签名，不能替换为其他有效时间
+		"timestamp changed": {secret, signature, strconv.FormatInt(now-1, 10), body, ErrInvalidSignature},
+	}
+
+	for name, tt := range tests {
+		err := Verify(tt.secret, tt.signature, tt.timestamp, tt.body, 0)
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s: err = %v, want %v", name, err, tt.want)
+		}
+	}
+}
+
+func TestVerifyRequest(t *testing.T) {
+	const secret = "s3cret"
+	body := `{"type":"echo.created"}`
+	now := time.Now().Unix()
+
+	req := httptest.NewRequest("POST", "/hook", strings.NewReader(body))
+	req.Header.Set(SignatureHeader, Sign(secret, now, []byte(body)))
+	req.Header.Set(TimestampHeader, strconv.FormatInt(now, 10))
+
+	got, err := VerifyRequest(req, secret, time.Minute)
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if string(got) != body {
+		t.Errorf("body = %q", got)
+	}
+	// 请求体放回后处理函数仍可读取
+	if rest, _ := io.ReadAll(req.Body); string(rest) != body {
+		t.Errorf("request body = %q", rest)
+	}
+
+	unsigned := httptest.NewRequest("POST", "/hook", strings.NewReader(body))
+	if _, err := VerifyRequest(unsigned, secret, time.Minute); !errors.Is(err, ErrMissingSignature) {
+		t.Errorf("unsigned: err = %v", err)
+	}
+}
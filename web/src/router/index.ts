@@ -125,6 +125,7 @@ router.beforeEach(async (to, from, next) => {
   ) {
     localStorage.removeItem('needLoginRedirect')
     localStorage.removeItem('token')
+    localStorage.removeItem('refresh_token')
     return next({ name: 'auth' })
   }
 
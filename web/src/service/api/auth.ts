@@ -2,13 +2,73 @@ import { request } from '../request'
 
 // 登录
 export function fetchLogin(loginParams: App.Api.Auth.LoginParams) {
-  return request<App.Api.Auth.LoginResponse>({
+  return request<App.Api.Auth.LoginResult>({
     url: '/login',
     method: 'POST',
     data: loginParams,
   })
 }
 
+// 两步验证登录
+export function fetchTwoFactorLogin(token: string, code: string) {
+  return request<App.Api.Auth.TwoFactorLoginResult>({
+    url: '/login/2fa',
+    method: 'POST',
+    data: { token, code },
+  })
+}
+
+// 登录过程中绑定认证器
+export function fetchTwoFactorLoginSetup(token: string) {
+  return request<App.Api.Auth.TwoFactorSetup>({
+    url: '/login/2fa/setup',
+    method: 'POST',
+    data: { token },
+  })
+}
+
+// 使用 OAuth2 回调附带的一次性登录码换取令牌
+export function fetchOAuthExchange(code: string) {
+  return request<App.Api.Auth.LoginResult>({
+    url: '/oauth/exchange',
+    method: 'POST',
+    data: { code },
+  })
+}
+
+// 刷新访问令牌
+export function fetchRefreshToken(refreshToken: string) {
+  return request<App.Api.Auth.LoginResponse>({
+    url: '/auth/refresh',
+    method: 'POST',
+    data: { refresh_token: refreshToken },
+  })
+}
+
+// 退出登录
+export function fetchLogout() {
+  return request({
+    url: '/logout',
+    method: 'POST',
+  })
+}
+
+// 获取登录会话列表
+export function fetchSessions() {
+  return request<App.Api.Auth.Session[]>({
+    url: '/sessions',
+    method: 'GET',
+  })
+}
+
+// 注销登录会话，id 为 all 时注销全部会话
+export function fetchDeleteSession(id: number | 'all') {
+  return request({
+    url: `/sessions/${id}`,
+    method: 'DELETE',
+  })
+}
+
 // 注册
 export function fetchSignup(signupParams: App.Api.Auth.SignupParams) {
   return request({
@@ -44,7 +104,7 @@ export function fetchPasskeyLoginBegin() {
 }
 
 export function fetchPasskeyLoginFinish(nonce: string, credential: unknown) {
-  return request<string>({
+  return request<App.Api.Auth.LoginResult>({
     url: '/passkey/login/finish',
     method: 'POST',
     data: { nonce, credential },
@@ -72,3 +132,42 @@ export function fetchUpdatePasskeyDeviceName(id: number, deviceName: string) {
     data: { device_name: deviceName },
   })
 }
+
+// 两步验证
+export function fetchTwoFactorStatus() {
+  return request<App.Api.Auth.TwoFactorStatus>({
+    url: '/2fa',
+    method: 'GET',
+  })
+}
+
+export function fetchSetupTwoFactor() {
+  return request<App.Api.Auth.TwoFactorSetup>({
+    url: '/2fa/setup',
+    method: 'POST',
+  })
+}
+
+export function fetchEnableTwoFactor(code: string) {
+  return request<App.Api.Auth.RecoveryCodes>({
+    url: '/2fa/enable',
+    method: 'POST',
+    data: { code },
+  })
+}
+
+export function fetchDisableTwoFactor(code: string) {
+  return request({
+    url: '/2fa/disable',
+    method: 'POST',
+    data: { code },
+  })
+}
+
+export function fetchRegenerateRecoveryCodes(code: string) {
+  return request<App.Api.Auth.RecoveryCodes>({
+    url: '/2fa/recovery-codes',
+    method: 'POST',
+    data: { code },
+  })
+}
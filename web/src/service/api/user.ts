@@ -33,6 +33,24 @@ export function fetchUpdateUserPermission(id: number) {
   })
 }
 
+// 修改用户角色
+export function fetchUpdateUserRole(id: number, role: App.Api.User.Role) {
+  return request({
+    url: `/user/${id}/role`,
+    method: 'PUT',
+    data: { role },
+  })
+}
+
+// 要求或取消要求用户使用两步验证
+export function fetchUpdateTwoFactorRequired(id: number, required: boolean) {
+  return request({
+    url: `/user/${id}/2fa`,
+    method: 'PUT',
+    data: { required },
+  })
+}
+
 // 删除用户
 export function fetchDeleteUser(id: number) {
   return request({
@@ -1,7 +1,14 @@
 // 封装ofetch
 
 import { ofetch } from 'ofetch'
-import { getAuthToken, getSystemReadyStatus } from './shared'
+import {
+  getAuthToken,
+  getRefreshToken,
+  getSystemReadyStatus,
+  removeRefreshToken,
+  saveAuthToken,
+  saveRefreshToken,
+} from './shared'
 import { theToast } from '@/utils/toast'
 
 interface RequestOptions {
@@ -46,31 +53,70 @@ const ofetchInstance = ofetch.create({
   },
 })
 
+// 正在进行的令牌刷新，并发请求共用同一次刷新，避免刷新令牌被重复使用而导致会话被注销
+let refreshing: Promise<boolean> | null = null
+
+// 使用刷新令牌换取新的访问令牌，返回是否刷新成功
+const refreshAuthToken = (url: string) => {
+  if (!refreshing) {
+    refreshing = ofetchInstance<App.Api.Response<App.Api.Auth.LoginResponse>>(url, {
+      method: 'POST',
+      body: { refresh_token: getRefreshToken() },
+    })
+      .then((res) => {
+        if (res.code === 1 && res.data?.access_token) {
+          saveAuthToken(res.data.access_token)
+          saveRefreshToken(res.data.refresh_token)
+          return true
+        }
+
+        // 刷新失败，会话已失效，交给登录流程处理
+        removeRefreshToken()
+        return false
+      })
+      .catch(() => false)
+      .finally(() => {
+        refreshing = null
+      })
+  }
+  return refreshing
+}
+
 export const request = async <T>(requestOptions: RequestOptions): Promise<App.Api.Response<T>> => {
   // 检查系统是否已经准备好
   const isSystemReady = getSystemReadyStatus()
 
   // 检查是否使用正向代理
+  let refreshUrl = '/auth/refresh'
   if (import.meta.env.VITE_PROXY === 'YES') {
     const proxyUrl = import.meta.env.VITE_PROXY_URL
     if (!proxyUrl) {
       throw new Error('Proxy URL is not defined')
     }
     requestOptions.url = `${proxyUrl}${requestOptions.url}`
+    refreshUrl = `${proxyUrl}${refreshUrl}`
   }
 
-  return ofetchInstance<App.Api.Response<T>>(requestOptions.url, {
-    method: requestOptions.method,
-    body: requestOptions.data,
-  }).then((res) => {
-    if (res.code !== 1) {
-      if (isSystemReady) {
-        theToast.error(res.msg ? String(res.msg) : '请求失败')
-      }
+  const send = () =>
+    ofetchInstance.raw<App.Api.Response<T>>(requestOptions.url, {
+      method: requestOptions.method,
+      body: requestOptions.data,
+    })
+
+  // 访问令牌过期时先尝试刷新，刷新成功后重放原请求
+  let response = await send()
+  if (response.status === 401 && getRefreshToken() && (await refreshAuthToken(refreshUrl))) {
+    response = await send()
+  }
+
+  const res = response._data as App.Api.Response<T>
+  if (res.code !== 1) {
+    if (isSystemReady) {
+      theToast.error(res.msg ? String(res.msg) : '请求失败')
     }
+  }
 
-    return res
-  })
+  return res
 }
 
 // 直接请求
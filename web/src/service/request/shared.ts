@@ -11,6 +11,20 @@ export const saveAuthToken = (token: string) => {
   }
 }
 
+export const getRefreshToken = () => {
+  return localStg.getItem<string>('refresh_token') ?? ''
+}
+
+export const saveRefreshToken = (refreshToken: string) => {
+  if (refreshToken) {
+    localStg.setItem('refresh_token', refreshToken)
+  }
+}
+
+export const removeRefreshToken = () => {
+  localStg.removeItem('refresh_token')
+}
+
 export const getApiUrl = () => {
   const baseUrl = import.meta.env.VITE_SERVICE_BASE_URL
   const resolvedBaseUrl = baseUrl.replace(/\/+$/, '') // 正则去除末尾的斜杠
@@ -1,7 +1,7 @@
 import { ref, computed } from 'vue'
 import { defineStore } from 'pinia'
-import { fetchLogin, fetchSignup, fetchGetCurrentUser } from '@/service/api'
-import { saveAuthToken } from '@/service/request/shared'
+import { fetchLogin, fetchLogout, fetchSignup, fetchGetCurrentUser } from '@/service/api'
+import { saveAuthToken, saveRefreshToken, removeRefreshToken } from '@/service/request/shared'
 import { localStg } from '@/utils/storage'
 import { theToast } from '@/utils/toast'
 import router from '@/router'
@@ -18,36 +18,31 @@ export const useUserStore = defineStore('userStore', () => {
   /**
    * actions
    */
-  // 登录
-  async function login(userInfo: App.Api.Auth.LoginParams) {
-    await fetchLogin(userInfo).then((res) => {
-      const token = String(res.data)
-
-      if (token && token.length > 0) {
-        // 保存token到localStorage
-        saveAuthToken(token)
-
-        // 获取当前登录用户信息
-        refreshCurrentUser()
-
-        // 登录成功
-        theToast.success('登录成功,欢迎回来！🎉')
+  // 登录，需要两步验证时返回登录挑战，由登录页继续完成第二步验证
+  async function login(
+    userInfo: App.Api.Auth.LoginParams,
+  ): Promise<App.Api.Auth.LoginResult | null> {
+    const res = await fetchLogin(userInfo)
+    if (res.code !== 1) {
+      return null
+    }
 
-        // 清除echo数据
-        const echoStore = useEchoStore()
-        echoStore.clearEchos()
+    if (res.data.two_factor_required) {
+      return res.data
+    }
 
-        // 跳转到首页
-        router.push({ name: 'home' })
-      }
-    })
+    await loginWithToken(res.data.access_token, res.data.refresh_token)
+    return null
   }
 
   // 使用token登录（自动登录或OAuth2登录后使用）
-  async function loginWithToken(token: string) {
+  async function loginWithToken(token: string, refreshToken?: string) {
     if (token && token.length > 0) {
-      // 保存token到localStorage
+      // 保存访问令牌与刷新令牌到localStorage
       saveAuthToken(token)
+      if (refreshToken) {
+        saveRefreshToken(refreshToken)
+      }
 
       // 获取当前登录用户信息
       await refreshCurrentUser()
@@ -80,6 +75,12 @@ export const useUserStore = defineStore('userStore', () => {
 
   // 退出登录
   async function logout() {
+    // 主动退出时注销服务端会话，刷新令牌随之作废
+    if (user.value) {
+      await fetchLogout()
+    }
+    removeRefreshToken()
+
     // 清除token
     user.value = null
 
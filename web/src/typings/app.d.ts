@@ -16,7 +16,51 @@ declare namespace App {
       }
 
       type LoginResponse = {
-        token: string
+        access_token: string
+        refresh_token: string
+        token_type: string
+        expires_in: number
+      }
+
+      type LoginResult = LoginResponse & {
+        two_factor_required: boolean
+        two_factor_setup: boolean
+        two_factor_token?: string
+      }
+
+      type TwoFactorLoginResult = LoginResponse & {
+        recovery_codes?: string[]
+      }
+
+      // 两步验证
+      type TwoFactorStatus = {
+        enabled: boolean
+        required: boolean
+        enabled_at: string | null
+        recovery_codes_count: number
+      }
+
+      type TwoFactorSetup = {
+        secret: string
+        uri: string
+        qr_code: string
+      }
+
+      type RecoveryCodes = {
+        recovery_codes: string[]
+      }
+
+      type Session = {
+        id: number
+        user_id: number
+        login_method: string
+        device: string
+        user_agent: string
+        ip: string
+        last_used_at: string
+        expires_at: string
+        created_at: string
+        current: boolean
       }
 
       type SignupParams = {
@@ -45,11 +89,15 @@ declare namespace App {
     }
 
     namespace User {
+      type Role = 'owner' | 'admin' | 'editor' | 'viewer'
+
       type User = {
         id: number
         username: string
         password?: string
         is_admin: boolean
+        role: Role
+        two_factor_required: boolean
         avatar?: string
       }
 
@@ -64,6 +112,7 @@ declare namespace App {
         user_id: number
         username: string
         is_admin: boolean
+        role: Role
       }
     }
 